@@ -3,6 +3,7 @@ package cli
 import (
 	"fmt"
 	"os"
+	"sort"
 	"strings"
 	"text/tabwriter"
 
@@ -99,18 +100,30 @@ func runAliasList(cmd *cobra.Command, args []string) error {
 
 	bundled := store.ListBundled()
 	user := store.ListUser()
+	sourceNames := store.SourceNames()
 
-	if len(bundled) == 0 && len(user) == 0 {
+	if len(bundled) == 0 && len(user) == 0 && len(sourceNames) == 0 {
 		fmt.Println("No aliases available.")
 		return nil
 	}
 
 	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	printed := false
 
-	if len(bundled) > 0 {
-		fmt.Println("Bundled aliases:")
+	// printAliases prints one origin's aliases, noting names that a
+	// higher-precedence origin overrides
+	printAliases := func(title, origin string, list map[string]aliases.Alias) {
+		if len(list) == 0 {
+			return
+		}
+		if printed {
+			fmt.Println()
+		}
+		printed = true
+		fmt.Println(title)
 		fmt.Fprintln(w, "  NAME\tRUNTIME\tDESCRIPTION")
-		for name, alias := range bundled {
+		for _, name := range sortedAliasNames(list) {
+			alias := list[name]
 			runtime := alias.Runtime
 			if runtime == "" {
 				runtime = "node"
@@ -119,19 +132,27 @@ func runAliasList(cmd *cobra.Command, args []string) error {
 			if len(desc) > 50 {
 				desc = desc[:47] + "..."
 			}
+			if winner := store.Origin(name); winner != origin {
+				desc = fmt.Sprintf("(overridden by %s) %s", winner, desc)
+			}
 			fmt.Fprintf(w, "  %s\t%s\t%s\n", name, runtime, desc)
 		}
 		w.Flush()
 	}
 
+	printAliases("Bundled aliases:", aliases.OriginBundled, bundled)
+	for _, name := range sourceNames {
+		printAliases(fmt.Sprintf("Aliases from source %q:", name), name, store.ListSource(name))
+	}
+
 	if len(user) > 0 {
-		if len(bundled) > 0 {
+		if printed {
 			fmt.Println()
 		}
 		fmt.Println("User aliases:")
 		fmt.Fprintln(w, "  NAME\tURL")
-		for name, alias := range user {
-			url := alias.URL
+		for _, name := range sortedAliasNames(user) {
+			url := user[name].URL
 			if len(url) > 50 {
 				url = url[:47] + "..."
 			}
@@ -143,6 +164,16 @@ func runAliasList(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// sortedAliasNames returns the alias names in a map in sorted order
+func sortedAliasNames(list map[string]aliases.Alias) []string {
+	names := make([]string, 0, len(list))
+	for name := range list {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
 func runAliasAdd(cmd *cobra.Command, args []string) error {
 	store := aliases.Default()
 
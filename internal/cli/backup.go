@@ -5,42 +5,74 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
 
+	"github.com/iheanyi/agentctl/pkg/config"
 	"github.com/iheanyi/agentctl/pkg/output"
+	"github.com/iheanyi/agentctl/pkg/snapshot"
 	"github.com/iheanyi/agentctl/pkg/sync"
 )
 
 var backupCmd = &cobra.Command{
 	Use:   "backup",
 	Short: "Manage configuration backups",
-	Long: `Manage backups of tool configuration files.
+	Long: `Manage snapshots and backups of tool configuration.
 
-agentctl automatically creates backups when syncing configurations.
-Use these commands to list, restore, or create backups manually.
+Every sync records a snapshot of all the files it touches across tools:
+config files plus command, rule, skill and agent directories. Snapshots
+are stored by content under the cache directory and named by sync ID.
+Use these commands to see what a sync changed and roll back to any point.
+
+Older snapshots are pruned after each sync. Configure retention with
+settings.snapshots.keep (default 20) and settings.snapshots.maxAge
+(e.g. "30d").
 
 Examples:
-  agentctl backup list                  # List all backups for all tools
-  agentctl backup list --tool claude    # List backups for Claude only
-  agentctl backup restore --tool cursor # Restore most recent Cursor backup
-  agentctl backup create --tool claude  # Manually create a Claude backup`,
+  agentctl backup list                          # List snapshots and backups
+  agentctl backup diff 20260101-120000-ab12     # Show what a sync changed
+  agentctl backup diff 20260101-120000 --current  # Compare a snapshot to now
+  agentctl backup restore 20260101-120000-ab12  # Undo everything since a snapshot
+  agentctl backup restore 20260101-120000 --tool cursor  # Restore Cursor only
+  agentctl backup restore --tool cursor         # Restore most recent Cursor backup file
+  agentctl backup create --tool claude          # Manually back up a Claude config file
+  agentctl backup prune --keep 5                # Keep only the 5 newest snapshots`,
 }
 
 var backupListCmd = &cobra.Command{
 	Use:   "list",
-	Short: "List available backups",
-	Long:  `List all available configuration backups for detected tools.`,
+	Short: "List snapshots and backups",
+	Long:  `List sync snapshots with the tools and files each changed, followed by config file backups for detected tools.`,
 	RunE:  runBackupList,
 }
 
+var backupDiffCmd = &cobra.Command{
+	Use:   "diff <id>",
+	Short: "Show what a snapshot's sync changed",
+	Long: `Show the files a sync changed, with line diffs.
+
+With --current, compare the snapshot to the files on disk now instead;
+this is what 'backup restore' would undo. IDs may be abbreviated to any
+unique prefix.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runBackupDiff,
+}
+
 var backupRestoreCmd = &cobra.Command{
-	Use:   "restore",
-	Short: "Restore from a backup",
-	Long: `Restore a tool's configuration from its most recent backup.
+	Use:   "restore [id]",
+	Short: "Restore from a snapshot or backup",
+	Long: `Restore files to their state in a snapshot, before its sync ran.
+
+Changed and deleted files are rewritten and files created since are
+removed. Use --tool to restore a single tool. The state before restoring
+is recorded as a new snapshot, so a restore can be undone too.
 
-This will overwrite the current configuration with the backup.`,
+Without an ID, restores a tool's config file from its most recent backup
+file (--tool is required).`,
+	Args: cobra.MaximumNArgs(1),
 	RunE: runBackupRestore,
 }
 
@@ -51,18 +83,36 @@ var backupCreateCmd = &cobra.Command{
 	RunE:  runBackupCreate,
 }
 
-var backupTool string
+var backupPruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Delete old snapshots",
+	Long: `Delete snapshots outside the retention policy and the stored content
+only they refer to. Flags override settings.snapshots.`,
+	RunE: runBackupPrune,
+}
+
+var (
+	backupTool    string
+	backupCurrent bool
+	backupKeep    int
+	backupMaxAge  string
+)
 
 func init() {
 	backupCmd.AddCommand(backupListCmd)
+	backupCmd.AddCommand(backupDiffCmd)
 	backupCmd.AddCommand(backupRestoreCmd)
 	backupCmd.AddCommand(backupCreateCmd)
+	backupCmd.AddCommand(backupPruneCmd)
 
 	backupListCmd.Flags().StringVarP(&backupTool, "tool", "t", "", "Filter to specific tool")
-	backupRestoreCmd.Flags().StringVarP(&backupTool, "tool", "t", "", "Tool to restore (required)")
-	backupRestoreCmd.MarkFlagRequired("tool")
+	backupDiffCmd.Flags().StringVarP(&backupTool, "tool", "t", "", "Filter to specific tool")
+	backupDiffCmd.Flags().BoolVar(&backupCurrent, "current", false, "Compare the snapshot to the files on disk now")
+	backupRestoreCmd.Flags().StringVarP(&backupTool, "tool", "t", "", "Tool to restore (required without a snapshot ID)")
 	backupCreateCmd.Flags().StringVarP(&backupTool, "tool", "t", "", "Tool to backup (required)")
 	backupCreateCmd.MarkFlagRequired("tool")
+	backupPruneCmd.Flags().IntVar(&backupKeep, "keep", 0, "Number of snapshots to keep")
+	backupPruneCmd.Flags().StringVar(&backupMaxAge, "max-age", "", "Delete snapshots older than this (e.g. 30d, 72h)")
 }
 
 // BackupInfo represents information about a single backup
@@ -74,10 +124,19 @@ type BackupInfo struct {
 	Size       int64     `json:"size"`
 }
 
+// SnapshotInfo represents a sync snapshot in backup list output
+type SnapshotInfo struct {
+	ID      string            `json:"id"`
+	Time    time.Time         `json:"time"`
+	Tools   []string          `json:"tools"`
+	Changes []snapshot.Change `json:"changes"`
+}
+
 // BackupListOutput represents the JSON output for backup list
 type BackupListOutput struct {
-	Backups []BackupInfo `json:"backups"`
-	Total   int          `json:"total"`
+	Snapshots []SnapshotInfo `json:"snapshots"`
+	Backups   []BackupInfo   `json:"backups"`
+	Total     int            `json:"total"`
 }
 
 func runBackupList(cmd *cobra.Command, args []string) error {
@@ -98,6 +157,29 @@ func runBackupList(cmd *cobra.Command, args []string) error {
 		adapters = sync.Detected()
 	}
 
+	snaps, err := sync.Snapshots().List()
+	if err != nil {
+		if JSONOutput {
+			jw := output.NewJSONWriter()
+			return jw.WriteError(err)
+		}
+		return fmt.Errorf("failed to list snapshots: %w", err)
+	}
+
+	var snapshots []SnapshotInfo
+	for _, snap := range snaps {
+		changes := filterChanges(snap.Changes, backupTool)
+		if backupTool != "" && len(changes) == 0 {
+			continue
+		}
+		snapshots = append(snapshots, SnapshotInfo{
+			ID:      snap.ID,
+			Time:    snap.Time,
+			Tools:   changedTools(changes),
+			Changes: changes,
+		})
+	}
+
 	var allBackups []BackupInfo
 
 	for _, adapter := range adapters {
@@ -130,38 +212,186 @@ func runBackupList(cmd *cobra.Command, args []string) error {
 	if JSONOutput {
 		jw := output.NewJSONWriter()
 		return jw.WriteSuccess(BackupListOutput{
-			Backups: allBackups,
-			Total:   len(allBackups),
+			Snapshots: snapshots,
+			Backups:   allBackups,
+			Total:     len(snapshots) + len(allBackups),
 		})
 	}
 
-	if len(allBackups) == 0 {
-		fmt.Println("No backups found.")
+	if len(snapshots) == 0 && len(allBackups) == 0 {
+		fmt.Println("No snapshots or backups found.")
 		return nil
 	}
 
-	fmt.Printf("Found %d backup(s):\n\n", len(allBackups))
-	for _, backup := range allBackups {
-		fmt.Printf("  %s\n", backup.Tool)
-		fmt.Printf("    Path: %s\n", backup.BackupPath)
-		if !backup.Timestamp.IsZero() {
-			fmt.Printf("    Time: %s\n", backup.Timestamp.Format(time.RFC3339))
+	if len(snapshots) > 0 {
+		fmt.Printf("Found %d snapshot(s):\n\n", len(snapshots))
+		for _, snap := range snapshots {
+			fmt.Printf("  %s  %s\n", snap.ID, snap.Time.Local().Format("2006-01-02 15:04:05"))
+			fmt.Printf("    Tools: %s\n", strings.Join(snap.Tools, ", "))
+			fmt.Printf("    Files changed: %d\n", len(snap.Changes))
+			for _, c := range snap.Changes {
+				fmt.Printf("      %s %s\n", changeSymbol(c.Status), c.Path)
+			}
+			fmt.Println()
+		}
+	}
+
+	if len(allBackups) > 0 {
+		fmt.Printf("Found %d backup file(s):\n\n", len(allBackups))
+		for _, backup := range allBackups {
+			fmt.Printf("  %s\n", backup.Tool)
+			fmt.Printf("    Path: %s\n", backup.BackupPath)
+			if !backup.Timestamp.IsZero() {
+				fmt.Printf("    Time: %s\n", backup.Timestamp.Format(time.RFC3339))
+			}
+			fmt.Printf("    Size: %d bytes\n", backup.Size)
+			fmt.Println()
 		}
-		fmt.Printf("    Size: %d bytes\n", backup.Size)
-		fmt.Println()
 	}
 
 	return nil
 }
 
+// filterChanges returns the changes for tool, or all changes when tool is
+// empty
+func filterChanges(changes []snapshot.Change, tool string) []snapshot.Change {
+	if tool == "" {
+		return changes
+	}
+	var filtered []snapshot.Change
+	for _, c := range changes {
+		if c.Tool == tool {
+			filtered = append(filtered, c)
+		}
+	}
+	return filtered
+}
+
+// changedTools returns the sorted tools that have changes
+func changedTools(changes []snapshot.Change) []string {
+	seen := make(map[string]bool)
+	var tools []string
+	for _, c := range changes {
+		if !seen[c.Tool] {
+			seen[c.Tool] = true
+			tools = append(tools, c.Tool)
+		}
+	}
+	sort.Strings(tools)
+	return tools
+}
+
+// changeSymbol returns a one-character marker for a change status
+func changeSymbol(status string) string {
+	switch status {
+	case snapshot.StatusAdded:
+		return "+"
+	case snapshot.StatusRemoved:
+		return "-"
+	default:
+		return "~"
+	}
+}
+
+// BackupDiffOutput represents the JSON output for backup diff
+type BackupDiffOutput struct {
+	ID      string            `json:"id"`
+	Current bool              `json:"current"`
+	Changes []snapshot.Change `json:"changes"`
+}
+
+func runBackupDiff(cmd *cobra.Command, args []string) error {
+	store := sync.Snapshots()
+	snap, err := store.Load(args[0])
+	if err != nil {
+		if JSONOutput {
+			jw := output.NewJSONWriter()
+			return jw.WriteError(err)
+		}
+		return err
+	}
+
+	// By default show what the sync changed; --current shows how the
+	// files have drifted from the snapshot since
+	changes := filterChanges(snap.Changes, backupTool)
+	if backupCurrent {
+		changes, err = store.Diff(snap, backupTool)
+		if err != nil {
+			if JSONOutput {
+				jw := output.NewJSONWriter()
+				return jw.WriteError(err)
+			}
+			return fmt.Errorf("failed to compare snapshot: %w", err)
+		}
+	}
+
+	if JSONOutput {
+		if changes == nil {
+			changes = []snapshot.Change{}
+		}
+		jw := output.NewJSONWriter()
+		return jw.WriteSuccess(BackupDiffOutput{ID: snap.ID, Current: backupCurrent, Changes: changes})
+	}
+
+	if len(changes) == 0 {
+		if backupCurrent {
+			fmt.Printf("No differences between snapshot %s and the current files.\n", snap.ID)
+		} else {
+			fmt.Printf("Snapshot %s has no recorded changes.\n", snap.ID)
+		}
+		return nil
+	}
+
+	for _, c := range changes {
+		fmt.Printf("%s %s (%s, %s)\n", changeSymbol(c.Status), c.Path, c.Tool, c.Status)
+		before, after := changeContent(store, c, backupCurrent)
+		for _, line := range snapshot.LineDiff(before, after) {
+			fmt.Printf("  %s\n", line)
+		}
+		fmt.Println()
+	}
+	return nil
+}
+
+// changeContent returns the before and after content of a change. After
+// is read from disk when comparing against the current files.
+func changeContent(store *snapshot.Store, c snapshot.Change, current bool) ([]byte, []byte) {
+	var before, after []byte
+	if c.Before != "" {
+		before, _ = store.Read(c.Before)
+	}
+	if c.After != "" {
+		if current {
+			after, _ = os.ReadFile(c.Path)
+		} else {
+			after, _ = store.Read(c.After)
+		}
+	}
+	return before, after
+}
+
 // BackupRestoreOutput represents the JSON output for backup restore
 type BackupRestoreOutput struct {
-	Tool         string `json:"tool"`
-	ConfigPath   string `json:"configPath"`
-	RestoredFrom string `json:"restoredFrom"`
+	Tool         string            `json:"tool,omitempty"`
+	ConfigPath   string            `json:"configPath,omitempty"`
+	RestoredFrom string            `json:"restoredFrom"`
+	Changes      []snapshot.Change `json:"changes,omitempty"`
+	SnapshotID   string            `json:"snapshotId,omitempty"` // Snapshot of the state before restoring
 }
 
 func runBackupRestore(cmd *cobra.Command, args []string) error {
+	if len(args) == 1 {
+		return runSnapshotRestore(args[0])
+	}
+	if backupTool == "" {
+		err := fmt.Errorf("specify a snapshot ID or --tool (see 'agentctl backup list')")
+		if JSONOutput {
+			jw := output.NewJSONWriter()
+			return jw.WriteError(err)
+		}
+		return err
+	}
+
 	adapter, ok := sync.Get(backupTool)
 	if !ok {
 		err := fmt.Errorf("unknown tool %q", backupTool)
@@ -210,6 +440,76 @@ func runBackupRestore(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// runSnapshotRestore restores files from a snapshot, recording the state
+// it replaces as a new snapshot
+func runSnapshotRestore(id string) error {
+	store := sync.Snapshots()
+	snap, err := store.Load(id)
+	if err == nil && backupTool != "" && !snap.HasTool(backupTool) {
+		err = fmt.Errorf("snapshot %s has no files for %s", snap.ID, backupTool)
+	}
+	if err != nil {
+		if JSONOutput {
+			jw := output.NewJSONWriter()
+			return jw.WriteError(err)
+		}
+		return err
+	}
+
+	// Snapshot the current state of the same roots first
+	undo := store.Begin(snapshot.NewID(time.Now()))
+	for _, root := range snap.Roots {
+		if backupTool != "" && root.Tool != backupTool {
+			continue
+		}
+		if err := store.Capture(undo, root.Tool, root.Path); err != nil {
+			err = fmt.Errorf("failed to snapshot current files: %w", err)
+			if JSONOutput {
+				jw := output.NewJSONWriter()
+				return jw.WriteError(err)
+			}
+			return err
+		}
+	}
+
+	changes, err := store.Restore(snap, backupTool)
+	if err != nil {
+		if JSONOutput {
+			jw := output.NewJSONWriter()
+			return jw.WriteError(err)
+		}
+		return fmt.Errorf("failed to restore snapshot: %w", err)
+	}
+
+	var undoID string
+	if saved, err := store.Finish(undo); err == nil && saved {
+		undoID = undo.ID
+	}
+
+	if JSONOutput {
+		jw := output.NewJSONWriter()
+		return jw.WriteSuccess(BackupRestoreOutput{
+			Tool:         backupTool,
+			RestoredFrom: snap.ID,
+			Changes:      changes,
+			SnapshotID:   undoID,
+		})
+	}
+
+	if len(changes) == 0 {
+		fmt.Printf("Files already match snapshot %s\n", snap.ID)
+		return nil
+	}
+	fmt.Printf("✓ Restored %d file(s) from snapshot %s\n", len(changes), snap.ID)
+	for _, c := range changes {
+		fmt.Printf("  %s\n", c.Path)
+	}
+	if undoID != "" {
+		fmt.Printf("  Previous state saved as snapshot %s\n", undoID)
+	}
+	return nil
+}
+
 // BackupCreateOutput represents the JSON output for backup create
 type BackupCreateOutput struct {
 	Tool       string `json:"tool"`
@@ -269,8 +569,57 @@ func runBackupCreate(cmd *cobra.Command, args []string) error {
 // MarshalJSON for BackupListOutput to handle empty slices
 func (o BackupListOutput) MarshalJSON() ([]byte, error) {
 	type Alias BackupListOutput
+	if o.Snapshots == nil {
+		o.Snapshots = []SnapshotInfo{}
+	}
 	if o.Backups == nil {
 		o.Backups = []BackupInfo{}
 	}
 	return json.Marshal(Alias(o))
 }
+
+// BackupPruneOutput represents the JSON output for backup prune
+type BackupPruneOutput struct {
+	Removed []string `json:"removed"`
+}
+
+func runBackupPrune(cmd *cobra.Command, args []string) error {
+	var settings config.SnapshotConfig
+	if cfg, err := config.Load(); err == nil && cfg.Settings.Snapshots != nil {
+		settings = *cfg.Settings.Snapshots
+	}
+	if cmd.Flags().Changed("keep") {
+		settings.Keep = backupKeep
+	}
+	if cmd.Flags().Changed("max-age") {
+		settings.MaxAge = backupMaxAge
+	}
+
+	retention, err := sync.SnapshotRetention(settings)
+	var removed []string
+	if err == nil {
+		removed, err = sync.Snapshots().Prune(retention)
+	}
+	if err != nil {
+		if JSONOutput {
+			jw := output.NewJSONWriter()
+			return jw.WriteError(err)
+		}
+		return err
+	}
+
+	if JSONOutput {
+		if removed == nil {
+			removed = []string{}
+		}
+		jw := output.NewJSONWriter()
+		return jw.WriteSuccess(BackupPruneOutput{Removed: removed})
+	}
+
+	if len(removed) == 0 {
+		fmt.Println("No snapshots to prune.")
+		return nil
+	}
+	fmt.Printf("✓ Pruned %d snapshot(s)\n", len(removed))
+	return nil
+}
@@ -1,9 +1,19 @@
 package cli
 
 import (
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
+
+	"github.com/iheanyi/agentctl/pkg/config"
+	"github.com/iheanyi/agentctl/pkg/mcp"
+	"github.com/iheanyi/agentctl/pkg/output"
+	"github.com/iheanyi/agentctl/pkg/registry"
+	"github.com/iheanyi/agentctl/pkg/sync"
 )
 
 func TestPathToName(t *testing.T) {
@@ -86,7 +96,7 @@ func TestParseAddTarget(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			server, err := parseAddTarget(tt.target)
+			server, _, err := parseAddTarget(tt.target)
 			if tt.wantErr {
 				if err == nil {
 					t.Error("Expected error, got nil")
@@ -110,7 +120,7 @@ func TestParseAddTarget(t *testing.T) {
 }
 
 func TestParseAddTargetWithVersion(t *testing.T) {
-	server, err := parseAddTarget("filesystem@v1.2.3")
+	server, _, err := parseAddTarget("filesystem@v1.2.3")
 	if err != nil {
 		t.Fatalf("Unexpected error: %v", err)
 	}
@@ -119,6 +129,46 @@ func TestParseAddTargetWithVersion(t *testing.T) {
 	}
 }
 
+func TestParseAddTargetImage(t *testing.T) {
+	for _, target := range []string{"oci://ghcr.io/acme/weather-mcp:1.2.0", "docker://ghcr.io/acme/weather-mcp@sha256:abc"} {
+		server, _, err := parseAddTarget(target)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		image := target[strings.Index(target, "://")+3:]
+		if server.Name != "weather" || server.Source.Type != "oci" || server.Container == nil || server.Container.Image != image {
+			t.Errorf("parseAddTarget(%q) = %+v", target, server)
+		}
+	}
+}
+
+func TestParseAddTargetRegistry(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.EscapedPath() != "/v0/servers/io.github.acme%2Fweather/versions/1.2.0" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"server": {"name": "io.github.acme/weather", "version": "1.2.0",
+			"packages": [{"registryType": "npm", "identifier": "@acme/weather", "version": "1.2.0", "transport": {"type": "stdio"}}]}}`))
+	}))
+	defer srv.Close()
+
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv(registry.EnvRegistryURL, srv.URL)
+
+	server, _, err := parseAddTarget("io.github.acme/weather@1.2.0")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if server.Name != "weather" || server.Source.Type != "registry" || server.Command != "npx" {
+		t.Errorf("parseAddTarget() = %+v", server)
+	}
+
+	if _, _, err := parseAddTarget("io.github.acme/missing"); err == nil {
+		t.Error("Expected error for a server missing from the registry")
+	}
+}
+
 func TestGetVersion(t *testing.T) {
 	// Test with a command that should exist
 	version, err := getVersion("go", []string{"version"})
@@ -312,3 +362,159 @@ func TestConfigDirCreation(t *testing.T) {
 		}
 	}
 }
+
+func TestResolveInputs(t *testing.T) {
+	stored := make(map[string]string)
+	orig := storeInputSecret
+	storeInputSecret = func(name, value string) error {
+		stored[name] = value
+		return nil
+	}
+	defer func() { storeInputSecret = orig }()
+
+	inputs := []mcp.Input{
+		{Name: "SLACK_BOT_TOKEN", Secret: true, Description: "Slack bot token"},
+		{Name: "SLACK_TEAM_ID"},
+		{Name: "SLACK_CHANNEL", Optional: true},
+		{Name: "Authorization", Secret: true, Target: mcp.InputTargetHeader, Format: "Bearer {value}"},
+	}
+
+	t.Run("missing inputs are listed", func(t *testing.T) {
+		t.Setenv("SLACK_TEAM_ID", "")
+		server := &mcp.Server{Name: "slack"}
+		err := resolveInputs(server, inputs, map[string]string{"Authorization": "abc"}, false)
+		if err == nil {
+			t.Fatal("Expected an error for missing inputs")
+		}
+		for _, want := range []string{"SLACK_BOT_TOKEN (env, secret) - Slack bot token", "SLACK_TEAM_ID (env)"} {
+			if !strings.Contains(err.Error(), want) {
+				t.Errorf("error %q should mention %q", err, want)
+			}
+		}
+		if strings.Contains(err.Error(), "SLACK_CHANNEL") || strings.Contains(err.Error(), "Authorization") {
+			t.Errorf("error %q should only list missing required inputs", err)
+		}
+	})
+
+	t.Run("provided and environment values", func(t *testing.T) {
+		t.Setenv("SLACK_TEAM_ID", "T0123")
+		server := &mcp.Server{Name: "slack", Env: map[string]string{"SLACK_CHANNEL": "$SLACK_CHANNEL"}}
+		err := resolveInputs(server, inputs, map[string]string{
+			"SLACK_BOT_TOKEN": "xoxb-secret",
+			"Authorization":   "$API_TOKEN",
+		}, false)
+		if err != nil {
+			t.Fatalf("resolveInputs() error = %v", err)
+		}
+
+		wantEnv := map[string]string{"SLACK_BOT_TOKEN": "keychain:slack-slack-bot-token", "SLACK_TEAM_ID": "$SLACK_TEAM_ID"}
+		if !reflect.DeepEqual(server.Env, wantEnv) {
+			t.Errorf("Env = %v, want %v", server.Env, wantEnv)
+		}
+		if got := server.Headers["Authorization"]; got != "Bearer ${API_TOKEN}" {
+			t.Errorf("Authorization header = %q", got)
+		}
+		if stored["slack-slack-bot-token"] != "xoxb-secret" {
+			t.Errorf("secret not stored in keychain: %v", stored)
+		}
+	})
+}
+
+func TestPublishedSchemasUpToDate(t *testing.T) {
+	for _, st := range schemaTypes {
+		want, err := schemaJSON(st.Generate())
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := os.ReadFile(filepath.Join("..", "..", "schemas", st.Name+".schema.json"))
+		if err != nil {
+			t.Fatalf("reading published %s schema: %v", st.Name, err)
+		}
+		if string(got) != string(want) {
+			t.Errorf("schemas/%s.schema.json is stale; regenerate with 'go run ./cmd/agentctl schema --write schemas'", st.Name)
+		}
+	}
+}
+
+func TestProxyServer(t *testing.T) {
+	orig := oauthToken
+	defer func() { oauthToken = orig }()
+	oauthToken = func(server string) (string, error) {
+		if server == "sentry" {
+			return "oauth-token", nil
+		}
+		return "", os.ErrNotExist
+	}
+	t.Setenv("FIGMA_TOKEN", "figma-token")
+
+	cfg := &config.Config{Servers: map[string]*mcp.Server{
+		"sentry": {Name: "sentry", Transport: mcp.TransportHTTP, URL: "https://mcp.sentry.dev/mcp"},
+		"figma":  {Name: "figma", Transport: mcp.TransportSSE, URL: "https://figma.example/sse", Headers: map[string]string{"authorization": "$FIGMA_TOKEN"}},
+		"fs":     {Name: "fs", Command: "fs-mcp"},
+	}}
+
+	sentry, err := proxyServer(cfg, "sentry")
+	if err != nil {
+		t.Fatalf("proxyServer(sentry) error = %v", err)
+	}
+	if got := sentry.Headers["Authorization"]; got != "Bearer oauth-token" {
+		t.Errorf("sentry Authorization = %q, want the stored OAuth token", got)
+	}
+
+	// An explicit Authorization header wins over a stored token
+	figma, err := proxyServer(cfg, "figma")
+	if err != nil {
+		t.Fatalf("proxyServer(figma) error = %v", err)
+	}
+	if len(figma.Headers) != 1 || figma.Headers["authorization"] != "figma-token" {
+		t.Errorf("figma headers = %v", figma.Headers)
+	}
+
+	if _, err := proxyServer(cfg, "fs"); err == nil {
+		t.Error("proxyServer() should reject stdio servers")
+	}
+	if _, err := proxyServer(cfg, "missing"); err == nil {
+		t.Error("proxyServer() should reject unknown servers")
+	}
+}
+
+func TestPerformScopedSyncResolvesVariables(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CACHE_HOME", filepath.Join(home, ".cache"))
+	t.Setenv("AGENTCTL_HOME", filepath.Join(home, ".config", "agentctl"))
+	t.Setenv("MCP_TOKEN", "abc")
+	if err := os.MkdirAll(filepath.Join(home, ".claude"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &config.Config{Vars: map[string]string{"region": "eu"}}
+	server := &mcp.Server{
+		Name:    "fs",
+		Command: "fs-mcp",
+		Args:    []string{"${home}/data", "${region}"},
+		Env:     map[string]string{"TOKEN": "${env.MCP_TOKEN}"},
+		Scope:   string(config.ScopeGlobal),
+	}
+	if n := performScopedSync(cfg, server, config.ScopeGlobal, output.DefaultWriter(), "claude"); n != 1 {
+		t.Fatalf("performScopedSync() synced %d tools, want 1", n)
+	}
+
+	adapter, _ := sync.Get("claude")
+	servers, err := adapter.(sync.ServerAdapter).ReadServers()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got *mcp.Server
+	for _, s := range servers {
+		if s.Name == "fs" {
+			got = s
+		}
+	}
+	if got == nil {
+		t.Fatalf("fs not written: %v", servers)
+	}
+	if want := []string{filepath.Join(home, "data"), "eu"}; !reflect.DeepEqual(got.Args, want) || got.Env["TOKEN"] != "abc" {
+		t.Errorf("written server = %q %v, want args %q and TOKEN resolved", got.Args, got.Env, want)
+	}
+}
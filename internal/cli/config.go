@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
-	"strings"
 
 	"github.com/spf13/cobra"
 
@@ -18,11 +17,20 @@ var configCmd = &cobra.Command{
 	Short: "View and edit configuration",
 	Long: `View and edit agentctl configuration.
 
+Keys are dotted paths of JSON field names. Map keys and list indexes
+are path segments too; quote keys containing dots.
+
 Examples:
   agentctl config                       # Show config location and summary
   agentctl config show                  # Show full config as JSON
   agentctl config get settings.defaultProfile
   agentctl config set settings.defaultProfile work
+  agentctl config set servers.github.env.GITHUB_TOKEN keychain:github
+  agentctl config set settings.tools.cursor.enabled false
+  agentctl config set servers.fs.args --append -- --verbose
+  agentctl config unset servers.github.env.DEBUG
+  agentctl config set --scope local profile work   # Edit .agentctl.json
+  agentctl config explain settings.defaultProfile  # Which layer set it
   agentctl config edit                  # Open in editor`,
 	RunE: runConfig,
 }
@@ -36,17 +44,66 @@ var configShowCmd = &cobra.Command{
 var configGetCmd = &cobra.Command{
 	Use:   "get <key>",
 	Short: "Get a configuration value",
-	Args:  cobra.ExactArgs(1),
-	RunE:  runConfigGet,
+	Long: `Print the value at a dotted path. Strings print as-is; other values
+print as JSON.
+
+With --scope all, reads the global config merged with the project
+config.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runConfigGet,
 }
 
 var configSetCmd = &cobra.Command{
-	Use:   "set <key> <value>",
+	Use:   "set <key> <value>...",
 	Short: "Set a configuration value",
-	Args:  cobra.ExactArgs(2),
-	RunE:  runConfigSet,
+	Long: `Set the value at a dotted path, creating missing objects and map
+entries along the way.
+
+The value is converted to the field's type: booleans take true/false,
+numbers are parsed, lists take a JSON array or comma-separated items,
+and objects and maps take JSON. Use --append and --remove to add or
+remove list items without replacing the list.
+
+The changed config is validated against the config schema before it's
+saved, so typos in keys and invalid values like an unknown transport
+are rejected.`,
+	Args: cobra.MinimumNArgs(2),
+	RunE: runConfigSet,
+}
+
+var configUnsetCmd = &cobra.Command{
+	Use:   "unset <key>",
+	Short: "Remove a configuration value",
+	Long: `Remove the value at a dotted path. Map entries and list items are
+deleted; other fields are reset to their default.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runConfigUnset,
+}
+
+var configExplainCmd = &cobra.Command{
+	Use:   "explain <key>",
+	Short: "Show which config layer supplies a value",
+	Long: `Show the effective value at a dotted path and every config layer that
+sets it, from lowest to highest precedence:
+
+  system   /etc/agentctl/agentctl.json (or $AGENTCTL_SYSTEM_CONFIG)
+  team     settings.teamConfig: a path, or a source shipping agentctl.json
+  global   ~/.config/agentctl/agentctl.json
+  project  every .agentctl.json from the repository root down to the cwd
+  env      AGENTCTL_CONFIG_<PATH> variables, "__" between path segments
+
+Servers and settings from higher layers win; command, rule and skill
+lists and permissions combine across layers.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runConfigExplain,
 }
 
+var (
+	configScope  string
+	configAppend bool
+	configRemove bool
+)
+
 var configEditCmd = &cobra.Command{
 	Use:   "edit",
 	Short: "Open configuration in editor",
@@ -63,8 +120,17 @@ func init() {
 	configCmd.AddCommand(configShowCmd)
 	configCmd.AddCommand(configGetCmd)
 	configCmd.AddCommand(configSetCmd)
+	configCmd.AddCommand(configUnsetCmd)
+	configCmd.AddCommand(configExplainCmd)
 	configCmd.AddCommand(configEditCmd)
 	configCmd.AddCommand(configPathCmd)
+
+	configGetCmd.Flags().StringVarP(&configScope, "scope", "s", "global", "Config scope: local, global or all (merged)")
+	for _, c := range []*cobra.Command{configSetCmd, configUnsetCmd} {
+		c.Flags().StringVarP(&configScope, "scope", "s", "global", "Config scope: local (.agentctl.json) or global")
+	}
+	configSetCmd.Flags().BoolVar(&configAppend, "append", false, "Append the values to a list")
+	configSetCmd.Flags().BoolVar(&configRemove, "remove", false, "Remove the values from a list")
 }
 
 func runConfig(cmd *cobra.Command, args []string) error {
@@ -118,70 +184,157 @@ func runConfigShow(cmd *cobra.Command, args []string) error {
 func runConfigGet(cmd *cobra.Command, args []string) error {
 	key := args[0]
 
-	cfg, err := config.Load()
+	scope, err := config.ParseScope(configScope)
 	if err != nil {
-		return fmt.Errorf("failed to load config: %w", err)
+		return err
 	}
-
-	// Convert config to map for key lookup
-	data, err := json.Marshal(cfg)
+	cfg, err := config.LoadScoped(scope)
 	if err != nil {
-		return err
+		return fmt.Errorf("failed to load config: %w", err)
 	}
 
-	var configMap map[string]interface{}
-	if err := json.Unmarshal(data, &configMap); err != nil {
+	value, err := cfg.GetPath(key)
+	if err != nil {
 		return err
 	}
 
-	// Navigate to the key
-	value, err := getNestedValue(configMap, key)
-	if err != nil {
-		return err
+	if JSONOutput {
+		return output.NewJSONWriter().WriteSuccess(map[string]any{"key": key, "value": value})
 	}
 
 	// Print value
 	switch v := value.(type) {
 	case string:
 		fmt.Println(v)
-	case nil:
-		fmt.Println("null")
 	default:
-		jsonValue, _ := json.MarshalIndent(v, "", "  ")
+		jsonValue, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return err
+		}
 		fmt.Println(string(jsonValue))
 	}
 
 	return nil
 }
 
+// loadConfigForEdit loads the config file of a writable scope
+func loadConfigForEdit() (*config.Config, config.Scope, error) {
+	scope, err := config.ParseScope(configScope)
+	if err != nil {
+		return nil, "", err
+	}
+	if scope == config.ScopeAll {
+		return nil, "", fmt.Errorf("cannot edit scope %q (use local or global)", configScope)
+	}
+	cfg, err := config.LoadScoped(scope)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to load config: %w", err)
+	}
+	return cfg, scope, nil
+}
+
 func runConfigSet(cmd *cobra.Command, args []string) error {
-	key := args[0]
-	value := args[1]
+	key, values := args[0], args[1:]
+	if configAppend && configRemove {
+		return fmt.Errorf("--append and --remove can't be used together")
+	}
 
-	cfg, err := config.Load()
+	cfg, scope, err := loadConfigForEdit()
 	if err != nil {
-		return fmt.Errorf("failed to load config: %w", err)
+		return err
 	}
 
-	out := output.DefaultWriter()
-
-	// For now, only support settings.defaultProfile
-	switch key {
-	case "settings.defaultProfile":
-		cfg.Settings.DefaultProfile = value
-	case "settings.autoUpdate.enabled":
-		cfg.Settings.AutoUpdate.Enabled = value == "true"
-	case "settings.autoUpdate.interval":
-		cfg.Settings.AutoUpdate.Interval = value
+	switch {
+	case configAppend:
+		err = cfg.AppendPath(key, values...)
+	case configRemove:
+		err = cfg.RemovePath(key, values...)
+	case len(values) > 1:
+		return fmt.Errorf("set takes a single value (use --append to add several list items)")
 	default:
-		return fmt.Errorf("setting %q is not supported via CLI (edit config file directly)", key)
+		err = cfg.SetPath(key, values[0])
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := cfg.SaveScoped(scope); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
 	}
 
-	if err := cfg.Save(); err != nil {
+	value, _ := cfg.GetPath(key)
+	if JSONOutput {
+		return output.NewJSONWriter().WriteSuccess(map[string]any{"key": key, "value": value, "scope": scope})
+	}
+	display, _ := json.Marshal(value)
+	output.DefaultWriter().Success("Set %s = %s", key, display)
+	return nil
+}
+
+func runConfigUnset(cmd *cobra.Command, args []string) error {
+	key := args[0]
+
+	cfg, scope, err := loadConfigForEdit()
+	if err != nil {
+		return err
+	}
+	if err := cfg.UnsetPath(key); err != nil {
+		return err
+	}
+	if err := cfg.SaveScoped(scope); err != nil {
 		return fmt.Errorf("failed to save config: %w", err)
 	}
 
-	out.Success("Set %s = %s", key, value)
+	if JSONOutput {
+		return output.NewJSONWriter().WriteSuccess(map[string]any{"key": key, "scope": scope})
+	}
+	output.DefaultWriter().Success("Unset %s", key)
+	return nil
+}
+
+func runConfigExplain(cmd *cobra.Command, args []string) error {
+	key := args[0]
+
+	cfg, err := config.LoadWithProject()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	exp, err := cfg.Explain(key)
+	if err != nil {
+		return err
+	}
+
+	if JSONOutput {
+		return output.NewJSONWriter().WriteSuccess(exp)
+	}
+
+	out := output.DefaultWriter()
+	if !exp.Found {
+		out.Println("%s is not set", key)
+	} else {
+		display, _ := json.Marshal(exp.Value)
+		out.Println("%s = %s", key, display)
+	}
+	if len(exp.Layers) == 0 {
+		out.Info("No config layer sets %s", key)
+		return nil
+	}
+
+	out.Println("")
+	winner := exp.Winner()
+	for _, lv := range exp.Layers {
+		display, _ := json.Marshal(lv.Value)
+		marker := " "
+		if lv.Layer == winner {
+			marker = "*"
+		}
+		out.Println("  %s %-8s %s", marker, lv.Layer.Kind, lv.Layer.Path)
+		out.Println("             %s", display)
+	}
+	if winner != nil {
+		out.Println("")
+		out.Info("* supplies the effective value")
+	}
 	return nil
 }
 
@@ -218,23 +371,3 @@ func runConfigPath(cmd *cobra.Command, args []string) error {
 	fmt.Println(cfg.Path)
 	return nil
 }
-
-func getNestedValue(m map[string]interface{}, key string) (interface{}, error) {
-	parts := strings.Split(key, ".")
-	current := interface{}(m)
-
-	for _, part := range parts {
-		switch v := current.(type) {
-		case map[string]interface{}:
-			val, ok := v[part]
-			if !ok {
-				return nil, fmt.Errorf("key %q not found", key)
-			}
-			current = val
-		default:
-			return nil, fmt.Errorf("cannot navigate into %q", part)
-		}
-	}
-
-	return current, nil
-}
@@ -2,7 +2,7 @@ package cli
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
@@ -15,6 +15,7 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/iheanyi/agentctl/pkg/config"
+	"github.com/iheanyi/agentctl/pkg/container"
 	"github.com/iheanyi/agentctl/pkg/output"
 	"github.com/iheanyi/agentctl/pkg/sync"
 )
@@ -112,6 +113,7 @@ func runDoctor(cmd *cobra.Command, args []string) error {
 		{"uv", "uv", []string{"--version"}, false},
 		{"Go", "go", []string{"version"}, false},
 		{"Docker", "docker", []string{"--version"}, false},
+		{"Podman", "podman", []string{"--version"}, false},
 	}
 
 	type runtimeResult struct {
@@ -236,7 +238,7 @@ func runDoctor(cmd *cobra.Command, args []string) error {
 	if detectedCount == 0 {
 		if !JSONOutput {
 			fmt.Println("  No supported tools detected!")
-			fmt.Println("  Supported: Claude Code, Cursor, Codex, OpenCode, Cline, Windsurf, Zed, Continue")
+			fmt.Println("  Supported: Claude Code, Cursor, Codex, OpenCode, Cline, Windsurf, Zed, Continue, VS Code, Roo Code, Goose, Amp, Kiro")
 		}
 		issues++
 	}
@@ -322,6 +324,8 @@ func runDoctor(cmd *cobra.Command, args []string) error {
 		if !JSONOutput {
 			fmt.Println("MCP Servers:")
 		}
+		// Each container runtime is checked once, however many servers use it
+		runtimeErrs := make(map[string]error)
 		for _, server := range cfg.Servers {
 			serverRes := output.DoctorServerResult{
 				Name:     server.Name,
@@ -337,7 +341,44 @@ func runDoctor(cmd *cobra.Command, args []string) error {
 				continue
 			}
 
-			if server.URL != "" {
+			if server.Container != nil {
+				serverRes.Type = "container"
+				rt := container.Runtime(server.Container)
+				err, checked := runtimeErrs[rt]
+				if !checked {
+					err = container.Check(rt)
+					runtimeErrs[rt] = err
+				}
+				image := container.Image(server)
+				present := err == nil && container.HasImage(server)
+				switch {
+				case err != nil:
+					serverRes.Available = false
+					serverRes.Error = err.Error()
+					if !JSONOutput {
+						fmt.Printf("  ✗ %s: %v\n", server.Name, err)
+					}
+					issues++
+				case !present && server.Container.Image == "":
+					// Locally built images can't be pulled on first start
+					serverRes.Available = false
+					serverRes.Error = fmt.Sprintf("image not built: %s", image)
+					if !JSONOutput {
+						fmt.Printf("  ✗ %s: image not built: %s (run 'agentctl update %s')\n", server.Name, image, server.Name)
+					}
+					issues++
+				case !present:
+					serverRes.Available = true
+					if !JSONOutput {
+						fmt.Printf("  ⚠ %s (%s): %s not pulled yet\n", server.Name, rt, image)
+					}
+				default:
+					serverRes.Available = true
+					if !JSONOutput {
+						fmt.Printf("  ✓ %s (%s)\n", server.Name, rt)
+					}
+				}
+			} else if server.URL != "" {
 				serverRes.Type = "http"
 				serverRes.Available = true
 				if !JSONOutput {
@@ -457,39 +498,14 @@ func runDoctor(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
-// validateToolConfig validates a tool's config file
+// validateToolConfig validates a tool's config file with the checks
+// 'agentctl validate' runs, returning the first error found
 func validateToolConfig(adapter sync.Adapter) (valid bool, serverCount int, err error) {
-	configPath := adapter.ConfigPath()
-
-	data, err := os.ReadFile(configPath)
-	if err != nil {
-		if os.IsNotExist(err) {
-			return true, 0, nil // Not created yet is OK
-		}
-		return false, 0, err
+	result := validateAdapter(adapter)
+	if len(result.Errors) > 0 {
+		return false, result.ServerCount, errors.New(result.Errors[0])
 	}
-
-	var raw map[string]interface{}
-	if err := json.Unmarshal(data, &raw); err != nil {
-		return false, 0, fmt.Errorf("invalid JSON")
-	}
-
-	// Get server section
-	var serverKey string
-	switch adapter.Name() {
-	case "zed":
-		serverKey = "context_servers"
-	case "opencode":
-		serverKey = "mcp"
-	default:
-		serverKey = "mcpServers"
-	}
-
-	if servers, ok := raw[serverKey].(map[string]interface{}); ok {
-		serverCount = len(servers)
-	}
-
-	return true, serverCount, nil
+	return result.Valid, result.ServerCount, nil
 }
 
 // shortenPath replaces home directory with ~
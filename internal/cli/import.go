@@ -7,11 +7,13 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"github.com/iheanyi/agentctl/pkg/codexrules"
 	"github.com/iheanyi/agentctl/pkg/command"
 	"github.com/iheanyi/agentctl/pkg/config"
 	"github.com/iheanyi/agentctl/pkg/discovery"
 	"github.com/iheanyi/agentctl/pkg/mcp"
 	"github.com/iheanyi/agentctl/pkg/output"
+	"github.com/iheanyi/agentctl/pkg/permission"
 	"github.com/iheanyi/agentctl/pkg/rule"
 	"github.com/iheanyi/agentctl/pkg/skill"
 	"github.com/iheanyi/agentctl/pkg/sync"
@@ -20,7 +22,7 @@ import (
 var importCmd = &cobra.Command{
 	Use:   "import [tool]",
 	Short: "Import configuration from an existing tool",
-	Long: `Import MCP servers, commands, rules, or skills from an existing tool.
+	Long: `Import MCP servers, commands, rules, skills, or permissions from an existing tool.
 
 This reads the tool's configuration and adds the entries to agentctl,
 allowing you to manage them centrally.
@@ -35,7 +37,9 @@ Examples:
   agentctl import claude           # Import all from Claude Code global config
   agentctl import cursor --servers # Import only servers from Cursor
   agentctl import claude --local   # Import from .mcp.json to .agentctl.json
+  agentctl import vscode --local   # Import from .vscode/mcp.json to .agentctl.json
   agentctl import cline --rules    # Import only rules from Cline
+  agentctl import codex --permissions # Import Codex execution rules
   agentctl import --all            # Import all discovered native resources
   agentctl import --all --rules    # Import only rules from all tools
   agentctl import --all --force    # Overwrite existing resources
@@ -49,6 +53,7 @@ var (
 	importCommands bool
 	importRules    bool
 	importSkills   bool
+	importPerms    bool
 	importLocal    bool
 	importAll      bool
 	importForce    bool
@@ -60,6 +65,7 @@ func init() {
 	importCmd.Flags().BoolVar(&importCommands, "commands", false, "Import only commands")
 	importCmd.Flags().BoolVar(&importRules, "rules", false, "Import only rules")
 	importCmd.Flags().BoolVar(&importSkills, "skills", false, "Import only skills")
+	importCmd.Flags().BoolVar(&importPerms, "permissions", false, "Import only permissions (e.g., Codex execution rules)")
 	importCmd.Flags().BoolVar(&importLocal, "local", false, "Import from workspace config (e.g., .mcp.json) to local .agentctl.json")
 	importCmd.Flags().BoolVar(&importAll, "all", false, "Import from all discovered native resources (.claude/, .cursor/, etc.)")
 	importCmd.Flags().BoolVar(&importForce, "force", false, "Overwrite existing resources")
@@ -113,10 +119,10 @@ func runImport(cmd *cobra.Command, args []string) error {
 	}
 
 	// Determine what to import (default: all types)
-	importAllTypes := !importServers && !importCommands && !importRules && !importSkills
+	importAllTypes := !importServers && !importCommands && !importRules && !importSkills && !importPerms
 
 	supported := adapter.SupportedResources()
-	var serverCount, commandCount, ruleCount int
+	var serverCount, commandCount, ruleCount, permCount int
 
 	// Import servers
 	if (importAllTypes || importServers) && containsResourceType(supported, sync.ResourceMCP) {
@@ -246,8 +252,20 @@ func runImport(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	// Import permissions
+	if (importAllTypes || importPerms) && containsResourceType(supported, sync.ResourcePermissions) {
+		pa, ok := sync.AsPermissionsAdapter(adapter)
+		if !ok {
+			out.Warning("Adapter doesn't support reading permissions")
+		} else if perms, err := pa.ReadPermissions(); err != nil {
+			out.Warning("Failed to read permissions: %v", err)
+		} else {
+			permCount = importPermissions(cfg, perms, out)
+		}
+	}
+
 	// Save config if we imported anything (not in dry-run mode)
-	if !importDryRun && serverCount > 0 {
+	if !importDryRun && (serverCount > 0 || permCount > 0) {
 		if err := cfg.Save(); err != nil {
 			return fmt.Errorf("failed to save config: %w", err)
 		}
@@ -270,8 +288,11 @@ func runImport(cmd *cobra.Command, args []string) error {
 	if ruleCount > 0 {
 		out.Success("%s%d rule(s)", prefix, ruleCount)
 	}
+	if permCount > 0 {
+		out.Success("%s%d permission(s)", prefix, permCount)
+	}
 
-	if serverCount == 0 && commandCount == 0 && ruleCount == 0 {
+	if serverCount == 0 && commandCount == 0 && ruleCount == 0 && permCount == 0 {
 		out.Info("No new resources to import")
 	} else if !importDryRun {
 		out.Println("")
@@ -401,7 +422,7 @@ func runImportAll(out *output.Writer) error {
 	out.Println("")
 
 	// Determine what to import (default: all types)
-	importAllTypes := !importServers && !importCommands && !importRules && !importSkills
+	importAllTypes := !importServers && !importCommands && !importRules && !importSkills && !importPerms
 
 	var (
 		serverCount  int
@@ -409,6 +430,7 @@ func runImportAll(out *output.Writer) error {
 		ruleCount    int
 		skillCount   int
 		pluginCount  int
+		permCount    int
 	)
 
 	rulesDir := filepath.Join(cfg.ConfigDir, "rules")
@@ -562,6 +584,16 @@ func runImportAll(out *output.Writer) error {
 			}
 			skillCount++
 
+		case "execrule":
+			if !importAllTypes && !importPerms {
+				continue
+			}
+			r, ok := res.Resource.(*codexrules.Rule)
+			if !ok {
+				continue
+			}
+			permCount += importPermissions(cfg, codexrules.ToPermissions([]*codexrules.Rule{r}), out)
+
 		case "plugin":
 			// Plugins can't be "imported" - they're installed via the tool
 			// Just count them for display
@@ -569,8 +601,8 @@ func runImportAll(out *output.Writer) error {
 		}
 	}
 
-	// Save config if we imported servers (not in dry-run mode)
-	if !importDryRun && serverCount > 0 {
+	// Save config if we imported servers or permissions (not in dry-run mode)
+	if !importDryRun && (serverCount > 0 || permCount > 0) {
 		if err := cfg.Save(); err != nil {
 			return fmt.Errorf("failed to save config: %w", err)
 		}
@@ -585,7 +617,7 @@ func runImportAll(out *output.Writer) error {
 		prefix = "Imported "
 	}
 
-	total := serverCount + commandCount + ruleCount + skillCount
+	total := serverCount + commandCount + ruleCount + skillCount + permCount
 	if serverCount > 0 {
 		out.Success("%s%d server(s)", prefix, serverCount)
 	}
@@ -598,6 +630,9 @@ func runImportAll(out *output.Writer) error {
 	if skillCount > 0 {
 		out.Success("%s%d skill(s)", prefix, skillCount)
 	}
+	if permCount > 0 {
+		out.Success("%s%d permission(s)", prefix, permCount)
+	}
 	if pluginCount > 0 {
 		out.Info("Found %d plugin(s) (plugins are managed by the tool, not imported)", pluginCount)
 	}
@@ -611,3 +646,31 @@ func runImportAll(out *output.Writer) error {
 
 	return nil
 }
+
+// importPermissions adds patterns from perms that the config doesn't have
+// yet and returns how many were (or, in dry-run mode, would be) added
+func importPermissions(cfg *config.Config, perms *permission.Permissions, out *output.Writer) int {
+	existing := make(map[permission.Rule]bool)
+	for _, r := range cfg.Permissions.Rules() {
+		existing[r] = true
+	}
+
+	count := 0
+	for _, r := range perms.Rules() {
+		if existing[r] {
+			continue
+		}
+		existing[r] = true
+		count++
+
+		if importDryRun {
+			out.Info("[dry-run] Would import %s permission %q", r.Decision, r.Pattern)
+			continue
+		}
+		if cfg.Permissions == nil {
+			cfg.Permissions = &permission.Permissions{}
+		}
+		cfg.Permissions.Add(r.Decision, r.Pattern)
+	}
+	return count
+}
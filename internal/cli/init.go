@@ -12,6 +12,7 @@ import (
 
 	"github.com/iheanyi/agentctl/pkg/config"
 	"github.com/iheanyi/agentctl/pkg/mcp"
+	"github.com/iheanyi/agentctl/pkg/schema"
 	"github.com/iheanyi/agentctl/pkg/sync"
 )
 
@@ -84,6 +85,7 @@ func runInit(cmd *cobra.Command, args []string) error {
 
 	// Create default config
 	cfg := &config.Config{
+		Schema:    schema.URL("config"),
 		Version:   "1",
 		Servers:   make(map[string]*mcp.Server),
 		Commands:  []string{},
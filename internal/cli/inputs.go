@@ -0,0 +1,194 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/huh"
+
+	"github.com/iheanyi/agentctl/pkg/mcp"
+	"github.com/iheanyi/agentctl/pkg/secrets"
+)
+
+// storeInputSecret stores a secret input value in the keychain. Tests
+// replace it to avoid touching the real keychain.
+var storeInputSecret = func(name, value string) error {
+	return secrets.NewStore().Set(name, value)
+}
+
+// parseInputFlags parses --input NAME=VALUE flags
+func parseInputFlags(flags []string) (map[string]string, error) {
+	values := make(map[string]string)
+	for _, f := range flags {
+		name, value, ok := strings.Cut(f, "=")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid --input %q: expected NAME=VALUE", f)
+		}
+		values[name] = value
+	}
+	return values, nil
+}
+
+// resolveInputs fills in a server's declared inputs. Values come from
+// --input flags first; the rest are prompted for when interactive.
+// Otherwise an input falls back to an environment reference ($NAME) when
+// the variable is set, then to its default. Required inputs left without a
+// value are reported together.
+func resolveInputs(server *mcp.Server, inputs []mcp.Input, provided map[string]string, interactive bool) error {
+	values := make(map[string]string)
+	var pending []mcp.Input
+	for _, in := range inputs {
+		if v, ok := provided[in.Name]; ok {
+			values[in.Name] = v
+		} else {
+			pending = append(pending, in)
+		}
+	}
+
+	if interactive && len(pending) > 0 {
+		prompted, err := promptInputs(server.Name, pending)
+		if err != nil {
+			return err
+		}
+		for name, v := range prompted {
+			values[name] = v
+		}
+		pending = nil
+	}
+
+	var missing []mcp.Input
+	for _, in := range pending {
+		switch {
+		case os.Getenv(in.Name) != "" && in.TargetOrDefault() == mcp.InputTargetEnv:
+			values[in.Name] = "$" + in.Name
+		case in.Default != "":
+			values[in.Name] = in.Default
+		case !in.Optional:
+			missing = append(missing, in)
+		}
+	}
+	if len(missing) > 0 {
+		return missingInputsError(server.Name, missing)
+	}
+
+	for _, in := range inputs {
+		value, ok := values[in.Name]
+		if !ok || value == "" {
+			// Drop placeholders for optional inputs left empty
+			if in.TargetOrDefault() == mcp.InputTargetHeader {
+				delete(server.Headers, in.Name)
+			} else {
+				delete(server.Env, in.Name)
+			}
+			continue
+		}
+		final, err := inputValue(server.Name, in, value)
+		if err != nil {
+			return err
+		}
+		server.SetInput(in, final)
+	}
+	return nil
+}
+
+// inputValue renders an input value and moves secrets into the keychain.
+// Values that are already references ($ENV_VAR, keychain:name) are kept.
+func inputValue(serverName string, in mcp.Input, value string) (string, error) {
+	if strings.HasPrefix(value, "keychain:") {
+		return value, nil
+	}
+	if strings.HasPrefix(value, "$") {
+		if in.Format != "" {
+			// Interpolate the variable into templates like "Bearer {value}"
+			name := strings.Trim(strings.TrimPrefix(value, "$"), "{}")
+			return in.Apply("${" + name + "}"), nil
+		}
+		return value, nil
+	}
+
+	value = in.Apply(value)
+	if !in.Secret {
+		return value, nil
+	}
+
+	key := inputSecretName(serverName, in.Name)
+	if err := storeInputSecret(key, value); err != nil {
+		return "", fmt.Errorf("failed to store %s in the keychain: %w\nSet it in your environment and pass --input %s='$%s' instead", in.Name, err, in.Name, in.Name)
+	}
+	return "keychain:" + key, nil
+}
+
+// inputSecretName returns the keychain entry name for a server input
+func inputSecretName(serverName, inputName string) string {
+	return serverName + "-" + strings.ToLower(strings.ReplaceAll(inputName, "_", "-"))
+}
+
+// missingInputsError lists the required inputs that have no value
+func missingInputsError(serverName string, missing []mcp.Input) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s needs %d input(s) that were not provided:\n", serverName, len(missing))
+	for _, in := range missing {
+		fmt.Fprintf(&b, "  %s (%s", in.Name, in.TargetOrDefault())
+		if in.Secret {
+			b.WriteString(", secret")
+		}
+		b.WriteString(")")
+		if in.Description != "" {
+			fmt.Fprintf(&b, " - %s", in.Description)
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("Pass them with --input NAME=VALUE, or export env inputs before running add")
+	return fmt.Errorf("%s", b.String())
+}
+
+// promptInputs asks for input values with a huh form. Secret inputs are
+// masked; leaving an input empty uses $NAME when it's set in the
+// environment.
+func promptInputs(serverName string, inputs []mcp.Input) (map[string]string, error) {
+	values := make([]string, len(inputs))
+	var fields []huh.Field
+	for i, in := range inputs {
+		in := in
+		values[i] = in.Default
+
+		desc := in.Description
+		fromEnv := os.Getenv(in.Name) != "" && in.TargetOrDefault() == mcp.InputTargetEnv
+		if fromEnv {
+			desc = strings.TrimSpace(desc + fmt.Sprintf(" (leave empty to use $%s)", in.Name))
+		}
+
+		field := huh.NewInput().
+			Title(in.Name).
+			Description(desc).
+			Value(&values[i]).
+			Validate(func(s string) error {
+				if s == "" && !in.Optional && !fromEnv {
+					return fmt.Errorf("%s is required", in.Name)
+				}
+				return nil
+			})
+		if in.Secret {
+			field = field.EchoMode(huh.EchoModePassword)
+		}
+		fields = append(fields, field)
+	}
+
+	form := huh.NewForm(huh.NewGroup(fields...).
+		Title(fmt.Sprintf("Configure %s", serverName)).
+		Description("Secrets are stored in your system keychain"))
+	if err := form.Run(); err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]string)
+	for i, in := range inputs {
+		v := values[i]
+		if v == "" && os.Getenv(in.Name) != "" && in.TargetOrDefault() == mcp.InputTargetEnv {
+			v = "$" + in.Name
+		}
+		result[in.Name] = v
+	}
+	return result, nil
+}
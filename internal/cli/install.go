@@ -14,9 +14,11 @@ import (
 	"github.com/iheanyi/agentctl/pkg/aliases"
 	"github.com/iheanyi/agentctl/pkg/builder"
 	"github.com/iheanyi/agentctl/pkg/config"
+	"github.com/iheanyi/agentctl/pkg/container"
 	"github.com/iheanyi/agentctl/pkg/lockfile"
 	"github.com/iheanyi/agentctl/pkg/mcp"
 	"github.com/iheanyi/agentctl/pkg/output"
+	"github.com/iheanyi/agentctl/pkg/registry"
 	"github.com/iheanyi/agentctl/pkg/sync"
 )
 
@@ -33,7 +35,8 @@ Scope:
   if it exists, otherwise to the global config (~/.config/agentctl/agentctl.json).
   Use --scope to explicitly choose local or global.
 
-  Local servers sync to workspace configs (.mcp.json, .cursor/mcp.json).
+  Local servers sync to workspace configs (.mcp.json, .cursor/mcp.json,
+  .vscode/mcp.json, ...).
   Global servers sync to global tool configs.
 
 MCP Transport Types:
@@ -45,16 +48,23 @@ Examples:
   # Interactive mode
   agentctl add
 
-  # Add from registry
+  # Add from an alias
   agentctl add figma
   agentctl add filesystem
 
+  # Add from the official MCP registry (optionally pinned to a version)
+  agentctl add io.github.owner/server
+  agentctl add io.github.owner/server@1.2.0
+
   # Add to local project config (explicit)
   agentctl add filesystem --scope local
 
   # Add to global config (explicit)
   agentctl add figma --scope global
 
+  # Add a server packaged as a container image (run with docker or podman)
+  agentctl add oci://ghcr.io/acme/weather-mcp:1.2.0
+
   # Add with explicit URL (http transport)
   agentctl add figma --url https://mcp.figma.com/mcp
 
@@ -65,6 +75,10 @@ Examples:
   agentctl add playwright --command npx --args "playwriter@latest"
   agentctl add fs --command npx --args "-y,@modelcontextprotocol/server-filesystem"
 
+  # Provide declared inputs (API keys, IDs) without prompting
+  agentctl add notion --input NOTION_TOKEN='$NOTION_TOKEN'
+  agentctl add slack --input SLACK_BOT_TOKEN=xoxb-... --input SLACK_TEAM_ID=T0123
+
   # Preview without adding
   agentctl add figma --dry-run`,
 	Args: cobra.MaximumNArgs(1),
@@ -84,6 +98,7 @@ var (
 	addDryRun      bool
 	addHeaders     []string
 	addScope       string
+	addInputs      []string
 )
 
 func init() {
@@ -101,6 +116,7 @@ func init() {
 	addCmd.Flags().BoolVar(&addDryRun, "dry-run", false, "Preview config without adding")
 	addCmd.Flags().StringArrayVarP(&addHeaders, "header", "H", nil, "HTTP headers (Key: Value)")
 	addCmd.Flags().StringVarP(&addScope, "scope", "s", "", "Config scope: local, global (default: local if .agentctl.json exists)")
+	addCmd.Flags().StringArrayVar(&addInputs, "input", nil, "Value for a declared input (NAME=VALUE, repeatable)")
 }
 
 func runAdd(cmd *cobra.Command, args []string) error {
@@ -131,6 +147,7 @@ func runAdd(cmd *cobra.Command, args []string) error {
 
 	var name string
 	var server *mcp.Server
+	var inputs []mcp.Input
 
 	// If no args provided, launch interactive mode
 	if len(args) == 0 && addCommand == "" && addURL == "" {
@@ -162,10 +179,14 @@ func runAdd(cmd *cobra.Command, args []string) error {
 			}
 		} else {
 			// Parse target as registry alias, URL, or git path
-			server, err = parseAddTarget(name)
+			server, inputs, err = parseAddTarget(name)
 			if err != nil {
 				return err
 			}
+			// Registry names resolve to a shorter server name
+			if _, exists := cfg.Servers[server.Name]; exists && server.Name != name {
+				return fmt.Errorf("server %q already exists - use 'agentctl remove %s' first", server.Name, server.Name)
+			}
 		}
 	}
 
@@ -174,6 +195,32 @@ func runAdd(cmd *cobra.Command, args []string) error {
 		server.Namespace = addNamespace
 	}
 
+	// Fill in the inputs the alias or registry entry declares (API keys,
+	// workspace IDs). A dry run only lists them.
+	provided, err := parseInputFlags(addInputs)
+	if err != nil {
+		return err
+	}
+	if len(inputs) > 0 && addDryRun {
+		out.Println("")
+		out.Println("Inputs:")
+		for _, in := range inputs {
+			kind := in.TargetOrDefault()
+			if in.Secret {
+				kind += ", secret"
+			}
+			out.Println("  %s (%s) %s", in.Name, kind, in.Description)
+		}
+	} else if len(inputs) > 0 {
+		if err := resolveInputs(server, inputs, provided, isInteractive()); err != nil {
+			if err == huh.ErrUserAborted {
+				showCancelHint("add")
+				return nil
+			}
+			return err
+		}
+	}
+
 	// Format and display the config that will be added
 	configJSON := formatMCPConfig(server.Name, server)
 
@@ -189,7 +236,7 @@ func runAdd(cmd *cobra.Command, args []string) error {
 	}
 
 	// Handle git sources: Prompt for Command (no clone/build)
-	if server.Source.Type == "git" && server.Command == "" {
+	if server.Source.Type == "git" && server.Command == "" && server.Container == nil {
 		out.Println("Adding %s (Source: %s)", server.Name, server.Source.URL)
 		out.Println("Please configure the launch command.")
 
@@ -239,6 +286,16 @@ func runAdd(cmd *cobra.Command, args []string) error {
 		_ = lf.Save()
 	}
 
+	// Pull or build the image now so the first start isn't slow
+	if server.Container != nil {
+		out.Println("")
+		out.Println("Preparing image %s...", container.Image(server))
+		if err := builder.New(cfg.CacheDir()).Image(server); err != nil {
+			out.Warning("Failed to prepare image: %v", err)
+			out.Info("Run 'agentctl update %s' to try again", server.Name)
+		}
+	}
+
 	// Sync to tools
 	if !addNoSync {
 		out.Println("")
@@ -531,8 +588,10 @@ func performScopedSync(cfg *config.Config, server *mcp.Server, scope config.Scop
 		adapters = sync.Detected()
 	}
 
-	servers := []*mcp.Server{server}
+	bridge := sync.BridgeFor(cfg)
+	vars := cfg.InterpContext()
 	syncedCount := 0
+	store, snap := sync.BeginSnapshot()
 
 	// Get project directory for workspace configs
 	projectDir := ""
@@ -560,13 +619,20 @@ func performScopedSync(cfg *config.Config, server *mcp.Server, scope config.Scop
 			continue
 		}
 
-		// Check transport compatibility
-		if server.Transport == mcp.TransportHTTP || server.Transport == mcp.TransportSSE {
-			supportsHTTP := toolName == "claude" || toolName == "claude-desktop"
-			if !supportsHTTP {
-				out.Println("  - %s (no HTTP/SSE support)", toolName)
-				continue
-			}
+		// Resolve variables for this tool, as sync does, and run
+		// containerized servers through their runtime
+		resolved := container.Command(vars.For(toolName, server.Scope).Server(server))
+
+		// Check transport compatibility. Remote servers reach stdio-only
+		// tools through 'agentctl mcp-proxy' when bridging is enabled.
+		servers, bridged := bridge.Servers(adapter, []*mcp.Server{resolved})
+		if sync.IsRemote(resolved) && !sync.SupportsRemoteServers(adapter) && len(bridged) == 0 {
+			out.Println("  - %s (no HTTP/SSE support; set settings.bridgeRemote to bridge)", toolName)
+			continue
+		}
+		via := ""
+		if len(bridged) > 0 {
+			via = " via mcp-proxy"
 		}
 
 		// Handle scoped sync
@@ -574,6 +640,9 @@ func performScopedSync(cfg *config.Config, server *mcp.Server, scope config.Scop
 			// Try to use workspace config if adapter supports it
 			if wa, ok := sync.AsWorkspaceAdapter(adapter); ok {
 				workspacePath := wa.WorkspaceConfigPath(projectDir)
+				if err := store.Capture(snap, toolName, workspacePath); err != nil {
+					out.Warning("  Could not snapshot %s: %v", workspacePath, err)
+				}
 
 				// Read existing workspace servers and merge
 				existingServers, _ := wa.ReadWorkspaceServers(projectDir)
@@ -583,7 +652,7 @@ func performScopedSync(cfg *config.Config, server *mcp.Server, scope config.Scop
 					out.Println("  x %s - %v", toolName, err)
 					continue
 				}
-				out.Println("  + %s (%s)", toolName, workspacePath)
+				out.Println("  + %s (%s)%s", toolName, workspacePath, via)
 				syncedCount++
 				continue
 			}
@@ -600,15 +669,21 @@ func performScopedSync(cfg *config.Config, server *mcp.Server, scope config.Scop
 		existingServers, _ := sa.ReadServers()
 		mergedServers := mergeServers(existingServers, servers)
 
+		if err := store.Capture(snap, toolName, configPath); err != nil {
+			out.Warning("  Could not snapshot %s: %v", configPath, err)
+		}
 		if err := sa.WriteServers(mergedServers); err != nil {
 			out.Println("  x %s - %v", toolName, err)
 			continue
 		}
 
-		out.Println("  + %s (%s)", toolName, configPath)
+		out.Println("  + %s (%s)%s", toolName, configPath, via)
 		syncedCount++
 	}
 
+	if _, err := sync.FinishSnapshot(store, snap); err != nil {
+		out.Warning("Could not save snapshot: %v", err)
+	}
 	return syncedCount
 }
 
@@ -628,7 +703,23 @@ func mergeServers(existing, new []*mcp.Server) []*mcp.Server {
 	return result
 }
 
-func parseAddTarget(target string) (*mcp.Server, error) {
+func parseAddTarget(target string) (*mcp.Server, []mcp.Input, error) {
+	// Check if it's a container image (before the version split, since
+	// digests contain an @)
+	for _, scheme := range []string{"oci://", "docker://"} {
+		if image, ok := strings.CutPrefix(target, scheme); ok {
+			return &mcp.Server{
+				Name: imageToName(image),
+				Source: mcp.Source{
+					Type: "oci",
+					URL:  image,
+				},
+				Transport: mcp.TransportStdio,
+				Container: &mcp.Container{Image: image},
+			}, nil, nil
+		}
+	}
+
 	// Check for version suffix (name@version)
 	var version string
 	if idx := strings.LastIndex(target, "@"); idx > 0 {
@@ -645,7 +736,7 @@ func parseAddTarget(target string) (*mcp.Server, error) {
 				URL:  target,
 			},
 			Transport: mcp.TransportStdio,
-		}, nil
+		}, nil, nil
 	}
 
 	// Check if it's a remote MCP URL (http/https)
@@ -658,7 +749,7 @@ func parseAddTarget(target string) (*mcp.Server, error) {
 			},
 			URL:       target,
 			Transport: mcp.TransportHTTP,
-		}, nil
+		}, nil, nil
 	}
 
 	// Determine variant preference from flags
@@ -669,10 +760,15 @@ func parseAddTarget(target string) (*mcp.Server, error) {
 		variantPref = "remote"
 	}
 
+	// Official registry names look like io.github.owner/server
+	if registry.IsRegistryName(target) {
+		return resolveRegistryServer(target, version, variantPref)
+	}
+
 	// Try to resolve as alias with variant
 	alias, resolvedVariant, ok := aliases.ResolveVariant(target, variantPref)
 	if !ok {
-		return nil, fmt.Errorf("unknown alias %q - use full git URL or 'agentctl search' to find servers", target)
+		return nil, nil, fmt.Errorf("unknown alias %q - use a registry name (e.g. io.github.owner/server), a full git URL, or 'agentctl search' to find servers", target)
 	}
 
 	server := &mcp.Server{
@@ -694,7 +790,7 @@ func parseAddTarget(target string) (*mcp.Server, error) {
 		if resolvedVariant != "" {
 			server.Name = target // Keep base name, variant is transparent
 		}
-		return server, nil
+		return server, alias.Inputs, nil
 	}
 
 	// Local server with stdio transport
@@ -717,13 +813,48 @@ func parseAddTarget(target string) (*mcp.Server, error) {
 	case "go":
 		server.Command = "go"
 		server.Args = []string{"run", alias.URL}
+	case "docker":
+		image := packageName
+		if version != "" {
+			image += ":" + version
+		}
+		server.Container = &mcp.Container{Image: image}
 	default:
 		// Default to node
 		server.Command = "npx"
 		server.Args = []string{"-y", packageName}
 	}
 
-	return server, nil
+	return server, alias.Inputs, nil
+}
+
+// resolveRegistryServer looks up a server in the official MCP registry and
+// converts its server.json into a server definition
+func resolveRegistryServer(name, version, variantPref string) (*mcp.Server, []mcp.Input, error) {
+	client := officialRegistry()
+	resp, err := client.Get(name, version)
+	if err != nil {
+		return nil, nil, err
+	}
+	if resp == nil {
+		if version != "" {
+			return nil, nil, fmt.Errorf("%s@%s not found in the MCP registry (%s)", name, version, client.BaseURL)
+		}
+		return nil, nil, fmt.Errorf("%s not found in the MCP registry (%s)", name, client.BaseURL)
+	}
+	return resp.Server.ToServer(variantPref)
+}
+
+// imageToName derives a server name from an image reference, e.g.
+// ghcr.io/acme/weather-mcp:1.2 is weather
+func imageToName(image string) string {
+	image, _, _ = strings.Cut(image, "@")
+	name := image[strings.LastIndex(image, "/")+1:]
+	name, _, _ = strings.Cut(name, ":")
+	name = strings.TrimSuffix(name, "-mcp")
+	name = strings.TrimSuffix(name, "-server")
+	name = strings.TrimPrefix(name, "mcp-")
+	return name
 }
 
 func pathToName(path string) string {
@@ -3,11 +3,13 @@ package cli
 import (
 	"fmt"
 	"os"
+	"strings"
 	"text/tabwriter"
 
 	"github.com/spf13/cobra"
 
 	"github.com/iheanyi/agentctl/pkg/agent"
+	"github.com/iheanyi/agentctl/pkg/codexrules"
 	"github.com/iheanyi/agentctl/pkg/command"
 	"github.com/iheanyi/agentctl/pkg/config"
 	"github.com/iheanyi/agentctl/pkg/discovery"
@@ -50,7 +52,7 @@ var (
 )
 
 func init() {
-	listCmd.Flags().StringVarP(&listType, "type", "t", "", "Filter by resource type (servers, commands, rules, skills, agents, hooks, plugins)")
+	listCmd.Flags().StringVarP(&listType, "type", "t", "", "Filter by resource type (servers, commands, rules, skills, agents, hooks, plugins, execrules)")
 	listCmd.Flags().StringVarP(&listProfile, "profile", "p", "", "List resources from specific profile")
 	listCmd.Flags().StringVarP(&listScope, "scope", "s", "", "Filter by scope: local, global, or all (default: all)")
 	listCmd.Flags().BoolVarP(&listNative, "native", "n", false, "Include resources from tool-native directories (.cursor/, .codex/, etc.)")
@@ -465,6 +467,42 @@ func runList(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	// List execution rules (only with --native flag since they're tool-native)
+	if listNative && (listType == "" || listType == "execrules") {
+		if execRules := filterNativeByType(nativeResources, "execrule", scope); len(execRules) > 0 {
+			if hasOutput {
+				fmt.Println()
+			}
+			fmt.Println("Execution Rules:")
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "  PATTERN\tSCOPE\tTOOL\tDECISION\tPATH")
+			for _, res := range execRules {
+				r, ok := res.Resource.(*codexrules.Rule)
+				if !ok {
+					continue
+				}
+				fmt.Fprintf(w, "  %s\t%s\t[%s]\t%s\t%s\n", res.Name, scopeToIndicator(res.Scope), res.Tool, r.Decision, res.Path)
+			}
+			w.Flush()
+			hasOutput = true
+		}
+	}
+
+	// List servers and resources left out by their "when" condition
+	if excluded := cfg.ExcludedFor(listExcludedType(), scope); len(excluded) > 0 {
+		if hasOutput {
+			fmt.Println()
+		}
+		fmt.Println("Excluded here:")
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "  NAME\tTYPE\tSCOPE\tREASON")
+		for _, e := range excluded {
+			fmt.Fprintf(w, "  %s\t%s\t%s\t%s\n", e.Name, e.Type, scopeToIndicator(e.Scope), e.Reason)
+		}
+		w.Flush()
+		hasOutput = true
+	}
+
 	if !hasOutput {
 		fmt.Println("No resources installed.")
 		fmt.Println("\nGet started:")
@@ -475,6 +513,18 @@ func runList(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// listExcludedType maps the --type filter to an exclusion type, or ""
+// for all types
+func listExcludedType() string {
+	switch listType {
+	case "":
+		return ""
+	case "servers", "commands", "rules", "skills", "agents":
+		return strings.TrimSuffix(listType, "s")
+	}
+	return "none"
+}
+
 // runListJSON outputs the list results as JSON
 func runListJSON(cfg *config.Config, scope config.Scope, nativeResources []*discovery.NativeResource, cwd string) error {
 	jw := output.NewJSONWriter()
@@ -728,6 +778,33 @@ func runListJSON(cfg *config.Config, scope config.Scope, nativeResources []*disc
 		}
 	}
 
+	// Get execution rules (only with --native flag)
+	if includeNative && (listType == "" || listType == "execrules") {
+		for _, res := range filterNativeByType(nativeResources, "execrule", scope) {
+			r, ok := res.Resource.(*codexrules.Rule)
+			if !ok {
+				continue
+			}
+			listOutput.ExecRules = append(listOutput.ExecRules, output.ExecRuleInfo{
+				Pattern:       res.Name,
+				Scope:         res.Scope,
+				Tool:          res.Tool,
+				Decision:      string(r.Decision),
+				Justification: r.Justification,
+				Path:          res.Path,
+			})
+		}
+	}
+
+	for _, e := range cfg.ExcludedFor(listExcludedType(), scope) {
+		listOutput.Excluded = append(listOutput.Excluded, output.ExcludedInfo{
+			Name:   e.Name,
+			Type:   e.Type,
+			Scope:  e.Scope,
+			Reason: e.Reason,
+		})
+	}
+
 	return jw.WriteSuccess(listOutput)
 }
 
@@ -0,0 +1,107 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/spf13/cobra"
+
+	"github.com/iheanyi/agentctl/pkg/config"
+	"github.com/iheanyi/agentctl/pkg/mcp"
+	"github.com/iheanyi/agentctl/pkg/mcpclient"
+	"github.com/iheanyi/agentctl/pkg/secrets"
+)
+
+var mcpProxyCmd = &cobra.Command{
+	Use:   "mcp-proxy <server>",
+	Short: "Relay stdio to a remote MCP server",
+	Long: `Run a remote (HTTP or SSE) server as a stdio server.
+
+MCP messages on stdin and stdout are relayed to the server's URL with its
+headers. Header values may reference secrets ($ENV_VAR or keychain:name).
+If the server has no Authorization header and an OAuth access token is
+stored as the secret oauth:<server>, it is sent as a bearer token.
+
+Sync writes entries running this command for tools that can only launch
+stdio servers when "bridgeRemote" is set in settings.
+
+Examples:
+  agentctl mcp-proxy sentry                        # Relay to the sentry server
+  agentctl mcp-proxy --project ~/src/app figma     # Use the project's config
+  agentctl secret set oauth:sentry                 # Store an OAuth token`,
+	Args: cobra.ExactArgs(1),
+	RunE: runMCPProxy,
+}
+
+var mcpProxyProject string
+
+// oauthToken returns the OAuth access token stored for a server. Tests
+// replace it to avoid touching the real keychain.
+var oauthToken = func(server string) (string, error) {
+	return secrets.NewStore().Get("oauth:" + server)
+}
+
+func init() {
+	mcpProxyCmd.Flags().StringVar(&mcpProxyProject, "project", "", "Project directory to load config from (default: current directory)")
+}
+
+func runMCPProxy(cmd *cobra.Command, args []string) error {
+	var cfg *config.Config
+	var err error
+	if mcpProxyProject != "" {
+		cfg, err = config.LoadProjectConfig(mcpProxyProject)
+	} else {
+		cfg, err = config.LoadWithProject()
+	}
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	server, err := proxyServer(cfg, args[0])
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	return mcpclient.ProxyStdio(ctx, server)
+}
+
+// proxyServer returns the named remote server with its variables, secrets
+// and OAuth token resolved
+func proxyServer(cfg *config.Config, name string) (*mcp.Server, error) {
+	server, ok := cfg.Servers[name]
+	if !ok {
+		return nil, fmt.Errorf("server %q not found", name)
+	}
+	if server.Transport != mcp.TransportHTTP && server.Transport != mcp.TransportSSE {
+		return nil, fmt.Errorf("server %q is not an HTTP or SSE server", name)
+	}
+
+	resolved := cfg.InterpContext().For("", server.Scope).Server(server)
+	headers, err := secrets.ResolveEnv(resolved.Headers)
+	if err != nil {
+		return nil, fmt.Errorf("server %q: %w", name, err)
+	}
+	if !hasHeader(headers, "Authorization") {
+		if token, err := oauthToken(name); err == nil && token != "" {
+			headers["Authorization"] = "Bearer " + token
+		}
+	}
+	resolved.Headers = headers
+	return resolved, nil
+}
+
+// hasHeader reports whether headers sets key, ignoring case
+func hasHeader(headers map[string]string, key string) bool {
+	for k := range headers {
+		if strings.EqualFold(k, key) {
+			return true
+		}
+	}
+	return false
+}
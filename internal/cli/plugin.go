@@ -0,0 +1,358 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/iheanyi/agentctl/pkg/config"
+	"github.com/iheanyi/agentctl/pkg/discovery"
+	"github.com/iheanyi/agentctl/pkg/output"
+	"github.com/iheanyi/agentctl/pkg/plugin"
+)
+
+var pluginCmd = &cobra.Command{
+	Use:   "plugin",
+	Short: "Manage tool plugins and marketplaces",
+	Long: `Manage the plugins your tools should have installed.
+
+Claude Code plugins come from marketplaces and are written as
+name@marketplace. OpenCode plugins are npm packages, written with an
+npm: prefix. Run 'agentctl sync' to apply changes to your tools.
+
+Examples:
+  agentctl plugin add commit-commands@claude-plugins-official
+  agentctl plugin add npm:opencode-wakatime --version 1.2.0
+  agentctl plugin add code-review@team-tools --disabled
+  agentctl plugin remove commit-commands@claude-plugins-official
+  agentctl plugin list
+  agentctl plugin marketplace add team-tools acme/claude-plugins`,
+}
+
+var pluginAddCmd = &cobra.Command{
+	Use:   "add <plugin>",
+	Short: "Add a plugin",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runPluginAdd,
+}
+
+var pluginRemoveCmd = &cobra.Command{
+	Use:     "remove <plugin>",
+	Aliases: []string{"rm"},
+	Short:   "Remove a plugin",
+	Args:    cobra.ExactArgs(1),
+	RunE:    runPluginRemove,
+}
+
+var pluginListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "List plugins and their install status",
+	RunE:    runPluginList,
+}
+
+var pluginMarketplaceCmd = &cobra.Command{
+	Use:   "marketplace",
+	Short: "Manage plugin marketplaces",
+	Long: `Manage the marketplaces Claude Code installs plugins from.
+
+A source can be a GitHub repository (owner/repo), a git URL, or a local
+directory.
+
+Examples:
+  agentctl plugin marketplace add team-tools acme/claude-plugins
+  agentctl plugin marketplace add internal https://git.example.com/plugins.git
+  agentctl plugin marketplace remove team-tools`,
+}
+
+var pluginMarketplaceAddCmd = &cobra.Command{
+	Use:   "add <name> <source>",
+	Short: "Add a plugin marketplace",
+	Args:  cobra.ExactArgs(2),
+	RunE:  runPluginMarketplaceAdd,
+}
+
+var pluginMarketplaceRemoveCmd = &cobra.Command{
+	Use:     "remove <name>",
+	Aliases: []string{"rm"},
+	Short:   "Remove a plugin marketplace",
+	Args:    cobra.ExactArgs(1),
+	RunE:    runPluginMarketplaceRemove,
+}
+
+var (
+	pluginVersion  string
+	pluginDisabled bool
+	pluginScope    string
+)
+
+func init() {
+	pluginCmd.AddCommand(pluginAddCmd)
+	pluginCmd.AddCommand(pluginRemoveCmd)
+	pluginCmd.AddCommand(pluginListCmd)
+	pluginCmd.AddCommand(pluginMarketplaceCmd)
+	pluginMarketplaceCmd.AddCommand(pluginMarketplaceAddCmd)
+	pluginMarketplaceCmd.AddCommand(pluginMarketplaceRemoveCmd)
+
+	pluginAddCmd.Flags().StringVar(&pluginVersion, "version", "", "Pin the plugin to a version")
+	pluginAddCmd.Flags().BoolVar(&pluginDisabled, "disabled", false, "Add the plugin but keep it disabled")
+	for _, c := range []*cobra.Command{pluginAddCmd, pluginRemoveCmd, pluginMarketplaceAddCmd, pluginMarketplaceRemoveCmd} {
+		c.Flags().StringVarP(&pluginScope, "scope", "s", "global", "Config scope: local or global")
+	}
+}
+
+// loadPluginConfig loads the config for the --scope flag
+func loadPluginConfig() (*config.Config, config.Scope, error) {
+	scope, err := config.ParseScope(pluginScope)
+	if err != nil {
+		return nil, "", err
+	}
+	if scope == config.ScopeAll {
+		scope = config.ScopeGlobal
+	}
+	cfg, err := config.LoadScoped(scope)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to load config: %w", err)
+	}
+	return cfg, scope, nil
+}
+
+func runPluginAdd(cmd *cobra.Command, args []string) error {
+	out := output.DefaultWriter()
+
+	p, err := plugin.Parse(args[0])
+	if err != nil {
+		return err
+	}
+	if pluginVersion != "" {
+		p.Version = pluginVersion
+	}
+	p.Disabled = pluginDisabled
+
+	cfg, scope, err := loadPluginConfig()
+	if err != nil {
+		return err
+	}
+
+	if !p.IsNPM() {
+		if _, ok := cfg.Marketplaces[p.Marketplace]; !ok {
+			out.Warning("Marketplace %q is not configured; add it with 'agentctl plugin marketplace add %s <source>' unless Claude Code already knows it", p.Marketplace, p.Marketplace)
+		}
+	}
+
+	if existing := plugin.Find(cfg.Plugins, p.ID()); existing != nil {
+		*existing = *p
+		out.Success("Updated plugin %q", p.ID())
+	} else {
+		cfg.Plugins = append(cfg.Plugins, p)
+		out.Success("Added plugin %q", p.ID())
+	}
+
+	if err := cfg.SaveScoped(scope); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+
+	out.Info("Run 'agentctl sync' to install it in your tools")
+	return nil
+}
+
+func runPluginRemove(cmd *cobra.Command, args []string) error {
+	out := output.DefaultWriter()
+
+	cfg, scope, err := loadPluginConfig()
+	if err != nil {
+		return err
+	}
+
+	id := args[0]
+	if p, err := plugin.Parse(id); err == nil {
+		id = p.ID()
+	}
+
+	var kept []*plugin.Plugin
+	for _, p := range cfg.Plugins {
+		if p.ID() != id {
+			kept = append(kept, p)
+		}
+	}
+	if len(kept) == len(cfg.Plugins) {
+		return fmt.Errorf("plugin %q is not configured", args[0])
+	}
+	cfg.Plugins = kept
+
+	if err := cfg.SaveScoped(scope); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+
+	out.Success("Removed plugin %q", id)
+	out.Info("Run 'agentctl sync' to remove it from your tools")
+	return nil
+}
+
+func runPluginMarketplaceAdd(cmd *cobra.Command, args []string) error {
+	out := output.DefaultWriter()
+
+	m, err := plugin.ParseMarketplace(args[1])
+	if err != nil {
+		return err
+	}
+
+	cfg, scope, err := loadPluginConfig()
+	if err != nil {
+		return err
+	}
+
+	if cfg.Marketplaces == nil {
+		cfg.Marketplaces = make(map[string]*plugin.Marketplace)
+	}
+	cfg.Marketplaces[args[0]] = m
+
+	if err := cfg.SaveScoped(scope); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+
+	out.Success("Added marketplace %q (%s: %s)", args[0], m.Source, m.Location())
+	return nil
+}
+
+func runPluginMarketplaceRemove(cmd *cobra.Command, args []string) error {
+	out := output.DefaultWriter()
+
+	cfg, scope, err := loadPluginConfig()
+	if err != nil {
+		return err
+	}
+
+	if _, ok := cfg.Marketplaces[args[0]]; !ok {
+		return fmt.Errorf("marketplace %q is not configured", args[0])
+	}
+	delete(cfg.Marketplaces, args[0])
+
+	for _, p := range cfg.Plugins {
+		if p.Marketplace == args[0] {
+			out.Warning("Plugin %q still references marketplace %q", p.ID(), args[0])
+		}
+	}
+
+	if err := cfg.SaveScoped(scope); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+
+	out.Success("Removed marketplace %q", args[0])
+	return nil
+}
+
+func runPluginList(cmd *cobra.Command, args []string) error {
+	cfg, err := config.LoadWithProject()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	// Index installed plugins by tool and name
+	installed := make(map[string]*discovery.Plugin)
+	claudePlugins, _ := discovery.LoadClaudePlugins()
+	openCodePlugins, _ := discovery.LoadOpenCodePlugins()
+	for _, p := range append(claudePlugins, openCodePlugins...) {
+		installed[p.Tool+"/"+p.Name] = p
+	}
+
+	// Plugins declared in the project config are shown as local
+	var projectPlugins []*plugin.Plugin
+	if cfg.ProjectPath != "" {
+		if local, err := config.LoadScoped(config.ScopeLocal); err == nil {
+			projectPlugins = local.Plugins
+		}
+	}
+
+	var infos []output.PluginInfo
+	for _, p := range cfg.Plugins {
+		tool := "claude"
+		if p.IsNPM() {
+			tool = "opencode"
+		}
+		info := output.PluginInfo{
+			Name:    p.ID(),
+			Scope:   string(config.ScopeGlobal),
+			Tool:    tool,
+			Version: p.Version,
+			Status:  pluginStatus(p, installed[tool+"/"+p.Name]),
+		}
+		if plugin.Find(projectPlugins, p.ID()) != nil {
+			info.Scope = string(config.ScopeLocal)
+		}
+		if inst := installed[tool+"/"+p.Name]; inst != nil {
+			info.Path = inst.Path
+		}
+		infos = append(infos, info)
+	}
+
+	names := make([]string, 0, len(cfg.Marketplaces))
+	for name := range cfg.Marketplaces {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	if JSONOutput {
+		var marketplaces []output.MarketplaceInfo
+		for _, name := range names {
+			m := cfg.Marketplaces[name]
+			marketplaces = append(marketplaces, output.MarketplaceInfo{Name: name, Source: m.Source, Location: m.Location()})
+		}
+		return output.NewJSONWriter().WriteSuccess(output.PluginListOutput{Plugins: infos, Marketplaces: marketplaces})
+	}
+
+	if len(infos) == 0 && len(names) == 0 {
+		fmt.Println("No plugins configured.")
+		fmt.Println("\nGet started:")
+		fmt.Println("  agentctl plugin add <name>@<marketplace>  # Add a Claude Code plugin")
+		fmt.Println("  agentctl plugin add npm:<package>         # Add an OpenCode plugin")
+		return nil
+	}
+
+	if len(infos) > 0 {
+		fmt.Println("Plugins:")
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "  NAME\tSCOPE\tTOOL\tVERSION\tSTATUS")
+		for _, info := range infos {
+			version := info.Version
+			if version == "" {
+				version = "latest"
+			}
+			fmt.Fprintf(w, "  %s\t%s\t[%s]\t%s\t%s\n", info.Name, scopeToIndicator(info.Scope), info.Tool, version, info.Status)
+		}
+		w.Flush()
+	}
+
+	if len(names) > 0 {
+		if len(infos) > 0 {
+			fmt.Println()
+		}
+		fmt.Println("Marketplaces:")
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "  NAME\tSOURCE\tLOCATION")
+		for _, name := range names {
+			m := cfg.Marketplaces[name]
+			fmt.Fprintf(w, "  %s\t%s\t%s\n", name, m.Source, m.Location())
+		}
+		w.Flush()
+	}
+
+	return nil
+}
+
+// pluginStatus compares a configured plugin with what the tool has installed
+func pluginStatus(p *plugin.Plugin, installed *discovery.Plugin) string {
+	switch {
+	case p.Disabled:
+		return "disabled"
+	case installed == nil:
+		return "not installed"
+	case p.Version != "" && installed.Version != "" && installed.Version != p.Version:
+		return fmt.Sprintf("installed %s (pinned %s)", installed.Version, p.Version)
+	default:
+		return "installed"
+	}
+}
@@ -8,6 +8,9 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/iheanyi/agentctl/internal/tui"
+	"github.com/iheanyi/agentctl/pkg/config"
+	"github.com/iheanyi/agentctl/pkg/schema"
+	"github.com/iheanyi/agentctl/pkg/sync"
 )
 
 var (
@@ -25,7 +28,8 @@ var rootCmd = &cobra.Command{
 	Long: `agentctl manages MCP servers, commands, rules, prompts, and skills
 across multiple agentic frameworks and developer tools.
 
-Supported tools: Claude Code, Cursor, Codex, OpenCode, Cline, Windsurf, Zed, Continue
+Supported tools: Claude Code, Cursor, Codex, OpenCode, Cline, Windsurf, Zed, Continue, VS Code,
+Roo Code, Goose, Amp, Kiro
 
 Examples:
   agentctl                             # Launch interactive TUI
@@ -48,8 +52,15 @@ func Execute() {
 }
 
 func init() {
+	// Adapters for tools agentctl doesn't know, provided by
+	// agentctl-adapter-<name> executables
+	cobra.OnInitialize(func() {
+		sync.LoadExternalAdapters(config.DefaultConfigDir())
+	})
+
 	// Global flags
 	rootCmd.PersistentFlags().BoolVar(&JSONOutput, "json", false, "Output results as JSON (machine-parseable)")
+	rootCmd.PersistentFlags().BoolVar(&schema.Strict, "strict", false, "Reject config and resource files that don't match their schema")
 
 	rootCmd.AddCommand(versionCmd)
 	rootCmd.AddCommand(addCmd) // Primary command for adding MCP servers (alias: install)
@@ -57,6 +68,7 @@ func init() {
 	rootCmd.AddCommand(listCmd)
 	rootCmd.AddCommand(syncCmd)
 	rootCmd.AddCommand(aliasCmd)
+	rootCmd.AddCommand(sourceCmd)
 	rootCmd.AddCommand(profileCmd)
 	rootCmd.AddCommand(initCmd)
 	rootCmd.AddCommand(doctorCmd)
@@ -72,6 +84,10 @@ func init() {
 	rootCmd.AddCommand(uiCmd)
 	rootCmd.AddCommand(daemonCmd)
 	rootCmd.AddCommand(backupCmd)
+	rootCmd.AddCommand(pluginCmd)
+	rootCmd.AddCommand(schemaCmd)
+	rootCmd.AddCommand(mcpProxyCmd)
+	rootCmd.AddCommand(serveCmd)
 }
 
 // runRoot handles the default behavior when no subcommand is given
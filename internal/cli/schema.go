@@ -0,0 +1,155 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/iheanyi/agentctl/pkg/agent"
+	"github.com/iheanyi/agentctl/pkg/command"
+	"github.com/iheanyi/agentctl/pkg/config"
+	"github.com/iheanyi/agentctl/pkg/output"
+	"github.com/iheanyi/agentctl/pkg/profile"
+	"github.com/iheanyi/agentctl/pkg/rule"
+	"github.com/iheanyi/agentctl/pkg/schema"
+	"github.com/iheanyi/agentctl/pkg/skill"
+)
+
+// schemaTypes lists the published schemas in display order
+var schemaTypes = []struct {
+	Name     string
+	Describe string
+	Generate func() *schema.Schema
+}{
+	{"config", "agentctl.json and .agentctl.json", config.JSONSchema},
+	{"profile", "profile files", profile.JSONSchema},
+	{"command", "command JSON files", command.JSONSchema},
+	{"rule", "rule frontmatter", rule.FrontmatterSchema},
+	{"agent", "agent frontmatter", agent.FrontmatterSchema},
+	{"skill", "SKILL.md frontmatter", skill.FrontmatterSchema},
+}
+
+var schemaWriteDir string
+
+var schemaCmd = &cobra.Command{
+	Use:   "schema [type]",
+	Short: "Print JSON Schemas for agentctl files",
+	Long: `Print the JSON Schema of an agentctl file type.
+
+Schemas are generated from the Go types agentctl loads files into, so
+they always match what it accepts. Point your editor at them for
+autocompletion and typo checking, or add "$schema" to a JSON file
+('agentctl init' does this for new configs).
+
+Frontmatter schemas (rule, agent, skill) describe the YAML block at the
+top of markdown files.
+
+Run any command with --strict to validate files against their schema
+as they load, failing with file:line:column errors on unknown fields.
+
+Examples:
+  agentctl schema                      # List schema types and URLs
+  agentctl schema config               # Print the config schema
+  agentctl schema --write schemas      # Write every schema to a directory`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runSchema,
+}
+
+func init() {
+	schemaCmd.Flags().StringVar(&schemaWriteDir, "write", "", "Write all schemas as <type>.schema.json into a directory")
+}
+
+// SchemaInfo describes a published schema for JSON output
+type SchemaInfo struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	URL         string `json:"url"`
+}
+
+func runSchema(cmd *cobra.Command, args []string) error {
+	if schemaWriteDir != "" {
+		written, err := writeSchemas(schemaWriteDir)
+		if err != nil {
+			return err
+		}
+		if JSONOutput {
+			return output.NewJSONWriter().WriteSuccess(map[string][]string{"written": written})
+		}
+		for _, path := range written {
+			fmt.Printf("Wrote %s\n", path)
+		}
+		return nil
+	}
+
+	if len(args) == 1 {
+		s, err := lookupSchema(args[0])
+		if err != nil {
+			return err
+		}
+		data, err := schemaJSON(s)
+		if err != nil {
+			return err
+		}
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+
+	infos := make([]SchemaInfo, len(schemaTypes))
+	for i, t := range schemaTypes {
+		infos[i] = SchemaInfo{Name: t.Name, Description: t.Describe, URL: schema.URL(t.Name)}
+	}
+	if JSONOutput {
+		return output.NewJSONWriter().WriteSuccess(infos)
+	}
+	fmt.Println("Schema types:")
+	for _, info := range infos {
+		fmt.Printf("  %-8s %s\n           %s\n", info.Name, info.Description, info.URL)
+	}
+	fmt.Println("\nPrint one with 'agentctl schema <type>'.")
+	return nil
+}
+
+// lookupSchema returns the named schema
+func lookupSchema(name string) (*schema.Schema, error) {
+	names := make([]string, len(schemaTypes))
+	for i, t := range schemaTypes {
+		if t.Name == name {
+			return t.Generate(), nil
+		}
+		names[i] = t.Name
+	}
+	return nil, fmt.Errorf("unknown schema type %q (available: %s)", name, strings.Join(names, ", "))
+}
+
+// schemaJSON encodes a schema the way it's published
+func schemaJSON(s *schema.Schema) ([]byte, error) {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// writeSchemas writes every schema into dir and returns the paths written
+func writeSchemas(dir string) ([]string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	var written []string
+	for _, t := range schemaTypes {
+		data, err := schemaJSON(t.Generate())
+		if err != nil {
+			return nil, err
+		}
+		path := filepath.Join(dir, t.Name+".schema.json")
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			return nil, err
+		}
+		written = append(written, path)
+	}
+	return written, nil
+}
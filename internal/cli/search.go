@@ -2,28 +2,39 @@ package cli
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/spf13/cobra"
 
 	"github.com/iheanyi/agentctl/pkg/aliases"
+	"github.com/iheanyi/agentctl/pkg/config"
 	"github.com/iheanyi/agentctl/pkg/output"
 	"github.com/iheanyi/agentctl/pkg/registry"
+	"github.com/iheanyi/agentctl/pkg/source"
 )
 
 var searchCmd = &cobra.Command{
 	Use:   "search <query>",
 	Short: "Search for MCP servers",
-	Long: `Search for MCP servers in bundled aliases (verified sources).
+	Long: `Search for MCP servers in bundled aliases and the official MCP registry.
 
 Bundled aliases point to official and verified MCP server sources,
 primarily from github.com/modelcontextprotocol/servers (Anthropic's official repo).
+Registry results come from registry.modelcontextprotocol.io and install by
+their full name (e.g. agentctl add io.github.owner/server).
+
+Aliases and resources from team sources ('agentctl source') are included.
 
 Use --community to also search mcp.so (third-party, unverified).
 
+Point at a private registry mirror with settings.registry.url in your config
+or the AGENTCTL_REGISTRY_URL environment variable.
+
 Examples:
-  agentctl search filesystem         # Search verified aliases
+  agentctl search filesystem         # Search aliases and the MCP registry
   agentctl search github             # Search for GitHub integrations
-  agentctl search database --community  # Include community registry`,
+  agentctl search database --community  # Include community registry
+  agentctl search slack --offline    # Only search bundled aliases`,
 	Args: cobra.ExactArgs(1),
 	RunE: runSearch,
 }
@@ -31,26 +42,79 @@ Examples:
 var (
 	searchLimit     int
 	searchCommunity bool
+	searchOffline   bool
 )
 
 func init() {
 	searchCmd.Flags().IntVarP(&searchLimit, "limit", "l", 10, "Maximum number of results")
 	searchCmd.Flags().BoolVar(&searchCommunity, "community", false, "Also search mcp.so (third-party, unverified)")
+	searchCmd.Flags().BoolVar(&searchOffline, "offline", false, "Only search bundled aliases")
+}
+
+// registrySettings returns the registry settings from the global config
+func registrySettings() config.RegistryConfig {
+	cfg, err := config.Load()
+	if err != nil || cfg.Settings.Registry == nil {
+		return config.RegistryConfig{}
+	}
+	return *cfg.Settings.Registry
+}
+
+// officialRegistry returns a client for the configured official MCP
+// registry (or mirror)
+func officialRegistry() *registry.OfficialClient {
+	client := registry.NewOfficialClient()
+	client.BaseURL = registry.ResolveURL(registry.EnvRegistryURL, registrySettings().URL, registry.DefaultOfficialURL)
+	return client
+}
+
+// communityRegistry returns a client for the configured mcp.so API
+func communityRegistry() *registry.MCPSoClient {
+	client := registry.NewMCPSoClient()
+	client.BaseURL = registry.ResolveURL(registry.EnvCommunityURL, registrySettings().CommunityURL, registry.DefaultMCPSoURL)
+	return client
 }
 
 func runSearch(cmd *cobra.Command, args []string) error {
 	query := args[0]
 	out := output.DefaultWriter()
 
-	// Search bundled aliases (verified sources)
-	out.Println("Verified aliases (from official sources):")
-	aliasMatches := aliases.Search(query)
-	if len(aliasMatches) == 0 {
+	// Bundled aliases (verified sources) come first, then registry servers
+	var results []registry.Result
+	for _, a := range aliases.Search(query) {
+		origin := "alias"
+		if a.Source != aliases.OriginBundled {
+			origin = fmt.Sprintf("alias (%s)", a.Source)
+		}
+		results = append(results, registry.Result{Name: a.Name, Description: a.Description, Source: origin})
+	}
+
+	if !searchOffline {
+		registryResults, err := officialRegistry().SearchServers(query, searchLimit)
+		if err != nil {
+			out.Warning("Could not search the MCP registry: %v", err)
+		}
+		results = append(results, registryResults...)
+	}
+
+	out.Println("Verified aliases and MCP registry:")
+	if len(results) == 0 {
 		out.Println("  No matches found")
 	} else {
-		table := output.NewTable("Name", "Description", "Runtime")
-		for _, a := range aliasMatches {
-			table.AddRow(a.Name, a.Description, a.Runtime)
+		table := output.NewTable("Name", "Description", "Version", "Source")
+		for _, r := range results {
+			table.AddRow(r.Name, truncateDescription(r.Description), r.Version, r.Source)
+		}
+		table.Render()
+	}
+
+	// Commands, rules, skills and agents shipped by team sources
+	if resources := searchSourceResources(query); len(resources) > 0 {
+		out.Println("")
+		out.Println("Team source resources:")
+		table := output.NewTable("Name", "Type", "Source")
+		for _, r := range resources {
+			table.AddRow(r[0], r[1], r[2])
 		}
 		table.Render()
 	}
@@ -62,7 +126,7 @@ func runSearch(cmd *cobra.Command, args []string) error {
 		out.Warning("These are third-party servers. Review source code before installing.")
 		out.Println("")
 
-		client := registry.NewMCPSoClient()
+		client := communityRegistry()
 		results, err := client.Search(query)
 		if err != nil {
 			out.Warning("Could not search mcp.so: %v", err)
@@ -78,11 +142,7 @@ func runSearch(cmd *cobra.Command, args []string) error {
 				if count >= searchLimit {
 					break
 				}
-				desc := r.Description
-				if len(desc) > 50 {
-					desc = desc[:47] + "..."
-				}
-				table.AddRow(r.Name, desc, r.Author)
+				table.AddRow(r.Name, truncateDescription(r.Description), r.Author)
 				count++
 			}
 			table.Render()
@@ -104,3 +164,32 @@ func runSearch(cmd *cobra.Command, args []string) error {
 
 	return nil
 }
+
+// truncateDescription shortens a description to fit a table column
+func truncateDescription(desc string) string {
+	if len(desc) > 50 {
+		return desc[:47] + "..."
+	}
+	return desc
+}
+
+// searchSourceResources returns the name, type and source of resources in
+// configured sources whose name matches query
+func searchSourceResources(query string) [][3]string {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil
+	}
+
+	query = strings.ToLower(query)
+	cacheDir := config.DefaultCacheDir()
+	var matches [][3]string
+	for _, src := range source.Sorted(cfg.Settings.Sources) {
+		for _, r := range source.ListResources(src.Dir(cacheDir)) {
+			if strings.Contains(strings.ToLower(r.Name), query) {
+				matches = append(matches, [3]string{r.Name, r.Type, src.Name})
+			}
+		}
+	}
+	return matches
+}
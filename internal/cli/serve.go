@@ -0,0 +1,144 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"reflect"
+	"syscall"
+	"time"
+
+	mcpsdk "github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/spf13/cobra"
+
+	"github.com/iheanyi/agentctl/pkg/config"
+	"github.com/iheanyi/agentctl/pkg/gateway"
+)
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve all configured MCP servers as one",
+	Long: `Run an MCP server that aggregates every enabled server.
+
+Tools and prompts of each server are exposed as <namespace>__<name>, using
+the server's namespace or, without one, its name. Resources keep their URIs.
+Servers start the first time a client lists or calls something, and one
+that exits is started again the next time it's used.
+
+The active profile's servers are served too, and its "disabled" servers
+left out. A profile's "tools" list limits which tools are exposed, by
+prefixed name or glob:
+
+  "tools": ["github__*", "filesystem__read_file"]
+
+Config and profile changes, including 'agentctl profile switch', are picked
+up while serving, so the editor doesn't need restarting.
+
+Point a tool at the gateway instead of syncing each server to it:
+
+  {"command": "agentctl", "args": ["serve"]}
+
+Examples:
+  agentctl serve                            # Serve over stdio
+  agentctl serve --http localhost:7331      # Serve streamable HTTP at /mcp
+  agentctl serve --project ~/src/app        # Include the project's servers`,
+	Args: cobra.NoArgs,
+	RunE: runServe,
+}
+
+var (
+	serveHTTP    string
+	serveProject string
+	serveReload  time.Duration
+)
+
+func init() {
+	serveCmd.Flags().StringVar(&serveHTTP, "http", "", "Serve streamable HTTP on this address instead of stdio")
+	serveCmd.Flags().StringVar(&serveProject, "project", "", "Project directory to load config from (default: current directory)")
+	serveCmd.Flags().DurationVar(&serveReload, "reload", 2*time.Second, "How often to check for config changes (0 disables)")
+}
+
+func runServe(cmd *cobra.Command, args []string) error {
+	spec, err := loadServeSpec()
+	if err != nil {
+		return err
+	}
+
+	g := gateway.New(spec, Version)
+	g.ErrorLog = os.Stderr
+	defer g.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if serveReload > 0 {
+		go watchServeSpec(ctx, g, spec)
+	}
+
+	if serveHTTP == "" {
+		return g.Run(ctx, &mcpsdk.StdioTransport{})
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/mcp", g.Handler())
+	srv := &http.Server{Addr: serveHTTP, Handler: mux}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = srv.Shutdown(shutdownCtx)
+	}()
+	fmt.Fprintf(os.Stderr, "Serving MCP on http://%s/mcp\n", serveHTTP)
+	if err := srv.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// loadServeSpec loads the config and returns what the gateway serves
+func loadServeSpec() (gateway.Spec, error) {
+	var cfg *config.Config
+	var err error
+	if serveProject != "" {
+		cfg, err = config.LoadProjectConfig(serveProject)
+	} else {
+		cfg, err = config.LoadWithProject()
+	}
+	if err != nil {
+		return gateway.Spec{}, fmt.Errorf("failed to load config: %w", err)
+	}
+	return gateway.SpecFor(cfg)
+}
+
+// watchServeSpec polls the config and updates the gateway when what it
+// serves changes. Load errors are reported once and the last good spec
+// kept.
+func watchServeSpec(ctx context.Context, g *gateway.Gateway, last gateway.Spec) {
+	var lastErr string
+	ticker := time.NewTicker(serveReload)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		spec, err := loadServeSpec()
+		if err != nil {
+			if err.Error() != lastErr {
+				fmt.Fprintf(os.Stderr, "Reload failed: %v\n", err)
+				lastErr = err.Error()
+			}
+			continue
+		}
+		lastErr = ""
+		if reflect.DeepEqual(spec, last) {
+			continue
+		}
+		g.Update(ctx, spec)
+		last = spec
+	}
+}
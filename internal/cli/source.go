@@ -0,0 +1,287 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/iheanyi/agentctl/pkg/config"
+	"github.com/iheanyi/agentctl/pkg/output"
+	"github.com/iheanyi/agentctl/pkg/source"
+)
+
+var sourceCmd = &cobra.Command{
+	Use:   "source",
+	Short: "Manage team alias and resource sources",
+	Long: `Manage sources of aliases and resources shared by your team.
+
+A source is a git repository, an HTTP URL or a local directory laid out
+like the agentctl config directory: an aliases.json plus optional
+commands/, rules/, skills/ and agents/ directories. HTTP sources may serve
+either an aliases.json or a .tar.gz archive of such a directory.
+
+Source aliases show up in 'agentctl search' and 'agentctl alias list' and
+can be installed with 'agentctl add'. Source commands, rules, skills and
+agents are synced alongside your own. Your own aliases and resources win
+over a source's; when sources conflict, the higher priority wins.
+
+Examples:
+  agentctl source add platform git@github.com:acme/agentctl-platform.git --priority 10
+  agentctl source add vetted https://tools.acme.dev/agentctl/aliases.json
+  agentctl source add local-team ~/src/team-agentctl
+  agentctl source update
+  agentctl source list
+  agentctl source remove vetted`,
+}
+
+var sourceAddCmd = &cobra.Command{
+	Use:   "add <name> <url>",
+	Short: "Add a source and fetch it",
+	Args:  cobra.ExactArgs(2),
+	RunE:  runSourceAdd,
+}
+
+var sourceRemoveCmd = &cobra.Command{
+	Use:     "remove <name>",
+	Aliases: []string{"rm"},
+	Short:   "Remove a source and its cached copy",
+	Args:    cobra.ExactArgs(1),
+	RunE:    runSourceRemove,
+}
+
+var sourceListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "List sources and when they were fetched",
+	RunE:    runSourceList,
+}
+
+var sourceUpdateCmd = &cobra.Command{
+	Use:   "update [name]",
+	Short: "Fetch the latest content of sources",
+	Args:  cobra.MaximumNArgs(1),
+	RunE:  runSourceUpdate,
+}
+
+var (
+	sourceType     string
+	sourceRef      string
+	sourcePriority int
+)
+
+func init() {
+	sourceCmd.AddCommand(sourceAddCmd)
+	sourceCmd.AddCommand(sourceRemoveCmd)
+	sourceCmd.AddCommand(sourceListCmd)
+	sourceCmd.AddCommand(sourceUpdateCmd)
+
+	sourceAddCmd.Flags().StringVar(&sourceType, "type", "", "Source type: git, http or dir (inferred from the URL by default)")
+	sourceAddCmd.Flags().StringVar(&sourceRef, "ref", "", "Git branch or tag to track")
+	sourceAddCmd.Flags().IntVar(&sourcePriority, "priority", 0, "Priority when sources define the same name (higher wins)")
+}
+
+func runSourceAdd(cmd *cobra.Command, args []string) error {
+	out := output.DefaultWriter()
+
+	src := &source.Source{
+		Name:     args[0],
+		Type:     sourceType,
+		URL:      args[1],
+		Ref:      sourceRef,
+		Priority: sourcePriority,
+	}
+	if err := src.Validate(); err != nil {
+		return err
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	if existing := source.Find(cfg.Settings.Sources, src.Name); existing != nil {
+		*existing = *src
+	} else {
+		cfg.Settings.Sources = append(cfg.Settings.Sources, src)
+	}
+
+	if err := fetchSource(src); err != nil {
+		return err
+	}
+
+	if err := cfg.Save(); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+
+	out.Success("Added source %q (%s: %s)", src.Name, src.Kind(), src.URL)
+	return nil
+}
+
+func runSourceRemove(cmd *cobra.Command, args []string) error {
+	out := output.DefaultWriter()
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	src := source.Find(cfg.Settings.Sources, args[0])
+	if src == nil {
+		return fmt.Errorf("source %q is not configured", args[0])
+	}
+
+	var kept []*source.Source
+	for _, s := range cfg.Settings.Sources {
+		if s.Name != src.Name {
+			kept = append(kept, s)
+		}
+	}
+	cfg.Settings.Sources = kept
+
+	if err := cfg.Save(); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+
+	cacheDir := config.DefaultCacheDir()
+	if err := source.Remove(src, cacheDir); err != nil {
+		out.Warning("Could not remove cached copy: %v", err)
+	}
+	if states, err := source.LoadState(cacheDir); err == nil {
+		delete(states, src.Name)
+		_ = source.SaveState(cacheDir, states)
+	}
+
+	out.Success("Removed source %q", src.Name)
+	return nil
+}
+
+func runSourceUpdate(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	sources := cfg.Settings.Sources
+	if len(args) == 1 {
+		src := source.Find(sources, args[0])
+		if src == nil {
+			return fmt.Errorf("source %q is not configured", args[0])
+		}
+		sources = []*source.Source{src}
+	}
+	if len(sources) == 0 {
+		fmt.Println("No sources configured.")
+		fmt.Println("Add one with 'agentctl source add <name> <url>'.")
+		return nil
+	}
+
+	var failed int
+	for _, src := range sources {
+		if err := fetchSource(src); err != nil {
+			output.DefaultWriter().Error("%s: %v", src.Name, err)
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d source(s) failed to update", failed)
+	}
+	return nil
+}
+
+// fetchSource fetches one source and records its new state
+func fetchSource(src *source.Source) error {
+	out := output.DefaultWriter()
+	cacheDir := config.DefaultCacheDir()
+
+	states, err := source.LoadState(cacheDir)
+	if err != nil {
+		return fmt.Errorf("failed to load source state: %w", err)
+	}
+
+	state, changed, err := source.Fetch(src, cacheDir, states[src.Name])
+	if err != nil {
+		return err
+	}
+	states[src.Name] = state
+	if err := source.SaveState(cacheDir, states); err != nil {
+		return fmt.Errorf("failed to save source state: %w", err)
+	}
+
+	switch {
+	case !changed:
+		out.Info("%s is up to date", src.Name)
+	case state.Commit != "":
+		out.Success("Fetched %s at %s", src.Name, shortCommit(state.Commit))
+	default:
+		out.Success("Fetched %s", src.Name)
+	}
+	return nil
+}
+
+func runSourceList(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	cacheDir := config.DefaultCacheDir()
+	states, _ := source.LoadState(cacheDir)
+
+	if JSONOutput {
+		var infos []output.SourceInfo
+		for _, src := range source.Sorted(cfg.Settings.Sources) {
+			info := output.SourceInfo{
+				Name:     src.Name,
+				Type:     src.Kind(),
+				URL:      src.URL,
+				Ref:      src.Ref,
+				Priority: src.Priority,
+			}
+			if state := states[src.Name]; state != nil {
+				info.Commit = state.Commit
+				info.ETag = state.ETag
+				info.FetchedAt = state.FetchedAt.Format(time.RFC3339)
+			}
+			infos = append(infos, info)
+		}
+		return output.NewJSONWriter().WriteSuccess(infos)
+	}
+
+	if len(cfg.Settings.Sources) == 0 {
+		fmt.Println("No sources configured.")
+		fmt.Println("\nGet started:")
+		fmt.Println("  agentctl source add <name> <git-url|https-url|directory>")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tTYPE\tPRIORITY\tURL\tVERSION\tFETCHED")
+	for _, src := range source.Sorted(cfg.Settings.Sources) {
+		version, fetched := "-", "never"
+		if state := states[src.Name]; state != nil {
+			switch {
+			case state.Commit != "":
+				version = shortCommit(state.Commit)
+			case state.ETag != "":
+				version = state.ETag
+			}
+			fetched = state.FetchedAt.Format("2006-01-02 15:04")
+		}
+		if src.Kind() == source.TypeDir {
+			fetched = "local"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%s\t%s\n", src.Name, src.Kind(), src.Priority, src.URL, version, fetched)
+	}
+	return w.Flush()
+}
+
+// shortCommit abbreviates a commit hash for display
+func shortCommit(commit string) string {
+	if len(commit) > 7 {
+		return commit[:7]
+	}
+	return commit
+}
@@ -56,6 +56,8 @@ func runStatus(cmd *cobra.Command, args []string) error {
 			status := "enabled"
 			if server.Disabled {
 				status = "disabled"
+			} else if server.Excluded != "" {
+				status = "excluded: " + server.Excluded
 			}
 
 			source := server.Source.Type
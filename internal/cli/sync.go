@@ -7,11 +7,16 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"github.com/iheanyi/agentctl/pkg/agent"
 	"github.com/iheanyi/agentctl/pkg/command"
 	"github.com/iheanyi/agentctl/pkg/config"
+	"github.com/iheanyi/agentctl/pkg/container"
+	"github.com/iheanyi/agentctl/pkg/interp"
 	"github.com/iheanyi/agentctl/pkg/mcp"
 	"github.com/iheanyi/agentctl/pkg/output"
 	"github.com/iheanyi/agentctl/pkg/rule"
+	"github.com/iheanyi/agentctl/pkg/skill"
+	"github.com/iheanyi/agentctl/pkg/snapshot"
 	"github.com/iheanyi/agentctl/pkg/sync"
 )
 
@@ -20,22 +25,39 @@ var syncCmd = &cobra.Command{
 	Short: "Sync configuration to tools",
 	Long: `Sync your agentctl configuration to all detected tools.
 
-This will update MCP servers, commands, and rules in each tool's
-configuration file. Manually added entries (without the agentctl
+This will update MCP servers, commands, rules, skills and agents in
+each tool's configuration. Manually added entries (without the agentctl
 marker) are preserved.
 
+Every sync records a snapshot of the files it touches. Use
+'agentctl backup list' to see them and 'agentctl backup restore <id>'
+to roll a sync back.
+
 Scope:
   By default, syncs all servers from both local and global configs.
   Use --scope to sync only specific scope.
 
-  Local servers sync to workspace configs (.mcp.json, .cursor/mcp.json)
-  for tools that support it, falling back to global config with a warning.
+  Local servers sync to workspace configs (.mcp.json, .cursor/mcp.json,
+  .gemini/settings.json, opencode.json, .zed/settings.json,
+  .codex/config.toml, .vscode/mcp.json) for tools that support it,
+  falling back to global config with a warning.
+
+  Local commands, rules, skills and agents sync into the project
+  (.claude/commands, .cursor/rules, .github/agents, ...) for tools that
+  read them from there, and to global config otherwise.
+
+  Global resources always sync to global tool configs.
 
-  Global servers always sync to global tool configs.
+Remote servers:
+  Tools that only launch stdio servers (Claude Desktop, Cursor, Copilot,
+  Cline, Windsurf, Zed, Continue) can't use HTTP or SSE servers, so those
+  are skipped. Set "bridgeRemote": true in settings to write them as
+  'agentctl mcp-proxy <server>' entries instead, which relay to the
+  remote server with its headers.
 
 Examples:
   agentctl sync                  # Sync all (local + global)
-  agentctl sync --scope local    # Sync only local servers to workspace configs
+  agentctl sync --scope local    # Sync only local resources into the project
   agentctl sync --scope global   # Sync only global servers to global configs
   agentctl sync --tool claude    # Sync only to Claude Code
   agentctl sync --dry-run        # Preview changes without applying
@@ -109,10 +131,18 @@ func runSync(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	commands := cfg.LoadedCommands
-	rules := cfg.LoadedRules
+	commands := cfg.CommandsForScope(scope)
+	rules := cfg.RulesForScope(scope)
+	skills := cfg.SkillsForScope(scope)
+	agents := cfg.AgentsForScope(scope)
+	hasLocalResources := len(cfg.CommandsForScope(config.ScopeLocal))+len(cfg.RulesForScope(config.ScopeLocal))+
+		len(cfg.SkillsForScope(config.ScopeLocal))+len(cfg.AgentsForScope(config.ScopeLocal)) > 0 && scope != config.ScopeGlobal
 
-	if len(servers) == 0 && len(commands) == 0 && len(rules) == 0 {
+	// ${...} variables are resolved per tool as it's synced
+	vars := cfg.InterpContext()
+
+	if len(servers) == 0 && len(commands) == 0 && len(rules) == 0 && len(skills) == 0 && len(agents) == 0 && cfg.Permissions.IsEmpty() &&
+		len(cfg.Plugins) == 0 && len(cfg.Marketplaces) == 0 {
 		if JSONOutput {
 			jw := output.NewJSONWriter()
 			return jw.WriteSuccess(output.SyncOutput{
@@ -149,6 +179,12 @@ func runSync(cmd *cobra.Command, args []string) error {
 			fmt.Printf("\nRules (%d):\n", len(rules))
 			printVerboseRules(rules, "  ")
 		}
+		if excluded := cfg.ExcludedFor("", scope); len(excluded) > 0 {
+			fmt.Printf("\nExcluded here (%d):\n", len(excluded))
+			for _, e := range excluded {
+				fmt.Printf("  %s %s: %s\n", e.Type, e.Name, e.Reason)
+			}
+		}
 		fmt.Println()
 	}
 
@@ -186,7 +222,7 @@ func runSync(cmd *cobra.Command, args []string) error {
 			})
 		}
 		fmt.Println("No supported tools detected.")
-		fmt.Println("\nSupported tools: Claude Code, Cursor, Codex, OpenCode, Cline, Windsurf, Zed, Continue")
+		fmt.Println("\nSupported tools: Claude Code, Cursor, Codex, OpenCode, Cline, Windsurf, Zed, Continue, VS Code, Roo Code, Goose, Amp, Kiro")
 		return nil
 	}
 
@@ -200,9 +236,20 @@ func runSync(cmd *cobra.Command, args []string) error {
 		state = nil // Continue without state if loading fails
 	}
 
+	// Record the files every adapter is about to touch so the sync can be
+	// inspected and rolled back with 'agentctl backup'
+	var store *snapshot.Store
+	var snap *snapshot.Snapshot
+	if !syncDryRun {
+		store, snap = sync.BeginSnapshot()
+	}
+
 	// JSON output tracking
 	var toolResults []output.SyncToolResult
 
+	// Remote servers are bridged for stdio-only tools when enabled
+	bridge := sync.BridgeFor(cfg)
+
 	// Sync to each adapter
 	var successCount, errorCount int
 	for _, adapter := range adapters {
@@ -223,6 +270,13 @@ func runSync(cmd *cobra.Command, args []string) error {
 		// Get supported resources
 		supported := adapter.SupportedResources()
 
+		// Resolve variables for this tool. Local servers see the project
+		// root, so workspace configs get project-correct paths. Servers
+		// run in a container become the runtime command that runs them.
+		toolServers := container.Commands(vars.Servers(adapter.Name(), servers))
+		toolLocal := container.Commands(vars.Servers(adapter.Name(), localServers))
+		toolGlobal := container.Commands(vars.Servers(adapter.Name(), globalServers))
+
 		// Track tool result for JSON output
 		toolResult := output.SyncToolResult{
 			Tool:       adapter.Name(),
@@ -230,6 +284,30 @@ func runSync(cmd *cobra.Command, args []string) error {
 			Success:    true,
 		}
 
+		// Remote servers are bridged through 'agentctl mcp-proxy' for tools
+		// that only launch stdio servers, and left out if bridging is off
+		if containsResourceType(supported, sync.ResourceMCP) {
+			if bridge == nil {
+				toolResult.Skipped = sync.RemoteServers(adapter, toolServers)
+			}
+			toolServers, toolResult.Bridged = bridge.Servers(adapter, toolServers)
+			toolLocal, _ = bridge.Servers(adapter, toolLocal)
+			toolGlobal, _ = bridge.Servers(adapter, toolGlobal)
+			if !JSONOutput {
+				if len(toolResult.Bridged) > 0 {
+					fmt.Printf("  Bridging %d remote server(s) through 'agentctl %s': %s\n", len(toolResult.Bridged), sync.ProxyCommand, strings.Join(toolResult.Bridged, ", "))
+				}
+				if len(toolResult.Skipped) > 0 {
+					fmt.Printf("  Skipping %d remote server(s) %s can't connect to: %s\n", len(toolResult.Skipped), adapter.Name(), strings.Join(toolResult.Skipped, ", "))
+					fmt.Println("  Set settings.bridgeRemote to run them through 'agentctl mcp-proxy'")
+				}
+			}
+			toolResult.SkippedFilters = sync.SkippedToolFilters(adapter, toolServers)
+			if len(toolResult.SkippedFilters) > 0 && !JSONOutput {
+				fmt.Printf("  Skipping %d tool filter(s) %s can't match by exact name: %s\n", len(toolResult.SkippedFilters), adapter.Name(), strings.Join(toolResult.SkippedFilters, ", "))
+			}
+		}
+
 		if syncDryRun {
 			if containsResourceType(supported, sync.ResourceMCP) && len(servers) > 0 {
 				// Read existing servers and compute diff
@@ -245,7 +323,12 @@ func runSync(cmd *cobra.Command, args []string) error {
 				}
 
 				if readErr == nil {
-					diff := computeServerDiff(existingServers, servers, managedNames)
+					// Remote servers left unbridged are skipped by the write
+					incoming := toolServers
+					if !sync.SupportsRemoteServers(adapter) {
+						incoming = sync.FilterStdioServers(incoming)
+					}
+					diff := computeServerDiff(existingServers, incoming, managedNames)
 
 					// Track changes for JSON
 					toolResult.ServersAdded = len(diff.toAdd)
@@ -339,30 +422,86 @@ func runSync(cmd *cobra.Command, args []string) error {
 					}
 				}
 			}
+			if containsResourceType(supported, sync.ResourceSkills) && len(skills) > 0 {
+				toolResult.SkillsSynced = len(skills)
+				if !JSONOutput {
+					fmt.Printf("  Would sync %d skill(s)\n", len(skills))
+				}
+			}
+			if containsResourceType(supported, sync.ResourceAgents) && len(agents) > 0 {
+				toolResult.AgentsSynced = len(agents)
+				if !JSONOutput {
+					fmt.Printf("  Would sync %d agent(s)\n", len(agents))
+				}
+			}
+			if containsResourceType(supported, sync.ResourcePermissions) && !cfg.Permissions.IsEmpty() {
+				toolResult.PermissionsSynced = len(cfg.Permissions.Rules())
+				toolResult.SkippedRules = sync.SkippedPermissions(adapter, cfg.Permissions)
+				if !JSONOutput {
+					fmt.Printf("  Would sync %d permission rule(s)\n", toolResult.PermissionsSynced)
+					printSkippedPermissions(adapter, toolResult.SkippedRules)
+				}
+			}
+			if containsResourceType(supported, sync.ResourcePlugins) && len(cfg.Plugins) > 0 {
+				toolResult.PluginsSynced = len(cfg.Plugins)
+				if !JSONOutput {
+					fmt.Printf("  Would sync %d plugin(s)\n", toolResult.PluginsSynced)
+				}
+			}
+
+			// Run the writes against an in-memory copy of the tool's files
+			// to list exactly what would change
+			files, err := previewFiles(adapter, cfg, state, vars, toolLocal, toolGlobal, commands, rules, skills, agents)
+			if err != nil {
+				if !JSONOutput {
+					fmt.Printf("  Could not preview file changes: %v\n", err)
+				}
+			} else {
+				for _, f := range files {
+					toolResult.Files = append(toolResult.Files, output.SyncFile{Path: f.Path, Change: f.Kind()})
+				}
+				if !JSONOutput {
+					if len(files) == 0 {
+						fmt.Println("  No files would change")
+					} else {
+						fmt.Printf("  Would change %d file(s)\n", len(files))
+					}
+					if syncVerbose {
+						for _, f := range files {
+							fmt.Printf("    %s %s\n", f.Kind(), f.Path)
+						}
+					}
+				}
+			}
 			toolResults = append(toolResults, toolResult)
 			successCount++
 			continue
 		}
 
 		var syncedAny bool
+		projectDir := syncProjectDir(cfg)
+		ws := workspaceWritersFor(adapter)
+
+		var workspacePaths []string
+		if wa, ok := sync.AsWorkspaceAdapter(adapter); ok && len(toolLocal) > 0 && projectDir != "" {
+			workspacePaths = append(workspacePaths, wa.WorkspaceConfigPath(projectDir))
+		}
+		if wa, ok := sync.AsWorkspaceResourcesAdapter(adapter); ok && hasLocalResources && projectDir != "" {
+			workspacePaths = append(workspacePaths, wa.WorkspacePaths(projectDir)...)
+		}
+		if err := sync.CaptureAdapter(store, snap, adapter, workspacePaths...); err != nil && !JSONOutput {
+			fmt.Printf("  Warning: could not snapshot files before syncing: %v\n", err)
+		}
 
 		// Sync servers if supported
 		if containsResourceType(supported, sync.ResourceMCP) && len(servers) > 0 {
-			// Get project directory for workspace configs
-			projectDir := cfg.ProjectDir()
-			if projectDir == "" {
-				if cwd, err := os.Getwd(); err == nil {
-					projectDir = cwd
-				}
-			}
-
 			// Check if adapter supports workspace configs
 			wa, hasWorkspace := sync.AsWorkspaceAdapter(adapter)
 
 			// Sync local servers to workspace config if supported
-			if len(localServers) > 0 && hasWorkspace && projectDir != "" {
+			if len(toolLocal) > 0 && hasWorkspace && projectDir != "" {
 				workspacePath := wa.WorkspaceConfigPath(projectDir)
-				if err := wa.WriteWorkspaceServers(projectDir, localServers); err != nil {
+				if err := wa.WriteWorkspaceServers(projectDir, toolLocal); err != nil {
 					if !JSONOutput {
 						fmt.Printf("  Error syncing local servers to workspace: %v\n", err)
 					}
@@ -371,25 +510,25 @@ func runSync(cmd *cobra.Command, args []string) error {
 					errorCount++
 				} else {
 					if !JSONOutput {
-						fmt.Printf("  Synced %d local server(s) to %s\n", len(localServers), workspacePath)
+						fmt.Printf("  Synced %d local server(s) to %s\n", len(toolLocal), workspacePath)
 						if syncVerbose {
-							printVerboseServers(localServers, "    ")
+							printVerboseServers(toolLocal, "    ")
 						}
 					}
-					toolResult.ServersAdded += len(localServers)
+					toolResult.ServersAdded += len(toolLocal)
 					syncedAny = true
 				}
-			} else if len(localServers) > 0 {
+			} else if len(toolLocal) > 0 {
 				// Tool doesn't support workspace configs - warn and sync to global
 				if !JSONOutput {
 					fmt.Printf("  Warning: %s doesn't support workspace configs\n", adapter.Name())
-					fmt.Printf("  Syncing %d local server(s) to global config\n", len(localServers))
+					fmt.Printf("  Syncing %d local server(s) to global config\n", len(toolLocal))
 				}
-				globalServers = append(globalServers, localServers...)
+				toolGlobal = append(toolGlobal, toolLocal...)
 			}
 
 			// Sync global servers to global config
-			if len(globalServers) > 0 {
+			if len(toolGlobal) > 0 {
 				sa, ok := sync.AsServerAdapter(adapter)
 				if !ok {
 					if !JSONOutput {
@@ -398,7 +537,7 @@ func runSync(cmd *cobra.Command, args []string) error {
 					toolResult.Success = false
 					toolResult.Error = "Adapter doesn't support servers"
 					errorCount++
-				} else if err := sa.WriteServers(globalServers); err != nil {
+				} else if err := sa.WriteServers(toolGlobal); err != nil {
 					if !JSONOutput {
 						fmt.Printf("  Error syncing global servers: %v\n", err)
 					}
@@ -407,12 +546,12 @@ func runSync(cmd *cobra.Command, args []string) error {
 					errorCount++
 				} else {
 					if !JSONOutput {
-						fmt.Printf("  Synced %d global server(s)\n", len(globalServers))
+						fmt.Printf("  Synced %d global server(s)\n", len(toolGlobal))
 						if syncVerbose {
-							printVerboseServers(globalServers, "    ")
+							printVerboseServers(toolGlobal, "    ")
 						}
 					}
-					toolResult.ServersAdded += len(globalServers)
+					toolResult.ServersAdded += len(toolGlobal)
 					syncedAny = true
 				}
 			}
@@ -426,14 +565,14 @@ func runSync(cmd *cobra.Command, args []string) error {
 					fmt.Printf("  Error: adapter doesn't support commands\n")
 				}
 				toolResult.Error = "Adapter doesn't support commands"
-			} else if err := ca.WriteCommands(commands); err != nil {
+			} else if n, err := writeScoped(vars.Commands(adapter.Name(), commands), scopeOfCommand, projectDir, ws.commands, ca.WriteCommands); err != nil {
 				if !JSONOutput {
 					fmt.Printf("  Error syncing commands: %v\n", err)
 				}
 				toolResult.Error = fmt.Sprintf("Error syncing commands: %v", err)
 			} else {
 				if !JSONOutput {
-					fmt.Printf("  Synced %d command(s)\n", len(commands))
+					fmt.Printf("  Synced %d command(s)%s\n", len(commands), inProject(n))
 					if syncVerbose {
 						printVerboseCommands(commands, "    ")
 					}
@@ -451,14 +590,14 @@ func runSync(cmd *cobra.Command, args []string) error {
 					fmt.Printf("  Error: adapter doesn't support rules\n")
 				}
 				toolResult.Error = "Adapter doesn't support rules"
-			} else if err := ra.WriteRules(rules); err != nil {
+			} else if n, err := writeScoped(vars.Rules(adapter.Name(), rules), scopeOfRule, projectDir, ws.rules, ra.WriteRules); err != nil {
 				if !JSONOutput {
 					fmt.Printf("  Error syncing rules: %v\n", err)
 				}
 				toolResult.Error = fmt.Sprintf("Error syncing rules: %v", err)
 			} else {
 				if !JSONOutput {
-					fmt.Printf("  Synced %d rule(s)\n", len(rules))
+					fmt.Printf("  Synced %d rule(s)%s\n", len(rules), inProject(n))
 					if syncVerbose {
 						printVerboseRules(rules, "    ")
 					}
@@ -468,18 +607,113 @@ func runSync(cmd *cobra.Command, args []string) error {
 			}
 		}
 
+		// Sync skills if supported
+		if containsResourceType(supported, sync.ResourceSkills) && len(skills) > 0 {
+			sa, ok := sync.AsSkillsAdapter(adapter)
+			if !ok {
+				if !JSONOutput {
+					fmt.Printf("  Error: adapter doesn't support skills\n")
+				}
+				toolResult.Error = "Adapter doesn't support skills"
+			} else if n, err := writeScoped(skills, scopeOfSkill, projectDir, ws.skills, sa.WriteSkills); err != nil {
+				if !JSONOutput {
+					fmt.Printf("  Error syncing skills: %v\n", err)
+				}
+				toolResult.Error = fmt.Sprintf("Error syncing skills: %v", err)
+			} else {
+				if !JSONOutput {
+					fmt.Printf("  Synced %d skill(s)%s\n", len(skills), inProject(n))
+				}
+				toolResult.SkillsSynced = len(skills)
+				syncedAny = true
+			}
+		}
+
+		// Sync agents if supported
+		if containsResourceType(supported, sync.ResourceAgents) && len(agents) > 0 {
+			aa, ok := sync.AsAgentsAdapter(adapter)
+			if !ok {
+				if !JSONOutput {
+					fmt.Printf("  Error: adapter doesn't support agents\n")
+				}
+				toolResult.Error = "Adapter doesn't support agents"
+			} else if n, err := writeScoped(agents, scopeOfAgent, projectDir, ws.agents, aa.WriteAgents); err != nil {
+				if !JSONOutput {
+					fmt.Printf("  Error syncing agents: %v\n", err)
+				}
+				toolResult.Error = fmt.Sprintf("Error syncing agents: %v", err)
+			} else {
+				if !JSONOutput {
+					fmt.Printf("  Synced %d agent(s)%s\n", len(agents), inProject(n))
+				}
+				toolResult.AgentsSynced = len(agents)
+				syncedAny = true
+			}
+		}
+
+		// Sync permissions if supported. An empty policy is still written
+		// when we manage entries, so removed patterns are cleaned up.
+		if containsResourceType(supported, sync.ResourcePermissions) && (!cfg.Permissions.IsEmpty() || managesPermissions(state, adapter)) {
+			pa, ok := sync.AsPermissionsAdapter(adapter)
+			if !ok {
+				if !JSONOutput {
+					fmt.Printf("  Error: adapter doesn't support permissions\n")
+				}
+				toolResult.Error = "Adapter doesn't support permissions"
+			} else if err := pa.WritePermissions(cfg.Permissions); err != nil {
+				if !JSONOutput {
+					fmt.Printf("  Error syncing permissions: %v\n", err)
+				}
+				toolResult.Error = fmt.Sprintf("Error syncing permissions: %v", err)
+			} else if !cfg.Permissions.IsEmpty() {
+				toolResult.PermissionsSynced = len(cfg.Permissions.Rules())
+				toolResult.SkippedRules = sync.SkippedPermissions(adapter, cfg.Permissions)
+				if !JSONOutput {
+					fmt.Printf("  Synced %d permission rule(s)\n", toolResult.PermissionsSynced)
+					printSkippedPermissions(adapter, toolResult.SkippedRules)
+				}
+				syncedAny = true
+			}
+		}
+
+		// Sync plugins if supported. Like permissions, an empty plugin set
+		// is still written when we manage entries so they get removed.
+		if containsResourceType(supported, sync.ResourcePlugins) && (len(cfg.Plugins) > 0 || len(cfg.Marketplaces) > 0 || managesPlugins(state, adapter)) {
+			pa, ok := sync.AsPluginsAdapter(adapter)
+			if !ok {
+				if !JSONOutput {
+					fmt.Printf("  Error: adapter doesn't support plugins\n")
+				}
+				toolResult.Error = "Adapter doesn't support plugins"
+			} else if err := pa.WritePlugins(cfg.Plugins, cfg.Marketplaces); err != nil {
+				if !JSONOutput {
+					fmt.Printf("  Error syncing plugins: %v\n", err)
+				}
+				toolResult.Error = fmt.Sprintf("Error syncing plugins: %v", err)
+			} else if len(cfg.Plugins) > 0 {
+				toolResult.PluginsSynced = len(cfg.Plugins)
+				if !JSONOutput {
+					fmt.Printf("  Synced %d plugin(s)\n", toolResult.PluginsSynced)
+				}
+				syncedAny = true
+			}
+		}
+
 		toolResults = append(toolResults, toolResult)
 		if syncedAny {
 			successCount++
 		}
 	}
 
+	snapshotID := finishSnapshot(store, snap)
+
 	// JSON output
 	if JSONOutput {
 		jw := output.NewJSONWriter()
 		return jw.WriteSuccess(output.SyncOutput{
 			DryRun:      syncDryRun,
 			ProjectPath: cfg.ProjectPath,
+			SnapshotID:  snapshotID,
 			ToolResults: toolResults,
 			Summary: output.SyncSummary{
 				ToolsSucceeded: successCount,
@@ -497,10 +731,188 @@ func runSync(cmd *cobra.Command, args []string) error {
 	} else {
 		fmt.Printf("Synced to %d tool(s)\n", successCount)
 	}
+	if snapshotID != "" {
+		fmt.Printf("Snapshot %s recorded (undo with 'agentctl backup restore %s')\n", snapshotID, snapshotID)
+	}
 
 	return nil
 }
 
+// previewFiles performs a sync's writes for adapter against an in-memory
+// filesystem over the real one and returns the files that would change
+func previewFiles(adapter sync.Adapter, cfg *config.Config, state *sync.SyncState, vars *interp.Context, local, global []*mcp.Server, commands []*command.Command, rules []*rule.Rule, skills []*skill.Skill, agents []*agent.Agent) ([]sync.FileChange, error) {
+	mem := sync.NewMemFS(sync.DefaultFS)
+	adapter, err := sync.WithFS(adapter, mem)
+	if err != nil {
+		return nil, err
+	}
+	supported := adapter.SupportedResources()
+	projectDir := syncProjectDir(cfg)
+	ws := workspaceWritersFor(adapter)
+
+	if containsResourceType(supported, sync.ResourceMCP) {
+		if wa, ok := sync.AsWorkspaceAdapter(adapter); ok && len(local) > 0 && projectDir != "" {
+			if err := wa.WriteWorkspaceServers(projectDir, local); err != nil {
+				return nil, err
+			}
+			local = nil
+		}
+		servers := append(append([]*mcp.Server{}, global...), local...)
+		if sa, ok := sync.AsServerAdapter(adapter); ok && len(servers) > 0 {
+			if err := sa.WriteServers(servers); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if ca, ok := sync.AsCommandsAdapter(adapter); ok && containsResourceType(supported, sync.ResourceCommands) && len(commands) > 0 {
+		if _, err := writeScoped(vars.Commands(adapter.Name(), commands), scopeOfCommand, projectDir, ws.commands, ca.WriteCommands); err != nil {
+			return nil, err
+		}
+	}
+	if ra, ok := sync.AsRulesAdapter(adapter); ok && containsResourceType(supported, sync.ResourceRules) && len(rules) > 0 {
+		if _, err := writeScoped(vars.Rules(adapter.Name(), rules), scopeOfRule, projectDir, ws.rules, ra.WriteRules); err != nil {
+			return nil, err
+		}
+	}
+	if sa, ok := sync.AsSkillsAdapter(adapter); ok && containsResourceType(supported, sync.ResourceSkills) && len(skills) > 0 {
+		if _, err := writeScoped(skills, scopeOfSkill, projectDir, ws.skills, sa.WriteSkills); err != nil {
+			return nil, err
+		}
+	}
+	if aa, ok := sync.AsAgentsAdapter(adapter); ok && containsResourceType(supported, sync.ResourceAgents) && len(agents) > 0 {
+		if _, err := writeScoped(agents, scopeOfAgent, projectDir, ws.agents, aa.WriteAgents); err != nil {
+			return nil, err
+		}
+	}
+	if pa, ok := sync.AsPermissionsAdapter(adapter); ok && containsResourceType(supported, sync.ResourcePermissions) && (!cfg.Permissions.IsEmpty() || managesPermissions(state, adapter)) {
+		if err := pa.WritePermissions(cfg.Permissions); err != nil {
+			return nil, err
+		}
+	}
+	if pa, ok := sync.AsPluginsAdapter(adapter); ok && containsResourceType(supported, sync.ResourcePlugins) && (len(cfg.Plugins) > 0 || len(cfg.Marketplaces) > 0 || managesPlugins(state, adapter)) {
+		if err := pa.WritePlugins(cfg.Plugins, cfg.Marketplaces); err != nil {
+			return nil, err
+		}
+	}
+
+	// Adapters update agentctl's own sync state as they write; only the
+	// tool's files are of interest
+	var changes []sync.FileChange
+	for _, c := range mem.Changes() {
+		if c.Path != sync.StatePath() {
+			changes = append(changes, c)
+		}
+	}
+	return changes, nil
+}
+
+// workspaceWriters holds an adapter's project writers for the resource
+// types its tool reads from a project; the others are nil
+type workspaceWriters struct {
+	commands func(string, []*command.Command) error
+	rules    func(string, []*rule.Rule) error
+	skills   func(string, []*skill.Skill) error
+	agents   func(string, []*agent.Agent) error
+}
+
+func workspaceWritersFor(adapter sync.Adapter) workspaceWriters {
+	var ws workspaceWriters
+	wa, ok := sync.AsWorkspaceResourcesAdapter(adapter)
+	if !ok {
+		return ws
+	}
+	for _, rt := range wa.WorkspaceResources() {
+		switch rt {
+		case sync.ResourceCommands:
+			ws.commands = wa.WriteWorkspaceCommands
+		case sync.ResourceRules:
+			ws.rules = wa.WriteWorkspaceRules
+		case sync.ResourceSkills:
+			ws.skills = wa.WriteWorkspaceSkills
+		case sync.ResourceAgents:
+			ws.agents = wa.WriteWorkspaceAgents
+		}
+	}
+	return ws
+}
+
+// writeScoped writes one type of resource. Local items go into projectDir
+// through workspace when the tool reads them from there (workspace is nil
+// otherwise); everything else goes through global. It returns the number
+// written to the project.
+func writeScoped[T any](items []T, scopeOf func(T) string, projectDir string, workspace func(string, []T) error, global func([]T) error) (int, error) {
+	var local, rest []T
+	for _, item := range items {
+		if workspace != nil && projectDir != "" && scopeOf(item) == string(config.ScopeLocal) {
+			local = append(local, item)
+		} else {
+			rest = append(rest, item)
+		}
+	}
+
+	if len(local) > 0 {
+		if err := workspace(projectDir, local); err != nil {
+			return 0, err
+		}
+	}
+	if len(rest) > 0 {
+		if err := global(rest); err != nil {
+			return len(local), err
+		}
+	}
+	return len(local), nil
+}
+
+func scopeOfCommand(c *command.Command) string { return c.Scope }
+func scopeOfRule(r *rule.Rule) string          { return r.Scope }
+func scopeOfSkill(s *skill.Skill) string       { return s.Scope }
+func scopeOfAgent(a *agent.Agent) string       { return a.Scope }
+
+// inProject describes how many of a sync's items went to the project
+func inProject(n int) string {
+	if n == 0 {
+		return ""
+	}
+	return fmt.Sprintf(" (%d to project)", n)
+}
+
+// syncProjectDir returns the project directory for workspace configs
+func syncProjectDir(cfg *config.Config) string {
+	if dir := cfg.ProjectDir(); dir != "" {
+		return dir
+	}
+	cwd, _ := os.Getwd()
+	return cwd
+}
+
+// finishSnapshot saves the sync's snapshot, if any. It returns the
+// snapshot ID, or "" when nothing changed.
+func finishSnapshot(store *snapshot.Store, snap *snapshot.Snapshot) string {
+	if store == nil {
+		return ""
+	}
+	id, err := sync.FinishSnapshot(store, snap)
+	if err != nil && !JSONOutput {
+		fmt.Printf("Warning: could not save snapshot: %v\n", err)
+	}
+	return id
+}
+
+// managesPermissions reports whether a previous sync wrote permission
+// entries for the adapter
+func managesPermissions(state *sync.SyncState, adapter sync.Adapter) bool {
+	if state != nil && len(state.GetManagedPermissions(adapter.Name())) > 0 {
+		return true
+	}
+	return sync.OwnsPermissionsFile(adapter)
+}
+
+// managesPlugins reports whether a previous sync wrote plugin entries for
+// the adapter
+func managesPlugins(state *sync.SyncState, adapter sync.Adapter) bool {
+	return state != nil && len(state.GetManagedPlugins(adapter.Name())) > 0
+}
+
 func containsResourceType(types []sync.ResourceType, target sync.ResourceType) bool {
 	for _, t := range types {
 		if t == target {
@@ -677,3 +1089,11 @@ func printVerboseRules(rules []*rule.Rule, indent string) {
 		}
 	}
 }
+
+// printSkippedPermissions warns about permission patterns a tool can't
+// express without widening them
+func printSkippedPermissions(adapter sync.Adapter, skipped []string) {
+	if len(skipped) > 0 {
+		fmt.Printf("  Skipping %d exact permission rule(s) %s would apply to every command with that prefix: %s\n", len(skipped), adapter.Name(), strings.Join(skipped, ", "))
+	}
+}
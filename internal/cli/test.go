@@ -83,7 +83,7 @@ func runTest(cmd *cobra.Command, args []string) error {
 		err     error
 		tools   int
 		latency time.Duration
-		skipped bool
+		skipped string // Why the server wasn't tested
 	}
 
 	results := make([]testResult, len(serversToTest))
@@ -93,7 +93,11 @@ func runTest(cmd *cobra.Command, args []string) error {
 		server := cfg.Servers[name]
 
 		if server.Disabled {
-			results[i] = testResult{name: name, skipped: true}
+			results[i] = testResult{name: name, skipped: "disabled"}
+			continue
+		}
+		if server.Excluded != "" {
+			results[i] = testResult{name: name, skipped: "excluded: " + server.Excluded}
 			continue
 		}
 
@@ -131,8 +135,8 @@ func runTest(cmd *cobra.Command, args []string) error {
 	var passCount, failCount int
 
 	for _, res := range results {
-		if res.skipped {
-			out.Info("%s: skipped (disabled)", res.name)
+		if res.skipped != "" {
+			out.Info("%s: skipped (%s)", res.name, res.skipped)
 			continue
 		}
 
@@ -16,11 +16,14 @@ var updateCmd = &cobra.Command{
 	Long: `Update installed MCP servers to their latest versions.
 
 With no arguments, updates all servers. Otherwise, updates only the
-specified servers.
+specified servers. Git sources are pulled and rebuilt (from their
+Dockerfile if they run in a container), and container images are pulled
+again.
 
 Examples:
   agentctl update                  # Update all servers
   agentctl update filesystem       # Update specific server
+  agentctl update weather          # Pull a container server's image again
   agentctl update --check          # Check for updates without applying`,
 	RunE: runUpdate,
 }
@@ -76,7 +79,24 @@ func runUpdate(cmd *cobra.Command, args []string) error {
 	for _, name := range serversToUpdate {
 		server := cfg.Servers[name]
 
-		// Only git sources can be updated
+		// Images are updated by pulling them again
+		if server.Container != nil && server.Container.Image != "" {
+			if updateCheck {
+				out.Println("  %s: would pull %s", name, server.Container.Image)
+				continue
+			}
+			out.Println("Pulling %s...", server.Container.Image)
+			if err := b.Image(server); err != nil {
+				out.Error("Failed to pull %s: %v", name, err)
+				errorCount++
+				continue
+			}
+			out.Success("Updated %s", name)
+			updatedCount++
+			continue
+		}
+
+		// Otherwise only git sources can be updated
 		if server.Source.Type != "git" {
 			if !updateCheck {
 				out.Info("Skipping %q (not a git source)", name)
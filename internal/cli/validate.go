@@ -1,37 +1,64 @@
 package cli
 
 import (
-	"encoding/json"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 
+	toml "github.com/pelletier/go-toml/v2"
 	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
 
+	"github.com/iheanyi/agentctl/pkg/config"
+	"github.com/iheanyi/agentctl/pkg/jsonc"
+	"github.com/iheanyi/agentctl/pkg/lint"
 	"github.com/iheanyi/agentctl/pkg/output"
+	"github.com/iheanyi/agentctl/pkg/profile"
 	"github.com/iheanyi/agentctl/pkg/sync"
 )
 
 var validateCmd = &cobra.Command{
 	Use:   "validate",
 	Short: "Validate configuration syntax and schema",
-	Long: `Validate the syntax and schema of all tool configuration files.
-
-This checks that each tool's config file:
-- Is valid JSON
-- Has the expected structure for MCP servers
-- Contains no obvious errors
+	Long: `Validate tool configuration files and lint agentctl's own config and
+resources.
+
+Tool configs are checked for syntax and the expected MCP server
+structure, including Codex's config.toml and Goose's config.yaml.
+
+agentctl's config, profiles and resource directories are linted for:
+- Fields that don't match the schema (see 'agentctl schema')
+- Duplicate resource names within and across scopes
+- Servers without a command or URL, or whose command isn't installed
+- keychain: references to secrets that aren't stored
+- Commands and agents naming unknown tools or unconfigured MCP servers
+- Rules with invalid globs
+- Agents preloading skills that don't exist
+- Profiles naming resources that don't exist
+
+Each issue has a severity (error, warning or info). Validation fails
+when there are issues at --fail-on severity or above. Use --format sarif
+to upload results to code scanning in CI.
 
 Examples:
-  agentctl validate                # Validate all detected tool configs
-  agentctl validate --tool claude  # Validate only Claude config`,
+  agentctl validate                    # Validate tool configs and lint resources
+  agentctl validate --tool claude      # Validate only Claude config
+  agentctl validate --fail-on warning  # Also fail on warnings
+  agentctl validate --format sarif > agentctl.sarif`,
 	RunE: runValidate,
 }
 
-var validateTool string
+var (
+	validateTool   string
+	validateFormat string
+	validateFailOn string
+)
 
 func init() {
 	validateCmd.Flags().StringVarP(&validateTool, "tool", "t", "", "Validate specific tool only")
+	validateCmd.Flags().StringVar(&validateFormat, "format", "text", "Output format: text, json or sarif")
+	validateCmd.Flags().StringVar(&validateFailOn, "fail-on", "error", "Minimum severity that fails validation: error, warning or info")
 }
 
 // ValidationResult represents the result of validating a single tool's config
@@ -45,9 +72,16 @@ type ValidationResult struct {
 }
 
 func runValidate(cmd *cobra.Command, args []string) error {
-	if !JSONOutput {
-		fmt.Println("Validating configurations...")
-		fmt.Println()
+	format := validateFormat
+	if JSONOutput {
+		format = "json"
+	}
+	if format != "text" && format != "json" && format != "sarif" {
+		return fmt.Errorf("invalid format %q (expected text, json or sarif)", format)
+	}
+	failOn, err := lint.ParseSeverity(validateFailOn)
+	if err != nil {
+		return err
 	}
 
 	// Get adapters to validate
@@ -56,7 +90,7 @@ func runValidate(cmd *cobra.Command, args []string) error {
 		adapter, ok := sync.Get(validateTool)
 		if !ok {
 			err := fmt.Errorf("unknown tool %q", validateTool)
-			if JSONOutput {
+			if format == "json" {
 				jw := output.NewJSONWriter()
 				return jw.WriteError(err)
 			}
@@ -67,20 +101,9 @@ func runValidate(cmd *cobra.Command, args []string) error {
 		adapters = sync.Detected()
 	}
 
-	if len(adapters) == 0 {
-		if JSONOutput {
-			jw := output.NewJSONWriter()
-			return jw.WriteSuccess(output.ValidateOutput{
-				Results: []output.ValidateToolResult{},
-				Summary: output.ValidateSummary{
-					TotalTools:   0,
-					ValidTools:   0,
-					InvalidTools: 0,
-				},
-			})
-		}
-		fmt.Println("No supported tools detected.")
-		return nil
+	if format == "text" {
+		fmt.Println("Validating configurations...")
+		fmt.Println()
 	}
 
 	var results []ValidationResult
@@ -94,11 +117,37 @@ func runValidate(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	// JSON output
-	if JSONOutput {
+	// Lint agentctl's own config and resources unless validating one tool
+	var issues []lint.Issue
+	if validateTool == "" {
+		issues, err = lintResources()
+		if err != nil {
+			return err
+		}
+	}
+	for _, issue := range issues {
+		if issue.Severity.AtLeast(failOn) {
+			hasErrors = true
+		}
+	}
+
+	switch format {
+	case "sarif":
+		cwd, _ := os.Getwd()
+		all := append(toolConfigIssues(results), issues...)
+		if err := output.PrintJSON(lint.SARIF(all, Version, cwd)); err != nil {
+			return err
+		}
+		if hasErrors {
+			return fmt.Errorf("validation failed")
+		}
+		return nil
+
+	case "json":
 		jw := output.NewJSONWriter()
 		validateOutput := output.ValidateOutput{
 			Results: make([]output.ValidateToolResult, len(results)),
+			Issues:  make([]output.ValidateIssue, len(issues)),
 		}
 
 		validCount := 0
@@ -115,11 +164,25 @@ func runValidate(cmd *cobra.Command, args []string) error {
 				validCount++
 			}
 		}
+		for i, issue := range issues {
+			validateOutput.Issues[i] = output.ValidateIssue{
+				Rule:     issue.Rule,
+				Severity: string(issue.Severity),
+				Message:  issue.Message,
+				Resource: issue.Resource,
+				File:     issue.File,
+				Line:     issue.Line,
+				Column:   issue.Column,
+			}
+		}
 
 		validateOutput.Summary = output.ValidateSummary{
 			TotalTools:   len(results),
 			ValidTools:   validCount,
 			InvalidTools: len(results) - validCount,
+			Errors:       lint.Count(issues, lint.SeverityError),
+			Warnings:     lint.Count(issues, lint.SeverityWarning),
+			Infos:        lint.Count(issues, lint.SeverityInfo),
 		}
 
 		return jw.Write(output.CLIOutput{
@@ -135,12 +198,18 @@ func runValidate(cmd *cobra.Command, args []string) error {
 	}
 
 	// Print results
+	if len(results) == 0 {
+		fmt.Println("No supported tools detected.")
+		fmt.Println()
+	}
 	for _, result := range results {
 		printValidationResult(result)
 	}
+	if validateTool == "" {
+		printLintIssues(issues)
+	}
 
 	// Summary
-	fmt.Println()
 	validCount := 0
 	for _, r := range results {
 		if r.Valid {
@@ -149,7 +218,11 @@ func runValidate(cmd *cobra.Command, args []string) error {
 	}
 
 	if hasErrors {
-		fmt.Printf("Validated %d tool(s): %d valid, %d with errors\n", len(results), validCount, len(results)-validCount)
+		fmt.Printf("Validated %d tool(s): %d valid, %d with errors", len(results), validCount, len(results)-validCount)
+		if len(issues) > 0 {
+			fmt.Printf("; %d lint issue(s)", len(issues))
+		}
+		fmt.Println()
 		return fmt.Errorf("validation failed")
 	}
 
@@ -157,6 +230,65 @@ func runValidate(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// lintResources lints agentctl's global and project config, profiles and
+// resources
+func lintResources() ([]lint.Issue, error) {
+	cfg, err := config.LoadWithProject()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	linter := &lint.Linter{Config: cfg}
+	if cfg.ProjectPath != "" {
+		if global, err := config.Load(); err == nil {
+			linter.Global = global
+		}
+	}
+	profiles, err := profile.LoadAll(filepath.Join(cfg.ConfigDir, "profiles"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load profiles: %w", err)
+	}
+	linter.Profiles = profiles
+	return linter.Run(), nil
+}
+
+// toolConfigIssues converts tool config validation results into lint
+// issues for SARIF output
+func toolConfigIssues(results []ValidationResult) []lint.Issue {
+	var issues []lint.Issue
+	for _, r := range results {
+		for _, msg := range r.Errors {
+			issues = append(issues, lint.Issue{Rule: lint.RuleToolConfig, Severity: lint.SeverityError, Resource: r.Tool, File: r.ConfigPath, Message: msg})
+		}
+		for _, msg := range r.Warnings {
+			issues = append(issues, lint.Issue{Rule: lint.RuleToolConfig, Severity: lint.SeverityWarning, Resource: r.Tool, File: r.ConfigPath, Message: msg})
+		}
+	}
+	return issues
+}
+
+// printLintIssues prints resource lint issues grouped under one heading
+func printLintIssues(issues []lint.Issue) {
+	fmt.Println("agentctl resources:")
+	if len(issues) == 0 {
+		fmt.Println("  ✓ No issues found")
+		fmt.Println()
+		return
+	}
+	for _, issue := range issues {
+		location := shortenPath(issue.File)
+		if issue.Line > 0 {
+			location = fmt.Sprintf("%s:%d", location, issue.Line)
+		}
+		if location != "" {
+			location += ": "
+		}
+		label := strings.ToUpper(string(issue.Severity[:1])) + string(issue.Severity[1:])
+		fmt.Printf("    %s: %s%s [%s]\n", label, location, issue.Message, issue.Rule)
+	}
+	fmt.Printf("  %d error(s), %d warning(s), %d info\n", lint.Count(issues, lint.SeverityError), lint.Count(issues, lint.SeverityWarning), lint.Count(issues, lint.SeverityInfo))
+	fmt.Println()
+}
+
 func validateAdapter(adapter sync.Adapter) ValidationResult {
 	result := ValidationResult{
 		Tool:       adapter.Name(),
@@ -176,9 +308,17 @@ func validateAdapter(adapter sync.Adapter) ValidationResult {
 		return result
 	}
 
-	// Parse JSON
+	// Codex's primary config is TOML, Goose's YAML
+	switch filepath.Ext(result.ConfigPath) {
+	case ".toml":
+		return validateTOMLConfig(result, data)
+	case ".yaml", ".yml":
+		return validateYAMLConfig(result, data)
+	}
+
+	// Parse JSON, allowing comments as Zed, VS Code and Amp do
 	var raw map[string]interface{}
-	if err := json.Unmarshal(data, &raw); err != nil {
+	if err := jsonc.Unmarshal(data, &raw); err != nil {
 		result.Valid = false
 		result.Errors = append(result.Errors, fmt.Sprintf("Invalid JSON: %v", err))
 		return result
@@ -213,12 +353,94 @@ func validateAdapter(adapter sync.Adapter) ValidationResult {
 	return result
 }
 
+// validateTOMLConfig checks a Codex config.toml
+func validateTOMLConfig(result ValidationResult, data []byte) ValidationResult {
+	for _, issue := range lint.CheckCodexConfig(result.ConfigPath, data) {
+		msg := issue.Message
+		if issue.Line > 0 {
+			msg = fmt.Sprintf("line %d: %s", issue.Line, msg)
+		}
+		if issue.Severity == lint.SeverityError {
+			result.Valid = false
+			result.Errors = append(result.Errors, msg)
+		} else {
+			result.Warnings = append(result.Warnings, msg)
+		}
+	}
+
+	var raw struct {
+		MCPServers map[string]any `toml:"mcp_servers"`
+	}
+	if err := toml.Unmarshal(data, &raw); err == nil {
+		result.ServerCount = len(raw.MCPServers)
+	}
+	if result.Valid && raw.MCPServers == nil {
+		result.Warnings = append(result.Warnings, "No mcp_servers section found")
+	}
+	return result
+}
+
+// validateYAMLConfig checks a Goose config.yaml
+func validateYAMLConfig(result ValidationResult, data []byte) ValidationResult {
+	var raw struct {
+		Extensions map[string]interface{} `yaml:"extensions"`
+	}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		result.Valid = false
+		result.Errors = append(result.Errors, fmt.Sprintf("Invalid YAML: %v", err))
+		return result
+	}
+
+	if raw.Extensions == nil {
+		result.Warnings = append(result.Warnings, "No extensions section found")
+		return result
+	}
+	result.ServerCount = len(raw.Extensions)
+	for name, ext := range raw.Extensions {
+		extErrors := validateGooseExtension(name, ext)
+		result.Errors = append(result.Errors, extErrors...)
+		if len(extErrors) > 0 {
+			result.Valid = false
+		}
+	}
+	return result
+}
+
+// validateGooseExtension checks an entry in Goose's extensions section.
+// Extensions of types other than the MCP transports are built into Goose.
+func validateGooseExtension(name string, extData interface{}) []string {
+	ext, ok := extData.(map[string]interface{})
+	if !ok {
+		return []string{fmt.Sprintf("Extension %q: should be a mapping", name)}
+	}
+
+	extType, ok := ext["type"].(string)
+	if !ok {
+		return []string{fmt.Sprintf("Extension %q: missing 'type' field", name)}
+	}
+	switch extType {
+	case "stdio":
+		if _, ok := ext["cmd"].(string); !ok {
+			return []string{fmt.Sprintf("Extension %q: stdio extension missing 'cmd' field", name)}
+		}
+	case "sse", "streamable_http":
+		if _, ok := ext["uri"].(string); !ok {
+			return []string{fmt.Sprintf("Extension %q: %s extension missing 'uri' field", name, extType)}
+		}
+	}
+	return nil
+}
+
 func getServerKey(adapterName string) string {
 	switch adapterName {
 	case "zed":
 		return "context_servers"
 	case "opencode":
 		return "mcp"
+	case "vscode":
+		return "servers"
+	case "amp":
+		return "amp.mcpServers"
 	default:
 		return "mcpServers"
 	}
@@ -300,13 +522,7 @@ func printValidationResult(result ValidationResult) {
 
 	// Config path
 	if result.ConfigPath != "" {
-		// Shorten home directory
-		home, _ := os.UserHomeDir()
-		path := result.ConfigPath
-		if strings.HasPrefix(path, home) {
-			path = "~" + path[len(home):]
-		}
-		fmt.Printf("  Config: %s\n", path)
+		fmt.Printf("  Config: %s\n", shortenPath(result.ConfigPath))
 	}
 
 	fmt.Println()
@@ -119,6 +119,8 @@ func TestGetServerKey(t *testing.T) {
 		{"cursor", "mcpServers"},
 		{"zed", "context_servers"},
 		{"opencode", "mcp"},
+		{"vscode", "servers"},
+		{"amp", "amp.mcpServers"},
 		{"unknown", "mcpServers"},
 	}
 
@@ -131,3 +133,33 @@ func TestGetServerKey(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateGooseExtension(t *testing.T) {
+	tests := []struct {
+		name    string
+		extData interface{}
+		want    []string
+	}{
+		{"stdio", map[string]interface{}{"type": "stdio", "cmd": "npx"}, nil},
+		{"streamable http", map[string]interface{}{"type": "streamable_http", "uri": "https://example.com/mcp"}, nil},
+		{"builtin", map[string]interface{}{"type": "builtin", "name": "developer"}, nil},
+		{"missing type", map[string]interface{}{"cmd": "npx"}, []string{`Extension "missing type": missing 'type' field`}},
+		{"stdio missing cmd", map[string]interface{}{"type": "stdio"}, []string{`Extension "stdio missing cmd": stdio extension missing 'cmd' field`}},
+		{"sse missing uri", map[string]interface{}{"type": "sse"}, []string{`Extension "sse missing uri": sse extension missing 'uri' field`}},
+		{"not a mapping", "npx", []string{`Extension "not a mapping": should be a mapping`}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := validateGooseExtension(tt.name, tt.extData)
+			if len(got) != len(tt.want) {
+				t.Fatalf("validateGooseExtension() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("validateGooseExtension() error[%d] = %q, want %q", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
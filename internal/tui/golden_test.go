@@ -13,6 +13,7 @@ import (
 	"github.com/iheanyi/agentctl/pkg/config"
 	"github.com/iheanyi/agentctl/pkg/hook"
 	"github.com/iheanyi/agentctl/pkg/mcp"
+	"github.com/iheanyi/agentctl/pkg/mcpclient"
 	"github.com/iheanyi/agentctl/pkg/rule"
 	"github.com/iheanyi/agentctl/pkg/skill"
 	"github.com/iheanyi/agentctl/pkg/sync"
@@ -608,6 +609,28 @@ func TestGoldenProfilePicker(t *testing.T) {
 	testdata.AssertGolden(t, "modal_profile_picker", []byte(stripped))
 }
 
+// TestGoldenToolModal tests the tool modal with some tools disabled
+func TestGoldenToolModal(t *testing.T) {
+	m := newTestModel()
+	server := &mcp.Server{Name: "github", Command: "github-mcp", DisabledTools: []string{"delete_*"}}
+	m.cfg.Servers["github"] = server
+	m.showToolModal = true
+	m.toolModalServer = &Server{
+		Name:         "github",
+		Status:       ServerStatusInstalled,
+		ServerConfig: server,
+		Tools: []mcpclient.Tool{
+			{Name: "create_issue", Description: "Create an issue"},
+			{Name: "delete_repo", Description: "Delete a repository"},
+			{Name: "search", Description: "Search code"},
+		},
+	}
+
+	output := m.View()
+	stripped := testdata.StripANSI(output)
+	testdata.AssertGolden(t, "modal_tools", []byte(stripped))
+}
+
 // TestGoldenBackupModalEmpty tests the backup modal with no adapters detected
 func TestGoldenBackupModalEmpty(t *testing.T) {
 	m := newTestModel()
@@ -942,6 +942,17 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.showServerEditor = false
 		}
 
+	case toolToggledMsg:
+		if msg.err != nil {
+			m.addLog("error", fmt.Sprintf("Failed to toggle %s: %v", msg.tool, msg.err))
+		} else {
+			action := "Enabled"
+			if !msg.enabled {
+				action = "Disabled"
+			}
+			m.addLog("success", fmt.Sprintf("%s %s on %s - sync to apply", action, msg.tool, msg.server))
+		}
+
 	case serverToggledMsg:
 		if msg.err != nil {
 			m.addLog("error", fmt.Sprintf("Failed to toggle %s: %v", msg.name, msg.err))
@@ -1156,6 +1167,16 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				} else {
 					m.toolArgInput.Focus()
 				}
+			case " ":
+				// Turn the selected tool on or off, unless typing args
+				if m.toolArgInput.Focused() {
+					var cmd tea.Cmd
+					m.toolArgInput, cmd = m.toolArgInput.Update(msg)
+					return m, cmd
+				}
+				if m.toolModalServer != nil && len(m.toolModalServer.Tools) > 0 {
+					return m, m.toggleTool(m.toolModalServer, m.toolModalServer.Tools[m.toolCursor].Name)
+				}
 			case "enter":
 				// Execute the selected tool
 				if m.toolModalServer != nil && len(m.toolModalServer.Tools) > 0 {
@@ -2914,7 +2935,13 @@ func (m *Model) renderToolModal() string {
 			name := tool.Name
 			desc := ansi.Truncate(tool.Description, 40, "...")
 
-			row := cursor + name
+			// Whether the server's tool filters let the tool through
+			check := "[x] "
+			if cfg := m.toolModalServer.ServerConfig; cfg != nil && !cfg.ToolEnabled(tool.Name) {
+				check = "[ ] "
+			}
+
+			row := cursor + check + name
 			if desc != "" {
 				row += " - " + lipgloss.NewStyle().Foreground(colorFgSubtle).Render(desc)
 			}
@@ -2970,7 +2997,7 @@ func (m *Model) renderToolModal() string {
 	}
 
 	sections = append(sections, "")
-	hints := KeyDescStyle.Render("j/k:select  Tab:edit args  Enter:execute  Esc:close")
+	hints := KeyDescStyle.Render("j/k:select  Space:toggle  Tab:edit args  Enter:execute  Esc:close")
 	sections = append(sections, hints)
 
 	content := lipgloss.JoinVertical(lipgloss.Left, sections...)
@@ -4895,6 +4922,13 @@ type serverToggledMsg struct {
 	err      error
 }
 
+type toolToggledMsg struct {
+	server  string
+	tool    string
+	enabled bool
+	err     error
+}
+
 type editorFinishedMsg struct {
 	err error
 }
@@ -4986,16 +5020,8 @@ func (m *Model) testAllServers() tea.Cmd {
 
 func (m *Model) syncAll() tea.Cmd {
 	return func() tea.Msg {
-		// Build server list from config
-		var servers []*mcp.Server
-		for _, server := range m.cfg.Servers {
-			if !server.Disabled {
-				servers = append(servers, server)
-			}
-		}
-
 		// Sync to all detected tools (servers, commands, rules, skills, agents)
-		results := sync.SyncAll(servers, m.commands, m.rules, m.skills, m.agents)
+		results := sync.SyncAll(m.cfg.InterpContext(), sync.BridgeFor(m.cfg), m.cfg.ActiveServers(), m.commands, m.rules, m.skills, m.agents)
 
 		errors := make(map[string]error)
 		for _, result := range results {
@@ -5024,6 +5050,29 @@ func (m *Model) toggleServer(name string) tea.Cmd {
 	}
 }
 
+// toggleTool turns one of a server's tools on or off in its enabledTools
+// and disabledTools lists, using the tools found by the last test
+func (m *Model) toggleTool(s *Server, tool string) tea.Cmd {
+	return func() tea.Msg {
+		server, ok := m.cfg.Servers[s.Name]
+		if !ok {
+			return toolToggledMsg{server: s.Name, tool: tool, err: fmt.Errorf("server is not managed by agentctl")}
+		}
+
+		names := make([]string, len(s.Tools))
+		for i, t := range s.Tools {
+			names[i] = t.Name
+		}
+		enabled := !server.ToolEnabled(tool)
+		server.SetToolEnabled(tool, enabled, names)
+		if err := m.cfg.Save(); err != nil {
+			return toolToggledMsg{server: s.Name, tool: tool, err: err}
+		}
+
+		return toolToggledMsg{server: s.Name, tool: tool, enabled: enabled}
+	}
+}
+
 func (m *Model) addServer(name string) tea.Cmd {
 	return func() tea.Msg {
 		// Resolve alias
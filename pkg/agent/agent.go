@@ -9,6 +9,9 @@ import (
 	"strings"
 
 	"gopkg.in/yaml.v3"
+
+	"github.com/iheanyi/agentctl/pkg/condition"
+	"github.com/iheanyi/agentctl/pkg/schema"
 )
 
 // Agent represents a custom agent/subagent for AI coding tools.
@@ -31,8 +34,8 @@ type Agent struct {
 	DisallowedTools []string `yaml:"disallowedTools,omitempty" json:"disallowedTools,omitempty"` // Denied tools (Claude)
 
 	// Claude-specific
-	PermissionMode string   `yaml:"permissionMode,omitempty" json:"permissionMode,omitempty"` // default, acceptEdits, dontAsk, bypassPermissions, plan
-	Skills         []string `yaml:"skills,omitempty" json:"skills,omitempty"`                 // Preloaded skills
+	PermissionMode string   `yaml:"permissionMode,omitempty" json:"permissionMode,omitempty" jsonschema:"enum=default|acceptEdits|dontAsk|bypassPermissions|plan"`
+	Skills         []string `yaml:"skills,omitempty" json:"skills,omitempty"` // Preloaded skills
 
 	// Cursor-specific
 	ReadOnly     bool `yaml:"readonly,omitempty" json:"readonly,omitempty"`           // Restrict write operations
@@ -41,14 +44,27 @@ type Agent struct {
 	// OpenCode-specific
 	Temperature float64 `yaml:"temperature,omitempty" json:"temperature,omitempty"` // Response randomness (0.0-1.0)
 	MaxSteps    int     `yaml:"maxSteps,omitempty" json:"maxSteps,omitempty"`       // Max iterations
-	Mode        string  `yaml:"mode,omitempty" json:"mode,omitempty"`               // primary, subagent, all
-	Hidden      bool    `yaml:"hidden,omitempty" json:"hidden,omitempty"`           // Exclude from autocomplete
-	Disabled    bool    `yaml:"disable,omitempty" json:"disabled,omitempty"`        // Agent disabled
+	Mode        string  `yaml:"mode,omitempty" json:"mode,omitempty" jsonschema:"enum=primary|subagent|all"`
+	Hidden      bool    `yaml:"hidden,omitempty" json:"hidden,omitempty"`    // Exclude from autocomplete
+	Disabled    bool    `yaml:"disable,omitempty" json:"disabled,omitempty"` // Agent disabled
 
 	// Copilot-specific
 	Target   string            `yaml:"target,omitempty" json:"target,omitempty"`     // vscode, github-copilot
 	Infer    bool              `yaml:"infer,omitempty" json:"infer,omitempty"`       // Auto-selection based on context
 	Metadata map[string]string `yaml:"metadata,omitempty" json:"metadata,omitempty"` // Custom annotations
+
+	// When limits the agent to environments where the condition holds
+	When *condition.Condition `yaml:"when,omitempty" json:"when,omitempty"`
+}
+
+// FrontmatterSchema returns the JSON Schema of agent frontmatter
+func FrontmatterSchema() *schema.Schema {
+	return schema.Generate(Agent{}, schema.Options{
+		Name:        "agent",
+		Title:       "agentctl agent frontmatter",
+		Description: "YAML frontmatter of an agent markdown file",
+		Tag:         "yaml",
+	})
 }
 
 // InspectTitle returns the display name for the inspector modal header
@@ -121,6 +137,12 @@ func LoadFromFile(path string) (*Agent, error) {
 		return nil, err
 	}
 
+	if schema.Strict {
+		if err := schema.ValidateFrontmatter(path, data, FrontmatterSchema()); err != nil {
+			return nil, err
+		}
+	}
+
 	agent, err := ParseAgentMarkdown(data)
 	if err != nil {
 		return nil, fmt.Errorf("parsing %s: %w", path, err)
@@ -240,12 +262,22 @@ func LoadFromDirectory(dir string, scope string, tool string) ([]*Agent, error)
 
 // SaveToFile saves an agent to a markdown file with YAML frontmatter
 func (a *Agent) SaveToFile(path string) error {
+	data, err := a.Markdown()
+	if err != nil {
+		return err
+	}
+
 	// Create directory if needed
 	dir := filepath.Dir(path)
 	if err := os.MkdirAll(dir, 0755); err != nil {
 		return fmt.Errorf("creating directory: %w", err)
 	}
 
+	return os.WriteFile(path, data, 0644)
+}
+
+// Markdown formats the agent as markdown with YAML frontmatter
+func (a *Agent) Markdown() ([]byte, error) {
 	var buf bytes.Buffer
 
 	// Write YAML frontmatter
@@ -253,23 +285,24 @@ func (a *Agent) SaveToFile(path string) error {
 
 	// Only include non-empty fields
 	type frontmatter struct {
-		Name            string            `yaml:"name,omitempty"`
-		Description     string            `yaml:"description,omitempty"`
-		Model           string            `yaml:"model,omitempty"`
-		Tools           []string          `yaml:"tools,omitempty"`
-		DisallowedTools []string          `yaml:"disallowedTools,omitempty"`
-		PermissionMode  string            `yaml:"permissionMode,omitempty"`
-		Skills          []string          `yaml:"skills,omitempty"`
-		ReadOnly        bool              `yaml:"readonly,omitempty"`
-		IsBackground    bool              `yaml:"is_background,omitempty"`
-		Temperature     float64           `yaml:"temperature,omitempty"`
-		MaxSteps        int               `yaml:"maxSteps,omitempty"`
-		Mode            string            `yaml:"mode,omitempty"`
-		Hidden          bool              `yaml:"hidden,omitempty"`
-		Disabled        bool              `yaml:"disable,omitempty"`
-		Target          string            `yaml:"target,omitempty"`
-		Infer           bool              `yaml:"infer,omitempty"`
-		Metadata        map[string]string `yaml:"metadata,omitempty"`
+		Name            string               `yaml:"name,omitempty"`
+		Description     string               `yaml:"description,omitempty"`
+		Model           string               `yaml:"model,omitempty"`
+		Tools           []string             `yaml:"tools,omitempty"`
+		DisallowedTools []string             `yaml:"disallowedTools,omitempty"`
+		PermissionMode  string               `yaml:"permissionMode,omitempty"`
+		Skills          []string             `yaml:"skills,omitempty"`
+		ReadOnly        bool                 `yaml:"readonly,omitempty"`
+		IsBackground    bool                 `yaml:"is_background,omitempty"`
+		Temperature     float64              `yaml:"temperature,omitempty"`
+		MaxSteps        int                  `yaml:"maxSteps,omitempty"`
+		Mode            string               `yaml:"mode,omitempty"`
+		Hidden          bool                 `yaml:"hidden,omitempty"`
+		Disabled        bool                 `yaml:"disable,omitempty"`
+		Target          string               `yaml:"target,omitempty"`
+		Infer           bool                 `yaml:"infer,omitempty"`
+		Metadata        map[string]string    `yaml:"metadata,omitempty"`
+		When            *condition.Condition `yaml:"when,omitempty"`
 	}
 
 	fm := frontmatter{
@@ -290,11 +323,12 @@ func (a *Agent) SaveToFile(path string) error {
 		Target:          a.Target,
 		Infer:           a.Infer,
 		Metadata:        a.Metadata,
+		When:            a.When,
 	}
 
 	yamlData, err := yaml.Marshal(fm)
 	if err != nil {
-		return fmt.Errorf("marshaling frontmatter: %w", err)
+		return nil, fmt.Errorf("marshaling frontmatter: %w", err)
 	}
 
 	buf.Write(yamlData)
@@ -308,7 +342,7 @@ func (a *Agent) SaveToFile(path string) error {
 		}
 	}
 
-	return os.WriteFile(path, buf.Bytes(), 0644)
+	return buf.Bytes(), nil
 }
 
 // ToToolFormat converts the agent to a tool-specific format
@@ -3,10 +3,16 @@ package aliases
 import (
 	"embed"
 	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
+
+	"github.com/iheanyi/agentctl/pkg/config"
+	"github.com/iheanyi/agentctl/pkg/mcp"
+	"github.com/iheanyi/agentctl/pkg/source"
 )
 
 //go:embed aliases.json
@@ -23,14 +29,16 @@ type Alias struct {
 	Package        string             `json:"package,omitempty"`        // npm/pypi package name (e.g., "@figma/mcp-local")
 	Variants       map[string]Variant `json:"variants,omitempty"`       // Available variants (local, remote)
 	DefaultVariant string             `json:"defaultVariant,omitempty"` // Preferred variant (local or remote)
+	Inputs         []mcp.Input        `json:"inputs,omitempty"`         // Values the user supplies on install (API keys, IDs)
 }
 
 // Variant represents a specific distribution variant of an MCP server
 type Variant struct {
-	Transport string `json:"transport,omitempty"` // stdio, http, sse
-	Package   string `json:"package,omitempty"`   // npm/pypi package name
-	MCPURL    string `json:"mcpUrl,omitempty"`    // Remote MCP URL
-	Runtime   string `json:"runtime,omitempty"`   // node, python, etc.
+	Transport string      `json:"transport,omitempty"` // stdio, http, sse
+	Package   string      `json:"package,omitempty"`   // npm/pypi package name
+	MCPURL    string      `json:"mcpUrl,omitempty"`    // Remote MCP URL
+	Runtime   string      `json:"runtime,omitempty"`   // node, python, etc.
+	Inputs    []mcp.Input `json:"inputs,omitempty"`    // Replaces the alias inputs for this variant
 }
 
 // OAuth represents OAuth configuration for remote MCP servers
@@ -42,14 +50,31 @@ type OAuth struct {
 	PKCEEnabled bool     `json:"pkceEnabled,omitempty"` // Use PKCE for security
 }
 
-// Store manages alias lookups from bundled and user-defined sources
+// Alias origins reported by Store.Origin and AliasInfo.Source, besides
+// the names of configured sources
+const (
+	OriginBundled = "bundled"
+	OriginUser    = "user"
+)
+
+// Store manages alias lookups from bundled, team source and user-defined
+// aliases. User aliases win over source aliases, which win over bundled
+// ones; sources are consulted from highest to lowest priority.
 type Store struct {
 	bundled map[string]Alias
 	user    map[string]Alias
+	sources []sourceAliases // Highest priority first
 	mu      sync.RWMutex
 	userDir string
 }
 
+// sourceAliases holds the aliases loaded from one configured source
+type sourceAliases struct {
+	name     string
+	priority int
+	aliases  map[string]Alias
+}
+
 var defaultStore *Store
 var once sync.Once
 
@@ -57,6 +82,7 @@ var once sync.Once
 func Default() *Store {
 	once.Do(func() {
 		defaultStore = NewStore("")
+		defaultStore.loadConfiguredSources()
 	})
 	return defaultStore
 }
@@ -117,7 +143,53 @@ func (s *Store) loadUser() {
 	s.mu.Unlock()
 }
 
-// Resolve looks up an alias by name, checking user aliases first then bundled
+// loadConfiguredSources loads the aliases of the sources in the global
+// config from their cached copies
+func (s *Store) loadConfiguredSources() {
+	cfg, err := config.Load()
+	if err != nil {
+		return
+	}
+	cacheDir := config.DefaultCacheDir()
+	for _, src := range cfg.Settings.Sources {
+		// Unfetched sources simply contribute nothing
+		_ = s.LoadSource(src.Name, src.Priority, src.Dir(cacheDir))
+	}
+}
+
+// LoadSource adds the aliases.json shipped in a source directory. A
+// source without an aliases.json is added with no aliases.
+func (s *Store) LoadSource(name string, priority int, dir string) error {
+	aliases := make(map[string]Alias)
+	data, err := os.ReadFile(filepath.Join(dir, source.AliasesFile))
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err == nil {
+		if err := json.Unmarshal(data, &aliases); err != nil {
+			return fmt.Errorf("source %q: invalid %s: %w", name, source.AliasesFile, err)
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry := sourceAliases{name: name, priority: priority, aliases: aliases}
+	for i, existing := range s.sources {
+		if existing.name == name {
+			s.sources = append(s.sources[:i], s.sources[i+1:]...)
+			break
+		}
+	}
+	i := sort.Search(len(s.sources), func(i int) bool { return s.sources[i].priority < priority })
+	s.sources = append(s.sources, sourceAliases{})
+	copy(s.sources[i+1:], s.sources[i:])
+	s.sources[i] = entry
+	return nil
+}
+
+// Resolve looks up an alias by name, checking user aliases first, then
+// sources by priority, then bundled
 func (s *Store) Resolve(name string) (Alias, bool) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
@@ -127,6 +199,13 @@ func (s *Store) Resolve(name string) (Alias, bool) {
 		return alias, true
 	}
 
+	// Then team sources, highest priority first
+	for _, src := range s.sources {
+		if alias, ok := src.aliases[name]; ok {
+			return alias, true
+		}
+	}
+
 	// Fall back to bundled
 	if alias, ok := s.bundled[name]; ok {
 		return alias, true
@@ -178,7 +257,7 @@ func (s *Store) saveUser() error {
 	return os.WriteFile(filepath.Join(s.userDir, "aliases.json"), data, 0644)
 }
 
-// List returns all aliases (bundled and user)
+// List returns all aliases (bundled, sources and user) as Resolve sees them
 func (s *Store) List() map[string]Alias {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
@@ -190,6 +269,13 @@ func (s *Store) List() map[string]Alias {
 		result[name] = alias
 	}
 
+	// Sources override bundled, higher priorities applied last
+	for i := len(s.sources) - 1; i >= 0; i-- {
+		for name, alias := range s.sources[i].aliases {
+			result[name] = alias
+		}
+	}
+
 	// User aliases override bundled
 	for name, alias := range s.user {
 		result[name] = alias
@@ -222,6 +308,55 @@ func (s *Store) ListUser() map[string]Alias {
 	return result
 }
 
+// SourceNames returns the names of the loaded sources, highest priority
+// first
+func (s *Store) SourceNames() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	names := make([]string, 0, len(s.sources))
+	for _, src := range s.sources {
+		names = append(names, src.name)
+	}
+	return names
+}
+
+// ListSource returns the aliases shipped by one source
+func (s *Store) ListSource(name string) map[string]Alias {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := make(map[string]Alias)
+	for _, src := range s.sources {
+		if src.name == name {
+			for alias, a := range src.aliases {
+				result[alias] = a
+			}
+		}
+	}
+	return result
+}
+
+// Origin returns where the alias Resolve would use comes from: OriginUser,
+// a source name, OriginBundled, or "" if the alias doesn't exist
+func (s *Store) Origin(name string) string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if _, ok := s.user[name]; ok {
+		return OriginUser
+	}
+	for _, src := range s.sources {
+		if _, ok := src.aliases[name]; ok {
+			return src.name
+		}
+	}
+	if _, ok := s.bundled[name]; ok {
+		return OriginBundled
+	}
+	return ""
+}
+
 // IsBundled checks if an alias is from the bundled set
 func (s *Store) IsBundled(name string) bool {
 	s.mu.RLock()
@@ -268,31 +403,16 @@ type AliasInfo struct {
 	Description string
 	Runtime     string
 	URL         string
+	Source      string // OriginBundled, OriginUser or a source name
 }
 
-// Search searches for aliases matching the query
+// Search searches for aliases matching the query. Each name appears once,
+// from the origin Resolve would use.
 func (s *Store) Search(query string) []AliasInfo {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-
 	query = strings.ToLower(query)
 	var results []AliasInfo
 
-	// Search bundled
-	for name, alias := range s.bundled {
-		if strings.Contains(strings.ToLower(name), query) ||
-			strings.Contains(strings.ToLower(alias.Description), query) {
-			results = append(results, AliasInfo{
-				Name:        name,
-				Description: alias.Description,
-				Runtime:     alias.Runtime,
-				URL:         alias.URL,
-			})
-		}
-	}
-
-	// Search user
-	for name, alias := range s.user {
+	for name, alias := range s.List() {
 		if strings.Contains(strings.ToLower(name), query) ||
 			strings.Contains(strings.ToLower(alias.Description), query) {
 			results = append(results, AliasInfo{
@@ -300,10 +420,12 @@ func (s *Store) Search(query string) []AliasInfo {
 				Description: alias.Description,
 				Runtime:     alias.Runtime,
 				URL:         alias.URL,
+				Source:      s.Origin(name),
 			})
 		}
 	}
 
+	sort.Slice(results, func(i, j int) bool { return results[i].Name < results[j].Name })
 	return results
 }
 
@@ -359,6 +481,9 @@ func (s *Store) ResolveVariant(name string, variantPref string) (Alias, string,
 	if variant.Runtime != "" {
 		resolved.Runtime = variant.Runtime
 	}
+	if len(variant.Inputs) > 0 {
+		resolved.Inputs = variant.Inputs
+	}
 
 	return resolved, variantName, true
 }
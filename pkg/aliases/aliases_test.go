@@ -221,3 +221,77 @@ func TestAliasDescription(t *testing.T) {
 		t.Error("Bundled aliases should have descriptions")
 	}
 }
+
+func TestSourceAliases(t *testing.T) {
+	userDir := t.TempDir()
+	store := NewStore(userDir)
+
+	writeSource := func(content string) string {
+		dir := t.TempDir()
+		if err := os.WriteFile(filepath.Join(dir, "aliases.json"), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return dir
+	}
+
+	low := writeSource(`{"team-db": {"url": "github.com/acme/db-low"}, "filesystem": {"url": "github.com/acme/fs"}}`)
+	high := writeSource(`{"team-db": {"url": "github.com/acme/db-high", "description": "Vetted database server"}}`)
+	if err := store.LoadSource("low", 0, low); err != nil {
+		t.Fatalf("LoadSource() error = %v", err)
+	}
+	if err := store.LoadSource("high", 10, high); err != nil {
+		t.Fatalf("LoadSource() error = %v", err)
+	}
+	if err := store.LoadSource("empty", 5, t.TempDir()); err != nil {
+		t.Fatalf("LoadSource() without aliases.json error = %v", err)
+	}
+
+	if got := store.SourceNames(); len(got) != 3 || got[0] != "high" || got[2] != "low" {
+		t.Errorf("SourceNames() = %v, want priority order", got)
+	}
+
+	alias, ok := store.Resolve("team-db")
+	if !ok || alias.URL != "github.com/acme/db-high" {
+		t.Errorf("Resolve(team-db) = %+v, want the higher-priority source", alias)
+	}
+	if origin := store.Origin("team-db"); origin != "high" {
+		t.Errorf("Origin(team-db) = %q, want high", origin)
+	}
+
+	// Sources override bundled aliases; user aliases override sources
+	if origin := store.Origin("filesystem"); origin != "low" {
+		t.Errorf("Origin(filesystem) = %q, want low", origin)
+	}
+	if err := store.Add("team-db", Alias{URL: "github.com/me/db"}); err != nil {
+		t.Fatal(err)
+	}
+	if origin := store.Origin("team-db"); origin != OriginUser {
+		t.Errorf("Origin(team-db) after Add = %q, want user", origin)
+	}
+
+	results := store.Search("vetted")
+	if len(results) != 0 {
+		t.Errorf("Search() should use the winning alias, got %+v", results)
+	}
+	results = store.Search("team-db")
+	if len(results) != 1 || results[0].Source != OriginUser {
+		t.Errorf("Search(team-db) = %+v, want one user result", results)
+	}
+}
+
+func TestAliasInputs(t *testing.T) {
+	store := NewStore(t.TempDir())
+
+	alias, _, ok := store.ResolveVariant("notion", "")
+	if !ok {
+		t.Fatal("Should resolve 'notion' alias")
+	}
+	if len(alias.Inputs) != 1 || alias.Inputs[0].Name != "NOTION_TOKEN" || !alias.Inputs[0].Secret {
+		t.Errorf("notion inputs = %+v, want secret NOTION_TOKEN", alias.Inputs)
+	}
+
+	slack, ok := store.Resolve("slack")
+	if !ok || len(slack.Inputs) != 2 {
+		t.Errorf("slack inputs = %+v, want bot token and team ID", slack.Inputs)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/iheanyi/agentctl/pkg/container"
 	"github.com/iheanyi/agentctl/pkg/mcp"
 )
 
@@ -111,6 +112,11 @@ func (b *Builder) Update(server *mcp.Server) error {
 func (b *Builder) Build(server *mcp.Server) error {
 	dir := b.ServerDir(server)
 
+	// Servers run in a container use their image instead
+	if server.Container != nil {
+		return b.buildImage(dir, server)
+	}
+
 	// If explicit build config, use it
 	if server.Build != nil {
 		return b.runBuildConfig(dir, server.Build)
@@ -120,6 +126,33 @@ func (b *Builder) Build(server *mcp.Server) error {
 	return b.autoBuild(dir, server)
 }
 
+// Image makes a containerized server's image available locally: git
+// sources without an image are cloned and their Dockerfile built, and
+// other images are pulled
+func (b *Builder) Image(server *mcp.Server) error {
+	if server.Container == nil {
+		return fmt.Errorf("server %q doesn't run in a container", server.Name)
+	}
+	if server.Source.Type == "git" && server.Container.Image == "" {
+		if err := b.Clone(server); err != nil {
+			return err
+		}
+	}
+	return b.buildImage(b.ServerDir(server), server)
+}
+
+// buildImage builds the Dockerfile in dir, or pulls the server's image if
+// it names one
+func (b *Builder) buildImage(dir string, server *mcp.Server) error {
+	if server.Container.Image != "" {
+		return container.Pull(server)
+	}
+	if !container.HasDockerfile(dir) {
+		return fmt.Errorf("no image set and no Dockerfile in %s", dir)
+	}
+	return container.Build(server, dir)
+}
+
 func (b *Builder) runBuildConfig(dir string, build *mcp.BuildConfig) error {
 	// Run install command if specified
 	if build.Install != "" {
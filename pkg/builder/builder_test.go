@@ -3,6 +3,7 @@ package builder
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/iheanyi/agentctl/pkg/mcp"
@@ -261,3 +262,18 @@ func TestNew(t *testing.T) {
 		t.Errorf("CacheDir = %q, want %q", b.CacheDir, "/test/cache")
 	}
 }
+
+func TestBuildContainerWithoutDockerfile(t *testing.T) {
+	b := New(t.TempDir())
+
+	if err := b.Image(&mcp.Server{Name: "plain"}); err == nil {
+		t.Error("Image() should fail for a server without a container")
+	}
+
+	// A container server with no image needs a Dockerfile to build
+	server := &mcp.Server{Name: "local", Source: mcp.Source{Type: "local"}, Container: &mcp.Container{}}
+	err := b.Build(server)
+	if err == nil || !strings.Contains(err.Error(), "no Dockerfile") {
+		t.Errorf("Build() error = %v, want no Dockerfile", err)
+	}
+}
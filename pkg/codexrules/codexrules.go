@@ -0,0 +1,312 @@
+// Package codexrules reads and writes Codex execution-rule files
+// (~/.codex/rules/*.rules). Only the prefix_rule subset of Starlark is
+// interpreted; comments and statements agentctl doesn't understand are
+// preserved verbatim so files round-trip unchanged.
+package codexrules
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/iheanyi/agentctl/pkg/permission"
+	"github.com/iheanyi/agentctl/pkg/toolname"
+)
+
+// Decision is what Codex does when a rule matches
+type Decision string
+
+const (
+	DecisionAllow     Decision = "allow"     // Run without asking
+	DecisionPrompt    Decision = "prompt"    // Ask the user first
+	DecisionForbidden Decision = "forbidden" // Refuse to run
+)
+
+// Valid returns true for decisions Codex recognizes
+func (d Decision) Valid() bool {
+	return d == DecisionAllow || d == DecisionPrompt || d == DecisionForbidden
+}
+
+// Rule is a parsed prefix_rule call
+type Rule struct {
+	// Pattern is the command prefix, one entry per argument position. A
+	// position with several entries matches any of them.
+	Pattern       [][]string
+	Decision      Decision
+	Justification string
+	Path          string // File the rule was loaded from (if any)
+}
+
+// Block is one top-level statement with its leading comments
+type Block struct {
+	Text string // Original source text, including trailing newline
+	Rule *Rule  // Parsed rule, or nil for comments and unknown statements
+}
+
+// Document is a parsed rules file
+type Document struct {
+	Blocks []Block
+}
+
+// String renders the document back to source
+func (d *Document) String() string {
+	var sb strings.Builder
+	for _, b := range d.Blocks {
+		sb.WriteString(b.Text)
+	}
+	return sb.String()
+}
+
+// Rules returns the prefix rules in the document
+func (d *Document) Rules() []*Rule {
+	var rules []*Rule
+	for _, b := range d.Blocks {
+		if b.Rule != nil {
+			rules = append(rules, b.Rule)
+		}
+	}
+	return rules
+}
+
+// SetRules replaces the document's prefix rules with rules. Existing rule
+// blocks that match a wanted rule keep their original text and comments;
+// comments and unknown statements are never touched. New rules are
+// appended at the end.
+func (d *Document) SetRules(rules []*Rule) {
+	remaining := make([]*Rule, len(rules))
+	copy(remaining, rules)
+
+	var blocks []Block
+	for _, b := range d.Blocks {
+		if b.Rule == nil {
+			blocks = append(blocks, b)
+			continue
+		}
+		for i, r := range remaining {
+			if r.Equal(b.Rule) {
+				blocks = append(blocks, b)
+				remaining = append(remaining[:i], remaining[i+1:]...)
+				break
+			}
+		}
+	}
+
+	for _, r := range remaining {
+		text := r.Format()
+		if len(blocks) > 0 {
+			prev := blocks[len(blocks)-1].Text
+			if !strings.HasSuffix(prev, "\n") {
+				blocks[len(blocks)-1].Text = prev + "\n"
+			}
+			text = "\n" + text
+		}
+		blocks = append(blocks, Block{Text: text, Rule: r})
+	}
+
+	d.Blocks = blocks
+}
+
+// Equal reports whether two rules express the same policy
+func (r *Rule) Equal(other *Rule) bool {
+	if r.Decision != other.Decision || r.Justification != other.Justification ||
+		len(r.Pattern) != len(other.Pattern) {
+		return false
+	}
+	for i := range r.Pattern {
+		if strings.Join(r.Pattern[i], "\x00") != strings.Join(other.Pattern[i], "\x00") {
+			return false
+		}
+	}
+	return true
+}
+
+// Format renders the rule as a prefix_rule call
+func (r *Rule) Format() string {
+	parts := make([]string, len(r.Pattern))
+	for i, alts := range r.Pattern {
+		if len(alts) == 1 {
+			parts[i] = strconv.Quote(alts[0])
+			continue
+		}
+		quoted := make([]string, len(alts))
+		for j, alt := range alts {
+			quoted[j] = strconv.Quote(alt)
+		}
+		parts[i] = "[" + strings.Join(quoted, ", ") + "]"
+	}
+
+	var sb strings.Builder
+	sb.WriteString("prefix_rule(\n")
+	sb.WriteString("    pattern = [" + strings.Join(parts, ", ") + "],\n")
+	sb.WriteString("    decision = " + strconv.Quote(string(r.Decision)) + ",\n")
+	if r.Justification != "" {
+		sb.WriteString("    justification = " + strconv.Quote(r.Justification) + ",\n")
+	}
+	sb.WriteString(")\n")
+	return sb.String()
+}
+
+// Prefixes expands alternatives into every command prefix the rule matches
+func (r *Rule) Prefixes() [][]string {
+	prefixes := [][]string{nil}
+	for _, alts := range r.Pattern {
+		var next [][]string
+		for _, p := range prefixes {
+			for _, alt := range alts {
+				words := make([]string, len(p), len(p)+1)
+				copy(words, p)
+				next = append(next, append(words, alt))
+			}
+		}
+		prefixes = next
+	}
+	return prefixes
+}
+
+// String returns a short human-readable form, e.g. "git [push|fetch]"
+func (r *Rule) String() string {
+	parts := make([]string, len(r.Pattern))
+	for i, alts := range r.Pattern {
+		if len(alts) == 1 {
+			parts[i] = alts[0]
+		} else {
+			parts[i] = "[" + strings.Join(alts, "|") + "]"
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// FS is what rules files are read through, so callers previewing changes
+// can read their own view of the disk
+type FS interface {
+	ReadFile(path string) ([]byte, error)
+	ReadDir(path string) ([]os.DirEntry, error)
+}
+
+type osFS struct{}
+
+func (osFS) ReadFile(path string) ([]byte, error)       { return os.ReadFile(path) }
+func (osFS) ReadDir(path string) ([]os.DirEntry, error) { return os.ReadDir(path) }
+
+// OS reads rules files from the real filesystem
+var OS FS = osFS{}
+
+// LoadFile parses a rules file and records its path on each rule
+func LoadFile(fsys FS, path string) (*Document, error) {
+	data, err := fsys.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := Parse(string(data))
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range doc.Rules() {
+		r.Path = path
+	}
+	return doc, nil
+}
+
+// LoadDir parses every *.rules file in dir (sorted by name, as Codex loads
+// them) and returns their rules. A missing directory yields no rules.
+func LoadDir(fsys FS, dir string) ([]*Rule, error) {
+	entries, err := fsys.ReadDir(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	var paths []string
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || filepath.Ext(name) != ".rules" {
+			continue
+		}
+		paths = append(paths, filepath.Join(dir, name))
+	}
+	sort.Strings(paths)
+
+	var rules []*Rule
+	for _, path := range paths {
+		doc, err := LoadFile(fsys, path)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, doc.Rules()...)
+	}
+	return rules, nil
+}
+
+// ToPermissions converts rules into canonical bash permissions. Each
+// expanded prefix becomes a "bash(<words>:*)" pattern.
+func ToPermissions(rules []*Rule) *permission.Permissions {
+	perms := &permission.Permissions{}
+	for _, r := range rules {
+		for _, words := range r.Prefixes() {
+			pattern := permission.FormatPattern(toolname.Bash, strings.Join(words, " ")+":*")
+			perms.Add(toPermissionDecision(r.Decision), pattern)
+		}
+	}
+	if perms.IsEmpty() {
+		return nil
+	}
+	return perms
+}
+
+// FromPermissions converts canonical permissions into prefix rules. Codex
+// execution rules only govern shell commands, so patterns for other tools
+// (and bash patterns without a command) are skipped. A prefix_rule matches
+// every command starting with its words, so exact allow and ask patterns
+// like bash(git status) can't be expressed without widening them; they're
+// returned as skipped. Exact deny patterns become prefix rules, which only
+// forbid more.
+func FromPermissions(perms *permission.Permissions) (rules []*Rule, skipped []string) {
+	for _, p := range perms.Rules() {
+		if p.Tool() != toolname.Bash || p.Specifier() == "" {
+			continue
+		}
+		words, prefix := permission.CommandPrefix(p.Specifier())
+		if len(words) == 0 {
+			continue
+		}
+		if !prefix && p.Decision != permission.DecisionDeny {
+			skipped = append(skipped, p.Pattern)
+			continue
+		}
+
+		pattern := make([][]string, len(words))
+		for i, w := range words {
+			pattern[i] = []string{w}
+		}
+		rules = append(rules, &Rule{Pattern: pattern, Decision: fromPermissionDecision(p.Decision)})
+	}
+	return rules, skipped
+}
+
+// fromPermissionDecision maps a permission decision to Codex's vocabulary
+func fromPermissionDecision(d permission.Decision) Decision {
+	switch d {
+	case permission.DecisionAllow:
+		return DecisionAllow
+	case permission.DecisionDeny:
+		return DecisionForbidden
+	default:
+		return DecisionPrompt
+	}
+}
+
+// toPermissionDecision maps a Codex decision to a permission decision
+func toPermissionDecision(d Decision) permission.Decision {
+	switch d {
+	case DecisionAllow:
+		return permission.DecisionAllow
+	case DecisionForbidden:
+		return permission.DecisionDeny
+	default:
+		return permission.DecisionAsk
+	}
+}
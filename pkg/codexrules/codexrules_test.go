@@ -0,0 +1,162 @@
+package codexrules
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/iheanyi/agentctl/pkg/permission"
+)
+
+const sample = `# Team execution policy
+
+# Never force-push
+prefix_rule(
+    pattern = ["git", "push", "--force"],
+    decision = "forbidden",
+    justification = "Rewrites shared history",
+)
+
+prefix_rule(pattern = ["git", ["fetch", "pull"]], match = ["git fetch origin"])
+
+load("//other.rules", "helper")
+prefix_rule(pattern = [helper], decision = "allow")
+prefix_rule(pattern = ["rm"], decision = "maybe")
+
+# trailing note
+`
+
+func TestParseRoundTrip(t *testing.T) {
+	doc, err := Parse(sample)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if got := doc.String(); got != sample {
+		t.Errorf("round trip mismatch:\n%s", got)
+	}
+
+	rules := doc.Rules()
+	if len(rules) != 2 {
+		t.Fatalf("Rules() returned %d rules, want 2", len(rules))
+	}
+
+	first := rules[0]
+	if first.Decision != DecisionForbidden || first.Justification != "Rewrites shared history" {
+		t.Errorf("first rule = %+v", first)
+	}
+	if first.String() != "git push --force" {
+		t.Errorf("first rule String() = %q", first.String())
+	}
+
+	second := rules[1]
+	if second.Decision != DecisionAllow {
+		t.Errorf("default decision = %q, want allow", second.Decision)
+	}
+	want := [][]string{{"git", "fetch"}, {"git", "pull"}}
+	if got := second.Prefixes(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Prefixes() = %v, want %v", got, want)
+	}
+}
+
+func TestParseBlocks(t *testing.T) {
+	doc, err := Parse(sample)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	// The header comment stands alone; the comment above the first rule
+	// travels with it.
+	if doc.Blocks[0].Rule != nil || !strings.HasPrefix(doc.Blocks[0].Text, "# Team") {
+		t.Errorf("first block = %q, want standalone header", doc.Blocks[0].Text)
+	}
+	if doc.Blocks[1].Rule == nil || !strings.Contains(doc.Blocks[1].Text, "# Never force-push") {
+		t.Errorf("second block = %q, want rule with its comment", doc.Blocks[1].Text)
+	}
+}
+
+func TestParseUnterminatedString(t *testing.T) {
+	if _, err := Parse(`prefix_rule(pattern = ["git])`); err == nil {
+		t.Error("Parse() should fail on an unterminated string")
+	}
+}
+
+func TestSetRules(t *testing.T) {
+	doc, err := Parse(sample)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	keep := doc.Rules()[0]
+	added := &Rule{Pattern: [][]string{{"npm"}, {"publish"}}, Decision: DecisionPrompt}
+	doc.SetRules([]*Rule{{
+		Pattern:       keep.Pattern,
+		Decision:      keep.Decision,
+		Justification: keep.Justification,
+	}, added})
+
+	got := doc.String()
+	for _, want := range []string{
+		"# Team execution policy",
+		"# Never force-push",
+		`justification = "Rewrites shared history"`,
+		`load("//other.rules", "helper")`,
+		`prefix_rule(pattern = [helper], decision = "allow")`,
+		"# trailing note",
+		"prefix_rule(\n    pattern = [\"npm\", \"publish\"],\n    decision = \"prompt\",\n)\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("SetRules() output missing %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, `"fetch", "pull"`) {
+		t.Errorf("SetRules() should drop rules that are no longer wanted:\n%s", got)
+	}
+
+	reparsed, err := Parse(got)
+	if err != nil {
+		t.Fatalf("Parse(output) error = %v", err)
+	}
+	if n := len(reparsed.Rules()); n != 2 {
+		t.Errorf("reparsed %d rules, want 2", n)
+	}
+}
+
+func TestPermissionsConversion(t *testing.T) {
+	rules := []*Rule{
+		{Pattern: [][]string{{"git"}, {"push", "reset"}}, Decision: DecisionForbidden},
+		{Pattern: [][]string{{"npm"}, {"test"}}, Decision: DecisionAllow},
+		{Pattern: [][]string{{"terraform"}}, Decision: DecisionPrompt},
+	}
+
+	perms := ToPermissions(rules)
+	want := &permission.Permissions{
+		Deny:  []string{"bash(git push:*)", "bash(git reset:*)"},
+		Allow: []string{"bash(npm test:*)"},
+		Ask:   []string{"bash(terraform:*)"},
+	}
+	if !reflect.DeepEqual(perms, want) {
+		t.Errorf("ToPermissions() = %+v, want %+v", perms, want)
+	}
+
+	back, skipped := FromPermissions(&permission.Permissions{
+		Deny:  []string{"bash(git push --force:*)", "edit", "bash(rm -rf /)"},
+		Allow: []string{"bash", "bash(git status)", "bash(go test *)"},
+		Ask:   []string{"bash(npm publish)"},
+	})
+	var got []string
+	for _, r := range back {
+		got = append(got, string(r.Decision)+" "+r.String())
+	}
+	wantRules := []string{"allow go test", "forbidden git push --force", "forbidden rm -rf /"}
+	sort.Strings(got)
+	if !reflect.DeepEqual(got, wantRules) {
+		t.Errorf("FromPermissions() = %q, want %q", got, wantRules)
+	}
+	// Exact allow and ask patterns would be widened to every command
+	// starting with them
+	sort.Strings(skipped)
+	if want := []string{"bash(git status)", "bash(npm publish)"}; !reflect.DeepEqual(skipped, want) {
+		t.Errorf("FromPermissions() skipped = %q, want %q", skipped, want)
+	}
+}
@@ -0,0 +1,329 @@
+package codexrules
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// tokenKind identifies a lexical token in a rules file
+type tokenKind int
+
+const (
+	tokIdent tokenKind = iota
+	tokString
+	tokOther // numbers, operators and anything else we don't interpret
+	tokLParen
+	tokRParen
+	tokLBracket
+	tokRBracket
+	tokLBrace
+	tokRBrace
+	tokComma
+	tokAssign
+	tokNewline
+	tokComment
+)
+
+type token struct {
+	kind  tokenKind
+	text  string // raw source text
+	value string // unquoted value for strings
+	start int
+	end   int
+}
+
+// lex splits Starlark source into tokens. It understands just enough of
+// the language (strings, comments, brackets) to find statement boundaries
+// and read prefix_rule arguments; everything else is an opaque token.
+func lex(src string) ([]token, error) {
+	var tokens []token
+	i := 0
+	for i < len(src) {
+		c := src[i]
+		start := i
+		switch {
+		case c == '\n':
+			i++
+			tokens = append(tokens, token{kind: tokNewline, text: "\n", start: start, end: i})
+		case c == ' ' || c == '\t' || c == '\r':
+			i++
+		case c == '\\' && i+1 < len(src) && src[i+1] == '\n':
+			// Explicit line continuation
+			i += 2
+		case c == '#':
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokComment, text: src[start:i], start: start, end: i})
+		case c == '"' || c == '\'' || ((c == 'r' || c == 'b') && i+1 < len(src) && (src[i+1] == '"' || src[i+1] == '\'')):
+			end, value, err := lexString(src, i)
+			if err != nil {
+				return nil, err
+			}
+			i = end
+			tokens = append(tokens, token{kind: tokString, text: src[start:i], value: value, start: start, end: i})
+		case isIdentStart(c):
+			for i < len(src) && isIdentPart(src[i]) {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokIdent, text: src[start:i], start: start, end: i})
+		default:
+			i++
+			kind := tokOther
+			switch c {
+			case '(':
+				kind = tokLParen
+			case ')':
+				kind = tokRParen
+			case '[':
+				kind = tokLBracket
+			case ']':
+				kind = tokRBracket
+			case '{':
+				kind = tokLBrace
+			case '}':
+				kind = tokRBrace
+			case ',':
+				kind = tokComma
+			case '=':
+				if i < len(src) && src[i] == '=' {
+					i++
+				} else {
+					kind = tokAssign
+				}
+			}
+			tokens = append(tokens, token{kind: kind, text: src[start:i], start: start, end: i})
+		}
+	}
+	return tokens, nil
+}
+
+// lexString reads a string literal starting at i and returns the index
+// just past it along with its decoded value
+func lexString(src string, i int) (int, string, error) {
+	start := i
+	raw := false
+	if src[i] == 'r' || src[i] == 'b' {
+		raw = src[i] == 'r'
+		i++
+	}
+	quote := src[i]
+	triple := strings.HasPrefix(src[i:], strings.Repeat(string(quote), 3))
+	delim := string(quote)
+	if triple {
+		delim = strings.Repeat(string(quote), 3)
+	}
+	i += len(delim)
+	bodyStart := i
+
+	for i < len(src) {
+		if src[i] == '\\' && i+1 < len(src) {
+			i += 2
+			continue
+		}
+		if !triple && src[i] == '\n' {
+			break
+		}
+		if strings.HasPrefix(src[i:], delim) {
+			body := src[bodyStart:i]
+			i += len(delim)
+			return i, decodeString(body, raw), nil
+		}
+		i++
+	}
+	return 0, "", fmt.Errorf("unterminated string at offset %d", start)
+}
+
+// decodeString resolves escape sequences in a string body
+func decodeString(body string, raw bool) string {
+	if raw || !strings.Contains(body, `\`) {
+		return body
+	}
+	if s, err := strconv.Unquote(`"` + strings.ReplaceAll(body, `"`, `\"`) + `"`); err == nil {
+		return s
+	}
+	return body
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isIdentPart(c byte) bool {
+	return isIdentStart(c) || (c >= '0' && c <= '9') || c == '.'
+}
+
+// Parse splits a rules file into blocks, one per top-level statement.
+// Comments directly above a statement are attached to it so they travel
+// with it; comments separated by a blank line (such as a file header) and
+// trailing comments form their own blocks. Statements that aren't a
+// prefix_rule we understand are kept verbatim.
+func Parse(src string) (*Document, error) {
+	tokens, err := lex(src)
+	if err != nil {
+		return nil, err
+	}
+
+	doc := &Document{}
+	blockStart := 0
+	depth := 0
+	var stmt []token
+	prev := tokNewline
+
+	flush := func(end int) {
+		b := Block{Text: src[blockStart:end]}
+		if r, ok := parseRule(stmt); ok {
+			b.Rule = r
+		}
+		doc.Blocks = append(doc.Blocks, b)
+		blockStart = end
+		stmt = nil
+	}
+
+	for _, tok := range tokens {
+		kind := prev
+		prev = tok.kind
+		switch tok.kind {
+		case tokComment:
+			continue
+		case tokNewline:
+			if depth == 0 && len(stmt) > 0 {
+				flush(tok.end)
+			} else if depth == 0 && kind == tokNewline && strings.TrimSpace(src[blockStart:tok.start]) != "" {
+				// A blank line ends a standalone comment block
+				flush(tok.start)
+			}
+			continue
+		case tokLParen, tokLBracket, tokLBrace:
+			depth++
+		case tokRParen, tokRBracket, tokRBrace:
+			if depth > 0 {
+				depth--
+			}
+		}
+		stmt = append(stmt, tok)
+	}
+
+	if len(stmt) > 0 || blockStart < len(src) {
+		flush(len(src))
+	}
+
+	return doc, nil
+}
+
+// parseRule interprets a statement as a prefix_rule call. It returns false
+// for anything else, including calls with arguments that aren't literals.
+func parseRule(stmt []token) (*Rule, bool) {
+	if len(stmt) < 3 || stmt[0].kind != tokIdent || stmt[0].text != "prefix_rule" ||
+		stmt[1].kind != tokLParen || stmt[len(stmt)-1].kind != tokRParen {
+		return nil, false
+	}
+
+	r := &Rule{}
+	args := stmt[2 : len(stmt)-1]
+	for len(args) > 0 {
+		if len(args) < 3 || args[0].kind != tokIdent || args[1].kind != tokAssign {
+			return nil, false
+		}
+		name := args[0].text
+		value, rest, ok := splitArg(args[2:])
+		if !ok {
+			return nil, false
+		}
+		args = rest
+
+		switch name {
+		case "pattern":
+			pattern, ok := parsePattern(value)
+			if !ok {
+				return nil, false
+			}
+			r.Pattern = pattern
+		case "decision":
+			if len(value) != 1 || value[0].kind != tokString {
+				return nil, false
+			}
+			r.Decision = Decision(value[0].value)
+		case "justification":
+			if len(value) != 1 || value[0].kind != tokString {
+				return nil, false
+			}
+			r.Justification = value[0].value
+		default:
+			// Other arguments (match/not_match examples) don't change the
+			// policy; the block's source text keeps them intact.
+		}
+	}
+
+	if len(r.Pattern) == 0 {
+		return nil, false
+	}
+	if r.Decision == "" {
+		r.Decision = DecisionAllow
+	}
+	if !r.Decision.Valid() {
+		return nil, false
+	}
+	return r, true
+}
+
+// splitArg returns the tokens of one argument value and the remaining
+// arguments after its trailing comma
+func splitArg(tokens []token) (value, rest []token, ok bool) {
+	depth := 0
+	for i, tok := range tokens {
+		switch tok.kind {
+		case tokLParen, tokLBracket, tokLBrace:
+			depth++
+		case tokRParen, tokRBracket, tokRBrace:
+			depth--
+		case tokComma:
+			if depth == 0 {
+				return tokens[:i], tokens[i+1:], i > 0
+			}
+		}
+	}
+	return tokens, nil, len(tokens) > 0
+}
+
+// parsePattern reads a list of strings or string alternatives, e.g.
+// ["git", ["push", "fetch"]]
+func parsePattern(tokens []token) ([][]string, bool) {
+	if len(tokens) < 2 || tokens[0].kind != tokLBracket || tokens[len(tokens)-1].kind != tokRBracket {
+		return nil, false
+	}
+
+	var pattern [][]string
+	inner := tokens[1 : len(tokens)-1]
+	for len(inner) > 0 {
+		elem, rest, ok := splitArg(inner)
+		if !ok {
+			return nil, false
+		}
+		inner = rest
+
+		switch {
+		case len(elem) == 1 && elem[0].kind == tokString:
+			pattern = append(pattern, []string{elem[0].value})
+		case len(elem) >= 2 && elem[0].kind == tokLBracket && elem[len(elem)-1].kind == tokRBracket:
+			var alts []string
+			for _, tok := range elem[1 : len(elem)-1] {
+				switch tok.kind {
+				case tokString:
+					alts = append(alts, tok.value)
+				case tokComma:
+				default:
+					return nil, false
+				}
+			}
+			if len(alts) == 0 {
+				return nil, false
+			}
+			pattern = append(pattern, alts)
+		default:
+			return nil, false
+		}
+	}
+	return pattern, true
+}
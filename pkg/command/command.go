@@ -9,7 +9,9 @@ import (
 
 	"gopkg.in/yaml.v3"
 
+	"github.com/iheanyi/agentctl/pkg/condition"
 	"github.com/iheanyi/agentctl/pkg/jsonutil"
+	"github.com/iheanyi/agentctl/pkg/schema"
 )
 
 // InspectTitle returns the display name for the inspector modal header
@@ -70,11 +72,11 @@ func (c *Command) InspectContent() string {
 
 // Arg represents a command argument definition
 type Arg struct {
-	Type        string   `json:"type"`                  // "string", "number", "boolean"
-	Enum        []string `json:"enum,omitempty"`        // Allowed values
-	Default     any      `json:"default,omitempty"`     // Default value
-	Description string   `json:"description,omitempty"` // Help text
-	Required    bool     `json:"required,omitempty"`    // Is this arg required?
+	Type        string   `json:"type" jsonschema:"enum=string|number|boolean"` // "string", "number", "boolean"
+	Enum        []string `json:"enum,omitempty"`                               // Allowed values
+	Default     any      `json:"default,omitempty"`                            // Default value
+	Description string   `json:"description,omitempty"`                        // Help text
+	Required    bool     `json:"required,omitempty"`                           // Is this arg required?
 }
 
 // ToolOverride represents tool-specific command overrides
@@ -96,6 +98,7 @@ type Command struct {
 	DisallowedTools []string                `json:"disallowedTools,omitempty"` // Tools this command cannot use
 	Overrides       map[string]ToolOverride `json:"overrides,omitempty"`       // Per-tool overrides
 	PromptRef       string                  `json:"promptRef,omitempty"`       // Reference to a prompt template
+	When            *condition.Condition    `json:"when,omitempty"`            // Environments the command is synced in
 
 	// Runtime fields (not serialized)
 	Scope string `json:"-"` // "local" or "global" - where this command came from
@@ -110,6 +113,12 @@ func Load(path string) (*Command, error) {
 		return nil, err
 	}
 
+	if schema.Strict {
+		if err := schema.ValidateJSON(path, data, JSONSchema()); err != nil {
+			return nil, err
+		}
+	}
+
 	var cmd Command
 	if err := json.Unmarshal(data, &cmd); err != nil {
 		return nil, err
@@ -118,11 +127,21 @@ func Load(path string) (*Command, error) {
 	return &cmd, nil
 }
 
+// JSONSchema returns the JSON Schema of command JSON files
+func JSONSchema() *schema.Schema {
+	return schema.Generate(Command{}, schema.Options{
+		Name:        "command",
+		Title:       "agentctl command",
+		Description: "A slash command defined as JSON in the commands directory",
+	})
+}
+
 // MarkdownFrontmatter represents the YAML frontmatter in a markdown command file
 type MarkdownFrontmatter struct {
-	Name         string `yaml:"name"`
-	Description  string `yaml:"description"`
-	ArgumentHint string `yaml:"argument-hint"` // Codex-style argument hint
+	Name         string               `yaml:"name"`
+	Description  string               `yaml:"description"`
+	ArgumentHint string               `yaml:"argument-hint"` // Codex-style argument hint
+	When         *condition.Condition `yaml:"when,omitempty"`
 }
 
 // LoadMarkdown loads a command from a markdown file with YAML frontmatter
@@ -156,6 +175,7 @@ func LoadMarkdown(path string) (*Command, error) {
 		Name:         name,
 		Description:  fm.Description,
 		ArgumentHint: fm.ArgumentHint,
+		When:         fm.When,
 		Prompt:       strings.TrimSpace(content),
 		Path:         path,
 	}, nil
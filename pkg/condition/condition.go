@@ -0,0 +1,207 @@
+// Package condition evaluates the "when" conditions that limit servers
+// and resources to some environments, so one shared config can work
+// across laptops, devcontainers and CI.
+package condition
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// Condition is a set of checks that must all hold for an item to be
+// used. List entries may be negated with a leading "!", e.g. "!windows"
+// or "!CI". An empty condition always holds.
+type Condition struct {
+	OS        []string `json:"os,omitempty" yaml:"os,omitempty"`               // Any of these GOOS values ("macos" means darwin)
+	Hostname  string   `json:"hostname,omitempty" yaml:"hostname,omitempty"`   // Hostname glob, e.g. "ci-*"
+	Env       []string `json:"env,omitempty" yaml:"env,omitempty"`             // Variables that must be set; NAME=value also checks the value
+	Binary    []string `json:"binary,omitempty" yaml:"binary,omitempty"`       // Executables that must be on PATH
+	GitRemote string   `json:"gitRemote,omitempty" yaml:"gitRemote,omitempty"` // Glob matched against the project's git remote URLs
+}
+
+// IsEmpty returns true if the condition has no checks
+func (c *Condition) IsEmpty() bool {
+	return c == nil || (len(c.OS) == 0 && c.Hostname == "" && len(c.Env) == 0 && len(c.Binary) == 0 && c.GitRemote == "")
+}
+
+// Env is the environment conditions are evaluated against
+type Env struct {
+	OS       string
+	Hostname string
+	Getenv   func(string) (string, bool)
+	LookPath func(string) (string, error)
+
+	// GitRemotes returns the project's git remote URLs
+	GitRemotes func() []string
+}
+
+// Current returns the running machine's environment. Git remotes are
+// read from dir the first time a condition needs them.
+func Current(dir string) *Env {
+	hostname, _ := os.Hostname()
+	var once sync.Once
+	var remotes []string
+	return &Env{
+		OS:       runtime.GOOS,
+		Hostname: hostname,
+		Getenv:   os.LookupEnv,
+		LookPath: exec.LookPath,
+		GitRemotes: func() []string {
+			once.Do(func() { remotes = gitRemotes(dir) })
+			return remotes
+		},
+	}
+}
+
+// gitRemotes lists the remote URLs of the repository containing dir
+func gitRemotes(dir string) []string {
+	out, err := exec.Command("git", "-C", dir, "config", "--get-regexp", `^remote\..*\.url$`).Output()
+	if err != nil {
+		return nil
+	}
+	var urls []string
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		if _, url, ok := strings.Cut(line, " "); ok {
+			urls = append(urls, strings.TrimSpace(url))
+		}
+	}
+	return urls
+}
+
+// Evaluate reports whether the condition holds in env and, when it
+// doesn't, why not
+func (c *Condition) Evaluate(env *Env) (bool, string) {
+	if c.IsEmpty() {
+		return true, ""
+	}
+
+	if len(c.OS) > 0 {
+		if ok, reason := c.matchOS(env.OS); !ok {
+			return false, reason
+		}
+	}
+
+	if c.Hostname != "" {
+		pattern, negated := cutNot(c.Hostname)
+		if Match(strings.ToLower(pattern), strings.ToLower(env.Hostname)) == negated {
+			if negated {
+				return false, fmt.Sprintf("hostname %q matches %q", env.Hostname, pattern)
+			}
+			return false, fmt.Sprintf("hostname %q doesn't match %q", env.Hostname, pattern)
+		}
+	}
+
+	for _, entry := range c.Env {
+		entry, negated := cutNot(entry)
+		name, want, hasValue := strings.Cut(entry, "=")
+		value, set := env.Getenv(name)
+		ok := set && (!hasValue || value == want)
+		if ok == negated {
+			switch {
+			case negated && hasValue:
+				return false, fmt.Sprintf("$%s is %q", name, want)
+			case negated:
+				return false, fmt.Sprintf("$%s is set", name)
+			case hasValue && set:
+				return false, fmt.Sprintf("$%s is %q, not %q", name, value, want)
+			default:
+				return false, fmt.Sprintf("$%s is not set", name)
+			}
+		}
+	}
+
+	for _, entry := range c.Binary {
+		name, negated := cutNot(entry)
+		_, err := env.LookPath(name)
+		if (err == nil) == negated {
+			if negated {
+				return false, fmt.Sprintf("%s is on PATH", name)
+			}
+			return false, fmt.Sprintf("%s not found on PATH", name)
+		}
+	}
+
+	if c.GitRemote != "" {
+		pattern, negated := cutNot(c.GitRemote)
+		matched := false
+		for _, url := range env.GitRemotes() {
+			if Match(pattern, url) {
+				matched = true
+				break
+			}
+		}
+		if matched == negated {
+			if negated {
+				return false, fmt.Sprintf("a git remote matches %q", pattern)
+			}
+			return false, fmt.Sprintf("no git remote matches %q", pattern)
+		}
+	}
+
+	return true, ""
+}
+
+// matchOS checks goos against the OS list. Plain entries are
+// alternatives; negated entries must all fail to match.
+func (c *Condition) matchOS(goos string) (bool, string) {
+	var allowed []string
+	for _, entry := range c.OS {
+		name, negated := cutNot(entry)
+		name = normalizeOS(name)
+		if negated {
+			if name == goos {
+				return false, fmt.Sprintf("os is %s", goos)
+			}
+			continue
+		}
+		if name == goos {
+			return true, ""
+		}
+		allowed = append(allowed, name)
+	}
+	if len(allowed) > 0 {
+		return false, fmt.Sprintf("os is %s, not %s", goos, strings.Join(allowed, " or "))
+	}
+	return true, ""
+}
+
+// normalizeOS maps common OS names to GOOS values
+func normalizeOS(name string) string {
+	switch name = strings.ToLower(name); name {
+	case "macos", "mac", "osx":
+		return "darwin"
+	}
+	return name
+}
+
+// cutNot strips a leading "!" and reports whether it was there
+func cutNot(s string) (string, bool) {
+	if rest, ok := strings.CutPrefix(s, "!"); ok {
+		return rest, true
+	}
+	return s, false
+}
+
+// Match reports whether s matches a glob pattern in which "*" matches any
+// run of characters (including "/") and "?" matches one character
+func Match(pattern, s string) bool {
+	var re strings.Builder
+	re.WriteString("^")
+	for _, r := range pattern {
+		switch r {
+		case '*':
+			re.WriteString(".*")
+		case '?':
+			re.WriteString(".")
+		default:
+			re.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	re.WriteString("$")
+	return regexp.MustCompile(re.String()).MatchString(s)
+}
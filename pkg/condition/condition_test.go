@@ -0,0 +1,84 @@
+package condition
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func testEnv() *Env {
+	vars := map[string]string{"CI": "true", "DEPLOY_ENV": "staging"}
+	return &Env{
+		OS:       "linux",
+		Hostname: "CI-runner-7",
+		Getenv: func(name string) (string, bool) {
+			v, ok := vars[name]
+			return v, ok
+		},
+		LookPath: func(name string) (string, error) {
+			if name == "docker" {
+				return "/usr/bin/docker", nil
+			}
+			return "", errors.New("not found")
+		},
+		GitRemotes: func() []string {
+			return []string{"git@github.com:acme/api.git"}
+		},
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	tests := []struct {
+		name       string
+		cond       *Condition
+		want       bool
+		wantReason string
+	}{
+		{"nil", nil, true, ""},
+		{"os match", &Condition{OS: []string{"macos", "linux"}}, true, ""},
+		{"os mismatch", &Condition{OS: []string{"macos"}}, false, "os is linux, not darwin"},
+		{"os negated", &Condition{OS: []string{"!linux"}}, false, "os is linux"},
+		{"hostname glob ignores case", &Condition{Hostname: "ci-*"}, true, ""},
+		{"hostname mismatch", &Condition{Hostname: "laptop-*"}, false, `doesn't match "laptop-*"`},
+		{"env set", &Condition{Env: []string{"CI"}}, true, ""},
+		{"env missing", &Condition{Env: []string{"DOCKER_HOST"}}, false, "$DOCKER_HOST is not set"},
+		{"env value", &Condition{Env: []string{"DEPLOY_ENV=prod"}}, false, `$DEPLOY_ENV is "staging", not "prod"`},
+		{"env negated", &Condition{Env: []string{"!CI"}}, false, "$CI is set"},
+		{"binary present", &Condition{Binary: []string{"docker"}}, true, ""},
+		{"binary missing", &Condition{Binary: []string{"podman"}}, false, "podman not found on PATH"},
+		{"binary negated", &Condition{Binary: []string{"!podman"}}, true, ""},
+		{"git remote", &Condition{GitRemote: "*github.com?acme/*"}, true, ""},
+		{"git remote mismatch", &Condition{GitRemote: "*gitlab.com*"}, false, `no git remote matches "*gitlab.com*"`},
+		{"all must hold", &Condition{OS: []string{"linux"}, Binary: []string{"kubectl"}}, false, "kubectl not found on PATH"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, reason := tt.cond.Evaluate(testEnv())
+			if got != tt.want {
+				t.Errorf("Evaluate() = %v (%s), want %v", got, reason, tt.want)
+			}
+			if !strings.Contains(reason, tt.wantReason) || (tt.wantReason == "" && reason != "") {
+				t.Errorf("reason = %q, want %q", reason, tt.wantReason)
+			}
+		})
+	}
+}
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		pattern, s string
+		want       bool
+	}{
+		{"*", "anything/at/all", true},
+		{"ci-?", "ci-1", true},
+		{"ci-?", "ci-12", false},
+		{"https://github.com/acme/*", "https://github.com/acme/api", true},
+		{"a.b", "axb", false},
+	}
+	for _, tt := range tests {
+		if got := Match(tt.pattern, tt.s); got != tt.want {
+			t.Errorf("Match(%q, %q) = %v, want %v", tt.pattern, tt.s, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package config
+
+import (
+	"os"
+	"sort"
+
+	"github.com/iheanyi/agentctl/pkg/agent"
+	"github.com/iheanyi/agentctl/pkg/command"
+	"github.com/iheanyi/agentctl/pkg/condition"
+	"github.com/iheanyi/agentctl/pkg/rule"
+	"github.com/iheanyi/agentctl/pkg/skill"
+)
+
+// Exclusion is a server or resource left out because its "when"
+// condition doesn't hold in this environment
+type Exclusion struct {
+	Type   string `json:"type"` // "server", "command", "rule", "skill" or "agent"
+	Name   string `json:"name"`
+	Scope  string `json:"scope"`
+	Reason string `json:"reason"`
+}
+
+// ApplyConditions evaluates the "when" conditions of servers and loaded
+// resources against env. Excluded servers stay in the config, marked with
+// the reason, so saving doesn't drop them; excluded resources are moved
+// out of the loaded lists. Both are recorded in Excluded.
+func (c *Config) ApplyConditions(env *condition.Env) {
+	c.Excluded = nil
+
+	names := make([]string, 0, len(c.Servers))
+	for name := range c.Servers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		server := c.Servers[name]
+		if server == nil {
+			continue
+		}
+		_, server.Excluded = server.When.Evaluate(env)
+		if server.Excluded != "" {
+			c.Excluded = append(c.Excluded, Exclusion{Type: "server", Name: name, Scope: server.Scope, Reason: server.Excluded})
+		}
+	}
+
+	c.LoadedCommands = filterWhen(c, env, "command", c.LoadedCommands, func(cmd *command.Command) (string, string, *condition.Condition) {
+		return cmd.Name, cmd.Scope, cmd.When
+	})
+	c.LoadedRules = filterWhen(c, env, "rule", c.LoadedRules, func(r *rule.Rule) (string, string, *condition.Condition) {
+		if r.Frontmatter == nil {
+			return r.Name, r.Scope, nil
+		}
+		return r.Name, r.Scope, r.Frontmatter.When
+	})
+	c.LoadedSkills = filterWhen(c, env, "skill", c.LoadedSkills, func(s *skill.Skill) (string, string, *condition.Condition) {
+		return s.Name, s.Scope, s.When
+	})
+	c.LoadedAgents = filterWhen(c, env, "agent", c.LoadedAgents, func(a *agent.Agent) (string, string, *condition.Condition) {
+		return a.Name, a.Scope, a.When
+	})
+}
+
+// applyConditions evaluates conditions against the current machine, with
+// git remotes read from the project (or working) directory
+func (c *Config) applyConditions() {
+	dir := c.ProjectDir()
+	if dir == "" {
+		dir, _ = os.Getwd()
+	}
+	c.ApplyConditions(condition.Current(dir))
+}
+
+// filterWhen returns the items whose condition holds, recording the rest
+// as exclusions
+func filterWhen[T any](c *Config, env *condition.Env, kind string, items []T, describe func(T) (string, string, *condition.Condition)) []T {
+	var kept []T
+	for _, item := range items {
+		name, scope, when := describe(item)
+		if ok, reason := when.Evaluate(env); !ok {
+			c.Excluded = append(c.Excluded, Exclusion{Type: kind, Name: name, Scope: scope, Reason: reason})
+			continue
+		}
+		kept = append(kept, item)
+	}
+	return kept
+}
+
+// ExcludedFor returns the exclusions of the given type ("" for all) in scope
+func (c *Config) ExcludedFor(kind string, scope Scope) []Exclusion {
+	var excluded []Exclusion
+	for _, e := range c.Excluded {
+		if kind != "" && e.Type != kind {
+			continue
+		}
+		switch scope {
+		case ScopeLocal:
+			if e.Scope != string(ScopeLocal) {
+				continue
+			}
+		case ScopeGlobal:
+			if e.Scope == string(ScopeLocal) {
+				continue
+			}
+		}
+		excluded = append(excluded, e)
+	}
+	return excluded
+}
@@ -6,10 +6,16 @@ import (
 	"os"
 	"path/filepath"
 
+	"github.com/iheanyi/agentctl/pkg/agent"
 	"github.com/iheanyi/agentctl/pkg/command"
+	"github.com/iheanyi/agentctl/pkg/interp"
 	"github.com/iheanyi/agentctl/pkg/mcp"
+	"github.com/iheanyi/agentctl/pkg/permission"
+	"github.com/iheanyi/agentctl/pkg/plugin"
 	"github.com/iheanyi/agentctl/pkg/rule"
+	"github.com/iheanyi/agentctl/pkg/schema"
 	"github.com/iheanyi/agentctl/pkg/skill"
+	"github.com/iheanyi/agentctl/pkg/source"
 )
 
 // AutoUpdateConfig configures automatic update behavior
@@ -25,15 +31,42 @@ type ToolConfig struct {
 	Overrides map[string]any `json:"overrides,omitempty"`
 }
 
+// RegistryConfig points agentctl at MCP registries other than the public
+// defaults, e.g. a private mirror
+type RegistryConfig struct {
+	URL          string `json:"url,omitempty"`          // Official MCP registry API base URL
+	CommunityURL string `json:"communityUrl,omitempty"` // mcp.so API base URL
+}
+
+// SnapshotConfig sets how many sync snapshots are kept
+type SnapshotConfig struct {
+	Keep   int    `json:"keep,omitempty"`   // Snapshots to keep (default 20)
+	MaxAge string `json:"maxAge,omitempty"` // Drop older snapshots, e.g. "30d" or "72h"
+}
+
 // Settings contains global settings
 type Settings struct {
 	DefaultProfile string                `json:"defaultProfile,omitempty"`
 	AutoUpdate     AutoUpdateConfig      `json:"autoUpdate,omitempty"`
 	Tools          map[string]ToolConfig `json:"tools,omitempty"`
+	Registry       *RegistryConfig       `json:"registry,omitempty"`
+	Snapshots      *SnapshotConfig       `json:"snapshots,omitempty"`
+
+	// Sources are team or private repositories of aliases and resources
+	Sources []*source.Source `json:"sources,omitempty"`
+
+	// TeamConfig is a shared config layered under the global config: a
+	// file or directory path, or the name of a source shipping agentctl.json
+	TeamConfig string `json:"teamConfig,omitempty"`
+
+	// BridgeRemote runs remote servers through 'agentctl mcp-proxy' for
+	// tools that can only launch stdio servers, instead of leaving them out
+	BridgeRemote bool `json:"bridgeRemote,omitempty"`
 }
 
 // Config represents the main agentctl configuration
 type Config struct {
+	Schema   string                 `json:"$schema,omitempty"` // JSON Schema URL for editor support
 	Version  string                 `json:"version"`
 	Servers  map[string]*mcp.Server `json:"servers,omitempty"`
 	Commands []string               `json:"commands,omitempty"` // Command names to include
@@ -43,15 +76,46 @@ type Config struct {
 	Profile  string                 `json:"profile,omitempty"`  // Active profile (for project configs)
 	Settings Settings               `json:"settings,omitempty"`
 
+	// Permissions are allow/deny/ask tool patterns synced to every tool
+	Permissions *permission.Permissions `json:"permissions,omitempty"`
+
+	// Vars are user-defined ${name} variables for server settings and
+	// resource bodies
+	Vars map[string]string `json:"vars,omitempty"`
+
+	// Plugins and the marketplaces they come from
+	Plugins      []*plugin.Plugin               `json:"plugins,omitempty"`
+	Marketplaces map[string]*plugin.Marketplace `json:"marketplaces,omitempty"`
+
 	// Loaded resources (not serialized)
 	LoadedCommands []*command.Command `json:"-"`
 	LoadedRules    []*rule.Rule       `json:"-"`
 	LoadedSkills   []*skill.Skill     `json:"-"`
+	LoadedAgents   []*agent.Agent     `json:"-"`
+
+	// Servers and resources whose "when" condition doesn't hold (not serialized)
+	Excluded []Exclusion `json:"-"`
 
 	// Path info (not serialized)
 	Path        string `json:"-"` // Path to config file
 	ConfigDir   string `json:"-"` // Config directory
 	ProjectPath string `json:"-"` // Path to project config (if loaded from project)
+
+	// Layers this config was merged from (not serialized)
+	Layers []*Layer `json:"-"`
+
+	// loaded is a merged config as it was merged, so Save can tell what
+	// was edited since
+	loaded []byte
+}
+
+// JSONSchema returns the JSON Schema of agentctl.json and .agentctl.json
+func JSONSchema() *schema.Schema {
+	return schema.Generate(Config{}, schema.Options{
+		Name:        "config",
+		Title:       "agentctl configuration",
+		Description: "Global (agentctl.json) or project (.agentctl.json) agentctl configuration",
+	})
 }
 
 // DefaultConfigDir returns the default configuration directory
@@ -97,7 +161,7 @@ func Load() (*Config, error) {
 
 // LoadFrom loads configuration from a specific path
 func LoadFrom(path string) (*Config, error) {
-	data, err := os.ReadFile(path)
+	cfg, err := readConfig(path)
 	if err != nil {
 		if os.IsNotExist(err) {
 			// Return default config if file doesn't exist
@@ -114,55 +178,48 @@ func LoadFrom(path string) (*Config, error) {
 		return nil, err
 	}
 
-	var cfg Config
-	if err := json.Unmarshal(data, &cfg); err != nil {
-		return nil, err
-	}
-
-	cfg.Path = path
-	cfg.ConfigDir = filepath.Dir(path)
-
 	// Load resources from directories
 	if err := cfg.loadResources(); err != nil {
 		return nil, err
 	}
+	cfg.applyConditions()
 
-	return &cfg, nil
+	return cfg, nil
 }
 
-// LoadProjectConfig loads a project-local configuration and merges with global
-func LoadProjectConfig(projectDir string) (*Config, error) {
-	// Load global config first
-	globalCfg, err := Load()
+// readConfig reads a config file without loading its resources
+func readConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
 	if err != nil {
 		return nil, err
 	}
 
-	// Check for project config
-	projectPath := filepath.Join(projectDir, ".agentctl.json")
-	if _, err := os.Stat(projectPath); os.IsNotExist(err) {
-		return globalCfg, nil
+	if schema.Strict {
+		if err := schema.ValidateJSON(path, data, JSONSchema()); err != nil {
+			return nil, err
+		}
 	}
 
-	// Load project config
-	projectCfg, err := LoadFrom(projectPath)
-	if err != nil {
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
 		return nil, err
 	}
 
-	// Mark that we have a project config
-	globalCfg.ProjectPath = projectPath
-
-	// Merge: project overrides global
-	merged := globalCfg.Merge(projectCfg)
-	merged.ProjectPath = projectPath
+	cfg.Path = path
+	cfg.ConfigDir = filepath.Dir(path)
+	return &cfg, nil
+}
 
-	// Load local resources from .agentctl/ directory
-	if err := merged.loadLocalResources(); err != nil {
+// LoadProjectConfig loads the layered configuration for projectDir: the
+// system config, the team config, the global config, every .agentctl.json
+// from the repository root down to projectDir, and environment overrides.
+// See LoadLayers.
+func LoadProjectConfig(projectDir string) (*Config, error) {
+	layers, err := LoadLayers(projectDir)
+	if err != nil {
 		return nil, err
 	}
-
-	return merged, nil
+	return MergeLayers(layers)
 }
 
 // LoadWithProject loads global config merged with any project config in cwd
@@ -212,6 +269,7 @@ func InitProjectConfig(dir string) (*Config, error) {
 	}
 
 	cfg := &Config{
+		Schema:      schema.URL("config"),
 		Version:     "1",
 		Servers:     make(map[string]*mcp.Server),
 		Path:        configPath,
@@ -229,12 +287,19 @@ func InitProjectConfig(dir string) (*Config, error) {
 // Merge merges another config into this one (other takes precedence)
 // Servers from the base config are marked as "global", servers from other are marked as "local"
 func (c *Config) Merge(other *Config) *Config {
+	return c.mergeScoped(other, ScopeLocal)
+}
+
+// mergeScoped merges other into this config, marking servers from other
+// with scope
+func (c *Config) mergeScoped(other *Config, scope Scope) *Config {
 	merged := &Config{
 		Version:   c.Version,
 		Servers:   make(map[string]*mcp.Server),
 		ConfigDir: c.ConfigDir,
 		Path:      c.Path,
-		Settings:  c.Settings,
+		Settings:  c.Settings.overlay(other.Settings),
+		Profile:   c.Profile,
 	}
 
 	// Copy servers from base (global)
@@ -246,10 +311,10 @@ func (c *Config) Merge(other *Config) *Config {
 		merged.Servers[name] = &serverCopy
 	}
 
-	// Override/add servers from other (local)
+	// Override/add servers from other
 	for name, server := range other.Servers {
 		serverCopy := *server
-		serverCopy.Scope = string(ScopeLocal)
+		serverCopy.Scope = string(scope)
 		merged.Servers[name] = &serverCopy
 	}
 
@@ -266,6 +331,31 @@ func (c *Config) Merge(other *Config) *Config {
 		merged.Skills = removeFromSlice(merged.Skills, disabled)
 	}
 
+	// Project permissions extend the global policy
+	merged.Permissions = c.Permissions.Merge(other.Permissions)
+
+	// Project plugins override global plugins with the same ID
+	merged.Plugins = plugin.Merge(c.Plugins, other.Plugins)
+	if len(c.Marketplaces) > 0 || len(other.Marketplaces) > 0 {
+		merged.Marketplaces = make(map[string]*plugin.Marketplace)
+		for name, m := range c.Marketplaces {
+			merged.Marketplaces[name] = m
+		}
+		for name, m := range other.Marketplaces {
+			merged.Marketplaces[name] = m
+		}
+	}
+
+	if len(c.Vars) > 0 || len(other.Vars) > 0 {
+		merged.Vars = make(map[string]string)
+		for name, value := range c.Vars {
+			merged.Vars[name] = value
+		}
+		for name, value := range other.Vars {
+			merged.Vars[name] = value
+		}
+	}
+
 	// Use profile from other if specified
 	if other.Profile != "" {
 		merged.Profile = other.Profile
@@ -274,8 +364,75 @@ func (c *Config) Merge(other *Config) *Config {
 	return merged
 }
 
-// Save saves the configuration to disk
+// overlay returns these settings with the fields set in other applied
+// on top. Tools and sources are merged by name.
+func (s Settings) overlay(other Settings) Settings {
+	merged := s
+	if other.DefaultProfile != "" {
+		merged.DefaultProfile = other.DefaultProfile
+	}
+	if other.AutoUpdate.Enabled || other.AutoUpdate.Interval != "" || len(other.AutoUpdate.Servers) > 0 {
+		merged.AutoUpdate = other.AutoUpdate
+	}
+	if len(s.Tools) > 0 || len(other.Tools) > 0 {
+		merged.Tools = make(map[string]ToolConfig)
+		for name, tool := range s.Tools {
+			merged.Tools[name] = tool
+		}
+		for name, tool := range other.Tools {
+			merged.Tools[name] = tool
+		}
+	}
+	if other.Registry != nil {
+		registry := RegistryConfig{}
+		if s.Registry != nil {
+			registry = *s.Registry
+		}
+		if other.Registry.URL != "" {
+			registry.URL = other.Registry.URL
+		}
+		if other.Registry.CommunityURL != "" {
+			registry.CommunityURL = other.Registry.CommunityURL
+		}
+		merged.Registry = &registry
+	}
+	if other.Snapshots != nil {
+		snapshots := SnapshotConfig{}
+		if s.Snapshots != nil {
+			snapshots = *s.Snapshots
+		}
+		if other.Snapshots.Keep != 0 {
+			snapshots.Keep = other.Snapshots.Keep
+		}
+		if other.Snapshots.MaxAge != "" {
+			snapshots.MaxAge = other.Snapshots.MaxAge
+		}
+		merged.Snapshots = &snapshots
+	}
+	if len(other.Sources) > 0 {
+		merged.Sources = nil
+		for _, src := range s.Sources {
+			if source.Find(other.Sources, src.Name) == nil {
+				merged.Sources = append(merged.Sources, src)
+			}
+		}
+		merged.Sources = append(merged.Sources, other.Sources...)
+	}
+	if other.TeamConfig != "" {
+		merged.TeamConfig = other.TeamConfig
+	}
+	if other.BridgeRemote {
+		merged.BridgeRemote = true
+	}
+	return merged
+}
+
+// Save saves the configuration to disk. A config merged from layers saves
+// only what was edited since it was loaded; see saveLayered.
 func (c *Config) Save() error {
+	if c.loaded != nil {
+		return c.saveLayered()
+	}
 	return c.SaveTo(c.Path)
 }
 
@@ -334,9 +491,87 @@ func (c *Config) loadResourcesWithScope(scope string) error {
 		s.Scope = scope
 	}
 
+	// Load agents
+	c.LoadedAgents, err = agent.LoadAll(filepath.Join(c.ConfigDir, "agents"))
+	if err != nil {
+		return err
+	}
+	for _, a := range c.LoadedAgents {
+		a.Scope = scope
+	}
+
+	return c.loadSourceResources(scope)
+}
+
+// loadSourceResources adds the resources shipped by configured sources.
+// Resources defined in the config directory win over source resources,
+// and higher-priority sources win over lower ones. Sources that haven't
+// been fetched yet are skipped.
+func (c *Config) loadSourceResources(scope string) error {
+	cacheDir := DefaultCacheDir()
+	for _, src := range source.Sorted(c.Settings.Sources) {
+		dir := src.Dir(cacheDir)
+		if _, err := os.Stat(dir); err != nil {
+			continue
+		}
+
+		commands, err := command.LoadAll(filepath.Join(dir, "commands"))
+		if err != nil {
+			return fmt.Errorf("source %q: %w", src.Name, err)
+		}
+		for _, cmd := range commands {
+			if !hasNamed(c.LoadedCommands, cmd.Name, func(x *command.Command) string { return x.Name }) {
+				cmd.Scope = scope
+				c.LoadedCommands = append(c.LoadedCommands, cmd)
+			}
+		}
+
+		rules, err := rule.LoadAll(filepath.Join(dir, "rules"))
+		if err != nil {
+			return fmt.Errorf("source %q: %w", src.Name, err)
+		}
+		for _, r := range rules {
+			if !hasNamed(c.LoadedRules, r.Name, func(x *rule.Rule) string { return x.Name }) {
+				r.Scope = scope
+				c.LoadedRules = append(c.LoadedRules, r)
+			}
+		}
+
+		skills, err := skill.LoadAll(filepath.Join(dir, "skills"))
+		if err != nil {
+			return fmt.Errorf("source %q: %w", src.Name, err)
+		}
+		for _, s := range skills {
+			if !hasNamed(c.LoadedSkills, s.Name, func(x *skill.Skill) string { return x.Name }) {
+				s.Scope = scope
+				c.LoadedSkills = append(c.LoadedSkills, s)
+			}
+		}
+
+		agents, err := agent.LoadAll(filepath.Join(dir, "agents"))
+		if err != nil {
+			return fmt.Errorf("source %q: %w", src.Name, err)
+		}
+		for _, a := range agents {
+			if !hasNamed(c.LoadedAgents, a.Name, func(x *agent.Agent) string { return x.Name }) {
+				a.Scope = scope
+				c.LoadedAgents = append(c.LoadedAgents, a)
+			}
+		}
+	}
 	return nil
 }
 
+// hasNamed reports whether items contains an item with the given name
+func hasNamed[T any](items []T, name string, nameOf func(T) string) bool {
+	for _, item := range items {
+		if nameOf(item) == name {
+			return true
+		}
+	}
+	return false
+}
+
 // loadLocalResources loads resources from the project-local .agentctl/ directory
 func (c *Config) loadLocalResources() error {
 	if c.ProjectPath == "" {
@@ -376,6 +611,13 @@ func (c *Config) loadLocalResources() error {
 	}
 	c.LoadedSkills = append(c.LoadedSkills, localSkills...)
 
+	// Load local agents
+	localAgents, err := agent.LoadFromDirectory(filepath.Join(localResourceDir, "agents"), string(ScopeLocal), "")
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	c.LoadedAgents = append(c.LoadedAgents, localAgents...)
+
 	return nil
 }
 
@@ -394,6 +636,7 @@ func (c *Config) ReloadResources() error {
 	c.LoadedCommands = nil
 	c.LoadedRules = nil
 	c.LoadedSkills = nil
+	c.LoadedAgents = nil
 
 	// Reload global resources
 	if err := c.loadResourcesWithScope(string(ScopeGlobal)); err != nil {
@@ -401,20 +644,32 @@ func (c *Config) ReloadResources() error {
 	}
 
 	// Reload local resources
-	return c.loadLocalResources()
+	if err := c.loadLocalResources(); err != nil {
+		return err
+	}
+
+	c.applyConditions()
+	return nil
 }
 
-// ActiveServers returns the list of non-disabled servers
+// ActiveServers returns the list of servers that aren't disabled or
+// excluded by their condition
 func (c *Config) ActiveServers() []*mcp.Server {
 	var servers []*mcp.Server
 	for _, server := range c.Servers {
-		if !server.Disabled {
+		if !server.Disabled && server.Excluded == "" {
 			servers = append(servers, server)
 		}
 	}
 	return servers
 }
 
+// InterpContext returns the context ${...} variables in servers and
+// resources are resolved in at sync time
+func (c *Config) InterpContext() *interp.Context {
+	return interp.NewContext(c.ProjectDir(), c.ConfigDir, DefaultCacheDir(), c.Vars)
+}
+
 // CacheDir returns the cache directory for this config
 func (c *Config) CacheDir() string {
 	return DefaultCacheDir()
@@ -533,7 +788,7 @@ func (c *Config) SaveScoped(scope Scope) error {
 func (c *Config) ServersForScope(scope Scope) []*mcp.Server {
 	var servers []*mcp.Server
 	for _, server := range c.Servers {
-		if server.Disabled {
+		if server.Disabled || server.Excluded != "" {
 			continue
 		}
 		switch scope {
@@ -624,6 +879,26 @@ func (c *Config) SkillsForScope(scope Scope) []*skill.Skill {
 	return skills
 }
 
+// AgentsForScope returns agents that belong to a specific scope
+func (c *Config) AgentsForScope(scope Scope) []*agent.Agent {
+	var agents []*agent.Agent
+	for _, a := range c.LoadedAgents {
+		switch scope {
+		case ScopeLocal:
+			if a.Scope == string(ScopeLocal) {
+				agents = append(agents, a)
+			}
+		case ScopeGlobal:
+			if a.Scope == string(ScopeGlobal) || a.Scope == "" {
+				agents = append(agents, a)
+			}
+		case ScopeAll:
+			agents = append(agents, a)
+		}
+	}
+	return agents
+}
+
 // ProjectDir returns the project directory if a project config is loaded
 func (c *Config) ProjectDir() string {
 	if c.ProjectPath == "" {
@@ -2,11 +2,15 @@ package config
 
 import (
 	"encoding/json"
+	"errors"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/iheanyi/agentctl/pkg/agent"
 	"github.com/iheanyi/agentctl/pkg/command"
+	"github.com/iheanyi/agentctl/pkg/condition"
 	"github.com/iheanyi/agentctl/pkg/mcp"
 	"github.com/iheanyi/agentctl/pkg/rule"
 	"github.com/iheanyi/agentctl/pkg/skill"
@@ -110,9 +114,15 @@ func TestLoadAndSave(t *testing.T) {
 		t.Fatalf("Failed to save config: %v", err)
 	}
 
-	// Verify file exists
-	if _, err := os.Stat(configPath); os.IsNotExist(err) {
-		t.Fatal("Config file was not created")
+	// Verify file exists, without settings that were never set
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		t.Fatalf("Config file was not created: %v", err)
+	}
+	for _, key := range []string{`"registry"`, `"snapshots"`} {
+		if strings.Contains(string(data), key) {
+			t.Errorf("saved config has empty %s:\n%s", key, data)
+		}
 	}
 
 	// Load it back
@@ -480,6 +490,7 @@ func TestMergeScopeTracking(t *testing.T) {
 			"server1": {Name: "server1", Command: "cmd1"},
 			"server2": {Name: "server2", Command: "cmd2"},
 		},
+		Vars: map[string]string{"region": "us-east-1", "team": "core"},
 	}
 
 	overlay := &Config{
@@ -487,10 +498,16 @@ func TestMergeScopeTracking(t *testing.T) {
 		Servers: map[string]*mcp.Server{
 			"server3": {Name: "server3", Command: "cmd3"},
 		},
+		Vars: map[string]string{"region": "eu-west-1"},
 	}
 
 	merged := base.Merge(overlay)
 
+	// Project vars override global vars of the same name
+	if merged.Vars["region"] != "eu-west-1" || merged.Vars["team"] != "core" {
+		t.Errorf("merged vars = %v", merged.Vars)
+	}
+
 	// Base servers should be marked as global
 	if merged.Servers["server1"].Scope != string(ScopeGlobal) {
 		t.Errorf("server1 should have global scope, got %q", merged.Servers["server1"].Scope)
@@ -682,3 +699,395 @@ func TestSkillsForScope(t *testing.T) {
 		}
 	})
 }
+
+func TestAgentsForScope(t *testing.T) {
+	cfg := &Config{
+		LoadedAgents: []*agent.Agent{
+			{Name: "global1", Scope: string(ScopeGlobal)},
+			{Name: "unscoped"},
+			{Name: "local1", Scope: string(ScopeLocal)},
+		},
+	}
+
+	if agents := cfg.AgentsForScope(ScopeGlobal); len(agents) != 2 {
+		t.Errorf("Expected 2 global agents, got %d", len(agents))
+	}
+	if agents := cfg.AgentsForScope(ScopeLocal); len(agents) != 1 || agents[0].Name != "local1" {
+		t.Errorf("Expected local1 only, got %v", agents)
+	}
+	if agents := cfg.AgentsForScope(ScopeAll); len(agents) != 3 {
+		t.Errorf("Expected 3 agents, got %d", len(agents))
+	}
+}
+
+func TestSourceResources(t *testing.T) {
+	configDir := t.TempDir()
+	cacheDir := t.TempDir()
+	teamDir := t.TempDir()
+	t.Setenv("XDG_CACHE_HOME", cacheDir)
+
+	write := func(path, content string) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(filepath.Join(configDir, "rules", "style.md"), "# My style")
+	write(filepath.Join(teamDir, "rules", "style.md"), "# Team style")
+	write(filepath.Join(teamDir, "rules", "security.md"), "# Security")
+	write(filepath.Join(teamDir, "agents", "oncall.md"), "---\nname: oncall\ndescription: On-call helper\n---\nHelp with incidents.")
+	write(filepath.Join(configDir, "agentctl.json"), `{
+  "version": "1",
+  "settings": {"sources": [
+    {"name": "team", "url": "`+teamDir+`"},
+    {"name": "unfetched", "url": "https://example.com/aliases.json"}
+  ]}
+}`)
+
+	cfg, err := LoadFrom(filepath.Join(configDir, "agentctl.json"))
+	if err != nil {
+		t.Fatalf("LoadFrom() error = %v", err)
+	}
+
+	rules := make(map[string]string)
+	for _, r := range cfg.LoadedRules {
+		rules[r.Name] = r.Content
+	}
+	if len(rules) != 2 {
+		t.Fatalf("LoadedRules = %v, want style and security", rules)
+	}
+	if !strings.Contains(rules["style"], "My style") {
+		t.Errorf("own rule should win over the source's, got %q", rules["style"])
+	}
+	if len(cfg.LoadedAgents) != 1 || cfg.LoadedAgents[0].Name != "oncall" {
+		t.Errorf("LoadedAgents = %+v, want oncall from the source", cfg.LoadedAgents)
+	}
+}
+
+func TestConfigPaths(t *testing.T) {
+	cfg := &Config{
+		Version: "1",
+		Servers: map[string]*mcp.Server{
+			"fs": {Name: "fs", Command: "npx", Args: []string{"-y"}},
+		},
+		Path: filepath.Join(t.TempDir(), "agentctl.json"),
+	}
+
+	steps := []struct {
+		op      string // set, append, remove or unset
+		path    string
+		values  []string
+		wantErr string
+	}{
+		{op: "set", path: "servers.github.command", values: []string{"gh-mcp"}},
+		{op: "set", path: `servers."my.server".url`, values: []string{"https://example.com/mcp"}},
+		{op: "set", path: "servers.github.env.TOKEN", values: []string{"keychain:gh"}},
+		{op: "set", path: "settings.tools.cursor.enabled", values: []string{"true"}},
+		{op: "set", path: "settings.snapshots.keep", values: []string{"5"}},
+		{op: "set", path: "commands", values: []string{"a, b"}},
+		{op: "append", path: "servers.fs.args", values: []string{"pkg", "-y"}},
+		{op: "set", path: "servers.fs.args[0]", values: []string{"--yes"}},
+		{op: "remove", path: "commands", values: []string{"a"}},
+		{op: "unset", path: "servers.fs.args.1"},
+		{op: "set", path: "servers.fs.transport", values: []string{"grpc"}, wantErr: `"grpc" is not one of`},
+		{op: "set", path: "servers.fs.trasnport", values: []string{"http"}, wantErr: `unknown field "trasnport"`},
+		{op: "set", path: "settings.tools.cursor.enabled", values: []string{"maybe"}, wantErr: "not a boolean"},
+		{op: "append", path: "servers.fs.command", values: []string{"x"}, wantErr: "not a list"},
+		{op: "remove", path: "commands", values: []string{"zzz"}, wantErr: "not in the list"},
+		{op: "unset", path: "servers.nope.env", wantErr: "path not found"},
+	}
+	for _, step := range steps {
+		var err error
+		switch step.op {
+		case "set":
+			err = cfg.SetPath(step.path, step.values[0])
+		case "append":
+			err = cfg.AppendPath(step.path, step.values...)
+		case "remove":
+			err = cfg.RemovePath(step.path, step.values...)
+		case "unset":
+			err = cfg.UnsetPath(step.path)
+		}
+		if step.wantErr == "" && err != nil {
+			t.Fatalf("%s %s: %v", step.op, step.path, err)
+		}
+		if step.wantErr != "" && (err == nil || !strings.Contains(err.Error(), step.wantErr)) {
+			t.Errorf("%s %s error = %v, want %q", step.op, step.path, err, step.wantErr)
+		}
+	}
+
+	github := cfg.Servers["github"]
+	if github == nil || github.Name != "github" || github.Command != "gh-mcp" || github.Env["TOKEN"] != "keychain:gh" {
+		t.Errorf("github server = %+v", github)
+	}
+	if s := cfg.Servers["my.server"]; s == nil || s.URL != "https://example.com/mcp" {
+		t.Errorf("quoted key server = %+v", s)
+	}
+	if !cfg.Settings.Tools["cursor"].Enabled || cfg.Settings.Snapshots.Keep != 5 {
+		t.Errorf("settings = %+v", cfg.Settings)
+	}
+	if got := strings.Join(cfg.Commands, ","); got != "b" {
+		t.Errorf("commands = %q, want b", got)
+	}
+	if got := strings.Join(cfg.Servers["fs"].Args, ","); got != "--yes" {
+		t.Errorf("fs args = %q, want --yes", got)
+	}
+	if cfg.Servers["fs"].Transport != "" {
+		t.Errorf("transport = %q after a rejected set", cfg.Servers["fs"].Transport)
+	}
+
+	value, err := cfg.GetPath("servers.github.env")
+	if err != nil || value.(map[string]string)["TOKEN"] != "keychain:gh" {
+		t.Errorf("GetPath(env) = %v, %v", value, err)
+	}
+	if _, err := cfg.GetPath("servers.missing.command"); !errors.Is(err, ErrPathNotFound) {
+		t.Errorf("GetPath(missing) error = %v", err)
+	}
+}
+
+func TestParsePath(t *testing.T) {
+	tests := []struct {
+		path    string
+		want    string
+		wantErr bool
+	}{
+		{"servers.fs.args", "servers|fs|args", false},
+		{"servers.fs.args[0]", "servers|fs|args|0", false},
+		{"a[0][1].b", "a|0|1|b", false},
+		{`servers."my.server".url`, "servers|my.server|url", false},
+		{"", "", true},
+		{"a..b", "", true},
+		{"a.", "", true},
+		{"a[0", "", true},
+		{`a."b`, "", true},
+	}
+	for _, tt := range tests {
+		parts, err := ParsePath(tt.path)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParsePath(%q) error = %v", tt.path, err)
+			continue
+		}
+		if got := strings.Join(parts, "|"); !tt.wantErr && got != tt.want {
+			t.Errorf("ParsePath(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestLoadLayers(t *testing.T) {
+	tmp := t.TempDir()
+	write := func(path, content string) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	systemPath := filepath.Join(tmp, "etc", "agentctl.json")
+	teamDir := filepath.Join(tmp, "team")
+	globalDir := filepath.Join(tmp, "global")
+	repo := filepath.Join(tmp, "repo")
+	pkgDir := filepath.Join(repo, "packages", "api")
+	t.Setenv("AGENTCTL_SYSTEM_CONFIG", systemPath)
+	t.Setenv("AGENTCTL_HOME", globalDir)
+	t.Setenv("XDG_CACHE_HOME", filepath.Join(tmp, "cache"))
+
+	write(systemPath, `{"version": "1", "settings": {"defaultProfile": "system", "teamConfig": "`+teamDir+`", "snapshots": {"keep": 5}}}`)
+	write(filepath.Join(teamDir, "agentctl.json"), `{"version": "1", "servers": {"jira": {"command": "jira-mcp"}}, "settings": {"defaultProfile": "team"}}`)
+	write(filepath.Join(globalDir, "agentctl.json"), `{"version": "1", "servers": {"github": {"command": "gh-mcp"}}, "commands": ["review"]}`)
+	write(filepath.Join(repo, ".agentctl.json"), `{"version": "1", "servers": {"github": {"command": "gh-mcp", "args": ["--repo"]}}, "commands": ["deploy"]}`)
+	write(filepath.Join(pkgDir, ".agentctl.json"), `{"version": "1", "servers": {"db": {"command": "db-mcp"}}, "disabled": ["jira"]}`)
+	if err := os.Mkdir(filepath.Join(repo, ".git"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("AGENTCTL_CONFIG_SETTINGS__DEFAULT_PROFILE", "env")
+	t.Setenv("AGENTCTL_CONFIG_SERVERS__GITHUB__ENV__GITHUB_TOKEN", "keychain:gh")
+
+	cfg, err := LoadProjectConfig(pkgDir)
+	if err != nil {
+		t.Fatalf("LoadProjectConfig() error = %v", err)
+	}
+
+	var kinds []string
+	for _, l := range cfg.Layers {
+		kinds = append(kinds, string(l.Kind))
+	}
+	if got := strings.Join(kinds, ","); got != "system,team,global,project,project,env,env" {
+		t.Errorf("layers = %s", got)
+	}
+	if cfg.Path != filepath.Join(globalDir, "agentctl.json") {
+		t.Errorf("Path = %q, want the global config", cfg.Path)
+	}
+	if cfg.ProjectPath != filepath.Join(pkgDir, ".agentctl.json") {
+		t.Errorf("ProjectPath = %q, want the nearest project config", cfg.ProjectPath)
+	}
+	if _, ok := cfg.Servers["jira"]; ok {
+		t.Error("jira should be disabled by the package config")
+	}
+	github := cfg.Servers["github"]
+	if github == nil || len(github.Args) != 1 || github.Scope != string(ScopeLocal) || github.Env["GITHUB_TOKEN"] != "keychain:gh" {
+		t.Errorf("github = %+v", github)
+	}
+	if cfg.Servers["db"] == nil {
+		t.Error("db from the package config is missing")
+	}
+	if got := strings.Join(cfg.Commands, ","); got != "review,deploy" {
+		t.Errorf("commands = %q", got)
+	}
+	if cfg.Settings.DefaultProfile != "env" || cfg.Settings.Snapshots.Keep != 5 {
+		t.Errorf("settings = %+v", cfg.Settings)
+	}
+
+	exp, err := cfg.Explain("settings.defaultProfile")
+	if err != nil {
+		t.Fatalf("Explain() error = %v", err)
+	}
+	if len(exp.Layers) != 3 || exp.Winner() == nil || exp.Winner().Path != "AGENTCTL_CONFIG_SETTINGS__DEFAULT_PROFILE" {
+		t.Errorf("Explain(defaultProfile) = %+v", exp)
+	}
+	exp, err = cfg.Explain("commands")
+	if err != nil {
+		t.Fatalf("Explain() error = %v", err)
+	}
+	if len(exp.Layers) != 2 || exp.Winner() != nil {
+		t.Errorf("merged list should combine layers, got %+v", exp)
+	}
+	if _, err := cfg.Explain("settings.nope"); err == nil {
+		t.Error("Explain() should reject unknown fields")
+	}
+
+	// Outside a repository only the directory's own config applies
+	if err := os.Remove(filepath.Join(repo, ".git")); err != nil {
+		t.Fatal(err)
+	}
+	if got := projectConfigPaths(pkgDir); len(got) != 1 {
+		t.Errorf("projectConfigPaths() = %v, want only the package config", got)
+	}
+}
+
+func TestSaveLayered(t *testing.T) {
+	tmp := t.TempDir()
+	write := func(path, content string) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	read := func(path string) *Config {
+		t.Helper()
+		cfg, err := readConfig(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return cfg
+	}
+
+	systemPath := filepath.Join(tmp, "etc", "agentctl.json")
+	globalPath := filepath.Join(tmp, "global", "agentctl.json")
+	repo := filepath.Join(tmp, "repo")
+	projectPath := filepath.Join(repo, ".agentctl.json")
+	t.Setenv("AGENTCTL_SYSTEM_CONFIG", systemPath)
+	t.Setenv("AGENTCTL_HOME", filepath.Dir(globalPath))
+	t.Setenv("XDG_CACHE_HOME", filepath.Join(tmp, "cache"))
+	t.Setenv("AGENTCTL_CONFIG_SETTINGS__DEFAULT_PROFILE", "env")
+
+	write(systemPath, `{"version": "1", "servers": {"jira": {"command": "jira-mcp"}}, "commands": ["audit"]}`)
+	write(globalPath, `{"version": "1", "servers": {"github": {"command": "gh-mcp"}}, "commands": ["review"]}`)
+	write(projectPath, `{"version": "1", "servers": {"db": {"command": "db-mcp"}}}`)
+
+	cfg, err := LoadProjectConfig(repo)
+	if err != nil {
+		t.Fatalf("LoadProjectConfig() error = %v", err)
+	}
+
+	// Saving without edits writes nothing from the other layers
+	if err := cfg.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	global := read(globalPath)
+	if len(global.Servers) != 1 || global.Settings.DefaultProfile != "" || strings.Join(global.Commands, ",") != "review" {
+		t.Errorf("unedited save changed the global config: %+v", global)
+	}
+
+	cfg.Servers["github"].Disabled = true
+	cfg.Servers["db"].Disabled = true
+	cfg.Servers["new"] = &mcp.Server{Command: "new-mcp"}
+	cfg.Commands = append(cfg.Commands, "ship")
+	if err := cfg.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	global = read(globalPath)
+	if global.Servers["github"] == nil || !global.Servers["github"].Disabled || global.Servers["new"] == nil {
+		t.Errorf("global servers = %+v, want github disabled and new added", global.Servers)
+	}
+	if global.Servers["jira"] != nil || global.Servers["db"] != nil {
+		t.Errorf("servers from other layers were copied into the global config: %+v", global.Servers)
+	}
+	if got := strings.Join(global.Commands, ","); got != "review,ship" {
+		t.Errorf("global commands = %q, want review,ship", got)
+	}
+	if global.Settings.DefaultProfile != "" {
+		t.Errorf("environment override was saved: %+v", global.Settings)
+	}
+	if project := read(projectPath); project.Servers["db"] == nil || !project.Servers["db"].Disabled {
+		t.Errorf("project servers = %+v, want db disabled", project.Servers)
+	}
+}
+
+func TestApplyConditions(t *testing.T) {
+	cfg := &Config{
+		Servers: map[string]*mcp.Server{
+			"docker": {Name: "docker", When: &condition.Condition{Binary: []string{"docker"}}},
+			"fs":     {Name: "fs"},
+		},
+		LoadedCommands: []*command.Command{
+			{Name: "deploy", When: &condition.Condition{Env: []string{"CI"}}},
+			{Name: "review"},
+		},
+		LoadedRules: []*rule.Rule{
+			{Name: "mac", Frontmatter: &rule.Frontmatter{When: &condition.Condition{OS: []string{"darwin"}}}},
+			{Name: "plain"},
+		},
+	}
+	env := &condition.Env{
+		OS:       "linux",
+		Getenv:   func(string) (string, bool) { return "", false },
+		LookPath: func(string) (string, error) { return "", errors.New("not found") },
+	}
+
+	cfg.ApplyConditions(env)
+
+	if active := cfg.ActiveServers(); len(active) != 1 || active[0].Name != "fs" {
+		t.Errorf("ActiveServers() = %v, want only fs", active)
+	}
+	if cfg.Servers["docker"] == nil {
+		t.Error("excluded servers should stay in the config")
+	}
+	if len(cfg.LoadedCommands) != 1 || cfg.LoadedCommands[0].Name != "review" {
+		t.Errorf("LoadedCommands = %v, want only review", cfg.LoadedCommands)
+	}
+	if len(cfg.LoadedRules) != 1 || cfg.LoadedRules[0].Name != "plain" {
+		t.Errorf("LoadedRules = %v, want only plain", cfg.LoadedRules)
+	}
+
+	var got []string
+	for _, e := range cfg.ExcludedFor("", ScopeAll) {
+		got = append(got, e.Type+":"+e.Name+":"+e.Reason)
+	}
+	want := "server:docker:docker not found on PATH,command:deploy:$CI is not set,rule:mac:os is linux, not darwin"
+	if strings.Join(got, ",") != want {
+		t.Errorf("Excluded = %v, want %s", got, want)
+	}
+	if len(cfg.ExcludedFor("rule", ScopeAll)) != 1 {
+		t.Errorf("ExcludedFor(rule) = %v", cfg.ExcludedFor("rule", ScopeAll))
+	}
+}
@@ -0,0 +1,643 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/iheanyi/agentctl/pkg/mcp"
+	"github.com/iheanyi/agentctl/pkg/source"
+)
+
+// LayerKind identifies where a config layer comes from
+type LayerKind string
+
+// Layer kinds, from lowest to highest precedence
+const (
+	LayerSystem  LayerKind = "system"  // Machine-wide config, e.g. /etc/agentctl/agentctl.json
+	LayerTeam    LayerKind = "team"    // Shared team config named by settings.teamConfig
+	LayerGlobal  LayerKind = "global"  // User config, ~/.config/agentctl/agentctl.json
+	LayerProject LayerKind = "project" // .agentctl.json in the project or a parent directory
+	LayerEnv     LayerKind = "env"     // AGENTCTL_CONFIG_* environment variable
+)
+
+// EnvOverridePrefix starts the names of environment variables that
+// override config values. The rest of the name is the config path with
+// "__" between segments, e.g. AGENTCTL_CONFIG_SETTINGS__DEFAULT_PROFILE.
+const EnvOverridePrefix = "AGENTCTL_CONFIG_"
+
+// Layer is one config file (or environment variable) in the layer stack
+type Layer struct {
+	Kind   LayerKind `json:"kind"`
+	Path   string    `json:"path"` // Config file, or the variable name for env layers
+	Config *Config   `json:"-"`    // The layer's own, unmerged values
+}
+
+// String describes the layer for display
+func (l *Layer) String() string {
+	return fmt.Sprintf("%s (%s)", l.Kind, l.Path)
+}
+
+// SystemConfigPath returns the machine-wide config path. AGENTCTL_SYSTEM_CONFIG
+// overrides the default of /etc/agentctl/agentctl.json
+// (%ProgramData%\agentctl\agentctl.json on Windows).
+func SystemConfigPath() string {
+	if path := os.Getenv("AGENTCTL_SYSTEM_CONFIG"); path != "" {
+		return path
+	}
+	if runtime.GOOS == "windows" {
+		if programData := os.Getenv("ProgramData"); programData != "" {
+			return filepath.Join(programData, "agentctl", "agentctl.json")
+		}
+	}
+	return filepath.Join("/etc", "agentctl", "agentctl.json")
+}
+
+// LoadLayers reads the config files that apply to dir, from lowest to
+// highest precedence: the system config, the team config, the global
+// config and every .agentctl.json from the repository root down to dir.
+// Outside a repository only dir's own .agentctl.json is used. Missing
+// files are skipped, except the global config, which is always present.
+func LoadLayers(dir string) ([]*Layer, error) {
+	var layers []*Layer
+
+	system, err := readLayer(LayerSystem, SystemConfigPath())
+	if err != nil {
+		return nil, err
+	}
+	global, err := Load()
+	if err != nil {
+		return nil, err
+	}
+
+	// The team config is named by the system or global settings
+	var settings Settings
+	if system != nil {
+		layers = append(layers, system)
+		settings = system.Config.Settings
+	}
+	settings = settings.overlay(global.Settings)
+	if ref := os.Getenv("AGENTCTL_TEAM_CONFIG"); ref != "" {
+		settings.TeamConfig = ref
+	}
+	if settings.TeamConfig != "" {
+		team, err := readLayer(LayerTeam, teamConfigPath(settings.TeamConfig, settings.Sources))
+		if err != nil {
+			return nil, err
+		}
+		if team != nil {
+			layers = append(layers, team)
+		}
+	}
+
+	layers = append(layers, &Layer{Kind: LayerGlobal, Path: global.Path, Config: global})
+
+	for _, path := range projectConfigPaths(dir) {
+		project, err := readLayer(LayerProject, path)
+		if err != nil {
+			return nil, err
+		}
+		if project != nil {
+			layers = append(layers, project)
+		}
+	}
+
+	return layers, nil
+}
+
+// MergeLayers merges layers in order, applies AGENTCTL_CONFIG_* overrides
+// and loads resources. Saving the result writes only its edits, to the
+// global config or the nearest project config, and its project path is
+// the nearest project layer's.
+func MergeLayers(layers []*Layer) (*Config, error) {
+	merged := &Config{
+		Version: "1",
+		Servers: make(map[string]*mcp.Server),
+	}
+	var global *Config
+	for _, layer := range layers {
+		scope := ScopeGlobal
+		switch layer.Kind {
+		case LayerGlobal:
+			global = layer.Config
+		case LayerProject:
+			scope = ScopeLocal
+			merged.ProjectPath = layer.Path
+		}
+		projectPath := merged.ProjectPath
+		merged = merged.mergeScoped(layer.Config, scope)
+		merged.ProjectPath = projectPath
+	}
+	if global != nil {
+		merged.Version = global.Version
+		merged.Path = global.Path
+		merged.ConfigDir = global.ConfigDir
+	}
+
+	envLayers, err := merged.applyEnvOverrides()
+	if err != nil {
+		return nil, err
+	}
+	merged.Layers = append(append([]*Layer{}, layers...), envLayers...)
+	if global != nil {
+		if merged.loaded, err = json.Marshal(merged); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := merged.ReloadResources(); err != nil {
+		return nil, err
+	}
+	return merged, nil
+}
+
+// saveLayered writes the edits made to a merged config since it was
+// merged. Servers defined in a project config are written back to it and
+// everything else to the global config, so values from the system and
+// team configs or from environment overrides are never copied into the
+// user's files. Each server is written whole, since
+// layers replace servers rather than merging their fields.
+func (c *Config) saveLayered() error {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	var before, after map[string]any
+	if err := json.Unmarshal(c.loaded, &before); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, &after); err != nil {
+		return err
+	}
+
+	var global, nearest *Layer
+	var projects []*Layer
+	for _, layer := range c.Layers {
+		switch layer.Kind {
+		case LayerGlobal:
+			global = layer
+		case LayerProject:
+			projects = append(projects, layer)
+			if layer.Path == c.ProjectPath {
+				nearest = layer
+			}
+		}
+	}
+	if global == nil {
+		return fmt.Errorf("merged config has no global layer to save to")
+	}
+
+	// Servers go back to the project config that defines them, new
+	// project servers to the nearest one, and the rest to the global config
+	edits := map[*Layer]map[string]any{global: {}}
+	beforeServers, _ := before["servers"].(map[string]any)
+	afterServers, _ := after["servers"].(map[string]any)
+	for _, name := range sortedKeys(beforeServers, afterServers) {
+		b, a := beforeServers[name], afterServers[name]
+		if reflect.DeepEqual(a, b) {
+			continue
+		}
+		target := global
+		if s := c.Servers[name]; s != nil && s.Scope == string(ScopeLocal) && nearest != nil {
+			target = nearest
+		}
+		for _, project := range projects {
+			if project.Config.Servers[name] != nil {
+				target = project
+			}
+		}
+		if edits[target] == nil {
+			edits[target] = map[string]any{}
+		}
+		servers, _ := edits[target]["servers"].(map[string]any)
+		if servers == nil {
+			servers = map[string]any{}
+			edits[target]["servers"] = servers
+		}
+		servers[name] = a // nil removes it
+	}
+	delete(before, "servers")
+	delete(after, "servers")
+	diffEdits(edits[global], before, after)
+
+	for _, layer := range c.Layers {
+		if len(edits[layer]) == 0 {
+			continue
+		}
+		if err := layer.apply(edits[layer]); err != nil {
+			return err
+		}
+	}
+	c.loaded = data
+	return nil
+}
+
+// apply writes edits to the layer's config file. Edited servers replace
+// the layer's, and nil values remove them.
+func (l *Layer) apply(edits map[string]any) error {
+	data, err := json.Marshal(l.Config)
+	if err != nil {
+		return err
+	}
+	var target map[string]any
+	if err := json.Unmarshal(data, &target); err != nil {
+		return err
+	}
+
+	if servers, ok := edits["servers"].(map[string]any); ok {
+		existing, _ := target["servers"].(map[string]any)
+		if existing == nil {
+			existing = map[string]any{}
+		}
+		for name, server := range servers {
+			if server == nil {
+				delete(existing, name)
+			} else {
+				existing[name] = server
+			}
+		}
+		target["servers"] = existing
+		delete(edits, "servers")
+	}
+	for k, v := range edits {
+		if edit, ok := v.(listEdit); ok {
+			list, _ := target[k].([]any)
+			target[k] = edit.apply(list)
+			continue
+		}
+		applyNested(target, k, v)
+	}
+
+	if data, err = json.Marshal(target); err != nil {
+		return err
+	}
+	var updated Config
+	if err := json.Unmarshal(data, &updated); err != nil {
+		return err
+	}
+	updated.Path = l.Config.Path
+	updated.ConfigDir = l.Config.ConfigDir
+	if err := updated.SaveTo(l.Path); err != nil {
+		return err
+	}
+	l.Config = &updated
+	return nil
+}
+
+// listEdit is the items added to and removed from a list. Lists are
+// edited item by item so merged lists aren't copied whole.
+type listEdit struct {
+	added, removed []any
+}
+
+func (e listEdit) apply(list []any) []any {
+	var result []any
+	for _, item := range list {
+		if !containsValue(e.removed, item) {
+			result = append(result, item)
+		}
+	}
+	for _, item := range e.added {
+		if !containsValue(result, item) {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+// diffEdits records in edits the changes from before to after: objects
+// are compared field by field, lists item by item, and removed fields are
+// recorded as nil
+func diffEdits(edits, before, after map[string]any) {
+	for _, k := range sortedKeys(before, after) {
+		b, had := before[k]
+		a, has := after[k]
+		switch {
+		case !has:
+			edits[k] = nil
+		case had && reflect.DeepEqual(a, b):
+		default:
+			bm, bok := b.(map[string]any)
+			am, aok := a.(map[string]any)
+			if bok && aok {
+				nested := map[string]any{}
+				diffEdits(nested, bm, am)
+				edits[k] = nested
+				continue
+			}
+			bl, bok := b.([]any)
+			al, aok := a.([]any)
+			if bok && aok || !had && aok {
+				var edit listEdit
+				for _, item := range al {
+					if !containsValue(bl, item) {
+						edit.added = append(edit.added, item)
+					}
+				}
+				for _, item := range bl {
+					if !containsValue(al, item) {
+						edit.removed = append(edit.removed, item)
+					}
+				}
+				edits[k] = edit
+				continue
+			}
+			edits[k] = a
+		}
+	}
+}
+
+// applyNested sets target[k] to the edit v, recursing into objects
+func applyNested(target map[string]any, k string, v any) {
+	switch v := v.(type) {
+	case nil:
+		delete(target, k)
+	case map[string]any:
+		nested, _ := target[k].(map[string]any)
+		if nested == nil {
+			nested = map[string]any{}
+		}
+		for nk, nv := range v {
+			if edit, ok := nv.(listEdit); ok {
+				list, _ := nested[nk].([]any)
+				nested[nk] = edit.apply(list)
+				continue
+			}
+			applyNested(nested, nk, nv)
+		}
+		target[k] = nested
+	default:
+		target[k] = v
+	}
+}
+
+func containsValue(list []any, v any) bool {
+	for _, item := range list {
+		if reflect.DeepEqual(item, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// sortedKeys returns the keys of both maps, sorted
+func sortedKeys(a, b map[string]any) []string {
+	seen := make(map[string]bool)
+	var keys []string
+	for _, m := range []map[string]any{a, b} {
+		for k := range m {
+			if !seen[k] {
+				seen[k] = true
+				keys = append(keys, k)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// readLayer reads a layer's config file, returning nil if it doesn't exist
+func readLayer(kind LayerKind, path string) (*Layer, error) {
+	cfg, err := readConfig(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("%s config: %w", kind, err)
+	}
+	return &Layer{Kind: kind, Path: path, Config: cfg}, nil
+}
+
+// teamConfigPath resolves settings.teamConfig. A configured source's name
+// means the agentctl.json at the source's root; a directory means the
+// agentctl.json inside it.
+func teamConfigPath(ref string, sources []*source.Source) string {
+	if src := source.Find(sources, ref); src != nil {
+		return filepath.Join(src.Dir(DefaultCacheDir()), "agentctl.json")
+	}
+	if strings.HasPrefix(ref, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			ref = filepath.Join(home, ref[2:])
+		}
+	}
+	if info, err := os.Stat(ref); err == nil && info.IsDir() {
+		return filepath.Join(ref, "agentctl.json")
+	}
+	return ref
+}
+
+// projectConfigPaths returns the .agentctl.json paths that apply to dir,
+// from the repository root down to dir. Outside a repository only dir's
+// own config applies.
+func projectConfigPaths(dir string) []string {
+	if abs, err := filepath.Abs(dir); err == nil {
+		dir = abs
+	}
+
+	var dirs []string
+	for d := dir; ; {
+		dirs = append(dirs, d)
+		if _, err := os.Stat(filepath.Join(d, ".git")); err == nil {
+			break
+		}
+		parent := filepath.Dir(d)
+		if parent == d {
+			// No repository root, so don't look above dir
+			dirs = dirs[:1]
+			break
+		}
+		d = parent
+	}
+
+	paths := make([]string, 0, len(dirs))
+	for i := len(dirs) - 1; i >= 0; i-- {
+		paths = append(paths, filepath.Join(dirs[i], ".agentctl.json"))
+	}
+	return paths
+}
+
+// applyEnvOverrides sets the values of AGENTCTL_CONFIG_* variables,
+// returning a layer for each
+func (c *Config) applyEnvOverrides() ([]*Layer, error) {
+	var names []string
+	for _, env := range os.Environ() {
+		name, _, _ := strings.Cut(env, "=")
+		if strings.HasPrefix(name, EnvOverridePrefix) && len(name) > len(EnvOverridePrefix) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var layers []*Layer
+	for _, name := range names {
+		value := os.Getenv(name)
+		path, err := c.envPath(strings.TrimPrefix(name, EnvOverridePrefix))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		if err := c.SetPath(path, value); err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+
+		// Record the value on its own so explain can attribute it
+		layerCfg := &Config{}
+		parts, _ := ParsePath(path)
+		err = update(reflect.ValueOf(layerCfg).Elem(), parts, true, func(v reflect.Value) error {
+			converted, err := convert(value, v.Type())
+			if err != nil {
+				return err
+			}
+			v.Set(converted)
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		layers = append(layers, &Layer{Kind: LayerEnv, Path: name, Config: layerCfg})
+	}
+	return layers, nil
+}
+
+// envPath turns the rest of an override variable's name into a config
+// path. Segments are separated by "__". Field names match ignoring case
+// and underscores; map keys match existing keys ignoring case, or are
+// used as written.
+func (c *Config) envPath(name string) (string, error) {
+	var parts []string
+	v := reflect.ValueOf(c).Elem()
+	t := v.Type()
+	for _, seg := range strings.Split(name, "__") {
+		if seg == "" {
+			return "", fmt.Errorf("empty path segment")
+		}
+		for t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+		v = indirect(v)
+
+		switch t.Kind() {
+		case reflect.Struct:
+			field, ok := looseField(t, seg)
+			if !ok {
+				return "", fmt.Errorf("unknown field %q", seg)
+			}
+			parts = append(parts, jsonName(field))
+			t = field.Type
+			if v.IsValid() {
+				v = v.FieldByIndex(field.Index)
+			}
+		case reflect.Map:
+			key := seg
+			if v.IsValid() {
+				for _, k := range v.MapKeys() {
+					if strings.EqualFold(k.String(), seg) {
+						key = k.String()
+						break
+					}
+				}
+				v = v.MapIndex(reflect.ValueOf(key).Convert(t.Key()))
+			}
+			parts = append(parts, key)
+			t = t.Elem()
+		case reflect.Slice:
+			i, err := strconv.Atoi(seg)
+			if err != nil {
+				return "", fmt.Errorf("list index %q is not a number", seg)
+			}
+			if v.IsValid() && i >= 0 && i < v.Len() {
+				v = v.Index(i)
+			} else {
+				v = reflect.Value{}
+			}
+			parts = append(parts, seg)
+			t = t.Elem()
+		default:
+			return "", fmt.Errorf("%s has no field %q", typeName(t), seg)
+		}
+	}
+	return formatPath(parts), nil
+}
+
+// looseField finds the struct field whose JSON name matches name,
+// ignoring case and underscores
+func looseField(t reflect.Type, name string) (reflect.StructField, bool) {
+	name = strings.ReplaceAll(name, "_", "")
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if json := jsonName(f); json != "" && strings.EqualFold(json, name) {
+			return f, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+// LayerValue is the value a layer sets at a path
+type LayerValue struct {
+	Layer *Layer `json:"layer"`
+	Value any    `json:"value"`
+}
+
+// Explanation describes where the value at a path comes from
+type Explanation struct {
+	Path   string       `json:"path"`
+	Value  any          `json:"value"`            // Effective value, nil if unset
+	Found  bool         `json:"found"`            // Whether the merged config has the path
+	Layers []LayerValue `json:"layers,omitempty"` // Layers setting the path, lowest precedence first
+}
+
+// Winner returns the layer whose value is the effective value, or nil
+// when the effective value combines several layers (merged lists and
+// maps) or no layer sets the path
+func (e *Explanation) Winner() *Layer {
+	if len(e.Layers) == 0 {
+		return nil
+	}
+	last := e.Layers[len(e.Layers)-1]
+	if !reflect.DeepEqual(last.Value, e.Value) {
+		return nil
+	}
+	return last.Layer
+}
+
+// Explain reports the effective value at path and every layer that sets
+// it. A layer sets a path when its own config has a non-empty value there.
+func (c *Config) Explain(path string) (*Explanation, error) {
+	exp := &Explanation{Path: path}
+
+	value, err := c.GetPath(path)
+	switch {
+	case err == nil:
+		exp.Value, exp.Found = value, true
+	case !errors.Is(err, ErrPathNotFound):
+		return nil, err
+	}
+
+	for _, layer := range c.Layers {
+		v, err := layer.Config.GetPath(path)
+		if err != nil || isEmpty(v) {
+			continue
+		}
+		exp.Layers = append(exp.Layers, LayerValue{Layer: layer, Value: v})
+	}
+	return exp, nil
+}
+
+// isEmpty reports whether v is nil or its type's zero value
+func isEmpty(v any) bool {
+	if v == nil {
+		return true
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Map, reflect.Slice:
+		return rv.Len() == 0
+	}
+	return rv.IsZero()
+}
@@ -0,0 +1,523 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/iheanyi/agentctl/pkg/schema"
+)
+
+// Paths address values in a Config by JSON field names separated by
+// dots, e.g. "servers.github.env.TOKEN" or "settings.tools.cursor.enabled".
+// List elements are addressed by index ("servers.fs.args.0" or
+// "servers.fs.args[0]") and map keys containing dots can be quoted
+// ("servers.\"my.server\".command").
+
+// ErrPathNotFound is returned when a path doesn't exist in the config
+var ErrPathNotFound = errors.New("path not found")
+
+// GetPath returns the value at path
+func (c *Config) GetPath(path string) (any, error) {
+	parts, err := ParsePath(path)
+	if err != nil {
+		return nil, err
+	}
+	v := reflect.ValueOf(c).Elem()
+	for i, part := range parts {
+		v, err = child(v, part)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", formatPath(parts[:i+1]), err)
+		}
+	}
+	return v.Interface(), nil
+}
+
+// SetPath sets the value at path, converting value to the field's type.
+// Strings, numbers and booleans are parsed from their text; lists accept
+// a JSON array or comma-separated items; maps and objects accept JSON.
+// Missing maps and objects along the path are created.
+func (c *Config) SetPath(path, value string) error {
+	return c.updatePath(path, func(v reflect.Value) error {
+		converted, err := convert(value, v.Type())
+		if err != nil {
+			return err
+		}
+		v.Set(converted)
+		return nil
+	})
+}
+
+// AppendPath appends values to the list at path, skipping values
+// already in it
+func (c *Config) AppendPath(path string, values ...string) error {
+	return c.updatePath(path, func(v reflect.Value) error {
+		if v.Kind() != reflect.Slice {
+			return fmt.Errorf("not a list (%s)", typeName(v.Type()))
+		}
+		for _, value := range values {
+			item, err := convert(value, v.Type().Elem())
+			if err != nil {
+				return err
+			}
+			if indexOf(v, item) < 0 {
+				v.Set(reflect.Append(v, item))
+			}
+		}
+		return nil
+	})
+}
+
+// RemovePath removes values from the list at path
+func (c *Config) RemovePath(path string, values ...string) error {
+	return c.updatePath(path, func(v reflect.Value) error {
+		if v.Kind() != reflect.Slice {
+			return fmt.Errorf("not a list (%s)", typeName(v.Type()))
+		}
+		for _, value := range values {
+			item, err := convert(value, v.Type().Elem())
+			if err != nil {
+				return err
+			}
+			i := indexOf(v, item)
+			if i < 0 {
+				return fmt.Errorf("%q is not in the list", value)
+			}
+			v.Set(reflect.AppendSlice(v.Slice(0, i), v.Slice(i+1, v.Len())))
+		}
+		return nil
+	})
+}
+
+// UnsetPath removes the value at path: map entries and list elements are
+// deleted, and fields are reset to their zero value
+func (c *Config) UnsetPath(path string) error {
+	parts, err := ParsePath(path)
+	if err != nil {
+		return err
+	}
+	last := parts[len(parts)-1]
+	return c.apply(path, func(cfg *Config) error {
+		return update(reflect.ValueOf(cfg).Elem(), parts[:len(parts)-1], false, func(v reflect.Value) error {
+			v = indirect(v)
+			if !v.IsValid() {
+				return ErrPathNotFound
+			}
+			switch v.Kind() {
+			case reflect.Map:
+				key := reflect.ValueOf(last).Convert(v.Type().Key())
+				if !v.MapIndex(key).IsValid() {
+					return ErrPathNotFound
+				}
+				v.SetMapIndex(key, reflect.Value{})
+				return nil
+			case reflect.Slice:
+				i, err := index(last, v.Len())
+				if err != nil {
+					return err
+				}
+				v.Set(reflect.AppendSlice(v.Slice(0, i), v.Slice(i+1, v.Len())))
+				return nil
+			}
+			field, err := child(v, last)
+			if err != nil {
+				return err
+			}
+			field.Set(reflect.Zero(field.Type()))
+			return nil
+		})
+	})
+}
+
+// updatePath applies fn to the value at path, creating the path as
+// needed, then validates the result
+func (c *Config) updatePath(path string, fn func(reflect.Value) error) error {
+	parts, err := ParsePath(path)
+	if err != nil {
+		return err
+	}
+	return c.apply(path, func(cfg *Config) error {
+		return update(reflect.ValueOf(cfg).Elem(), parts, true, fn)
+	})
+}
+
+// apply makes a change to a copy of the config and validates it the way
+// a strict load would, then makes it for real. The config is left
+// untouched when the change fails.
+func (c *Config) apply(path string, change func(*Config) error) error {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	var draft Config
+	if err := json.Unmarshal(data, &draft); err != nil {
+		return err
+	}
+	if err := change(&draft); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	draft.nameServers()
+	if err := validateConfig(c.Path, &draft); err != nil {
+		return err
+	}
+
+	if err := change(c); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	c.nameServers()
+	return nil
+}
+
+// nameServers names servers added by path, which have no name yet
+func (c *Config) nameServers() {
+	for name, server := range c.Servers {
+		if server != nil && server.Name == "" {
+			server.Name = name
+		}
+	}
+}
+
+// validateConfig checks a config against the config schema
+func validateConfig(file string, c *Config) error {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	if err := schema.ValidateJSON(file, data, JSONSchema()); err != nil {
+		var verr *schema.ValidationError
+		if errors.As(err, &verr) {
+			msgs := make([]string, len(verr.Errors))
+			for i, e := range verr.Errors {
+				msgs[i] = e.Path + ": " + e.Message
+			}
+			return fmt.Errorf("invalid config: %s", strings.Join(msgs, "; "))
+		}
+		return err
+	}
+	var check Config
+	return json.Unmarshal(data, &check)
+}
+
+// update walks parts from v and calls fn with the addressable value at
+// the end. Map values aren't addressable, so they're copied, updated and
+// stored back. With create, missing map entries and nil pointers are
+// allocated.
+func update(v reflect.Value, parts []string, create bool, fn func(reflect.Value) error) error {
+	if len(parts) == 0 {
+		return fn(v)
+	}
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			if !create {
+				return ErrPathNotFound
+			}
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		v = v.Elem()
+	}
+
+	part := parts[0]
+	switch v.Kind() {
+	case reflect.Map:
+		if v.IsNil() {
+			if !create {
+				return ErrPathNotFound
+			}
+			v.Set(reflect.MakeMap(v.Type()))
+		}
+		key := reflect.ValueOf(part).Convert(v.Type().Key())
+		elem := reflect.New(v.Type().Elem()).Elem()
+		if existing := v.MapIndex(key); existing.IsValid() {
+			elem.Set(existing)
+		} else if !create {
+			return ErrPathNotFound
+		}
+		if err := update(elem, parts[1:], create, fn); err != nil {
+			return err
+		}
+		v.SetMapIndex(key, elem)
+		return nil
+	case reflect.Slice:
+		i, err := index(part, v.Len())
+		if err != nil {
+			return err
+		}
+		return update(v.Index(i), parts[1:], create, fn)
+	case reflect.Struct:
+		field, err := child(v, part)
+		if err != nil {
+			return err
+		}
+		return update(field, parts[1:], create, fn)
+	}
+	return fmt.Errorf("cannot set %q inside a %s", part, typeName(v.Type()))
+}
+
+// child returns the struct field, map entry or list element named part
+func child(v reflect.Value, part string) (reflect.Value, error) {
+	v = indirect(v)
+	if !v.IsValid() {
+		return reflect.Value{}, ErrPathNotFound
+	}
+	switch v.Kind() {
+	case reflect.Struct:
+		var names []string
+		for i := 0; i < v.NumField(); i++ {
+			f := v.Type().Field(i)
+			name := jsonName(f)
+			if name == part {
+				return v.Field(i), nil
+			}
+			if name != "" {
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
+		return reflect.Value{}, fmt.Errorf("unknown field %q (fields: %s)", part, strings.Join(names, ", "))
+	case reflect.Map:
+		elem := v.MapIndex(reflect.ValueOf(part).Convert(v.Type().Key()))
+		if !elem.IsValid() {
+			return reflect.Value{}, ErrPathNotFound
+		}
+		return elem, nil
+	case reflect.Slice:
+		i, err := index(part, v.Len())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		return v.Index(i), nil
+	}
+	return reflect.Value{}, fmt.Errorf("%s has no field %q", typeName(v.Type()), part)
+}
+
+// indirect follows pointers and interfaces, returning an invalid value
+// for nil
+func indirect(v reflect.Value) reflect.Value {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		v = v.Elem()
+	}
+	return v
+}
+
+// jsonName returns a struct field's JSON name, or "" if it isn't serialized
+func jsonName(f reflect.StructField) string {
+	if !f.IsExported() {
+		return ""
+	}
+	tag := f.Tag.Get("json")
+	if tag == "-" {
+		return ""
+	}
+	name, _, _ := strings.Cut(tag, ",")
+	if name == "" {
+		name = f.Name
+	}
+	return name
+}
+
+// index parses a list index
+func index(part string, length int) (int, error) {
+	i, err := strconv.Atoi(part)
+	if err != nil {
+		return 0, fmt.Errorf("list index %q is not a number", part)
+	}
+	if i < 0 || i >= length {
+		return 0, fmt.Errorf("list index %d out of range (length %d)", i, length)
+	}
+	return i, nil
+}
+
+// indexOf returns the index of item in list, or -1
+func indexOf(list, item reflect.Value) int {
+	for i := 0; i < list.Len(); i++ {
+		if reflect.DeepEqual(list.Index(i).Interface(), item.Interface()) {
+			return i
+		}
+	}
+	return -1
+}
+
+// convert parses text into a value of type t
+func convert(text string, t reflect.Type) (reflect.Value, error) {
+	v := reflect.New(t).Elem()
+	switch t.Kind() {
+	case reflect.String:
+		v.SetString(text)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(text)
+		if err != nil {
+			return v, fmt.Errorf("%q is not a boolean (use true or false)", text)
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(text, 10, t.Bits())
+		if err != nil {
+			return v, fmt.Errorf("%q is not an integer", text)
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(text, 10, t.Bits())
+		if err != nil {
+			return v, fmt.Errorf("%q is not a non-negative integer", text)
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(text, t.Bits())
+		if err != nil {
+			return v, fmt.Errorf("%q is not a number", text)
+		}
+		v.SetFloat(f)
+	case reflect.Slice:
+		if strings.HasPrefix(strings.TrimSpace(text), "[") {
+			return decodeJSON(text, t)
+		}
+		v.Set(reflect.MakeSlice(t, 0, 0))
+		if text == "" {
+			return v, nil
+		}
+		for _, item := range strings.Split(text, ",") {
+			elem, err := convert(strings.TrimSpace(item), t.Elem())
+			if err != nil {
+				return v, err
+			}
+			v.Set(reflect.Append(v, elem))
+		}
+	case reflect.Interface:
+		// Free-form values take JSON, falling back to a plain string
+		var decoded any
+		if err := json.Unmarshal([]byte(text), &decoded); err != nil {
+			decoded = text
+		}
+		if decoded != nil {
+			v.Set(reflect.ValueOf(decoded))
+		}
+	case reflect.Pointer:
+		elem, err := convert(text, t.Elem())
+		if err != nil {
+			return v, err
+		}
+		ptr := reflect.New(t.Elem())
+		ptr.Elem().Set(elem)
+		v.Set(ptr)
+	default:
+		return decodeJSON(text, t)
+	}
+	return v, nil
+}
+
+// decodeJSON parses JSON text into a value of type t
+func decodeJSON(text string, t reflect.Type) (reflect.Value, error) {
+	ptr := reflect.New(t)
+	if err := json.Unmarshal([]byte(text), ptr.Interface()); err != nil {
+		return reflect.Value{}, fmt.Errorf("expected JSON for %s: %v", typeName(t), err)
+	}
+	return ptr.Elem(), nil
+}
+
+// typeName describes a type in JSON terms for error messages
+func typeName(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Pointer:
+		return typeName(t.Elem())
+	case reflect.Struct:
+		return "object"
+	case reflect.Map:
+		return "map"
+	case reflect.Slice:
+		return "list"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.String:
+		return "string"
+	case reflect.Interface:
+		return "value"
+	}
+	return "number"
+}
+
+// ParsePath splits a dotted path into its parts. Parts may be quoted
+// with double quotes, and [N] is shorthand for .N.
+func ParsePath(path string) ([]string, error) {
+	var parts []string
+	var cur strings.Builder
+	quoted, inPart := false, false
+	invalid := func(reason string) error {
+		return fmt.Errorf("invalid path %q: %s", path, reason)
+	}
+
+	for i := 0; i < len(path); i++ {
+		ch := path[i]
+		switch {
+		case quoted:
+			if ch == '"' {
+				quoted = false
+			} else {
+				cur.WriteByte(ch)
+			}
+		case ch == '"':
+			quoted, inPart = true, true
+		case ch == '.':
+			if !inPart {
+				return nil, invalid("empty segment")
+			}
+			parts = append(parts, cur.String())
+			cur.Reset()
+			inPart = false
+		case ch == '[':
+			if inPart {
+				parts = append(parts, cur.String())
+				cur.Reset()
+				inPart = false
+			}
+			end := strings.IndexByte(path[i:], ']')
+			if end < 0 {
+				return nil, invalid("unclosed [")
+			}
+			parts = append(parts, path[i+1:i+end])
+			i += end
+			if i+1 < len(path) {
+				switch path[i+1] {
+				case '.':
+					i++
+					if i+1 == len(path) {
+						return nil, invalid("empty segment")
+					}
+				case '[':
+				default:
+					return nil, invalid("expected . after ]")
+				}
+			}
+		default:
+			cur.WriteByte(ch)
+			inPart = true
+		}
+	}
+	if quoted {
+		return nil, invalid("unclosed quote")
+	}
+	if inPart {
+		parts = append(parts, cur.String())
+	} else if len(path) == 0 || path[len(path)-1] != ']' {
+		return nil, invalid("empty segment")
+	}
+	return parts, nil
+}
+
+// formatPath joins parts into a path, quoting parts with dots
+func formatPath(parts []string) string {
+	quoted := make([]string, len(parts))
+	for i, part := range parts {
+		if strings.ContainsAny(part, ".[]") {
+			part = `"` + part + `"`
+		}
+		quoted[i] = part
+	}
+	return strings.Join(quoted, ".")
+}
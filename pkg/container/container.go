@@ -0,0 +1,182 @@
+// Package container runs MCP servers packaged as OCI images. Servers with
+// a container block are written to tools as a stdio command running the
+// image with docker or podman, and images are pulled or built ahead of
+// time so the first start isn't slow.
+package container
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/iheanyi/agentctl/pkg/mcp"
+)
+
+// Container runtimes
+const (
+	Docker = "docker"
+	Podman = "podman"
+)
+
+// lookPath finds a runtime on PATH. Tests replace it.
+var lookPath = exec.LookPath
+
+// Runtime returns the runtime that runs c: its own, or docker if it's
+// installed and podman if only that is
+func Runtime(c *mcp.Container) string {
+	if c != nil && c.Runtime != "" {
+		return c.Runtime
+	}
+	if _, err := lookPath(Docker); err != nil {
+		if _, err := lookPath(Podman); err == nil {
+			return Podman
+		}
+	}
+	return Docker
+}
+
+// Image returns the image a server runs: its container's image, or the
+// local tag its Dockerfile is built as
+func Image(s *mcp.Server) string {
+	if s.Container != nil && s.Container.Image != "" {
+		return s.Container.Image
+	}
+	return LocalTag(s)
+}
+
+// LocalTag returns the tag an image built for a server is given
+func LocalTag(s *mcp.Server) string {
+	name := strings.ToLower(s.Name)
+	name = strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '-' || r == '_' || r == '.' {
+			return r
+		}
+		return '-'
+	}, name)
+	return "agentctl/" + name + ":latest"
+}
+
+// Command returns s as the stdio server that runs its container: the
+// runtime with 'run -i --rm', the network mode, volumes and env vars, the
+// image and the server's args. Servers without a container are returned
+// unchanged.
+func Command(s *mcp.Server) *mcp.Server {
+	if s.Container == nil {
+		return s
+	}
+	c := s.Container
+
+	args := []string{"run", "-i", "--rm"}
+	if c.Network != "" {
+		args = append(args, "--network", c.Network)
+	}
+	for _, v := range c.Volumes {
+		args = append(args, "-v", expandHome(v))
+	}
+	if s.EnvFile != "" {
+		args = append(args, "--env-file", s.EnvFile)
+	}
+	// Env vars are set on the runtime's process by the tool and passed
+	// through by name, so their values stay out of the arguments
+	for _, name := range slices.Sorted(maps.Keys(s.Env)) {
+		args = append(args, "-e", name)
+	}
+	args = append(args, c.Args...)
+	args = append(args, Image(s))
+	args = append(args, s.Args...)
+
+	run := *s
+	run.Transport = mcp.TransportStdio
+	run.Command = Runtime(c)
+	run.Args = args
+	run.Container = nil
+	return &run
+}
+
+// Commands returns servers with each container server replaced by the
+// command that runs it
+func Commands(servers []*mcp.Server) []*mcp.Server {
+	run := make([]*mcp.Server, len(servers))
+	for i, s := range servers {
+		run[i] = Command(s)
+	}
+	return run
+}
+
+// expandHome expands a leading ~ in the host side of a volume
+func expandHome(volume string) string {
+	if volume != "~" && !strings.HasPrefix(volume, "~/") && !strings.HasPrefix(volume, "~:") {
+		return volume
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return volume
+	}
+	return home + volume[1:]
+}
+
+// Pull pulls a server's image
+func Pull(s *mcp.Server) error {
+	return run(Runtime(s.Container), "", "pull", Image(s))
+}
+
+// Build builds the Dockerfile in dir as the server's local tag
+func Build(s *mcp.Server, dir string) error {
+	return run(Runtime(s.Container), dir, "build", "-t", LocalTag(s), ".")
+}
+
+// HasDockerfile reports whether dir has a Dockerfile to build
+func HasDockerfile(dir string) bool {
+	for _, name := range []string{"Dockerfile", "Containerfile"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// HasImage reports whether a server's image is present locally
+func HasImage(s *mcp.Server) bool {
+	cmd := exec.Command(Runtime(s.Container), "image", "inspect", Image(s))
+	return cmd.Run() == nil
+}
+
+// Check reports whether a runtime is installed and can run containers,
+// which for docker means its daemon is reachable
+func Check(runtime string) error {
+	if _, err := lookPath(runtime); err != nil {
+		return fmt.Errorf("%s not found", runtime)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, runtime, "info").CombinedOutput()
+	if err != nil {
+		// docker info prints the client's details before the error
+		msg := strings.TrimSpace(string(out))
+		if i := strings.LastIndexByte(msg, '\n'); i >= 0 {
+			msg = msg[i+1:]
+		}
+		if msg == "" {
+			msg = err.Error()
+		}
+		return fmt.Errorf("%s isn't running: %s", runtime, msg)
+	}
+	return nil
+}
+
+func run(runtime, dir string, args ...string) error {
+	cmd := exec.Command(runtime, args...)
+	cmd.Dir = dir
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%s %s failed: %w", runtime, args[0], err)
+	}
+	return nil
+}
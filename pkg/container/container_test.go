@@ -0,0 +1,122 @@
+package container
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/iheanyi/agentctl/pkg/mcp"
+)
+
+// fakeRuntimes makes lookPath find only the given runtimes
+func fakeRuntimes(t *testing.T, installed ...string) {
+	t.Helper()
+	old := lookPath
+	lookPath = func(file string) (string, error) {
+		for _, name := range installed {
+			if name == file {
+				return "/usr/bin/" + file, nil
+			}
+		}
+		return "", exec.ErrNotFound
+	}
+	t.Cleanup(func() { lookPath = old })
+}
+
+func TestRuntime(t *testing.T) {
+	tests := []struct {
+		name      string
+		container *mcp.Container
+		installed []string
+		want      string
+	}{
+		{"explicit", &mcp.Container{Runtime: Podman}, []string{Docker}, Podman},
+		{"docker preferred", &mcp.Container{}, []string{Docker, Podman}, Docker},
+		{"podman only", &mcp.Container{}, []string{Podman}, Podman},
+		{"none installed", nil, nil, Docker},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeRuntimes(t, tt.installed...)
+			if got := Runtime(tt.container); got != tt.want {
+				t.Errorf("Runtime() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCommand(t *testing.T) {
+	fakeRuntimes(t, Docker)
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	server := &mcp.Server{
+		Name: "weather",
+		Args: []string{"--units", "metric"},
+		Env:  map[string]string{"WEATHER_API_KEY": "secret", "DEBUG": "1"},
+		Container: &mcp.Container{
+			Image:   "ghcr.io/acme/weather:1.2.0",
+			Volumes: []string{"~/data:/data:ro", "/tmp:/tmp"},
+			Network: "none",
+			Args:    []string{"--memory", "512m"},
+		},
+	}
+	got := Command(server)
+
+	want := []string{
+		"run", "-i", "--rm",
+		"--network", "none",
+		"-v", filepath.Join(home, "data") + ":/data:ro",
+		"-v", "/tmp:/tmp",
+		"-e", "DEBUG",
+		"-e", "WEATHER_API_KEY",
+		"--memory", "512m",
+		"ghcr.io/acme/weather:1.2.0",
+		"--units", "metric",
+	}
+	if got.Command != Docker || got.Transport != mcp.TransportStdio || got.Container != nil {
+		t.Errorf("Command() = %q %q %+v", got.Command, got.Transport, got.Container)
+	}
+	if !reflect.DeepEqual(got.Args, want) {
+		t.Errorf("Command() args = %q, want %q", got.Args, want)
+	}
+	if !reflect.DeepEqual(got.Env, server.Env) {
+		t.Errorf("Command() env = %v, want %v", got.Env, server.Env)
+	}
+	if server.Container == nil || server.Command != "" {
+		t.Error("Command() modified the server")
+	}
+
+	// Without an image, the locally built tag runs
+	built := Command(&mcp.Server{Name: "My Server", Container: &mcp.Container{Runtime: Podman}})
+	if built.Command != Podman || built.Args[len(built.Args)-1] != "agentctl/my-server:latest" {
+		t.Errorf("Command() = %q %q", built.Command, built.Args)
+	}
+
+	plain := &mcp.Server{Name: "fs", Command: "npx"}
+	if Command(plain) != plain {
+		t.Error("Command() changed a server without a container")
+	}
+}
+
+func TestHasDockerfile(t *testing.T) {
+	dir := t.TempDir()
+	if HasDockerfile(dir) {
+		t.Error("HasDockerfile() = true for an empty dir")
+	}
+	if err := os.WriteFile(filepath.Join(dir, "Containerfile"), []byte("FROM scratch\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if !HasDockerfile(dir) {
+		t.Error("HasDockerfile() = false with a Containerfile")
+	}
+}
+
+func TestCheckMissingRuntime(t *testing.T) {
+	fakeRuntimes(t)
+	if err := Check(Podman); err == nil || err.Error() != "podman not found" {
+		t.Errorf("Check() error = %v, want podman not found", err)
+	}
+}
@@ -0,0 +1,40 @@
+package discovery
+
+import (
+	"path/filepath"
+
+	"github.com/iheanyi/agentctl/pkg/codexrules"
+)
+
+// CodexScanner discovers resources from Codex's .codex/ directory. Prompts,
+// skills and agents use the shared DirectoryScanner; execution rules are
+// Starlark files in rules/ and need their own parser.
+type CodexScanner struct {
+	*DirectoryScanner
+}
+
+func init() {
+	// Detects: .codex/ directory or AGENTS.md file
+	// Resources: skills in .codex/skills/, custom prompts in prompts/,
+	// execution rules in rules/*.rules
+	// Note: Codex custom prompts are markdown files with description/argument-hint frontmatter
+	// They function as slash commands (e.g., ~/.codex/prompts/test.md -> /test)
+	Register(&CodexScanner{NewDirectoryScanner(ScannerConfig{
+		Name:         "codex",
+		LocalDirs:    []string{".codex"},
+		GlobalDirs:   []string{"~/.codex"},
+		DetectFiles:  []string{"AGENTS.md"},
+		CommandsDirs: []string{"prompts"}, // Custom prompts as slash commands
+		SkillsDirs:   []string{"skills"},
+	})})
+}
+
+// ScanExecRules discovers prefix rules from .codex/rules/ in the project
+func (s *CodexScanner) ScanExecRules(dir string) ([]*codexrules.Rule, error) {
+	return codexrules.LoadDir(codexrules.OS, filepath.Join(dir, ".codex", "rules"))
+}
+
+// ScanGlobalExecRules discovers prefix rules from ~/.codex/rules/
+func (s *CodexScanner) ScanGlobalExecRules() ([]*codexrules.Rule, error) {
+	return codexrules.LoadDir(codexrules.OS, expandHomeDir("~/.codex/rules"))
+}
@@ -328,3 +328,83 @@ func TestTopLevelScannerIntegration(t *testing.T) {
 		t.Errorf("scanner.Name() = %q, want %q", scanner.Name(), "toplevel")
 	}
 }
+
+func TestVSCodeScannerServers(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	dir := t.TempDir()
+	scanner, ok := Get("vscode")
+	if !ok {
+		t.Fatal("vscode scanner not registered")
+	}
+	if scanner.Detect(dir) {
+		t.Error("Detect() = true without .vscode/mcp.json")
+	}
+
+	vscodeDir := filepath.Join(dir, ".vscode")
+	if err := os.MkdirAll(vscodeDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	mcpJSON := `{
+  "inputs": [{"type": "promptString", "id": "token", "password": true}],
+  "servers": {"api": {"type": "http", "url": "https://example.com/mcp", "headers": {"Authorization": "${input:token}"}}}
+}`
+	if err := os.WriteFile(filepath.Join(vscodeDir, "mcp.json"), []byte(mcpJSON), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if !scanner.Detect(dir) {
+		t.Fatal("Detect() = false with .vscode/mcp.json")
+	}
+
+	servers, err := scanner.ScanServers(dir)
+	if err != nil {
+		t.Fatalf("ScanServers failed: %v", err)
+	}
+	if len(servers) != 1 || servers[0].Name != "api" || servers[0].Headers["Authorization"] != "keychain:token" {
+		t.Errorf("ScanServers() = %+v", servers)
+	}
+}
+
+func TestRooKiroAmpGooseScanners(t *testing.T) {
+	tests := []struct {
+		name   string
+		marker string // File that makes the scanner detect the project
+		rule   string // Rule file the scanner should find, if any
+	}{
+		{"roo", ".roo/rules/style.md", "style"},
+		{"kiro", ".kiro/steering/tech.md", "tech"},
+		{"amp", ".agents/commands/review.md", ""},
+		{"goose", ".goosehints", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scanner, ok := Get(tt.name)
+			if !ok {
+				t.Fatalf("%s scanner not registered", tt.name)
+			}
+
+			dir := t.TempDir()
+			path := filepath.Join(dir, tt.marker)
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(path, []byte("Be concise.\n"), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if !scanner.Detect(dir) {
+				t.Fatalf("Detect() = false with %s", tt.marker)
+			}
+
+			rules, err := scanner.ScanRules(dir)
+			if err != nil {
+				t.Fatalf("ScanRules() error = %v", err)
+			}
+			if tt.rule == "" {
+				return
+			}
+			if len(rules) != 1 || rules[0].Name != tt.rule {
+				t.Errorf("ScanRules() = %+v, want %q", rules, tt.rule)
+			}
+		})
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"path/filepath"
 
 	"github.com/iheanyi/agentctl/pkg/agent"
+	"github.com/iheanyi/agentctl/pkg/codexrules"
 	"github.com/iheanyi/agentctl/pkg/command"
 	"github.com/iheanyi/agentctl/pkg/hook"
 	"github.com/iheanyi/agentctl/pkg/mcp"
@@ -17,12 +18,12 @@ import (
 
 // NativeResource represents a resource discovered from a tool's native config
 type NativeResource struct {
-	Type     string // "rule", "skill", "command", "hook", "server", "plugin", "agent"
+	Type     string // "rule", "skill", "command", "hook", "server", "plugin", "agent", "execrule"
 	Name     string
 	Path     string // Path to the file or directory
 	Tool     string // Which tool owns this (e.g., "claude", "gemini")
 	Scope    string // "local" or "global"
-	Resource any    // The actual resource (*rule.Rule, *skill.Skill, *agent.Agent, etc.)
+	Resource any    // The actual resource (*rule.Rule, *skill.Skill, *codexrules.Rule, etc.)
 }
 
 // Scanner defines the interface for discovering resources from tool configurations
@@ -195,6 +196,22 @@ func DiscoverAll(dir string) []*NativeResource {
 				}
 			}
 		}
+
+		// Scan execution rules (if scanner supports it)
+		if es, ok := scanner.(ExecRuleScanner); ok {
+			if rules, err := es.ScanExecRules(dir); err == nil {
+				for _, r := range rules {
+					resources = append(resources, &NativeResource{
+						Type:     "execrule",
+						Name:     r.String(),
+						Path:     r.Path,
+						Tool:     tool,
+						Scope:    "local",
+						Resource: r,
+					})
+				}
+			}
+		}
 	}
 
 	return resources
@@ -299,6 +316,16 @@ type AgentScanner interface {
 	ScanGlobalAgents() ([]*agent.Agent, error)
 }
 
+// ExecRuleScanner defines the interface for discovering command execution
+// rules (e.g., Codex prefix_rule files)
+type ExecRuleScanner interface {
+	Scanner
+	// ScanExecRules discovers execution rules from the tool's local config
+	ScanExecRules(dir string) ([]*codexrules.Rule, error)
+	// ScanGlobalExecRules discovers execution rules from the tool's global config
+	ScanGlobalExecRules() ([]*codexrules.Rule, error)
+}
+
 // DiscoverGlobal discovers resources from global tool configurations
 func DiscoverGlobal() []*NativeResource {
 	var resources []*NativeResource
@@ -414,6 +441,29 @@ func DiscoverGlobal() []*NativeResource {
 		}
 	}
 
+	// Scan for global execution rules
+	for _, scanner := range registry {
+		es, ok := scanner.(ExecRuleScanner)
+		if !ok {
+			continue
+		}
+
+		tool := scanner.Name()
+
+		if rules, err := es.ScanGlobalExecRules(); err == nil {
+			for _, r := range rules {
+				resources = append(resources, &NativeResource{
+					Type:     "execrule",
+					Name:     r.String(),
+					Path:     r.Path,
+					Tool:     tool,
+					Scope:    "global",
+					Resource: r,
+				})
+			}
+		}
+	}
+
 	return resources
 }
 
@@ -17,19 +17,7 @@ func init() {
 		FileExts:    []string{".md", ".mdc"},
 	}))
 
-	// Codex Scanner
-	// Detects: .codex/ directory or AGENTS.md file
-	// Resources: skills in .codex/skills/, custom prompts in prompts/
-	// Note: Codex custom prompts are markdown files with description/argument-hint frontmatter
-	// They function as slash commands (e.g., ~/.codex/prompts/test.md -> /test)
-	Register(NewDirectoryScanner(ScannerConfig{
-		Name:         "codex",
-		LocalDirs:    []string{".codex"},
-		GlobalDirs:   []string{"~/.codex"},
-		DetectFiles:  []string{"AGENTS.md"},
-		CommandsDirs: []string{"prompts"}, // Custom prompts as slash commands
-		SkillsDirs:   []string{"skills"},
-	}))
+	// Codex Scanner is registered in codex.go (it also discovers execution rules)
 
 	// OpenCode Scanner
 	// Detects: .opencode/ directory
@@ -56,6 +44,46 @@ func init() {
 		FileExts:     []string{".md", ".agent.md"},
 	}))
 
+	// Roo Code Scanner
+	// Detects: .roo/ directory or .roorules file
+	// Resources: rules in .roo/rules/, commands in .roo/commands/
+	Register(NewDirectoryScanner(ScannerConfig{
+		Name:         "roo",
+		LocalDirs:    []string{".roo"},
+		GlobalDirs:   []string{"~/.roo"},
+		DetectFiles:  []string{".roorules"},
+		RulesDirs:    []string{"rules"},
+		CommandsDirs: []string{"commands"},
+	}))
+
+	// Kiro Scanner
+	// Detects: .kiro/ directory
+	// Resources: steering files in .kiro/steering/ as rules
+	Register(NewDirectoryScanner(ScannerConfig{
+		Name:       "kiro",
+		LocalDirs:  []string{".kiro"},
+		GlobalDirs: []string{"~/.kiro"},
+		RulesDirs:  []string{"steering"},
+	}))
+
+	// Amp Scanner
+	// Detects: .agents/ directory
+	// Resources: commands in .agents/commands/
+	Register(NewDirectoryScanner(ScannerConfig{
+		Name:         "amp",
+		LocalDirs:    []string{".agents"},
+		GlobalDirs:   []string{"~/.config/amp"},
+		CommandsDirs: []string{"commands"},
+	}))
+
+	// Goose Scanner
+	// Detects: .goosehints file
+	// Resources: none beyond detection - extensions live in the global config.yaml
+	Register(NewDirectoryScanner(ScannerConfig{
+		Name:        "goose",
+		DetectFiles: []string{".goosehints"},
+	}))
+
 	// TopLevel Scanner
 	// Detects: skills/ directory or CLAUDE.md/AGENTS.md at project root
 	// Resources: skills from skills/, treats CLAUDE.md/AGENTS.md as rules
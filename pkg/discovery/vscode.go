@@ -0,0 +1,60 @@
+package discovery
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/iheanyi/agentctl/pkg/command"
+	"github.com/iheanyi/agentctl/pkg/hook"
+	"github.com/iheanyi/agentctl/pkg/mcp"
+	"github.com/iheanyi/agentctl/pkg/rule"
+	"github.com/iheanyi/agentctl/pkg/skill"
+	"github.com/iheanyi/agentctl/pkg/sync"
+)
+
+// VSCodeScanner discovers MCP servers from a workspace's .vscode/mcp.json
+type VSCodeScanner struct{}
+
+func init() {
+	Register(&VSCodeScanner{})
+}
+
+func (s *VSCodeScanner) Name() string {
+	return "vscode"
+}
+
+func (s *VSCodeScanner) Detect(dir string) bool {
+	_, err := os.Stat(filepath.Join(dir, ".vscode", "mcp.json"))
+	return err == nil
+}
+
+func (s *VSCodeScanner) ScanRules(dir string) ([]*rule.Rule, error) {
+	// VS Code's instructions are discovered by the copilot scanner
+	return nil, nil
+}
+
+func (s *VSCodeScanner) ScanSkills(dir string) ([]*skill.Skill, error) {
+	return nil, nil
+}
+
+func (s *VSCodeScanner) ScanHooks(dir string) ([]*hook.Hook, error) {
+	return nil, nil
+}
+
+func (s *VSCodeScanner) ScanCommands(dir string) ([]*command.Command, error) {
+	return nil, nil
+}
+
+// ScanServers reads .vscode/mcp.json with the sync adapter, so password
+// inputs come back as secret references
+func (s *VSCodeScanner) ScanServers(dir string) ([]*mcp.Server, error) {
+	adapter, ok := sync.Get("vscode")
+	if !ok {
+		return nil, nil
+	}
+	wa, ok := sync.AsWorkspaceAdapter(adapter)
+	if !ok {
+		return nil, nil
+	}
+	return wa.ReadWorkspaceServers(dir)
+}
@@ -0,0 +1,258 @@
+package gateway
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	mcpsdk "github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/iheanyi/agentctl/pkg/mcp"
+	"github.com/iheanyi/agentctl/pkg/secrets"
+)
+
+// backend is one server behind the gateway
+type backend struct {
+	prefix  string
+	server  *mcp.Server
+	gateway *Gateway
+
+	mu       sync.Mutex
+	session  *mcpsdk.ClientSession
+	closed   bool
+	features features // What the server offered when last listed
+}
+
+// features are a backend's tools, prompts and resources
+type features struct {
+	tools     []*mcpsdk.Tool
+	prompts   []*mcpsdk.Prompt
+	resources []*mcpsdk.Resource
+	templates []*mcpsdk.ResourceTemplate
+}
+
+// connect returns the backend's session, starting the server and listing
+// what it offers if it isn't running
+func (b *backend) connect(ctx context.Context) (*mcpsdk.ClientSession, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return nil, fmt.Errorf("server %q was removed", b.server.Name)
+	}
+	if b.session != nil {
+		return b.session, nil
+	}
+
+	server, err := resolveSecrets(b.server)
+	if err != nil {
+		return nil, err
+	}
+	// List change handlers run on the session's goroutine, which has to
+	// keep reading while the lists are fetched
+	relist := func() { go b.relist() }
+	session, err := b.gateway.client.Connect(ctx, server, &mcpsdk.ClientOptions{
+		ToolListChangedHandler:     func(context.Context, *mcpsdk.ToolListChangedRequest) { relist() },
+		PromptListChangedHandler:   func(context.Context, *mcpsdk.PromptListChangedRequest) { relist() },
+		ResourceListChangedHandler: func(context.Context, *mcpsdk.ResourceListChangedRequest) { relist() },
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	features, err := list(ctx, session)
+	if err != nil {
+		session.Close()
+		return nil, err
+	}
+	b.session = session
+	b.features = features
+
+	// Forget the session when the server exits, so the next use restarts it
+	go func() {
+		_ = session.Wait()
+		b.mu.Lock()
+		if b.session == session {
+			b.session = nil
+		}
+		b.mu.Unlock()
+	}()
+	return session, nil
+}
+
+// do runs f with the backend's session, restarting the server and trying
+// once more if it exited since it was last used
+func (b *backend) do(ctx context.Context, f func(*mcpsdk.ClientSession) error) error {
+	session, err := b.connect(ctx)
+	if err != nil {
+		return err
+	}
+	if err := f(session); !isClosed(err) {
+		return err
+	}
+
+	b.mu.Lock()
+	if b.session == session {
+		b.session = nil
+	}
+	b.mu.Unlock()
+	if session, err = b.connect(ctx); err != nil {
+		return err
+	}
+	if err := f(session); err != nil {
+		return err
+	}
+	// The restarted server may offer something different
+	b.gateway.publish()
+	return nil
+}
+
+// relist fetches the backend's lists again after it said they changed
+func (b *backend) relist() {
+	b.mu.Lock()
+	session := b.session
+	b.mu.Unlock()
+	if session == nil {
+		return
+	}
+
+	features, err := list(context.Background(), session)
+	if err != nil {
+		b.gateway.logf("%s: %v\n", b.prefix, err)
+		return
+	}
+	b.mu.Lock()
+	b.features = features
+	b.mu.Unlock()
+	b.gateway.publish()
+}
+
+// snapshot returns what the backend offered when last listed
+func (b *backend) snapshot() features {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.features
+}
+
+// close stops the backend for good
+func (b *backend) close() {
+	b.mu.Lock()
+	session := b.session
+	b.session = nil
+	b.closed = true
+	b.mu.Unlock()
+	if session != nil {
+		session.Close()
+	}
+}
+
+func (b *backend) callTool(name string) mcpsdk.ToolHandler {
+	return func(ctx context.Context, req *mcpsdk.CallToolRequest) (*mcpsdk.CallToolResult, error) {
+		var result *mcpsdk.CallToolResult
+		err := b.do(ctx, func(session *mcpsdk.ClientSession) error {
+			var err error
+			result, err = session.CallTool(ctx, &mcpsdk.CallToolParams{
+				Meta:      req.Params.Meta,
+				Name:      name,
+				Arguments: req.Params.Arguments,
+			})
+			return err
+		})
+		if err != nil {
+			// Tool failures are reported to the model rather than as
+			// protocol errors
+			return &mcpsdk.CallToolResult{
+				IsError: true,
+				Content: []mcpsdk.Content{&mcpsdk.TextContent{Text: fmt.Sprintf("%s: %v", b.prefix, err)}},
+			}, nil
+		}
+		return result, nil
+	}
+}
+
+func (b *backend) getPrompt(name string) mcpsdk.PromptHandler {
+	return func(ctx context.Context, req *mcpsdk.GetPromptRequest) (*mcpsdk.GetPromptResult, error) {
+		var result *mcpsdk.GetPromptResult
+		err := b.do(ctx, func(session *mcpsdk.ClientSession) error {
+			var err error
+			result, err = session.GetPrompt(ctx, &mcpsdk.GetPromptParams{
+				Meta:      req.Params.Meta,
+				Name:      name,
+				Arguments: req.Params.Arguments,
+			})
+			return err
+		})
+		return result, err
+	}
+}
+
+func (b *backend) readResource(ctx context.Context, req *mcpsdk.ReadResourceRequest) (*mcpsdk.ReadResourceResult, error) {
+	var result *mcpsdk.ReadResourceResult
+	err := b.do(ctx, func(session *mcpsdk.ClientSession) error {
+		var err error
+		result, err = session.ReadResource(ctx, req.Params)
+		return err
+	})
+	return result, err
+}
+
+// list fetches everything a server offers, skipping what its
+// capabilities say it doesn't have
+func list(ctx context.Context, session *mcpsdk.ClientSession) (features, error) {
+	var f features
+	caps := session.InitializeResult().Capabilities
+	if caps == nil {
+		return f, nil
+	}
+	if caps.Tools != nil {
+		for t, err := range session.Tools(ctx, nil) {
+			if err != nil {
+				return f, fmt.Errorf("failed to list tools: %w", err)
+			}
+			f.tools = append(f.tools, t)
+		}
+	}
+	if caps.Prompts != nil {
+		for p, err := range session.Prompts(ctx, nil) {
+			if err != nil {
+				return f, fmt.Errorf("failed to list prompts: %w", err)
+			}
+			f.prompts = append(f.prompts, p)
+		}
+	}
+	if caps.Resources != nil {
+		for r, err := range session.Resources(ctx, nil) {
+			if err != nil {
+				return f, fmt.Errorf("failed to list resources: %w", err)
+			}
+			f.resources = append(f.resources, r)
+		}
+		for t, err := range session.ResourceTemplates(ctx, nil) {
+			if err != nil {
+				return f, fmt.Errorf("failed to list resource templates: %w", err)
+			}
+			f.templates = append(f.templates, t)
+		}
+	}
+	return f, nil
+}
+
+// resolveSecrets returns a copy of s with secret references in its env
+// and headers resolved
+func resolveSecrets(s *mcp.Server) (*mcp.Server, error) {
+	resolved := *s
+	if len(s.Env) > 0 {
+		env, err := secrets.ResolveEnv(s.Env)
+		if err != nil {
+			return nil, err
+		}
+		resolved.Env = env
+	}
+	if len(s.Headers) > 0 {
+		headers, err := secrets.ResolveEnv(s.Headers)
+		if err != nil {
+			return nil, err
+		}
+		resolved.Headers = headers
+	}
+	return &resolved, nil
+}
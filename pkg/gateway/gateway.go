@@ -0,0 +1,351 @@
+// Package gateway serves many MCP servers as one. The tools, prompts and
+// resources of every backend server are re-exported under names prefixed
+// with the server's namespace, and requests are routed back to the server
+// they came from.
+package gateway
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"reflect"
+	"sort"
+	"sync"
+
+	mcpsdk "github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/iheanyi/agentctl/pkg/mcp"
+	"github.com/iheanyi/agentctl/pkg/mcpclient"
+)
+
+// Separator joins a server's prefix and the name of one of its tools or
+// prompts, e.g. github__create_issue
+const Separator = "__"
+
+// Spec is what a gateway exposes
+type Spec struct {
+	Servers []*mcp.Server // Backend servers, with variables resolved
+	Tools   []string      // Prefixed tool names or globs to expose; empty exposes all
+}
+
+// Gateway is an MCP server in front of a set of backend servers. Backends
+// start the first time a client asks for tools, prompts or resources, and
+// one that exits is started again the next time it's used.
+type Gateway struct {
+	// ErrorLog receives backend failures; nil discards them
+	ErrorLog io.Writer
+
+	server *mcpsdk.Server
+	client *mcpclient.Client
+
+	startMu   sync.Mutex // Held while backends start on first use
+	publishMu sync.Mutex // Serializes publish
+
+	mu       sync.Mutex
+	backends map[string]*backend // By prefix
+	allow    []string
+	started  bool
+
+	// What's registered with server, so it can be removed again
+	tools     []string
+	prompts   []string
+	resources []string
+	templates []string
+}
+
+// New returns a gateway for spec. version is reported to clients.
+func New(spec Spec, version string) *Gateway {
+	g := &Gateway{
+		client:   mcpclient.NewClient(),
+		backends: make(map[string]*backend),
+	}
+	g.server = mcpsdk.NewServer(&mcpsdk.Implementation{Name: "agentctl", Version: version}, &mcpsdk.ServerOptions{
+		Capabilities: &mcpsdk.ServerCapabilities{
+			Tools:     &mcpsdk.ToolCapabilities{ListChanged: true},
+			Prompts:   &mcpsdk.PromptCapabilities{ListChanged: true},
+			Resources: &mcpsdk.ResourceCapabilities{ListChanged: true},
+		},
+	})
+	g.server.AddReceivingMiddleware(g.startOnUse)
+	g.Update(context.Background(), spec)
+	return g
+}
+
+// Run serves the gateway over transport until the client disconnects or
+// ctx is cancelled
+func (g *Gateway) Run(ctx context.Context, transport mcpsdk.Transport) error {
+	return g.server.Run(ctx, transport)
+}
+
+// Handler serves the gateway over streamable HTTP
+func (g *Gateway) Handler() http.Handler {
+	return mcpsdk.NewStreamableHTTPHandler(func(*http.Request) *mcpsdk.Server { return g.server }, nil)
+}
+
+// Update switches the gateway to spec. Backends whose config changed are
+// restarted, removed ones stopped, and clients told the lists changed.
+func (g *Gateway) Update(ctx context.Context, spec Spec) {
+	g.mu.Lock()
+	next := make(map[string]*backend)
+	var fresh, stale []*backend
+	for _, s := range spec.Servers {
+		prefix := Prefix(s)
+		if _, dup := next[prefix]; dup {
+			continue
+		}
+		if b, ok := g.backends[prefix]; ok && reflect.DeepEqual(b.server, s) {
+			next[prefix] = b
+			continue
+		}
+		b := &backend{prefix: prefix, server: s, gateway: g}
+		next[prefix] = b
+		fresh = append(fresh, b)
+	}
+	for prefix, b := range g.backends {
+		if next[prefix] != b {
+			stale = append(stale, b)
+		}
+	}
+	g.backends = next
+	g.allow = spec.Tools
+	started := g.started
+	g.mu.Unlock()
+
+	for _, b := range stale {
+		b.close()
+	}
+	if started {
+		g.start(ctx, fresh)
+		g.publish()
+	}
+}
+
+// Close stops every backend
+func (g *Gateway) Close() error {
+	g.mu.Lock()
+	backends := g.sortedBackends()
+	g.mu.Unlock()
+	for _, b := range backends {
+		b.close()
+	}
+	return nil
+}
+
+// Prefix returns the prefix of a server's tools and prompts: its
+// namespace, or its name
+func Prefix(s *mcp.Server) string {
+	if s.Namespace != "" {
+		return s.Namespace
+	}
+	return s.Name
+}
+
+// Allowed reports whether a prefixed tool name matches the allowlist. An
+// empty allowlist allows every tool.
+func Allowed(allow []string, name string) bool {
+	if len(allow) == 0 {
+		return true
+	}
+	for _, pattern := range allow {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// startOnUse starts the backends before the first request that needs them
+func (g *Gateway) startOnUse(next mcpsdk.MethodHandler) mcpsdk.MethodHandler {
+	return func(ctx context.Context, method string, req mcpsdk.Request) (mcpsdk.Result, error) {
+		switch method {
+		case "tools/list", "tools/call", "prompts/list", "prompts/get",
+			"resources/list", "resources/read", "resources/templates/list":
+			g.ensureStarted(ctx)
+		}
+		return next(ctx, method, req)
+	}
+}
+
+func (g *Gateway) ensureStarted(ctx context.Context) {
+	g.startMu.Lock()
+	defer g.startMu.Unlock()
+
+	g.mu.Lock()
+	if g.started {
+		g.mu.Unlock()
+		return
+	}
+	backends := g.sortedBackends()
+	g.mu.Unlock()
+
+	g.start(ctx, backends)
+
+	g.mu.Lock()
+	g.started = true
+	g.mu.Unlock()
+	g.publish()
+}
+
+// start connects to backends in parallel, reporting the ones that fail
+func (g *Gateway) start(ctx context.Context, backends []*backend) {
+	var wg sync.WaitGroup
+	for _, b := range backends {
+		wg.Add(1)
+		go func(b *backend) {
+			defer wg.Done()
+			if _, err := b.connect(ctx); err != nil {
+				g.logf("%s: %v\n", b.prefix, err)
+			}
+		}(b)
+	}
+	wg.Wait()
+}
+
+// sortedBackends returns the backends by prefix. g.mu must be held.
+func (g *Gateway) sortedBackends() []*backend {
+	backends := make([]*backend, 0, len(g.backends))
+	for _, b := range g.backends {
+		backends = append(backends, b)
+	}
+	sort.Slice(backends, func(i, j int) bool { return backends[i].prefix < backends[j].prefix })
+	return backends
+}
+
+// publish registers what the backends currently offer with the server and
+// removes what they no longer do. The server tells clients the lists
+// changed.
+func (g *Gateway) publish() {
+	g.publishMu.Lock()
+	defer g.publishMu.Unlock()
+
+	g.mu.Lock()
+	backends := g.sortedBackends()
+	allow := g.allow
+	g.mu.Unlock()
+
+	tools := make(map[string]bool)
+	prompts := make(map[string]bool)
+	resources := make(map[string]bool)
+	templates := make(map[string]bool)
+	for _, b := range backends {
+		features := b.snapshot()
+		for _, t := range features.tools {
+			name := b.prefix + Separator + t.Name
+			if tools[name] || !b.server.ToolEnabled(t.Name) || !Allowed(allow, name) {
+				continue
+			}
+			g.server.AddTool(prefixedTool(name, t), b.callTool(t.Name))
+			tools[name] = true
+		}
+		for _, p := range features.prompts {
+			name := b.prefix + Separator + p.Name
+			if prompts[name] {
+				continue
+			}
+			prompt := *p
+			prompt.Name = name
+			g.server.AddPrompt(&prompt, b.getPrompt(p.Name))
+			prompts[name] = true
+		}
+		for _, r := range features.resources {
+			if resources[r.URI] {
+				continue // The first server with a URI serves it
+			}
+			if _, err := url.Parse(r.URI); err != nil {
+				continue
+			}
+			resource := *r
+			resource.Name = b.prefix + Separator + r.Name
+			g.server.AddResource(&resource, b.readResource)
+			resources[r.URI] = true
+		}
+		for _, t := range features.templates {
+			if templates[t.URITemplate] {
+				continue
+			}
+			template := *t
+			template.Name = b.prefix + Separator + t.Name
+			if err := addTemplate(g.server, &template, b.readResource); err != nil {
+				g.logf("%s: %v\n", b.prefix, err)
+				continue
+			}
+			templates[t.URITemplate] = true
+		}
+	}
+
+	g.server.RemoveTools(stale(g.tools, tools)...)
+	g.server.RemovePrompts(stale(g.prompts, prompts)...)
+	g.server.RemoveResources(stale(g.resources, resources)...)
+	g.server.RemoveResourceTemplates(stale(g.templates, templates)...)
+	g.tools, g.prompts, g.resources, g.templates = keys(tools), keys(prompts), keys(resources), keys(templates)
+}
+
+func (g *Gateway) logf(format string, args ...any) {
+	if g.ErrorLog != nil {
+		fmt.Fprintf(g.ErrorLog, format, args...)
+	}
+}
+
+// prefixedTool returns a copy of t named name. Schemas that aren't JSON
+// objects, which the server would reject, are replaced or dropped.
+func prefixedTool(name string, t *mcpsdk.Tool) *mcpsdk.Tool {
+	tool := &mcpsdk.Tool{
+		Name:        name,
+		Title:       t.Title,
+		Description: t.Description,
+		Annotations: t.Annotations,
+		InputSchema: map[string]any{"type": "object"},
+	}
+	if isObjectSchema(t.InputSchema) {
+		tool.InputSchema = t.InputSchema
+	}
+	if isObjectSchema(t.OutputSchema) {
+		tool.OutputSchema = t.OutputSchema
+	}
+	return tool
+}
+
+func isObjectSchema(schema any) bool {
+	m, ok := schema.(map[string]any)
+	return ok && m["type"] == "object"
+}
+
+// addTemplate adds a resource template, returning an error for templates
+// the server can't parse instead of panicking
+func addTemplate(server *mcpsdk.Server, t *mcpsdk.ResourceTemplate, h mcpsdk.ResourceHandler) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+	server.AddResourceTemplate(t, h)
+	return nil
+}
+
+// stale returns the names in registered that aren't in current
+func stale(registered []string, current map[string]bool) []string {
+	var names []string
+	for _, name := range registered {
+		if !current[name] {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+func keys(m map[string]bool) []string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	return names
+}
+
+// isClosed reports whether err means the backend's connection is gone
+func isClosed(err error) bool {
+	return errors.Is(err, mcpsdk.ErrConnectionClosed)
+}
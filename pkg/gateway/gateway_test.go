@@ -0,0 +1,179 @@
+package gateway
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	mcpsdk "github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/iheanyi/agentctl/pkg/config"
+	"github.com/iheanyi/agentctl/pkg/mcp"
+)
+
+// remoteServer serves an MCP server with the named tools, each returning
+// its own name
+func remoteServer(t *testing.T, name string, tools ...string) *mcp.Server {
+	t.Helper()
+	server := mcpsdk.NewServer(&mcpsdk.Implementation{Name: name, Version: "1.0.0"}, nil)
+	for _, tool := range tools {
+		mcpsdk.AddTool(server, &mcpsdk.Tool{Name: tool}, func(ctx context.Context, req *mcpsdk.CallToolRequest, args struct{}) (*mcpsdk.CallToolResult, any, error) {
+			return &mcpsdk.CallToolResult{Content: []mcpsdk.Content{&mcpsdk.TextContent{Text: name + "/" + tool}}}, nil, nil
+		})
+	}
+	ts := httptest.NewServer(mcpsdk.NewStreamableHTTPHandler(func(*http.Request) *mcpsdk.Server { return server }, nil))
+	t.Cleanup(ts.Close)
+	return &mcp.Server{Name: name, Transport: mcp.TransportHTTP, URL: ts.URL}
+}
+
+func connect(t *testing.T, ctx context.Context, g *Gateway) *mcpsdk.ClientSession {
+	t.Helper()
+	clientTransport, serverTransport := mcpsdk.NewInMemoryTransports()
+	go g.Run(ctx, serverTransport)
+	client := mcpsdk.NewClient(&mcpsdk.Implementation{Name: "test", Version: "1.0.0"}, nil)
+	session, err := client.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	t.Cleanup(func() { session.Close() })
+	return session
+}
+
+func toolNames(t *testing.T, ctx context.Context, session *mcpsdk.ClientSession) []string {
+	t.Helper()
+	var names []string
+	for tool, err := range session.Tools(ctx, nil) {
+		if err != nil {
+			t.Fatalf("Tools failed: %v", err)
+		}
+		names = append(names, tool.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func callText(t *testing.T, ctx context.Context, session *mcpsdk.ClientSession, name string) string {
+	t.Helper()
+	result, err := session.CallTool(ctx, &mcpsdk.CallToolParams{Name: name})
+	if err != nil {
+		t.Fatalf("CallTool(%s) failed: %v", name, err)
+	}
+	text, _ := result.Content[0].(*mcpsdk.TextContent)
+	if text == nil {
+		t.Fatalf("CallTool(%s) content = %+v", name, result.Content)
+	}
+	return text.Text
+}
+
+func TestGateway(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	github := remoteServer(t, "github", "create_issue", "search")
+	files := remoteServer(t, "filesystem", "read_file")
+	files.Namespace = "fs"
+
+	g := New(Spec{Servers: []*mcp.Server{github, files}}, "test")
+	defer g.Close()
+	session := connect(t, ctx, g)
+
+	want := []string{"fs__read_file", "github__create_issue", "github__search"}
+	if got := toolNames(t, ctx, session); !reflect.DeepEqual(got, want) {
+		t.Errorf("tools = %v, want %v", got, want)
+	}
+	if got := callText(t, ctx, session, "fs__read_file"); got != "filesystem/read_file" {
+		t.Errorf("fs__read_file returned %q", got)
+	}
+
+	// A backend whose connection goes away is started again on next use
+	g.mu.Lock()
+	b := g.backends["github"]
+	g.mu.Unlock()
+	b.mu.Lock()
+	b.session.Close()
+	b.mu.Unlock()
+	if got := callText(t, ctx, session, "github__search"); got != "github/search" {
+		t.Errorf("github__search after restart returned %q", got)
+	}
+
+	// Updating the spec applies the allowlist and drops removed servers
+	g.Update(ctx, Spec{Servers: []*mcp.Server{github}, Tools: []string{"github__create_*"}})
+	if got := toolNames(t, ctx, session); !reflect.DeepEqual(got, []string{"github__create_issue"}) {
+		t.Errorf("tools after update = %v", got)
+	}
+	result, err := session.CallTool(ctx, &mcpsdk.CallToolParams{Name: "fs__read_file"})
+	if err == nil && !result.IsError {
+		t.Error("removed server's tool should no longer be callable")
+	}
+}
+
+func TestGatewayStartsLazily(t *testing.T) {
+	g := New(Spec{Servers: []*mcp.Server{{Name: "broken", Command: "/nonexistent/mcp-server"}}}, "test")
+	defer g.Close()
+	if g.started {
+		t.Error("backends started before any request")
+	}
+}
+
+func TestAllowed(t *testing.T) {
+	tests := []struct {
+		allow []string
+		name  string
+		want  bool
+	}{
+		{nil, "github__search", true},
+		{[]string{"github__*"}, "github__search", true},
+		{[]string{"github__*"}, "fs__read_file", false},
+		{[]string{"fs__read_file", "fs__list_*"}, "fs__list_dir", true},
+	}
+	for _, tt := range tests {
+		if got := Allowed(tt.allow, tt.name); got != tt.want {
+			t.Errorf("Allowed(%v, %q) = %v, want %v", tt.allow, tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSpecFor(t *testing.T) {
+	dir := t.TempDir()
+	profiles := filepath.Join(dir, "profiles")
+	if err := os.MkdirAll(profiles, 0755); err != nil {
+		t.Fatal(err)
+	}
+	profile := `{"name": "work", "servers": ["sentry"], "disabled": ["fs"], "tools": ["github__*"]}`
+	if err := os.WriteFile(filepath.Join(profiles, "work.json"), []byte(profile), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &config.Config{
+		ConfigDir: dir,
+		Servers: map[string]*mcp.Server{
+			"github":  {Name: "github", Command: "github-mcp"},
+			"fs":      {Name: "fs", Command: "fs-mcp"},
+			"sentry":  {Name: "sentry", Command: "sentry-mcp", Disabled: true},
+			"gateway": {Name: "gateway", Command: "agentctl", Args: []string{"serve"}},
+		},
+	}
+
+	spec, err := SpecFor(cfg)
+	if err != nil {
+		t.Fatalf("SpecFor failed: %v", err)
+	}
+	if len(spec.Servers) != 2 || spec.Servers[0].Name != "fs" || spec.Servers[1].Name != "github" || spec.Tools != nil {
+		t.Errorf("SpecFor() without profile = %+v", spec)
+	}
+
+	cfg.Settings.DefaultProfile = "work"
+	spec, err = SpecFor(cfg)
+	if err != nil {
+		t.Fatalf("SpecFor failed: %v", err)
+	}
+	if len(spec.Servers) != 2 || spec.Servers[0].Name != "github" || spec.Servers[1].Name != "sentry" || !reflect.DeepEqual(spec.Tools, []string{"github__*"}) {
+		t.Errorf("SpecFor() with profile = %+v", spec)
+	}
+}
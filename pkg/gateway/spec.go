@@ -0,0 +1,77 @@
+package gateway
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/iheanyi/agentctl/pkg/config"
+	"github.com/iheanyi/agentctl/pkg/container"
+	"github.com/iheanyi/agentctl/pkg/mcp"
+	"github.com/iheanyi/agentctl/pkg/profile"
+)
+
+// SpecFor returns what the gateway exposes for cfg: its active servers,
+// plus the active profile's servers and less the ones it disables, and
+// the profile's tool allowlist
+func SpecFor(cfg *config.Config) (Spec, error) {
+	var spec Spec
+	servers := make(map[string]*mcp.Server)
+	for _, s := range cfg.ActiveServers() {
+		servers[s.Name] = s
+	}
+
+	if name := ActiveProfile(cfg); name != "" {
+		p, err := profile.Load(filepath.Join(cfg.ConfigDir, "profiles", name+".json"))
+		if err != nil {
+			return spec, fmt.Errorf("failed to load profile %q: %w", name, err)
+		}
+		// Profiles add servers, even ones disabled in the main config
+		for _, name := range p.Servers {
+			if s, ok := cfg.Servers[name]; ok && s.Excluded == "" {
+				servers[name] = s
+			}
+		}
+		for _, name := range p.Disabled {
+			delete(servers, name)
+		}
+		spec.Tools = p.Tools
+	}
+
+	ic := cfg.InterpContext()
+	for _, s := range servers {
+		if isGateway(s) {
+			continue // Serving the gateway through itself would never end
+		}
+		spec.Servers = append(spec.Servers, container.Command(ic.For("", s.Scope).Server(s)))
+	}
+	sort.Slice(spec.Servers, func(i, j int) bool { return spec.Servers[i].Name < spec.Servers[j].Name })
+	return spec, nil
+}
+
+// ActiveProfile returns the name of the profile in effect for cfg, or ""
+// if it's the main config
+func ActiveProfile(cfg *config.Config) string {
+	name := cfg.Profile
+	if name == "" {
+		name = cfg.Settings.DefaultProfile
+	}
+	if name == "default" {
+		return ""
+	}
+	return name
+}
+
+// isGateway reports whether a server runs 'agentctl serve'
+func isGateway(s *mcp.Server) bool {
+	if len(s.Args) == 0 || s.Args[0] != "serve" {
+		return false
+	}
+	exe := filepath.Base(s.Command)
+	if exe == "agentctl" {
+		return true
+	}
+	self, err := os.Executable()
+	return err == nil && s.Command == self
+}
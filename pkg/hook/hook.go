@@ -7,6 +7,8 @@ import (
 	"path/filepath"
 	"runtime"
 	"strings"
+
+	"github.com/iheanyi/agentctl/pkg/toolname"
 )
 
 // InspectTitle returns the display name for the inspector modal header
@@ -40,7 +42,7 @@ func (h *Hook) InspectContent() string {
 // Hook represents a configured hook
 type Hook struct {
 	Type    string `json:"type"`    // PreToolUse, PostToolUse, Notification, Stop, UserPromptSubmit, etc.
-	Matcher string `json:"matcher"` // Tool matcher (e.g., "Bash", "Edit", "*")
+	Matcher string `json:"matcher"` // Tool matcher in canonical tool names (e.g., "bash", "edit|write", "*")
 	Command string `json:"command"` // Shell command to run
 	Source  string `json:"-"`       // Which tool this hook came from (e.g., "claude", "claude-local", "gemini")
 	Name    string `json:"name"`    // Optional hook name (Gemini)
@@ -73,6 +75,12 @@ type GeminiHookItem struct {
 	Timeout     int    `json:"timeout,omitempty"`
 }
 
+// canonicalMatcher translates a tool's hook matcher into canonical tool
+// names, so hooks from every tool read alike
+func canonicalMatcher(tool, matcher string) string {
+	return toolname.NewTranslator(tool, nil).CanonicalMatcher(matcher)
+}
+
 // parseClaudeSettings parses Claude settings and extracts hooks
 func parseClaudeSettings(data []byte, source string) ([]*Hook, error) {
 	var settings struct {
@@ -85,6 +93,7 @@ func parseClaudeSettings(data []byte, source string) ([]*Hook, error) {
 	var hooks []*Hook
 	for hookType, entries := range settings.Hooks {
 		for _, entry := range entries {
+			matcher := canonicalMatcher("claude", entry.Matcher)
 			for _, h := range entry.Hooks {
 				var cmd string
 				switch v := h.(type) {
@@ -98,12 +107,12 @@ func parseClaudeSettings(data []byte, source string) ([]*Hook, error) {
 				if cmd != "" {
 					// Generate a name from type and matcher
 					name := hookType
-					if entry.Matcher != "" && entry.Matcher != "*" {
-						name = hookType + ":" + entry.Matcher
+					if matcher != "" && matcher != "*" {
+						name = hookType + ":" + matcher
 					}
 					hooks = append(hooks, &Hook{
 						Type:    hookType,
-						Matcher: entry.Matcher,
+						Matcher: matcher,
 						Command: cmd,
 						Source:  source,
 						Name:    name,
@@ -199,7 +208,7 @@ func parseGeminiSettings(data []byte, source string) ([]*Hook, error) {
 				if h.Command != "" {
 					hooks = append(hooks, &Hook{
 						Type:    eventType,
-						Matcher: entry.Matcher,
+						Matcher: canonicalMatcher("gemini", entry.Matcher),
 						Command: h.Command,
 						Name:    h.Name,
 						Source:  source,
@@ -113,8 +113,8 @@ func TestParseClaudeSettingsHookDetails(t *testing.T) {
 	if hook.Type != "PostToolUse" {
 		t.Errorf("Type = %q, want %q", hook.Type, "PostToolUse")
 	}
-	if hook.Matcher != "Bash" {
-		t.Errorf("Matcher = %q, want %q", hook.Matcher, "Bash")
+	if hook.Matcher != "bash" {
+		t.Errorf("Matcher = %q, want %q", hook.Matcher, "bash")
 	}
 	if hook.Command != "echo done" {
 		t.Errorf("Command = %q, want %q", hook.Command, "echo done")
@@ -328,8 +328,8 @@ func TestParseGeminiSettingsHookDetails(t *testing.T) {
 	if hook.Type != "BeforeTool" {
 		t.Errorf("Type = %q, want %q", hook.Type, "BeforeTool")
 	}
-	if hook.Matcher != "write_file|replace" {
-		t.Errorf("Matcher = %q, want %q", hook.Matcher, "write_file|replace")
+	if hook.Matcher != "write|edit" {
+		t.Errorf("Matcher = %q, want %q", hook.Matcher, "write|edit")
 	}
 	if hook.Command != "./validate.sh" {
 		t.Errorf("Command = %q, want %q", hook.Command, "./validate.sh")
@@ -0,0 +1,314 @@
+// Package interp resolves ${...} variables in server settings and
+// resource bodies at sync time, so a config can refer to the project
+// root, home directory or git branch of whichever machine and project
+// it's synced from. Write $${...} for a literal ${...}.
+package interp
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/iheanyi/agentctl/pkg/command"
+	"github.com/iheanyi/agentctl/pkg/mcp"
+	"github.com/iheanyi/agentctl/pkg/rule"
+)
+
+// Builtins lists the built-in variables with a description of each
+var Builtins = map[string]string{
+	"home":               "User home directory",
+	"cwd":                "Working directory agentctl runs in",
+	"os":                 "Operating system (GOOS, e.g. darwin or linux)",
+	"project.root":       "Project directory (local servers and resources only)",
+	"project.name":       "Base name of the project directory",
+	"agentctl.configDir": "agentctl config directory",
+	"agentctl.cacheDir":  "agentctl cache directory",
+	"git.branch":         "Current git branch",
+	"git.root":           "Root of the current git repository",
+	"tool":               "Name of the tool being synced (e.g. claude)",
+	"env.NAME":           "Environment variable NAME",
+}
+
+// maxDepth bounds nested references between user variables
+const maxDepth = 8
+
+// Context holds the values variables resolve to. Names not defined in the
+// context are left as written, so tool-native references like ${VAR}
+// pass through untouched.
+type Context struct {
+	Home        string
+	CWD         string
+	ConfigDir   string
+	CacheDir    string
+	ProjectRoot string            // Empty when values aren't tied to a project
+	Tool        string            // Tool the values are written to
+	Vars        map[string]string // User-defined variables, referenced by name
+
+	git *gitCache
+}
+
+// gitCache remembers git lookups per directory; contexts derived from
+// one another share it
+type gitCache struct {
+	mu     sync.Mutex
+	values map[string]string
+}
+
+// NewContext returns a context for the machine agentctl runs on
+func NewContext(projectRoot, configDir, cacheDir string, vars map[string]string) *Context {
+	home, _ := os.UserHomeDir()
+	cwd, _ := os.Getwd()
+	return &Context{
+		Home:        home,
+		CWD:         cwd,
+		ConfigDir:   configDir,
+		CacheDir:    cacheDir,
+		ProjectRoot: projectRoot,
+		Vars:        vars,
+		git:         &gitCache{values: make(map[string]string)},
+	}
+}
+
+// For returns the context for values of the given scope written to tool.
+// Only local ("project") values resolve ${project.root}, since global
+// values are shared by every project.
+func (ctx *Context) For(tool, scope string) *Context {
+	derived := *ctx
+	derived.Tool = tool
+	if scope != "local" {
+		derived.ProjectRoot = ""
+	}
+	return &derived
+}
+
+// Lookup returns the value of a variable
+func (ctx *Context) Lookup(name string) (string, bool) {
+	return ctx.lookup(name, 0)
+}
+
+func (ctx *Context) lookup(name string, depth int) (string, bool) {
+	if envName, ok := strings.CutPrefix(name, "env."); ok {
+		return os.LookupEnv(envName)
+	}
+
+	var value string
+	switch name {
+	case "home":
+		value = ctx.Home
+	case "cwd":
+		value = ctx.CWD
+	case "os":
+		value = runtime.GOOS
+	case "project.root":
+		value = ctx.ProjectRoot
+	case "project.name":
+		if ctx.ProjectRoot != "" {
+			value = filepath.Base(ctx.ProjectRoot)
+		}
+	case "agentctl.configDir":
+		value = ctx.ConfigDir
+	case "agentctl.cacheDir":
+		value = ctx.CacheDir
+	case "git.branch":
+		value = ctx.gitValue("branch", "rev-parse", "--abbrev-ref", "HEAD")
+	case "git.root":
+		value = ctx.gitValue("root", "rev-parse", "--show-toplevel")
+	case "tool":
+		value = ctx.Tool
+	default:
+		user, ok := ctx.Vars[name]
+		if !ok || depth >= maxDepth {
+			return "", false
+		}
+		return ctx.expand(user, depth+1), true
+	}
+	return value, value != ""
+}
+
+// gitValue runs git in the project (or working) directory, caching the output
+func (ctx *Context) gitValue(key string, args ...string) string {
+	dir := ctx.ProjectRoot
+	if dir == "" {
+		dir = ctx.CWD
+	}
+	if ctx.git == nil {
+		ctx.git = &gitCache{values: make(map[string]string)}
+	}
+
+	ctx.git.mu.Lock()
+	defer ctx.git.mu.Unlock()
+	cacheKey := dir + "\x00" + key
+	if value, ok := ctx.git.values[cacheKey]; ok {
+		return value
+	}
+	out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).Output()
+	value := ""
+	if err == nil {
+		value = strings.TrimSpace(string(out))
+	}
+	ctx.git.values[cacheKey] = value
+	return value
+}
+
+// Expand resolves the ${...} references in s. Unknown names are left as
+// written and $${...} becomes a literal ${...}.
+func (ctx *Context) Expand(s string) string {
+	return ctx.expand(s, 0)
+}
+
+func (ctx *Context) expand(s string, depth int) string {
+	if !strings.Contains(s, "${") {
+		return s
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(s); {
+		switch {
+		case strings.HasPrefix(s[i:], "$${"):
+			b.WriteString("${")
+			i += 3
+		case strings.HasPrefix(s[i:], "${"):
+			end := strings.IndexByte(s[i:], '}')
+			if end < 0 {
+				b.WriteString(s[i:])
+				return b.String()
+			}
+			ref := s[i : i+end+1]
+			if value, ok := ctx.lookup(strings.TrimSpace(ref[2:len(ref)-1]), depth); ok {
+				b.WriteString(value)
+			} else {
+				b.WriteString(ref)
+			}
+			i += end + 1
+		default:
+			b.WriteByte(s[i])
+			i++
+		}
+	}
+	return b.String()
+}
+
+// Known reports whether name is a built-in variable, an env.NAME
+// reference or one of vars
+func Known(name string, vars map[string]string) bool {
+	if _, ok := Builtins[name]; ok {
+		return true
+	}
+	if _, ok := vars[name]; ok {
+		return true
+	}
+	return strings.HasPrefix(name, "env.") && len(name) > len("env.")
+}
+
+// References returns the variable names s refers to, sorted and without
+// duplicates
+func References(s string) []string {
+	seen := make(map[string]bool)
+	for i := 0; i < len(s); {
+		switch {
+		case strings.HasPrefix(s[i:], "$${"):
+			i += 3
+		case strings.HasPrefix(s[i:], "${"):
+			end := strings.IndexByte(s[i:], '}')
+			if end < 0 {
+				i = len(s)
+				continue
+			}
+			seen[strings.TrimSpace(s[i+2:i+end])] = true
+			i += end + 1
+		default:
+			i++
+		}
+	}
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Server returns a copy of s with variables resolved in its command,
+// args, env, URL, headers and container
+func (ctx *Context) Server(s *mcp.Server) *mcp.Server {
+	resolved := *s
+	resolved.Command = ctx.Expand(s.Command)
+	resolved.URL = ctx.Expand(s.URL)
+	resolved.Args = ctx.expandList(s.Args)
+	resolved.Env = ctx.expandMap(s.Env)
+	resolved.Headers = ctx.expandMap(s.Headers)
+	if s.Container != nil {
+		c := *s.Container
+		c.Image = ctx.Expand(c.Image)
+		c.Volumes = ctx.expandList(c.Volumes)
+		c.Args = ctx.expandList(c.Args)
+		resolved.Container = &c
+	}
+	return &resolved
+}
+
+func (ctx *Context) expandList(list []string) []string {
+	if list == nil {
+		return nil
+	}
+	expanded := make([]string, len(list))
+	for i, item := range list {
+		expanded[i] = ctx.Expand(item)
+	}
+	return expanded
+}
+
+func (ctx *Context) expandMap(m map[string]string) map[string]string {
+	if m == nil {
+		return nil
+	}
+	expanded := make(map[string]string, len(m))
+	for k, v := range m {
+		expanded[k] = ctx.Expand(v)
+	}
+	return expanded
+}
+
+// Servers resolves servers written to tool, each in its own scope
+func (ctx *Context) Servers(tool string, servers []*mcp.Server) []*mcp.Server {
+	if ctx == nil {
+		return servers
+	}
+	resolved := make([]*mcp.Server, len(servers))
+	for i, s := range servers {
+		resolved[i] = ctx.For(tool, s.Scope).Server(s)
+	}
+	return resolved
+}
+
+// Commands resolves the prompts of commands written to tool
+func (ctx *Context) Commands(tool string, commands []*command.Command) []*command.Command {
+	if ctx == nil {
+		return commands
+	}
+	resolved := make([]*command.Command, len(commands))
+	for i, cmd := range commands {
+		c := *cmd
+		c.Prompt = ctx.For(tool, cmd.Scope).Expand(cmd.Prompt)
+		resolved[i] = &c
+	}
+	return resolved
+}
+
+// Rules resolves the content of rules written to tool
+func (ctx *Context) Rules(tool string, rules []*rule.Rule) []*rule.Rule {
+	if ctx == nil {
+		return rules
+	}
+	resolved := make([]*rule.Rule, len(rules))
+	for i, r := range rules {
+		c := *r
+		c.Content = ctx.For(tool, r.Scope).Expand(r.Content)
+		resolved[i] = &c
+	}
+	return resolved
+}
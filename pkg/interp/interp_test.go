@@ -0,0 +1,152 @@
+package interp
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/iheanyi/agentctl/pkg/command"
+	"github.com/iheanyi/agentctl/pkg/mcp"
+	"github.com/iheanyi/agentctl/pkg/rule"
+)
+
+func testContext() *Context {
+	return &Context{
+		Home:        "/home/me",
+		CWD:         "/work",
+		ConfigDir:   "/home/me/.config/agentctl",
+		CacheDir:    "/home/me/.cache/agentctl",
+		ProjectRoot: "/src/app",
+		Vars: map[string]string{
+			"region": "us-east-1",
+			"data":   "${project.root}/data",
+			"loop":   "${loop}",
+		},
+		git: &gitCache{values: map[string]string{"/src/app\x00branch": "main"}},
+	}
+}
+
+func TestExpand(t *testing.T) {
+	t.Setenv("INTERP_TEST", "value")
+	ctx := testContext().For("claude", "local")
+
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"plain", "plain"},
+		{"${home}/bin", "/home/me/bin"},
+		{"${ project.root }", "/src/app"},
+		{"${project.name}", "app"},
+		{"${agentctl.cacheDir}/x", "/home/me/.cache/agentctl/x"},
+		{"${git.branch}", "main"},
+		{"${tool}", "claude"},
+		{"${env.INTERP_TEST}", "value"},
+		{"${region}", "us-east-1"},
+		{"${data}", "/src/app/data"},
+		{"${GITHUB_TOKEN}", "${GITHUB_TOKEN}"},
+		{"${env.INTERP_TEST_UNSET}", "${env.INTERP_TEST_UNSET}"},
+		{"$${home}", "${home}"},
+		{"${home", "${home"},
+		{"${loop}", "${loop}"},
+	}
+	for _, tt := range tests {
+		if got := ctx.Expand(tt.in); got != tt.want {
+			t.Errorf("Expand(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestForGlobalScope(t *testing.T) {
+	ctx := testContext().For("cursor", "global")
+	if got := ctx.Expand("${project.root}"); got != "${project.root}" {
+		t.Errorf("global scope resolved project.root to %q", got)
+	}
+	if got := ctx.Expand("${tool}"); got != "cursor" {
+		t.Errorf("Expand(${tool}) = %q, want cursor", got)
+	}
+}
+
+func TestServers(t *testing.T) {
+	servers := []*mcp.Server{
+		{
+			Name:    "fs",
+			Scope:   "local",
+			Command: "${home}/bin/fs",
+			Args:    []string{"--root", "${project.root}"},
+			Env:     map[string]string{"REGION": "${region}", "TOKEN": "${TOKEN}"},
+		},
+		{
+			Name:    "api",
+			Scope:   "global",
+			URL:     "https://${region}.example.com/${project.name}",
+			Headers: map[string]string{"X-Tool": "${tool}"},
+		},
+	}
+	got := testContext().Servers("claude", servers)
+
+	if got[0].Command != "/home/me/bin/fs" || !reflect.DeepEqual(got[0].Args, []string{"--root", "/src/app"}) {
+		t.Errorf("local server = %q %v", got[0].Command, got[0].Args)
+	}
+	if want := map[string]string{"REGION": "us-east-1", "TOKEN": "${TOKEN}"}; !reflect.DeepEqual(got[0].Env, want) {
+		t.Errorf("local server env = %v, want %v", got[0].Env, want)
+	}
+	if got[1].URL != "https://us-east-1.example.com/${project.name}" || got[1].Headers["X-Tool"] != "claude" {
+		t.Errorf("global server = %q %v", got[1].URL, got[1].Headers)
+	}
+
+	// The originals are left as written
+	if servers[0].Args[1] != "${project.root}" || servers[0].Env["REGION"] != "${region}" {
+		t.Errorf("Servers modified its input: %v %v", servers[0].Args, servers[0].Env)
+	}
+
+	var nilCtx *Context
+	if out := nilCtx.Servers("claude", servers); out[0] != servers[0] {
+		t.Error("nil context should return servers unchanged")
+	}
+}
+
+func TestServerContainer(t *testing.T) {
+	server := &mcp.Server{
+		Name: "weather",
+		Container: &mcp.Container{
+			Image:   "ghcr.io/acme/weather:${region}",
+			Runtime: "podman",
+			Volumes: []string{"${project.root}:/work"},
+		},
+	}
+	got := testContext().Server(server)
+
+	want := mcp.Container{Image: "ghcr.io/acme/weather:us-east-1", Runtime: "podman", Volumes: []string{"/src/app:/work"}}
+	if got.Command != "" || got.Container == nil || !reflect.DeepEqual(*got.Container, want) {
+		t.Errorf("Server() = %q %+v, want container %+v", got.Command, got.Container, want)
+	}
+	if server.Container.Volumes[0] != "${project.root}:/work" {
+		t.Errorf("Server modified its input: %v", server.Container.Volumes)
+	}
+}
+
+func TestCommandsAndRules(t *testing.T) {
+	ctx := testContext()
+	commands := ctx.Commands("claude", []*command.Command{{Name: "build", Scope: "local", Prompt: "Build ${project.root}, not $${project.root}"}})
+	if want := "Build /src/app, not ${project.root}"; commands[0].Prompt != want {
+		t.Errorf("command prompt = %q, want %q", commands[0].Prompt, want)
+	}
+	rules := ctx.Rules("claude", []*rule.Rule{{Name: "style", Scope: "global", Content: "Cache: ${agentctl.cacheDir}"}})
+	if want := "Cache: /home/me/.cache/agentctl"; rules[0].Content != want {
+		t.Errorf("rule content = %q, want %q", rules[0].Content, want)
+	}
+}
+
+func TestReferences(t *testing.T) {
+	got := References("${b} ${a} $${c} ${b} ${unterminated")
+	if want := []string{"a", "b"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("References() = %v, want %v", got, want)
+	}
+
+	vars := map[string]string{"region": "x"}
+	for name, want := range map[string]bool{"home": true, "env.PATH": true, "env.": false, "region": true, "nope": false} {
+		if got := Known(name, vars); got != want {
+			t.Errorf("Known(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
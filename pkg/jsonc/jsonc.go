@@ -0,0 +1,303 @@
+// Package jsonc reads and edits JSON with comments and trailing commas
+// (JSONC), the format of Zed's and VS Code's settings files. Edits are
+// patched into the original text, so comments, key order and formatting
+// outside the values that changed are kept byte for byte.
+package jsonc
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Kind is the type of a JSON value
+type Kind int
+
+const (
+	Object Kind = iota
+	Array
+	String
+	Number
+	Bool
+	Null
+)
+
+// Node is a value in a parsed document. Offsets index the original text.
+type Node struct {
+	Kind    Kind
+	Start   int       // Offset of the value's first byte
+	End     int       // Offset just past the value's last byte
+	Members []*Member // Object members, in document order
+	Elems   []*Node   // Array elements
+	Commas  []int     // Offset of the comma after each element, or -1
+}
+
+// Member is a key and value in an object
+type Member struct {
+	Key   string
+	Start int // Offset of the key's opening quote
+	Value *Node
+	Comma int // Offset of the comma after the value, or -1 if there is none
+}
+
+// SyntaxError is returned for text that isn't valid JSONC
+type SyntaxError struct {
+	Offset int
+	Msg    string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("jsonc: %s at offset %d", e.Msg, e.Offset)
+}
+
+// Parse parses a JSONC document. Whitespace-only input returns a nil node.
+func Parse(data []byte) (*Node, error) {
+	p := &parser{data: data}
+	p.skip()
+	if p.pos == len(data) {
+		if p.err != nil {
+			return nil, p.err
+		}
+		return nil, nil
+	}
+	n := p.value()
+	if p.err != nil {
+		return nil, p.err
+	}
+	p.skip()
+	if p.err != nil {
+		return nil, p.err
+	}
+	if p.pos != len(data) {
+		return nil, &SyntaxError{Offset: p.pos, Msg: "unexpected data after value"}
+	}
+	return n, nil
+}
+
+type parser struct {
+	data []byte
+	pos  int
+	err  error
+}
+
+func (p *parser) fail(msg string) {
+	if p.err == nil {
+		p.err = &SyntaxError{Offset: p.pos, Msg: msg}
+	}
+	p.pos = len(p.data)
+}
+
+// skip moves past whitespace and comments
+func (p *parser) skip() {
+	for p.pos < len(p.data) {
+		switch c := p.data[p.pos]; {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			p.pos++
+		case c == '/' && p.pos+1 < len(p.data) && p.data[p.pos+1] == '/':
+			for p.pos < len(p.data) && p.data[p.pos] != '\n' {
+				p.pos++
+			}
+		case c == '/' && p.pos+1 < len(p.data) && p.data[p.pos+1] == '*':
+			end := strings.Index(string(p.data[p.pos+2:]), "*/")
+			if end < 0 {
+				p.fail("unterminated comment")
+				return
+			}
+			p.pos += end + 4
+		default:
+			return
+		}
+	}
+}
+
+func (p *parser) value() *Node {
+	if p.pos >= len(p.data) {
+		p.fail("unexpected end of input")
+		return nil
+	}
+	start := p.pos
+	switch c := p.data[p.pos]; {
+	case c == '{':
+		return p.object()
+	case c == '[':
+		return p.array()
+	case c == '"':
+		p.str()
+		return &Node{Kind: String, Start: start, End: p.pos}
+	case c == '-' || (c >= '0' && c <= '9'):
+		for p.pos < len(p.data) && strings.IndexByte("+-.0123456789eE", p.data[p.pos]) >= 0 {
+			p.pos++
+		}
+		return &Node{Kind: Number, Start: start, End: p.pos}
+	case p.literal("true"), p.literal("false"):
+		return &Node{Kind: Bool, Start: start, End: p.pos}
+	case p.literal("null"):
+		return &Node{Kind: Null, Start: start, End: p.pos}
+	}
+	p.fail(fmt.Sprintf("unexpected character %q", p.data[p.pos]))
+	return nil
+}
+
+func (p *parser) literal(word string) bool {
+	if strings.HasPrefix(string(p.data[p.pos:]), word) {
+		p.pos += len(word)
+		return true
+	}
+	return false
+}
+
+// str moves past a string and returns its decoded value
+func (p *parser) str() string {
+	start := p.pos
+	p.pos++
+	for p.pos < len(p.data) {
+		switch p.data[p.pos] {
+		case '\\':
+			p.pos += 2
+		case '"':
+			p.pos++
+			var s string
+			if err := json.Unmarshal(p.data[start:p.pos], &s); err != nil {
+				p.pos = start
+				p.fail("invalid string")
+			}
+			return s
+		case '\n':
+			p.fail("newline in string")
+			return ""
+		default:
+			p.pos++
+		}
+	}
+	p.pos = start
+	p.fail("unterminated string")
+	return ""
+}
+
+func (p *parser) object() *Node {
+	n := &Node{Kind: Object, Start: p.pos}
+	p.pos++
+	for {
+		p.skip()
+		if p.pos >= len(p.data) {
+			p.fail("unterminated object")
+			return nil
+		}
+		if p.data[p.pos] == '}' {
+			p.pos++
+			n.End = p.pos
+			return n
+		}
+		if p.data[p.pos] != '"' {
+			p.fail("expected object key")
+			return nil
+		}
+		m := &Member{Start: p.pos, Comma: -1}
+		m.Key = p.str()
+		p.skip()
+		if p.pos >= len(p.data) || p.data[p.pos] != ':' {
+			p.fail("expected ':' after object key")
+			return nil
+		}
+		p.pos++
+		p.skip()
+		if m.Value = p.value(); p.err != nil {
+			return nil
+		}
+		n.Members = append(n.Members, m)
+		p.skip()
+		if p.pos < len(p.data) && p.data[p.pos] == ',' {
+			m.Comma = p.pos
+			p.pos++
+		} else if p.pos < len(p.data) && p.data[p.pos] != '}' {
+			p.fail("expected ',' or '}' in object")
+			return nil
+		}
+	}
+}
+
+func (p *parser) array() *Node {
+	n := &Node{Kind: Array, Start: p.pos}
+	p.pos++
+	for {
+		p.skip()
+		if p.pos >= len(p.data) {
+			p.fail("unterminated array")
+			return nil
+		}
+		if p.data[p.pos] == ']' {
+			p.pos++
+			n.End = p.pos
+			return n
+		}
+		elem := p.value()
+		if p.err != nil {
+			return nil
+		}
+		n.Elems = append(n.Elems, elem)
+		n.Commas = append(n.Commas, -1)
+		p.skip()
+		if p.pos < len(p.data) && p.data[p.pos] == ',' {
+			n.Commas[len(n.Commas)-1] = p.pos
+			p.pos++
+		} else if p.pos < len(p.data) && p.data[p.pos] != ']' {
+			p.fail("expected ',' or ']' in array")
+			return nil
+		}
+	}
+}
+
+// Standardize returns data as plain JSON. Comments and trailing commas
+// are replaced with spaces, so offsets into the result match data.
+func Standardize(data []byte) ([]byte, error) {
+	if _, err := Parse(data); err != nil {
+		return nil, err
+	}
+
+	out := make([]byte, len(data))
+	copy(out, data)
+	comma := -1
+	for i := 0; i < len(out); i++ {
+		switch c := out[i]; {
+		case c == '"':
+			for i++; i < len(out) && out[i] != '"'; i++ {
+				if out[i] == '\\' {
+					i++
+				}
+			}
+			comma = -1
+		case c == '/' && i+1 < len(out) && out[i+1] == '/':
+			for ; i < len(out) && out[i] != '\n'; i++ {
+				out[i] = ' '
+			}
+		case c == '/' && i+1 < len(out) && out[i+1] == '*':
+			end := i + 2 + strings.Index(string(out[i+2:]), "*/") + 2
+			for ; i < end; i++ {
+				if out[i] != '\n' && out[i] != '\r' {
+					out[i] = ' '
+				}
+			}
+			i--
+		case c == ',':
+			comma = i
+		case c == '}' || c == ']':
+			if comma >= 0 {
+				out[comma] = ' '
+			}
+			comma = -1
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+		default:
+			comma = -1
+		}
+	}
+	return out, nil
+}
+
+// Unmarshal decodes JSONC data into v
+func Unmarshal(data []byte, v any) error {
+	std, err := Standardize(data)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(std, v)
+}
@@ -0,0 +1,141 @@
+package jsonc
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	n, err := Parse([]byte(`{"a": 1, /* c */ "b": [true, null,], // x
+}`))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if n.Kind != Object || len(n.Members) != 2 {
+		t.Fatalf("Parse = %+v", n)
+	}
+	if b := n.Members[1]; b.Key != "b" || b.Value.Kind != Array || len(b.Value.Elems) != 2 || b.Comma < 0 {
+		t.Errorf("member b = %+v", b)
+	}
+
+	if n, err := Parse([]byte("  // nothing\n")); n != nil || err != nil {
+		t.Errorf("Parse(comment only) = %v, %v", n, err)
+	}
+
+	for _, bad := range []string{`{"a" 1}`, `{"a": 1`, `[1 2]`, `{"a": 1} x`, `/* open`, `{a: 1}`, `"unterminated`} {
+		var syntaxErr *SyntaxError
+		if _, err := Parse([]byte(bad)); !errors.As(err, &syntaxErr) {
+			t.Errorf("Parse(%q) error = %v, want SyntaxError", bad, err)
+		}
+	}
+}
+
+func TestStandardize(t *testing.T) {
+	in := "{\n  \"url\": \"http://x//y\", // c\n  \"a\": [1, 2,], /* b */\n}"
+	want := "{\n  \"url\": \"http://x//y\",     \n  \"a\": [1, 2 ]         \n}"
+	got, err := Standardize([]byte(in))
+	if err != nil {
+		t.Fatalf("Standardize failed: %v", err)
+	}
+	if string(got) != want {
+		t.Errorf("Standardize() = %q, want %q", got, want)
+	}
+
+	var v map[string]any
+	if err := Unmarshal([]byte(in), &v); err != nil || v["url"] != "http://x//y" {
+		t.Errorf("Unmarshal = %v, %v", v, err)
+	}
+}
+
+func TestPatch(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		set  func(map[string]any)
+		want string
+	}{
+		{
+			name: "unchanged",
+			in:   "{ \"a\" : 1.0 , // keep\n}",
+			set:  func(m map[string]any) {},
+			want: "{ \"a\" : 1.0 , // keep\n}",
+		},
+		{
+			name: "change nested value",
+			in:   "{\n  // servers\n  \"s\": {\"x\": 1, \"y\": 2},\n  \"z\": true\n}",
+			set:  func(m map[string]any) { m["s"].(map[string]any)["y"] = 3 },
+			want: "{\n  // servers\n  \"s\": {\"x\": 1, \"y\": 3},\n  \"z\": true\n}",
+		},
+		{
+			name: "add member",
+			in:   "{\n\t\"a\": 1 // one\n}\n",
+			set:  func(m map[string]any) { m["b"] = map[string]any{"c": []string{"d"}} },
+			want: "{\n\t\"a\": 1, // one\n\t\"b\": {\n\t\t\"c\": [\n\t\t\t\"d\"\n\t\t]\n\t}\n}\n",
+		},
+		{
+			name: "add member with trailing commas",
+			in:   "{\n  \"a\": 1,\n}",
+			set:  func(m map[string]any) { m["b"] = 2 },
+			want: "{\n  \"a\": 1,\n  \"b\": 2,\n}",
+		},
+		{
+			name: "remove last member",
+			in:   "{\n  \"a\": 1,\n  \"b\": 2 // gone\n}",
+			set:  func(m map[string]any) { delete(m, "b") },
+			want: "{\n  \"a\": 1\n}",
+		},
+		{
+			name: "remove middle member",
+			in:   "{\n  \"a\": 1,\n  \"b\": {\n    \"c\": 2\n  },\n  \"d\": 3\n}",
+			set:  func(m map[string]any) { delete(m, "b") },
+			want: "{\n  \"a\": 1,\n  \"d\": 3\n}",
+		},
+		{
+			name: "inline object",
+			in:   `{"a": 1, "b": 2, "c": 3}`,
+			set:  func(m map[string]any) { delete(m, "a"); delete(m, "c"); m["d"] = []int{1} },
+			want: `{"b": 2, "d": [1]}`,
+		},
+		{
+			name: "fill empty object",
+			in:   "{\n  \"mcp\": {}\n}",
+			set:  func(m map[string]any) { m["mcp"] = map[string]any{"x": 1} },
+			want: "{\n  \"mcp\": {\n    \"x\": 1\n  }\n}",
+		},
+		{
+			name: "replace array tail",
+			in:   "{\n  \"inputs\": [\n    // mine\n    {\"id\": \"a\", \"type\": \"promptString\"},\n    {\"id\": \"old\"}\n  ]\n}",
+			set:  func(m map[string]any) { m["inputs"] = []any{m["inputs"].([]any)[0], map[string]any{"id": "b"}} },
+			want: "{\n  \"inputs\": [\n    // mine\n    {\"id\": \"a\", \"type\": \"promptString\"},\n    {\n      \"id\": \"b\"\n    }\n  ]\n}",
+		},
+		{
+			name: "inline array",
+			in:   `{"args": ["a", "b", "c"]}`,
+			set:  func(m map[string]any) { m["args"] = []any{"a", "c", "d"} },
+			want: `{"args": ["a", "c", "d"]}`,
+		},
+		{
+			name: "empty document",
+			in:   "",
+			set:  func(m map[string]any) { m["a"] = 1 },
+			want: "{\n  \"a\": 1\n}",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := make(map[string]any)
+			if err := Unmarshal([]byte(tt.in), &m); err != nil && tt.in != "" {
+				t.Fatalf("Unmarshal failed: %v", err)
+			}
+			tt.set(m)
+			got, err := Patch([]byte(tt.in), m)
+			if err != nil {
+				t.Fatalf("Patch failed: %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("Patch() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
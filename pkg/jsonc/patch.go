@@ -0,0 +1,370 @@
+package jsonc
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"sort"
+
+	"github.com/iheanyi/agentctl/pkg/jsonutil"
+)
+
+// Patch returns data edited to hold v. Only values that differ are
+// rewritten: objects are patched member by member, new members are
+// appended in the object's own indentation and removed members are cut
+// along with their line. Arrays keep the leading run of elements that
+// still match and have the rest cut and appended the same way.
+// Everything else, including comments, key order and trailing commas, is
+// left as it was. Empty data is replaced with v marshaled as indented
+// JSON.
+func Patch(data []byte, v any) ([]byte, error) {
+	want, err := normalize(v)
+	if err != nil {
+		return nil, err
+	}
+	root, err := Parse(data)
+	if err != nil {
+		return nil, err
+	}
+	if root == nil {
+		return jsonutil.MarshalIndent(want, "", "  ")
+	}
+	std, err := Standardize(data)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &patcher{data: data, std: std, unit: detectIndent(data)}
+	if err := p.value(root, want); err != nil {
+		return nil, err
+	}
+
+	// Edits at the same offset apply in the order they were made
+	sort.SliceStable(p.edits, func(i, j int) bool { return p.edits[i].start < p.edits[j].start })
+	var out bytes.Buffer
+	pos := 0
+	for _, e := range p.edits {
+		if e.start > pos {
+			out.Write(data[pos:e.start])
+		}
+		out.WriteString(e.text)
+		pos = max(pos, e.end)
+	}
+	out.Write(data[pos:])
+	return out.Bytes(), nil
+}
+
+// normalize round-trips v through JSON so it compares equal to decoded text
+func normalize(v any) (any, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var out any
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+type edit struct {
+	start, end int
+	text       string
+}
+
+type patcher struct {
+	data  []byte
+	std   []byte // Data with comments and trailing commas blanked out
+	unit  string // One level of indentation
+	edits []edit
+}
+
+func (p *patcher) replace(start, end int, text string) {
+	p.edits = append(p.edits, edit{start: start, end: end, text: text})
+}
+
+// decoded returns the value n holds
+func (p *patcher) decoded(n *Node) (any, error) {
+	var v any
+	err := json.Unmarshal(p.std[n.Start:n.End], &v)
+	return v, err
+}
+
+func (p *patcher) value(n *Node, want any) error {
+	have, err := p.decoded(n)
+	if err != nil {
+		return err
+	}
+	if reflect.DeepEqual(have, want) {
+		return nil
+	}
+	if obj, ok := want.(map[string]any); ok && n.Kind == Object {
+		return p.object(n, obj)
+	}
+	if arr, ok := want.([]any); ok && n.Kind == Array {
+		return p.array(n, arr)
+	}
+	text, err := p.marshal(want, n)
+	if err != nil {
+		return err
+	}
+	p.replace(n.Start, n.End, text)
+	return nil
+}
+
+// marshal formats v to replace n: indented to match n's line, or on one
+// line if n is a non-empty container written on one line
+func (p *patcher) marshal(v any, n *Node) (string, error) {
+	inline := (len(n.Members) > 0 || len(n.Elems) > 0) && !bytes.ContainsAny(p.data[n.Start:n.End], "\n")
+	if inline {
+		data, err := jsonutil.Marshal(v)
+		return string(data), err
+	}
+	data, err := jsonutil.MarshalIndent(v, p.lineIndent(n.Start), p.unit)
+	return string(data), err
+}
+
+func (p *patcher) object(n *Node, want map[string]any) error {
+	var kept []*Member
+	seen := make(map[string]bool)
+	for _, m := range n.Members {
+		if _, ok := want[m.Key]; ok {
+			kept = append(kept, m)
+			seen[m.Key] = true
+		}
+	}
+	var added []string
+	for key := range want {
+		if !seen[key] {
+			added = append(added, key)
+		}
+	}
+	sort.Strings(added)
+
+	// Nothing left to anchor edits to, so write the object afresh
+	if len(kept) == 0 {
+		text, err := jsonutil.MarshalIndent(want, p.lineIndent(n.Start), p.unit)
+		if err != nil {
+			return err
+		}
+		p.replace(n.Start, n.End, string(text))
+		return nil
+	}
+
+	for _, m := range n.Members {
+		if val, ok := want[m.Key]; ok {
+			if err := p.value(m.Value, val); err != nil {
+				return err
+			}
+			continue
+		}
+		start, end := p.memberSpan(m)
+		p.replace(start, end, "")
+	}
+
+	entries := make([]entry, len(added))
+	for i, key := range added {
+		entries[i] = entry{key: key, value: want[key]}
+	}
+	return p.insert(n, n.Members[len(n.Members)-1], kept[len(kept)-1], entries)
+}
+
+// array keeps the elements of n that match want in order, cutting any
+// that don't and appending the rest of want after the last one kept
+func (p *patcher) array(n *Node, want []any) error {
+	elems := make([]*Member, len(n.Elems))
+	keep := make(map[*Member]bool)
+	var anchor *Member
+	j := 0
+	for i, e := range n.Elems {
+		elems[i] = &Member{Start: e.Start, Value: e, Comma: n.Commas[i]}
+		have, err := p.decoded(e)
+		if err != nil {
+			return err
+		}
+		if j < len(want) && reflect.DeepEqual(have, want[j]) {
+			keep[elems[i]] = true
+			anchor = elems[i]
+			j++
+		}
+	}
+
+	// Nothing left to anchor edits to, so write the array afresh
+	if anchor == nil {
+		text, err := p.marshal(want, n)
+		if err != nil {
+			return err
+		}
+		p.replace(n.Start, n.End, text)
+		return nil
+	}
+
+	for _, m := range elems {
+		if !keep[m] {
+			start, end := p.memberSpan(m)
+			p.replace(start, end, "")
+		}
+	}
+
+	entries := make([]entry, len(want)-j)
+	for i, v := range want[j:] {
+		entries[i] = entry{value: v}
+	}
+	return p.insert(n, elems[len(elems)-1], anchor, entries)
+}
+
+// entry is an object member or array element to add. Array elements have
+// no key.
+type entry struct {
+	key   string
+	value any
+}
+
+// insert adds entries to container n after anchor, the last of its
+// members that is kept, matching the container's layout. last is its last
+// member, which decides whether the file uses trailing commas.
+func (p *patcher) insert(n *Node, last, anchor *Member, entries []entry) error {
+	trailing := last.Comma >= 0
+
+	// The anchor's comma would be left trailing in a file that doesn't use them
+	if anchor != last && anchor.Comma >= 0 && !trailing && len(entries) == 0 {
+		p.replace(anchor.Comma, anchor.Comma+1, "")
+	}
+	if len(entries) == 0 {
+		return nil
+	}
+
+	multiline := bytes.IndexByte(p.data[anchor.Value.End:n.End], '\n') >= 0
+	indent := p.lineIndent(anchor.Start)
+	var buf bytes.Buffer
+	for i, e := range entries {
+		var val []byte
+		var err error
+		if multiline {
+			val, err = jsonutil.MarshalIndent(e.value, indent, p.unit)
+		} else {
+			val, err = jsonutil.Marshal(e.value)
+		}
+		if err != nil {
+			return err
+		}
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		if multiline {
+			buf.WriteString("\n" + indent)
+		} else {
+			buf.WriteByte(' ')
+		}
+		if n.Kind == Object {
+			keyText, err := jsonutil.Marshal(e.key)
+			if err != nil {
+				return err
+			}
+			buf.Write(keyText)
+			buf.WriteString(": ")
+		}
+		buf.Write(val)
+	}
+	if trailing && multiline {
+		buf.WriteByte(',')
+	}
+
+	if anchor.Comma < 0 {
+		p.replace(anchor.Value.End, anchor.Value.End, ",")
+	}
+	if multiline {
+		// After any comment trailing the anchor on its line
+		p.replace(p.lineEnd(anchor.Value.End), p.lineEnd(anchor.Value.End), buf.String())
+	} else {
+		at := anchor.Value.End
+		if anchor.Comma >= 0 {
+			at = anchor.Comma + 1
+		}
+		p.replace(at, at, buf.String())
+	}
+	return nil
+}
+
+// memberSpan returns the range to cut to remove m: its whole line, with
+// any comment after it, when it sits on a line of its own
+func (p *patcher) memberSpan(m *Member) (int, int) {
+	start := m.Start
+	end := m.Value.End
+	if m.Comma >= 0 {
+		end = m.Comma + 1
+	}
+
+	lineStart := start
+	for lineStart > 0 && (p.data[lineStart-1] == ' ' || p.data[lineStart-1] == '\t') {
+		lineStart--
+	}
+	if lineStart > 0 && p.data[lineStart-1] != '\n' {
+		// Shares its line, so cut the space before it, or after it if
+		// it directly follows the brace
+		if lineStart < start {
+			return lineStart, end
+		}
+		for end < len(p.data) && (p.data[end] == ' ' || p.data[end] == '\t') {
+			end++
+		}
+		return start, end
+	}
+
+	lineEnd := p.lineEnd(end)
+	if p.onlySpace(end, lineEnd) || p.isComment(end, lineEnd) {
+		end = lineEnd
+		for end < len(p.data) && (p.data[end] == '\r' || p.data[end] == '\n') {
+			end++
+			if p.data[end-1] == '\n' {
+				break
+			}
+		}
+	}
+	return lineStart, end
+}
+
+// lineEnd returns the offset of the newline ending the line at pos, or
+// the end of the data
+func (p *patcher) lineEnd(pos int) int {
+	if i := bytes.IndexByte(p.data[pos:], '\n'); i >= 0 {
+		end := pos + i
+		if end > pos && p.data[end-1] == '\r' {
+			end--
+		}
+		return end
+	}
+	return len(p.data)
+}
+
+func (p *patcher) onlySpace(start, end int) bool {
+	return len(bytes.TrimSpace(p.data[start:end])) == 0
+}
+
+// isComment reports whether data[start:end] is a line comment, possibly
+// after some whitespace
+func (p *patcher) isComment(start, end int) bool {
+	return bytes.HasPrefix(bytes.TrimSpace(p.data[start:end]), []byte("//"))
+}
+
+// lineIndent returns the whitespace leading the line at pos
+func (p *patcher) lineIndent(pos int) string {
+	start := bytes.LastIndexByte(p.data[:pos], '\n') + 1
+	end := start
+	for end < len(p.data) && (p.data[end] == ' ' || p.data[end] == '\t') {
+		end++
+	}
+	return string(p.data[start:end])
+}
+
+// detectIndent returns the indentation of the first indented line, or
+// two spaces
+func detectIndent(data []byte) string {
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		trimmed := bytes.TrimLeft(line, " \t")
+		if len(trimmed) < len(line) && len(bytes.TrimSpace(trimmed)) > 0 {
+			return string(line[:len(line)-len(trimmed)])
+		}
+	}
+	return "  "
+}
@@ -0,0 +1,200 @@
+package lint
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	toml "github.com/pelletier/go-toml/v2"
+)
+
+// codexServerKeys are the keys Codex accepts in an [mcp_servers.<name>] table
+var codexServerKeys = map[string]string{
+	"command":              "string",
+	"args":                 "array",
+	"env":                  "table",
+	"env_vars":             "array",
+	"cwd":                  "string",
+	"url":                  "string",
+	"bearer_token_env_var": "string",
+	"http_headers":         "table",
+	"env_http_headers":     "table",
+	"enabled":              "boolean",
+	"startup_timeout_sec":  "number",
+	"startup_timeout_ms":   "number",
+	"tool_timeout_sec":     "number",
+	"enabled_tools":        "array",
+	"disabled_tools":       "array",
+}
+
+// codexEnums are top-level Codex settings with a fixed set of values
+var codexEnums = map[string][]string{
+	"approval_policy": {"untrusted", "on-failure", "on-request", "never"},
+	"sandbox_mode":    {"read-only", "workspace-write", "danger-full-access"},
+}
+
+// CheckCodexConfig validates the structure of a Codex config.toml
+func CheckCodexConfig(file string, data []byte) []Issue {
+	var raw map[string]any
+	if err := toml.Unmarshal(data, &raw); err != nil {
+		issue := Issue{Rule: RuleCodexConfig, Severity: SeverityError, File: file, Message: "invalid TOML: " + err.Error()}
+		var derr *toml.DecodeError
+		if errors.As(err, &derr) {
+			issue.Line, issue.Column = derr.Position()
+		}
+		return []Issue{issue}
+	}
+
+	var issues []Issue
+	fail := func(sev Severity, line int, format string, args ...any) {
+		issues = append(issues, Issue{Rule: RuleCodexConfig, Severity: sev, File: file, Line: line, Message: fmt.Sprintf(format, args...)})
+	}
+
+	for _, key := range sortedKeys(codexEnums) {
+		value, ok := raw[key]
+		if !ok {
+			continue
+		}
+		s, _ := value.(string)
+		if !contains(codexEnums[key], s) {
+			fail(SeverityError, keyLine(data, key), "%s = %#v is not one of %s", key, value, strings.Join(codexEnums[key], ", "))
+		}
+	}
+
+	servers, ok := raw["mcp_servers"]
+	if !ok {
+		return issues
+	}
+	table, ok := servers.(map[string]any)
+	if !ok {
+		fail(SeverityError, keyLine(data, "mcp_servers"), "mcp_servers should be a table")
+		return issues
+	}
+
+	for _, name := range sortedKeys(table) {
+		line := tableLine(data, "mcp_servers", name)
+		server, ok := table[name].(map[string]any)
+		if !ok {
+			fail(SeverityError, line, "mcp_servers.%s should be a table", name)
+			continue
+		}
+
+		_, hasCommand := server["command"]
+		_, hasURL := server["url"]
+		switch {
+		case !hasCommand && !hasURL:
+			fail(SeverityError, line, "mcp_servers.%s: missing 'command' or 'url'", name)
+		case hasCommand && hasURL:
+			fail(SeverityError, line, "mcp_servers.%s: has both 'command' and 'url' (should have only one)", name)
+		}
+
+		for _, key := range sortedKeys(server) {
+			want, known := codexServerKeys[key]
+			keyAt := keyLine(data, key, "mcp_servers", name)
+			if !known {
+				fail(SeverityWarning, keyAt, "mcp_servers.%s: unknown key %q", name, key)
+				continue
+			}
+			if got := tomlType(server[key]); got != want {
+				fail(SeverityError, keyAt, "mcp_servers.%s.%s: expected %s, got %s", name, key, want, got)
+				continue
+			}
+			switch want {
+			case "array":
+				for _, item := range server[key].([]any) {
+					if _, ok := item.(string); !ok {
+						fail(SeverityError, keyAt, "mcp_servers.%s.%s: items should be strings", name, key)
+						break
+					}
+				}
+			case "table":
+				for _, k := range sortedKeys(server[key].(map[string]any)) {
+					if _, ok := server[key].(map[string]any)[k].(string); !ok {
+						fail(SeverityError, keyAt, "mcp_servers.%s.%s.%s: expected string", name, key, k)
+					}
+				}
+			case "number":
+				if n, _ := toFloat(server[key]); n < 0 {
+					fail(SeverityError, keyAt, "mcp_servers.%s.%s: must not be negative", name, key)
+				}
+			}
+		}
+	}
+	return issues
+}
+
+// tomlType names the TOML type of a decoded value
+func tomlType(v any) string {
+	switch v.(type) {
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case int64, float64:
+		return "number"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "table"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+func toFloat(v any) (float64, bool) {
+	switch n := v.(type) {
+	case int64:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
+
+// tableLine returns the line of a [table] header, or 0 if it isn't found
+func tableLine(data []byte, table ...string) int {
+	parts := make([]string, len(table))
+	for i, part := range table {
+		parts[i] = `"?` + regexp.QuoteMeta(part) + `"?`
+	}
+	header := regexp.MustCompile(`^\s*\[\s*` + strings.Join(parts, `\s*\.\s*`) + `\s*\]`)
+	for i, line := range strings.Split(string(data), "\n") {
+		if header.MatchString(line) {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// keyLine returns the line a key is set on within a table (no table for
+// the top level), falling back to the table's header line
+func keyLine(data []byte, key string, table ...string) int {
+	start := 0
+	if len(table) > 0 {
+		if start = tableLine(data, table...); start == 0 {
+			return 0
+		}
+	}
+	assignment := regexp.MustCompile(`^\s*"?` + regexp.QuoteMeta(key) + `"?\s*=`)
+	lines := strings.Split(string(data), "\n")
+	// start is the 1-based header line, so lines[start] is the first line
+	// of the table's body
+	for i := start; i < len(lines); i++ {
+		if strings.HasPrefix(strings.TrimSpace(lines[i]), "[") {
+			break
+		}
+		if assignment.MatchString(lines[i]) {
+			return i + 1
+		}
+	}
+	return start
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
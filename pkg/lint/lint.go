@@ -0,0 +1,634 @@
+// Package lint checks agentctl's own configuration and resources for
+// mistakes that loading alone doesn't catch: references to servers,
+// skills or tools that don't exist, unresolvable secrets, missing
+// binaries, bad globs and conflicting names.
+//
+// Each Issue carries a rule ID and a severity so results can be
+// filtered, and can be written as SARIF for code scanning in CI.
+package lint
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/iheanyi/agentctl/pkg/command"
+	"github.com/iheanyi/agentctl/pkg/config"
+	"github.com/iheanyi/agentctl/pkg/interp"
+	"github.com/iheanyi/agentctl/pkg/profile"
+	"github.com/iheanyi/agentctl/pkg/schema"
+	"github.com/iheanyi/agentctl/pkg/secrets"
+	"github.com/iheanyi/agentctl/pkg/toolname"
+)
+
+// Severity is how serious an issue is
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+	SeverityInfo    Severity = "info"
+)
+
+// rank orders severities from least to most serious
+func (s Severity) rank() int {
+	switch s {
+	case SeverityError:
+		return 2
+	case SeverityWarning:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// AtLeast reports whether s is at least as serious as min
+func (s Severity) AtLeast(min Severity) bool {
+	return s.rank() >= min.rank()
+}
+
+// ParseSeverity parses "error", "warning" or "info"
+func ParseSeverity(s string) (Severity, error) {
+	switch sev := Severity(strings.ToLower(s)); sev {
+	case SeverityError, SeverityWarning, SeverityInfo:
+		return sev, nil
+	}
+	return "", fmt.Errorf("invalid severity %q (expected error, warning or info)", s)
+}
+
+// Issue is a single lint finding
+type Issue struct {
+	Rule     string   `json:"rule"`
+	Severity Severity `json:"severity"`
+	Message  string   `json:"message"`
+	Resource string   `json:"resource,omitempty"` // e.g. "server github"
+	File     string   `json:"file,omitempty"`
+	Line     int      `json:"line,omitempty"`
+	Column   int      `json:"column,omitempty"`
+}
+
+// Rule describes a lint check
+type Rule struct {
+	ID          string
+	Description string
+}
+
+// Rule IDs
+const (
+	RuleSchema          = "schema"
+	RuleDuplicateName   = "duplicate-name"
+	RuleServerCommand   = "server-command"
+	RuleServerBinary    = "server-binary"
+	RuleSecret          = "unresolved-secret"
+	RuleUnknownTool     = "unknown-tool"
+	RuleUnknownServer   = "unknown-server"
+	RuleInvalidGlob     = "invalid-glob"
+	RuleMissingSkill    = "missing-skill"
+	RuleProfileResource = "profile-resource"
+	RuleCodexConfig     = "codex-config"
+	RuleToolConfig      = "tool-config"
+	RuleUnknownVariable = "unknown-variable"
+)
+
+// Rules lists every check, for documentation and SARIF output
+var Rules = []Rule{
+	{RuleSchema, "Config and resource files match their JSON Schema"},
+	{RuleDuplicateName, "Resource names are unique within and across scopes"},
+	{RuleServerCommand, "Servers have a command (stdio) or URL (http/sse)"},
+	{RuleServerBinary, "Server commands are installed"},
+	{RuleSecret, "keychain: references resolve to a stored secret"},
+	{RuleUnknownTool, "Tool names in allowed/disallowed tools are known"},
+	{RuleUnknownServer, "MCP tool names refer to configured servers"},
+	{RuleInvalidGlob, "Rule globs and paths are valid patterns"},
+	{RuleMissingSkill, "Skills preloaded by agents exist"},
+	{RuleProfileResource, "Resources named by profiles exist"},
+	{RuleCodexConfig, "Codex config.toml has the expected structure"},
+	{RuleToolConfig, "Tool config files parse and have the expected structure"},
+	{RuleUnknownVariable, "${...} references in servers name a known variable"},
+}
+
+// Linter checks a loaded configuration
+type Linter struct {
+	// Config is the merged global and project configuration
+	Config *config.Config
+
+	// Global is the global configuration on its own, used to report
+	// project servers that override global ones. Optional.
+	Global *config.Config
+
+	// Profiles are the profiles to check
+	Profiles []*profile.Profile
+
+	// LookPath finds server binaries (defaults to exec.LookPath)
+	LookPath func(file string) (string, error)
+
+	// SecretExists reports whether a keychain secret is stored
+	// (defaults to querying the system keychain)
+	SecretExists func(name string) bool
+}
+
+// Run runs every check and returns the issues found, most serious first
+func (l *Linter) Run() []Issue {
+	var issues []Issue
+	add := func(found ...Issue) { issues = append(issues, found...) }
+
+	add(l.checkSchemas()...)
+	add(l.checkDuplicates()...)
+	add(l.checkServers()...)
+	add(l.checkCommands()...)
+	add(l.checkRules()...)
+	add(l.checkAgents()...)
+	add(l.checkProfiles()...)
+
+	Sort(issues)
+	return issues
+}
+
+// Sort orders issues by severity, then file and position
+func Sort(issues []Issue) {
+	sort.SliceStable(issues, func(i, j int) bool {
+		a, b := issues[i], issues[j]
+		if a.Severity != b.Severity {
+			return a.Severity.rank() > b.Severity.rank()
+		}
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		return a.Line < b.Line
+	})
+}
+
+// Filter returns the issues at least as serious as min
+func Filter(issues []Issue, min Severity) []Issue {
+	var filtered []Issue
+	for _, issue := range issues {
+		if issue.Severity.AtLeast(min) {
+			filtered = append(filtered, issue)
+		}
+	}
+	return filtered
+}
+
+// Count returns the number of issues with the given severity
+func Count(issues []Issue, severity Severity) int {
+	n := 0
+	for _, issue := range issues {
+		if issue.Severity == severity {
+			n++
+		}
+	}
+	return n
+}
+
+// checkSchemas validates config, profile and resource files against
+// their schemas. Unknown fields are ignored when loading, so they're
+// reported as warnings.
+func (l *Linter) checkSchemas() []Issue {
+	var issues []Issue
+	validate := func(file string, check func([]byte) error) {
+		if file == "" {
+			return
+		}
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return
+		}
+		issues = append(issues, schemaIssues(check(data))...)
+	}
+
+	for _, file := range []string{l.globalPath(), l.Config.ProjectPath} {
+		validate(file, func(data []byte) error { return schema.ValidateJSON(file, data, config.JSONSchema()) })
+	}
+	for _, p := range l.Profiles {
+		validate(p.Path, func(data []byte) error { return schema.ValidateJSON(p.Path, data, profile.JSONSchema()) })
+	}
+	for _, cmd := range l.Config.LoadedCommands {
+		if filepath.Ext(cmd.Path) == ".json" {
+			validate(cmd.Path, func(data []byte) error { return schema.ValidateJSON(cmd.Path, data, command.JSONSchema()) })
+		}
+	}
+	return issues
+}
+
+// globalPath returns the global config file, if it exists
+func (l *Linter) globalPath() string {
+	p := l.Config.Path
+	if l.Global != nil {
+		p = l.Global.Path
+	}
+	if p == "" || p == l.Config.ProjectPath {
+		return ""
+	}
+	if _, err := os.Stat(p); err != nil {
+		return ""
+	}
+	return p
+}
+
+// schemaIssues converts a schema validation error into issues
+func schemaIssues(err error) []Issue {
+	if err == nil {
+		return nil
+	}
+	var verr *schema.ValidationError
+	if !errors.As(err, &verr) {
+		return []Issue{{Rule: RuleSchema, Severity: SeverityError, Message: err.Error()}}
+	}
+	issues := make([]Issue, len(verr.Errors))
+	for i, e := range verr.Errors {
+		msg := e.Message
+		if e.Path != "" {
+			msg = e.Path + ": " + msg
+		}
+		issues[i] = Issue{Rule: RuleSchema, Severity: SeverityWarning, Message: msg, File: verr.File, Line: e.Line, Column: e.Column}
+	}
+	return issues
+}
+
+// named is a resource for duplicate detection
+type named struct {
+	kind, name, scope, file string
+}
+
+// checkDuplicates reports resources defined more than once. Duplicates
+// within a scope are errors since only one of them is used; a project
+// resource overriding a global one is worth a warning.
+func (l *Linter) checkDuplicates() []Issue {
+	var all []named
+	for _, c := range l.Config.LoadedCommands {
+		all = append(all, named{"command", c.Name, c.Scope, c.Path})
+	}
+	for _, r := range l.Config.LoadedRules {
+		all = append(all, named{"rule", r.Name, r.Scope, r.Path})
+	}
+	for _, s := range l.Config.LoadedSkills {
+		all = append(all, named{"skill", s.Name, s.Scope, s.Path})
+	}
+	for _, a := range l.Config.LoadedAgents {
+		all = append(all, named{"agent", a.Name, a.Scope, a.Path})
+	}
+
+	groups := make(map[string][]named)
+	var keys []string
+	for _, n := range all {
+		key := n.kind + "/" + n.name
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], n)
+	}
+
+	var issues []Issue
+	for _, key := range keys {
+		group := groups[key]
+		if len(group) < 2 {
+			continue
+		}
+		first := group[0]
+		for _, dup := range group[1:] {
+			issue := Issue{Rule: RuleDuplicateName, Resource: first.kind + " " + first.name, File: dup.file}
+			if dup.scope == first.scope {
+				issue.Severity = SeverityError
+				issue.Message = fmt.Sprintf("%s %q is defined more than once in %s scope (also %s)", first.kind, first.name, scopeName(first.scope), first.file)
+			} else {
+				issue.Severity = SeverityWarning
+				issue.Message = fmt.Sprintf("%s %q in %s scope shadows the one in %s scope (%s)", first.kind, first.name, scopeName(dup.scope), scopeName(first.scope), first.file)
+			}
+			issues = append(issues, issue)
+		}
+	}
+
+	if l.Global != nil && l.Config.ProjectPath != "" {
+		for _, name := range sortedKeys(l.Config.Servers) {
+			server := l.Config.Servers[name]
+			if _, ok := l.Global.Servers[name]; ok && server.Scope == string(config.ScopeLocal) {
+				issues = append(issues, Issue{
+					Rule:     RuleDuplicateName,
+					Severity: SeverityInfo,
+					Resource: "server " + name,
+					File:     l.Config.ProjectPath,
+					Message:  fmt.Sprintf("project server %q overrides the global server of the same name", name),
+				})
+			}
+		}
+	}
+	return issues
+}
+
+func scopeName(scope string) string {
+	if scope == "" {
+		return string(config.ScopeGlobal)
+	}
+	return scope
+}
+
+// checkServers checks that servers can start and their secrets resolve
+func (l *Linter) checkServers() []Issue {
+	lookPath := l.LookPath
+	if lookPath == nil {
+		lookPath = exec.LookPath
+	}
+	secretExists := l.SecretExists
+	if secretExists == nil {
+		store := secrets.NewStore()
+		secretExists = func(name string) bool {
+			_, err := store.Get(name)
+			return err == nil
+		}
+	}
+
+	var issues []Issue
+	for _, name := range sortedKeys(l.Config.Servers) {
+		server := l.Config.Servers[name]
+		if server.Disabled {
+			continue
+		}
+		issue := func(rule string, sev Severity, format string, args ...any) {
+			issues = append(issues, Issue{
+				Rule:     rule,
+				Severity: sev,
+				Resource: "server " + name,
+				File:     l.serverFile(server.Scope),
+				Message:  fmt.Sprintf(format, args...),
+			})
+		}
+
+		switch {
+		case server.Container != nil:
+			// The runtime runs the container, so only the image matters
+			if server.Container.Image == "" && server.Source.Type != "git" && server.Source.Type != "local" {
+				issue(RuleServerCommand, SeverityError, "container server %q has neither an image nor a source to build", name)
+			}
+		case server.Command == "" && server.URL == "":
+			issue(RuleServerCommand, SeverityError, "server %q has neither a command nor a url", name)
+		case server.Command != "" && server.URL != "":
+			issue(RuleServerCommand, SeverityWarning, "server %q has both a command and a url; the url is ignored", name)
+		case server.Command == "" && server.Transport == "stdio":
+			issue(RuleServerCommand, SeverityError, "stdio server %q has no command", name)
+		case server.URL == "" && (server.Transport == "http" || server.Transport == "sse"):
+			issue(RuleServerCommand, SeverityError, "%s server %q has no url", server.Transport, name)
+		}
+
+		if server.Command != "" && server.Build == nil && server.Container == nil && !strings.Contains(server.Command, "$") {
+			if _, err := lookPath(server.Command); err != nil {
+				issue(RuleServerBinary, SeverityWarning, "command %q for server %q was not found on PATH", server.Command, name)
+			}
+		}
+
+		refs := make(map[string]string)
+		for k, v := range server.Env {
+			refs["env "+k] = v
+		}
+		for k, v := range server.Headers {
+			refs["header "+k] = v
+		}
+		for _, field := range sortedKeys(refs) {
+			ref, ok := strings.CutPrefix(refs[field], "keychain:")
+			if ok && !secretExists(ref) {
+				issue(RuleSecret, SeverityError, "%s of server %q refers to keychain secret %q, which isn't stored (set it with 'agentctl secret set %s')", field, name, ref, ref)
+			}
+		}
+
+		fields := append([]string{server.Command, server.URL}, server.Args...)
+		for _, field := range sortedKeys(refs) {
+			fields = append(fields, refs[field])
+		}
+		unknown := make(map[string]bool)
+		for _, field := range fields {
+			for _, ref := range interp.References(field) {
+				// Upper-case names are usually the tool's own ${VAR} expansion
+				if !interp.Known(ref, l.Config.Vars) && ref != strings.ToUpper(ref) {
+					unknown[ref] = true
+				}
+			}
+		}
+		for _, ref := range sortedKeys(unknown) {
+			issue(RuleUnknownVariable, SeverityWarning, "server %q refers to unknown variable ${%s}, which is left as written", name, ref)
+		}
+	}
+	return issues
+}
+
+// serverFile returns the config file a server of the given scope is in
+func (l *Linter) serverFile(scope string) string {
+	if scope == string(config.ScopeLocal) && l.Config.ProjectPath != "" {
+		return l.Config.ProjectPath
+	}
+	if p := l.globalPath(); p != "" {
+		return p
+	}
+	return l.Config.Path
+}
+
+// checkCommands checks the tools commands allow and disallow
+func (l *Linter) checkCommands() []Issue {
+	var issues []Issue
+	for _, cmd := range l.Config.LoadedCommands {
+		resource := "command " + cmd.Name
+		issues = append(issues, l.checkTools(resource, cmd.Path, cmd.AllowedTools)...)
+		issues = append(issues, l.checkTools(resource, cmd.Path, cmd.DisallowedTools)...)
+	}
+	return issues
+}
+
+// checkTools checks tool names: MCP tools must name a configured server,
+// and built-in tools should be in a known vocabulary
+func (l *Linter) checkTools(resource, file string, names []string) []Issue {
+	var issues []Issue
+	for _, name := range names {
+		base, _, _ := strings.Cut(name, "(")
+		if base == "" || base == "*" {
+			continue
+		}
+		if server, ok := toolname.MCPServer(base); ok {
+			if _, exists := l.Config.Servers[server]; !exists {
+				issues = append(issues, Issue{
+					Rule:     RuleUnknownServer,
+					Severity: SeverityError,
+					Resource: resource,
+					File:     file,
+					Message:  fmt.Sprintf("tool %q refers to MCP server %q, which isn't configured", name, server),
+				})
+			}
+			continue
+		}
+		if !isKnownTool(base) {
+			issues = append(issues, Issue{
+				Rule:     RuleUnknownTool,
+				Severity: SeverityWarning,
+				Resource: resource,
+				File:     file,
+				Message:  fmt.Sprintf("unknown tool %q", name),
+			})
+		}
+	}
+	return issues
+}
+
+// isKnownTool reports whether a built-in tool name is canonical or
+// native to any known tool
+func isKnownTool(name string) bool {
+	canonical := toolname.Canonicalize(name)
+	for _, c := range toolname.Canonical {
+		if c == canonical {
+			return true
+		}
+	}
+	return false
+}
+
+// checkRules checks rule file patterns
+func (l *Linter) checkRules() []Issue {
+	var issues []Issue
+	for _, r := range l.Config.LoadedRules {
+		if r.Frontmatter == nil {
+			continue
+		}
+		patterns := append(append([]string{}, r.Frontmatter.Paths...), r.Frontmatter.Globs...)
+		if r.Frontmatter.Applies != "" {
+			patterns = append(patterns, r.Frontmatter.Applies)
+		}
+		for _, pattern := range patterns {
+			if err := ValidGlob(pattern); err != nil {
+				issues = append(issues, Issue{
+					Rule:     RuleInvalidGlob,
+					Severity: SeverityError,
+					Resource: "rule " + r.Name,
+					File:     r.Path,
+					Message:  fmt.Sprintf("invalid pattern %q: %v", pattern, err),
+				})
+			}
+		}
+	}
+	return issues
+}
+
+// ValidGlob checks a file pattern. Patterns use path.Match syntax
+// extended with ** and {a,b} alternatives, as tools accept them.
+func ValidGlob(pattern string) error {
+	if strings.TrimSpace(pattern) == "" {
+		return errors.New("empty pattern")
+	}
+	depth := 0
+	for _, r := range pattern {
+		switch r {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth < 0 {
+				return errors.New("unmatched '}'")
+			}
+		}
+	}
+	if depth != 0 {
+		return errors.New("unmatched '{'")
+	}
+	// Braces and commas are literal to path.Match, so this checks the
+	// remaining syntax: character classes and escapes
+	if _, err := path.Match(pattern, ""); err != nil {
+		return err
+	}
+	return nil
+}
+
+// checkAgents checks the skills and tools agents refer to
+func (l *Linter) checkAgents() []Issue {
+	skills := make(map[string]bool)
+	for _, s := range l.Config.LoadedSkills {
+		skills[s.Name] = true
+	}
+
+	var issues []Issue
+	for _, a := range l.Config.LoadedAgents {
+		resource := "agent " + a.Name
+		for _, name := range a.Skills {
+			if !skills[name] {
+				issues = append(issues, Issue{
+					Rule:     RuleMissingSkill,
+					Severity: SeverityError,
+					Resource: resource,
+					File:     a.Path,
+					Message:  fmt.Sprintf("agent %q preloads skill %q, which doesn't exist", a.Name, name),
+				})
+			}
+		}
+		issues = append(issues, l.checkTools(resource, a.Path, a.Tools)...)
+		issues = append(issues, l.checkTools(resource, a.Path, a.DisallowedTools)...)
+	}
+	return issues
+}
+
+// checkProfiles checks that profiles name existing resources
+func (l *Linter) checkProfiles() []Issue {
+	exists := map[string]map[string]bool{
+		"server":  {},
+		"command": {},
+		"rule":    {},
+		"skill":   {},
+	}
+	for name := range l.Config.Servers {
+		exists["server"][name] = true
+	}
+	for _, c := range l.Config.LoadedCommands {
+		exists["command"][c.Name] = true
+	}
+	for _, r := range l.Config.LoadedRules {
+		exists["rule"][r.Name] = true
+	}
+	for _, s := range l.Config.LoadedSkills {
+		exists["skill"][s.Name] = true
+	}
+
+	var issues []Issue
+	for _, p := range l.Profiles {
+		check := func(kind string, names []string) {
+			for _, name := range names {
+				if !exists[kind][name] {
+					issues = append(issues, Issue{
+						Rule:     RuleProfileResource,
+						Severity: SeverityError,
+						Resource: "profile " + p.Name,
+						File:     p.Path,
+						Message:  fmt.Sprintf("profile %q includes %s %q, which doesn't exist", p.Name, kind, name),
+					})
+				}
+			}
+		}
+		check("server", p.Servers)
+		check("command", p.Commands)
+		check("rule", p.Rules)
+		check("skill", p.Skills)
+
+		for _, name := range p.Disabled {
+			found := false
+			for _, names := range exists {
+				found = found || names[name]
+			}
+			if !found {
+				issues = append(issues, Issue{
+					Rule:     RuleProfileResource,
+					Severity: SeverityWarning,
+					Resource: "profile " + p.Name,
+					File:     p.Path,
+					Message:  fmt.Sprintf("profile %q disables %q, which doesn't match any resource", p.Name, name),
+				})
+			}
+		}
+	}
+	return issues
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,219 @@
+package lint
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/iheanyi/agentctl/pkg/agent"
+	"github.com/iheanyi/agentctl/pkg/command"
+	"github.com/iheanyi/agentctl/pkg/config"
+	"github.com/iheanyi/agentctl/pkg/mcp"
+	"github.com/iheanyi/agentctl/pkg/profile"
+	"github.com/iheanyi/agentctl/pkg/rule"
+	"github.com/iheanyi/agentctl/pkg/skill"
+)
+
+// rulesOf returns "severity rule resource" for each issue, sorted
+func rulesOf(issues []Issue) []string {
+	var got []string
+	for _, issue := range issues {
+		got = append(got, string(issue.Severity)+" "+issue.Rule+" "+issue.Resource)
+	}
+	sort.Strings(got)
+	return got
+}
+
+func TestLinterRun(t *testing.T) {
+	cfg := &config.Config{
+		Servers: map[string]*mcp.Server{
+			"github":  {Name: "github", Command: "gh-mcp", Env: map[string]string{"TOKEN": "keychain:gh", "OTHER": "keychain:missing"}},
+			"empty":   {Name: "empty"},
+			"remote":  {Name: "remote", Transport: "http"},
+			"off":     {Name: "off", Disabled: true},
+			"ok":      {Name: "ok", Command: "npx"},
+			"vars":    {Name: "vars", Command: "npx", Args: []string{"${project.root}", "${env.HOME}", "${region}", "${HOME}", "${typo}"}},
+			"image":   {Name: "image", Container: &mcp.Container{Image: "ghcr.io/acme/weather:1.2.0"}},
+			"unbuilt": {Name: "unbuilt", Container: &mcp.Container{}},
+		},
+		Vars: map[string]string{"region": "us-east-1"},
+		LoadedCommands: []*command.Command{
+			{Name: "ship", Scope: "global", AllowedTools: []string{"Read", "Bash(git push:*)", "mcp__github__create_pr", "mcp__jira__create", "Frobnicate"}},
+			{Name: "ship", Scope: "local"},
+			{Name: "dup", Scope: "global"},
+			{Name: "dup", Scope: "global"},
+		},
+		LoadedRules: []*rule.Rule{
+			{Name: "ts", Frontmatter: &rule.Frontmatter{Globs: []string{"src/**/*.{ts,tsx}"}, Paths: []string{"[a-"}}},
+		},
+		LoadedSkills: []*skill.Skill{{Name: "review"}},
+		LoadedAgents: []*agent.Agent{
+			{Name: "reviewer", Skills: []string{"review", "nope"}, Tools: []string{"read_file", "mcp__ok"}},
+		},
+	}
+	linter := &Linter{
+		Config: cfg,
+		Profiles: []*profile.Profile{
+			{Name: "work", Servers: []string{"github", "gone"}, Skills: []string{"review"}, Disabled: []string{"ok", "zzz"}},
+		},
+		LookPath: func(file string) (string, error) {
+			if file == "npx" {
+				return "/usr/bin/npx", nil
+			}
+			return "", errors.New("not found")
+		},
+		SecretExists: func(name string) bool { return name == "gh" },
+	}
+
+	got := rulesOf(linter.Run())
+	want := []string{
+		"error duplicate-name command dup",
+		"error invalid-glob rule ts",
+		"error missing-skill agent reviewer",
+		"error profile-resource profile work",
+		"error server-command server empty",
+		"error server-command server remote",
+		"error server-command server unbuilt",
+		"error unknown-server command ship",
+		"error unresolved-secret server github",
+		"warning duplicate-name command ship",
+		"warning profile-resource profile work",
+		"warning server-binary server github",
+		"warning unknown-tool command ship",
+		"warning unknown-variable server vars",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Run() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestLinterSchemaAndOverrides(t *testing.T) {
+	dir := t.TempDir()
+	globalPath := filepath.Join(dir, "agentctl.json")
+	projectPath := filepath.Join(dir, "project", ".agentctl.json")
+	os.MkdirAll(filepath.Dir(projectPath), 0755)
+	os.WriteFile(globalPath, []byte("{\n  \"version\": \"1\",\n  \"severs\": {}\n}\n"), 0644)
+	os.WriteFile(projectPath, []byte(`{"version": "1"}`), 0644)
+
+	linter := &Linter{
+		Config: &config.Config{
+			Path:        globalPath,
+			ProjectPath: projectPath,
+			Servers:     map[string]*mcp.Server{"fs": {Name: "fs", Command: "npx", Scope: "local"}},
+		},
+		Global: &config.Config{
+			Path:    globalPath,
+			Servers: map[string]*mcp.Server{"fs": {Name: "fs", Command: "npx"}},
+		},
+		LookPath: func(string) (string, error) { return "", nil },
+	}
+
+	issues := linter.Run()
+	if len(issues) != 2 {
+		t.Fatalf("Run() = %+v, want schema warning and override info", issues)
+	}
+	schemaIssue, override := issues[0], issues[1]
+	if schemaIssue.Rule != RuleSchema || schemaIssue.File != globalPath || schemaIssue.Line != 3 ||
+		!strings.Contains(schemaIssue.Message, `did you mean "servers"`) {
+		t.Errorf("schema issue = %+v", schemaIssue)
+	}
+	if override.Rule != RuleDuplicateName || override.Severity != SeverityInfo || override.File != projectPath {
+		t.Errorf("override issue = %+v", override)
+	}
+}
+
+func TestValidGlob(t *testing.T) {
+	for _, pattern := range []string{"*.ts", "src/**/*.go", "**/*.{ts,tsx}", `\[literal\]`, "[a-z]*.md"} {
+		if err := ValidGlob(pattern); err != nil {
+			t.Errorf("ValidGlob(%q) error = %v", pattern, err)
+		}
+	}
+	for _, pattern := range []string{"", "[a-", "{a,b", "a}", `trailing\`} {
+		if err := ValidGlob(pattern); err == nil {
+			t.Errorf("ValidGlob(%q) should fail", pattern)
+		}
+	}
+}
+
+func TestCheckCodexConfig(t *testing.T) {
+	data := `model = "o3"
+approval_policy = "sometimes"
+
+[mcp_servers.fs]
+command = "npx"
+args = ["-y", 3]
+startup_timeout_sec = -1
+enabled_tools = ["read"]
+bogus = true
+
+[mcp_servers."with.dot"]
+url = "https://example.com/mcp"
+
+[mcp_servers.none]
+env = { A = "b" }
+`
+	var got []string
+	for _, issue := range CheckCodexConfig("config.toml", []byte(data)) {
+		got = append(got, strings.Join([]string{string(issue.Severity), strconv.Itoa(issue.Line), issue.Message}, " "))
+	}
+	want := []string{
+		`error 2 approval_policy = "sometimes" is not one of untrusted, on-failure, on-request, never`,
+		`error 6 mcp_servers.fs.args: items should be strings`,
+		`warning 9 mcp_servers.fs: unknown key "bogus"`,
+		`error 7 mcp_servers.fs.startup_timeout_sec: must not be negative`,
+		`error 14 mcp_servers.none: missing 'command' or 'url'`,
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("CheckCodexConfig() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	issues := CheckCodexConfig("config.toml", []byte("[mcp_servers\n"))
+	if len(issues) != 1 || issues[0].Line != 1 || !strings.Contains(issues[0].Message, "invalid TOML") {
+		t.Errorf("CheckCodexConfig(invalid) = %+v", issues)
+	}
+}
+
+func TestSARIF(t *testing.T) {
+	issues := []Issue{
+		{Rule: RuleInvalidGlob, Severity: SeverityError, Message: "bad", File: "/repo/.agentctl/rules/a.md", Line: 2, Column: 3},
+		{Rule: RuleDuplicateName, Severity: SeverityInfo, Message: "shadowed", File: "/home/me/agentctl.json"},
+		{Rule: RuleSecret, Severity: SeverityWarning, Message: "no file"},
+	}
+	log := SARIF(issues, "1.2.3", "/repo")
+	if log.Version != "2.1.0" || len(log.Runs) != 1 {
+		t.Fatalf("SARIF() = %+v", log)
+	}
+	run := log.Runs[0]
+	if run.Tool.Driver.Version != "1.2.3" || len(run.Tool.Driver.Rules) != len(Rules) {
+		t.Errorf("driver = %+v", run.Tool.Driver)
+	}
+
+	first := run.Results[0]
+	loc := first.Locations[0].PhysicalLocation
+	if first.Level != "error" || loc.ArtifactLocation.URI != ".agentctl/rules/a.md" || loc.Region.StartLine != 2 || loc.Region.StartColumn != 3 {
+		t.Errorf("result[0] = %+v, location %+v", first, loc)
+	}
+	second := run.Results[1]
+	if second.Level != "note" || second.Locations[0].PhysicalLocation.ArtifactLocation.URI != "file:///home/me/agentctl.json" || second.Locations[0].PhysicalLocation.Region != nil {
+		t.Errorf("result[1] = %+v", second)
+	}
+	if third := run.Results[2]; third.Level != "warning" || third.Locations != nil {
+		t.Errorf("result[2] = %+v", third)
+	}
+}
+
+func TestParseSeverity(t *testing.T) {
+	if s, err := ParseSeverity("Warning"); err != nil || s != SeverityWarning {
+		t.Errorf("ParseSeverity(Warning) = %v, %v", s, err)
+	}
+	if _, err := ParseSeverity("fatal"); err == nil {
+		t.Error("ParseSeverity(fatal) should fail")
+	}
+	if !SeverityError.AtLeast(SeverityWarning) || SeverityInfo.AtLeast(SeverityWarning) {
+		t.Error("AtLeast ordering is wrong")
+	}
+}
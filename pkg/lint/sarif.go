@@ -0,0 +1,131 @@
+package lint
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// SARIF 2.1.0 types, covering the subset code scanning tools read
+
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+)
+
+// SARIFLog is a SARIF log with a single run
+type SARIFLog struct {
+	Version string     `json:"version"`
+	Schema  string     `json:"$schema"`
+	Runs    []SARIFRun `json:"runs"`
+}
+
+type SARIFRun struct {
+	Tool    SARIFTool     `json:"tool"`
+	Results []SARIFResult `json:"results"`
+}
+
+type SARIFTool struct {
+	Driver SARIFDriver `json:"driver"`
+}
+
+type SARIFDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version,omitempty"`
+	InformationURI string      `json:"informationUri,omitempty"`
+	Rules          []SARIFRule `json:"rules"`
+}
+
+type SARIFRule struct {
+	ID               string       `json:"id"`
+	ShortDescription SARIFMessage `json:"shortDescription"`
+}
+
+type SARIFResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   SARIFMessage    `json:"message"`
+	Locations []SARIFLocation `json:"locations,omitempty"`
+}
+
+type SARIFMessage struct {
+	Text string `json:"text"`
+}
+
+type SARIFLocation struct {
+	PhysicalLocation SARIFPhysicalLocation `json:"physicalLocation"`
+}
+
+type SARIFPhysicalLocation struct {
+	ArtifactLocation SARIFArtifactLocation `json:"artifactLocation"`
+	Region           *SARIFRegion          `json:"region,omitempty"`
+}
+
+type SARIFArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type SARIFRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+}
+
+// SARIF converts issues into a SARIF log. File paths under baseDir are
+// made relative to it so code scanning can map them to the repository.
+func SARIF(issues []Issue, version, baseDir string) *SARIFLog {
+	driver := SARIFDriver{
+		Name:           "agentctl",
+		Version:        version,
+		InformationURI: "https://github.com/iheanyi/agentctl",
+	}
+	for _, rule := range Rules {
+		driver.Rules = append(driver.Rules, SARIFRule{ID: rule.ID, ShortDescription: SARIFMessage{Text: rule.Description}})
+	}
+
+	results := make([]SARIFResult, 0, len(issues))
+	for _, issue := range issues {
+		result := SARIFResult{
+			RuleID:  issue.Rule,
+			Level:   sarifLevel(issue.Severity),
+			Message: SARIFMessage{Text: issue.Message},
+		}
+		if issue.File != "" {
+			loc := SARIFPhysicalLocation{ArtifactLocation: SARIFArtifactLocation{URI: sarifURI(issue.File, baseDir)}}
+			if issue.Line > 0 {
+				loc.Region = &SARIFRegion{StartLine: issue.Line, StartColumn: issue.Column}
+			}
+			result.Locations = []SARIFLocation{{PhysicalLocation: loc}}
+		}
+		results = append(results, result)
+	}
+
+	return &SARIFLog{
+		Version: sarifVersion,
+		Schema:  sarifSchema,
+		Runs:    []SARIFRun{{Tool: SARIFTool{Driver: driver}, Results: results}},
+	}
+}
+
+// sarifLevel maps a severity to a SARIF result level
+func sarifLevel(s Severity) string {
+	switch s {
+	case SeverityError:
+		return "error"
+	case SeverityWarning:
+		return "warning"
+	default:
+		return "note"
+	}
+}
+
+// sarifURI returns a file's URI, relative to baseDir when inside it
+func sarifURI(file, baseDir string) string {
+	if baseDir != "" {
+		if rel, err := filepath.Rel(baseDir, file); err == nil && !strings.HasPrefix(rel, "..") {
+			return filepath.ToSlash(rel)
+		}
+	}
+	if filepath.IsAbs(file) {
+		return "file://" + filepath.ToSlash(file)
+	}
+	return filepath.ToSlash(file)
+}
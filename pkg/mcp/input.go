@@ -0,0 +1,52 @@
+package mcp
+
+import "strings"
+
+// Input targets: where an input's value is written
+const (
+	InputTargetEnv    = "env"    // Environment variable (stdio servers)
+	InputTargetHeader = "header" // HTTP header (http/sse servers)
+)
+
+// Input declares a value the user supplies when installing a server, such
+// as an API key or workspace ID
+type Input struct {
+	Name        string `json:"name"` // Env var or header name
+	Description string `json:"description,omitempty"`
+	Secret      bool   `json:"secret,omitempty"`   // Stored in the keychain, prompted without echo
+	Default     string `json:"default,omitempty"`  // Pre-filled value
+	Optional    bool   `json:"optional,omitempty"` // May be left empty
+	Target      string `json:"target,omitempty"`   // "env" (default) or "header"
+	Format      string `json:"format,omitempty"`   // Value template, e.g. "Bearer {value}"
+}
+
+// TargetOrDefault returns where the input is written, defaulting to env
+func (in Input) TargetOrDefault() string {
+	if in.Target == "" {
+		return InputTargetEnv
+	}
+	return in.Target
+}
+
+// Apply renders value through the input's format
+func (in Input) Apply(value string) string {
+	if in.Format == "" {
+		return value
+	}
+	return strings.ReplaceAll(in.Format, "{value}", value)
+}
+
+// SetInput writes a final value to the env var or header the input targets
+func (s *Server) SetInput(in Input, value string) {
+	if in.TargetOrDefault() == InputTargetHeader {
+		if s.Headers == nil {
+			s.Headers = make(map[string]string)
+		}
+		s.Headers[in.Name] = value
+		return
+	}
+	if s.Env == nil {
+		s.Env = make(map[string]string)
+	}
+	s.Env[in.Name] = value
+}
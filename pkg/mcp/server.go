@@ -3,6 +3,8 @@ package mcp
 import (
 	"fmt"
 	"strings"
+
+	"github.com/iheanyi/agentctl/pkg/condition"
 )
 
 // Transport represents the MCP transport protocol
@@ -16,8 +18,8 @@ const (
 
 // Source represents where an MCP server comes from
 type Source struct {
-	Type  string `json:"type"`            // "git", "alias", "local"
-	URL   string `json:"url,omitempty"`   // Git URL or local path
+	Type  string `json:"type"`            // "git", "alias", "local", "oci"
+	URL   string `json:"url,omitempty"`   // Git URL, local path or image reference
 	Ref   string `json:"ref,omitempty"`   // Tag, branch, or commit
 	Alias string `json:"alias,omitempty"` // Short name alias
 }
@@ -29,6 +31,17 @@ type BuildConfig struct {
 	WorkDir string `json:"workdir,omitempty"` // Working directory for build
 }
 
+// Container runs a server as a stdio process in a container. Args of the
+// server are passed to the image's entrypoint, and its env vars are passed
+// through by name.
+type Container struct {
+	Image   string   `json:"image,omitempty"`                                   // Image to run; built from the Dockerfile of git sources
+	Runtime string   `json:"runtime,omitempty" jsonschema:"enum=docker|podman"` // Container runtime; docker if installed, else podman
+	Volumes []string `json:"volumes,omitempty"`                                 // Mounts as host:container[:ro]
+	Network string   `json:"network,omitempty"`                                 // Network mode, e.g. none or host; the runtime's default if empty
+	Args    []string `json:"args,omitempty"`                                    // Extra arguments to 'run'
+}
+
 // Server represents an MCP server configuration
 type Server struct {
 	Name      string            `json:"name"`
@@ -38,13 +51,29 @@ type Server struct {
 	URL       string            `json:"url,omitempty"`     // For remote servers (http/sse)
 	Headers   map[string]string `json:"headers,omitempty"` // For remote servers (http/sse)
 	Env       map[string]string `json:"env,omitempty"`
-	Transport Transport         `json:"transport,omitempty"`
+	EnvFile   string            `json:"envFile,omitempty"` // File of extra env vars, for tools that support it (e.g. VS Code)
+	Transport Transport         `json:"transport,omitempty" jsonschema:"enum=stdio|sse|http"`
 	Namespace string            `json:"namespace,omitempty"` // For conflict resolution
 	Build     *BuildConfig      `json:"build,omitempty"`
+	Container *Container        `json:"container,omitempty"` // Run in a container instead of with command
 	Disabled  bool              `json:"disabled,omitempty"`
 
+	// Timeouts in seconds, for tools that support them (e.g. Codex)
+	StartupTimeout int `json:"startupTimeout,omitempty"` // Time allowed for the server to start
+	ToolTimeout    int `json:"toolTimeout,omitempty"`    // Time allowed for each tool call
+
+	// EnabledTools limits the server to the matching tools and
+	// DisabledTools hides the matching ones. Entries are tool names or
+	// globs (e.g. "get_*"), and a tool must pass both lists.
+	EnabledTools  []string `json:"enabledTools,omitempty"`
+	DisabledTools []string `json:"disabledTools,omitempty"`
+
+	// When limits the server to environments where the condition holds
+	When *condition.Condition `json:"when,omitempty"`
+
 	// Runtime fields (not serialized to JSON)
-	Scope string `json:"-"` // "local" or "global" - where this server came from
+	Scope    string `json:"-"` // "local" or "global" - where this server came from
+	Excluded string `json:"-"` // Why the server's condition doesn't hold here, if it doesn't
 }
 
 // InspectTitle returns the display name for the inspector modal header
@@ -62,6 +91,8 @@ func (s *Server) InspectContent() string {
 
 	if s.Disabled {
 		b.WriteString("Status:    DISABLED\n")
+	} else if s.Excluded != "" {
+		b.WriteString(fmt.Sprintf("Status:    EXCLUDED (%s)\n", s.Excluded))
 	}
 	b.WriteString("\n")
 
@@ -121,6 +152,34 @@ func (s *Server) InspectContent() string {
 		}
 	}
 
+	// Tool filters
+	if s.FiltersTools() {
+		b.WriteString("\nTools:\n")
+		if len(s.EnabledTools) > 0 {
+			b.WriteString(fmt.Sprintf("  Enabled:  %s\n", strings.Join(s.EnabledTools, ", ")))
+		}
+		if len(s.DisabledTools) > 0 {
+			b.WriteString(fmt.Sprintf("  Disabled: %s\n", strings.Join(s.DisabledTools, ", ")))
+		}
+	}
+
+	// Container
+	if c := s.Container; c != nil {
+		b.WriteString("\nContainer:\n")
+		if c.Image != "" {
+			b.WriteString(fmt.Sprintf("  Image:   %s\n", c.Image))
+		}
+		if c.Runtime != "" {
+			b.WriteString(fmt.Sprintf("  Runtime: %s\n", c.Runtime))
+		}
+		if c.Network != "" {
+			b.WriteString(fmt.Sprintf("  Network: %s\n", c.Network))
+		}
+		for _, v := range c.Volumes {
+			b.WriteString(fmt.Sprintf("  Volume:  %s\n", v))
+		}
+	}
+
 	// Build config
 	if s.Build != nil {
 		b.WriteString("\nBuild:\n")
@@ -2,6 +2,7 @@ package mcp
 
 import (
 	"encoding/json"
+	"reflect"
 	"testing"
 )
 
@@ -162,3 +163,57 @@ func TestServerDisabled(t *testing.T) {
 		t.Error("Disabled flag should be true")
 	}
 }
+
+func TestToolEnabled(t *testing.T) {
+	s := &Server{EnabledTools: []string{"get_*", "search"}, DisabledTools: []string{"get_secret"}}
+	tests := map[string]bool{
+		"get_issue":  true,
+		"search":     true,
+		"get_secret": false,
+		"delete":     false,
+	}
+	for name, want := range tests {
+		if got := s.ToolEnabled(name); got != want {
+			t.Errorf("ToolEnabled(%q) = %v, want %v", name, got, want)
+		}
+	}
+	if (&Server{}).FiltersTools() || !s.FiltersTools() {
+		t.Error("FiltersTools() wrong")
+	}
+}
+
+func TestSetToolEnabled(t *testing.T) {
+	tools := []string{"read", "write", "delete", "debug_dump", "debug_trace"}
+
+	s := &Server{}
+	s.SetToolEnabled("delete", false, tools)
+	if !reflect.DeepEqual(s.DisabledTools, []string{"delete"}) || s.ToolEnabled("delete") {
+		t.Errorf("disabling delete: %+v", s)
+	}
+	s.SetToolEnabled("delete", true, tools)
+	if s.FiltersTools() {
+		t.Errorf("re-enabling delete left filters: %+v", s)
+	}
+
+	// Enabling a tool hidden by a glob replaces the glob with the rest of
+	// its matches
+	s = &Server{DisabledTools: []string{"debug_*"}}
+	s.SetToolEnabled("debug_trace", true, tools)
+	if !reflect.DeepEqual(s.DisabledTools, []string{"debug_dump"}) {
+		t.Errorf("DisabledTools = %v, want [debug_dump]", s.DisabledTools)
+	}
+
+	// With an allowlist, tools are added to and dropped from it
+	s = &Server{EnabledTools: []string{"read", "write"}}
+	s.SetToolEnabled("delete", true, tools)
+	s.SetToolEnabled("write", false, tools)
+	if !reflect.DeepEqual(s.EnabledTools, []string{"read", "delete"}) || len(s.DisabledTools) != 0 {
+		t.Errorf("allowlist edits: %+v", s)
+	}
+	// Dropping the last entry would enable everything, so it's disabled
+	s = &Server{EnabledTools: []string{"read"}}
+	s.SetToolEnabled("read", false, tools)
+	if s.ToolEnabled("read") || s.ToolEnabled("write") {
+		t.Errorf("disabling the only enabled tool: %+v", s)
+	}
+}
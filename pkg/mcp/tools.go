@@ -0,0 +1,82 @@
+package mcp
+
+import (
+	"path"
+	"slices"
+	"strings"
+)
+
+// FiltersTools reports whether the server hides any of its tools
+func (s *Server) FiltersTools() bool {
+	return len(s.EnabledTools) > 0 || len(s.DisabledTools) > 0
+}
+
+// ToolEnabled reports whether a tool passes the server's enabled and
+// disabled lists
+func (s *Server) ToolEnabled(name string) bool {
+	if len(s.EnabledTools) > 0 && !MatchTool(s.EnabledTools, name) {
+		return false
+	}
+	return !MatchTool(s.DisabledTools, name)
+}
+
+// SetToolEnabled turns one tool on or off, editing the lists as little as
+// possible. tools is the server's current tool list, used to expand a glob
+// in DisabledTools that would otherwise keep the tool off.
+func (s *Server) SetToolEnabled(name string, enabled bool, tools []string) {
+	if !enabled {
+		if !s.ToolEnabled(name) {
+			return
+		}
+		// Dropping the tool from the enabled list is enough unless that
+		// would leave the list empty, which enables everything
+		if i := slices.Index(s.EnabledTools, name); i >= 0 && len(s.EnabledTools) > 1 {
+			s.EnabledTools = slices.Delete(s.EnabledTools, i, i+1)
+			if !s.ToolEnabled(name) {
+				return
+			}
+		}
+		s.DisabledTools = append(s.DisabledTools, name)
+		return
+	}
+
+	var disabled []string
+	for _, pattern := range s.DisabledTools {
+		switch {
+		case pattern == name:
+		case IsToolGlob(pattern) && matchTool(pattern, name):
+			// Replace the glob with the other tools it matched
+			for _, tool := range tools {
+				if tool != name && matchTool(pattern, tool) && !slices.Contains(disabled, tool) {
+					disabled = append(disabled, tool)
+				}
+			}
+		default:
+			disabled = append(disabled, pattern)
+		}
+	}
+	s.DisabledTools = disabled
+	if len(s.EnabledTools) > 0 && !MatchTool(s.EnabledTools, name) {
+		s.EnabledTools = append(s.EnabledTools, name)
+	}
+}
+
+// MatchTool reports whether a tool name matches any of patterns
+func MatchTool(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if matchTool(pattern, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchTool(pattern, name string) bool {
+	ok, _ := path.Match(pattern, name)
+	return ok
+}
+
+// IsToolGlob reports whether a tool pattern is a glob rather than a name
+func IsToolGlob(pattern string) bool {
+	return strings.ContainsAny(pattern, "*?[")
+}
@@ -4,11 +4,13 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"os"
 	"os/exec"
 	"time"
 
 	mcpsdk "github.com/modelcontextprotocol/go-sdk/mcp"
 
+	"github.com/iheanyi/agentctl/pkg/container"
 	"github.com/iheanyi/agentctl/pkg/mcp"
 )
 
@@ -202,19 +204,53 @@ func (c *Client) CallTool(ctx context.Context, server *mcp.Server, toolName stri
 	return result
 }
 
+// Connect starts or connects to server and returns an initialized session
+// that stays open until it's closed or the server goes away. The client's
+// timeout bounds the handshake only.
+func (c *Client) Connect(ctx context.Context, server *mcp.Server, opts *mcpsdk.ClientOptions) (*mcpsdk.ClientSession, error) {
+	var transport mcpsdk.Transport
+	var err error
+	if server.Transport == mcp.TransportHTTP || server.Transport == mcp.TransportSSE {
+		// No request timeout, which would cut off streamed responses
+		transport, err = remoteTransport(server, 0)
+		transport = detachedTransport{transport}
+	} else {
+		transport, err = c.createTransport(server)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create transport: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	client := mcpsdk.NewClient(&mcpsdk.Implementation{
+		Name:    "agentctl",
+		Version: "1.0.0",
+	}, opts)
+	session, err := client.Connect(ctx, transport, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect: %w", err)
+	}
+	return session, nil
+}
+
+// detachedTransport connects without the caller's deadline, which would
+// otherwise end an SSE stream once the handshake's timeout passed
+type detachedTransport struct {
+	mcpsdk.Transport
+}
+
+func (t detachedTransport) Connect(ctx context.Context) (mcpsdk.Connection, error) {
+	return t.Transport.Connect(context.WithoutCancel(ctx))
+}
+
 // createTransport creates the appropriate transport based on server config
 func (c *Client) createTransport(server *mcp.Server) (mcpsdk.Transport, error) {
+	server = container.Command(server)
 	switch server.Transport {
 	case mcp.TransportHTTP, mcp.TransportSSE:
-		if server.URL == "" {
-			return nil, fmt.Errorf("HTTP/SSE server requires URL")
-		}
-		return &mcpsdk.StreamableClientTransport{
-			Endpoint: server.URL,
-			HTTPClient: &http.Client{
-				Timeout: c.timeout,
-			},
-		}, nil
+		return remoteTransport(server, c.timeout)
 
 	case mcp.TransportStdio, "":
 		if server.Command == "" {
@@ -222,8 +258,9 @@ func (c *Client) createTransport(server *mcp.Server) (mcpsdk.Transport, error) {
 		}
 		cmd := exec.Command(server.Command, server.Args...)
 
-		// Set environment variables
+		// Set environment variables on top of our own
 		if len(server.Env) > 0 {
+			cmd.Env = os.Environ()
 			for k, v := range server.Env {
 				cmd.Env = append(cmd.Env, fmt.Sprintf("%s=%s", k, v))
 			}
@@ -237,3 +274,44 @@ func (c *Client) createTransport(server *mcp.Server) (mcpsdk.Transport, error) {
 		return nil, fmt.Errorf("unsupported transport: %s", server.Transport)
 	}
 }
+
+// remoteTransport creates the transport for an HTTP or SSE server, sending
+// the server's headers with every request. A zero timeout leaves requests
+// unbounded, which long-lived SSE streams need.
+func remoteTransport(server *mcp.Server, timeout time.Duration) (mcpsdk.Transport, error) {
+	if server.URL == "" {
+		return nil, fmt.Errorf("HTTP/SSE server requires URL")
+	}
+
+	httpClient := &http.Client{
+		Timeout:   timeout,
+		Transport: &headerTransport{headers: server.Headers, base: http.DefaultTransport},
+	}
+	if server.Transport == mcp.TransportSSE {
+		return &mcpsdk.SSEClientTransport{
+			Endpoint:   server.URL,
+			HTTPClient: httpClient,
+		}, nil
+	}
+	return &mcpsdk.StreamableClientTransport{
+		Endpoint:   server.URL,
+		HTTPClient: httpClient,
+	}, nil
+}
+
+// headerTransport adds fixed headers to every request
+type headerTransport struct {
+	headers map[string]string
+	base    http.RoundTripper
+}
+
+func (t *headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if len(t.headers) == 0 {
+		return t.base.RoundTrip(req)
+	}
+	req = req.Clone(req.Context())
+	for k, v := range t.headers {
+		req.Header.Set(k, v)
+	}
+	return t.base.RoundTrip(req)
+}
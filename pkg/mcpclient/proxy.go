@@ -0,0 +1,71 @@
+package mcpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	mcpsdk "github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/iheanyi/agentctl/pkg/mcp"
+)
+
+// Proxy relays MCP messages between local, usually stdio, and the remote
+// (HTTP or SSE) server until either side closes or ctx is cancelled.
+// Messages are passed through as they are, so every method, notification
+// and server request works without the proxy knowing about it.
+func Proxy(ctx context.Context, server *mcp.Server, local mcpsdk.Transport) error {
+	if server.Transport != mcp.TransportHTTP && server.Transport != mcp.TransportSSE {
+		return fmt.Errorf("server %q is not an HTTP or SSE server", server.Name)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	transport, err := remoteTransport(server, 0)
+	if err != nil {
+		return fmt.Errorf("failed to create transport: %w", err)
+	}
+	remoteConn, err := transport.Connect(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to connect: %w", err)
+	}
+	defer remoteConn.Close()
+
+	localConn, err := local.Connect(ctx)
+	if err != nil {
+		return err
+	}
+	defer localConn.Close()
+
+	errc := make(chan error, 2)
+	go func() { errc <- relay(ctx, localConn, remoteConn) }()
+	go func() { errc <- relay(ctx, remoteConn, localConn) }()
+
+	// The first side to finish ends the session
+	err = <-errc
+	if errors.Is(err, io.EOF) || errors.Is(err, context.Canceled) {
+		return nil
+	}
+	return err
+}
+
+// ProxyStdio relays between this process's stdin and stdout and the
+// remote server
+func ProxyStdio(ctx context.Context, server *mcp.Server) error {
+	return Proxy(ctx, server, &mcpsdk.StdioTransport{})
+}
+
+// relay copies messages from one connection to another
+func relay(ctx context.Context, from, to mcpsdk.Connection) error {
+	for {
+		msg, err := from.Read(ctx)
+		if err != nil {
+			return err
+		}
+		if err := to.Write(ctx, msg); err != nil {
+			return err
+		}
+	}
+}
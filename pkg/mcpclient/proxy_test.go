@@ -0,0 +1,71 @@
+package mcpclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	mcpsdk "github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/iheanyi/agentctl/pkg/mcp"
+)
+
+func TestProxy(t *testing.T) {
+	remote := mcpsdk.NewServer(&mcpsdk.Implementation{Name: "remote", Version: "1.0.0"}, nil)
+	mcpsdk.AddTool(remote, &mcpsdk.Tool{Name: "greet"}, func(ctx context.Context, req *mcpsdk.CallToolRequest, args struct{}) (*mcpsdk.CallToolResult, any, error) {
+		return &mcpsdk.CallToolResult{Content: []mcpsdk.Content{&mcpsdk.TextContent{Text: "hello"}}}, nil, nil
+	})
+
+	var authorization string
+	handler := mcpsdk.NewStreamableHTTPHandler(func(*http.Request) *mcpsdk.Server { return remote }, nil)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		handler.ServeHTTP(w, r)
+	}))
+	defer ts.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	clientTransport, proxyTransport := mcpsdk.NewInMemoryTransports()
+	server := &mcp.Server{
+		Name:      "remote",
+		Transport: mcp.TransportHTTP,
+		URL:       ts.URL,
+		Headers:   map[string]string{"Authorization": "Bearer token"},
+	}
+	done := make(chan error, 1)
+	go func() { done <- Proxy(ctx, server, proxyTransport) }()
+
+	client := mcpsdk.NewClient(&mcpsdk.Implementation{Name: "test", Version: "1.0.0"}, nil)
+	session, err := client.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+
+	result, err := session.CallTool(ctx, &mcpsdk.CallToolParams{Name: "greet"})
+	if err != nil {
+		t.Fatalf("CallTool failed: %v", err)
+	}
+	if text, ok := result.Content[0].(*mcpsdk.TextContent); !ok || text.Text != "hello" {
+		t.Errorf("CallTool() content = %+v", result.Content)
+	}
+	if authorization != "Bearer token" {
+		t.Errorf("Authorization header = %q, want %q", authorization, "Bearer token")
+	}
+
+	// Closing the client ends the proxy cleanly
+	session.Close()
+	if err := <-done; err != nil {
+		t.Errorf("Proxy() = %v", err)
+	}
+}
+
+func TestProxyRejectsStdio(t *testing.T) {
+	_, local := mcpsdk.NewInMemoryTransports()
+	if err := Proxy(context.Background(), &mcp.Server{Name: "fs", Command: "fs-mcp"}, local); err == nil {
+		t.Error("Proxy() should reject stdio servers")
+	}
+}
@@ -59,13 +59,24 @@ func (w *JSONWriter) WriteErrorString(errMsg string) error {
 
 // ListOutput represents the JSON output for the list command
 type ListOutput struct {
-	ProjectPath string        `json:"projectPath,omitempty"`
-	Servers     []ServerInfo  `json:"servers,omitempty"`
-	Commands    []CommandInfo `json:"commands,omitempty"`
-	Rules       []RuleInfo    `json:"rules,omitempty"`
-	Skills      []SkillInfo   `json:"skills,omitempty"`
-	Plugins     []PluginInfo  `json:"plugins,omitempty"`
-	Agents      []AgentInfo   `json:"agents,omitempty"`
+	ProjectPath string         `json:"projectPath,omitempty"`
+	Servers     []ServerInfo   `json:"servers,omitempty"`
+	Commands    []CommandInfo  `json:"commands,omitempty"`
+	Rules       []RuleInfo     `json:"rules,omitempty"`
+	Skills      []SkillInfo    `json:"skills,omitempty"`
+	Plugins     []PluginInfo   `json:"plugins,omitempty"`
+	Agents      []AgentInfo    `json:"agents,omitempty"`
+	ExecRules   []ExecRuleInfo `json:"execRules,omitempty"`
+	Excluded    []ExcludedInfo `json:"excluded,omitempty"`
+}
+
+// ExcludedInfo describes a server or resource whose "when" condition
+// doesn't hold in this environment
+type ExcludedInfo struct {
+	Name   string `json:"name"`
+	Type   string `json:"type"`
+	Scope  string `json:"scope"`
+	Reason string `json:"reason"`
 }
 
 // ServerInfo represents server information in JSON output
@@ -113,6 +124,31 @@ type PluginInfo struct {
 	Path    string `json:"path,omitempty"`
 }
 
+// PluginListOutput represents the JSON output for the plugin list command
+type PluginListOutput struct {
+	Plugins      []PluginInfo      `json:"plugins"`
+	Marketplaces []MarketplaceInfo `json:"marketplaces,omitempty"`
+}
+
+// MarketplaceInfo represents a plugin marketplace in JSON output
+type MarketplaceInfo struct {
+	Name     string `json:"name"`
+	Source   string `json:"source"`
+	Location string `json:"location"`
+}
+
+// SourceInfo represents a team alias/resource source in JSON output
+type SourceInfo struct {
+	Name      string `json:"name"`
+	Type      string `json:"type"`
+	URL       string `json:"url"`
+	Ref       string `json:"ref,omitempty"`
+	Priority  int    `json:"priority"`
+	Commit    string `json:"commit,omitempty"`
+	ETag      string `json:"etag,omitempty"`
+	FetchedAt string `json:"fetchedAt,omitempty"`
+}
+
 // AgentInfo represents agent information in JSON output
 type AgentInfo struct {
 	Name        string `json:"name"`
@@ -123,26 +159,52 @@ type AgentInfo struct {
 	Path        string `json:"path,omitempty"`
 }
 
+// ExecRuleInfo represents a command execution rule in JSON output
+type ExecRuleInfo struct {
+	Pattern       string `json:"pattern"`
+	Scope         string `json:"scope"`
+	Tool          string `json:"tool"`
+	Decision      string `json:"decision"`
+	Justification string `json:"justification,omitempty"`
+	Path          string `json:"path,omitempty"`
+}
+
 // SyncOutput represents the JSON output for the sync command
 type SyncOutput struct {
 	DryRun      bool             `json:"dryRun"`
 	ProjectPath string           `json:"projectPath,omitempty"`
+	SnapshotID  string           `json:"snapshotId,omitempty"`
 	ToolResults []SyncToolResult `json:"toolResults"`
 	Summary     SyncSummary      `json:"summary"`
 }
 
 // SyncToolResult represents the sync result for a single tool
 type SyncToolResult struct {
-	Tool           string       `json:"tool"`
-	ConfigPath     string       `json:"configPath"`
-	Success        bool         `json:"success"`
-	Error          string       `json:"error,omitempty"`
-	ServersAdded   int          `json:"serversAdded,omitempty"`
-	ServersUpdated int          `json:"serversUpdated,omitempty"`
-	ServersRemoved int          `json:"serversRemoved,omitempty"`
-	CommandsSynced int          `json:"commandsSynced,omitempty"`
-	RulesSynced    int          `json:"rulesSynced,omitempty"`
-	Changes        []SyncChange `json:"changes,omitempty"`
+	Tool              string       `json:"tool"`
+	ConfigPath        string       `json:"configPath"`
+	Success           bool         `json:"success"`
+	Error             string       `json:"error,omitempty"`
+	ServersAdded      int          `json:"serversAdded,omitempty"`
+	ServersUpdated    int          `json:"serversUpdated,omitempty"`
+	ServersRemoved    int          `json:"serversRemoved,omitempty"`
+	CommandsSynced    int          `json:"commandsSynced,omitempty"`
+	RulesSynced       int          `json:"rulesSynced,omitempty"`
+	SkillsSynced      int          `json:"skillsSynced,omitempty"`
+	AgentsSynced      int          `json:"agentsSynced,omitempty"`
+	PermissionsSynced int          `json:"permissionsSynced,omitempty"`
+	PluginsSynced     int          `json:"pluginsSynced,omitempty"`
+	Bridged           []string     `json:"bridged,omitempty"`            // Remote servers written as 'agentctl mcp-proxy' entries
+	Skipped           []string     `json:"skipped,omitempty"`            // Remote servers the tool can't connect to
+	SkippedRules      []string     `json:"skippedPermissions,omitempty"` // Permission patterns the tool can't express exactly
+	SkippedFilters    []string     `json:"skippedToolFilters,omitempty"` // Server tool filters the tool can't match, as server:pattern
+	Changes           []SyncChange `json:"changes,omitempty"`
+	Files             []SyncFile   `json:"files,omitempty"` // Files a dry run would change
+}
+
+// SyncFile is a file a sync would create, update or remove
+type SyncFile struct {
+	Path   string `json:"path"`
+	Change string `json:"change"` // "create", "update", "remove"
 }
 
 // SyncChange represents a single change during sync
@@ -202,7 +264,7 @@ type DoctorToolResult struct {
 // DoctorServerResult represents an MCP server check result
 type DoctorServerResult struct {
 	Name      string `json:"name"`
-	Type      string `json:"type"` // "stdio" or "http"
+	Type      string `json:"type"` // "stdio", "http" or "container"
 	Disabled  bool   `json:"disabled"`
 	Available bool   `json:"available"`
 	Error     string `json:"error,omitempty"`
@@ -228,9 +290,21 @@ type DoctorSystemInfo struct {
 // ValidateOutput represents the JSON output for the validate command
 type ValidateOutput struct {
 	Results []ValidateToolResult `json:"results"`
+	Issues  []ValidateIssue      `json:"issues"`
 	Summary ValidateSummary      `json:"summary"`
 }
 
+// ValidateIssue represents a lint issue in agentctl's config or resources
+type ValidateIssue struct {
+	Rule     string `json:"rule"`
+	Severity string `json:"severity"` // error, warning or info
+	Message  string `json:"message"`
+	Resource string `json:"resource,omitempty"`
+	File     string `json:"file,omitempty"`
+	Line     int    `json:"line,omitempty"`
+	Column   int    `json:"column,omitempty"`
+}
+
 // ValidateToolResult represents the validation result for a single tool
 type ValidateToolResult struct {
 	Tool        string   `json:"tool"`
@@ -246,6 +320,9 @@ type ValidateSummary struct {
 	TotalTools   int `json:"totalTools"`
 	ValidTools   int `json:"validTools"`
 	InvalidTools int `json:"invalidTools"`
+	Errors       int `json:"errors"` // Lint issues by severity
+	Warnings     int `json:"warnings"`
+	Infos        int `json:"infos"`
 }
 
 // PrintJSON is a helper function to print any data as JSON
@@ -0,0 +1,149 @@
+// Package permission models tool permission policies (allow/deny/ask) that
+// agentctl syncs to each coding assistant's native permission system.
+package permission
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/iheanyi/agentctl/pkg/toolname"
+)
+
+// Decision is the action taken when a permission pattern matches
+type Decision string
+
+const (
+	DecisionAllow Decision = "allow" // Run without asking
+	DecisionDeny  Decision = "deny"  // Never run
+	DecisionAsk   Decision = "ask"   // Prompt the user first
+)
+
+// Permissions holds allow/deny/ask patterns in agentctl's canonical tool
+// vocabulary. Patterns are a tool name with an optional specifier, e.g.
+// "read", "bash(git push --force:*)" or "mcp__github__delete_repo".
+type Permissions struct {
+	Allow []string `json:"allow,omitempty"`
+	Deny  []string `json:"deny,omitempty"`
+	Ask   []string `json:"ask,omitempty"`
+}
+
+// Rule is a single pattern with its decision
+type Rule struct {
+	Decision Decision
+	Pattern  string
+}
+
+// Tool returns the canonical tool name the rule applies to
+func (r Rule) Tool() string {
+	tool, _ := SplitPattern(r.Pattern)
+	return tool
+}
+
+// Specifier returns the rule's argument specifier (e.g., "git push:*")
+func (r Rule) Specifier() string {
+	_, spec := SplitPattern(r.Pattern)
+	return spec
+}
+
+// IsEmpty returns true if no patterns are configured
+func (p *Permissions) IsEmpty() bool {
+	return p == nil || (len(p.Allow) == 0 && len(p.Deny) == 0 && len(p.Ask) == 0)
+}
+
+// Rules returns all patterns as rules, deny first, then ask, then allow
+func (p *Permissions) Rules() []Rule {
+	if p == nil {
+		return nil
+	}
+	var rules []Rule
+	for _, pattern := range p.Deny {
+		rules = append(rules, Rule{Decision: DecisionDeny, Pattern: pattern})
+	}
+	for _, pattern := range p.Ask {
+		rules = append(rules, Rule{Decision: DecisionAsk, Pattern: pattern})
+	}
+	for _, pattern := range p.Allow {
+		rules = append(rules, Rule{Decision: DecisionAllow, Pattern: pattern})
+	}
+	return rules
+}
+
+// Add adds a pattern under the given decision, skipping duplicates
+func (p *Permissions) Add(decision Decision, pattern string) {
+	switch decision {
+	case DecisionAllow:
+		p.Allow = appendUnique(p.Allow, pattern)
+	case DecisionDeny:
+		p.Deny = appendUnique(p.Deny, pattern)
+	case DecisionAsk:
+		p.Ask = appendUnique(p.Ask, pattern)
+	}
+}
+
+// Merge returns the union of two permission sets (other's patterns appended)
+func (p *Permissions) Merge(other *Permissions) *Permissions {
+	if p.IsEmpty() && other.IsEmpty() {
+		return nil
+	}
+	merged := &Permissions{}
+	for _, src := range []*Permissions{p, other} {
+		for _, r := range src.Rules() {
+			merged.Add(r.Decision, r.Pattern)
+		}
+	}
+	return merged
+}
+
+// Validate checks that every pattern is well-formed
+func (p *Permissions) Validate() error {
+	for _, r := range p.Rules() {
+		if strings.TrimSpace(r.Pattern) == "" {
+			return fmt.Errorf("empty %s pattern", r.Decision)
+		}
+		if strings.Contains(r.Pattern, "(") && !strings.HasSuffix(r.Pattern, ")") {
+			return fmt.Errorf("invalid %s pattern %q: unclosed specifier", r.Decision, r.Pattern)
+		}
+	}
+	return nil
+}
+
+// SplitPattern splits "bash(git push:*)" into its canonical tool name
+// ("bash") and specifier ("git push:*")
+func SplitPattern(pattern string) (tool, specifier string) {
+	if i := strings.Index(pattern, "("); i > 0 && strings.HasSuffix(pattern, ")") {
+		return toolname.Canonicalize(pattern[:i]), pattern[i+1 : len(pattern)-1]
+	}
+	return toolname.Canonicalize(pattern), ""
+}
+
+// FormatPattern joins a tool name and specifier into a pattern
+func FormatPattern(tool, specifier string) string {
+	if specifier == "" {
+		return tool
+	}
+	return tool + "(" + specifier + ")"
+}
+
+// CommandPrefix converts a bash specifier into the command words it matches.
+// "git push --force:*" yields ["git", "push", "--force"] and true for prefix
+// matching; a specifier without ":*" matches the exact command.
+func CommandPrefix(specifier string) (words []string, prefix bool) {
+	spec := strings.TrimSpace(specifier)
+	if trimmed, ok := strings.CutSuffix(spec, ":*"); ok {
+		spec = trimmed
+		prefix = true
+	} else if trimmed, ok := strings.CutSuffix(spec, " *"); ok {
+		spec = trimmed
+		prefix = true
+	}
+	return strings.Fields(spec), prefix
+}
+
+func appendUnique(list []string, item string) []string {
+	for _, existing := range list {
+		if existing == item {
+			return list
+		}
+	}
+	return append(list, item)
+}
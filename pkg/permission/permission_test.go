@@ -0,0 +1,97 @@
+package permission
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitPattern(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		wantTool string
+		wantSpec string
+	}{
+		{"read", "read", ""},
+		{"Bash(git push --force:*)", "bash", "git push --force:*"},
+		{"bash(npm test)", "bash", "npm test"},
+		{"mcp__github__delete_repo", "mcp__github__delete_repo", ""},
+	}
+
+	for _, tt := range tests {
+		tool, spec := SplitPattern(tt.pattern)
+		if tool != tt.wantTool || spec != tt.wantSpec {
+			t.Errorf("SplitPattern(%q) = %q, %q; want %q, %q", tt.pattern, tool, spec, tt.wantTool, tt.wantSpec)
+		}
+	}
+}
+
+func TestCommandPrefix(t *testing.T) {
+	tests := []struct {
+		spec       string
+		wantWords  []string
+		wantPrefix bool
+	}{
+		{"git push --force:*", []string{"git", "push", "--force"}, true},
+		{"rm -rf *", []string{"rm", "-rf"}, true},
+		{"npm test", []string{"npm", "test"}, false},
+	}
+
+	for _, tt := range tests {
+		words, prefix := CommandPrefix(tt.spec)
+		if !reflect.DeepEqual(words, tt.wantWords) || prefix != tt.wantPrefix {
+			t.Errorf("CommandPrefix(%q) = %v, %v; want %v, %v", tt.spec, words, prefix, tt.wantWords, tt.wantPrefix)
+		}
+	}
+}
+
+func TestRulesOrder(t *testing.T) {
+	p := &Permissions{
+		Allow: []string{"read"},
+		Deny:  []string{"bash(git push --force:*)"},
+		Ask:   []string{"edit"},
+	}
+
+	rules := p.Rules()
+	want := []Decision{DecisionDeny, DecisionAsk, DecisionAllow}
+	if len(rules) != len(want) {
+		t.Fatalf("Rules() returned %d rules, want %d", len(rules), len(want))
+	}
+	for i, r := range rules {
+		if r.Decision != want[i] {
+			t.Errorf("rule %d decision = %q, want %q", i, r.Decision, want[i])
+		}
+	}
+}
+
+func TestMerge(t *testing.T) {
+	global := &Permissions{Deny: []string{"bash(git push --force:*)"}}
+	project := &Permissions{
+		Deny:  []string{"bash(git push --force:*)", "bash(rm -rf:*)"},
+		Allow: []string{"bash(npm test:*)"},
+	}
+
+	merged := global.Merge(project)
+	if !reflect.DeepEqual(merged.Deny, []string{"bash(git push --force:*)", "bash(rm -rf:*)"}) {
+		t.Errorf("merged Deny = %v", merged.Deny)
+	}
+	if !reflect.DeepEqual(merged.Allow, []string{"bash(npm test:*)"}) {
+		t.Errorf("merged Allow = %v", merged.Allow)
+	}
+
+	var empty *Permissions
+	if empty.Merge(nil) != nil {
+		t.Error("merging two empty sets should return nil")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	if err := (&Permissions{Allow: []string{"read"}}).Validate(); err != nil {
+		t.Errorf("Validate() unexpected error: %v", err)
+	}
+	if err := (&Permissions{Deny: []string{""}}).Validate(); err == nil {
+		t.Error("Validate() should reject empty patterns")
+	}
+	if err := (&Permissions{Ask: []string{"bash(git push"}}).Validate(); err == nil {
+		t.Error("Validate() should reject unclosed specifiers")
+	}
+}
@@ -0,0 +1,181 @@
+// Package plugin models the tool plugins and plugin marketplaces agentctl
+// keeps consistent across tools. Claude Code plugins come from marketplaces
+// ("name@marketplace"); OpenCode plugins are npm packages.
+package plugin
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// NPMPrefix marks an npm package plugin spec on the command line
+const NPMPrefix = "npm:"
+
+// Plugin is a plugin that should be installed in a tool
+type Plugin struct {
+	Name        string `json:"name"`
+	Marketplace string `json:"marketplace,omitempty"` // Claude Code marketplace; empty for npm plugins
+	Version     string `json:"version,omitempty"`     // Pinned version (empty = latest)
+	Disabled    bool   `json:"disabled,omitempty"`
+}
+
+// ID returns the identifier tools use for the plugin: "name@marketplace"
+// for marketplace plugins, the package name for npm plugins
+func (p *Plugin) ID() string {
+	if p.Marketplace != "" {
+		return p.Name + "@" + p.Marketplace
+	}
+	return p.Name
+}
+
+// IsNPM returns true for npm package plugins (OpenCode)
+func (p *Plugin) IsNPM() bool {
+	return p.Marketplace == ""
+}
+
+// Validate checks that the plugin has the fields a tool needs
+func (p *Plugin) Validate() error {
+	if p.Name == "" {
+		return fmt.Errorf("plugin name is required")
+	}
+	if p.Marketplace != "" && strings.ContainsAny(p.Name, "@/ ") {
+		return fmt.Errorf("invalid plugin name %q", p.Name)
+	}
+	return nil
+}
+
+// Parse parses a plugin spec. "name@marketplace" is a marketplace plugin;
+// "npm:package" or "npm:package@version" (including scoped packages such
+// as "npm:@org/pkg@1.0.0") is an npm plugin.
+func Parse(spec string) (*Plugin, error) {
+	spec = strings.TrimSpace(spec)
+
+	if pkg, ok := strings.CutPrefix(spec, NPMPrefix); ok {
+		p := &Plugin{Name: pkg}
+		if i := strings.LastIndex(pkg, "@"); i > 0 {
+			p.Name, p.Version = pkg[:i], pkg[i+1:]
+		}
+		return p, p.Validate()
+	}
+
+	name, marketplace, ok := strings.Cut(spec, "@")
+	if !ok || name == "" || marketplace == "" {
+		return nil, fmt.Errorf("invalid plugin %q: expected name@marketplace or npm:package", spec)
+	}
+	p := &Plugin{Name: name, Marketplace: marketplace}
+	return p, p.Validate()
+}
+
+// Find returns the plugin with the given ID, or nil
+func Find(plugins []*Plugin, id string) *Plugin {
+	for _, p := range plugins {
+		if p.ID() == id {
+			return p
+		}
+	}
+	return nil
+}
+
+// Merge returns base with overrides applied; overrides replace plugins with
+// the same ID and new plugins are appended
+func Merge(base, overrides []*Plugin) []*Plugin {
+	if len(overrides) == 0 {
+		return base
+	}
+	merged := make([]*Plugin, 0, len(base)+len(overrides))
+	for _, p := range base {
+		if Find(overrides, p.ID()) == nil {
+			merged = append(merged, p)
+		}
+	}
+	return append(merged, overrides...)
+}
+
+// Marketplace source types understood by Claude Code
+const (
+	SourceGitHub    = "github"
+	SourceGit       = "git"
+	SourceDirectory = "directory"
+)
+
+// Marketplace is a plugin catalog tools can install plugins from
+type Marketplace struct {
+	Source string `json:"source" jsonschema:"enum=github|git|directory"` // "github", "git" or "directory"
+	Repo   string `json:"repo,omitempty"`                                // owner/repo for github sources
+	URL    string `json:"url,omitempty"`                                 // Clone URL for git sources
+	Path   string `json:"path,omitempty"`                                // Local path for directory sources
+}
+
+// ParseMarketplace infers a marketplace source from "owner/repo", a git
+// URL or a local directory path
+func ParseMarketplace(source string) (*Marketplace, error) {
+	source = strings.TrimSpace(source)
+	switch {
+	case source == "":
+		return nil, fmt.Errorf("marketplace source is required")
+	case strings.HasPrefix(source, "."):
+		// Relative paths are resolved now; tools don't run from our cwd
+		abs, err := filepath.Abs(source)
+		if err != nil {
+			return nil, err
+		}
+		return &Marketplace{Source: SourceDirectory, Path: abs}, nil
+	case strings.HasPrefix(source, "/"), strings.HasPrefix(source, "~"):
+		return &Marketplace{Source: SourceDirectory, Path: filepath.Clean(source)}, nil
+	case strings.Contains(source, "://"), strings.HasPrefix(source, "git@"), strings.HasSuffix(source, ".git"):
+		return &Marketplace{Source: SourceGit, URL: source}, nil
+	case strings.Count(source, "/") == 1 && !strings.ContainsAny(source, " :"):
+		return &Marketplace{Source: SourceGitHub, Repo: source}, nil
+	}
+	return nil, fmt.Errorf("invalid marketplace source %q: expected owner/repo, a git URL or a directory", source)
+}
+
+// Location returns the repo, URL or path the marketplace is fetched from
+func (m *Marketplace) Location() string {
+	switch m.Source {
+	case SourceGitHub:
+		return m.Repo
+	case SourceGit:
+		return m.URL
+	default:
+		return m.Path
+	}
+}
+
+// Validate checks that the source has the field its type requires
+func (m *Marketplace) Validate() error {
+	switch m.Source {
+	case SourceGitHub, SourceGit, SourceDirectory:
+	default:
+		return fmt.Errorf("unknown marketplace source type %q", m.Source)
+	}
+	if m.Location() == "" {
+		return fmt.Errorf("%s marketplace is missing its location", m.Source)
+	}
+	return nil
+}
+
+// ToMap converts the marketplace to Claude Code's source object
+func (m *Marketplace) ToMap() map[string]interface{} {
+	source := map[string]interface{}{"source": m.Source}
+	switch m.Source {
+	case SourceGitHub:
+		source["repo"] = m.Repo
+	case SourceGit:
+		source["url"] = m.URL
+	case SourceDirectory:
+		source["path"] = m.Path
+	}
+	return source
+}
+
+// MarketplaceFromMap reads a Claude Code source object
+func MarketplaceFromMap(source map[string]interface{}) *Marketplace {
+	m := &Marketplace{}
+	m.Source, _ = source["source"].(string)
+	m.Repo, _ = source["repo"].(string)
+	m.URL, _ = source["url"].(string)
+	m.Path, _ = source["path"].(string)
+	return m
+}
@@ -0,0 +1,103 @@
+package plugin
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    *Plugin
+		wantErr bool
+	}{
+		{spec: "commit-commands@claude-plugins-official", want: &Plugin{Name: "commit-commands", Marketplace: "claude-plugins-official"}},
+		{spec: "npm:opencode-wakatime", want: &Plugin{Name: "opencode-wakatime"}},
+		{spec: "npm:opencode-wakatime@1.2.0", want: &Plugin{Name: "opencode-wakatime", Version: "1.2.0"}},
+		{spec: "npm:@org/plugin@latest", want: &Plugin{Name: "@org/plugin", Version: "latest"}},
+		{spec: "npm:@org/plugin", want: &Plugin{Name: "@org/plugin"}},
+		{spec: "no-marketplace", wantErr: true},
+		{spec: "@marketplace", wantErr: true},
+		{spec: "npm:", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			got, err := Parse(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse(%q) error = %v, wantErr %v", tt.spec, err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse(%q) = %+v, want %+v", tt.spec, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestID(t *testing.T) {
+	if id := (&Plugin{Name: "review", Marketplace: "team"}).ID(); id != "review@team" {
+		t.Errorf("ID() = %q, want review@team", id)
+	}
+	if id := (&Plugin{Name: "@org/plugin", Version: "1.0.0"}).ID(); id != "@org/plugin" {
+		t.Errorf("ID() = %q, want @org/plugin", id)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	base := []*Plugin{
+		{Name: "a", Marketplace: "m"},
+		{Name: "b", Marketplace: "m"},
+	}
+	overrides := []*Plugin{
+		{Name: "b", Marketplace: "m", Disabled: true},
+		{Name: "c"},
+	}
+
+	merged := Merge(base, overrides)
+	var ids []string
+	for _, p := range merged {
+		ids = append(ids, p.ID())
+	}
+	if want := []string{"a@m", "b@m", "c"}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("Merge() IDs = %v, want %v", ids, want)
+	}
+	if !Find(merged, "b@m").Disabled {
+		t.Error("Merge() should prefer the override")
+	}
+}
+
+func TestParseMarketplace(t *testing.T) {
+	tests := []struct {
+		source  string
+		want    *Marketplace
+		wantErr bool
+	}{
+		{source: "anthropics/claude-plugins-official", want: &Marketplace{Source: SourceGitHub, Repo: "anthropics/claude-plugins-official"}},
+		{source: "https://git.example.com/plugins.git", want: &Marketplace{Source: SourceGit, URL: "https://git.example.com/plugins.git"}},
+		{source: "git@github.com:acme/plugins.git", want: &Marketplace{Source: SourceGit, URL: "git@github.com:acme/plugins.git"}},
+		{source: "/opt/plugins/", want: &Marketplace{Source: SourceDirectory, Path: "/opt/plugins"}},
+		{source: "not a source", wantErr: true},
+		{source: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.source, func(t *testing.T) {
+			got, err := ParseMarketplace(tt.source)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseMarketplace(%q) error = %v, wantErr %v", tt.source, err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseMarketplace(%q) = %+v, want %+v", tt.source, got, tt.want)
+			}
+			if err := got.Validate(); err != nil {
+				t.Errorf("Validate() error = %v", err)
+			}
+			if back := MarketplaceFromMap(got.ToMap()); !reflect.DeepEqual(back, got) {
+				t.Errorf("map round trip = %+v, want %+v", back, got)
+			}
+		})
+	}
+}
@@ -5,10 +5,13 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+
+	"github.com/iheanyi/agentctl/pkg/schema"
 )
 
 // Profile represents a configuration profile
 type Profile struct {
+	Schema      string   `json:"$schema,omitempty"` // JSON Schema URL for editor support
 	Name        string   `json:"name"`
 	Description string   `json:"description,omitempty"`
 	Servers     []string `json:"servers,omitempty"`  // Server names to include
@@ -17,6 +20,7 @@ type Profile struct {
 	Prompts     []string `json:"prompts,omitempty"`  // Prompt names to include
 	Skills      []string `json:"skills,omitempty"`   // Skill names to include
 	Disabled    []string `json:"disabled,omitempty"` // Resources to disable
+	Tools       []string `json:"tools,omitempty"`    // Tools 'agentctl serve' exposes, as prefixed names or globs
 	Path        string   `json:"-"`                  // Path to profile file (not serialized)
 }
 
@@ -27,6 +31,12 @@ func Load(path string) (*Profile, error) {
 		return nil, err
 	}
 
+	if schema.Strict {
+		if err := schema.ValidateJSON(path, data, JSONSchema()); err != nil {
+			return nil, err
+		}
+	}
+
 	var p Profile
 	if err := json.Unmarshal(data, &p); err != nil {
 		return nil, err
@@ -36,6 +46,15 @@ func Load(path string) (*Profile, error) {
 	return &p, nil
 }
 
+// JSONSchema returns the JSON Schema of profile files
+func JSONSchema() *schema.Schema {
+	return schema.Generate(Profile{}, schema.Options{
+		Name:        "profile",
+		Title:       "agentctl profile",
+		Description: "A named set of servers and resources in the profiles directory",
+	})
+}
+
 // Save saves the profile to disk
 func (p *Profile) Save() error {
 	return p.SaveTo(p.Path)
@@ -93,6 +112,7 @@ func Exists(dir, name string) bool {
 // Create creates a new empty profile
 func Create(dir, name string, description string) (*Profile, error) {
 	p := &Profile{
+		Schema:      schema.URL("profile"),
 		Name:        name,
 		Description: description,
 		Path:        filepath.Join(dir, name+".json"),
@@ -34,7 +34,7 @@ type SearchResponse struct {
 // NewMCPSoClient creates a new mcp.so client
 func NewMCPSoClient() *MCPSoClient {
 	return &MCPSoClient{
-		BaseURL: "https://mcp.so/api",
+		BaseURL: DefaultMCPSoURL,
 		HTTPClient: &http.Client{
 			Timeout: 10 * time.Second,
 		},
@@ -125,3 +125,25 @@ func (c *MCPSoClient) List(limit int) (*SearchResponse, error) {
 
 	return &result, nil
 }
+
+// Name implements Registry
+func (c *MCPSoClient) Name() string {
+	return "mcp.so"
+}
+
+// SearchServers implements Registry
+func (c *MCPSoClient) SearchServers(query string, limit int) ([]Result, error) {
+	resp, err := c.Search(query)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []Result
+	for _, r := range resp.Results {
+		if limit > 0 && len(results) >= limit {
+			break
+		}
+		results = append(results, Result{Name: r.Name, Description: r.Description, Source: c.Name()})
+	}
+	return results, nil
+}
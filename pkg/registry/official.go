@@ -0,0 +1,200 @@
+package registry
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// OfficialClient is a client for the official MCP registry API
+// (registry.modelcontextprotocol.io) or any mirror that serves the same
+// /v0 API
+type OfficialClient struct {
+	BaseURL    string
+	HTTPClient *http.Client
+}
+
+// ServerResponse is a server.json document along with registry metadata
+type ServerResponse struct {
+	Server ServerJSON     `json:"server"`
+	Meta   map[string]any `json:"_meta,omitempty"`
+}
+
+// ListMetadata carries the pagination cursor of a list response
+type ListMetadata struct {
+	NextCursor string `json:"nextCursor,omitempty"`
+	Count      int    `json:"count,omitempty"`
+}
+
+// ServerList is a page of servers from the registry
+type ServerList struct {
+	Servers  []ServerResponse `json:"servers"`
+	Metadata ListMetadata     `json:"metadata"`
+}
+
+// ListOptions filters and pages a server listing
+type ListOptions struct {
+	Search  string // Substring match on server names
+	Version string // "latest" to only return the latest version of each server
+	Cursor  string // Cursor from a previous page
+	Limit   int    // Page size (0 = registry default)
+}
+
+// officialMetaKey is the _meta key the official registry stores its
+// publication metadata under
+const officialMetaKey = "io.modelcontextprotocol.registry/official"
+
+// NewOfficialClient creates a client for the official MCP registry
+func NewOfficialClient() *OfficialClient {
+	return &OfficialClient{
+		BaseURL: DefaultOfficialURL,
+		HTTPClient: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+	}
+}
+
+// ListServers returns one page of servers
+func (c *OfficialClient) ListServers(opts ListOptions) (*ServerList, error) {
+	u, err := url.Parse(c.BaseURL + "/v0/servers")
+	if err != nil {
+		return nil, err
+	}
+
+	q := u.Query()
+	if opts.Search != "" {
+		q.Set("search", opts.Search)
+	}
+	if opts.Version != "" {
+		q.Set("version", opts.Version)
+	}
+	if opts.Cursor != "" {
+		q.Set("cursor", opts.Cursor)
+	}
+	if opts.Limit > 0 {
+		q.Set("limit", strconv.Itoa(opts.Limit))
+	}
+	u.RawQuery = q.Encode()
+
+	var result ServerList
+	if _, err := c.getJSON(u.String(), &result); err != nil {
+		return nil, fmt.Errorf("failed to list servers: %w", err)
+	}
+	return &result, nil
+}
+
+// Search follows pagination until it has collected limit servers matching
+// query (latest versions only) or the registry runs out of results
+func (c *OfficialClient) Search(query string, limit int) ([]ServerResponse, error) {
+	var servers []ServerResponse
+	opts := ListOptions{Search: query, Version: "latest", Limit: limit}
+	for {
+		page, err := c.ListServers(opts)
+		if err != nil {
+			return nil, err
+		}
+		servers = append(servers, page.Servers...)
+		if limit > 0 && len(servers) >= limit {
+			return servers[:limit], nil
+		}
+		if page.Metadata.NextCursor == "" || len(page.Servers) == 0 {
+			return servers, nil
+		}
+		opts.Cursor = page.Metadata.NextCursor
+	}
+}
+
+// Get returns a specific version of a server, or the latest version when
+// version is empty. It returns nil if the server doesn't exist.
+func (c *OfficialClient) Get(name, version string) (*ServerResponse, error) {
+	if version == "" {
+		version = "latest"
+	}
+	u := c.BaseURL + "/v0/servers/" + url.PathEscape(name) + "/versions/" + url.PathEscape(version)
+
+	var result ServerResponse
+	found, err := c.getJSON(u, &result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get server: %w", err)
+	}
+	if !found {
+		return nil, nil
+	}
+	return &result, nil
+}
+
+// Versions returns every published version of a server. It returns nil if
+// the server doesn't exist.
+func (c *OfficialClient) Versions(name string) ([]ServerResponse, error) {
+	u := c.BaseURL + "/v0/servers/" + url.PathEscape(name) + "/versions"
+
+	var result ServerList
+	found, err := c.getJSON(u, &result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list versions: %w", err)
+	}
+	if !found {
+		return nil, nil
+	}
+	return result.Servers, nil
+}
+
+// Name implements Registry
+func (c *OfficialClient) Name() string {
+	return "registry"
+}
+
+// SearchServers implements Registry
+func (c *OfficialClient) SearchServers(query string, limit int) ([]Result, error) {
+	servers, err := c.Search(query, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]Result, 0, len(servers))
+	for _, s := range servers {
+		results = append(results, Result{
+			Name:        s.Server.Name,
+			Description: s.Server.Description,
+			Version:     s.Server.Version,
+			Source:      c.Name(),
+		})
+	}
+	return results, nil
+}
+
+// IsLatest reports whether the registry marks this as the server's latest
+// version; responses without registry metadata count as latest
+func (r *ServerResponse) IsLatest() bool {
+	meta, ok := r.Meta[officialMetaKey].(map[string]any)
+	if !ok {
+		return true
+	}
+	latest, ok := meta["isLatest"].(bool)
+	return !ok || latest
+}
+
+// getJSON fetches u and decodes the body into v. It returns false for a
+// 404 response.
+func (c *OfficialClient) getJSON(u string, v any) (bool, error) {
+	resp, err := c.HTTPClient.Get(u)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return false, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("registry returned status %d", resp.StatusCode)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return false, fmt.Errorf("failed to parse response: %w", err)
+	}
+	return true, nil
+}
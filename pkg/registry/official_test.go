@@ -0,0 +1,263 @@
+package registry
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/iheanyi/agentctl/pkg/mcp"
+)
+
+func TestOfficialSearchPagination(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v0/servers" {
+			t.Errorf("Unexpected path: %s", r.URL.Path)
+		}
+		if got := r.URL.Query().Get("search"); got != "weather" {
+			t.Errorf("search = %q, want weather", got)
+		}
+		if got := r.URL.Query().Get("version"); got != "latest" {
+			t.Errorf("version = %q, want latest", got)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Query().Get("cursor") {
+		case "":
+			w.Write([]byte(`{"servers": [{"server": {"name": "io.github.a/weather", "version": "1.0.0"}}],
+				"metadata": {"nextCursor": "page2", "count": 1}}`))
+		case "page2":
+			w.Write([]byte(`{"servers": [{"server": {"name": "io.github.b/weather", "description": "Forecasts"}}],
+				"metadata": {"count": 1}}`))
+		default:
+			t.Errorf("Unexpected cursor: %s", r.URL.Query().Get("cursor"))
+		}
+	}))
+	defer server.Close()
+
+	client := NewOfficialClient()
+	client.BaseURL = server.URL
+
+	results, err := client.SearchServers("weather", 10)
+	if err != nil {
+		t.Fatalf("SearchServers() error = %v", err)
+	}
+	want := []Result{
+		{Name: "io.github.a/weather", Version: "1.0.0", Source: "registry"},
+		{Name: "io.github.b/weather", Description: "Forecasts", Source: "registry"},
+	}
+	if !reflect.DeepEqual(results, want) {
+		t.Errorf("SearchServers() = %+v, want %+v", results, want)
+	}
+
+	limited, err := client.Search("weather", 1)
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if len(limited) != 1 {
+		t.Errorf("Search() with limit 1 returned %d servers", len(limited))
+	}
+}
+
+func TestOfficialGetAndVersions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.EscapedPath() {
+		case "/v0/servers/io.github.a%2Fweather/versions/latest":
+			w.Write([]byte(`{"server": {"name": "io.github.a/weather", "version": "2.0.0"},
+				"_meta": {"io.modelcontextprotocol.registry/official": {"isLatest": true}}}`))
+		case "/v0/servers/io.github.a%2Fweather/versions":
+			w.Write([]byte(`{"servers": [
+				{"server": {"name": "io.github.a/weather", "version": "1.0.0"}, "_meta": {"io.modelcontextprotocol.registry/official": {"isLatest": false}}},
+				{"server": {"name": "io.github.a/weather", "version": "2.0.0"}, "_meta": {"io.modelcontextprotocol.registry/official": {"isLatest": true}}}
+			]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	client := NewOfficialClient()
+	client.BaseURL = server.URL
+
+	resp, err := client.Get("io.github.a/weather", "")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if resp == nil || resp.Server.Version != "2.0.0" || !resp.IsLatest() {
+		t.Errorf("Get() = %+v, want latest 2.0.0", resp)
+	}
+
+	missing, err := client.Get("io.github.a/missing", "")
+	if err != nil || missing != nil {
+		t.Errorf("Get() for a missing server = %+v, %v; want nil, nil", missing, err)
+	}
+
+	versions, err := client.Versions("io.github.a/weather")
+	if err != nil {
+		t.Fatalf("Versions() error = %v", err)
+	}
+	if len(versions) != 2 || versions[0].IsLatest() || !versions[1].IsLatest() {
+		t.Errorf("Versions() = %+v", versions)
+	}
+}
+
+const weatherServerJSON = `{
+  "name": "io.github.acme/weather",
+  "version": "1.2.0",
+  "repository": {"url": "https://github.com/acme/weather", "source": "github"},
+  "packages": [
+    {
+      "registryType": "mcpb",
+      "identifier": "https://example.com/weather.mcpb",
+      "transport": {"type": "stdio"}
+    },
+    {
+      "registryType": "npm",
+      "identifier": "@acme/weather",
+      "version": "1.2.0",
+      "transport": {"type": "stdio"},
+      "packageArguments": [
+        {"type": "named", "name": "--units", "default": "metric"},
+        {"type": "positional", "valueHint": "region"}
+      ],
+      "environmentVariables": [
+        {"name": "WEATHER_API_KEY", "isRequired": true, "isSecret": true},
+        {"name": "WEATHER_LOG", "default": "info"}
+      ]
+    },
+    {
+      "registryType": "oci",
+      "identifier": "ghcr.io/acme/weather",
+      "version": "1.2.0",
+      "transport": {"type": "stdio"},
+      "environmentVariables": [{"name": "WEATHER_API_KEY", "isSecret": true}]
+    }
+  ],
+  "remotes": [
+    {
+      "type": "streamable-http",
+      "url": "https://weather.acme.dev/mcp",
+      "headers": [{"name": "Authorization", "value": "Bearer {api_key}", "isSecret": true}]
+    }
+  ]
+}`
+
+func TestServerJSONToServer(t *testing.T) {
+	var doc ServerJSON
+	if err := json.Unmarshal([]byte(weatherServerJSON), &doc); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		doc    ServerJSON
+		prefer string
+		want   *mcp.Server
+		inputs []mcp.Input
+	}{
+		{
+			name: "npm package preferred",
+			doc:  doc,
+			want: &mcp.Server{
+				Command:   "npx",
+				Args:      []string{"-y", "@acme/weather@1.2.0", "--units", "metric", "$REGION"},
+				Env:       map[string]string{"WEATHER_API_KEY": "$WEATHER_API_KEY", "WEATHER_LOG": "info"},
+				Transport: mcp.TransportStdio,
+			},
+			inputs: []mcp.Input{
+				{Name: "WEATHER_API_KEY", Secret: true},
+				{Name: "WEATHER_LOG", Default: "info", Optional: true},
+			},
+		},
+		{
+			name:   "remote",
+			doc:    doc,
+			prefer: "remote",
+			want: &mcp.Server{
+				URL:       "https://weather.acme.dev/mcp",
+				Headers:   map[string]string{"Authorization": "Bearer ${API_KEY}"},
+				Transport: mcp.TransportHTTP,
+			},
+			inputs: []mcp.Input{
+				{Name: "Authorization", Secret: true, Optional: true, Target: mcp.InputTargetHeader, Format: "Bearer {value}"},
+			},
+		},
+		{
+			name: "pypi package",
+			doc: ServerJSON{Name: "io.github.acme/weather", Version: "1.2.0", Packages: []Package{{
+				RegistryType: RegistryPyPI, Identifier: "acme-weather", Version: "1.2.0",
+			}}},
+			want: &mcp.Server{
+				Command:   "uvx",
+				Args:      []string{"acme-weather==1.2.0"},
+				Transport: mcp.TransportStdio,
+			},
+		},
+		{
+			name: "oci package",
+			doc:  ServerJSON{Name: "io.github.acme/weather", Version: "1.2.0", Packages: doc.Packages[2:]},
+			want: &mcp.Server{
+				Container: &mcp.Container{Image: "ghcr.io/acme/weather:1.2.0"},
+				Env:       map[string]string{"WEATHER_API_KEY": "$WEATHER_API_KEY"},
+				Transport: mcp.TransportStdio,
+			},
+			inputs: []mcp.Input{{Name: "WEATHER_API_KEY", Secret: true, Optional: true}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, inputs, err := tt.doc.ToServer(tt.prefer)
+			if err != nil {
+				t.Fatalf("ToServer() error = %v", err)
+			}
+			if got.Name != "weather" || got.Source.Type != "registry" || got.Source.Alias != "io.github.acme/weather" || got.Source.Ref != "1.2.0" {
+				t.Errorf("ToServer() name/source = %q, %+v", got.Name, got.Source)
+			}
+			got.Name, got.Source = "", mcp.Source{}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ToServer() = %+v, want %+v", got, tt.want)
+			}
+			if !reflect.DeepEqual(inputs, tt.inputs) {
+				t.Errorf("ToServer() inputs = %+v, want %+v", inputs, tt.inputs)
+			}
+		})
+	}
+
+	if _, _, err := (&ServerJSON{Name: "io.github.acme/remote-only", Remotes: doc.Remotes}).ToServer("local"); err == nil {
+		t.Error("ToServer(local) should fail without a runnable package")
+	}
+}
+
+func TestIsRegistryName(t *testing.T) {
+	tests := map[string]bool{
+		"io.github.acme/weather":  true,
+		"com.example/server":      true,
+		"filesystem":              false,
+		"github.com/org/repo":     false,
+		"./local/path":            false,
+		"https://mcp.example.com": false,
+		"acme/weather":            false,
+	}
+	for name, want := range tests {
+		if got := IsRegistryName(name); got != want {
+			t.Errorf("IsRegistryName(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
+
+func TestResolveURL(t *testing.T) {
+	t.Setenv(EnvRegistryURL, "")
+	if got := ResolveURL(EnvRegistryURL, "", DefaultOfficialURL); got != DefaultOfficialURL {
+		t.Errorf("ResolveURL() default = %q", got)
+	}
+	if got := ResolveURL(EnvRegistryURL, "https://mirror.example.com/", DefaultOfficialURL); got != "https://mirror.example.com" {
+		t.Errorf("ResolveURL() configured = %q", got)
+	}
+	t.Setenv(EnvRegistryURL, "http://localhost:8080")
+	if got := ResolveURL(EnvRegistryURL, "https://mirror.example.com", DefaultOfficialURL); got != "http://localhost:8080" {
+		t.Errorf("ResolveURL() env = %q", got)
+	}
+}
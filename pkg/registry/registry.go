@@ -0,0 +1,66 @@
+package registry
+
+import (
+	"os"
+	"strings"
+)
+
+// Environment variables that point agentctl at other registries, e.g. a
+// private mirror or a test server
+const (
+	EnvRegistryURL  = "AGENTCTL_REGISTRY_URL"
+	EnvCommunityURL = "AGENTCTL_COMMUNITY_REGISTRY_URL"
+)
+
+// Default registry base URLs
+const (
+	DefaultOfficialURL = "https://registry.modelcontextprotocol.io"
+	DefaultMCPSoURL    = "https://mcp.so/api"
+)
+
+// Result is a registry search result in a registry-independent form
+type Result struct {
+	Name        string // Name to install with (registry name or alias)
+	Description string
+	Version     string
+	Source      string // Registry the result came from, e.g. "official" or "mcp.so"
+}
+
+// Registry is a searchable catalog of MCP servers
+type Registry interface {
+	// Name identifies the registry in search output
+	Name() string
+	// SearchServers returns up to limit servers matching query
+	SearchServers(query string, limit int) ([]Result, error)
+}
+
+// ResolveURL picks a registry base URL: the environment variable wins over
+// the configured value, which wins over the default
+func ResolveURL(envVar, configured, fallback string) string {
+	if v := os.Getenv(envVar); v != "" {
+		return strings.TrimSuffix(v, "/")
+	}
+	if configured != "" {
+		return strings.TrimSuffix(configured, "/")
+	}
+	return fallback
+}
+
+// IsRegistryName reports whether target looks like an official registry
+// server name ("io.github.owner/server", "com.example/server") rather than
+// an alias, path or URL
+func IsRegistryName(target string) bool {
+	namespace, name, ok := strings.Cut(target, "/")
+	return ok && name != "" && !strings.Contains(name, "/") &&
+		strings.Contains(namespace, ".") && !strings.Contains(target, "://") &&
+		!strings.HasPrefix(target, ".")
+}
+
+// ShortName returns the part of a registry name after the namespace,
+// which agentctl uses as the server name
+func ShortName(registryName string) string {
+	if i := strings.LastIndex(registryName, "/"); i >= 0 {
+		return registryName[i+1:]
+	}
+	return registryName
+}
@@ -0,0 +1,337 @@
+package registry
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/iheanyi/agentctl/pkg/container"
+	"github.com/iheanyi/agentctl/pkg/mcp"
+)
+
+// ServerJSON is a server.json document as published to the official MCP
+// registry
+type ServerJSON struct {
+	Schema      string      `json:"$schema,omitempty"`
+	Name        string      `json:"name"`
+	Title       string      `json:"title,omitempty"`
+	Description string      `json:"description,omitempty"`
+	Version     string      `json:"version,omitempty"`
+	WebsiteURL  string      `json:"websiteUrl,omitempty"`
+	Repository  *Repository `json:"repository,omitempty"`
+	Packages    []Package   `json:"packages,omitempty"`
+	Remotes     []Remote    `json:"remotes,omitempty"`
+}
+
+// Repository is the source repository of a server
+type Repository struct {
+	URL       string `json:"url"`
+	Source    string `json:"source,omitempty"` // e.g. "github"
+	Subfolder string `json:"subfolder,omitempty"`
+}
+
+// Package registry types
+const (
+	RegistryNPM  = "npm"
+	RegistryPyPI = "pypi"
+	RegistryOCI  = "oci"
+)
+
+// Package is a locally run distribution of a server
+type Package struct {
+	RegistryType         string     `json:"registryType"` // npm, pypi, oci, ...
+	RegistryBaseURL      string     `json:"registryBaseUrl,omitempty"`
+	Identifier           string     `json:"identifier"`
+	Version              string     `json:"version,omitempty"`
+	RuntimeHint          string     `json:"runtimeHint,omitempty"` // e.g. npx, uvx, docker
+	Transport            Transport  `json:"transport"`
+	RuntimeArguments     []Argument `json:"runtimeArguments,omitempty"`
+	PackageArguments     []Argument `json:"packageArguments,omitempty"`
+	EnvironmentVariables []Input    `json:"environmentVariables,omitempty"`
+}
+
+// Transport is how a client talks to a package
+type Transport struct {
+	Type string `json:"type"` // stdio, streamable-http or sse
+	URL  string `json:"url,omitempty"`
+}
+
+// Remote is a hosted endpoint for a server
+type Remote struct {
+	Type    string  `json:"type"` // streamable-http or sse
+	URL     string  `json:"url"`
+	Headers []Input `json:"headers,omitempty"`
+}
+
+// Input is a user-supplied value: an environment variable, header or
+// argument value
+type Input struct {
+	Name        string           `json:"name,omitempty"`
+	Description string           `json:"description,omitempty"`
+	IsRequired  bool             `json:"isRequired,omitempty"`
+	IsSecret    bool             `json:"isSecret,omitempty"`
+	Format      string           `json:"format,omitempty"`
+	Value       string           `json:"value,omitempty"`
+	Default     string           `json:"default,omitempty"`
+	Choices     []string         `json:"choices,omitempty"`
+	Variables   map[string]Input `json:"variables,omitempty"`
+}
+
+// Argument is a runtime or package command-line argument
+type Argument struct {
+	Input
+	Type       string `json:"type"` // positional or named
+	ValueHint  string `json:"valueHint,omitempty"`
+	IsRepeated bool   `json:"isRepeated,omitempty"`
+}
+
+// placeholderRe matches {variable} references in input values
+var placeholderRe = regexp.MustCompile(`\{([A-Za-z0-9_.-]+)\}`)
+
+// ToServer converts a server.json document into an agentctl server.
+// prefer is "local" to use a package, "remote" to use a hosted endpoint,
+// or empty to use a package when one is supported and fall back to a
+// remote otherwise.
+//
+// Values the user has to supply become environment references: a package
+// env var API_KEY without a default is written as "$API_KEY" (resolved
+// like any other secret reference), and a header template
+// "Bearer {token}" becomes "Bearer ${TOKEN}". The returned inputs declare
+// those env vars and headers so they can be prompted for on install.
+func (s *ServerJSON) ToServer(prefer string) (*mcp.Server, []mcp.Input, error) {
+	server := &mcp.Server{
+		Name: ShortName(s.Name),
+		Source: mcp.Source{
+			Type:  "registry",
+			Alias: s.Name,
+			Ref:   s.Version,
+		},
+	}
+	if s.Repository != nil {
+		server.Source.URL = s.Repository.URL
+	}
+
+	if prefer != "remote" {
+		for _, pkg := range s.Packages {
+			if applyPackage(server, pkg) {
+				return server, packageInputs(pkg), nil
+			}
+		}
+	}
+	if prefer != "local" {
+		for _, remote := range s.Remotes {
+			if applyRemote(server, remote) {
+				return server, remoteInputs(remote), nil
+			}
+		}
+	}
+
+	switch prefer {
+	case "local":
+		return nil, nil, fmt.Errorf("%s has no package agentctl can run locally", s.Name)
+	case "remote":
+		return nil, nil, fmt.Errorf("%s has no remote endpoint", s.Name)
+	}
+	return nil, nil, fmt.Errorf("%s has no supported package or remote", s.Name)
+}
+
+// packageInputs declares the package env vars that have no fixed value
+func packageInputs(pkg Package) []mcp.Input {
+	var inputs []mcp.Input
+	for _, env := range pkg.EnvironmentVariables {
+		if env.Name == "" || env.Value != "" {
+			continue
+		}
+		inputs = append(inputs, mcp.Input{
+			Name:        env.Name,
+			Description: env.Description,
+			Secret:      env.IsSecret,
+			Default:     env.Default,
+			Optional:    !env.IsRequired,
+		})
+	}
+	return inputs
+}
+
+// remoteInputs declares the headers the user has to fill in: headers with
+// no value, and header templates with a single {variable}
+func remoteInputs(remote Remote) []mcp.Input {
+	var inputs []mcp.Input
+	for _, h := range remote.Headers {
+		if h.Name == "" {
+			continue
+		}
+		in := mcp.Input{
+			Name:        h.Name,
+			Description: h.Description,
+			Secret:      h.IsSecret,
+			Optional:    !h.IsRequired,
+			Target:      mcp.InputTargetHeader,
+		}
+		if h.Value != "" {
+			vars := placeholderRe.FindAllStringSubmatch(h.Value, -1)
+			if len(vars) != 1 {
+				continue
+			}
+			variable := h.Variables[vars[0][1]]
+			if variable.Value != "" {
+				continue
+			}
+			in.Format = strings.Replace(h.Value, vars[0][0], "{value}", 1)
+			in.Secret = in.Secret || variable.IsSecret
+			in.Default = variable.Default
+			if variable.Description != "" {
+				in.Description = variable.Description
+			}
+		}
+		inputs = append(inputs, in)
+	}
+	return inputs
+}
+
+// applyPackage configures server to run pkg over stdio. It returns false
+// for registry types and transports agentctl can't run.
+func applyPackage(server *mcp.Server, pkg Package) bool {
+	if pkg.Transport.Type != "" && pkg.Transport.Type != "stdio" {
+		return false
+	}
+
+	var command string
+	var args []string
+	switch pkg.RegistryType {
+	case RegistryNPM:
+		command = "npx"
+		if pkg.RuntimeHint == "" || pkg.RuntimeHint == "npx" {
+			args = []string{"-y"}
+		}
+		ref := pkg.Identifier
+		if pkg.Version != "" {
+			ref += "@" + pkg.Version
+		}
+		args = append(args, argumentValues(pkg.RuntimeArguments)...)
+		args = append(args, ref)
+	case RegistryPyPI:
+		command = "uvx"
+		ref := pkg.Identifier
+		if pkg.Version != "" {
+			ref += "==" + pkg.Version
+		}
+		args = append(argumentValues(pkg.RuntimeArguments), ref)
+	case RegistryOCI:
+		// Images run through the container runtime, which passes env vars
+		// through by name
+		ref := pkg.Identifier
+		if pkg.Version != "" && !strings.Contains(ref, "@") && !strings.Contains(ref[strings.LastIndex(ref, "/")+1:], ":") {
+			ref += ":" + pkg.Version
+		}
+		server.Container = &mcp.Container{Image: ref, Args: argumentValues(pkg.RuntimeArguments)}
+		if pkg.RuntimeHint == container.Podman {
+			server.Container.Runtime = container.Podman
+		}
+	default:
+		return false
+	}
+	if pkg.RuntimeHint != "" && server.Container == nil {
+		command = pkg.RuntimeHint
+	}
+
+	server.Transport = mcp.TransportStdio
+	server.Command = command
+	server.Args = append(args, argumentValues(pkg.PackageArguments)...)
+
+	for _, env := range pkg.EnvironmentVariables {
+		if env.Name == "" {
+			continue
+		}
+		if server.Env == nil {
+			server.Env = make(map[string]string)
+		}
+		server.Env[env.Name] = inputValue(env, env.Name)
+	}
+	return true
+}
+
+// applyRemote configures server to connect to remote. It returns false
+// for unknown transport types.
+func applyRemote(server *mcp.Server, remote Remote) bool {
+	switch remote.Type {
+	case "streamable-http", "http":
+		server.Transport = mcp.TransportHTTP
+	case "sse":
+		server.Transport = mcp.TransportSSE
+	default:
+		return false
+	}
+
+	server.URL = remote.URL
+	for _, h := range remote.Headers {
+		if h.Name == "" {
+			continue
+		}
+		if server.Headers == nil {
+			server.Headers = make(map[string]string)
+		}
+		server.Headers[h.Name] = inputValue(h, h.Name)
+	}
+	return true
+}
+
+// argumentValues renders arguments as command-line words. Arguments with
+// no value, default or hint are skipped.
+func argumentValues(arguments []Argument) []string {
+	var words []string
+	for _, arg := range arguments {
+		hint := arg.ValueHint
+		if hint == "" {
+			hint = arg.Name
+		}
+		value := inputValue(arg.Input, strings.TrimLeft(hint, "-"))
+		switch arg.Type {
+		case "named":
+			if arg.Name == "" {
+				continue
+			}
+			words = append(words, arg.Name)
+			if arg.Value != "" || arg.Default != "" {
+				words = append(words, value)
+			}
+		default:
+			if arg.Value == "" && arg.Default == "" && hint == "" {
+				continue
+			}
+			words = append(words, value)
+		}
+	}
+	return words
+}
+
+// inputValue returns the literal value for an input, its default, or an
+// environment reference named after it. {variable} placeholders in values
+// become environment references too.
+func inputValue(in Input, name string) string {
+	value := in.Value
+	if value == "" {
+		value = in.Default
+	}
+	if value == "" {
+		return "$" + envName(name)
+	}
+	return placeholderRe.ReplaceAllStringFunc(value, func(m string) string {
+		variable := m[1 : len(m)-1]
+		if v, ok := in.Variables[variable]; ok && v.Value != "" {
+			return v.Value
+		}
+		return "${" + envName(variable) + "}"
+	})
+}
+
+// envName turns an input name into an environment variable name
+func envName(name string) string {
+	name = strings.ToUpper(name)
+	return strings.Map(func(r rune) rune {
+		if (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '_' {
+			return r
+		}
+		return '_'
+	}, name)
+}
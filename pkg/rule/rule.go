@@ -8,7 +8,9 @@ import (
 
 	"gopkg.in/yaml.v3"
 
+	"github.com/iheanyi/agentctl/pkg/condition"
 	"github.com/iheanyi/agentctl/pkg/pathutil"
+	"github.com/iheanyi/agentctl/pkg/schema"
 )
 
 // InspectTitle returns the display name for the inspector modal header
@@ -57,6 +59,19 @@ type Frontmatter struct {
 	Applies  string   `yaml:"applies,omitempty"`  // File pattern this rule applies to (e.g., "*.ts") - legacy
 	Paths    []string `yaml:"paths,omitempty"`    // File patterns for conditional rules (Claude Code style)
 	Globs    []string `yaml:"globs,omitempty"`    // File patterns for conditional rules (Cursor style)
+
+	// When limits the rule to environments where the condition holds
+	When *condition.Condition `yaml:"when,omitempty"`
+}
+
+// FrontmatterSchema returns the JSON Schema of rule frontmatter
+func FrontmatterSchema() *schema.Schema {
+	return schema.Generate(Frontmatter{}, schema.Options{
+		Name:        "rule",
+		Title:       "agentctl rule frontmatter",
+		Description: "YAML frontmatter of a rule markdown file",
+		Tag:         "yaml",
+	})
 }
 
 // Rule represents a rule/instruction configuration
@@ -78,6 +93,12 @@ func Load(path string) (*Rule, error) {
 		return nil, err
 	}
 
+	if schema.Strict {
+		if err := schema.ValidateFrontmatter(path, data, FrontmatterSchema()); err != nil {
+			return nil, err
+		}
+	}
+
 	content := string(data)
 	rule := &Rule{
 		Path: path,
@@ -178,11 +199,26 @@ func LoadAll(dir string) ([]*Rule, error) {
 
 // Save saves a rule to a directory as a markdown file
 func Save(r *Rule, dir string) error {
+	name, err := FileName(r)
+	if err != nil {
+		return err
+	}
+	content, err := Marshal(r)
+	if err != nil {
+		return err
+	}
+
 	// Ensure directory exists
 	if err := os.MkdirAll(dir, 0755); err != nil {
 		return err
 	}
 
+	path := dir + "/" + name
+	return os.WriteFile(path, content, 0644)
+}
+
+// Marshal formats a rule as markdown with its frontmatter
+func Marshal(r *Rule) ([]byte, error) {
 	var content strings.Builder
 
 	// Write frontmatter if present
@@ -217,11 +253,22 @@ func Save(r *Rule, dir string) error {
 				content.WriteString("\"\n")
 			}
 		}
+		if !r.Frontmatter.When.IsEmpty() {
+			when, err := yaml.Marshal(map[string]*condition.Condition{"when": r.Frontmatter.When})
+			if err != nil {
+				return nil, fmt.Errorf("marshaling when: %w", err)
+			}
+			content.Write(when)
+		}
 		content.WriteString("---\n\n")
 	}
 
 	content.WriteString(r.Content)
+	return []byte(content.String()), nil
+}
 
+// FileName returns the file a rule is saved as, named after the rule
+func FileName(r *Rule) (string, error) {
 	// Determine filename
 	name := r.Name
 	if name == "" {
@@ -231,13 +278,11 @@ func Save(r *Rule, dir string) error {
 	// Validate rule name to prevent path traversal (without extension)
 	baseName := strings.TrimSuffix(name, ".md")
 	if err := pathutil.SanitizeName(baseName); err != nil {
-		return fmt.Errorf("invalid rule name: %w", err)
+		return "", fmt.Errorf("invalid rule name: %w", err)
 	}
 
 	if !strings.HasSuffix(name, ".md") {
 		name += ".md"
 	}
-
-	path := dir + "/" + name
-	return os.WriteFile(path, []byte(content.String()), 0644)
+	return name, nil
 }
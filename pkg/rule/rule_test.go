@@ -4,6 +4,8 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+
+	"github.com/iheanyi/agentctl/pkg/condition"
 )
 
 func TestLoadRuleWithFrontmatter(t *testing.T) {
@@ -202,3 +204,30 @@ Content`,
 		})
 	}
 }
+
+func TestSaveRuleWithCondition(t *testing.T) {
+	dir := t.TempDir()
+	r := &Rule{
+		Name: "docker",
+		Frontmatter: &Frontmatter{
+			Priority: 2,
+			When:     &condition.Condition{Binary: []string{"docker"}, OS: []string{"!windows"}},
+		},
+		Content: "Use docker compose.",
+	}
+	if err := Save(r, dir); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	loaded, err := Load(filepath.Join(dir, "docker.md"))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	when := loaded.Frontmatter.When
+	if when == nil || len(when.Binary) != 1 || when.Binary[0] != "docker" || len(when.OS) != 1 || when.OS[0] != "!windows" {
+		t.Errorf("When = %+v after a round trip", when)
+	}
+	if loaded.Content != "Use docker compose." {
+		t.Errorf("Content = %q", loaded.Content)
+	}
+}
@@ -0,0 +1,175 @@
+// Package schema generates JSON Schemas from Go types and validates
+// JSON and YAML documents against them with line and column positions.
+//
+// Schemas are generated by reflection from the same struct tags the
+// loaders decode with, so they can't drift from the Go types. Struct
+// objects disallow unknown properties, which is what catches typos like
+// "trasnport". Fields may add constraints with a jsonschema tag:
+//
+//	Transport string `json:"transport" jsonschema:"enum=stdio|sse|http"`
+package schema
+
+import (
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Draft is the JSON Schema dialect of generated schemas
+const Draft = "https://json-schema.org/draft/2020-12/schema"
+
+// BaseURL is where the published schemas live
+const BaseURL = "https://raw.githubusercontent.com/iheanyi/agentctl/main/schemas/"
+
+// Strict makes loaders validate files against their schema before
+// decoding them. It's set by the global --strict flag.
+var Strict bool
+
+// URL returns the published URL of the named schema
+func URL(name string) string {
+	return BaseURL + name + ".schema.json"
+}
+
+// Schema is a JSON Schema document or subschema
+type Schema struct {
+	Schema      string `json:"$schema,omitempty"`
+	ID          string `json:"$id,omitempty"`
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+
+	Type   string   `json:"type,omitempty"`
+	Format string   `json:"format,omitempty"`
+	Enum   []string `json:"enum,omitempty"`
+
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties any                `json:"additionalProperties,omitempty"` // false or *Schema
+	Items                *Schema            `json:"items,omitempty"`
+}
+
+// Options controls schema generation
+type Options struct {
+	Name        string // Published name, used for $id
+	Title       string
+	Description string
+	Tag         string // Struct tag naming fields: "json" (default) or "yaml"
+}
+
+// Generate returns the schema of v's type
+func Generate(v any, opts Options) *Schema {
+	tag := opts.Tag
+	if tag == "" {
+		tag = "json"
+	}
+	g := &generator{tag: tag, seen: make(map[reflect.Type]bool)}
+	s := g.schemaFor(reflect.TypeOf(v))
+	s.Schema = Draft
+	if opts.Name != "" {
+		s.ID = URL(opts.Name)
+	}
+	s.Title = opts.Title
+	s.Description = opts.Description
+	return s
+}
+
+type generator struct {
+	tag  string
+	seen map[reflect.Type]bool // Struct types being generated, to stop cycles
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+func (g *generator) schemaFor(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == timeType {
+		return &Schema{Type: "string", Format: "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string"}
+		}
+		return &Schema{Type: "array", Items: g.schemaFor(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: g.schemaFor(t.Elem())}
+	case reflect.Struct:
+		if g.seen[t] {
+			return &Schema{}
+		}
+		g.seen[t] = true
+		defer delete(g.seen, t)
+
+		s := &Schema{Type: "object", Properties: make(map[string]*Schema), AdditionalProperties: false}
+		g.addFields(s, t)
+		return s
+	default:
+		// Interfaces and anything else accept any value
+		return &Schema{}
+	}
+}
+
+// addFields adds a struct's fields to s, flattening embedded structs
+func (g *generator) addFields(s *Schema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, ok := g.fieldName(f)
+		if !ok {
+			continue
+		}
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			for ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				g.addFields(s, ft)
+				continue
+			}
+		}
+		if name == "" {
+			name = f.Name
+			if g.tag == "yaml" {
+				name = strings.ToLower(name)
+			}
+		}
+
+		prop := g.schemaFor(f.Type)
+		for _, opt := range strings.Split(f.Tag.Get("jsonschema"), ",") {
+			key, value, _ := strings.Cut(opt, "=")
+			switch key {
+			case "enum":
+				prop.Enum = strings.Split(value, "|")
+			case "required":
+				s.Required = append(s.Required, name)
+			}
+		}
+		s.Properties[name] = prop
+	}
+}
+
+// fieldName returns a field's name from its struct tag. ok is false for
+// unexported and ignored ("-") fields; name is empty when the tag doesn't
+// set one.
+func (g *generator) fieldName(f reflect.StructField) (name string, ok bool) {
+	if !f.IsExported() && !f.Anonymous {
+		return "", false
+	}
+	tag := f.Tag.Get(g.tag)
+	if tag == "-" {
+		return "", false
+	}
+	name, _, _ = strings.Cut(tag, ",")
+	return name, true
+}
@@ -0,0 +1,141 @@
+package schema
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+type testServer struct {
+	Command   string            `json:"command,omitempty"`
+	Args      []string          `json:"args,omitempty"`
+	Transport string            `json:"transport,omitempty" jsonschema:"enum=stdio|sse|http"`
+	Env       map[string]string `json:"env,omitempty"`
+	Disabled  bool              `json:"disabled,omitempty"`
+	Path      string            `json:"-"`
+}
+
+type testConfig struct {
+	Schema  string                 `json:"$schema,omitempty"`
+	Version string                 `json:"version" jsonschema:"required"`
+	Servers map[string]*testServer `json:"servers,omitempty"`
+}
+
+type testFrontmatter struct {
+	Name     string   `yaml:"name"`
+	Tools    []string `yaml:"tools,omitempty"`
+	Priority int      `yaml:"priority,omitempty"`
+	Model    string
+}
+
+func TestGenerate(t *testing.T) {
+	s := Generate(testConfig{}, Options{Name: "test", Title: "Test"})
+	if s.Schema != Draft || s.ID != URL("test") || s.Title != "Test" {
+		t.Errorf("header = %q %q %q", s.Schema, s.ID, s.Title)
+	}
+	if s.AdditionalProperties != false {
+		t.Errorf("struct schema should disallow additional properties")
+	}
+	if len(s.Required) != 1 || s.Required[0] != "version" {
+		t.Errorf("Required = %v", s.Required)
+	}
+	server, ok := s.Properties["servers"].AdditionalProperties.(*Schema)
+	if !ok {
+		t.Fatalf("servers should map to a server schema")
+	}
+	if _, ok := server.Properties["Path"]; ok {
+		t.Errorf(`fields tagged "-" should be skipped`)
+	}
+	if got := strings.Join(server.Properties["transport"].Enum, ","); got != "stdio,sse,http" {
+		t.Errorf("transport enum = %s", got)
+	}
+	if server.Properties["args"].Items.Type != "string" {
+		t.Errorf("args items = %+v", server.Properties["args"].Items)
+	}
+
+	fm := Generate(testFrontmatter{}, Options{Tag: "yaml"})
+	if _, ok := fm.Properties["model"]; !ok {
+		t.Errorf("untagged yaml field should be lowercased, got %v", fm.Properties)
+	}
+}
+
+func TestValidateJSON(t *testing.T) {
+	s := Generate(testConfig{}, Options{})
+	tests := []struct {
+		name string
+		data string
+		want []string
+	}{
+		{
+			name: "valid",
+			data: `{"$schema": "x", "version": "1", "servers": {"a": {"command": "npx", "transport": "stdio", "env": {"K": "v"}}}}`,
+		},
+		{
+			name: "typo",
+			data: "{\n  \"version\": \"1\",\n  \"servers\": {\n    \"a\": {\"trasnport\": \"stdio\"}\n  }\n}",
+			want: []string{`f.json:4:11: servers.a: unknown field "trasnport" (did you mean "transport"?)`},
+		},
+		{
+			name: "enum and type",
+			data: `{"version": "1", "servers": {"a": {"transport": "grpc", "args": "x", "disabled": "yes"}}}`,
+			want: []string{
+				`f.json:1:49: servers.a.transport: "grpc" is not one of stdio, sse, http`,
+				`f.json:1:65: servers.a.args: expected array, got string`,
+				`f.json:1:82: servers.a.disabled: expected boolean, got string`,
+			},
+		},
+		{
+			name: "missing required",
+			data: `{"servers": {}}`,
+			want: []string{`f.json:1:15: missing required field "version"`},
+		},
+		{
+			name: "unknown object skipped",
+			data: `{"version": "1", "extra": {"nested": [1, 2]}, "servers": {"a": {"command": 3}}}`,
+			want: []string{
+				`f.json:1:18: unknown field "extra"`,
+				`f.json:1:76: servers.a.command: expected string, got number 3`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateJSON("f.json", []byte(tt.data), s)
+			if len(tt.want) == 0 {
+				if err != nil {
+					t.Fatalf("ValidateJSON() error = %v", err)
+				}
+				return
+			}
+			var verr *ValidationError
+			if !errors.As(err, &verr) {
+				t.Fatalf("ValidateJSON() error = %v, want *ValidationError", err)
+			}
+			if got := strings.Split(verr.Error(), "\n"); strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("ValidateJSON() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+
+	if err := ValidateJSON("f.json", []byte(`{"version": `), s); err == nil || !strings.Contains(err.Error(), "invalid JSON") {
+		t.Errorf("ValidateJSON(truncated) error = %v", err)
+	}
+}
+
+func TestValidateFrontmatter(t *testing.T) {
+	s := Generate(testFrontmatter{}, Options{Tag: "yaml"})
+	data := "---\nname: reviewer\ntols: [Read]\npriority: high\nmodel: 4\n---\n\nBody with name: x\n"
+
+	err := ValidateFrontmatter("agent.md", []byte(data), s)
+	want := []string{
+		`agent.md:3:1: unknown field "tols" (did you mean "tools"?)`,
+		`agent.md:4:11: priority: expected integer, got string "high"`,
+	}
+	if err == nil || err.Error() != strings.Join(want, "\n") {
+		t.Errorf("ValidateFrontmatter() error =\n%v\nwant\n%s", err, strings.Join(want, "\n"))
+	}
+
+	if err := ValidateFrontmatter("plain.md", []byte("# No frontmatter\n"), s); err != nil {
+		t.Errorf("ValidateFrontmatter(no frontmatter) error = %v", err)
+	}
+}
@@ -0,0 +1,407 @@
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Error is a schema violation at a position in a document
+type Error struct {
+	Line    int
+	Column  int
+	Path    string // JSON pointer-like path, e.g. "servers.github.transport"
+	Message string
+}
+
+func (e *Error) Error() string {
+	if e.Path == "" {
+		return fmt.Sprintf("%d:%d: %s", e.Line, e.Column, e.Message)
+	}
+	return fmt.Sprintf("%d:%d: %s: %s", e.Line, e.Column, e.Path, e.Message)
+}
+
+// ValidationError lists the violations found in a file
+type ValidationError struct {
+	File   string
+	Errors []*Error
+}
+
+func (e *ValidationError) Error() string {
+	lines := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		lines[i] = e.File + ":" + err.Error()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// ValidateJSON validates a JSON document against s. It returns a
+// *ValidationError listing every violation, or nil. Syntax errors are
+// reported as a single violation.
+func ValidateJSON(file string, data []byte, s *Schema) error {
+	v := &jsonValidator{data: data, dec: json.NewDecoder(bytes.NewReader(data))}
+	v.dec.UseNumber()
+	if err := v.value(s, ""); err != nil {
+		line, col := position(data, int(v.dec.InputOffset()))
+		var syntax *json.SyntaxError
+		if errors.As(err, &syntax) {
+			line, col = position(data, int(syntax.Offset))
+		}
+		v.errs = append(v.errs, &Error{Line: line, Column: col, Message: "invalid JSON: " + err.Error()})
+	}
+	return result(file, v.errs)
+}
+
+// ValidateYAML validates a YAML document against s. firstLine is the line
+// of the file the document starts on, for documents embedded in a larger
+// file.
+func ValidateYAML(file string, data []byte, s *Schema, firstLine int) error {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return &ValidationError{File: file, Errors: []*Error{{Line: firstLine, Column: 1, Message: "invalid YAML: " + err.Error()}}}
+	}
+	v := &yamlValidator{offset: firstLine - 1}
+	if len(doc.Content) > 0 {
+		v.node(doc.Content[0], s, "")
+	}
+	return result(file, v.errs)
+}
+
+// ValidateFrontmatter validates the YAML frontmatter of a markdown file
+// against s. Files without frontmatter are valid.
+func ValidateFrontmatter(file string, data []byte, s *Schema) error {
+	lines := strings.Split(string(data), "\n")
+	start := 0
+	for start < len(lines) && strings.TrimSpace(lines[start]) == "" {
+		start++
+	}
+	if start == len(lines) || strings.TrimRight(lines[start], "\r") != "---" {
+		return nil
+	}
+	for end := start + 1; end < len(lines); end++ {
+		if strings.TrimRight(lines[end], "\r") == "---" {
+			fm := strings.Join(lines[start+1:end], "\n")
+			return ValidateYAML(file, []byte(fm), s, start+2)
+		}
+	}
+	return nil
+}
+
+func result(file string, errs []*Error) error {
+	if len(errs) == 0 {
+		return nil
+	}
+	sort.SliceStable(errs, func(i, j int) bool {
+		if errs[i].Line != errs[j].Line {
+			return errs[i].Line < errs[j].Line
+		}
+		return errs[i].Column < errs[j].Column
+	})
+	return &ValidationError{File: file, Errors: errs}
+}
+
+type jsonValidator struct {
+	data []byte
+	dec  *json.Decoder
+	errs []*Error
+}
+
+// start returns the offset where the next token begins
+func (v *jsonValidator) start() int {
+	off := int(v.dec.InputOffset())
+	for off < len(v.data) && strings.IndexByte(" \t\r\n,:", v.data[off]) >= 0 {
+		off++
+	}
+	return off
+}
+
+func (v *jsonValidator) fail(off int, path, format string, args ...any) {
+	line, col := position(v.data, off)
+	v.errs = append(v.errs, &Error{Line: line, Column: col, Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+// value validates the next value in the stream against s
+func (v *jsonValidator) value(s *Schema, path string) error {
+	off := v.start()
+	tok, err := v.dec.Token()
+	if err != nil {
+		return err
+	}
+	if s == nil {
+		s = &Schema{}
+	}
+
+	switch t := tok.(type) {
+	case json.Delim:
+		if t == '{' {
+			if !s.accepts("object") {
+				v.fail(off, path, "expected %s, got object", s.Type)
+				return v.skip()
+			}
+			return v.object(s, path)
+		}
+		if !s.accepts("array") {
+			v.fail(off, path, "expected %s, got array", s.Type)
+			return v.skip()
+		}
+		for i := 0; v.dec.More(); i++ {
+			if err := v.value(s.Items, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+		_, err := v.dec.Token()
+		return err
+	case string:
+		if !s.accepts("string") {
+			v.fail(off, path, "expected %s, got string", s.Type)
+		} else if len(s.Enum) > 0 && !contains(s.Enum, t) {
+			v.fail(off, path, "%q is not one of %s", t, strings.Join(s.Enum, ", "))
+		}
+	case json.Number:
+		isInt := !strings.ContainsAny(t.String(), ".eE")
+		if !(s.accepts("number") || (isInt && s.accepts("integer"))) {
+			v.fail(off, path, "expected %s, got number %s", s.Type, t)
+		}
+	case bool:
+		if !s.accepts("boolean") {
+			v.fail(off, path, "expected %s, got boolean", s.Type)
+		}
+	case nil:
+		// null decodes to the zero value of any type
+	}
+	return nil
+}
+
+func (v *jsonValidator) object(s *Schema, path string) error {
+	seen := make(map[string]bool)
+	for v.dec.More() {
+		off := v.start()
+		tok, err := v.dec.Token()
+		if err != nil {
+			return err
+		}
+		key, _ := tok.(string)
+		seen[key] = true
+
+		prop, ok := s.property(key)
+		if !ok {
+			v.fail(off, path, "%s", unknownField(key, s))
+			if err := v.skipValue(); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := v.value(prop, join(path, key)); err != nil {
+			return err
+		}
+	}
+	off := v.start()
+	if _, err := v.dec.Token(); err != nil {
+		return err
+	}
+	for _, name := range s.Required {
+		if !seen[name] {
+			v.fail(off, path, "missing required field %q", name)
+		}
+	}
+	return nil
+}
+
+// skipValue consumes the next value without validating it
+func (v *jsonValidator) skipValue() error {
+	tok, err := v.dec.Token()
+	if err != nil {
+		return err
+	}
+	if _, ok := tok.(json.Delim); ok {
+		return v.skip()
+	}
+	return nil
+}
+
+// skip consumes the rest of an object or array whose opening delimiter
+// was just read
+func (v *jsonValidator) skip() error {
+	depth := 1
+	for depth > 0 {
+		tok, err := v.dec.Token()
+		if err != nil {
+			return err
+		}
+		if d, ok := tok.(json.Delim); ok {
+			if d == '{' || d == '[' {
+				depth++
+			} else {
+				depth--
+			}
+		}
+	}
+	return nil
+}
+
+type yamlValidator struct {
+	offset int
+	errs   []*Error
+}
+
+func (v *yamlValidator) fail(n *yaml.Node, path, format string, args ...any) {
+	v.errs = append(v.errs, &Error{Line: n.Line + v.offset, Column: n.Column, Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+func (v *yamlValidator) node(n *yaml.Node, s *Schema, path string) {
+	if s == nil {
+		return
+	}
+	if n.Kind == yaml.AliasNode && n.Alias != nil {
+		n = n.Alias
+	}
+
+	switch n.Kind {
+	case yaml.MappingNode:
+		if !s.accepts("object") {
+			v.fail(n, path, "expected %s, got mapping", s.Type)
+			return
+		}
+		seen := make(map[string]bool)
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			key, value := n.Content[i], n.Content[i+1]
+			seen[key.Value] = true
+			prop, ok := s.property(key.Value)
+			if !ok {
+				v.fail(key, path, "%s", unknownField(key.Value, s))
+				continue
+			}
+			v.node(value, prop, join(path, key.Value))
+		}
+		for _, name := range s.Required {
+			if !seen[name] {
+				v.fail(n, path, "missing required field %q", name)
+			}
+		}
+	case yaml.SequenceNode:
+		if !s.accepts("array") {
+			v.fail(n, path, "expected %s, got list", s.Type)
+			return
+		}
+		for i, item := range n.Content {
+			v.node(item, s.Items, fmt.Sprintf("%s[%d]", path, i))
+		}
+	case yaml.ScalarNode:
+		var got string
+		switch n.Tag {
+		case "!!null":
+			return
+		case "!!bool":
+			got = "boolean"
+		case "!!int":
+			got = "integer"
+		case "!!float":
+			got = "number"
+		default:
+			got = "string"
+		}
+		// Any scalar decodes into a string field
+		ok := s.accepts(got) || s.accepts("string") || (got == "integer" && s.accepts("number"))
+		if !ok {
+			v.fail(n, path, "expected %s, got %s %q", s.Type, got, n.Value)
+		} else if len(s.Enum) > 0 && !contains(s.Enum, n.Value) {
+			v.fail(n, path, "%q is not one of %s", n.Value, strings.Join(s.Enum, ", "))
+		}
+	}
+}
+
+// accepts reports whether the schema allows values of type typ
+func (s *Schema) accepts(typ string) bool {
+	return s.Type == "" || s.Type == typ
+}
+
+// property returns the schema for an object key and whether it's allowed
+func (s *Schema) property(key string) (*Schema, bool) {
+	if prop, ok := s.Properties[key]; ok {
+		return prop, true
+	}
+	switch extra := s.AdditionalProperties.(type) {
+	case *Schema:
+		return extra, true
+	case bool:
+		return nil, extra
+	}
+	return nil, s.Properties == nil
+}
+
+// unknownField describes an unknown key, suggesting a close match
+func unknownField(key string, s *Schema) string {
+	msg := fmt.Sprintf("unknown field %q", key)
+	names := make([]string, 0, len(s.Properties))
+	for name := range s.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	best, bestDist := "", 3
+	for _, name := range names {
+		if d := distance(strings.ToLower(key), strings.ToLower(name)); d < bestDist {
+			best, bestDist = name, d
+		}
+	}
+	if best != "" {
+		msg += fmt.Sprintf(" (did you mean %q?)", best)
+	}
+	return msg
+}
+
+// distance is the Damerau-Levenshtein (optimal string alignment) distance
+func distance(a, b string) int {
+	d := make([][]int, len(a)+1)
+	for i := range d {
+		d[i] = make([]int, len(b)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(a)][len(b)]
+}
+
+// position converts a byte offset into a 1-based line and column
+func position(data []byte, off int) (int, int) {
+	if off > len(data) {
+		off = len(data)
+	}
+	line := 1 + bytes.Count(data[:off], []byte("\n"))
+	col := off - bytes.LastIndexByte(data[:off], '\n')
+	return line, col
+}
+
+func join(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"regexp"
 	"runtime"
 	"strings"
 )
@@ -244,9 +245,17 @@ func (s *Store) listWindows() ([]string, error) {
 	return secrets, nil
 }
 
+// envRef matches a ${ENV_VAR} reference inside a value
+var envRef = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
 // Resolve resolves a value that may be a secret reference
-// Supports: $ENV_VAR, keychain:name
+// Supports: $ENV_VAR, keychain:name, and ${ENV_VAR} inside a value such as
+// "Bearer ${API_TOKEN}"
 func Resolve(value string) (string, error) {
+	if envRef.MatchString(value) {
+		return resolveEmbedded(value)
+	}
+
 	// Environment variable
 	if strings.HasPrefix(value, "$") {
 		envName := strings.TrimPrefix(value, "$")
@@ -268,6 +277,23 @@ func Resolve(value string) (string, error) {
 	return value, nil
 }
 
+// resolveEmbedded replaces each ${ENV_VAR} in value with the variable's value
+func resolveEmbedded(value string) (string, error) {
+	var missing string
+	resolved := envRef.ReplaceAllStringFunc(value, func(ref string) string {
+		name := envRef.FindStringSubmatch(ref)[1]
+		envValue := os.Getenv(name)
+		if envValue == "" && missing == "" {
+			missing = name
+		}
+		return envValue
+	})
+	if missing != "" {
+		return "", fmt.Errorf("environment variable %s not set", missing)
+	}
+	return resolved, nil
+}
+
 // ResolveEnv resolves all values in an env map
 func ResolveEnv(env map[string]string) (map[string]string, error) {
 	resolved := make(map[string]string)
@@ -32,6 +32,22 @@ func TestResolveEnvVarNotSet(t *testing.T) {
 	}
 }
 
+func TestResolveEmbeddedEnvVar(t *testing.T) {
+	t.Setenv("TEST_SECRET_TOKEN", "abc123")
+
+	value, err := Resolve("Bearer ${TEST_SECRET_TOKEN}")
+	if err != nil {
+		t.Fatalf("Resolve failed: %v", err)
+	}
+	if value != "Bearer abc123" {
+		t.Errorf("value = %q, want %q", value, "Bearer abc123")
+	}
+
+	if _, err := Resolve("Bearer ${NONEXISTENT_SECRET_VAR_12345}"); err == nil {
+		t.Error("Expected error for unset env var")
+	}
+}
+
 func TestResolvePlainValue(t *testing.T) {
 	value, err := Resolve("plain-value")
 	if err != nil {
@@ -9,6 +9,9 @@ import (
 	"strings"
 
 	"gopkg.in/yaml.v3"
+
+	"github.com/iheanyi/agentctl/pkg/condition"
+	"github.com/iheanyi/agentctl/pkg/schema"
 )
 
 // InspectTitle returns the display name for the inspector modal header
@@ -85,6 +88,9 @@ type Skill struct {
 	Name        string `yaml:"name" json:"name"`
 	Description string `yaml:"description,omitempty" json:"description,omitempty"`
 
+	// When limits the skill to environments where the condition holds
+	When *condition.Condition `yaml:"when,omitempty" json:"when,omitempty"`
+
 	// Content is the markdown prompt content (after frontmatter) for the default command
 	Content string `yaml:"-" json:"-"`
 
@@ -108,6 +114,16 @@ type Skill struct {
 	Files   []string          `yaml:"files,omitempty" json:"files,omitempty"`
 }
 
+// FrontmatterSchema returns the JSON Schema of SKILL.md frontmatter
+func FrontmatterSchema() *schema.Schema {
+	return schema.Generate(Skill{}, schema.Options{
+		Name:        "skill",
+		Title:       "agentctl skill frontmatter",
+		Description: "YAML frontmatter of a skill's SKILL.md",
+		Tag:         "yaml",
+	})
+}
+
 // Load loads a skill from a directory
 // It first tries SKILL.md (Claude Code format), then falls back to skill.json
 // It also loads any additional .md files as subcommands
@@ -115,6 +131,11 @@ func Load(dir string) (*Skill, error) {
 	// Try SKILL.md first (Claude Code format)
 	skillMdPath := filepath.Join(dir, SkillFileName)
 	if data, err := os.ReadFile(skillMdPath); err == nil {
+		if schema.Strict {
+			if err := schema.ValidateFrontmatter(skillMdPath, data, FrontmatterSchema()); err != nil {
+				return nil, err
+			}
+		}
 		s, err := parseSkillMd(data)
 		if err != nil {
 			return nil, fmt.Errorf("parsing %s: %w", SkillFileName, err)
@@ -331,6 +352,11 @@ func (s *Skill) ToMarkdown() string {
 	if s.Description != "" {
 		buf.WriteString(fmt.Sprintf("description: %s\n", s.Description))
 	}
+	if !s.When.IsEmpty() {
+		if when, err := yaml.Marshal(map[string]*condition.Condition{"when": s.When}); err == nil {
+			buf.Write(when)
+		}
+	}
 	buf.WriteString("---\n\n")
 
 	// Write content
@@ -0,0 +1,109 @@
+package snapshot
+
+import (
+	"fmt"
+	"strings"
+)
+
+// diffContext is the number of unchanged lines shown around each change
+const diffContext = 3
+
+// LineDiff returns a unified diff of two file contents, without file
+// headers. It returns nil when the contents are equal.
+func LineDiff(before, after []byte) []string {
+	a := splitLines(string(before))
+	b := splitLines(string(after))
+
+	// Longest common subsequence table, filled from the end
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	type op struct {
+		kind byte // ' ', '-' or '+'
+		text string
+		ai   int // line number in a (1-based) for ' ' and '-'
+		bi   int // line number in b (1-based) for ' ' and '+'
+	}
+	var ops []op
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			ops = append(ops, op{' ', a[i], i + 1, j + 1})
+			i++
+			j++
+		case i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+			ops = append(ops, op{'-', a[i], i + 1, j})
+			i++
+		default:
+			ops = append(ops, op{'+', b[j], i, j + 1})
+			j++
+		}
+	}
+
+	// Group changes into hunks with surrounding context
+	var lines []string
+	for start := 0; start < len(ops); {
+		if ops[start].kind == ' ' {
+			start++
+			continue
+		}
+		from := max(start-diffContext, 0)
+		end := start
+		for k := start; k < len(ops); k++ {
+			if ops[k].kind != ' ' {
+				end = k
+			} else if k-end > 2*diffContext {
+				break
+			}
+		}
+		to := min(end+diffContext+1, len(ops))
+
+		var aStart, aLen, bStart, bLen int
+		var body []string
+		for _, o := range ops[from:to] {
+			if o.kind != '+' {
+				if aLen == 0 {
+					aStart = o.ai
+				}
+				aLen++
+			}
+			if o.kind != '-' {
+				if bLen == 0 {
+					bStart = o.bi
+				}
+				bLen++
+			}
+			body = append(body, string(o.kind)+o.text)
+		}
+		if aLen == 0 {
+			aStart = ops[from].ai
+		}
+		if bLen == 0 {
+			bStart = ops[from].bi
+		}
+		lines = append(lines, fmt.Sprintf("@@ -%d,%d +%d,%d @@", aStart, aLen, bStart, bLen))
+		lines = append(lines, body...)
+		start = to
+	}
+	return lines
+}
+
+// splitLines splits content into lines, ignoring a trailing newline
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
@@ -0,0 +1,528 @@
+// Package snapshot keeps point-in-time copies of the files agentctl writes
+// into tool configurations, so any sync can be inspected and rolled back.
+//
+// A store lives under the cache directory:
+//
+//	snapshots/<id>.json        snapshot manifests
+//	snapshots/objects/ab/abcd… file contents, addressed by SHA-256
+//
+// A snapshot records the roots (files and directories) each tool's sync
+// touches and the content of every file under them before the sync ran.
+// Contents are shared between snapshots, so unchanged files cost nothing.
+package snapshot
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Change statuses
+const (
+	StatusAdded    = "added"
+	StatusModified = "modified"
+	StatusRemoved  = "removed"
+)
+
+// Root is a file or directory a tool's sync writes to
+type Root struct {
+	Tool string `json:"tool"`
+	Path string `json:"path"`
+}
+
+// File is the content of a file when the snapshot was taken
+type File struct {
+	Tool string      `json:"tool"`
+	Path string      `json:"path"`
+	Hash string      `json:"hash"`
+	Mode fs.FileMode `json:"mode"`
+}
+
+// Change is a difference between a snapshot and another state of its
+// files. Before and After are content hashes; Before is empty for added
+// files and After is empty for removed ones.
+type Change struct {
+	Tool   string `json:"tool"`
+	Path   string `json:"path"`
+	Status string `json:"status"`
+	Before string `json:"before,omitempty"`
+	After  string `json:"after,omitempty"`
+}
+
+// Snapshot is the state of every file touched by one sync
+type Snapshot struct {
+	ID    string    `json:"id"`
+	Time  time.Time `json:"time"`
+	Roots []Root    `json:"roots"`
+	Files []File    `json:"files"`
+
+	// Changes are the files the sync itself changed, recorded by Finish
+	Changes []Change `json:"changes,omitempty"`
+}
+
+// Tools returns the tools recorded in the snapshot, sorted
+func (s *Snapshot) Tools() []string {
+	seen := make(map[string]bool)
+	var tools []string
+	for _, r := range s.Roots {
+		if !seen[r.Tool] {
+			seen[r.Tool] = true
+			tools = append(tools, r.Tool)
+		}
+	}
+	sort.Strings(tools)
+	return tools
+}
+
+// HasTool reports whether the snapshot recorded files for tool
+func (s *Snapshot) HasTool(tool string) bool {
+	for _, r := range s.Roots {
+		if r.Tool == tool {
+			return true
+		}
+	}
+	return false
+}
+
+// Retention controls which snapshots Prune keeps. Zero values disable a
+// limit.
+type Retention struct {
+	Keep   int           // Keep at most this many snapshots
+	MaxAge time.Duration // Drop snapshots older than this
+}
+
+// DefaultKeep is the number of snapshots kept when no retention is
+// configured
+const DefaultKeep = 20
+
+// ParseMaxAge parses a retention age such as "720h" or "30d"
+func ParseMaxAge(s string) (time.Duration, error) {
+	if s == "" {
+		return 0, nil
+	}
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid max age %q", s)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid max age %q: use a duration like 72h or 30d", s)
+	}
+	return d, nil
+}
+
+// NewID returns a sync ID for a snapshot taken at t: a UTC timestamp
+// with a random suffix
+func NewID(t time.Time) string {
+	b := make([]byte, 2)
+	_, _ = rand.Read(b)
+	return t.UTC().Format("20060102-150405") + "-" + hex.EncodeToString(b)
+}
+
+// Store is a snapshot store rooted at Dir
+type Store struct {
+	Dir string
+}
+
+// NewStore returns the snapshot store in cacheDir
+func NewStore(cacheDir string) *Store {
+	return &Store{Dir: filepath.Join(cacheDir, "snapshots")}
+}
+
+// Begin starts a new, unsaved snapshot
+func (s *Store) Begin(id string) *Snapshot {
+	return &Snapshot{ID: id, Time: time.Now()}
+}
+
+// Capture records the current content of paths for tool. A path may be a
+// file or a directory (captured recursively) and need not exist yet.
+func (s *Store) Capture(snap *Snapshot, tool string, paths ...string) error {
+	for _, path := range paths {
+		if path == "" {
+			continue
+		}
+		path = filepath.Clean(path)
+		root := Root{Tool: tool, Path: path}
+		if containsRoot(snap.Roots, root) {
+			continue
+		}
+		snap.Roots = append(snap.Roots, root)
+
+		files, err := walkRoot(path)
+		if err != nil {
+			return err
+		}
+		for _, file := range files {
+			if snap.file(tool, file) != nil {
+				continue
+			}
+			hash, mode, err := s.put(file)
+			if err != nil {
+				return fmt.Errorf("failed to snapshot %s: %w", file, err)
+			}
+			snap.Files = append(snap.Files, File{Tool: tool, Path: file, Hash: hash, Mode: mode})
+		}
+	}
+	return nil
+}
+
+// Finish records what changed since the snapshot was captured, keeping
+// the new content too, and saves it. Snapshots of syncs that changed
+// nothing are not kept; Finish reports whether the snapshot was saved.
+func (s *Store) Finish(snap *Snapshot) (bool, error) {
+	changes, err := s.Diff(snap, "")
+	if err != nil {
+		return false, err
+	}
+	if len(changes) == 0 {
+		return false, nil
+	}
+	for _, c := range changes {
+		if c.After == "" {
+			continue
+		}
+		if _, _, err := s.put(c.Path); err != nil {
+			return false, fmt.Errorf("failed to snapshot %s: %w", c.Path, err)
+		}
+	}
+	snap.Changes = changes
+	return true, s.Save(snap)
+}
+
+// Save writes the snapshot manifest
+func (s *Store) Save(snap *Snapshot) error {
+	if err := os.MkdirAll(s.Dir, 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(snap, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(s.Dir, snap.ID+".json"), data, 0644)
+}
+
+// List returns all snapshots, newest first
+func (s *Store) List() ([]*Snapshot, error) {
+	entries, err := os.ReadDir(s.Dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var snaps []*Snapshot
+	for _, e := range entries {
+		id, ok := strings.CutSuffix(e.Name(), ".json")
+		if !ok || e.IsDir() {
+			continue
+		}
+		snap, err := s.load(id)
+		if err != nil {
+			return nil, err
+		}
+		snaps = append(snaps, snap)
+	}
+	sort.Slice(snaps, func(i, j int) bool {
+		if !snaps[i].Time.Equal(snaps[j].Time) {
+			return snaps[i].Time.After(snaps[j].Time)
+		}
+		return snaps[i].ID > snaps[j].ID
+	})
+	return snaps, nil
+}
+
+// Load returns the snapshot with the given ID or unique ID prefix
+func (s *Store) Load(id string) (*Snapshot, error) {
+	if snap, err := s.load(id); err == nil {
+		return snap, nil
+	}
+
+	snaps, err := s.List()
+	if err != nil {
+		return nil, err
+	}
+	var match *Snapshot
+	for _, snap := range snaps {
+		if strings.HasPrefix(snap.ID, id) {
+			if match != nil {
+				return nil, fmt.Errorf("snapshot ID %q is ambiguous", id)
+			}
+			match = snap
+		}
+	}
+	if match == nil {
+		return nil, fmt.Errorf("snapshot %q not found", id)
+	}
+	return match, nil
+}
+
+func (s *Store) load(id string) (*Snapshot, error) {
+	data, err := os.ReadFile(filepath.Join(s.Dir, id+".json"))
+	if err != nil {
+		return nil, err
+	}
+	var snap Snapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return nil, fmt.Errorf("invalid snapshot %s: %w", id, err)
+	}
+	return &snap, nil
+}
+
+// Read returns the content stored under hash
+func (s *Store) Read(hash string) ([]byte, error) {
+	return os.ReadFile(s.objectPath(hash))
+}
+
+// Diff compares a snapshot with the files on disk now. Files created
+// under a snapshot root since are reported as added. An empty tool
+// compares every tool.
+func (s *Store) Diff(snap *Snapshot, tool string) ([]Change, error) {
+	var changes []Change
+	for _, root := range snap.Roots {
+		if tool != "" && root.Tool != tool {
+			continue
+		}
+		current, err := walkRoot(root.Path)
+		if err != nil {
+			return nil, err
+		}
+		onDisk := make(map[string]bool)
+		for _, path := range current {
+			onDisk[path] = true
+			hash, err := hashFile(path)
+			if err != nil {
+				return nil, err
+			}
+			prev := snap.file(root.Tool, path)
+			switch {
+			case prev == nil:
+				changes = append(changes, Change{Tool: root.Tool, Path: path, Status: StatusAdded, After: hash})
+			case prev.Hash != hash:
+				changes = append(changes, Change{Tool: root.Tool, Path: path, Status: StatusModified, Before: prev.Hash, After: hash})
+			}
+		}
+		for _, f := range snap.Files {
+			if f.Tool == root.Tool && within(root.Path, f.Path) && !onDisk[f.Path] {
+				changes = append(changes, Change{Tool: f.Tool, Path: f.Path, Status: StatusRemoved, Before: f.Hash})
+			}
+		}
+	}
+	return dedupeChanges(changes), nil
+}
+
+// Restore puts a tool's files (every tool's when tool is empty) back to
+// their state in the snapshot: changed and removed files are rewritten
+// and files created since are deleted. It returns the changes undone.
+func (s *Store) Restore(snap *Snapshot, tool string) ([]Change, error) {
+	changes, err := s.Diff(snap, tool)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, c := range changes {
+		if c.Status == StatusAdded {
+			if err := os.Remove(c.Path); err != nil && !os.IsNotExist(err) {
+				return nil, fmt.Errorf("failed to remove %s: %w", c.Path, err)
+			}
+			continue
+		}
+		f := snap.file(c.Tool, c.Path)
+		data, err := s.Read(f.Hash)
+		if err != nil {
+			return nil, fmt.Errorf("snapshot content for %s is missing: %w", c.Path, err)
+		}
+		if err := os.MkdirAll(filepath.Dir(c.Path), 0755); err != nil {
+			return nil, err
+		}
+		if err := os.WriteFile(c.Path, data, f.Mode.Perm()|0600); err != nil {
+			return nil, fmt.Errorf("failed to restore %s: %w", c.Path, err)
+		}
+	}
+	return changes, nil
+}
+
+// Prune deletes snapshots outside the retention policy, then any stored
+// content no remaining snapshot refers to. It returns the deleted IDs.
+func (s *Store) Prune(r Retention) ([]string, error) {
+	snaps, err := s.List()
+	if err != nil {
+		return nil, err
+	}
+
+	var removed []string
+	var kept []*Snapshot
+	for i, snap := range snaps {
+		tooMany := r.Keep > 0 && i >= r.Keep
+		tooOld := r.MaxAge > 0 && time.Since(snap.Time) > r.MaxAge
+		if !tooMany && !tooOld {
+			kept = append(kept, snap)
+			continue
+		}
+		if err := os.Remove(filepath.Join(s.Dir, snap.ID+".json")); err != nil && !os.IsNotExist(err) {
+			return removed, err
+		}
+		removed = append(removed, snap.ID)
+	}
+
+	if len(removed) > 0 {
+		if err := s.collectGarbage(kept); err != nil {
+			return removed, err
+		}
+	}
+	return removed, nil
+}
+
+// collectGarbage removes objects not referenced by snaps
+func (s *Store) collectGarbage(snaps []*Snapshot) error {
+	live := make(map[string]bool)
+	for _, snap := range snaps {
+		for _, f := range snap.Files {
+			live[f.Hash] = true
+		}
+		for _, c := range snap.Changes {
+			live[c.After] = true
+		}
+	}
+
+	objects := filepath.Join(s.Dir, "objects")
+	return filepath.WalkDir(objects, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() || live[d.Name()] {
+			return nil
+		}
+		return os.Remove(path)
+	})
+}
+
+// put stores a file's content and returns its hash and mode
+func (s *Store) put(path string) (string, fs.FileMode, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", 0, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", 0, err
+	}
+
+	hash := hashBytes(data)
+	obj := s.objectPath(hash)
+	if _, err := os.Stat(obj); err == nil {
+		return hash, info.Mode().Perm(), nil
+	}
+	if err := os.MkdirAll(filepath.Dir(obj), 0755); err != nil {
+		return "", 0, err
+	}
+	tmp := obj + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return "", 0, err
+	}
+	if err := os.Rename(tmp, obj); err != nil {
+		os.Remove(tmp)
+		return "", 0, err
+	}
+	return hash, info.Mode().Perm(), nil
+}
+
+func (s *Store) objectPath(hash string) string {
+	if len(hash) < 2 {
+		return filepath.Join(s.Dir, "objects", hash)
+	}
+	return filepath.Join(s.Dir, "objects", hash[:2], hash)
+}
+
+// file returns the recorded file for tool at path, or nil
+func (snap *Snapshot) file(tool, path string) *File {
+	for i := range snap.Files {
+		if snap.Files[i].Tool == tool && snap.Files[i].Path == path {
+			return &snap.Files[i]
+		}
+	}
+	return nil
+}
+
+// walkRoot lists the regular files at or under path. A missing path has
+// no files.
+func walkRoot(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
+
+	var files []string
+	err = filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.Type().IsRegular() {
+			files = append(files, p)
+		}
+		return nil
+	})
+	return files, err
+}
+
+// within reports whether path is root or inside it
+func within(root, path string) bool {
+	return path == root || strings.HasPrefix(path, root+string(filepath.Separator))
+}
+
+func containsRoot(roots []Root, r Root) bool {
+	for _, existing := range roots {
+		if existing == r {
+			return true
+		}
+	}
+	return false
+}
+
+// dedupeChanges drops repeats from overlapping roots of the same tool
+func dedupeChanges(changes []Change) []Change {
+	seen := make(map[string]bool)
+	var out []Change
+	for _, c := range changes {
+		key := c.Tool + "\x00" + c.Path
+		if !seen[key] {
+			seen[key] = true
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+func hashFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return hashBytes(data), nil
+}
+
+func hashBytes(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,266 @@
+package snapshot
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestSnapshotCaptureDiffRestore(t *testing.T) {
+	dir := t.TempDir()
+	store := NewStore(filepath.Join(dir, "cache"))
+
+	config := filepath.Join(dir, "tool", "settings.json")
+	commands := filepath.Join(dir, "tool", "commands")
+	writeFile(t, config, `{"a": 1}`)
+	writeFile(t, filepath.Join(commands, "old.md"), "old")
+	writeFile(t, filepath.Join(commands, "gone.md"), "gone")
+
+	snap := store.Begin("20260101-000000-aaaa")
+	if err := store.Capture(snap, "tool", config, commands); err != nil {
+		t.Fatalf("Capture() error = %v", err)
+	}
+	if len(snap.Files) != 3 {
+		t.Fatalf("captured %d files, want 3", len(snap.Files))
+	}
+
+	// Simulate a sync
+	writeFile(t, config, `{"a": 2}`)
+	writeFile(t, filepath.Join(commands, "new.md"), "new")
+	os.Remove(filepath.Join(commands, "gone.md"))
+
+	saved, err := store.Finish(snap)
+	if err != nil || !saved {
+		t.Fatalf("Finish() = %v, %v", saved, err)
+	}
+
+	statuses := make(map[string]string)
+	for _, c := range snap.Changes {
+		statuses[filepath.Base(c.Path)] = c.Status
+	}
+	want := map[string]string{"settings.json": StatusModified, "new.md": StatusAdded, "gone.md": StatusRemoved}
+	if len(statuses) != len(want) {
+		t.Fatalf("changes = %v, want %v", statuses, want)
+	}
+	for name, status := range want {
+		if statuses[name] != status {
+			t.Errorf("%s status = %q, want %q", name, statuses[name], status)
+		}
+	}
+
+	// The sync's new content is kept for diffs
+	for _, c := range snap.Changes {
+		if c.After == "" {
+			continue
+		}
+		if _, err := store.Read(c.After); err != nil {
+			t.Errorf("content of %s after sync not stored: %v", c.Path, err)
+		}
+	}
+
+	loaded, err := store.Load("20260101")
+	if err != nil {
+		t.Fatalf("Load(prefix) error = %v", err)
+	}
+	if loaded.ID != snap.ID || len(loaded.Changes) != 3 {
+		t.Errorf("Load() = %+v", loaded)
+	}
+
+	restored, err := store.Restore(loaded, "")
+	if err != nil {
+		t.Fatalf("Restore() error = %v", err)
+	}
+	if len(restored) != 3 {
+		t.Errorf("Restore() undid %d changes, want 3", len(restored))
+	}
+	if got := readFile(t, config); got != `{"a": 1}` {
+		t.Errorf("config = %q after restore", got)
+	}
+	if got := readFile(t, filepath.Join(commands, "gone.md")); got != "gone" {
+		t.Errorf("gone.md = %q after restore", got)
+	}
+	if _, err := os.Stat(filepath.Join(commands, "new.md")); !os.IsNotExist(err) {
+		t.Errorf("new.md should be removed by restore")
+	}
+
+	if changes, _ := store.Diff(loaded, ""); len(changes) != 0 {
+		t.Errorf("Diff() after restore = %v, want none", changes)
+	}
+}
+
+func TestSnapshotRestoreTool(t *testing.T) {
+	dir := t.TempDir()
+	store := NewStore(filepath.Join(dir, "cache"))
+
+	a := filepath.Join(dir, "a.json")
+	b := filepath.Join(dir, "b.json")
+	writeFile(t, a, "a1")
+	writeFile(t, b, "b1")
+
+	snap := store.Begin(NewID(time.Now()))
+	if err := store.Capture(snap, "alpha", a); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Capture(snap, "beta", b); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, a, "a2")
+	writeFile(t, b, "b2")
+
+	if _, err := store.Restore(snap, "alpha"); err != nil {
+		t.Fatalf("Restore() error = %v", err)
+	}
+	if got := readFile(t, a); got != "a1" {
+		t.Errorf("alpha file = %q, want restored", got)
+	}
+	if got := readFile(t, b); got != "b2" {
+		t.Errorf("beta file = %q, want untouched", got)
+	}
+	if got := snap.Tools(); strings.Join(got, ",") != "alpha,beta" {
+		t.Errorf("Tools() = %v", got)
+	}
+}
+
+func TestSnapshotFinishUnchanged(t *testing.T) {
+	dir := t.TempDir()
+	store := NewStore(filepath.Join(dir, "cache"))
+	path := filepath.Join(dir, "config.json")
+	writeFile(t, path, "same")
+
+	snap := store.Begin(NewID(time.Now()))
+	if err := store.Capture(snap, "tool", path); err != nil {
+		t.Fatal(err)
+	}
+	saved, err := store.Finish(snap)
+	if err != nil || saved {
+		t.Fatalf("Finish() = %v, %v; want unsaved", saved, err)
+	}
+	if snaps, _ := store.List(); len(snaps) != 0 {
+		t.Errorf("List() = %d snapshots, want 0", len(snaps))
+	}
+}
+
+func TestSnapshotPrune(t *testing.T) {
+	dir := t.TempDir()
+	store := NewStore(filepath.Join(dir, "cache"))
+	path := filepath.Join(dir, "config.json")
+
+	ids := []string{"20260101-000000-0001", "20260102-000000-0002", "20260103-000000-0003"}
+	for i, id := range ids {
+		writeFile(t, path, "version "+id)
+		snap := store.Begin(id)
+		snap.Time = time.Now().Add(time.Duration(i-len(ids)) * 24 * time.Hour)
+		if err := store.Capture(snap, "tool", path); err != nil {
+			t.Fatal(err)
+		}
+		writeFile(t, path, "synced "+id)
+		if _, err := store.Finish(snap); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	removed, err := store.Prune(Retention{Keep: 2})
+	if err != nil {
+		t.Fatalf("Prune() error = %v", err)
+	}
+	if len(removed) != 1 || removed[0] != ids[0] {
+		t.Errorf("Prune(Keep: 2) removed %v, want [%s]", removed, ids[0])
+	}
+
+	// Content only the pruned snapshot referred to is gone
+	var objects int
+	filepath.WalkDir(filepath.Join(store.Dir, "objects"), func(p string, d os.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			objects++
+		}
+		return nil
+	})
+	if objects != 4 {
+		t.Errorf("%d objects left after prune, want 4", objects)
+	}
+
+	removed, err = store.Prune(Retention{MaxAge: 36 * time.Hour})
+	if err != nil {
+		t.Fatalf("Prune() error = %v", err)
+	}
+	if len(removed) != 1 || removed[0] != ids[1] {
+		t.Errorf("Prune(MaxAge) removed %v, want [%s]", removed, ids[1])
+	}
+}
+
+func TestLoadAmbiguous(t *testing.T) {
+	store := NewStore(t.TempDir())
+	for _, id := range []string{"20260101-000000-0001", "20260101-000000-0002"} {
+		if err := store.Save(&Snapshot{ID: id}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := store.Load("20260101"); err == nil || !strings.Contains(err.Error(), "ambiguous") {
+		t.Errorf("Load(ambiguous) error = %v", err)
+	}
+	if _, err := store.Load("2025"); err == nil {
+		t.Error("Load(missing) should fail")
+	}
+}
+
+func TestParseMaxAge(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"", 0, false},
+		{"30d", 30 * 24 * time.Hour, false},
+		{"72h", 72 * time.Hour, false},
+		{"xd", 0, true},
+		{"soon", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseMaxAge(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseMaxAge(%q) = %v, %v", tt.in, got, err)
+		}
+	}
+}
+
+func TestLineDiff(t *testing.T) {
+	before := "a\nb\nc\nd\ne\nf\ng\nh\ni\nj\n"
+	after := "a\nb\nc\nd\nE\nf\ng\nh\ni\nj\nk\n"
+
+	got := strings.Join(LineDiff([]byte(before), []byte(after)), "\n")
+	want := strings.Join([]string{
+		"@@ -2,9 +2,10 @@",
+		" b", " c", " d", "-e", "+E", " f", " g", " h", " i", " j", "+k",
+	}, "\n")
+	if got != want {
+		t.Errorf("LineDiff() =\n%s\nwant\n%s", got, want)
+	}
+
+	if lines := LineDiff([]byte("same\n"), []byte("same\n")); lines != nil {
+		t.Errorf("LineDiff(equal) = %v, want nil", lines)
+	}
+	if got := LineDiff(nil, []byte("new\n")); strings.Join(got, "|") != "@@ -0,0 +1,1 @@|+new" {
+		t.Errorf("LineDiff(added) = %v", got)
+	}
+}
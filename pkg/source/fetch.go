@@ -0,0 +1,270 @@
+package source
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// State records what was last fetched for a source
+type State struct {
+	URL       string    `json:"url"`
+	ETag      string    `json:"etag,omitempty"`   // HTTP sources
+	Commit    string    `json:"commit,omitempty"` // Git sources
+	FetchedAt time.Time `json:"fetchedAt"`
+}
+
+// HTTPClient is used to fetch HTTP sources
+var HTTPClient = &http.Client{Timeout: 30 * time.Second}
+
+// stateFilePath returns the path of the fetch state file
+func stateFilePath(cacheDir string) string {
+	return filepath.Join(cacheDir, "sources", "state.json")
+}
+
+// LoadState loads the fetch state of all sources
+func LoadState(cacheDir string) (map[string]*State, error) {
+	data, err := os.ReadFile(stateFilePath(cacheDir))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return make(map[string]*State), nil
+		}
+		return nil, err
+	}
+
+	states := make(map[string]*State)
+	if err := json.Unmarshal(data, &states); err != nil {
+		return nil, err
+	}
+	return states, nil
+}
+
+// SaveState saves the fetch state of all sources
+func SaveState(cacheDir string, states map[string]*State) error {
+	path := stateFilePath(cacheDir)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(states, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// Fetch brings a source's cached copy up to date. prev is the state from
+// the last fetch (nil if never fetched); the returned bool reports whether
+// the content changed. Git sources are shallow-cloned and tracked by
+// commit; HTTP sources use the ETag to skip unchanged downloads.
+func Fetch(s *Source, cacheDir string, prev *State) (*State, bool, error) {
+	if err := s.Validate(); err != nil {
+		return nil, false, err
+	}
+	// A URL change invalidates the cached copy
+	if prev != nil && prev.URL != s.URL {
+		prev = nil
+	}
+
+	switch s.Kind() {
+	case TypeGit:
+		return fetchGit(s, s.Dir(cacheDir), prev)
+	case TypeHTTP:
+		return fetchHTTP(s, s.Dir(cacheDir), prev)
+	default:
+		if _, err := os.Stat(s.Dir(cacheDir)); err != nil {
+			return nil, false, fmt.Errorf("source %q: %w", s.Name, err)
+		}
+		return &State{URL: s.URL, FetchedAt: time.Now()}, prev == nil, nil
+	}
+}
+
+// Remove deletes a source's cached copy
+func Remove(s *Source, cacheDir string) error {
+	if s.Kind() == TypeDir {
+		return nil
+	}
+	return os.RemoveAll(s.Dir(cacheDir))
+}
+
+// fetchGit clones the repository or fast-forwards an existing clone
+func fetchGit(s *Source, dir string, prev *State) (*State, bool, error) {
+	if _, err := os.Stat(filepath.Join(dir, ".git")); err != nil || prev == nil {
+		if err := os.RemoveAll(dir); err != nil {
+			return nil, false, err
+		}
+		if err := os.MkdirAll(filepath.Dir(dir), 0755); err != nil {
+			return nil, false, err
+		}
+		args := []string{"clone", "--depth", "1"}
+		if s.Ref != "" {
+			args = append(args, "--branch", s.Ref)
+		}
+		args = append(args, s.URL, dir)
+		if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			return nil, false, fmt.Errorf("git clone failed: %w\n%s", err, out)
+		}
+	} else {
+		ref := s.Ref
+		if ref == "" {
+			ref = "HEAD"
+		}
+		if out, err := exec.Command("git", "-C", dir, "fetch", "--depth", "1", "origin", ref).CombinedOutput(); err != nil {
+			return nil, false, fmt.Errorf("git fetch failed: %w\n%s", err, out)
+		}
+		if out, err := exec.Command("git", "-C", dir, "reset", "--hard", "FETCH_HEAD").CombinedOutput(); err != nil {
+			return nil, false, fmt.Errorf("git reset failed: %w\n%s", err, out)
+		}
+	}
+
+	out, err := exec.Command("git", "-C", dir, "rev-parse", "HEAD").Output()
+	if err != nil {
+		return nil, false, fmt.Errorf("git rev-parse failed: %w", err)
+	}
+	state := &State{URL: s.URL, Commit: strings.TrimSpace(string(out)), FetchedAt: time.Now()}
+	return state, prev == nil || prev.Commit != state.Commit, nil
+}
+
+// fetchHTTP downloads an aliases.json file, or a .tar.gz archive laid out
+// like a source directory
+func fetchHTTP(s *Source, dir string, prev *State) (*State, bool, error) {
+	req, err := http.NewRequest(http.MethodGet, s.URL, nil)
+	if err != nil {
+		return nil, false, err
+	}
+	if prev != nil && prev.ETag != "" {
+		if _, err := os.Stat(dir); err == nil {
+			req.Header.Set("If-None-Match", prev.ETag)
+		}
+	}
+
+	resp, err := HTTPClient.Do(req)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to fetch %s: %w", s.URL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified {
+		state := *prev
+		state.FetchedAt = time.Now()
+		return &state, false, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, false, fmt.Errorf("failed to fetch %s: status %d", s.URL, resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to fetch %s: %w", s.URL, err)
+	}
+
+	// Unpack into a fresh directory and swap it in, so a bad download
+	// doesn't destroy the last good copy
+	tmp := dir + ".tmp"
+	if err := os.RemoveAll(tmp); err != nil {
+		return nil, false, err
+	}
+	if err := os.MkdirAll(tmp, 0755); err != nil {
+		return nil, false, err
+	}
+	if isArchive(s.URL, body) {
+		err = extractTarGz(body, tmp)
+	} else {
+		var aliases map[string]json.RawMessage
+		if err = json.Unmarshal(body, &aliases); err != nil {
+			err = fmt.Errorf("%s is not an aliases.json file or .tar.gz archive: %w", s.URL, err)
+		} else {
+			err = os.WriteFile(filepath.Join(tmp, AliasesFile), body, 0644)
+		}
+	}
+	if err != nil {
+		os.RemoveAll(tmp)
+		return nil, false, err
+	}
+	if err := os.RemoveAll(dir); err != nil {
+		return nil, false, err
+	}
+	if err := os.Rename(tmp, dir); err != nil {
+		return nil, false, err
+	}
+
+	return &State{URL: s.URL, ETag: resp.Header.Get("ETag"), FetchedAt: time.Now()}, true, nil
+}
+
+// isArchive reports whether a download is a gzipped tarball
+func isArchive(url string, body []byte) bool {
+	return strings.HasSuffix(url, ".tar.gz") || strings.HasSuffix(url, ".tgz") ||
+		bytes.HasPrefix(body, []byte{0x1f, 0x8b})
+}
+
+// extractTarGz unpacks a gzipped tarball into dir. A single top-level
+// directory (as produced by GitHub release archives) is stripped.
+func extractTarGz(data []byte, dir string) error {
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("invalid archive: %w", err)
+	}
+	defer gz.Close()
+
+	type file struct {
+		name string
+		mode os.FileMode
+		data []byte
+	}
+	var files []file
+	prefix := ""
+	single := true
+
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("invalid archive: %w", err)
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		name := filepath.ToSlash(filepath.Clean(hdr.Name))
+		if filepath.IsAbs(name) || name == ".." || strings.HasPrefix(name, "../") {
+			return fmt.Errorf("invalid path in archive: %s", hdr.Name)
+		}
+		top, _, nested := strings.Cut(name, "/")
+		if !nested || (prefix != "" && top != prefix) {
+			single = false
+		}
+		prefix = top
+
+		content, err := io.ReadAll(tr)
+		if err != nil {
+			return fmt.Errorf("invalid archive: %w", err)
+		}
+		files = append(files, file{name: name, mode: os.FileMode(hdr.Mode).Perm(), data: content})
+	}
+
+	for _, f := range files {
+		name := f.name
+		if single {
+			name = strings.TrimPrefix(name, prefix+"/")
+		}
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, f.data, f.mode|0600); err != nil {
+			return err
+		}
+	}
+	return nil
+}
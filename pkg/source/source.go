@@ -0,0 +1,151 @@
+// Package source manages team and private sources of aliases and
+// resources. A source is a git repository, an HTTP URL or a local
+// directory laid out like the agentctl config directory:
+//
+//	aliases.json   server aliases
+//	commands/      slash commands
+//	rules/         rules
+//	skills/        skills
+//	agents/        agents
+//
+// Git and HTTP sources are fetched into the cache directory; directory
+// sources are read in place.
+package source
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Source types
+const (
+	TypeGit  = "git"
+	TypeHTTP = "http"
+	TypeDir  = "dir"
+)
+
+// AliasesFile is the alias file a source ships at its root
+const AliasesFile = "aliases.json"
+
+// Source is a configured alias and resource source
+type Source struct {
+	Name     string `json:"name"`
+	Type     string `json:"type,omitempty" jsonschema:"enum=git|http|dir"` // git, http or dir (inferred from the URL when empty)
+	URL      string `json:"url"`                                           // Git URL, HTTP URL or local directory
+	Ref      string `json:"ref,omitempty"`                                 // Git branch or tag (default branch when empty)
+	Priority int    `json:"priority,omitempty"`
+}
+
+// Kind returns the source type, inferring it from the URL when unset.
+// HTTP URLs ending in .git are git repositories.
+func (s *Source) Kind() string {
+	if s.Type != "" {
+		return s.Type
+	}
+	switch {
+	case strings.HasPrefix(s.URL, "git@"), strings.HasSuffix(s.URL, ".git"),
+		strings.HasPrefix(s.URL, "ssh://"), strings.HasPrefix(s.URL, "git://"):
+		return TypeGit
+	case strings.HasPrefix(s.URL, "http://"), strings.HasPrefix(s.URL, "https://"):
+		return TypeHTTP
+	default:
+		return TypeDir
+	}
+}
+
+// Validate checks that the source has a usable name, type and URL
+func (s *Source) Validate() error {
+	if s.Name == "" {
+		return fmt.Errorf("source name is required")
+	}
+	if strings.ContainsAny(s.Name, `/\ `) || s.Name == "." || s.Name == ".." {
+		return fmt.Errorf("invalid source name %q", s.Name)
+	}
+	if s.URL == "" {
+		return fmt.Errorf("source %q is missing a URL", s.Name)
+	}
+	switch s.Kind() {
+	case TypeGit, TypeHTTP, TypeDir:
+	default:
+		return fmt.Errorf("unknown source type %q", s.Type)
+	}
+	return nil
+}
+
+// Dir returns the local directory holding the source's content
+func (s *Source) Dir(cacheDir string) string {
+	if s.Kind() == TypeDir {
+		path := s.URL
+		if strings.HasPrefix(path, "~/") {
+			if home, err := os.UserHomeDir(); err == nil {
+				path = filepath.Join(home, path[2:])
+			}
+		}
+		return path
+	}
+	return filepath.Join(cacheDir, "sources", s.Name)
+}
+
+// Sorted returns sources from highest to lowest priority; sources with
+// equal priority keep their configured order
+func Sorted(sources []*Source) []*Source {
+	sorted := make([]*Source, len(sources))
+	copy(sorted, sources)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Priority > sorted[j].Priority
+	})
+	return sorted
+}
+
+// Find returns the source with the given name, or nil
+func Find(sources []*Source, name string) *Source {
+	for _, s := range sources {
+		if s.Name == name {
+			return s
+		}
+	}
+	return nil
+}
+
+// Resource is a command, rule, skill or agent shipped by a source
+type Resource struct {
+	Type string // "command", "rule", "skill" or "agent"
+	Name string
+}
+
+// ListResources lists the resources in a source directory by file name,
+// without parsing them
+func ListResources(dir string) []Resource {
+	var resources []Resource
+	for _, kind := range []struct{ typ, subdir string }{
+		{"command", "commands"},
+		{"rule", "rules"},
+		{"skill", "skills"},
+		{"agent", "agents"},
+	} {
+		entries, err := os.ReadDir(filepath.Join(dir, kind.subdir))
+		if err != nil {
+			continue
+		}
+		for _, e := range entries {
+			name := e.Name()
+			switch {
+			case strings.HasPrefix(name, "."):
+				continue
+			case kind.typ == "skill":
+				if !e.IsDir() {
+					continue
+				}
+			case e.IsDir():
+				continue
+			default:
+				name = strings.TrimSuffix(name, filepath.Ext(name))
+			}
+			resources = append(resources, Resource{Type: kind.typ, Name: name})
+		}
+	}
+	return resources
+}
@@ -0,0 +1,202 @@
+package source
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestKind(t *testing.T) {
+	tests := []struct {
+		src  Source
+		want string
+	}{
+		{Source{URL: "git@github.com:acme/agentctl.git"}, TypeGit},
+		{Source{URL: "https://github.com/acme/agentctl.git"}, TypeGit},
+		{Source{URL: "https://tools.acme.dev/aliases.json"}, TypeHTTP},
+		{Source{URL: "~/src/team"}, TypeDir},
+		{Source{URL: "https://github.com/acme/agentctl", Type: TypeGit}, TypeGit},
+	}
+	for _, tt := range tests {
+		if got := tt.src.Kind(); got != tt.want {
+			t.Errorf("Kind(%q) = %q, want %q", tt.src.URL, got, tt.want)
+		}
+	}
+
+	if err := (&Source{Name: "../x", URL: "/tmp"}).Validate(); err == nil {
+		t.Error("Validate() should reject path-like names")
+	}
+}
+
+func TestSorted(t *testing.T) {
+	sources := []*Source{{Name: "a"}, {Name: "b", Priority: 10}, {Name: "c"}, {Name: "d", Priority: -1}}
+	var names []string
+	for _, s := range Sorted(sources) {
+		names = append(names, s.Name)
+	}
+	if want := []string{"b", "a", "c", "d"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("Sorted() = %v, want %v", names, want)
+	}
+}
+
+func TestListResources(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "commands", "review.md"), "# review")
+	writeFile(t, filepath.Join(dir, "rules", "house-style.md"), "# style")
+	writeFile(t, filepath.Join(dir, "skills", "deploy", "SKILL.md"), "# deploy")
+	writeFile(t, filepath.Join(dir, "agents", "oncall.md"), "# oncall")
+
+	want := []Resource{
+		{Type: "command", Name: "review"},
+		{Type: "rule", Name: "house-style"},
+		{Type: "skill", Name: "deploy"},
+		{Type: "agent", Name: "oncall"},
+	}
+	if got := ListResources(dir); !reflect.DeepEqual(got, want) {
+		t.Errorf("ListResources() = %+v, want %+v", got, want)
+	}
+}
+
+func TestFetchHTTPETag(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte(`{"team-db": {"url": "github.com/acme/db-mcp"}}`))
+	}))
+	defer server.Close()
+
+	cacheDir := t.TempDir()
+	src := &Source{Name: "team", URL: server.URL + "/aliases.json"}
+
+	state, changed, err := Fetch(src, cacheDir, nil)
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	if !changed || state.ETag != `"v1"` {
+		t.Errorf("first Fetch() = %+v, changed %v", state, changed)
+	}
+	if _, err := os.Stat(filepath.Join(src.Dir(cacheDir), AliasesFile)); err != nil {
+		t.Errorf("aliases.json not cached: %v", err)
+	}
+
+	state, changed, err = Fetch(src, cacheDir, state)
+	if err != nil {
+		t.Fatalf("second Fetch() error = %v", err)
+	}
+	if changed || state.ETag != `"v1"` || requests != 2 {
+		t.Errorf("second Fetch() = %+v, changed %v after %d requests", state, changed, requests)
+	}
+
+	// State round-trips through the state file
+	if err := SaveState(cacheDir, map[string]*State{"team": state}); err != nil {
+		t.Fatalf("SaveState() error = %v", err)
+	}
+	states, err := LoadState(cacheDir)
+	if err != nil || states["team"].ETag != `"v1"` {
+		t.Errorf("LoadState() = %+v, %v", states, err)
+	}
+}
+
+func TestFetchHTTPArchive(t *testing.T) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for name, content := range map[string]string{
+		"team-main/aliases.json":   `{}`,
+		"team-main/rules/house.md": "# house rules",
+	} {
+		tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg})
+		tw.Write([]byte(content))
+	}
+	tw.Close()
+	gz.Close()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(buf.Bytes())
+	}))
+	defer server.Close()
+
+	cacheDir := t.TempDir()
+	src := &Source{Name: "team", URL: server.URL + "/team.tar.gz"}
+	if _, _, err := Fetch(src, cacheDir, nil); err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(src.Dir(cacheDir), "rules", "house.md")); err != nil {
+		t.Errorf("archive not extracted with its top-level directory stripped: %v", err)
+	}
+}
+
+func TestFetchGit(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	repo := t.TempDir()
+	writeFile(t, filepath.Join(repo, AliasesFile), `{}`)
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", repo, "-c", "user.name=t", "-c", "user.email=t@example.com"}, args...)...)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	git("init", "-q")
+	git("add", ".")
+	git("commit", "-q", "-m", "initial")
+
+	cacheDir := t.TempDir()
+	src := &Source{Name: "team", URL: "file://" + repo, Type: TypeGit}
+
+	state, changed, err := Fetch(src, cacheDir, nil)
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	if !changed || state.Commit == "" {
+		t.Errorf("first Fetch() = %+v, changed %v", state, changed)
+	}
+
+	_, changed, err = Fetch(src, cacheDir, state)
+	if err != nil {
+		t.Fatalf("second Fetch() error = %v", err)
+	}
+	if changed {
+		t.Error("second Fetch() without new commits should report no change")
+	}
+
+	writeFile(t, filepath.Join(repo, "rules", "new.md"), "# new")
+	git("add", ".")
+	git("commit", "-q", "-m", "add rule")
+
+	next, changed, err := Fetch(src, cacheDir, state)
+	if err != nil {
+		t.Fatalf("third Fetch() error = %v", err)
+	}
+	if !changed || next.Commit == state.Commit {
+		t.Errorf("third Fetch() = %+v, changed %v; want new commit", next, changed)
+	}
+	if _, err := os.Stat(filepath.Join(src.Dir(cacheDir), "rules", "new.md")); err != nil {
+		t.Errorf("new commit not checked out: %v", err)
+	}
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -5,7 +5,11 @@ import (
 
 	"github.com/iheanyi/agentctl/pkg/agent"
 	"github.com/iheanyi/agentctl/pkg/command"
+	"github.com/iheanyi/agentctl/pkg/container"
+	"github.com/iheanyi/agentctl/pkg/interp"
 	"github.com/iheanyi/agentctl/pkg/mcp"
+	"github.com/iheanyi/agentctl/pkg/permission"
+	"github.com/iheanyi/agentctl/pkg/plugin"
 	"github.com/iheanyi/agentctl/pkg/rule"
 	"github.com/iheanyi/agentctl/pkg/skill"
 )
@@ -19,6 +23,9 @@ const (
 	ResourceRules    ResourceType = "rules"
 	ResourceSkills   ResourceType = "skills"
 	ResourceAgents   ResourceType = "agents"
+
+	ResourcePermissions ResourceType = "permissions"
+	ResourcePlugins     ResourceType = "plugins"
 )
 
 // ManagedMarker is the key used to mark entries managed by agentctl
@@ -105,6 +112,48 @@ func SupportsWorkspace(a Adapter) bool {
 	return ok && wa.SupportsWorkspace()
 }
 
+// WorkspaceResourcesAdapter is an optional interface for adapters whose tool
+// also reads commands, rules, skills or agents from a project directory,
+// e.g. .claude/commands or .cursor/rules. Writers for resource types not
+// listed in WorkspaceResources do nothing.
+type WorkspaceResourcesAdapter interface {
+	Adapter
+
+	// WorkspaceResources returns the resource types the tool reads from a project
+	WorkspaceResources() []ResourceType
+
+	// WorkspacePaths returns the files and directories the workspace writers
+	// touch in a project, for snapshots
+	WorkspacePaths(projectDir string) []string
+
+	// WriteWorkspaceCommands writes commands to the project
+	WriteWorkspaceCommands(projectDir string, commands []*command.Command) error
+
+	// WriteWorkspaceRules writes rules to the project
+	WriteWorkspaceRules(projectDir string, rules []*rule.Rule) error
+
+	// WriteWorkspaceSkills writes skills to the project
+	WriteWorkspaceSkills(projectDir string, skills []*skill.Skill) error
+
+	// WriteWorkspaceAgents writes agents to the project
+	WriteWorkspaceAgents(projectDir string, agents []*agent.Agent) error
+}
+
+// SupportsWorkspaceResource checks if an adapter can write a resource type
+// to a project
+func SupportsWorkspaceResource(a Adapter, rt ResourceType) bool {
+	wa, ok := a.(WorkspaceResourcesAdapter)
+	if !ok {
+		return false
+	}
+	for _, r := range wa.WorkspaceResources() {
+		if r == rt {
+			return true
+		}
+	}
+	return false
+}
+
 // SkillsAdapter is an optional interface for adapters that support skills/plugins.
 // Skills are directory-based configurations with SKILL.md files that define
 // slash commands, prompts, and other tool extensions.
@@ -134,6 +183,131 @@ type AgentsAdapter interface {
 	WriteAgents(agents []*agent.Agent) error
 }
 
+// PermissionsAdapter is an optional interface for adapters that support
+// tool permission policies (allow/deny/ask). Patterns are exchanged in the
+// canonical tool vocabulary; adapters translate them to their native format.
+type PermissionsAdapter interface {
+	Adapter
+
+	// ReadPermissions reads the tool's permission policy
+	ReadPermissions() (*permission.Permissions, error)
+
+	// WritePermissions replaces agentctl-managed permission entries,
+	// preserving entries the user added manually
+	WritePermissions(perms *permission.Permissions) error
+}
+
+// ToolFilterAdapter is an optional interface for adapters whose tool can't
+// express every entry of a server's enabledTools and disabledTools. Those
+// entries are left out of its config.
+type ToolFilterAdapter interface {
+	Adapter
+
+	// SkippedToolFilters returns the entries WriteServers leaves out, as
+	// server:pattern
+	SkippedToolFilters(servers []*mcp.Server) []string
+}
+
+// SkippedToolFilters returns the tool filter entries an adapter leaves out
+// of its config
+func SkippedToolFilters(a Adapter, servers []*mcp.Server) []string {
+	tf, ok := a.(ToolFilterAdapter)
+	if !ok {
+		return nil
+	}
+	return tf.SkippedToolFilters(servers)
+}
+
+// PermissionsFilterAdapter is an optional interface for permissions
+// adapters whose tool can't express every pattern exactly. Those patterns
+// are left out rather than written more broadly than they were given.
+type PermissionsFilterAdapter interface {
+	PermissionsAdapter
+
+	// SkippedPermissions returns the patterns WritePermissions leaves out
+	SkippedPermissions(perms *permission.Permissions) []string
+}
+
+// PermissionsFileAdapter is an optional interface for permissions adapters
+// that keep agentctl's entries in a file of their own, so they're managed
+// even when sync state records none.
+type PermissionsFileAdapter interface {
+	PermissionsAdapter
+
+	// OwnsPermissionsFile returns true if agentctl owns the file its
+	// permissions are written to
+	OwnsPermissionsFile() bool
+}
+
+// SkippedPermissions returns the permission patterns an adapter leaves out
+// of its config
+func SkippedPermissions(a Adapter, perms *permission.Permissions) []string {
+	pf, ok := a.(PermissionsFilterAdapter)
+	if !ok || perms.IsEmpty() {
+		return nil
+	}
+	return pf.SkippedPermissions(perms)
+}
+
+// OwnsPermissionsFile checks if an adapter keeps agentctl's permission
+// entries in a file of their own
+func OwnsPermissionsFile(a Adapter) bool {
+	pf, ok := a.(PermissionsFileAdapter)
+	return ok && pf.OwnsPermissionsFile()
+}
+
+// PluginsAdapter is an optional interface for adapters that install tool
+// plugins. Adapters only touch plugins they can express (marketplace
+// plugins for Claude Code, npm plugins for OpenCode).
+type PluginsAdapter interface {
+	Adapter
+
+	// ReadPlugins reads the tool's configured plugins and marketplaces
+	ReadPlugins() ([]*plugin.Plugin, map[string]*plugin.Marketplace, error)
+
+	// WritePlugins replaces agentctl-managed plugin entries, preserving
+	// plugins the user installed manually
+	WritePlugins(plugins []*plugin.Plugin, marketplaces map[string]*plugin.Marketplace) error
+}
+
+// PathsAdapter is an optional interface for adapters that write files
+// outside their config file, such as command, rule and agent directories.
+// The snapshot store records these paths before every sync.
+type PathsAdapter interface {
+	Adapter
+
+	// ManagedPaths returns the files and directories written by sync,
+	// excluding ConfigPath
+	ManagedPaths() []string
+}
+
+// StdioOnlyAdapter is an optional interface for adapters whose tool can
+// only launch stdio servers. Remote servers are left out of its config
+// unless a Bridge runs them through 'agentctl mcp-proxy'.
+type StdioOnlyAdapter interface {
+	Adapter
+
+	// StdioOnly returns true if the tool can't connect to HTTP or SSE servers
+	StdioOnly() bool
+}
+
+// SupportsRemoteServers checks if an adapter's tool connects to HTTP and
+// SSE servers itself
+func SupportsRemoteServers(a Adapter) bool {
+	so, ok := a.(StdioOnlyAdapter)
+	return !ok || !so.StdioOnly()
+}
+
+// TouchedPaths returns every file and directory a sync may write for the
+// adapter: its config file plus any managed paths
+func TouchedPaths(a Adapter) []string {
+	paths := []string{a.ConfigPath()}
+	if pa, ok := a.(PathsAdapter); ok {
+		paths = append(paths, pa.ManagedPaths()...)
+	}
+	return paths
+}
+
 // AsServerAdapter returns the adapter as a ServerAdapter if supported
 func AsServerAdapter(a Adapter) (ServerAdapter, bool) {
 	sa, ok := a.(ServerAdapter)
@@ -194,6 +368,30 @@ func SupportsAgents(a Adapter) bool {
 	return ok
 }
 
+// AsPermissionsAdapter returns the adapter as a PermissionsAdapter if supported
+func AsPermissionsAdapter(a Adapter) (PermissionsAdapter, bool) {
+	pa, ok := a.(PermissionsAdapter)
+	return pa, ok
+}
+
+// SupportsPermissions checks if an adapter implements PermissionsAdapter
+func SupportsPermissions(a Adapter) bool {
+	_, ok := a.(PermissionsAdapter)
+	return ok
+}
+
+// AsPluginsAdapter returns the adapter as a PluginsAdapter if supported
+func AsPluginsAdapter(a Adapter) (PluginsAdapter, bool) {
+	pa, ok := a.(PluginsAdapter)
+	return pa, ok
+}
+
+// SupportsPlugins checks if an adapter implements PluginsAdapter
+func SupportsPlugins(a Adapter) bool {
+	_, ok := a.(PluginsAdapter)
+	return ok
+}
+
 // AsWorkspaceAdapter returns the adapter as a WorkspaceAdapter if supported
 func AsWorkspaceAdapter(a Adapter) (WorkspaceAdapter, bool) {
 	wa, ok := a.(WorkspaceAdapter)
@@ -203,6 +401,12 @@ func AsWorkspaceAdapter(a Adapter) (WorkspaceAdapter, bool) {
 	return wa, true
 }
 
+// AsWorkspaceResourcesAdapter returns the adapter as a WorkspaceResourcesAdapter if supported
+func AsWorkspaceResourcesAdapter(a Adapter) (WorkspaceResourcesAdapter, bool) {
+	wa, ok := a.(WorkspaceResourcesAdapter)
+	return wa, ok
+}
+
 // Registry holds all registered adapters
 var registry = make(map[string]Adapter)
 
@@ -245,12 +449,22 @@ type SyncResult struct {
 	Changes int // Number of changes made
 }
 
-// SyncAll syncs configuration to all detected tools
-func SyncAll(servers []*mcp.Server, commands []*command.Command, rules []*rule.Rule, skills []*skill.Skill, agents []*agent.Agent) []SyncResult {
+// SyncAll syncs configuration to all detected tools, recording a
+// snapshot of the files it touches. Variables in servers, commands and
+// rules are resolved per tool with vars (nil leaves them as written), and
+// remote servers are bridged for stdio-only tools with bridge (nil leaves
+// them out).
+func SyncAll(vars *interp.Context, bridge *Bridge, servers []*mcp.Server, commands []*command.Command, rules []*rule.Rule, skills []*skill.Skill, agents []*agent.Agent) []SyncResult {
 	adapters := Detected()
 	results := make([]SyncResult, len(adapters))
 	var wg sync.WaitGroup
 
+	// Snapshot every adapter's files before the concurrent writes start
+	store, snap := BeginSnapshot()
+	for _, adapter := range adapters {
+		_ = CaptureAdapter(store, snap, adapter)
+	}
+
 	for i, adapter := range adapters {
 		wg.Add(1)
 		go func(i int, adapter Adapter) {
@@ -260,7 +474,8 @@ func SyncAll(servers []*mcp.Server, commands []*command.Command, rules []*rule.R
 			// Sync servers if adapter supports it
 			if len(servers) > 0 {
 				if sa, ok := AsServerAdapter(adapter); ok {
-					if err := sa.WriteServers(servers); err != nil {
+					toolServers, _ := bridge.Servers(adapter, container.Commands(vars.Servers(adapter.Name(), servers)))
+					if err := sa.WriteServers(toolServers); err != nil {
 						result.Error = err
 					} else {
 						result.Changes += len(servers)
@@ -271,7 +486,7 @@ func SyncAll(servers []*mcp.Server, commands []*command.Command, rules []*rule.R
 			// Sync commands if adapter supports it
 			if len(commands) > 0 {
 				if ca, ok := AsCommandsAdapter(adapter); ok {
-					if err := ca.WriteCommands(commands); err != nil {
+					if err := ca.WriteCommands(vars.Commands(adapter.Name(), commands)); err != nil {
 						if result.Error == nil {
 							result.Error = err
 						}
@@ -284,7 +499,7 @@ func SyncAll(servers []*mcp.Server, commands []*command.Command, rules []*rule.R
 			// Sync rules if adapter supports it
 			if len(rules) > 0 {
 				if ra, ok := AsRulesAdapter(adapter); ok {
-					if err := ra.WriteRules(rules); err != nil {
+					if err := ra.WriteRules(vars.Rules(adapter.Name(), rules)); err != nil {
 						if result.Error == nil {
 							result.Error = err
 						}
@@ -326,6 +541,7 @@ func SyncAll(servers []*mcp.Server, commands []*command.Command, rules []*rule.R
 	}
 
 	wg.Wait()
+	_, _ = FinishSnapshot(store, snap)
 	return results
 }
 
@@ -339,11 +555,12 @@ func containsResource(resources []ResourceType, target ResourceType) bool {
 }
 
 // FilterStdioServers returns only servers that use stdio transport
-// Use this for tools that don't support HTTP/SSE remote MCP servers
+// Use this for tools that don't support HTTP/SSE remote MCP servers, and
+// implement StdioOnlyAdapter so they can be bridged
 func FilterStdioServers(servers []*mcp.Server) []*mcp.Server {
 	var filtered []*mcp.Server
 	for _, s := range servers {
-		if s.Transport != mcp.TransportHTTP && s.Transport != mcp.TransportSSE {
+		if !IsRemote(s) {
 			filtered = append(filtered, s)
 		}
 	}
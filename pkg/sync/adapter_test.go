@@ -4,18 +4,23 @@ import (
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
 	"testing"
 
 	"github.com/iheanyi/agentctl/pkg/agent"
 	"github.com/iheanyi/agentctl/pkg/command"
 	"github.com/iheanyi/agentctl/pkg/mcp"
+	"github.com/iheanyi/agentctl/pkg/rule"
+	"github.com/iheanyi/agentctl/pkg/skill"
 )
 
 func TestAdapterRegistry(t *testing.T) {
 	// All adapters should be auto-registered via init()
 	adapters := All()
 
-	expectedAdapters := []string{"claude", "cursor", "windsurf", "cline", "continue", "zed", "codex", "opencode", "copilot", "gemini", "claude-desktop"}
+	expectedAdapters := []string{"claude", "cursor", "windsurf", "cline", "continue", "zed", "codex", "opencode", "copilot", "gemini", "claude-desktop", "vscode", "roo", "goose", "amp", "kiro"}
 
 	for _, name := range expectedAdapters {
 		found := false
@@ -916,6 +921,59 @@ func TestCodexAdapter(t *testing.T) {
 	}
 }
 
+func TestCodexTOMLServerFields(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	mem := NewMemFS(nil)
+	adapter, _ := WithFS(&CodexAdapter{}, mem)
+	codex := adapter.(*CodexAdapter)
+	mem.WriteFile(codex.tomlConfigPath(), []byte("model = \"o3\"\n"), 0644)
+
+	server := &mcp.Server{
+		Name:           "search",
+		Command:        "search-mcp",
+		StartupTimeout: 30,
+		ToolTimeout:    120,
+		EnabledTools:   []string{"query", "fetch"},
+		DisabledTools:  []string{"fetch_raw", "debug_*"},
+	}
+	if err := codex.WriteServers([]*mcp.Server{server}); err != nil {
+		t.Fatalf("WriteServers failed: %v", err)
+	}
+
+	servers, err := codex.ReadServers()
+	if err != nil || len(servers) != 1 {
+		t.Fatalf("ReadServers = %v, %v", servers, err)
+	}
+	got := servers[0]
+	if got.StartupTimeout != 30 || got.ToolTimeout != 120 || len(got.EnabledTools) != 2 || got.EnabledTools[1] != "fetch" {
+		t.Errorf("ReadServers round trip = %+v", got)
+	}
+	// Codex matches names exactly, so globs are left out
+	if len(got.DisabledTools) != 1 || got.DisabledTools[0] != "fetch_raw" {
+		t.Errorf("disabled_tools = %v, want [fetch_raw]", got.DisabledTools)
+	}
+	server.EnabledTools = []string{"query", "get_*"}
+	if err := codex.WriteServers([]*mcp.Server{server}); err != nil {
+		t.Fatalf("WriteServers failed: %v", err)
+	}
+	if servers, _ = codex.ReadServers(); len(servers[0].EnabledTools) != 0 {
+		t.Errorf("enabled_tools with a glob = %v, want none", servers[0].EnabledTools)
+	}
+	if got, want := SkippedToolFilters(codex, []*mcp.Server{server}), []string{"search:query", "search:get_*", "search:debug_*"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("SkippedToolFilters() = %v, want %v", got, want)
+	}
+
+	// Removing every server removes the managed table
+	if err := codex.WriteServers(nil); err != nil {
+		t.Fatalf("WriteServers failed: %v", err)
+	}
+	if data, _ := mem.ReadFile(codex.tomlConfigPath()); string(data) != "model = \"o3\"\n" {
+		t.Errorf("config after removing servers = %q", data)
+	}
+}
+
 func TestParseCodexPrompt(t *testing.T) {
 	tests := []struct {
 		name            string
@@ -1138,9 +1196,162 @@ func TestFilterStdioServers(t *testing.T) {
 // Workspace Adapter Tests
 // ============================================
 
+func TestVSCodeSecretInputs(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	mem := NewMemFS(nil)
+	wrapped, err := WithFS(&VSCodeAdapter{}, mem)
+	if err != nil {
+		t.Fatalf("WithFS failed: %v", err)
+	}
+	adapter := wrapped.(*VSCodeAdapter)
+
+	servers := []*mcp.Server{
+		{
+			Name:    "github",
+			Command: "github-mcp",
+			Env:     map[string]string{"GITHUB_TOKEN": "keychain:github-token"},
+			EnvFile: "${workspaceFolder}/.env",
+		},
+		{
+			Name:      "sentry",
+			Transport: mcp.TransportHTTP,
+			URL:       "https://mcp.sentry.dev/mcp",
+			Headers:   map[string]string{"Authorization": "keychain:sentry-auth"},
+		},
+	}
+	if err := adapter.WriteServers(servers); err != nil {
+		t.Fatalf("WriteServers failed: %v", err)
+	}
+
+	data, err := mem.ReadFile(adapter.ConfigPath())
+	if err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	for _, want := range []string{`"${input:github-token}"`, `"id": "sentry-auth"`, `"password": true`, `"type": "promptString"`} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("mcp.json missing %s:\n%s", want, data)
+		}
+	}
+
+	got, err := adapter.ReadServers()
+	if err != nil {
+		t.Fatalf("ReadServers failed: %v", err)
+	}
+	byName := make(map[string]*mcp.Server)
+	for _, s := range got {
+		byName[s.Name] = s
+	}
+	if gh := byName["github"]; gh == nil || gh.Env["GITHUB_TOKEN"] != "keychain:github-token" || gh.EnvFile != "${workspaceFolder}/.env" {
+		t.Errorf("github = %+v", gh)
+	}
+	if sentry := byName["sentry"]; sentry == nil || sentry.Transport != mcp.TransportHTTP || sentry.Headers["Authorization"] != "keychain:sentry-auth" {
+		t.Errorf("sentry = %+v", sentry)
+	}
+
+	// Inputs for secrets no longer referenced are removed
+	if err := adapter.WriteServers(servers[:1]); err != nil {
+		t.Fatalf("WriteServers failed: %v", err)
+	}
+	data, _ = mem.ReadFile(adapter.ConfigPath())
+	if strings.Contains(string(data), "sentry-auth") {
+		t.Errorf("stale input left behind:\n%s", data)
+	}
+}
+
+func TestKiroSteering(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	mem := NewMemFS(nil)
+	wrapped, err := WithFS(&KiroAdapter{}, mem)
+	if err != nil {
+		t.Fatalf("WithFS failed: %v", err)
+	}
+	adapter := wrapped.(*KiroAdapter)
+
+	rules := []*rule.Rule{
+		{Name: "style", Content: "Use tabs", Frontmatter: &rule.Frontmatter{Paths: []string{"*.go"}}},
+		{Name: "product", Content: "We build CLIs"},
+	}
+	if err := adapter.WriteRules(rules); err != nil {
+		t.Fatalf("WriteRules failed: %v", err)
+	}
+
+	data, _ := mem.ReadFile(filepath.Join(adapter.steeringDir(), "style.md"))
+	if want := "---\ninclusion: fileMatch\nfileMatchPattern: '*.go'\n---\n\nUse tabs"; string(data) != want {
+		t.Errorf("steering file = %q, want %q", data, want)
+	}
+
+	got, err := adapter.ReadRules()
+	if err != nil || len(got) != 2 {
+		t.Fatalf("ReadRules() = %v, %v", got, err)
+	}
+	byName := make(map[string]*rule.Rule)
+	for _, r := range got {
+		byName[r.Name] = r
+	}
+	if r := byName["style"]; r == nil || r.Content != "Use tabs" || r.Frontmatter == nil || len(r.Frontmatter.Paths) != 1 || r.Frontmatter.Paths[0] != "*.go" {
+		t.Errorf("style = %+v", r)
+	}
+	if r := byName["product"]; r == nil || r.Content != "We build CLIs" || r.Frontmatter != nil {
+		t.Errorf("product = %+v", r)
+	}
+}
+
+func TestGooseExtensions(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", "")
+	mem := NewMemFS(nil)
+	wrapped, err := WithFS(&GooseAdapter{}, mem)
+	if err != nil {
+		t.Fatalf("WithFS failed: %v", err)
+	}
+	adapter := wrapped.(*GooseAdapter)
+
+	config := "GOOSE_PROVIDER: openai\nextensions:\n  developer:\n    enabled: true\n    name: developer\n    type: builtin\n"
+	mem.WriteFile(adapter.ConfigPath(), []byte(config), 0644)
+
+	servers := []*mcp.Server{
+		{Name: "fs", Command: "fs-mcp", Args: []string{"."}, Env: map[string]string{"ROOT": "/"}},
+		{Name: "api", Transport: mcp.TransportHTTP, URL: "https://example.com/mcp", ToolTimeout: 60},
+	}
+	if err := adapter.WriteServers(servers); err != nil {
+		t.Fatalf("WriteServers failed: %v", err)
+	}
+
+	got, err := adapter.ReadServers()
+	if err != nil {
+		t.Fatalf("ReadServers failed: %v", err)
+	}
+	byName := make(map[string]*mcp.Server)
+	for _, s := range got {
+		byName[s.Name] = s
+	}
+	if len(got) != 2 || byName["developer"] != nil {
+		t.Errorf("ReadServers() = %+v, want fs and api without built-in extensions", got)
+	}
+	if fs := byName["fs"]; fs == nil || fs.Command != "fs-mcp" || fs.Env["ROOT"] != "/" || fs.Disabled {
+		t.Errorf("fs = %+v", fs)
+	}
+	if api := byName["api"]; api == nil || api.Transport != mcp.TransportHTTP || api.URL != "https://example.com/mcp" || api.ToolTimeout != 60 {
+		t.Errorf("api = %+v", api)
+	}
+
+	// A later sync drops the extensions it wrote and keeps everything else
+	if err := adapter.WriteServers(servers[:1]); err != nil {
+		t.Fatalf("WriteServers failed: %v", err)
+	}
+	data, _ := mem.ReadFile(adapter.ConfigPath())
+	for _, want := range []string{"GOOSE_PROVIDER: openai", "developer:", "fs:"} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("config.yaml missing %q:\n%s", want, data)
+		}
+	}
+	if strings.Contains(string(data), "api:") {
+		t.Errorf("stale extension left behind:\n%s", data)
+	}
+}
+
 func TestWorkspaceAdapterInterface(t *testing.T) {
-	// Claude and Cursor should support workspace configs
-	workspaceAdapters := []string{"claude", "cursor"}
+	workspaceAdapters := []string{"claude", "cursor", "gemini", "opencode", "zed", "codex", "vscode", "roo", "kiro"}
 
 	for _, name := range workspaceAdapters {
 		t.Run(name, func(t *testing.T) {
@@ -1164,7 +1375,7 @@ func TestWorkspaceAdapterInterface(t *testing.T) {
 	}
 
 	// Non-workspace adapters should return false
-	nonWorkspaceAdapters := []string{"codex", "opencode", "copilot"}
+	nonWorkspaceAdapters := []string{"cline", "windsurf", "claude-desktop", "goose", "amp", "copilot"}
 	for _, name := range nonWorkspaceAdapters {
 		t.Run(name+"_no_workspace", func(t *testing.T) {
 			adapter, ok := Get(name)
@@ -1179,6 +1390,132 @@ func TestWorkspaceAdapterInterface(t *testing.T) {
 	}
 }
 
+func TestWorkspaceServersRoundTrip(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	projectDir := filepath.Join(t.TempDir(), "repo")
+
+	servers := []*mcp.Server{
+		{Name: "fs", Command: "fs-mcp", Args: []string{"."}},
+		{Name: "docs", Command: "docs-mcp"},
+	}
+
+	for _, name := range []string{"gemini", "opencode", "zed", "codex", "vscode", "roo", "kiro"} {
+		t.Run(name, func(t *testing.T) {
+			mem := NewMemFS(nil)
+			registered, _ := Get(name)
+			adapter, err := WithFS(registered, mem)
+			if err != nil {
+				t.Fatalf("WithFS failed: %v", err)
+			}
+			wa, _ := AsWorkspaceAdapter(adapter)
+
+			if err := wa.WriteWorkspaceServers(projectDir, servers); err != nil {
+				t.Fatalf("WriteWorkspaceServers failed: %v", err)
+			}
+			got, err := wa.ReadWorkspaceServers(projectDir)
+			if err != nil || len(got) != 2 {
+				t.Fatalf("ReadWorkspaceServers = %v, %v", got, err)
+			}
+			for _, s := range got {
+				if s.Scope != "local" {
+					t.Errorf("server %q scope = %q, want local", s.Name, s.Scope)
+				}
+			}
+
+			// A later sync replaces the servers it wrote
+			if err := wa.WriteWorkspaceServers(projectDir, servers[:1]); err != nil {
+				t.Fatalf("WriteWorkspaceServers failed: %v", err)
+			}
+			got, _ = wa.ReadWorkspaceServers(projectDir)
+			if len(got) != 1 || got[0].Name != "fs" || got[0].Command != "fs-mcp" {
+				t.Errorf("after second sync = %+v", got)
+			}
+
+			// The global config is left alone
+			if _, err := mem.Stat(adapter.ConfigPath()); err == nil {
+				t.Errorf("global config %s was written", adapter.ConfigPath())
+			}
+		})
+	}
+}
+
+func TestWorkspaceResources(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	projectDir := t.TempDir()
+
+	commands := []*command.Command{{Name: "review", Prompt: "Review this"}}
+	rules := []*rule.Rule{{Name: "style", Content: "Use tabs", Frontmatter: &rule.Frontmatter{Paths: []string{"*.go"}}}}
+	skills := []*skill.Skill{{Name: "deploy", Description: "Deploy it", Content: "Run make deploy"}}
+	agents := []*agent.Agent{{Name: "helper", Description: "Helps", Content: "Help out"}}
+
+	tests := []struct {
+		adapter string
+		want    []string // Relative to the project
+	}{
+		{"claude", []string{".claude/commands/review.md", ".claude/rules/style.md", ".claude/skills/deploy/SKILL.md", ".claude/agents/helper.md"}},
+		{"cursor", []string{".cursor/commands/review.md", ".cursor/rules/style.mdc", ".cursor/agents/helper.md"}},
+		{"copilot", []string{".github/prompts/review.prompt.md", ".github/instructions/style.instructions.md", ".github/skills/deploy/SKILL.md", ".github/agents/helper.agent.md"}},
+		{"opencode", []string{".opencode/command/review.md", ".opencode/skill/deploy/SKILL.md", ".opencode/agent/helper.md"}},
+		{"roo", []string{".roo/rules/style.md"}},
+		{"kiro", []string{".kiro/steering/style.md"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.adapter, func(t *testing.T) {
+			mem := NewMemFS(nil)
+			registered, _ := Get(tt.adapter)
+			adapter, err := WithFS(registered, mem)
+			if err != nil {
+				t.Fatalf("WithFS failed: %v", err)
+			}
+			wa, ok := AsWorkspaceResourcesAdapter(adapter)
+			if !ok {
+				t.Fatalf("%s is not a WorkspaceResourcesAdapter", tt.adapter)
+			}
+
+			if err := wa.WriteWorkspaceCommands(projectDir, commands); err != nil {
+				t.Fatalf("WriteWorkspaceCommands failed: %v", err)
+			}
+			if err := wa.WriteWorkspaceRules(projectDir, rules); err != nil {
+				t.Fatalf("WriteWorkspaceRules failed: %v", err)
+			}
+			if err := wa.WriteWorkspaceSkills(projectDir, skills); err != nil {
+				t.Fatalf("WriteWorkspaceSkills failed: %v", err)
+			}
+			if err := wa.WriteWorkspaceAgents(projectDir, agents); err != nil {
+				t.Fatalf("WriteWorkspaceAgents failed: %v", err)
+			}
+
+			var written []string
+			for _, c := range mem.Changes() {
+				rel, err := filepath.Rel(projectDir, c.Path)
+				if err != nil || strings.HasPrefix(rel, "..") {
+					t.Errorf("wrote outside the project: %s", c.Path)
+					continue
+				}
+				written = append(written, filepath.ToSlash(rel))
+			}
+			sort.Strings(written)
+			want := append([]string(nil), tt.want...)
+			sort.Strings(want)
+			if strings.Join(written, " ") != strings.Join(want, " ") {
+				t.Errorf("wrote %v, want %v", written, want)
+			}
+		})
+	}
+
+	// Copilot instruction files scope rules with applyTo
+	mem := NewMemFS(nil)
+	copilot, _ := WithFS(&CopilotAdapter{}, mem)
+	if err := copilot.(*CopilotAdapter).WriteWorkspaceRules(projectDir, rules); err != nil {
+		t.Fatalf("WriteWorkspaceRules failed: %v", err)
+	}
+	data, _ := mem.ReadFile(filepath.Join(projectDir, ".github", "instructions", "style.instructions.md"))
+	if want := "---\napplyTo: \"*.go\"\n---\n\nUse tabs"; string(data) != want {
+		t.Errorf("instructions file = %q, want %q", data, want)
+	}
+}
+
 // ============================================
 // Empty Name Server Protection Tests
 // ============================================
@@ -0,0 +1,107 @@
+package sync
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"github.com/iheanyi/agentctl/pkg/mcp"
+)
+
+// AmpAdapter syncs MCP servers to Amp. Its settings.json keeps them under
+// the flat "amp.mcpServers" key.
+type AmpAdapter struct {
+	fsHolder
+}
+
+// ampServersKey is the settings key holding Amp's MCP servers
+const ampServersKey = "amp.mcpServers"
+
+func init() {
+	Register(&AmpAdapter{})
+}
+
+func (a *AmpAdapter) Name() string {
+	return "amp"
+}
+
+// WithFS returns a copy of the adapter that uses fsys
+func (a *AmpAdapter) WithFS(fsys FS) Adapter {
+	c := *a
+	c.fsys = fsys
+	return &c
+}
+
+func (a *AmpAdapter) Detect() (bool, error) {
+	path := a.ConfigPath()
+	if path == "" {
+		return false, nil
+	}
+
+	if _, err := a.fs().Stat(filepath.Dir(path)); os.IsNotExist(err) {
+		return false, nil
+	}
+
+	return true, nil
+}
+
+func (a *AmpAdapter) ConfigPath() string {
+	if runtime.GOOS == "windows" {
+		if appData := os.Getenv("APPDATA"); appData != "" {
+			return filepath.Join(appData, "amp", "settings.json")
+		}
+	}
+	if xdgConfig := os.Getenv("XDG_CONFIG_HOME"); xdgConfig != "" {
+		return filepath.Join(xdgConfig, "amp", "settings.json")
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(homeDir, ".config", "amp", "settings.json")
+}
+
+func (a *AmpAdapter) SupportedResources() []ResourceType {
+	return []ResourceType{ResourceMCP}
+}
+
+func (a *AmpAdapter) ReadServers() ([]*mcp.Server, error) {
+	raw, err := NewJSONConfigHelper(a.fs(), a.ConfigPath()).LoadRaw()
+	if err != nil {
+		return nil, err
+	}
+
+	mcpServers, _ := GetMCPServersSection(raw, ampServersKey)
+	var servers []*mcp.Server
+	for name, v := range mcpServers {
+		serverData, ok := v.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		servers = append(servers, remoteServerFromRawMap(name, serverData, ""))
+	}
+	return servers, nil
+}
+
+func (a *AmpAdapter) WriteServers(servers []*mcp.Server) error {
+	helper := NewJSONConfigHelper(a.fs(), a.ConfigPath())
+	raw, err := helper.LoadRaw()
+	if err != nil {
+		return err
+	}
+
+	mcpServers, _ := GetMCPServersSection(raw, ampServersKey)
+	RemoveManagedServers(mcpServers)
+
+	for _, server := range servers {
+		name := GetServerName(server)
+		if name == "" {
+			continue
+		}
+		mcpServers[name] = remoteServerToRawMap(server, "")
+	}
+
+	raw[ampServersKey] = mcpServers
+	return helper.SaveRaw(raw)
+}
@@ -0,0 +1,100 @@
+package sync
+
+import (
+	"os"
+	"os/exec"
+
+	"github.com/iheanyi/agentctl/pkg/config"
+	"github.com/iheanyi/agentctl/pkg/mcp"
+)
+
+// ProxyCommand is the agentctl subcommand bridged entries run
+const ProxyCommand = "mcp-proxy"
+
+// Bridge rewrites remote (HTTP or SSE) servers as stdio entries running
+// 'agentctl mcp-proxy <server>', for tools that can only launch stdio
+// servers. The proxy looks the server up in agentctl's config when it
+// starts, so URLs, headers and tokens stay out of the tool's config.
+type Bridge struct {
+	Executable string // agentctl binary the entries run
+	ProjectDir string // Project the proxy loads local servers from
+}
+
+// NewBridge returns a bridge running the agentctl on PATH, or this binary
+// if there isn't one
+func NewBridge(projectDir string) *Bridge {
+	exe, err := exec.LookPath("agentctl")
+	if err != nil {
+		if exe, err = os.Executable(); err != nil {
+			exe = "agentctl"
+		}
+	}
+	return &Bridge{Executable: exe, ProjectDir: projectDir}
+}
+
+// BridgeFor returns the bridge for cfg, or nil unless bridgeRemote is set
+func BridgeFor(cfg *config.Config) *Bridge {
+	if !cfg.Settings.BridgeRemote {
+		return nil
+	}
+	return NewBridge(cfg.ProjectDir())
+}
+
+// Servers returns servers as they should be written to a: remote servers
+// are bridged if a's tool can't connect to them, and the names of the
+// bridged entries are returned. A nil bridge returns servers unchanged.
+func (b *Bridge) Servers(a Adapter, servers []*mcp.Server) ([]*mcp.Server, []string) {
+	if b == nil || SupportsRemoteServers(a) {
+		return servers, nil
+	}
+
+	result := make([]*mcp.Server, len(servers))
+	var bridged []string
+	for i, s := range servers {
+		if !IsRemote(s) {
+			result[i] = s
+			continue
+		}
+		result[i] = b.server(s)
+		bridged = append(bridged, GetServerName(s))
+	}
+	return result, bridged
+}
+
+// server returns the stdio entry that proxies to s
+func (b *Bridge) server(s *mcp.Server) *mcp.Server {
+	args := []string{ProxyCommand}
+	if s.Scope == string(config.ScopeLocal) && b.ProjectDir != "" {
+		args = append(args, "--project", b.ProjectDir)
+	}
+	args = append(args, s.Name)
+
+	bridged := *s
+	bridged.Transport = mcp.TransportStdio
+	bridged.Command = b.Executable
+	bridged.Args = args
+	bridged.URL = ""
+	bridged.Headers = nil
+	bridged.Env = nil
+	return &bridged
+}
+
+// IsRemote reports whether a server is reached over HTTP or SSE
+func IsRemote(s *mcp.Server) bool {
+	return s.Transport == mcp.TransportHTTP || s.Transport == mcp.TransportSSE
+}
+
+// RemoteServers returns the names of the remote servers a stdio-only
+// adapter leaves out when they aren't bridged
+func RemoteServers(a Adapter, servers []*mcp.Server) []string {
+	if SupportsRemoteServers(a) {
+		return nil
+	}
+	var names []string
+	for _, s := range servers {
+		if IsRemote(s) {
+			names = append(names, GetServerName(s))
+		}
+	}
+	return names
+}
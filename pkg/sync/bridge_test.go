@@ -0,0 +1,71 @@
+package sync
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/iheanyi/agentctl/pkg/mcp"
+)
+
+func TestBridgeServers(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	servers := []*mcp.Server{
+		{Name: "fs", Command: "fs-mcp"},
+		{Name: "sentry", Transport: mcp.TransportHTTP, URL: "https://mcp.sentry.dev/mcp", Headers: map[string]string{"Authorization": "Bearer secret"}},
+		{Name: "figma", Namespace: "design", Transport: mcp.TransportSSE, URL: "https://figma.example/sse", Scope: "local", ToolTimeout: 60},
+	}
+	bridge := &Bridge{Executable: "/usr/local/bin/agentctl", ProjectDir: "/src/app"}
+	cursor, _ := Get("cursor")
+	claude, _ := Get("claude")
+
+	// Tools that connect to remote servers get them as they are
+	if got, bridged := bridge.Servers(claude, servers); !reflect.DeepEqual(got, servers) || bridged != nil {
+		t.Errorf("Servers(claude) bridged %v", bridged)
+	}
+
+	// Without a bridge, stdio-only tools leave remote servers out
+	var none *Bridge
+	if got, bridged := none.Servers(cursor, servers); !reflect.DeepEqual(got, servers) || bridged != nil {
+		t.Errorf("nil bridge changed servers: %v", bridged)
+	}
+	if skipped := RemoteServers(cursor, servers); !reflect.DeepEqual(skipped, []string{"sentry", "design"}) {
+		t.Errorf("RemoteServers(cursor) = %v", skipped)
+	}
+
+	got, bridged := bridge.Servers(cursor, servers)
+	if !reflect.DeepEqual(bridged, []string{"sentry", "design"}) {
+		t.Errorf("bridged = %v, want sentry and design", bridged)
+	}
+	if got[0] != servers[0] {
+		t.Error("stdio server should be passed through")
+	}
+	sentry := got[1]
+	if sentry.Command != "/usr/local/bin/agentctl" || !reflect.DeepEqual(sentry.Args, []string{"mcp-proxy", "sentry"}) {
+		t.Errorf("sentry entry = %s %v", sentry.Command, sentry.Args)
+	}
+	if IsRemote(sentry) || sentry.URL != "" || sentry.Headers != nil {
+		t.Errorf("bridged entry kept remote fields: %+v", sentry)
+	}
+	// Local servers are looked up in the project, under their config name
+	figma := got[2]
+	if !reflect.DeepEqual(figma.Args, []string{"mcp-proxy", "--project", "/src/app", "figma"}) || figma.Namespace != "design" || figma.ToolTimeout != 60 {
+		t.Errorf("figma entry = %+v", figma)
+	}
+	if servers[1].URL == "" {
+		t.Error("Servers() modified its input")
+	}
+
+	// The bridged entries are written like any stdio server
+	fsys := NewMemFS(nil)
+	wrapped, err := WithFS(cursor, fsys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := wrapped.(ServerAdapter).WriteServers(got); err != nil {
+		t.Fatalf("WriteServers failed: %v", err)
+	}
+	read, err := wrapped.(ServerAdapter).ReadServers()
+	if err != nil || len(read) != 3 {
+		t.Fatalf("ReadServers() = %v, %v", read, err)
+	}
+}
@@ -5,18 +5,24 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/iheanyi/agentctl/pkg/agent"
 	"github.com/iheanyi/agentctl/pkg/command"
+	"github.com/iheanyi/agentctl/pkg/jsonc"
 	"github.com/iheanyi/agentctl/pkg/mcp"
+	"github.com/iheanyi/agentctl/pkg/permission"
+	"github.com/iheanyi/agentctl/pkg/plugin"
 	"github.com/iheanyi/agentctl/pkg/rule"
 	"github.com/iheanyi/agentctl/pkg/skill"
 )
 
 // ClaudeAdapter syncs configuration to Claude Code CLI (~/.claude/)
 // This is the default "claude" adapter as Claude Code is the primary developer tool
-type ClaudeAdapter struct{}
+type ClaudeAdapter struct {
+	fsHolder
+}
 
 // ClaudeCodeSettings represents Claude Code's settings.json structure
 type ClaudeCodeSettings struct {
@@ -52,6 +58,13 @@ func (a *ClaudeAdapter) Name() string {
 	return "claude"
 }
 
+// WithFS returns a copy of the adapter that uses fsys
+func (a *ClaudeAdapter) WithFS(fsys FS) Adapter {
+	c := *a
+	c.fsys = fsys
+	return &c
+}
+
 func (a *ClaudeAdapter) Detect() (bool, error) {
 	path := a.configDir()
 	if path == "" {
@@ -59,7 +72,7 @@ func (a *ClaudeAdapter) Detect() (bool, error) {
 	}
 
 	// Check if ~/.claude/ exists
-	if _, err := os.Stat(path); os.IsNotExist(err) {
+	if _, err := a.fs().Stat(path); os.IsNotExist(err) {
 		return false, nil
 	}
 
@@ -94,8 +107,16 @@ func (a *ClaudeAdapter) agentsDir() string {
 	return filepath.Join(a.configDir(), "agents")
 }
 
+// ManagedPaths returns the command, rule, skill and agent directories and
+// the plugin marketplace registry. The rest of the plugins directory holds
+// Claude Code's own marketplace clones and plugin caches, which a restore
+// mustn't delete.
+func (a *ClaudeAdapter) ManagedPaths() []string {
+	return []string{a.commandsDir(), a.rulesDir(), filepath.Join(a.configDir(), "skills"), a.agentsDir(), a.knownMarketplacesPath()}
+}
+
 func (a *ClaudeAdapter) SupportedResources() []ResourceType {
-	return []ResourceType{ResourceMCP, ResourceCommands, ResourceRules, ResourceSkills, ResourceAgents}
+	return []ResourceType{ResourceMCP, ResourceCommands, ResourceRules, ResourceSkills, ResourceAgents, ResourcePermissions, ResourcePlugins}
 }
 
 func (a *ClaudeAdapter) ReadServers() ([]*mcp.Server, error) {
@@ -164,13 +185,16 @@ func (a *ClaudeAdapter) WriteServers(servers []*mcp.Server) error {
 		settings.MCPServers[name] = cfg
 	}
 
-	return a.saveSettings(settings)
+	if err := a.saveSettings(settings); err != nil {
+		return err
+	}
+	return a.writeToolDenials(a.ConfigPath(), a.Name(), servers)
 }
 
 func (a *ClaudeAdapter) ReadCommands() ([]*command.Command, error) {
 	commandsDir := a.commandsDir()
 
-	entries, err := os.ReadDir(commandsDir)
+	entries, err := a.fs().ReadDir(commandsDir)
 	if err != nil {
 		if os.IsNotExist(err) {
 			return nil, nil
@@ -185,7 +209,7 @@ func (a *ClaudeAdapter) ReadCommands() ([]*command.Command, error) {
 		}
 
 		path := filepath.Join(commandsDir, entry.Name())
-		data, err := os.ReadFile(path)
+		data, err := a.fs().ReadFile(path)
 		if err != nil {
 			continue
 		}
@@ -197,18 +221,22 @@ func (a *ClaudeAdapter) ReadCommands() ([]*command.Command, error) {
 		}
 	}
 
+	commandsToCanonical(toolTranslator(a), commands)
 	return commands, nil
 }
 
 func (a *ClaudeAdapter) WriteCommands(commands []*command.Command) error {
-	commandsDir := a.commandsDir()
+	return a.writeCommands(a.commandsDir(), commands)
+}
 
+// writeCommands writes commands as markdown files to commandsDir
+func (a *ClaudeAdapter) writeCommands(commandsDir string, commands []*command.Command) error {
 	// Ensure directory exists
-	if err := os.MkdirAll(commandsDir, 0755); err != nil {
+	if err := a.fs().MkdirAll(commandsDir, 0755); err != nil {
 		return err
 	}
 
-	for _, cmd := range commands {
+	for _, cmd := range commandsToNative(toolTranslator(a), commands) {
 		// Validate command name to prevent path traversal
 		if err := SanitizeName(cmd.Name); err != nil {
 			return fmt.Errorf("invalid command name: %w", err)
@@ -218,7 +246,7 @@ func (a *ClaudeAdapter) WriteCommands(commands []*command.Command) error {
 		filename := cmd.Name + ".md"
 		path := filepath.Join(commandsDir, filename)
 
-		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		if err := a.fs().WriteFile(path, []byte(content), 0644); err != nil {
 			return err
 		}
 	}
@@ -232,27 +260,14 @@ func (a *ClaudeAdapter) ReadRules() ([]*rule.Rule, error) {
 }
 
 func (a *ClaudeAdapter) WriteRules(rules []*rule.Rule) error {
-	rulesDir := a.rulesDir()
-
-	// Ensure directory exists
-	if err := os.MkdirAll(rulesDir, 0755); err != nil {
-		return err
-	}
-
-	for _, r := range rules {
-		if err := rule.Save(r, rulesDir); err != nil {
-			return err
-		}
-	}
-
-	return nil
+	return WriteRulesToDir(a.fs(), a.rulesDir(), rules)
 }
 
 // ReadSkills reads installed plugins as skills
 func (a *ClaudeAdapter) ReadSkills() ([]*skill.Skill, error) {
 	pluginsFile := filepath.Join(a.pluginsDir(), "installed_plugins.json")
 
-	data, err := os.ReadFile(pluginsFile)
+	data, err := a.fs().ReadFile(pluginsFile)
 	if err != nil {
 		if os.IsNotExist(err) {
 			return nil, nil
@@ -296,31 +311,13 @@ func (a *ClaudeAdapter) ReadSkills() ([]*skill.Skill, error) {
 // WriteSkills writes skills to Claude Code's skills directory
 // Note: This writes to ~/.claude/skills/, not the plugins system
 func (a *ClaudeAdapter) WriteSkills(skills []*skill.Skill) error {
-	skillsDir := filepath.Join(a.configDir(), "skills")
-
-	if err := os.MkdirAll(skillsDir, 0755); err != nil {
-		return err
-	}
-
-	for _, s := range skills {
-		// Validate skill name to prevent path traversal
-		if err := SanitizeName(s.Name); err != nil {
-			return fmt.Errorf("invalid skill name: %w", err)
-		}
-
-		skillDir := filepath.Join(skillsDir, s.Name)
-		if err := s.Save(skillDir); err != nil {
-			return err
-		}
-	}
-
-	return nil
+	return WriteSkillsToDir(a.fs(), filepath.Join(a.configDir(), "skills"), skills)
 }
 
 func (a *ClaudeAdapter) loadSettings() (*ClaudeCodeSettings, error) {
 	path := a.ConfigPath()
 
-	data, err := os.ReadFile(path)
+	data, err := a.fs().ReadFile(path)
 	if err != nil {
 		if os.IsNotExist(err) {
 			return &ClaudeCodeSettings{}, nil
@@ -330,12 +327,12 @@ func (a *ClaudeAdapter) loadSettings() (*ClaudeCodeSettings, error) {
 
 	// First unmarshal to capture all fields
 	var raw map[string]interface{}
-	if err := json.Unmarshal(data, &raw); err != nil {
+	if err := jsonc.Unmarshal(data, &raw); err != nil {
 		return nil, err
 	}
 
 	var settings ClaudeCodeSettings
-	if err := json.Unmarshal(data, &settings); err != nil {
+	if err := jsonc.Unmarshal(data, &settings); err != nil {
 		return nil, err
 	}
 
@@ -362,12 +359,7 @@ func (a *ClaudeAdapter) saveSettings(settings *ClaudeCodeSettings) error {
 		output["hooks"] = settings.Hooks
 	}
 
-	data, err := json.MarshalIndent(output, "", "  ")
-	if err != nil {
-		return err
-	}
-
-	return os.WriteFile(path, data, 0644)
+	return writeJSON(a.fs(), path, output)
 }
 
 // parseClaudeCommand parses a Claude Code command markdown file
@@ -463,7 +455,7 @@ func (a *ClaudeAdapter) WorkspaceConfigPath(projectDir string) string {
 func (a *ClaudeAdapter) ReadWorkspaceServers(projectDir string) ([]*mcp.Server, error) {
 	path := a.WorkspaceConfigPath(projectDir)
 
-	data, err := os.ReadFile(path)
+	data, err := a.fs().ReadFile(path)
 	if err != nil {
 		if os.IsNotExist(err) {
 			return nil, nil
@@ -472,7 +464,7 @@ func (a *ClaudeAdapter) ReadWorkspaceServers(projectDir string) ([]*mcp.Server,
 	}
 
 	var raw map[string]interface{}
-	if err := json.Unmarshal(data, &raw); err != nil {
+	if err := jsonc.Unmarshal(data, &raw); err != nil {
 		return nil, err
 	}
 
@@ -534,8 +526,8 @@ func (a *ClaudeAdapter) WriteWorkspaceServers(projectDir string, servers []*mcp.
 
 	// Load existing config if present
 	var raw map[string]interface{}
-	if data, err := os.ReadFile(path); err == nil {
-		if err := json.Unmarshal(data, &raw); err != nil {
+	if data, err := a.fs().ReadFile(path); err == nil {
+		if err := jsonc.Unmarshal(data, &raw); err != nil {
 			raw = make(map[string]interface{})
 		}
 	} else {
@@ -593,23 +585,465 @@ func (a *ClaudeAdapter) WriteWorkspaceServers(projectDir string, servers []*mcp.
 
 	raw["mcpServers"] = mcpServers
 
-	data, err := json.MarshalIndent(raw, "", "  ")
-	if err != nil {
+	if err := writeJSON(a.fs(), path, raw); err != nil {
 		return err
 	}
+	return a.writeToolDenials(filepath.Join(a.workspaceDir(projectDir), "settings.json"), WorkspaceStateKey(a.Name(), projectDir), servers)
+}
+
+// WorkspaceResourcesAdapter implementation for Claude Code
+
+// workspaceDir returns the project's .claude directory
+func (a *ClaudeAdapter) workspaceDir(projectDir string) string {
+	return filepath.Join(projectDir, ".claude")
+}
+
+// WorkspaceResources returns the resources Claude Code reads from .claude in a project
+func (a *ClaudeAdapter) WorkspaceResources() []ResourceType {
+	return []ResourceType{ResourceCommands, ResourceRules, ResourceSkills, ResourceAgents}
+}
 
-	return os.WriteFile(path, data, 0644)
+// WorkspacePaths returns the project's command, rule, skill and agent directories
+func (a *ClaudeAdapter) WorkspacePaths(projectDir string) []string {
+	dir := a.workspaceDir(projectDir)
+	return []string{filepath.Join(dir, "commands"), filepath.Join(dir, "rules"), filepath.Join(dir, "skills"), filepath.Join(dir, "agents")}
+}
+
+// WriteWorkspaceCommands writes commands to the project's .claude/commands
+func (a *ClaudeAdapter) WriteWorkspaceCommands(projectDir string, commands []*command.Command) error {
+	return a.writeCommands(filepath.Join(a.workspaceDir(projectDir), "commands"), commands)
+}
+
+// WriteWorkspaceRules writes rules to the project's .claude/rules
+func (a *ClaudeAdapter) WriteWorkspaceRules(projectDir string, rules []*rule.Rule) error {
+	return WriteRulesToDir(a.fs(), filepath.Join(a.workspaceDir(projectDir), "rules"), rules)
+}
+
+// WriteWorkspaceSkills writes skills to the project's .claude/skills
+func (a *ClaudeAdapter) WriteWorkspaceSkills(projectDir string, skills []*skill.Skill) error {
+	return WriteSkillsToDir(a.fs(), filepath.Join(a.workspaceDir(projectDir), "skills"), skills)
+}
+
+// WriteWorkspaceAgents writes agents to the project's .claude/agents
+func (a *ClaudeAdapter) WriteWorkspaceAgents(projectDir string, agents []*agent.Agent) error {
+	return WriteAgentsToDir(a.fs(), filepath.Join(a.workspaceDir(projectDir), "agents"), agentsToNative(toolTranslator(a), agents))
 }
 
 // AgentsAdapter implementation for Claude Code
 
 // ReadAgents reads agents from Claude Code's agents directory
 func (a *ClaudeAdapter) ReadAgents() ([]*agent.Agent, error) {
-	agentsDir := a.agentsDir()
-	return agent.LoadAll(agentsDir)
+	agents, err := agent.LoadAll(a.agentsDir())
+	if err != nil {
+		return nil, err
+	}
+	agentsToCanonical(toolTranslator(a), agents)
+	return agents, nil
 }
 
 // WriteAgents writes agents to Claude Code's agents directory
 func (a *ClaudeAdapter) WriteAgents(agents []*agent.Agent) error {
-	return WriteAgentsToDir(a.agentsDir(), agents)
+	return WriteAgentsToDir(a.fs(), a.agentsDir(), agentsToNative(toolTranslator(a), agents))
+}
+
+// PermissionsAdapter implementation for Claude Code
+
+// ReadPermissions reads the permissions block from Claude Code's settings.json
+func (a *ClaudeAdapter) ReadPermissions() (*permission.Permissions, error) {
+	raw, err := NewJSONConfigHelper(a.fs(), a.ConfigPath()).LoadRaw()
+	if err != nil {
+		return nil, err
+	}
+
+	section, ok := raw["permissions"].(map[string]interface{})
+	if !ok {
+		return nil, nil
+	}
+
+	tr := toolTranslator(a)
+	perms := &permission.Permissions{}
+	for _, decision := range []permission.Decision{permission.DecisionAllow, permission.DecisionDeny, permission.DecisionAsk} {
+		for _, pattern := range stringList(section[string(decision)]) {
+			perms.Add(decision, tr.CanonicalName(pattern))
+		}
+	}
+
+	return perms, nil
+}
+
+// WritePermissions writes permission patterns to Claude Code's settings.json.
+// Claude's permission lists can't carry a marker, so the entries agentctl
+// wrote are tracked in the sync state file. The deny list is shared with
+// the rules written for servers' disabled tools; see sharedDenials.
+func (a *ClaudeAdapter) WritePermissions(perms *permission.Permissions) error {
+	helper := NewJSONConfigHelper(a.fs(), a.ConfigPath())
+	raw, err := helper.LoadRaw()
+	if err != nil {
+		return err
+	}
+
+	state, err := loadState(a.fs())
+	if err != nil {
+		return err
+	}
+
+	section, ok := raw["permissions"].(map[string]interface{})
+	if !ok {
+		section = make(map[string]interface{})
+	}
+
+	// Remove entries written by a previous sync, unless a server's
+	// disabled tools still need the deny rule
+	previous := make(map[string]bool)
+	for _, entry := range state.GetManagedPermissions(a.Name()) {
+		previous[entry] = true
+	}
+	shared := sharedDenials(state.GetManagedPermissions(toolsStateKey(a.Name())))
+	lists := make(map[permission.Decision][]string)
+	for _, decision := range []permission.Decision{permission.DecisionAllow, permission.DecisionDeny, permission.DecisionAsk} {
+		for _, pattern := range stringList(section[string(decision)]) {
+			entry := string(decision) + ":" + pattern
+			if !previous[entry] || shared[entry] {
+				lists[decision] = append(lists[decision], pattern)
+			}
+		}
+	}
+
+	// Add the current patterns in Claude's vocabulary
+	tr := toolTranslator(a)
+	var managed []string
+	for _, r := range perms.Rules() {
+		pattern := tr.NativeName(r.Pattern)
+		entry := string(r.Decision) + ":" + pattern
+		if hasString(lists[r.Decision], pattern) {
+			// A deny rule agentctl wrote for disabled tools is shared
+			if shared[entry] && !hasString(managed, entry) {
+				managed = append(managed, entry)
+			}
+			continue
+		}
+		lists[r.Decision] = append(lists[r.Decision], pattern)
+		managed = append(managed, entry)
+	}
+
+	for _, decision := range []permission.Decision{permission.DecisionAllow, permission.DecisionDeny, permission.DecisionAsk} {
+		if len(lists[decision]) > 0 {
+			section[string(decision)] = lists[decision]
+		} else {
+			delete(section, string(decision))
+		}
+	}
+
+	if len(section) > 0 {
+		raw["permissions"] = section
+	} else {
+		delete(raw, "permissions")
+	}
+
+	if err := helper.SaveRaw(raw); err != nil {
+		return err
+	}
+
+	state.SetManagedPermissions(a.Name(), managed)
+	return state.save(a.fs())
+}
+
+// writeToolDenials writes a permissions deny rule, mcp__<server>__<tool>,
+// for each of the servers' disabled tools to the settings file at path.
+// Claude has no allowlist for a server's tools, since deny rules win over
+// allow rules, so enabled lists aren't written. Claude matches a tool's
+// exact name, so globs are left out; SkippedToolFilters reports them. The
+// rules are tracked in the sync state apart from the permissions agentctl
+// syncs, and a rule both need is kept until neither does.
+func (a *ClaudeAdapter) writeToolDenials(path, stateKey string, servers []*mcp.Server) error {
+	state, err := loadState(a.fs())
+	if err != nil {
+		return err
+	}
+	key := toolsStateKey(stateKey)
+	previous := state.GetManagedPermissions(key)
+
+	// Only the global settings hold synced permissions
+	var synced map[string]bool
+	if stateKey == a.Name() {
+		synced = syncedDenials(state.GetManagedPermissions(a.Name()))
+	}
+
+	var rules []string
+	for _, server := range servers {
+		for _, tool := range exactTools(server.DisabledTools) {
+			rules = append(rules, "mcp__"+GetServerName(server)+"__"+tool)
+		}
+	}
+	if len(rules) == 0 && len(previous) == 0 {
+		return nil
+	}
+
+	helper := NewJSONConfigHelper(a.fs(), path)
+	raw, err := helper.LoadRaw()
+	if err != nil {
+		return err
+	}
+	section, ok := raw["permissions"].(map[string]interface{})
+	if !ok {
+		section = make(map[string]interface{})
+	}
+
+	var deny []string
+	for _, rule := range stringList(section["deny"]) {
+		if !hasString(previous, rule) || synced[rule] {
+			deny = append(deny, rule)
+		}
+	}
+	var managed []string
+	for _, rule := range rules {
+		if hasString(managed, rule) {
+			continue
+		}
+		if !hasString(deny, rule) {
+			deny = append(deny, rule)
+			managed = append(managed, rule)
+		} else if synced[rule] {
+			managed = append(managed, rule)
+		}
+	}
+
+	if len(deny) > 0 {
+		section["deny"] = deny
+	} else {
+		delete(section, "deny")
+	}
+	if len(section) > 0 {
+		raw["permissions"] = section
+	} else {
+		delete(raw, "permissions")
+	}
+	if err := helper.SaveRaw(raw); err != nil {
+		return err
+	}
+
+	state.SetManagedPermissions(key, managed)
+	return state.save(a.fs())
+}
+
+// sourceMap returns the source object of a marketplace entry
+func sourceMap(entry map[string]interface{}) map[string]interface{} {
+	source, _ := entry["source"].(map[string]interface{})
+	return source
+}
+
+// sharedDenials returns the disabled-tool rules tracked under a tools state
+// key as permission state entries, deny:<rule>
+func sharedDenials(rules []string) map[string]bool {
+	entries := make(map[string]bool, len(rules))
+	for _, rule := range rules {
+		entries[string(permission.DecisionDeny)+":"+rule] = true
+	}
+	return entries
+}
+
+// syncedDenials returns the deny rules among synced permission state
+// entries
+func syncedDenials(entries []string) map[string]bool {
+	rules := make(map[string]bool)
+	for _, entry := range entries {
+		if rule, ok := strings.CutPrefix(entry, string(permission.DecisionDeny)+":"); ok {
+			rules[rule] = true
+		}
+	}
+	return rules
+}
+
+// SkippedToolFilters returns the disabled-tool globs Claude can't match,
+// as server:pattern
+func (a *ClaudeAdapter) SkippedToolFilters(servers []*mcp.Server) []string {
+	var skipped []string
+	for _, server := range servers {
+		for _, pattern := range server.DisabledTools {
+			if mcp.IsToolGlob(pattern) {
+				skipped = append(skipped, GetServerName(server)+":"+pattern)
+			}
+		}
+	}
+	return skipped
+}
+
+// PluginsAdapter implementation for Claude Code
+
+func (a *ClaudeAdapter) knownMarketplacesPath() string {
+	return filepath.Join(a.pluginsDir(), "known_marketplaces.json")
+}
+
+// ReadPlugins reads enabledPlugins and extraKnownMarketplaces from
+// settings.json, plus marketplaces registered in known_marketplaces.json.
+// Versions come from installed_plugins.json.
+func (a *ClaudeAdapter) ReadPlugins() ([]*plugin.Plugin, map[string]*plugin.Marketplace, error) {
+	raw, err := NewJSONConfigHelper(a.fs(), a.ConfigPath()).LoadRaw()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	installed := make(map[string]string)
+	if data, err := a.fs().ReadFile(filepath.Join(a.pluginsDir(), "installed_plugins.json")); err == nil {
+		var manifest ClaudeCodePluginsFile
+		if err := json.Unmarshal(data, &manifest); err == nil {
+			for id, versions := range manifest.Plugins {
+				if len(versions) > 0 {
+					installed[id] = versions[0].Version
+				}
+			}
+		}
+	}
+
+	var plugins []*plugin.Plugin
+	enabled, _ := raw["enabledPlugins"].(map[string]interface{})
+	for id, v := range enabled {
+		p, err := plugin.Parse(id)
+		if err != nil {
+			continue
+		}
+		on, _ := v.(bool)
+		p.Disabled = !on
+		p.Version = installed[id]
+		plugins = append(plugins, p)
+	}
+	sort.Slice(plugins, func(i, j int) bool { return plugins[i].ID() < plugins[j].ID() })
+
+	marketplaces := make(map[string]*plugin.Marketplace)
+	known, err := NewJSONConfigHelper(a.fs(), a.knownMarketplacesPath()).LoadRaw()
+	if err != nil {
+		return nil, nil, err
+	}
+	extra, _ := raw["extraKnownMarketplaces"].(map[string]interface{})
+	for _, section := range []map[string]interface{}{known, extra} {
+		for name, v := range section {
+			entry, _ := v.(map[string]interface{})
+			if source, ok := entry["source"].(map[string]interface{}); ok {
+				marketplaces[name] = plugin.MarketplaceFromMap(source)
+			}
+		}
+	}
+
+	return plugins, marketplaces, nil
+}
+
+// WritePlugins enables marketplace plugins in settings.json and registers
+// their marketplaces in extraKnownMarketplaces and known_marketplaces.json.
+// Claude Code installs enabled plugins from these on startup. npm plugins
+// are skipped, and entries agentctl wrote are tracked in the sync state.
+func (a *ClaudeAdapter) WritePlugins(plugins []*plugin.Plugin, marketplaces map[string]*plugin.Marketplace) error {
+	helper := NewJSONConfigHelper(a.fs(), a.ConfigPath())
+	raw, err := helper.LoadRaw()
+	if err != nil {
+		return err
+	}
+	knownHelper := NewJSONConfigHelper(a.fs(), a.knownMarketplacesPath())
+	known, err := knownHelper.LoadRaw()
+	if err != nil {
+		return err
+	}
+
+	state, err := loadState(a.fs())
+	if err != nil {
+		return err
+	}
+
+	enabled, ok := raw["enabledPlugins"].(map[string]interface{})
+	if !ok {
+		enabled = make(map[string]interface{})
+	}
+	extra, ok := raw["extraKnownMarketplaces"].(map[string]interface{})
+	if !ok {
+		extra = make(map[string]interface{})
+	}
+
+	// Remove entries written by a previous sync ("plugin:id",
+	// "marketplace:name" or "known:name"). Claude keeps its own fields in
+	// known_marketplaces.json, so those entries are only removed once the
+	// marketplace is gone from agentctl.json.
+	knownManaged := make(map[string]bool)
+	for _, entry := range state.GetManagedPlugins(a.Name()) {
+		kind, name, _ := strings.Cut(entry, ":")
+		switch kind {
+		case "plugin":
+			delete(enabled, name)
+		case "marketplace":
+			delete(extra, name)
+		case "known":
+			knownManaged[name] = true
+		}
+	}
+	knownChanged := false
+
+	// Entries the user already had stay theirs: agentctl sets the value but
+	// won't delete them when the plugin is removed from agentctl.json.
+	var managed []string
+	for _, p := range plugins {
+		if p.IsNPM() {
+			continue
+		}
+		if _, exists := enabled[p.ID()]; !exists {
+			managed = append(managed, "plugin:"+p.ID())
+		}
+		enabled[p.ID()] = !p.Disabled
+	}
+
+	names := make([]string, 0, len(marketplaces))
+	for name := range marketplaces {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		m := marketplaces[name]
+		if _, exists := extra[name]; !exists {
+			managed = append(managed, "marketplace:"+name)
+		}
+		extra[name] = map[string]interface{}{"source": m.ToMap()}
+
+		// Claude maintains known_marketplaces.json itself once a
+		// marketplace is cloned: register ones it doesn't know yet, and
+		// only keep the source of ones agentctl registered up to date
+		entry, exists := known[name].(map[string]interface{})
+		switch {
+		case !exists:
+			known[name] = map[string]interface{}{
+				"source":          m.ToMap(),
+				"installLocation": filepath.Join(a.pluginsDir(), "marketplaces", name),
+			}
+			managed = append(managed, "known:"+name)
+			knownChanged = true
+		case knownManaged[name]:
+			if *plugin.MarketplaceFromMap(sourceMap(entry)) != *m {
+				entry["source"] = m.ToMap()
+				knownChanged = true
+			}
+			managed = append(managed, "known:"+name)
+		}
+	}
+	for name := range knownManaged {
+		if _, wanted := marketplaces[name]; !wanted {
+			delete(known, name)
+			knownChanged = true
+		}
+	}
+
+	for key, section := range map[string]map[string]interface{}{"enabledPlugins": enabled, "extraKnownMarketplaces": extra} {
+		if len(section) > 0 {
+			raw[key] = section
+		} else {
+			delete(raw, key)
+		}
+	}
+
+	if err := helper.SaveRaw(raw); err != nil {
+		return err
+	}
+	if knownChanged {
+		if err := knownHelper.SaveRaw(known); err != nil {
+			return err
+		}
+	}
+
+	state.SetManagedPlugins(a.Name(), managed)
+	return state.save(a.fs())
 }
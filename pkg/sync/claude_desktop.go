@@ -1,16 +1,18 @@
 package sync
 
 import (
-	"encoding/json"
 	"os"
 	"path/filepath"
 	"runtime"
 
+	"github.com/iheanyi/agentctl/pkg/jsonc"
 	"github.com/iheanyi/agentctl/pkg/mcp"
 )
 
 // ClaudeDesktopAdapter syncs configuration to Claude Desktop (the Electron app)
-type ClaudeDesktopAdapter struct{}
+type ClaudeDesktopAdapter struct {
+	fsHolder
+}
 
 // ClaudeServerConfig represents a server in Claude's config format
 type ClaudeServerConfig struct {
@@ -40,6 +42,13 @@ func (a *ClaudeDesktopAdapter) Name() string {
 	return "claude-desktop"
 }
 
+// WithFS returns a copy of the adapter that uses fsys
+func (a *ClaudeDesktopAdapter) WithFS(fsys FS) Adapter {
+	c := *a
+	c.fsys = fsys
+	return &c
+}
+
 func (a *ClaudeDesktopAdapter) Detect() (bool, error) {
 	path := a.ConfigPath()
 	if path == "" {
@@ -48,7 +57,7 @@ func (a *ClaudeDesktopAdapter) Detect() (bool, error) {
 
 	// Check if Claude config directory exists
 	dir := filepath.Dir(path)
-	if _, err := os.Stat(dir); os.IsNotExist(err) {
+	if _, err := a.fs().Stat(dir); os.IsNotExist(err) {
 		return false, nil
 	}
 
@@ -77,6 +86,11 @@ func (a *ClaudeDesktopAdapter) SupportedResources() []ResourceType {
 	return []ResourceType{ResourceMCP}
 }
 
+// StdioOnly returns true - Claude Desktop only launches stdio servers
+func (a *ClaudeDesktopAdapter) StdioOnly() bool {
+	return true
+}
+
 func (a *ClaudeDesktopAdapter) ReadServers() ([]*mcp.Server, error) {
 	config, err := a.loadConfig()
 	if err != nil {
@@ -147,7 +161,7 @@ func (a *ClaudeDesktopAdapter) WriteServers(servers []*mcp.Server) error {
 func (a *ClaudeDesktopAdapter) loadConfig() (*ClaudeConfig, error) {
 	path := a.ConfigPath()
 
-	data, err := os.ReadFile(path)
+	data, err := a.fs().ReadFile(path)
 	if err != nil {
 		if os.IsNotExist(err) {
 			return &ClaudeConfig{}, nil
@@ -156,7 +170,7 @@ func (a *ClaudeDesktopAdapter) loadConfig() (*ClaudeConfig, error) {
 	}
 
 	var config ClaudeConfig
-	if err := json.Unmarshal(data, &config); err != nil {
+	if err := jsonc.Unmarshal(data, &config); err != nil {
 		return nil, err
 	}
 
@@ -168,14 +182,9 @@ func (a *ClaudeDesktopAdapter) saveConfig(config *ClaudeConfig) error {
 
 	// Ensure directory exists
 	dir := filepath.Dir(path)
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		return err
-	}
-
-	data, err := json.MarshalIndent(config, "", "  ")
-	if err != nil {
+	if err := a.fs().MkdirAll(dir, 0755); err != nil {
 		return err
 	}
 
-	return os.WriteFile(path, data, 0644)
+	return writeJSON(a.fs(), path, config)
 }
@@ -9,7 +9,9 @@ import (
 )
 
 // ClineAdapter syncs configuration to Cline
-type ClineAdapter struct{}
+type ClineAdapter struct {
+	fsHolder
+}
 
 // ClineServerConfig represents a server in Cline's config format
 type ClineServerConfig struct {
@@ -27,6 +29,13 @@ func (a *ClineAdapter) Name() string {
 	return "cline"
 }
 
+// WithFS returns a copy of the adapter that uses fsys
+func (a *ClineAdapter) WithFS(fsys FS) Adapter {
+	c := *a
+	c.fsys = fsys
+	return &c
+}
+
 func (a *ClineAdapter) Detect() (bool, error) {
 	path := a.ConfigPath()
 	if path == "" {
@@ -34,7 +43,7 @@ func (a *ClineAdapter) Detect() (bool, error) {
 	}
 
 	dir := filepath.Dir(path)
-	if _, err := os.Stat(dir); os.IsNotExist(err) {
+	if _, err := a.fs().Stat(dir); os.IsNotExist(err) {
 		return false, nil
 	}
 
@@ -62,8 +71,13 @@ func (a *ClineAdapter) SupportedResources() []ResourceType {
 	return []ResourceType{ResourceMCP}
 }
 
+// StdioOnly returns true - Cline only launches stdio servers
+func (a *ClineAdapter) StdioOnly() bool {
+	return true
+}
+
 func (a *ClineAdapter) ReadServers() ([]*mcp.Server, error) {
-	helper := NewJSONConfigHelper(a.ConfigPath())
+	helper := NewJSONConfigHelper(a.fs(), a.ConfigPath())
 	raw, err := helper.LoadRaw()
 	if err != nil {
 		return nil, err
@@ -74,7 +88,7 @@ func (a *ClineAdapter) ReadServers() ([]*mcp.Server, error) {
 }
 
 func (a *ClineAdapter) WriteServers(servers []*mcp.Server) error {
-	helper := NewJSONConfigHelper(a.ConfigPath())
+	helper := NewJSONConfigHelper(a.fs(), a.ConfigPath())
 	raw, err := helper.LoadRaw()
 	if err != nil {
 		return err
@@ -84,7 +98,7 @@ func (a *ClineAdapter) WriteServers(servers []*mcp.Server) error {
 	RemoveManagedServers(mcpServers)
 
 	// Add new servers
-	for _, server := range servers {
+	for _, server := range FilterStdioServers(servers) {
 		name := GetServerName(server)
 		if name == "" {
 			continue
@@ -10,15 +10,20 @@ import (
 
 	toml "github.com/pelletier/go-toml/v2"
 
+	"github.com/iheanyi/agentctl/pkg/codexrules"
 	"github.com/iheanyi/agentctl/pkg/command"
 	"github.com/iheanyi/agentctl/pkg/mcp"
+	"github.com/iheanyi/agentctl/pkg/permission"
 	"github.com/iheanyi/agentctl/pkg/rule"
 	"github.com/iheanyi/agentctl/pkg/skill"
+	"github.com/iheanyi/agentctl/pkg/tomldoc"
 )
 
 // CodexAdapter syncs configuration to OpenAI Codex CLI
 // Codex uses TOML format (config.toml) as primary config
-type CodexAdapter struct{}
+type CodexAdapter struct {
+	fsHolder
+}
 
 // CodexTOMLConfig represents Codex's TOML configuration structure
 type CodexTOMLConfig struct {
@@ -29,13 +34,15 @@ type CodexTOMLConfig struct {
 
 // CodexTOMLServerConfig represents a server in Codex's TOML format
 type CodexTOMLServerConfig struct {
-	Command     string            `toml:"command,omitempty"`
-	Args        []string          `toml:"args,omitempty"`
-	URL         string            `toml:"url,omitempty"`
-	Env         map[string]string `toml:"env,omitempty"`
-	Enabled     *bool             `toml:"enabled,omitempty"`
-	Timeout     int               `toml:"startup_timeout_sec,omitempty"`
-	ToolTimeout int               `toml:"tool_timeout_sec,omitempty"`
+	Command       string            `toml:"command,omitempty"`
+	Args          []string          `toml:"args,omitempty"`
+	URL           string            `toml:"url,omitempty"`
+	Env           map[string]string `toml:"env,omitempty"`
+	Enabled       *bool             `toml:"enabled,omitempty"`
+	Timeout       int               `toml:"startup_timeout_sec,omitempty"`
+	ToolTimeout   int               `toml:"tool_timeout_sec,omitempty"`
+	EnabledTools  []string          `toml:"enabled_tools,omitempty"`
+	DisabledTools []string          `toml:"disabled_tools,omitempty"`
 }
 
 // CodexJSONServerConfig represents a server in Codex's legacy JSON format
@@ -59,13 +66,20 @@ func (a *CodexAdapter) Name() string {
 	return "codex"
 }
 
+// WithFS returns a copy of the adapter that uses fsys
+func (a *CodexAdapter) WithFS(fsys FS) Adapter {
+	c := *a
+	c.fsys = fsys
+	return &c
+}
+
 func (a *CodexAdapter) Detect() (bool, error) {
 	configDir := a.configDir()
 	if configDir == "" {
 		return false, nil
 	}
 
-	if _, err := os.Stat(configDir); os.IsNotExist(err) {
+	if _, err := a.fs().Stat(configDir); os.IsNotExist(err) {
 		return false, nil
 	}
 
@@ -75,7 +89,7 @@ func (a *CodexAdapter) Detect() (bool, error) {
 func (a *CodexAdapter) ConfigPath() string {
 	// Prefer TOML, fall back to JSON
 	tomlPath := a.tomlConfigPath()
-	if _, err := os.Stat(tomlPath); err == nil {
+	if _, err := a.fs().Stat(tomlPath); err == nil {
 		return tomlPath
 	}
 	return a.jsonConfigPath()
@@ -115,13 +129,19 @@ func (a *CodexAdapter) agentsFilePath() string {
 	return filepath.Join(a.configDir(), "AGENTS.md")
 }
 
+// ManagedPaths returns the prompt and skill directories, AGENTS.md and the
+// managed execution-rules file
+func (a *CodexAdapter) ManagedPaths() []string {
+	return []string{a.promptsDir(), a.skillsDir(), a.agentsFilePath(), filepath.Join(a.rulesDir(), codexManagedRulesFile)}
+}
+
 func (a *CodexAdapter) SupportedResources() []ResourceType {
-	return []ResourceType{ResourceMCP, ResourceCommands, ResourceRules, ResourceSkills}
+	return []ResourceType{ResourceMCP, ResourceCommands, ResourceRules, ResourceSkills, ResourcePermissions}
 }
 
 func (a *CodexAdapter) ReadServers() ([]*mcp.Server, error) {
 	// Try TOML first
-	if servers, err := a.readServersFromTOML(); err == nil && len(servers) > 0 {
+	if servers, err := a.readServersFromTOML(a.tomlConfigPath()); err == nil && len(servers) > 0 {
 		return servers, nil
 	}
 
@@ -129,9 +149,8 @@ func (a *CodexAdapter) ReadServers() ([]*mcp.Server, error) {
 	return a.readServersFromJSON()
 }
 
-func (a *CodexAdapter) readServersFromTOML() ([]*mcp.Server, error) {
-	path := a.tomlConfigPath()
-	data, err := os.ReadFile(path)
+func (a *CodexAdapter) readServersFromTOML(path string) ([]*mcp.Server, error) {
+	data, err := a.fs().ReadFile(path)
 	if err != nil {
 		return nil, err
 	}
@@ -186,6 +205,15 @@ func (a *CodexAdapter) readServersFromTOML() ([]*mcp.Server, error) {
 			}
 		}
 
+		if timeout, ok := serverData["startup_timeout_sec"].(int64); ok {
+			server.StartupTimeout = int(timeout)
+		}
+		if timeout, ok := serverData["tool_timeout_sec"].(int64); ok {
+			server.ToolTimeout = int(timeout)
+		}
+		server.EnabledTools = stringList(serverData["enabled_tools"])
+		server.DisabledTools = stringList(serverData["disabled_tools"])
+
 		servers = append(servers, server)
 	}
 
@@ -194,7 +222,7 @@ func (a *CodexAdapter) readServersFromTOML() ([]*mcp.Server, error) {
 
 func (a *CodexAdapter) readServersFromJSON() ([]*mcp.Server, error) {
 	path := a.jsonConfigPath()
-	data, err := os.ReadFile(path)
+	data, err := a.fs().ReadFile(path)
 	if err != nil {
 		if os.IsNotExist(err) {
 			return nil, nil
@@ -224,45 +252,51 @@ func (a *CodexAdapter) readServersFromJSON() ([]*mcp.Server, error) {
 func (a *CodexAdapter) WriteServers(servers []*mcp.Server) error {
 	// Check if TOML config exists - if so, write to TOML
 	tomlPath := a.tomlConfigPath()
-	if _, err := os.Stat(tomlPath); err == nil {
-		return a.writeServersToTOML(servers)
+	if _, err := a.fs().Stat(tomlPath); err == nil {
+		return a.writeServersToTOML(tomlPath, a.Name(), servers)
 	}
 
 	// Otherwise write to JSON (legacy)
 	return a.writeServersToJSON(servers)
 }
 
-func (a *CodexAdapter) writeServersToTOML(servers []*mcp.Server) error {
-	path := a.tomlConfigPath()
-
-	// Load existing TOML config
-	var raw map[string]interface{}
-	if data, err := os.ReadFile(path); err == nil {
-		if err := toml.Unmarshal(data, &raw); err != nil {
-			raw = make(map[string]interface{})
-		}
-	} else {
-		raw = make(map[string]interface{})
+// codexManagedMarker is the comment above the [mcp_servers.*] tables
+// agentctl writes. TOML has no equivalent of the _managedBy field, so the
+// marker is how a later sync knows which tables it may replace.
+const codexManagedMarker = "# Managed by agentctl"
+
+// writeServersToTOML updates the [mcp_servers.*] tables agentctl manages
+// in the file at path, tracking their names in the sync state under
+// stateKey. Other tables, comments and formatting are left untouched.
+func (a *CodexAdapter) writeServersToTOML(path, stateKey string, servers []*mcp.Server) error {
+	data, err := a.fs().ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	doc, err := tomldoc.Parse(string(data))
+	if err != nil {
+		return fmt.Errorf("failed to parse %s: %w", path, err)
 	}
 
 	// Load sync state to track managed servers
-	state, err := LoadState()
+	state, err := loadState(a.fs())
 	if err != nil {
 		return err
 	}
 
-	// Get or create mcp_servers section
-	mcpServers, ok := raw["mcp_servers"].(map[string]interface{})
-	if !ok {
-		mcpServers = make(map[string]interface{})
+	// Tables with the marker, and those written before it was added
+	managed := make(map[string]bool)
+	for _, name := range state.GetManagedServers(stateKey) {
+		managed[name] = true
 	}
-
-	// Remove previously managed servers
-	for _, name := range state.GetManagedServers(a.Name()) {
-		delete(mcpServers, name)
+	for _, b := range doc.Blocks {
+		if len(b.Header) == 2 && b.Header[0] == "mcp_servers" && b.HasComment(codexManagedMarker) {
+			managed[b.Header[1]] = true
+		}
 	}
 
 	var managedNames []string
+	wanted := make(map[string]bool)
 
 	// Add new servers
 	for _, server := range servers {
@@ -274,42 +308,122 @@ func (a *CodexAdapter) writeServersToTOML(servers []*mcp.Server) error {
 			continue
 		}
 
-		serverCfg := map[string]interface{}{
-			"enabled": true,
+		if err := doc.SetTable([]string{"mcp_servers", name}, codexServerValues(server), codexManagedMarker); err != nil {
+			return fmt.Errorf("server %q: %w", name, err)
 		}
+		wanted[name] = true
+		managedNames = append(managedNames, name)
+	}
 
-		if server.URL != "" {
-			serverCfg["url"] = server.URL
-		} else {
-			serverCfg["command"] = server.Command
-			if len(server.Args) > 0 {
-				serverCfg["args"] = server.Args
-			}
+	// Remove previously managed servers
+	for name := range managed {
+		if !wanted[name] {
+			doc.RemoveTable("mcp_servers", name)
 		}
+	}
 
-		if len(server.Env) > 0 {
-			serverCfg["env"] = server.Env
-		}
+	// Refuse to write a file Codex can't load, e.g. one that already
+	// defined a table twice
+	out := doc.String()
+	var check map[string]interface{}
+	if err := toml.Unmarshal([]byte(out), &check); err != nil {
+		return fmt.Errorf("updating %s: %w", path, err)
+	}
 
-		mcpServers[name] = serverCfg
-		managedNames = append(managedNames, name)
+	if err := a.fs().MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
 	}
+	if err := a.fs().WriteFile(path, []byte(out), 0644); err != nil {
+		return err
+	}
+
+	// Update state
+	state.SetManagedServers(stateKey, managedNames)
+	return state.save(a.fs())
+}
 
-	raw["mcp_servers"] = mcpServers
+// WorkspaceAdapter implementation for Codex
 
-	// Write TOML
-	data, err := toml.Marshal(raw)
+// SupportsWorkspace returns true - Codex reads .codex/config.toml in trusted projects
+func (a *CodexAdapter) SupportsWorkspace() bool {
+	return true
+}
+
+// WorkspaceConfigPath returns the path to .codex/config.toml in the project directory
+func (a *CodexAdapter) WorkspaceConfigPath(projectDir string) string {
+	return filepath.Join(projectDir, ".codex", "config.toml")
+}
+
+// ReadWorkspaceServers reads MCP servers from the project's .codex/config.toml
+func (a *CodexAdapter) ReadWorkspaceServers(projectDir string) ([]*mcp.Server, error) {
+	servers, err := a.readServersFromTOML(a.WorkspaceConfigPath(projectDir))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
 	if err != nil {
-		return err
+		return nil, err
+	}
+	for _, s := range servers {
+		s.Scope = "local"
 	}
+	return servers, nil
+}
 
-	if err := os.WriteFile(path, data, 0644); err != nil {
-		return err
+// WriteWorkspaceServers writes MCP servers to the project's .codex/config.toml
+func (a *CodexAdapter) WriteWorkspaceServers(projectDir string, servers []*mcp.Server) error {
+	return a.writeServersToTOML(a.WorkspaceConfigPath(projectDir), WorkspaceStateKey(a.Name(), projectDir), servers)
+}
+
+// codexServerValues returns the keys of a server's [mcp_servers.*] table
+func codexServerValues(server *mcp.Server) []tomldoc.KeyValue {
+	var values []tomldoc.KeyValue
+	if server.URL != "" {
+		values = append(values, tomldoc.KeyValue{Key: "url", Value: server.URL})
+	} else {
+		values = append(values, tomldoc.KeyValue{Key: "command", Value: server.Command})
+		if len(server.Args) > 0 {
+			values = append(values, tomldoc.KeyValue{Key: "args", Value: server.Args})
+		}
+	}
+	if len(server.Env) > 0 {
+		values = append(values, tomldoc.KeyValue{Key: "env", Value: server.Env})
+	}
+	if server.StartupTimeout > 0 {
+		values = append(values, tomldoc.KeyValue{Key: "startup_timeout_sec", Value: server.StartupTimeout})
 	}
+	if server.ToolTimeout > 0 {
+		values = append(values, tomldoc.KeyValue{Key: "tool_timeout_sec", Value: server.ToolTimeout})
+	}
+	// Codex matches tool names exactly. An enabled list with globs is left
+	// out rather than hiding the tools they match; disabled globs are
+	// dropped.
+	if len(server.EnabledTools) > 0 && len(exactTools(server.EnabledTools)) == len(server.EnabledTools) {
+		values = append(values, tomldoc.KeyValue{Key: "enabled_tools", Value: server.EnabledTools})
+	}
+	if disabled := exactTools(server.DisabledTools); len(disabled) > 0 {
+		values = append(values, tomldoc.KeyValue{Key: "disabled_tools", Value: disabled})
+	}
+	return append(values, tomldoc.KeyValue{Key: "enabled", Value: true})
+}
 
-	// Update state
-	state.SetManagedServers(a.Name(), managedNames)
-	return state.Save()
+// SkippedToolFilters returns the tool filter globs Codex can't match, as
+// server:pattern. An enabled list with a glob is left out whole.
+func (a *CodexAdapter) SkippedToolFilters(servers []*mcp.Server) []string {
+	var skipped []string
+	for _, server := range servers {
+		name := GetServerName(server)
+		if len(exactTools(server.EnabledTools)) != len(server.EnabledTools) {
+			for _, pattern := range server.EnabledTools {
+				skipped = append(skipped, name+":"+pattern)
+			}
+		}
+		for _, pattern := range server.DisabledTools {
+			if mcp.IsToolGlob(pattern) {
+				skipped = append(skipped, name+":"+pattern)
+			}
+		}
+	}
+	return skipped
 }
 
 func (a *CodexAdapter) writeServersToJSON(servers []*mcp.Server) error {
@@ -317,7 +431,7 @@ func (a *CodexAdapter) writeServersToJSON(servers []*mcp.Server) error {
 
 	// Load existing config
 	var config CodexJSONConfig
-	if data, err := os.ReadFile(path); err == nil {
+	if data, err := a.fs().ReadFile(path); err == nil {
 		_ = json.Unmarshal(data, &config) // Ignore error, start with empty config if invalid
 	}
 
@@ -351,7 +465,7 @@ func (a *CodexAdapter) writeServersToJSON(servers []*mcp.Server) error {
 	}
 
 	// Ensure directory exists
-	if err := os.MkdirAll(a.configDir(), 0755); err != nil {
+	if err := a.fs().MkdirAll(a.configDir(), 0755); err != nil {
 		return err
 	}
 
@@ -360,18 +474,18 @@ func (a *CodexAdapter) writeServersToJSON(servers []*mcp.Server) error {
 		return err
 	}
 
-	return os.WriteFile(path, data, 0644)
+	return a.fs().WriteFile(path, data, 0644)
 }
 
 func (a *CodexAdapter) ReadCommands() ([]*command.Command, error) {
 	// Codex uses ~/.codex/prompts/ for custom prompts (similar to commands)
-	return ReadCommandsFromDir(a.promptsDir(), parseCodexPrompt)
+	return ReadCommandsFromDir(a.fs(), a.promptsDir(), parseCodexPrompt)
 }
 
 func (a *CodexAdapter) WriteCommands(commands []*command.Command) error {
 	promptsDir := a.promptsDir()
 
-	if err := os.MkdirAll(promptsDir, 0755); err != nil {
+	if err := a.fs().MkdirAll(promptsDir, 0755); err != nil {
 		return err
 	}
 
@@ -385,7 +499,7 @@ func (a *CodexAdapter) WriteCommands(commands []*command.Command) error {
 		filename := cmd.Name + ".md"
 		path := filepath.Join(promptsDir, filename)
 
-		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		if err := a.fs().WriteFile(path, []byte(content), 0644); err != nil {
 			return err
 		}
 	}
@@ -397,7 +511,7 @@ func (a *CodexAdapter) ReadRules() ([]*rule.Rule, error) {
 	// Codex uses AGENTS.md for instructions
 	agentsPath := a.agentsFilePath()
 
-	if _, err := os.Stat(agentsPath); os.IsNotExist(err) {
+	if _, err := a.fs().Stat(agentsPath); os.IsNotExist(err) {
 		return nil, nil
 	}
 
@@ -424,21 +538,21 @@ func (a *CodexAdapter) WriteRules(rules []*rule.Rule) error {
 		content.WriteString(r.Content)
 	}
 
-	if err := os.MkdirAll(a.configDir(), 0755); err != nil {
+	if err := a.fs().MkdirAll(a.configDir(), 0755); err != nil {
 		return err
 	}
 
-	return os.WriteFile(agentsPath, []byte(content.String()), 0644)
+	return a.fs().WriteFile(agentsPath, []byte(content.String()), 0644)
 }
 
 // ReadSkills reads skills from Codex's skills directory
 func (a *CodexAdapter) ReadSkills() ([]*skill.Skill, error) {
-	return ReadSkillsFromDir(a.skillsDir())
+	return ReadSkillsFromDir(a.fs(), a.skillsDir())
 }
 
 // WriteSkills writes skills to Codex's skills directory
 func (a *CodexAdapter) WriteSkills(skills []*skill.Skill) error {
-	return WriteSkillsToDir(a.skillsDir(), skills)
+	return WriteSkillsToDir(a.fs(), a.skillsDir(), skills)
 }
 
 // parseCodexPrompt parses a Codex prompt markdown file
@@ -488,3 +602,73 @@ func formatCodexPrompt(cmd *command.Command) string {
 
 	return sb.String()
 }
+
+// PermissionsAdapter implementation for Codex
+
+// codexManagedRulesFile is the execution-rules file agentctl owns. Codex
+// loads every *.rules file in ~/.codex/rules, so user files stay untouched.
+const codexManagedRulesFile = "agentctl.rules"
+
+// codexManagedRulesHeader marks the rules file as generated by agentctl
+const codexManagedRulesHeader = "# Managed by agentctl - changes are overwritten on sync"
+
+func (a *CodexAdapter) rulesDir() string {
+	return filepath.Join(a.configDir(), "rules")
+}
+
+// ReadPermissions imports prefix_rule entries from every *.rules file in
+// Codex's rules directory as bash permissions
+func (a *CodexAdapter) ReadPermissions() (*permission.Permissions, error) {
+	rules, err := codexrules.LoadDir(a.fs(), a.rulesDir())
+	if err != nil {
+		return nil, err
+	}
+	return codexrules.ToPermissions(rules), nil
+}
+
+// SkippedPermissions returns the bash allow and ask patterns Codex can't
+// express: prefix rules would widen exact commands, so they're left out
+func (a *CodexAdapter) SkippedPermissions(perms *permission.Permissions) []string {
+	_, skipped := codexrules.FromPermissions(perms)
+	return skipped
+}
+
+// OwnsPermissionsFile returns true - agentctl's rules live in their own
+// file in Codex's rules directory rather than in sync state
+func (a *CodexAdapter) OwnsPermissionsFile() bool {
+	return true
+}
+
+// WritePermissions generates prefix_rule entries for bash permissions in
+// ~/.codex/rules/agentctl.rules. Codex execution rules only govern shell
+// commands, so patterns for other tools are skipped. Comments and
+// statements agentctl doesn't understand are preserved.
+func (a *CodexAdapter) WritePermissions(perms *permission.Permissions) error {
+	path := filepath.Join(a.rulesDir(), codexManagedRulesFile)
+
+	doc, err := codexrules.LoadFile(a.fs(), path)
+	if os.IsNotExist(err) {
+		doc = &codexrules.Document{}
+	} else if err != nil {
+		return fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if len(doc.Blocks) == 0 {
+		doc.Blocks = []codexrules.Block{{Text: codexManagedRulesHeader + "\n"}}
+	}
+	rules, _ := codexrules.FromPermissions(perms)
+	doc.SetRules(rules)
+
+	if content := strings.TrimSpace(doc.String()); len(doc.Rules()) == 0 && (content == "" || content == codexManagedRulesHeader) {
+		// Nothing to enforce - remove our file if a previous sync wrote it
+		if err := a.fs().Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+
+	if err := a.fs().MkdirAll(a.rulesDir(), 0755); err != nil {
+		return err
+	}
+
+	return a.fs().WriteFile(path, []byte(doc.String()), 0644)
+}
@@ -10,7 +10,9 @@ import (
 )
 
 // ContinueAdapter syncs configuration to Continue.dev
-type ContinueAdapter struct{}
+type ContinueAdapter struct {
+	fsHolder
+}
 
 // ContinueServerConfig represents a server in Continue's config format
 type ContinueServerConfig struct {
@@ -28,6 +30,13 @@ func (a *ContinueAdapter) Name() string {
 	return "continue"
 }
 
+// WithFS returns a copy of the adapter that uses fsys
+func (a *ContinueAdapter) WithFS(fsys FS) Adapter {
+	c := *a
+	c.fsys = fsys
+	return &c
+}
+
 func (a *ContinueAdapter) Detect() (bool, error) {
 	path := a.ConfigPath()
 	if path == "" {
@@ -35,7 +44,7 @@ func (a *ContinueAdapter) Detect() (bool, error) {
 	}
 
 	dir := filepath.Dir(path)
-	if _, err := os.Stat(dir); os.IsNotExist(err) {
+	if _, err := a.fs().Stat(dir); os.IsNotExist(err) {
 		return false, nil
 	}
 
@@ -62,8 +71,22 @@ func (a *ContinueAdapter) SupportedResources() []ResourceType {
 	return []ResourceType{ResourceMCP, ResourceRules}
 }
 
+// StdioOnly returns true - Continue only launches stdio servers
+func (a *ContinueAdapter) StdioOnly() bool {
+	return true
+}
+
+// ManagedPaths returns the global rules file
+func (a *ContinueAdapter) ManagedPaths() []string {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return nil
+	}
+	return []string{filepath.Join(homeDir, ".continue", "rules.md")}
+}
+
 func (a *ContinueAdapter) ReadServers() ([]*mcp.Server, error) {
-	helper := NewJSONConfigHelper(a.ConfigPath())
+	helper := NewJSONConfigHelper(a.fs(), a.ConfigPath())
 	raw, err := helper.LoadRaw()
 	if err != nil {
 		return nil, err
@@ -131,7 +154,7 @@ func (a *ContinueAdapter) readServersFromMCPServers(raw map[string]interface{})
 }
 
 func (a *ContinueAdapter) WriteServers(servers []*mcp.Server) error {
-	helper := NewJSONConfigHelper(a.ConfigPath())
+	helper := NewJSONConfigHelper(a.fs(), a.ConfigPath())
 	raw, err := helper.LoadRaw()
 	if err != nil {
 		return err
@@ -141,7 +164,7 @@ func (a *ContinueAdapter) WriteServers(servers []*mcp.Server) error {
 	RemoveManagedServers(mcpServers)
 
 	// Add new servers
-	for _, server := range servers {
+	for _, server := range FilterStdioServers(servers) {
 		name := GetServerName(server)
 		if name == "" {
 			continue
@@ -161,7 +184,7 @@ func (a *ContinueAdapter) ReadRules() ([]*rule.Rule, error) {
 	}
 
 	rulesPath := filepath.Join(homeDir, ".continue", "rules.md")
-	if _, err := os.Stat(rulesPath); os.IsNotExist(err) {
+	if _, err := a.fs().Stat(rulesPath); os.IsNotExist(err) {
 		return nil, nil
 	}
 
@@ -186,7 +209,7 @@ func (a *ContinueAdapter) WriteRules(rules []*rule.Rule) error {
 	rulesPath := filepath.Join(homeDir, ".continue", "rules.md")
 
 	dir := filepath.Dir(rulesPath)
-	if err := os.MkdirAll(dir, 0755); err != nil {
+	if err := a.fs().MkdirAll(dir, 0755); err != nil {
 		return err
 	}
 
@@ -198,5 +221,5 @@ func (a *ContinueAdapter) WriteRules(rules []*rule.Rule) error {
 		content += r.Content
 	}
 
-	return os.WriteFile(rulesPath, []byte(content), 0644)
+	return a.fs().WriteFile(rulesPath, []byte(content), 0644)
 }
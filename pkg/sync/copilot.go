@@ -15,8 +15,12 @@ import (
 )
 
 // CopilotAdapter syncs configuration to GitHub Copilot CLI
-// Copilot CLI uses ~/.config/github-copilot/ on Unix systems
-type CopilotAdapter struct{}
+// Copilot CLI uses ~/.config/github-copilot/ on Unix systems. Copilot in
+// VS Code reads the project's .vscode/mcp.json, which the VS Code adapter
+// owns, so this adapter has no workspace MCP config of its own.
+type CopilotAdapter struct {
+	fsHolder
+}
 
 // CopilotServerConfig represents a server in Copilot's config format
 type CopilotServerConfig struct {
@@ -41,6 +45,13 @@ func (a *CopilotAdapter) Name() string {
 	return "copilot"
 }
 
+// WithFS returns a copy of the adapter that uses fsys
+func (a *CopilotAdapter) WithFS(fsys FS) Adapter {
+	c := *a
+	c.fsys = fsys
+	return &c
+}
+
 func (a *CopilotAdapter) Detect() (bool, error) {
 	configDir := a.configDir()
 	if configDir == "" {
@@ -48,7 +59,7 @@ func (a *CopilotAdapter) Detect() (bool, error) {
 	}
 
 	// Check if Copilot config directory exists
-	if _, err := os.Stat(configDir); os.IsNotExist(err) {
+	if _, err := a.fs().Stat(configDir); os.IsNotExist(err) {
 		return false, nil
 	}
 
@@ -95,12 +106,22 @@ func (a *CopilotAdapter) agentsDir() string {
 	return filepath.Join(a.configDir(), "agents")
 }
 
+// ManagedPaths returns the command, skill and agent directories and AGENTS.md
+func (a *CopilotAdapter) ManagedPaths() []string {
+	return []string{a.commandsDir(), a.skillsDir(), a.agentsFilePath(), a.agentsDir()}
+}
+
 func (a *CopilotAdapter) SupportedResources() []ResourceType {
 	return []ResourceType{ResourceMCP, ResourceCommands, ResourceRules, ResourceSkills, ResourceAgents}
 }
 
+// StdioOnly returns true - Copilot only launches stdio servers
+func (a *CopilotAdapter) StdioOnly() bool {
+	return true
+}
+
 func (a *CopilotAdapter) ReadServers() ([]*mcp.Server, error) {
-	helper := NewJSONConfigHelper(a.ConfigPath())
+	helper := NewJSONConfigHelper(a.fs(), a.ConfigPath())
 	raw, err := helper.LoadRaw()
 	if err != nil {
 		return nil, err
@@ -111,7 +132,7 @@ func (a *CopilotAdapter) ReadServers() ([]*mcp.Server, error) {
 }
 
 func (a *CopilotAdapter) WriteServers(servers []*mcp.Server) error {
-	helper := NewJSONConfigHelper(a.ConfigPath())
+	helper := NewJSONConfigHelper(a.fs(), a.ConfigPath())
 	raw, err := helper.LoadRaw()
 	if err != nil {
 		return err
@@ -134,18 +155,18 @@ func (a *CopilotAdapter) WriteServers(servers []*mcp.Server) error {
 }
 
 func (a *CopilotAdapter) ReadCommands() ([]*command.Command, error) {
-	return ReadCommandsFromDir(a.commandsDir(), parseCopilotCommand)
+	return ReadCommandsFromDir(a.fs(), a.commandsDir(), parseCopilotCommand)
 }
 
 func (a *CopilotAdapter) WriteCommands(commands []*command.Command) error {
-	return WriteCommandsToDir(a.commandsDir(), commands, formatCopilotCommand)
+	return WriteCommandsToDir(a.fs(), a.commandsDir(), commands, formatCopilotCommand)
 }
 
 func (a *CopilotAdapter) ReadRules() ([]*rule.Rule, error) {
 	// Copilot uses AGENTS.md for instructions
 	agentsPath := a.agentsFilePath()
 
-	if _, err := os.Stat(agentsPath); os.IsNotExist(err) {
+	if _, err := a.fs().Stat(agentsPath); os.IsNotExist(err) {
 		return nil, nil
 	}
 
@@ -174,21 +195,21 @@ func (a *CopilotAdapter) WriteRules(rules []*rule.Rule) error {
 	}
 
 	// Ensure config directory exists
-	if err := os.MkdirAll(a.configDir(), 0755); err != nil {
+	if err := a.fs().MkdirAll(a.configDir(), 0755); err != nil {
 		return err
 	}
 
-	return os.WriteFile(agentsPath, []byte(content.String()), 0644)
+	return a.fs().WriteFile(agentsPath, []byte(content.String()), 0644)
 }
 
 // ReadSkills reads skills from Copilot's skills directory
 func (a *CopilotAdapter) ReadSkills() ([]*skill.Skill, error) {
-	return ReadSkillsFromDir(a.skillsDir())
+	return ReadSkillsFromDir(a.fs(), a.skillsDir())
 }
 
 // WriteSkills writes skills to Copilot's skills directory
 func (a *CopilotAdapter) WriteSkills(skills []*skill.Skill) error {
-	return WriteSkillsToDir(a.skillsDir(), skills)
+	return WriteSkillsToDir(a.fs(), a.skillsDir(), skills)
 }
 
 // parseCopilotCommand parses a Copilot command markdown file
@@ -242,21 +263,28 @@ func formatCopilotCommand(cmd *command.Command) string {
 
 // ReadAgents reads agents from Copilot's agents directory
 func (a *CopilotAdapter) ReadAgents() ([]*agent.Agent, error) {
-	agentsDir := a.agentsDir()
-	return agent.LoadAll(agentsDir)
+	agents, err := agent.LoadAll(a.agentsDir())
+	if err != nil {
+		return nil, err
+	}
+	agentsToCanonical(toolTranslator(a), agents)
+	return agents, nil
 }
 
 // WriteAgents writes agents to Copilot's agents directory
 // Note: GitHub Copilot expects .agent.md extension for agents
 func (a *CopilotAdapter) WriteAgents(agents []*agent.Agent) error {
-	agentsDir := a.agentsDir()
+	return a.writeAgents(a.agentsDir(), agents)
+}
 
+// writeAgents writes agents as .agent.md files to agentsDir
+func (a *CopilotAdapter) writeAgents(agentsDir string, agents []*agent.Agent) error {
 	// Ensure directory exists
-	if err := os.MkdirAll(agentsDir, 0755); err != nil {
+	if err := a.fs().MkdirAll(agentsDir, 0755); err != nil {
 		return err
 	}
 
-	for _, ag := range agents {
+	for _, ag := range agentsToNative(toolTranslator(a), agents) {
 		// Validate agent name to prevent path traversal
 		if err := SanitizeName(ag.Name); err != nil {
 			return fmt.Errorf("invalid agent name: %w", err)
@@ -265,10 +293,102 @@ func (a *CopilotAdapter) WriteAgents(agents []*agent.Agent) error {
 		// Copilot uses .agent.md extension
 		filename := ag.Name + ".agent.md"
 		path := filepath.Join(agentsDir, filename)
-		if err := ag.SaveToFile(path); err != nil {
+		data, err := ag.Markdown()
+		if err != nil {
+			return err
+		}
+		if err := a.fs().WriteFile(path, data, 0644); err != nil {
 			return err
 		}
 	}
 
 	return nil
 }
+
+// WorkspaceResourcesAdapter implementation for Copilot
+
+// workspaceDir returns the project's .github directory
+func (a *CopilotAdapter) workspaceDir(projectDir string) string {
+	return filepath.Join(projectDir, ".github")
+}
+
+// WorkspaceResources returns the resources Copilot reads from .github in a project
+func (a *CopilotAdapter) WorkspaceResources() []ResourceType {
+	return []ResourceType{ResourceCommands, ResourceRules, ResourceSkills, ResourceAgents}
+}
+
+// WorkspacePaths returns the project's prompt, instruction, skill and agent directories
+func (a *CopilotAdapter) WorkspacePaths(projectDir string) []string {
+	dir := a.workspaceDir(projectDir)
+	return []string{filepath.Join(dir, "prompts"), filepath.Join(dir, "instructions"), filepath.Join(dir, "skills"), filepath.Join(dir, "agents")}
+}
+
+// WriteWorkspaceCommands writes commands as prompt files to the project's
+// .github/prompts
+func (a *CopilotAdapter) WriteWorkspaceCommands(projectDir string, commands []*command.Command) error {
+	dir := filepath.Join(a.workspaceDir(projectDir), "prompts")
+	if err := a.fs().MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	for _, cmd := range commands {
+		if err := SanitizeName(cmd.Name); err != nil {
+			return fmt.Errorf("invalid command name: %w", err)
+		}
+
+		// Prompt files need the .prompt.md extension
+		path := filepath.Join(dir, cmd.Name+".prompt.md")
+		if err := a.fs().WriteFile(path, []byte(formatCopilotCommand(cmd)), 0644); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// WriteWorkspaceRules writes rules as instruction files to the project's
+// .github/instructions. A rule's paths or globs become its applyTo
+// pattern; rules without them apply to every file.
+func (a *CopilotAdapter) WriteWorkspaceRules(projectDir string, rules []*rule.Rule) error {
+	dir := filepath.Join(a.workspaceDir(projectDir), "instructions")
+	if err := a.fs().MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	for _, r := range rules {
+		name, err := rule.FileName(r)
+		if err != nil {
+			return err
+		}
+
+		applyTo := []string{"**"}
+		if r.Frontmatter != nil && len(r.Frontmatter.Globs) > 0 {
+			applyTo = r.Frontmatter.Globs
+		} else if r.Frontmatter != nil && len(r.Frontmatter.Paths) > 0 {
+			applyTo = r.Frontmatter.Paths
+		}
+
+		var content strings.Builder
+		content.WriteString("---\n")
+		content.WriteString(fmt.Sprintf("applyTo: %q\n", strings.Join(applyTo, ",")))
+		content.WriteString("---\n\n")
+		content.WriteString(r.Content)
+
+		path := filepath.Join(dir, strings.TrimSuffix(name, ".md")+".instructions.md")
+		if err := a.fs().WriteFile(path, []byte(content.String()), 0644); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// WriteWorkspaceSkills writes skills to the project's .github/skills
+func (a *CopilotAdapter) WriteWorkspaceSkills(projectDir string, skills []*skill.Skill) error {
+	return WriteSkillsToDir(a.fs(), filepath.Join(a.workspaceDir(projectDir), "skills"), skills)
+}
+
+// WriteWorkspaceAgents writes agents to the project's .github/agents
+func (a *CopilotAdapter) WriteWorkspaceAgents(projectDir string, agents []*agent.Agent) error {
+	return a.writeAgents(filepath.Join(a.workspaceDir(projectDir), "agents"), agents)
+}
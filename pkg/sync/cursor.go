@@ -11,10 +11,13 @@ import (
 	"github.com/iheanyi/agentctl/pkg/command"
 	"github.com/iheanyi/agentctl/pkg/mcp"
 	"github.com/iheanyi/agentctl/pkg/rule"
+	"github.com/iheanyi/agentctl/pkg/skill"
 )
 
 // CursorAdapter syncs configuration to Cursor
-type CursorAdapter struct{}
+type CursorAdapter struct {
+	fsHolder
+}
 
 // CursorServerConfig represents a server in Cursor's config format
 type CursorServerConfig struct {
@@ -32,6 +35,13 @@ func (a *CursorAdapter) Name() string {
 	return "cursor"
 }
 
+// WithFS returns a copy of the adapter that uses fsys
+func (a *CursorAdapter) WithFS(fsys FS) Adapter {
+	c := *a
+	c.fsys = fsys
+	return &c
+}
+
 func (a *CursorAdapter) Detect() (bool, error) {
 	path := a.ConfigPath()
 	if path == "" {
@@ -40,7 +50,7 @@ func (a *CursorAdapter) Detect() (bool, error) {
 
 	// Check if Cursor directory exists
 	dir := filepath.Dir(path)
-	if _, err := os.Stat(dir); os.IsNotExist(err) {
+	if _, err := a.fs().Stat(dir); os.IsNotExist(err) {
 		return false, nil
 	}
 
@@ -77,12 +87,22 @@ func (a *CursorAdapter) agentsDir() string {
 	return filepath.Join(a.configDir(), "agents")
 }
 
+// ManagedPaths returns the rule, command and agent directories
+func (a *CursorAdapter) ManagedPaths() []string {
+	return []string{a.rulesDir(), a.commandsDir(), a.agentsDir()}
+}
+
 func (a *CursorAdapter) SupportedResources() []ResourceType {
 	return []ResourceType{ResourceMCP, ResourceRules, ResourceCommands, ResourceAgents}
 }
 
+// StdioOnly returns true - Cursor only launches stdio servers
+func (a *CursorAdapter) StdioOnly() bool {
+	return true
+}
+
 func (a *CursorAdapter) ReadServers() ([]*mcp.Server, error) {
-	helper := NewJSONConfigHelper(a.ConfigPath())
+	helper := NewJSONConfigHelper(a.fs(), a.ConfigPath())
 	raw, err := helper.LoadRaw()
 	if err != nil {
 		return nil, err
@@ -93,7 +113,7 @@ func (a *CursorAdapter) ReadServers() ([]*mcp.Server, error) {
 }
 
 func (a *CursorAdapter) WriteServers(servers []*mcp.Server) error {
-	helper := NewJSONConfigHelper(a.ConfigPath())
+	helper := NewJSONConfigHelper(a.fs(), a.ConfigPath())
 	raw, err := helper.LoadRaw()
 	if err != nil {
 		return err
@@ -116,11 +136,11 @@ func (a *CursorAdapter) WriteServers(servers []*mcp.Server) error {
 }
 
 func (a *CursorAdapter) ReadCommands() ([]*command.Command, error) {
-	return ReadCommandsFromDir(a.commandsDir(), parseCursorCommand)
+	return ReadCommandsFromDir(a.fs(), a.commandsDir(), parseCursorCommand)
 }
 
 func (a *CursorAdapter) WriteCommands(commands []*command.Command) error {
-	return WriteCommandsToDir(a.commandsDir(), commands, formatCursorCommand)
+	return WriteCommandsToDir(a.fs(), a.commandsDir(), commands, formatCursorCommand)
 }
 
 func (a *CursorAdapter) ReadRules() ([]*rule.Rule, error) {
@@ -128,7 +148,7 @@ func (a *CursorAdapter) ReadRules() ([]*rule.Rule, error) {
 
 	// First check modern .cursor/rules/ directory (takes priority)
 	rulesDir := a.rulesDir()
-	if entries, err := os.ReadDir(rulesDir); err == nil {
+	if entries, err := a.fs().ReadDir(rulesDir); err == nil {
 		for _, entry := range entries {
 			if entry.IsDir() {
 				continue
@@ -155,7 +175,7 @@ func (a *CursorAdapter) ReadRules() ([]*rule.Rule, error) {
 		}
 
 		legacyPath := filepath.Join(homeDir, ".cursorrules")
-		if _, err := os.Stat(legacyPath); err == nil {
+		if _, err := a.fs().Stat(legacyPath); err == nil {
 			r, err := rule.Load(legacyPath)
 			if err == nil {
 				rules = append(rules, r)
@@ -167,20 +187,23 @@ func (a *CursorAdapter) ReadRules() ([]*rule.Rule, error) {
 }
 
 func (a *CursorAdapter) WriteRules(rules []*rule.Rule) error {
+	return a.writeRules(a.rulesDir(), rules)
+}
+
+// writeRules writes rules as .mdc files to rulesDir
+func (a *CursorAdapter) writeRules(rulesDir string, rules []*rule.Rule) error {
 	if len(rules) == 0 {
 		return nil
 	}
 
-	rulesDir := a.rulesDir()
-
 	// Ensure directory exists
-	if err := os.MkdirAll(rulesDir, 0755); err != nil {
+	if err := a.fs().MkdirAll(rulesDir, 0755); err != nil {
 		return err
 	}
 
 	// Write each rule as a .mdc file
 	for _, r := range rules {
-		if err := saveCursorRule(r, rulesDir); err != nil {
+		if err := saveCursorRule(a.fs(), r, rulesDir); err != nil {
 			return err
 		}
 	}
@@ -203,7 +226,7 @@ func loadCursorRule(path string) (*rule.Rule, error) {
 }
 
 // saveCursorRule saves a rule in Cursor's .mdc format
-func saveCursorRule(r *rule.Rule, dir string) error {
+func saveCursorRule(fsys FS, r *rule.Rule, dir string) error {
 	var content strings.Builder
 
 	// Write Cursor-style frontmatter if we have glob patterns
@@ -249,7 +272,7 @@ func saveCursorRule(r *rule.Rule, dir string) error {
 	}
 
 	path := filepath.Join(dir, name)
-	return os.WriteFile(path, []byte(content.String()), 0644)
+	return fsys.WriteFile(path, []byte(content.String()), 0644)
 }
 
 // parseCursorCommand parses a Cursor command markdown file
@@ -306,7 +329,7 @@ func (a *CursorAdapter) WorkspaceConfigPath(projectDir string) string {
 
 // ReadWorkspaceServers reads MCP servers from the project's .cursor/mcp.json file
 func (a *CursorAdapter) ReadWorkspaceServers(projectDir string) ([]*mcp.Server, error) {
-	helper := NewJSONConfigHelper(a.WorkspaceConfigPath(projectDir))
+	helper := NewJSONConfigHelper(a.fs(), a.WorkspaceConfigPath(projectDir))
 	raw, err := helper.LoadRaw()
 	if err != nil {
 		return nil, err
@@ -325,7 +348,7 @@ func (a *CursorAdapter) ReadWorkspaceServers(projectDir string) ([]*mcp.Server,
 
 // WriteWorkspaceServers writes MCP servers to the project's .cursor/mcp.json file
 func (a *CursorAdapter) WriteWorkspaceServers(projectDir string, servers []*mcp.Server) error {
-	helper := NewJSONConfigHelper(a.WorkspaceConfigPath(projectDir))
+	helper := NewJSONConfigHelper(a.fs(), a.WorkspaceConfigPath(projectDir))
 	raw, err := helper.LoadRaw()
 	if err != nil {
 		return err
@@ -347,6 +370,44 @@ func (a *CursorAdapter) WriteWorkspaceServers(projectDir string, servers []*mcp.
 	return helper.SaveRaw(raw)
 }
 
+// WorkspaceResourcesAdapter implementation for Cursor
+
+// workspaceDir returns the project's .cursor directory
+func (a *CursorAdapter) workspaceDir(projectDir string) string {
+	return filepath.Join(projectDir, ".cursor")
+}
+
+// WorkspaceResources returns the resources Cursor reads from .cursor in a project
+func (a *CursorAdapter) WorkspaceResources() []ResourceType {
+	return []ResourceType{ResourceCommands, ResourceRules, ResourceAgents}
+}
+
+// WorkspacePaths returns the project's rule, command and agent directories
+func (a *CursorAdapter) WorkspacePaths(projectDir string) []string {
+	dir := a.workspaceDir(projectDir)
+	return []string{filepath.Join(dir, "rules"), filepath.Join(dir, "commands"), filepath.Join(dir, "agents")}
+}
+
+// WriteWorkspaceCommands writes commands to the project's .cursor/commands
+func (a *CursorAdapter) WriteWorkspaceCommands(projectDir string, commands []*command.Command) error {
+	return WriteCommandsToDir(a.fs(), filepath.Join(a.workspaceDir(projectDir), "commands"), commands, formatCursorCommand)
+}
+
+// WriteWorkspaceRules writes rules to the project's .cursor/rules
+func (a *CursorAdapter) WriteWorkspaceRules(projectDir string, rules []*rule.Rule) error {
+	return a.writeRules(filepath.Join(a.workspaceDir(projectDir), "rules"), rules)
+}
+
+// WriteWorkspaceSkills does nothing - Cursor has no skills
+func (a *CursorAdapter) WriteWorkspaceSkills(projectDir string, skills []*skill.Skill) error {
+	return nil
+}
+
+// WriteWorkspaceAgents writes agents to the project's .cursor/agents
+func (a *CursorAdapter) WriteWorkspaceAgents(projectDir string, agents []*agent.Agent) error {
+	return WriteAgentsToDir(a.fs(), filepath.Join(a.workspaceDir(projectDir), "agents"), agents)
+}
+
 // AgentsAdapter implementation for Cursor
 
 // ReadAgents reads agents from Cursor's agents directory
@@ -357,5 +418,5 @@ func (a *CursorAdapter) ReadAgents() ([]*agent.Agent, error) {
 
 // WriteAgents writes agents to Cursor's agents directory
 func (a *CursorAdapter) WriteAgents(agents []*agent.Agent) error {
-	return WriteAgentsToDir(a.agentsDir(), agents)
+	return WriteAgentsToDir(a.fs(), a.agentsDir(), agents)
 }
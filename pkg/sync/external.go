@@ -0,0 +1,308 @@
+package sync
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/iheanyi/agentctl/pkg/agent"
+	"github.com/iheanyi/agentctl/pkg/command"
+	"github.com/iheanyi/agentctl/pkg/mcp"
+	"github.com/iheanyi/agentctl/pkg/rule"
+	"github.com/iheanyi/agentctl/pkg/skill"
+)
+
+// ExternalAdapterPrefix starts the file name of an external adapter
+// executable. agentctl-adapter-aider provides the "aider" adapter.
+const ExternalAdapterPrefix = "agentctl-adapter-"
+
+// ExternalProtocolVersion is the version of the protocol spoken with
+// external adapters, sent with every request
+const ExternalProtocolVersion = 1
+
+// externalTimeout bounds each call to an external adapter
+const externalTimeout = 30 * time.Second
+
+// ExternalAdapter is an adapter implemented by an executable outside
+// agentctl. Each call runs the executable with one JSON request on stdin
+// and reads one JSON response from stdout:
+//
+//	{"version": 1, "method": "detect"}                        -> {"installed": true}
+//	{"version": 1, "method": "configPath"}                    -> {"path": "/home/me/.tool/config.json"}
+//	{"version": 1, "method": "supportedResources"}            -> {"resources": ["mcp", "rules"]}
+//	{"version": 1, "method": "read", "resource": "mcp"}       -> {"items": [...]}
+//	{"version": 1, "method": "write", "resource": "mcp", "items": [...]} -> {}
+//
+// Items are servers, commands, rules, skills or agents as they appear in
+// agentctl's own JSON. A response with an "error" string, or a non-zero
+// exit, fails the call. The executable writes the tool's files itself.
+type ExternalAdapter struct {
+	name string
+	path string
+
+	describeOnce sync.Once
+	configPath   string
+	resources    []ResourceType
+}
+
+// NewExternalAdapter returns an adapter named name that runs the
+// executable at path
+func NewExternalAdapter(name, path string) *ExternalAdapter {
+	return &ExternalAdapter{name: name, path: path}
+}
+
+type externalRequest struct {
+	Version  int          `json:"version"`
+	Method   string       `json:"method"`
+	Resource ResourceType `json:"resource,omitempty"`
+	Items    any          `json:"items,omitempty"`
+}
+
+type externalResponse struct {
+	Installed bool            `json:"installed"`
+	Path      string          `json:"path"`
+	Resources []ResourceType  `json:"resources"`
+	Items     json.RawMessage `json:"items"`
+	Error     string          `json:"error"`
+}
+
+// externalSkill carries the fields a skill leaves out of its JSON, so the
+// executable can copy the skill's directory
+type externalSkill struct {
+	*skill.Skill
+	Content string `json:"content,omitempty"`
+	Path    string `json:"path,omitempty"`
+}
+
+func (a *ExternalAdapter) Name() string {
+	return a.name
+}
+
+// Path returns the path of the adapter's executable
+func (a *ExternalAdapter) Path() string {
+	return a.path
+}
+
+func (a *ExternalAdapter) Detect() (bool, error) {
+	var resp externalResponse
+	if err := a.call(externalRequest{Method: "detect"}, &resp); err != nil {
+		return false, err
+	}
+	return resp.Installed, nil
+}
+
+func (a *ExternalAdapter) ConfigPath() string {
+	a.describe()
+	return a.configPath
+}
+
+func (a *ExternalAdapter) SupportedResources() []ResourceType {
+	a.describe()
+	return a.resources
+}
+
+// describe asks the executable for its config path and resources once.
+// An adapter that can't answer has no config path and supports nothing.
+func (a *ExternalAdapter) describe() {
+	a.describeOnce.Do(func() {
+		var resp externalResponse
+		if err := a.call(externalRequest{Method: "configPath"}, &resp); err == nil {
+			a.configPath = resp.Path
+		}
+		resp = externalResponse{}
+		if err := a.call(externalRequest{Method: "supportedResources"}, &resp); err == nil {
+			a.resources = resp.Resources
+		}
+	})
+}
+
+func (a *ExternalAdapter) supports(rt ResourceType) bool {
+	return containsResource(a.SupportedResources(), rt)
+}
+
+// call runs the executable with req and decodes its reply into resp
+func (a *ExternalAdapter) call(req externalRequest, resp *externalResponse) error {
+	req.Version = ExternalProtocolVersion
+	in, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), externalTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, a.path)
+	cmd.Stdin = bytes.NewReader(in)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("%s adapter %s: %w: %s", a.name, req.Method, err, msg)
+		}
+		return fmt.Errorf("%s adapter %s: %w", a.name, req.Method, err)
+	}
+
+	if err := json.Unmarshal(out, resp); err != nil {
+		return fmt.Errorf("%s adapter %s: invalid response: %w", a.name, req.Method, err)
+	}
+	if resp.Error != "" {
+		return fmt.Errorf("%s adapter %s: %s", a.name, req.Method, resp.Error)
+	}
+	return nil
+}
+
+// read fetches the items of resource type rt into items
+func (a *ExternalAdapter) read(rt ResourceType, items any) error {
+	if !a.supports(rt) {
+		return nil
+	}
+	var resp externalResponse
+	if err := a.call(externalRequest{Method: "read", Resource: rt}, &resp); err != nil {
+		return err
+	}
+	if len(resp.Items) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(resp.Items, items); err != nil {
+		return fmt.Errorf("%s adapter read %s: %w", a.name, rt, err)
+	}
+	return nil
+}
+
+// write sends the items of resource type rt
+func (a *ExternalAdapter) write(rt ResourceType, items any) error {
+	if !a.supports(rt) {
+		return nil
+	}
+	var resp externalResponse
+	return a.call(externalRequest{Method: "write", Resource: rt, Items: items}, &resp)
+}
+
+func (a *ExternalAdapter) ReadServers() ([]*mcp.Server, error) {
+	var servers []*mcp.Server
+	err := a.read(ResourceMCP, &servers)
+	return servers, err
+}
+
+func (a *ExternalAdapter) WriteServers(servers []*mcp.Server) error {
+	return a.write(ResourceMCP, append([]*mcp.Server{}, servers...))
+}
+
+func (a *ExternalAdapter) ReadCommands() ([]*command.Command, error) {
+	var commands []*command.Command
+	err := a.read(ResourceCommands, &commands)
+	return commands, err
+}
+
+func (a *ExternalAdapter) WriteCommands(commands []*command.Command) error {
+	return a.write(ResourceCommands, append([]*command.Command{}, commands...))
+}
+
+func (a *ExternalAdapter) ReadRules() ([]*rule.Rule, error) {
+	var rules []*rule.Rule
+	err := a.read(ResourceRules, &rules)
+	return rules, err
+}
+
+func (a *ExternalAdapter) WriteRules(rules []*rule.Rule) error {
+	return a.write(ResourceRules, append([]*rule.Rule{}, rules...))
+}
+
+func (a *ExternalAdapter) ReadSkills() ([]*skill.Skill, error) {
+	var wire []externalSkill
+	if err := a.read(ResourceSkills, &wire); err != nil {
+		return nil, err
+	}
+	var skills []*skill.Skill
+	for _, w := range wire {
+		if w.Skill == nil {
+			continue
+		}
+		w.Skill.Content = w.Content
+		w.Skill.Path = w.Path
+		skills = append(skills, w.Skill)
+	}
+	return skills, nil
+}
+
+func (a *ExternalAdapter) WriteSkills(skills []*skill.Skill) error {
+	wire := make([]externalSkill, 0, len(skills))
+	for _, s := range skills {
+		wire = append(wire, externalSkill{Skill: s, Content: s.Content, Path: s.Path})
+	}
+	return a.write(ResourceSkills, wire)
+}
+
+func (a *ExternalAdapter) ReadAgents() ([]*agent.Agent, error) {
+	var agents []*agent.Agent
+	err := a.read(ResourceAgents, &agents)
+	return agents, err
+}
+
+func (a *ExternalAdapter) WriteAgents(agents []*agent.Agent) error {
+	return a.write(ResourceAgents, append([]*agent.Agent{}, agents...))
+}
+
+// FindExternalAdapters returns the external adapter executables in dirs.
+// When two dirs hold an adapter of the same name, the earlier one wins.
+func FindExternalAdapters(dirs []string) []*ExternalAdapter {
+	var found []*ExternalAdapter
+	seen := make(map[string]bool)
+	for _, dir := range dirs {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			name, ok := externalAdapterName(entry.Name())
+			if !ok || seen[name] || entry.IsDir() {
+				continue
+			}
+			path := filepath.Join(dir, entry.Name())
+			if !isExecutable(path) {
+				continue
+			}
+			seen[name] = true
+			found = append(found, NewExternalAdapter(name, path))
+		}
+	}
+	return found
+}
+
+// LoadExternalAdapters registers the external adapters in configDir's
+// adapters directory and on PATH. Built-in adapters keep their names.
+func LoadExternalAdapters(configDir string) {
+	dirs := []string{filepath.Join(configDir, "adapters")}
+	dirs = append(dirs, filepath.SplitList(os.Getenv("PATH"))...)
+	for _, a := range FindExternalAdapters(dirs) {
+		if _, exists := registry[a.Name()]; exists {
+			continue
+		}
+		Register(a)
+	}
+}
+
+// externalAdapterName returns the adapter name in an executable's file name
+func externalAdapterName(file string) (string, bool) {
+	if runtime.GOOS == "windows" {
+		file = strings.TrimSuffix(strings.ToLower(file), ".exe")
+	}
+	name, ok := strings.CutPrefix(file, ExternalAdapterPrefix)
+	return name, ok && name != ""
+}
+
+func isExecutable(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil || info.IsDir() {
+		return false
+	}
+	return runtime.GOOS == "windows" || info.Mode()&0111 != 0
+}
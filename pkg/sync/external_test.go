@@ -0,0 +1,140 @@
+package sync
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/iheanyi/agentctl/pkg/mcp"
+	"github.com/iheanyi/agentctl/pkg/skill"
+)
+
+// testAdapterDirEnv makes the test binary act as an external adapter that
+// keeps each resource's items in a file in the named directory
+const testAdapterDirEnv = "AGENTCTL_TEST_ADAPTER_DIR"
+
+func TestMain(m *testing.M) {
+	if dir := os.Getenv(testAdapterDirEnv); dir != "" {
+		runTestAdapter(dir)
+		return
+	}
+	os.Exit(m.Run())
+}
+
+func runTestAdapter(dir string) {
+	var req externalRequest
+	if err := json.NewDecoder(os.Stdin).Decode(&req); err != nil {
+		os.Exit(2)
+	}
+	resp := map[string]any{}
+	switch req.Method {
+	case "detect":
+		resp["installed"] = true
+	case "configPath":
+		resp["path"] = filepath.Join(dir, "config.json")
+	case "supportedResources":
+		resp["resources"] = []ResourceType{ResourceMCP, ResourceSkills}
+	case "read":
+		data, err := os.ReadFile(filepath.Join(dir, string(req.Resource)+".json"))
+		if err == nil {
+			resp["items"] = json.RawMessage(data)
+		}
+	case "write":
+		data, _ := json.Marshal(req.Items)
+		if err := os.WriteFile(filepath.Join(dir, string(req.Resource)+".json"), data, 0644); err != nil {
+			resp["error"] = err.Error()
+		}
+	default:
+		resp["error"] = "unknown method " + req.Method
+	}
+	_ = json.NewEncoder(os.Stdout).Encode(resp)
+}
+
+func TestExternalAdapter(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv(testAdapterDirEnv, dir)
+	exe, err := os.Executable()
+	if err != nil {
+		t.Fatalf("Executable failed: %v", err)
+	}
+	a := NewExternalAdapter("harness", exe)
+
+	if installed, err := a.Detect(); err != nil || !installed {
+		t.Fatalf("Detect() = %v, %v", installed, err)
+	}
+	if got := a.ConfigPath(); got != filepath.Join(dir, "config.json") {
+		t.Errorf("ConfigPath() = %q", got)
+	}
+	if !SupportsServers(a) || !containsResource(a.SupportedResources(), ResourceSkills) || containsResource(a.SupportedResources(), ResourceRules) {
+		t.Errorf("SupportedResources() = %v", a.SupportedResources())
+	}
+
+	servers := []*mcp.Server{{Name: "fs", Command: "fs-mcp", Args: []string{"."}}}
+	if err := a.WriteServers(servers); err != nil {
+		t.Fatalf("WriteServers failed: %v", err)
+	}
+	got, err := a.ReadServers()
+	if err != nil || len(got) != 1 || got[0].Name != "fs" || got[0].Command != "fs-mcp" {
+		t.Errorf("ReadServers() = %+v, %v", got, err)
+	}
+
+	// Skills carry their content and directory
+	skills := []*skill.Skill{{Name: "deploy", Content: "Run make deploy", Path: "/skills/deploy"}}
+	if err := a.WriteSkills(skills); err != nil {
+		t.Fatalf("WriteSkills failed: %v", err)
+	}
+	gotSkills, err := a.ReadSkills()
+	if err != nil || len(gotSkills) != 1 || gotSkills[0].Content != "Run make deploy" || gotSkills[0].Path != "/skills/deploy" {
+		t.Errorf("ReadSkills() = %+v, %v", gotSkills, err)
+	}
+
+	// Resources the adapter doesn't support are never sent
+	if err := a.WriteRules(nil); err != nil {
+		t.Errorf("WriteRules failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "rules.json")); err == nil {
+		t.Error("rules were sent to an adapter that doesn't support them")
+	}
+}
+
+func TestFindExternalAdapters(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("executable bits don't apply on Windows")
+	}
+	first, second := t.TempDir(), t.TempDir()
+	for _, f := range []struct {
+		dir, name string
+		mode      os.FileMode
+	}{
+		{first, "agentctl-adapter-aider", 0755},
+		{second, "agentctl-adapter-aider", 0755},
+		{second, "agentctl-adapter-goose", 0755},
+		{second, "agentctl-adapter-notes", 0644},
+		{second, "agentctl-adapter-", 0755},
+		{second, "other-tool", 0755},
+	} {
+		if err := os.WriteFile(filepath.Join(f.dir, f.name), []byte("#!/bin/sh\n"), f.mode); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	found := FindExternalAdapters([]string{first, second, filepath.Join(first, "missing")})
+	got := make(map[string]string)
+	for _, a := range found {
+		got[a.Name()] = a.Path()
+	}
+	want := map[string]string{
+		"aider": filepath.Join(first, "agentctl-adapter-aider"),
+		"goose": filepath.Join(second, "agentctl-adapter-goose"),
+	}
+	if len(got) != len(want) {
+		t.Fatalf("FindExternalAdapters() = %v, want %v", got, want)
+	}
+	for name, path := range want {
+		if got[name] != path {
+			t.Errorf("adapter %q = %q, want %q", name, got[name], path)
+		}
+	}
+}
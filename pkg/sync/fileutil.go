@@ -228,6 +228,11 @@ func isLockBusyWindows(err error) bool {
 // CreateBackup creates a timestamped backup of the file at the given path.
 // Returns the backup path or empty string if the source file doesn't exist.
 func CreateBackup(path string) (string, error) {
+	return createBackupIn(path, filepath.Dir(path))
+}
+
+// createBackupIn creates a timestamped backup of path in dir
+func createBackupIn(path, dir string) (string, error) {
 	// Check if source file exists
 	if _, err := os.Stat(path); os.IsNotExist(err) {
 		return "", nil
@@ -237,11 +242,14 @@ func CreateBackup(path string) (string, error) {
 
 	// Generate timestamp-based backup filename (include nanoseconds for uniqueness)
 	timestamp := time.Now().Format("20060102-150405.000000000")
-	ext := filepath.Ext(path)
-	base := strings.TrimSuffix(path, ext)
-	backupPath := fmt.Sprintf("%s%s.%s%s", base, BackupSuffix, timestamp, ext)
+	base := filepath.Base(path)
+	ext := filepath.Ext(base)
+	backupPath := filepath.Join(dir, fmt.Sprintf("%s%s.%s%s", strings.TrimSuffix(base, ext), BackupSuffix, timestamp, ext))
 
 	// Copy file to backup location
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("creating backup directory: %w", err)
+	}
 	if err := copyFile(path, backupPath); err != nil {
 		return "", fmt.Errorf("creating backup: %w", err)
 	}
@@ -271,36 +279,20 @@ func CreateSimpleBackup(path string) (string, error) {
 // RotateBackups keeps only the most recent N backups for the given file.
 // It looks for files matching the pattern: base.bak.TIMESTAMP.ext
 func RotateBackups(path string, keepCount int) error {
+	return rotateBackupsIn(path, filepath.Dir(path), keepCount)
+}
+
+// rotateBackupsIn keeps only the most recent N backups of path in dir
+func rotateBackupsIn(path, dir string, keepCount int) error {
 	if keepCount < 0 {
 		keepCount = DefaultBackupCount
 	}
 
-	dir := filepath.Dir(path)
-	base := filepath.Base(path)
-	ext := filepath.Ext(base)
-	nameWithoutExt := strings.TrimSuffix(base, ext)
-	prefix := nameWithoutExt + BackupSuffix + "."
-
-	entries, err := os.ReadDir(dir)
+	backups, err := timestampedBackups(path, dir)
 	if err != nil {
-		if os.IsNotExist(err) {
-			return nil
-		}
-		return fmt.Errorf("reading directory: %w", err)
-	}
-
-	// Find all backup files
-	var backups []string
-	for _, entry := range entries {
-		name := entry.Name()
-		if strings.HasPrefix(name, prefix) && strings.HasSuffix(name, ext) {
-			backups = append(backups, filepath.Join(dir, name))
-		}
+		return err
 	}
 
-	// Sort by name (timestamp is in name, so lexicographic sort works)
-	sort.Strings(backups)
-
 	// Remove oldest backups beyond keepCount
 	if len(backups) > keepCount {
 		toRemove := backups[:len(backups)-keepCount]
@@ -314,6 +306,37 @@ func RotateBackups(path string, keepCount int) error {
 	return nil
 }
 
+// timestampedBackups returns the timestamped backups of path in each of
+// dirs, sorted from oldest to newest
+func timestampedBackups(path string, dirs ...string) ([]string, error) {
+	base := filepath.Base(path)
+	ext := filepath.Ext(base)
+	prefix := strings.TrimSuffix(base, ext) + BackupSuffix + "."
+
+	var backups []string
+	for _, dir := range dirs {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, fmt.Errorf("reading directory: %w", err)
+		}
+		for _, entry := range entries {
+			name := entry.Name()
+			if strings.HasPrefix(name, prefix) && strings.HasSuffix(name, ext) {
+				backups = append(backups, filepath.Join(dir, name))
+			}
+		}
+	}
+
+	// Sort by name (timestamp is in name, so lexicographic sort works)
+	sort.Slice(backups, func(i, j int) bool {
+		return filepath.Base(backups[i]) < filepath.Base(backups[j])
+	})
+	return backups, nil
+}
+
 // RestoreBackup restores the most recent backup for the given file.
 // Returns the backup path that was restored, or empty string if no backup exists.
 func RestoreBackup(path string) (string, error) {
@@ -326,32 +349,16 @@ func RestoreBackup(path string) (string, error) {
 		return simpleBak, nil
 	}
 
-	// Look for timestamped backups
-	dir := filepath.Dir(path)
-	base := filepath.Base(path)
-	ext := filepath.Ext(base)
-	nameWithoutExt := strings.TrimSuffix(base, ext)
-	prefix := nameWithoutExt + BackupSuffix + "."
-
-	entries, err := os.ReadDir(dir)
+	// Look for timestamped backups, next to the file or where sync keeps them
+	backups, err := timestampedBackups(path, filepath.Dir(path), backupDir(path))
 	if err != nil {
-		return "", fmt.Errorf("reading directory: %w", err)
+		return "", err
 	}
-
-	var backups []string
-	for _, entry := range entries {
-		name := entry.Name()
-		if strings.HasPrefix(name, prefix) && strings.HasSuffix(name, ext) {
-			backups = append(backups, filepath.Join(dir, name))
-		}
-	}
-
 	if len(backups) == 0 {
 		return "", nil
 	}
 
-	// Sort and get most recent (last in sorted order)
-	sort.Strings(backups)
+	// Most recent is last in sorted order
 	mostRecent := backups[len(backups)-1]
 
 	if err := copyFile(mostRecent, path); err != nil {
@@ -362,7 +369,8 @@ func RestoreBackup(path string) (string, error) {
 }
 
 // ListBackups returns a list of all backup files for the given path,
-// sorted from oldest to newest.
+// sorted from oldest to newest. This includes the backups sync keeps in
+// the cache directory.
 func ListBackups(path string) ([]string, error) {
 	var backups []string
 
@@ -372,33 +380,11 @@ func ListBackups(path string) ([]string, error) {
 		backups = append(backups, simpleBak)
 	}
 
-	// Look for timestamped backups
-	dir := filepath.Dir(path)
-	base := filepath.Base(path)
-	ext := filepath.Ext(base)
-	nameWithoutExt := strings.TrimSuffix(base, ext)
-	prefix := nameWithoutExt + BackupSuffix + "."
-
-	entries, err := os.ReadDir(dir)
+	timestamped, err := timestampedBackups(path, filepath.Dir(path), backupDir(path))
 	if err != nil {
-		if os.IsNotExist(err) {
-			return backups, nil
-		}
-		return nil, fmt.Errorf("reading directory: %w", err)
+		return nil, err
 	}
-
-	var timestamped []string
-	for _, entry := range entries {
-		name := entry.Name()
-		if strings.HasPrefix(name, prefix) && strings.HasSuffix(name, ext) {
-			timestamped = append(timestamped, filepath.Join(dir, name))
-		}
-	}
-
-	sort.Strings(timestamped)
-	backups = append(backups, timestamped...)
-
-	return backups, nil
+	return append(backups, timestamped...), nil
 }
 
 // SafeWriteFile combines backup creation, atomic write, and backup rotation.
@@ -0,0 +1,367 @@
+package sync
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/iheanyi/agentctl/pkg/config"
+)
+
+// FS is the filesystem adapters read and write tool configs through.
+// Adapters use DefaultFS unless given another with WithFS.
+type FS interface {
+	ReadFile(path string) ([]byte, error)
+	ReadDir(path string) ([]os.DirEntry, error)
+	Stat(path string) (os.FileInfo, error)
+	WriteFile(path string, data []byte, perm os.FileMode) error
+	MkdirAll(path string, perm os.FileMode) error
+	Remove(path string) error
+}
+
+// OSFS is the real filesystem. Every write locks the file, backs up its
+// previous contents and replaces it atomically, so an interrupted or
+// concurrent sync can't leave a tool's config half-written.
+type OSFS struct {
+	// Backups is how many timestamped backups to keep per file
+	Backups int
+}
+
+// DefaultFS is the filesystem adapters use unless given another
+var DefaultFS FS = &OSFS{Backups: DefaultBackupCount}
+
+func (o *OSFS) ReadFile(path string) ([]byte, error) { return os.ReadFile(path) }
+
+func (o *OSFS) ReadDir(path string) ([]os.DirEntry, error) { return os.ReadDir(path) }
+
+func (o *OSFS) Stat(path string) (os.FileInfo, error) { return os.Stat(path) }
+
+func (o *OSFS) MkdirAll(path string, perm os.FileMode) error { return os.MkdirAll(path, perm) }
+
+// WriteFile writes data to path under a lock, backing up the file first.
+// Writing the contents a file already has is a no-op.
+func (o *OSFS) WriteFile(path string, data []byte, perm os.FileMode) error {
+	lock := fileLock(path)
+	if err := lock.Lock(); err != nil {
+		return fmt.Errorf("acquiring lock: %w", err)
+	}
+	defer func() { _ = lock.Unlock() }()
+
+	if existing, err := os.ReadFile(path); err == nil && bytes.Equal(existing, data) {
+		return nil
+	}
+	if err := o.backup(path); err != nil {
+		return err
+	}
+	if err := AtomicWriteFile(path, data, perm); err != nil {
+		return fmt.Errorf("atomic write: %w", err)
+	}
+	return nil
+}
+
+// Remove deletes path under a lock, backing it up first
+func (o *OSFS) Remove(path string) error {
+	lock := fileLock(path)
+	if err := lock.Lock(); err != nil {
+		return fmt.Errorf("acquiring lock: %w", err)
+	}
+	defer func() { _ = lock.Unlock() }()
+
+	if err := o.backup(path); err != nil {
+		return err
+	}
+	return os.Remove(path)
+}
+
+// fileLock returns the lock for writes to path. Lock files live in
+// agentctl's cache directory so none are left next to tool configs or in
+// project directories.
+func fileLock(path string) *FileLock {
+	return NewFileLock(filepath.Join(config.DefaultCacheDir(), "locks", pathKey(path)))
+}
+
+// backupDir returns the directory OSFS keeps backups of path in. Like
+// lock files, backups live in the cache directory: tools load every
+// matching file in their config directories (Codex reads all *.rules,
+// Claude Code every .md command) and would pick up a copy left there.
+func backupDir(path string) string {
+	return filepath.Join(config.DefaultCacheDir(), "backups", pathKey(path))
+}
+
+// pathKey returns a short, stable key for path's absolute form
+func pathKey(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	sum := sha256.Sum256([]byte(path))
+	return hex.EncodeToString(sum[:8])
+}
+
+// backup copies path aside before it changes, keeping the newest
+// o.Backups copies
+func (o *OSFS) backup(path string) error {
+	dir := backupDir(path)
+	if _, err := createBackupIn(path, dir); err != nil {
+		return fmt.Errorf("creating backup: %w", err)
+	}
+	// Rotation failing doesn't affect the write
+	_ = rotateBackupsIn(path, dir, o.Backups)
+	return nil
+}
+
+// MemFS is an in-memory filesystem. Files it hasn't written are read
+// from its base, so a MemFS over DefaultFS previews a sync without
+// changing anything; with no base it's empty, for tests.
+type MemFS struct {
+	base FS
+
+	mu      sync.Mutex
+	files   map[string]*memFile
+	dirs    map[string]bool
+	removed map[string]bool
+}
+
+type memFile struct {
+	data []byte
+	perm os.FileMode
+}
+
+// NewMemFS returns an in-memory filesystem over base (which may be nil)
+func NewMemFS(base FS) *MemFS {
+	return &MemFS{
+		base:    base,
+		files:   make(map[string]*memFile),
+		dirs:    make(map[string]bool),
+		removed: make(map[string]bool),
+	}
+}
+
+func (m *MemFS) ReadFile(path string) ([]byte, error) {
+	path = filepath.Clean(path)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if f, ok := m.files[path]; ok {
+		return bytes.Clone(f.data), nil
+	}
+	if m.removed[path] || m.base == nil {
+		return nil, notExist("open", path)
+	}
+	return m.base.ReadFile(path)
+}
+
+func (m *MemFS) Stat(path string) (os.FileInfo, error) {
+	path = filepath.Clean(path)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.stat(path)
+}
+
+func (m *MemFS) stat(path string) (os.FileInfo, error) {
+	if f, ok := m.files[path]; ok {
+		return &memInfo{name: filepath.Base(path), size: int64(len(f.data)), mode: f.perm}, nil
+	}
+	if m.dirs[path] {
+		return &memInfo{name: filepath.Base(path), mode: fs.ModeDir | 0755}, nil
+	}
+	if m.removed[path] || m.base == nil {
+		return nil, notExist("stat", path)
+	}
+	return m.base.Stat(path)
+}
+
+// ReadDir lists the files written to dir along with the base's entries
+func (m *MemFS) ReadDir(dir string) ([]os.DirEntry, error) {
+	dir = filepath.Clean(dir)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entries := make(map[string]os.DirEntry)
+	found := m.dirs[dir]
+	if m.base != nil && !m.removed[dir] {
+		if base, err := m.base.ReadDir(dir); err == nil {
+			found = true
+			for _, e := range base {
+				if !m.removed[filepath.Join(dir, e.Name())] {
+					entries[e.Name()] = e
+				}
+			}
+		}
+	}
+	for path := range m.files {
+		if filepath.Dir(path) == dir {
+			info, _ := m.stat(path)
+			entries[info.Name()] = fs.FileInfoToDirEntry(info)
+			found = true
+		}
+	}
+	for path := range m.dirs {
+		if filepath.Dir(path) == dir && path != dir {
+			info, _ := m.stat(path)
+			entries[info.Name()] = fs.FileInfoToDirEntry(info)
+			found = true
+		}
+	}
+	if !found {
+		return nil, notExist("open", dir)
+	}
+
+	list := make([]os.DirEntry, 0, len(entries))
+	for _, e := range entries {
+		list = append(list, e)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name() < list[j].Name() })
+	return list, nil
+}
+
+func (m *MemFS) WriteFile(path string, data []byte, perm os.FileMode) error {
+	path = filepath.Clean(path)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.files[path] = &memFile{data: bytes.Clone(data), perm: perm}
+	delete(m.removed, path)
+	m.mkdirAll(filepath.Dir(path))
+	return nil
+}
+
+func (m *MemFS) MkdirAll(path string, perm os.FileMode) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.mkdirAll(filepath.Clean(path))
+	return nil
+}
+
+func (m *MemFS) mkdirAll(dir string) {
+	for ; !m.dirs[dir]; dir = filepath.Dir(dir) {
+		m.dirs[dir] = true
+		delete(m.removed, dir)
+		if parent := filepath.Dir(dir); parent == dir {
+			break
+		}
+	}
+}
+
+func (m *MemFS) Remove(path string) error {
+	path = filepath.Clean(path)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, err := m.stat(path); err != nil {
+		return err
+	}
+	delete(m.files, path)
+	delete(m.dirs, path)
+	m.removed[path] = true
+	return nil
+}
+
+// FileChange is a file a MemFS changed relative to its base
+type FileChange struct {
+	Path   string
+	Before []byte // Nil if the file didn't exist
+	After  []byte // Nil if the file was removed
+}
+
+// Kind returns "create", "update" or "remove"
+func (c FileChange) Kind() string {
+	switch {
+	case c.Before == nil:
+		return "create"
+	case c.After == nil:
+		return "remove"
+	default:
+		return "update"
+	}
+}
+
+// Changes returns the files written or removed whose contents differ
+// from the base, sorted by path
+func (m *MemFS) Changes() []FileChange {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var changes []FileChange
+	before := func(path string) []byte {
+		if m.base == nil {
+			return nil
+		}
+		data, err := m.base.ReadFile(path)
+		if err != nil {
+			return nil
+		}
+		return data
+	}
+	for path, f := range m.files {
+		old := before(path)
+		if old != nil && bytes.Equal(old, f.data) {
+			continue
+		}
+		changes = append(changes, FileChange{Path: path, Before: old, After: bytes.Clone(f.data)})
+	}
+	for path := range m.removed {
+		if old := before(path); old != nil {
+			changes = append(changes, FileChange{Path: path, Before: old})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return changes
+}
+
+// memInfo describes a MemFS file or directory
+type memInfo struct {
+	name string
+	size int64
+	mode os.FileMode
+}
+
+func (i *memInfo) Name() string       { return i.name }
+func (i *memInfo) Size() int64        { return i.size }
+func (i *memInfo) Mode() os.FileMode  { return i.mode }
+func (i *memInfo) ModTime() time.Time { return time.Time{} }
+func (i *memInfo) IsDir() bool        { return i.mode.IsDir() }
+func (i *memInfo) Sys() any           { return nil }
+
+func notExist(op, path string) error {
+	return &fs.PathError{Op: op, Path: path, Err: fs.ErrNotExist}
+}
+
+// fsHolder gives an adapter its filesystem. The zero value uses DefaultFS.
+type fsHolder struct {
+	fsys FS
+}
+
+func (h fsHolder) fs() FS {
+	if h.fsys == nil {
+		return DefaultFS
+	}
+	return h.fsys
+}
+
+// FSAdapter is implemented by adapters that read and write through an FS
+type FSAdapter interface {
+	Adapter
+
+	// WithFS returns a copy of the adapter that uses fsys
+	WithFS(fsys FS) Adapter
+}
+
+// ErrNoFS is returned when an adapter can't be given a filesystem
+var ErrNoFS = errors.New("adapter doesn't support a custom filesystem")
+
+// WithFS returns a copy of a that reads and writes through fsys
+func WithFS(a Adapter, fsys FS) (Adapter, error) {
+	fa, ok := a.(FSAdapter)
+	if !ok {
+		return nil, fmt.Errorf("%s: %w", a.Name(), ErrNoFS)
+	}
+	return fa.WithFS(fsys), nil
+}
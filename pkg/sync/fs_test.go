@@ -0,0 +1,188 @@
+package sync
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/iheanyi/agentctl/pkg/command"
+	"github.com/iheanyi/agentctl/pkg/mcp"
+)
+
+func TestOSFSWriteFile(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CACHE_HOME", filepath.Join(dir, "cache"))
+	fsys := &OSFS{Backups: 2}
+
+	path := filepath.Join(dir, "tool", "settings.json")
+	if err := fsys.WriteFile(path, []byte(`{"a":1}`), 0644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	if backups, _ := ListBackups(path); len(backups) != 0 {
+		t.Errorf("new file should have no backups, got %v", backups)
+	}
+
+	// Rewriting the same contents is a no-op
+	if err := fsys.WriteFile(path, []byte(`{"a":1}`), 0644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	if backups, _ := ListBackups(path); len(backups) != 0 {
+		t.Errorf("unchanged write should not back up, got %v", backups)
+	}
+
+	for _, data := range []string{`{"a":2}`, `{"a":3}`, `{"a":4}`} {
+		if err := fsys.WriteFile(path, []byte(data), 0644); err != nil {
+			t.Fatalf("WriteFile failed: %v", err)
+		}
+	}
+	backups, _ := ListBackups(path)
+	if len(backups) != 2 {
+		t.Fatalf("expected 2 rotated backups, got %v", backups)
+	}
+	if data, _ := os.ReadFile(backups[1]); string(data) != `{"a":3}` {
+		t.Errorf("newest backup = %s, want {\"a\":3}", data)
+	}
+
+	// Backups live in the cache, where tools loading every *.rules or .md
+	// file in a directory won't pick them up
+	rules := filepath.Join(dir, "tool", "rules", "agentctl.rules")
+	for _, data := range []string{"one", "two"} {
+		if err := fsys.WriteFile(rules, []byte(data), 0644); err != nil {
+			t.Fatalf("WriteFile failed: %v", err)
+		}
+	}
+	backups, _ = ListBackups(rules)
+	if len(backups) != 1 || !strings.HasPrefix(backups[0], filepath.Join(dir, "cache")) {
+		t.Fatalf("rules backups = %v, want one in the cache", backups)
+	}
+	if data, _ := os.ReadFile(backups[0]); string(data) != "one" {
+		t.Errorf("rules backup = %q, want one", data)
+	}
+	if _, err := RestoreBackup(rules); err != nil {
+		t.Fatalf("RestoreBackup failed: %v", err)
+	}
+	if data, _ := os.ReadFile(rules); string(data) != "one" {
+		t.Errorf("restored rules = %q, want one", data)
+	}
+
+	// No lock or backup files are left next to the tool's files
+	filepath.WalkDir(filepath.Join(dir, "tool"), func(path string, d os.DirEntry, err error) error {
+		if err == nil && (strings.HasSuffix(path, ".lock") || strings.Contains(d.Name(), BackupSuffix)) {
+			t.Errorf("%s left in tool directory", path)
+		}
+		return nil
+	})
+
+	md := filepath.Join(dir, "tool", "commands", "hello.md")
+	if err := fsys.WriteFile(md, []byte("hello"), 0644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+
+	if err := fsys.Remove(md); err != nil {
+		t.Fatalf("Remove failed: %v", err)
+	}
+	if _, err := os.Stat(md); !os.IsNotExist(err) {
+		t.Errorf("file still exists after Remove: %v", err)
+	}
+}
+
+func TestMemFS(t *testing.T) {
+	dir := t.TempDir()
+	existing := filepath.Join(dir, "existing.json")
+	same := filepath.Join(dir, "same.json")
+	os.WriteFile(existing, []byte("old"), 0644)
+	os.WriteFile(same, []byte("same"), 0644)
+
+	mem := NewMemFS(&OSFS{})
+
+	// Reads fall through to the base until a file is written
+	if data, err := mem.ReadFile(existing); err != nil || string(data) != "old" {
+		t.Fatalf("ReadFile(existing) = %q, %v", data, err)
+	}
+	mem.WriteFile(existing, []byte("new"), 0644)
+	mem.WriteFile(same, []byte("same"), 0644)
+	mem.WriteFile(filepath.Join(dir, "sub", "created.md"), []byte("created"), 0644)
+	if data, _ := mem.ReadFile(existing); string(data) != "new" {
+		t.Errorf("ReadFile after write = %q, want new", data)
+	}
+	if data, _ := os.ReadFile(existing); string(data) != "old" {
+		t.Errorf("MemFS changed the base file: %q", data)
+	}
+
+	entries, err := mem.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("ReadDir failed: %v", err)
+	}
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	if got := strings.Join(names, ","); got != "existing.json,same.json,sub" {
+		t.Errorf("ReadDir = %s", got)
+	}
+
+	if err := mem.Remove(same); err != nil {
+		t.Fatalf("Remove failed: %v", err)
+	}
+	if _, err := mem.Stat(same); !os.IsNotExist(err) {
+		t.Errorf("Stat after Remove = %v, want not exist", err)
+	}
+	if _, err := os.Stat(same); err != nil {
+		t.Errorf("MemFS removed the base file: %v", err)
+	}
+
+	var got []string
+	for _, c := range mem.Changes() {
+		got = append(got, c.Kind()+" "+filepath.Base(c.Path))
+	}
+	if want := "update existing.json,remove same.json,create created.md"; strings.Join(got, ",") != want {
+		t.Errorf("Changes() = %v, want %s", got, want)
+	}
+}
+
+func TestAdapterWithMemFS(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	mem := NewMemFS(nil)
+	adapter, err := WithFS(&ClaudeAdapter{}, mem)
+	if err != nil {
+		t.Fatalf("WithFS failed: %v", err)
+	}
+	claude := adapter.(*ClaudeAdapter)
+
+	if err := claude.WriteServers([]*mcp.Server{{Name: "fs", Command: "npx"}}); err != nil {
+		t.Fatalf("WriteServers failed: %v", err)
+	}
+	if err := claude.WriteCommands([]*command.Command{{Name: "hello", Prompt: "Say hello"}}); err != nil {
+		t.Fatalf("WriteCommands failed: %v", err)
+	}
+
+	servers, err := claude.ReadServers()
+	if err != nil || len(servers) != 1 || servers[0].Name != "fs" {
+		t.Errorf("ReadServers = %v, %v", servers, err)
+	}
+
+	paths := make(map[string]bool)
+	for _, c := range mem.Changes() {
+		paths[c.Path] = true
+	}
+	for _, want := range []string{claude.ConfigPath(), filepath.Join(claude.commandsDir(), "hello.md")} {
+		if !paths[want] {
+			t.Errorf("Changes() missing %s: %v", want, paths)
+		}
+		if _, err := os.Stat(want); err == nil {
+			t.Errorf("%s was written to disk", want)
+		}
+	}
+
+	if entries, _ := os.ReadDir(home); len(entries) != 0 {
+		t.Errorf("adapter wrote to $HOME: %v", entries)
+	}
+
+	// The registered adapter still uses the real filesystem
+	if (&ClaudeAdapter{}).fs() != DefaultFS {
+		t.Error("WithFS changed the original adapter")
+	}
+}
@@ -1,18 +1,20 @@
 package sync
 
 import (
-	"encoding/json"
 	"os"
 	"path/filepath"
 	"runtime"
 
 	"github.com/iheanyi/agentctl/pkg/command"
+	"github.com/iheanyi/agentctl/pkg/jsonc"
 	"github.com/iheanyi/agentctl/pkg/mcp"
 	"github.com/iheanyi/agentctl/pkg/rule"
 )
 
 // GeminiAdapter syncs configuration to Google Gemini CLI
-type GeminiAdapter struct{}
+type GeminiAdapter struct {
+	fsHolder
+}
 
 // GeminiServerConfig represents a server in Gemini's config format
 type GeminiServerConfig struct {
@@ -39,6 +41,13 @@ func (a *GeminiAdapter) Name() string {
 	return "gemini"
 }
 
+// WithFS returns a copy of the adapter that uses fsys
+func (a *GeminiAdapter) WithFS(fsys FS) Adapter {
+	c := *a
+	c.fsys = fsys
+	return &c
+}
+
 func (a *GeminiAdapter) Detect() (bool, error) {
 	path := a.ConfigPath()
 	if path == "" {
@@ -46,7 +55,7 @@ func (a *GeminiAdapter) Detect() (bool, error) {
 	}
 
 	dir := filepath.Dir(path)
-	if _, err := os.Stat(dir); os.IsNotExist(err) {
+	if _, err := a.fs().Stat(dir); os.IsNotExist(err) {
 		return false, nil
 	}
 
@@ -74,7 +83,16 @@ func (a *GeminiAdapter) SupportedResources() []ResourceType {
 }
 
 func (a *GeminiAdapter) ReadServers() ([]*mcp.Server, error) {
-	config, err := a.loadConfig()
+	return a.readServers(a.ConfigPath())
+}
+
+func (a *GeminiAdapter) WriteServers(servers []*mcp.Server) error {
+	return a.writeServers(a.ConfigPath(), servers)
+}
+
+// readServers reads the mcpServers of the settings file at path
+func (a *GeminiAdapter) readServers(path string) ([]*mcp.Server, error) {
+	config, err := a.loadConfig(path)
 	if err != nil {
 		return nil, err
 	}
@@ -99,8 +117,9 @@ func (a *GeminiAdapter) ReadServers() ([]*mcp.Server, error) {
 	return servers, nil
 }
 
-func (a *GeminiAdapter) WriteServers(servers []*mcp.Server) error {
-	config, err := a.loadConfig()
+// writeServers replaces the managed mcpServers of the settings file at path
+func (a *GeminiAdapter) writeServers(path string, servers []*mcp.Server) error {
+	config, err := a.loadConfig(path)
 	if err != nil {
 		return err
 	}
@@ -142,7 +161,7 @@ func (a *GeminiAdapter) WriteServers(servers []*mcp.Server) error {
 		config.MCPServers[name] = cfg
 	}
 
-	return a.saveConfig(config)
+	return a.saveConfig(path, config)
 }
 
 func (a *GeminiAdapter) ReadCommands() ([]*command.Command, error) {
@@ -161,10 +180,8 @@ func (a *GeminiAdapter) WriteRules(rules []*rule.Rule) error {
 	return nil
 }
 
-func (a *GeminiAdapter) loadConfig() (*GeminiConfig, error) {
-	path := a.ConfigPath()
-
-	data, err := os.ReadFile(path)
+func (a *GeminiAdapter) loadConfig(path string) (*GeminiConfig, error) {
+	data, err := a.fs().ReadFile(path)
 	if err != nil {
 		if os.IsNotExist(err) {
 			return &GeminiConfig{}, nil
@@ -174,12 +191,12 @@ func (a *GeminiAdapter) loadConfig() (*GeminiConfig, error) {
 
 	// First unmarshal to capture all fields
 	var raw map[string]interface{}
-	if err := json.Unmarshal(data, &raw); err != nil {
+	if err := jsonc.Unmarshal(data, &raw); err != nil {
 		return nil, err
 	}
 
 	var config GeminiConfig
-	if err := json.Unmarshal(data, &config); err != nil {
+	if err := jsonc.Unmarshal(data, &config); err != nil {
 		return nil, err
 	}
 
@@ -188,11 +205,9 @@ func (a *GeminiAdapter) loadConfig() (*GeminiConfig, error) {
 	return &config, nil
 }
 
-func (a *GeminiAdapter) saveConfig(config *GeminiConfig) error {
-	path := a.ConfigPath()
-
+func (a *GeminiAdapter) saveConfig(path string, config *GeminiConfig) error {
 	dir := filepath.Dir(path)
-	if err := os.MkdirAll(dir, 0755); err != nil {
+	if err := a.fs().MkdirAll(dir, 0755); err != nil {
 		return err
 	}
 
@@ -204,10 +219,34 @@ func (a *GeminiAdapter) saveConfig(config *GeminiConfig) error {
 
 	output["mcpServers"] = config.MCPServers
 
-	data, err := json.MarshalIndent(output, "", "  ")
+	return writeJSON(a.fs(), path, output)
+}
+
+// WorkspaceAdapter implementation for Gemini
+
+// SupportsWorkspace returns true - Gemini CLI reads .gemini/settings.json in the project
+func (a *GeminiAdapter) SupportsWorkspace() bool {
+	return true
+}
+
+// WorkspaceConfigPath returns the path to .gemini/settings.json in the project directory
+func (a *GeminiAdapter) WorkspaceConfigPath(projectDir string) string {
+	return filepath.Join(projectDir, ".gemini", "settings.json")
+}
+
+// ReadWorkspaceServers reads MCP servers from the project's .gemini/settings.json
+func (a *GeminiAdapter) ReadWorkspaceServers(projectDir string) ([]*mcp.Server, error) {
+	servers, err := a.readServers(a.WorkspaceConfigPath(projectDir))
 	if err != nil {
-		return err
+		return nil, err
 	}
+	for _, s := range servers {
+		s.Scope = "local"
+	}
+	return servers, nil
+}
 
-	return os.WriteFile(path, data, 0644)
+// WriteWorkspaceServers writes MCP servers to the project's .gemini/settings.json
+func (a *GeminiAdapter) WriteWorkspaceServers(projectDir string, servers []*mcp.Server) error {
+	return a.writeServers(a.WorkspaceConfigPath(projectDir), servers)
 }
@@ -762,3 +762,73 @@ func createWorkspaceTestAdapter(adapterName string) WorkspaceAdapter {
 		return nil
 	}
 }
+
+// TestAdapterFormatGolden checks that syncing into a JSONC, TOML or YAML config
+// changes only the servers agentctl manages, leaving comments and
+// formatting elsewhere byte for byte
+func TestAdapterFormatGolden(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", "")
+
+	tests := []struct {
+		adapterName string
+		testCase    string
+		ext         string
+		configPath  string // Relative to $HOME
+		fixture     string
+	}{
+		{"zed", "jsonc", "jsonc", ".config/zed/settings.json", "servers_minimal.json"},
+		{"cursor", "jsonc", "jsonc", ".cursor/mcp.json", "servers_minimal.json"},
+		{"codex", "toml", "toml", ".codex/config.toml", "servers_minimal.json"},
+		{"vscode", "jsonc", "jsonc", ".config/Code/User/mcp.json", "servers_secrets.json"},
+		{"roo", "json", "json", ".config/Code/User/globalStorage/rooveterinaryinc.roo-cline/settings/mcp_settings.json", "servers_all_transports.json"},
+		{"goose", "yaml", "yaml", ".config/goose/config.yaml", "servers_all_transports.json"},
+		{"amp", "jsonc", "jsonc", ".config/amp/settings.json", "servers_all_transports.json"},
+		{"kiro", "json", "json", ".kiro/settings/mcp.json", "servers_all_transports.json"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.adapterName+"/"+tt.testCase, func(t *testing.T) {
+			servers, err := testdata.LoadFixtureServers(tt.fixture)
+			if err != nil {
+				t.Fatalf("Failed to load fixture servers: %v", err)
+			}
+
+			dir := testdata.AdapterGoldenDir(tt.adapterName)
+			input, err := os.ReadFile(filepath.Join(dir, tt.testCase+".input."+tt.ext))
+			if err != nil {
+				t.Fatalf("Failed to load input: %v", err)
+			}
+
+			registered, _ := Get(tt.adapterName)
+			mem := NewMemFS(nil)
+			adapter, err := WithFS(registered, mem)
+			if err != nil {
+				t.Fatalf("WithFS failed: %v", err)
+			}
+			configPath := filepath.Join(home, tt.configPath)
+			mem.WriteFile(configPath, input, 0644)
+
+			if err := adapter.(ServerAdapter).WriteServers(servers); err != nil {
+				t.Fatalf("WriteServers failed: %v", err)
+			}
+			actual, _ := mem.ReadFile(configPath)
+
+			goldenPath := filepath.Join(dir, tt.testCase+".golden."+tt.ext)
+			if testdata.ShouldUpdateGoldens() {
+				if err := os.WriteFile(goldenPath, actual, 0644); err != nil {
+					t.Fatalf("Failed to update golden file: %v", err)
+				}
+				return
+			}
+			expected, err := os.ReadFile(goldenPath)
+			if err != nil {
+				t.Fatalf("Failed to load golden file: %v", err)
+			}
+			if string(actual) != string(expected) {
+				t.Errorf("Golden file mismatch for %s/%s:\n%s\n\nRun with UPDATE_GOLDENS=1 to update", tt.adapterName, tt.testCase, testdata.ColoredDiff(string(expected), string(actual)))
+			}
+		})
+	}
+}
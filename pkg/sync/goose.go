@@ -0,0 +1,282 @@
+package sync
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/iheanyi/agentctl/pkg/mcp"
+)
+
+// GooseAdapter syncs MCP servers to Goose, which calls them extensions
+// and keeps them in config.yaml. The file is edited as a YAML tree, so
+// keys, comments and ordering outside the managed extensions survive.
+type GooseAdapter struct {
+	fsHolder
+}
+
+// gooseExtension is an entry in the "extensions" section of Goose's
+// config.yaml
+type gooseExtension struct {
+	Name    string            `yaml:"name"`
+	Type    string            `yaml:"type"` // stdio, sse or streamable_http; others are built in
+	Enabled bool              `yaml:"enabled"`
+	Cmd     string            `yaml:"cmd,omitempty"`
+	Args    []string          `yaml:"args,omitempty"`
+	URI     string            `yaml:"uri,omitempty"`
+	Headers map[string]string `yaml:"headers,omitempty"`
+	Envs    map[string]string `yaml:"envs,omitempty"`
+	Timeout int               `yaml:"timeout,omitempty"`
+}
+
+// gooseDefaultTimeout is the tool call timeout Goose gives extensions
+const gooseDefaultTimeout = 300
+
+func init() {
+	Register(&GooseAdapter{})
+}
+
+func (a *GooseAdapter) Name() string {
+	return "goose"
+}
+
+// WithFS returns a copy of the adapter that uses fsys
+func (a *GooseAdapter) WithFS(fsys FS) Adapter {
+	c := *a
+	c.fsys = fsys
+	return &c
+}
+
+func (a *GooseAdapter) Detect() (bool, error) {
+	path := a.ConfigPath()
+	if path == "" {
+		return false, nil
+	}
+
+	if _, err := a.fs().Stat(filepath.Dir(path)); os.IsNotExist(err) {
+		return false, nil
+	}
+
+	return true, nil
+}
+
+func (a *GooseAdapter) ConfigPath() string {
+	if runtime.GOOS == "windows" {
+		if appData := os.Getenv("APPDATA"); appData != "" {
+			return filepath.Join(appData, "Block", "goose", "config", "config.yaml")
+		}
+	}
+	if xdgConfig := os.Getenv("XDG_CONFIG_HOME"); xdgConfig != "" {
+		return filepath.Join(xdgConfig, "goose", "config.yaml")
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(homeDir, ".config", "goose", "config.yaml")
+}
+
+func (a *GooseAdapter) SupportedResources() []ResourceType {
+	return []ResourceType{ResourceMCP}
+}
+
+func (a *GooseAdapter) ReadServers() ([]*mcp.Server, error) {
+	doc, err := a.loadConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	extensions := yamlMapValue(doc.Content[0], "extensions")
+	if extensions == nil || extensions.Kind != yaml.MappingNode {
+		return nil, nil
+	}
+
+	var servers []*mcp.Server
+	for i := 0; i+1 < len(extensions.Content); i += 2 {
+		var ext gooseExtension
+		if err := extensions.Content[i+1].Decode(&ext); err != nil {
+			continue
+		}
+		if server := gooseServerFromExtension(extensions.Content[i].Value, ext); server != nil {
+			servers = append(servers, server)
+		}
+	}
+	return servers, nil
+}
+
+// WriteServers replaces the extensions agentctl manages. Goose rejects
+// unknown extension fields, so they are tracked in the sync state rather
+// than with a _managedBy marker.
+func (a *GooseAdapter) WriteServers(servers []*mcp.Server) error {
+	doc, err := a.loadConfig()
+	if err != nil {
+		return err
+	}
+
+	state, err := loadState(a.fs())
+	if err != nil {
+		return err
+	}
+
+	root := doc.Content[0]
+	extensions := yamlMapValue(root, "extensions")
+	if extensions == nil || extensions.Kind != yaml.MappingNode {
+		extensions = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		yamlMapSet(root, "extensions", extensions)
+	}
+	for _, name := range state.GetManagedServers(a.Name()) {
+		yamlMapDelete(extensions, name)
+	}
+
+	var managedNames []string
+	for _, server := range servers {
+		name := GetServerName(server)
+		if name == "" {
+			continue
+		}
+		var value yaml.Node
+		if err := value.Encode(gooseExtensionFromServer(name, server)); err != nil {
+			return err
+		}
+		yamlMapSet(extensions, name, &value)
+		managedNames = append(managedNames, name)
+	}
+
+	if err := a.saveConfig(doc); err != nil {
+		return err
+	}
+
+	state.SetManagedServers(a.Name(), managedNames)
+	return state.save(a.fs())
+}
+
+// loadConfig parses config.yaml into a document whose root is a mapping
+func (a *GooseAdapter) loadConfig() (*yaml.Node, error) {
+	data, err := a.fs().ReadFile(a.ConfigPath())
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("%s: %w", a.ConfigPath(), err)
+	}
+	if doc.Kind == 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}}
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("%s: expected a mapping at the top level", a.ConfigPath())
+	}
+	return &doc, nil
+}
+
+func (a *GooseAdapter) saveConfig(doc *yaml.Node) error {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	if err := enc.Close(); err != nil {
+		return err
+	}
+
+	if err := a.fs().MkdirAll(filepath.Dir(a.ConfigPath()), 0755); err != nil {
+		return err
+	}
+	return a.fs().WriteFile(a.ConfigPath(), buf.Bytes(), 0644)
+}
+
+// gooseExtensionFromServer converts a server to a Goose extension
+func gooseExtensionFromServer(name string, server *mcp.Server) gooseExtension {
+	ext := gooseExtension{
+		Name:    name,
+		Enabled: !server.Disabled,
+		Envs:    server.Env,
+		Timeout: gooseDefaultTimeout,
+	}
+	if server.ToolTimeout > 0 {
+		ext.Timeout = server.ToolTimeout
+	}
+
+	switch server.Transport {
+	case mcp.TransportHTTP:
+		ext.Type = "streamable_http"
+		ext.URI = server.URL
+		ext.Headers = server.Headers
+	case mcp.TransportSSE:
+		ext.Type = "sse"
+		ext.URI = server.URL
+	default:
+		ext.Type = "stdio"
+		ext.Cmd = server.Command
+		ext.Args = server.Args
+	}
+	return ext
+}
+
+// gooseServerFromExtension converts a Goose extension to a server, or
+// returns nil for extensions built into Goose
+func gooseServerFromExtension(key string, ext gooseExtension) *mcp.Server {
+	server := &mcp.Server{
+		Name:     key,
+		Env:      ext.Envs,
+		Disabled: !ext.Enabled,
+	}
+	if ext.Timeout != 0 && ext.Timeout != gooseDefaultTimeout {
+		server.ToolTimeout = ext.Timeout
+	}
+
+	switch ext.Type {
+	case "stdio":
+		server.Command = ext.Cmd
+		server.Args = ext.Args
+	case "streamable_http":
+		server.Transport = mcp.TransportHTTP
+		server.URL = ext.URI
+		server.Headers = ext.Headers
+	case "sse":
+		server.Transport = mcp.TransportSSE
+		server.URL = ext.URI
+	default:
+		return nil
+	}
+	return server
+}
+
+// yamlMapValue returns the value under key in mapping m, or nil
+func yamlMapValue(m *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			return m.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// yamlMapSet sets key in mapping m to value, in place if the key exists
+// and at the end otherwise
+func yamlMapSet(m *yaml.Node, key string, value *yaml.Node) {
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			m.Content[i+1] = value
+			return
+		}
+	}
+	m.Content = append(m.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, value)
+}
+
+// yamlMapDelete removes key from mapping m
+func yamlMapDelete(m *yaml.Node, key string) {
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			m.Content = append(m.Content[:i], m.Content[i+2:]...)
+			return
+		}
+	}
+}
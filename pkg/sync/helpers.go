@@ -9,7 +9,9 @@ import (
 
 	"github.com/iheanyi/agentctl/pkg/agent"
 	"github.com/iheanyi/agentctl/pkg/command"
+	"github.com/iheanyi/agentctl/pkg/jsonc"
 	"github.com/iheanyi/agentctl/pkg/mcp"
+	"github.com/iheanyi/agentctl/pkg/rule"
 	"github.com/iheanyi/agentctl/pkg/skill"
 )
 
@@ -18,17 +20,20 @@ import (
 type JSONConfigHelper struct {
 	// ConfigPath is the path to the JSON config file
 	ConfigPath string
+
+	// FS is the filesystem the file is read from and written to
+	FS FS
 }
 
 // NewJSONConfigHelper creates a new helper for the given config path
-func NewJSONConfigHelper(configPath string) *JSONConfigHelper {
-	return &JSONConfigHelper{ConfigPath: configPath}
+func NewJSONConfigHelper(fsys FS, configPath string) *JSONConfigHelper {
+	return &JSONConfigHelper{ConfigPath: configPath, FS: fsys}
 }
 
 // LoadRaw loads the entire config as a raw map to preserve all fields.
 // Returns an empty map if file doesn't exist.
 func (h *JSONConfigHelper) LoadRaw() (map[string]interface{}, error) {
-	data, err := os.ReadFile(h.ConfigPath)
+	data, err := h.FS.ReadFile(h.ConfigPath)
 	if err != nil {
 		if os.IsNotExist(err) {
 			return make(map[string]interface{}), nil
@@ -37,7 +42,7 @@ func (h *JSONConfigHelper) LoadRaw() (map[string]interface{}, error) {
 	}
 
 	var raw map[string]interface{}
-	if err := json.Unmarshal(data, &raw); err != nil {
+	if err := jsonc.Unmarshal(data, &raw); err != nil {
 		return nil, err
 	}
 
@@ -48,16 +53,29 @@ func (h *JSONConfigHelper) LoadRaw() (map[string]interface{}, error) {
 // Creates the parent directory if it doesn't exist.
 func (h *JSONConfigHelper) SaveRaw(raw map[string]interface{}) error {
 	dir := filepath.Dir(h.ConfigPath)
-	if err := os.MkdirAll(dir, 0755); err != nil {
+	if err := h.FS.MkdirAll(dir, 0755); err != nil {
 		return err
 	}
 
-	data, err := json.MarshalIndent(raw, "", "  ")
-	if err != nil {
-		return err
+	return writeJSON(h.FS, h.ConfigPath, raw)
+}
+
+// writeJSON writes v to a JSON or JSONC config. An existing file is patched
+// in place, so comments, key order and formatting outside the values that
+// changed are kept; one that can't be parsed is replaced.
+func writeJSON(fsys FS, path string, v any) error {
+	var data []byte
+	if existing, err := fsys.ReadFile(path); err == nil {
+		data, _ = jsonc.Patch(existing, v)
+	}
+	if data == nil {
+		var err error
+		if data, err = json.MarshalIndent(v, "", "  "); err != nil {
+			return err
+		}
 	}
 
-	return os.WriteFile(h.ConfigPath, data, 0644)
+	return fsys.WriteFile(path, data, 0644)
 }
 
 // GetMCPServersSection gets or creates the mcpServers section from a raw config.
@@ -176,6 +194,65 @@ func ServerToRawMapWithTransport(server *mcp.Server) map[string]interface{} {
 	return serverCfg
 }
 
+// remoteServerToRawMap converts a server to an mcpServers entry, with
+// remote servers given by url and headers. httpType names streamable HTTP
+// in the entry's "type" field, for tools that need one; when it's empty,
+// remote servers are written without a type. Includes the _managedBy
+// marker.
+func remoteServerToRawMap(server *mcp.Server, httpType string) map[string]interface{} {
+	serverCfg := map[string]interface{}{
+		"_managedBy": ManagedValue,
+	}
+
+	if server.Transport == mcp.TransportHTTP || server.Transport == mcp.TransportSSE {
+		if httpType != "" {
+			serverCfg["type"] = httpType
+			if server.Transport == mcp.TransportSSE {
+				serverCfg["type"] = "sse"
+			}
+		}
+		serverCfg["url"] = server.URL
+		if len(server.Headers) > 0 {
+			serverCfg["headers"] = server.Headers
+		}
+	} else {
+		serverCfg["command"] = server.Command
+		if len(server.Args) > 0 {
+			serverCfg["args"] = server.Args
+		}
+	}
+
+	if len(server.Env) > 0 {
+		serverCfg["env"] = server.Env
+	}
+
+	return serverCfg
+}
+
+// remoteServerFromRawMap parses an entry written by remoteServerToRawMap.
+// An entry with a url and no type is a streamable HTTP server.
+func remoteServerFromRawMap(name string, serverData map[string]interface{}, httpType string) *mcp.Server {
+	server := ServerFromRawMap(name, serverData)
+
+	switch serverType, _ := serverData["type"].(string); {
+	case serverType == "sse":
+		server.Transport = mcp.TransportSSE
+	case serverType == httpType && httpType != "", server.URL != "" && server.Transport == "":
+		server.Transport = mcp.TransportHTTP
+	}
+
+	if headers, ok := serverData["headers"].(map[string]interface{}); ok {
+		server.Headers = make(map[string]string)
+		for k, v := range headers {
+			if str, ok := v.(string); ok {
+				server.Headers[k] = str
+			}
+		}
+	}
+
+	return server
+}
+
 // GetServerName returns the effective name for a server (namespace if set, otherwise name)
 func GetServerName(server *mcp.Server) string {
 	if server.Namespace != "" {
@@ -275,8 +352,8 @@ func parseToolsList(s string) []string {
 }
 
 // ReadCommandsFromDir reads all .md command files from a directory
-func ReadCommandsFromDir(dir string, parseFunc func(filename, content string) *command.Command) ([]*command.Command, error) {
-	entries, err := os.ReadDir(dir)
+func ReadCommandsFromDir(fsys FS, dir string, parseFunc func(filename, content string) *command.Command) ([]*command.Command, error) {
+	entries, err := fsys.ReadDir(dir)
 	if err != nil {
 		if os.IsNotExist(err) {
 			return nil, nil
@@ -291,7 +368,7 @@ func ReadCommandsFromDir(dir string, parseFunc func(filename, content string) *c
 		}
 
 		path := filepath.Join(dir, entry.Name())
-		data, err := os.ReadFile(path)
+		data, err := fsys.ReadFile(path)
 		if err != nil {
 			continue
 		}
@@ -306,8 +383,8 @@ func ReadCommandsFromDir(dir string, parseFunc func(filename, content string) *c
 }
 
 // WriteCommandsToDir writes commands as .md files to a directory
-func WriteCommandsToDir(dir string, commands []*command.Command, formatFunc func(cmd *command.Command) string) error {
-	if err := os.MkdirAll(dir, 0755); err != nil {
+func WriteCommandsToDir(fsys FS, dir string, commands []*command.Command, formatFunc func(cmd *command.Command) string) error {
+	if err := fsys.MkdirAll(dir, 0755); err != nil {
 		return err
 	}
 
@@ -321,7 +398,7 @@ func WriteCommandsToDir(dir string, commands []*command.Command, formatFunc func
 		filename := cmd.Name + ".md"
 		path := filepath.Join(dir, filename)
 
-		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		if err := fsys.WriteFile(path, []byte(content), 0644); err != nil {
 			return err
 		}
 	}
@@ -331,8 +408,8 @@ func WriteCommandsToDir(dir string, commands []*command.Command, formatFunc func
 
 // ReadSkillsFromDir reads all skills from a skills directory.
 // Each skill should be in its own subdirectory with a SKILL.md file.
-func ReadSkillsFromDir(skillsDir string) ([]*skill.Skill, error) {
-	entries, err := os.ReadDir(skillsDir)
+func ReadSkillsFromDir(fsys FS, skillsDir string) ([]*skill.Skill, error) {
+	entries, err := fsys.ReadDir(skillsDir)
 	if err != nil {
 		if os.IsNotExist(err) {
 			return nil, nil
@@ -347,7 +424,7 @@ func ReadSkillsFromDir(skillsDir string) ([]*skill.Skill, error) {
 		}
 
 		skillPath := filepath.Join(skillsDir, entry.Name(), "SKILL.md")
-		if _, err := os.Stat(skillPath); os.IsNotExist(err) {
+		if _, err := fsys.Stat(skillPath); os.IsNotExist(err) {
 			continue
 		}
 
@@ -363,8 +440,8 @@ func ReadSkillsFromDir(skillsDir string) ([]*skill.Skill, error) {
 
 // WriteSkillsToDir writes skills to a skills directory.
 // Each skill is written to its own subdirectory.
-func WriteSkillsToDir(skillsDir string, skills []*skill.Skill) error {
-	if err := os.MkdirAll(skillsDir, 0755); err != nil {
+func WriteSkillsToDir(fsys FS, skillsDir string, skills []*skill.Skill) error {
+	if err := fsys.MkdirAll(skillsDir, 0755); err != nil {
 		return err
 	}
 
@@ -374,8 +451,8 @@ func WriteSkillsToDir(skillsDir string, skills []*skill.Skill) error {
 			return fmt.Errorf("invalid skill name: %w", err)
 		}
 
-		skillDir := filepath.Join(skillsDir, s.Name)
-		if err := s.Save(skillDir); err != nil {
+		path := filepath.Join(skillsDir, s.Name, skill.SkillFileName)
+		if err := fsys.WriteFile(path, []byte(s.ToMarkdown()), 0644); err != nil {
 			return err
 		}
 	}
@@ -385,8 +462,8 @@ func WriteSkillsToDir(skillsDir string, skills []*skill.Skill) error {
 
 // WriteAgentsToDir writes agents to an agents directory.
 // Each agent is written as a markdown file.
-func WriteAgentsToDir(agentsDir string, agents []*agent.Agent) error {
-	if err := os.MkdirAll(agentsDir, 0755); err != nil {
+func WriteAgentsToDir(fsys FS, agentsDir string, agents []*agent.Agent) error {
+	if err := fsys.MkdirAll(agentsDir, 0755); err != nil {
 		return err
 	}
 
@@ -396,10 +473,82 @@ func WriteAgentsToDir(agentsDir string, agents []*agent.Agent) error {
 			return fmt.Errorf("invalid agent name: %w", err)
 		}
 
-		if err := ag.Save(agentsDir); err != nil {
+		data, err := ag.Markdown()
+		if err != nil {
+			return err
+		}
+		if err := fsys.WriteFile(filepath.Join(agentsDir, ag.Name+".md"), data, 0644); err != nil {
 			return err
 		}
 	}
 
 	return nil
 }
+
+// WriteRulesToDir writes rules as markdown files to a directory
+func WriteRulesToDir(fsys FS, dir string, rules []*rule.Rule) error {
+	if err := fsys.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	for _, r := range rules {
+		name, err := rule.FileName(r)
+		if err != nil {
+			return err
+		}
+		data, err := rule.Marshal(r)
+		if err != nil {
+			return err
+		}
+		if err := fsys.WriteFile(filepath.Join(dir, name), data, 0644); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// stringList converts a raw JSON/TOML array into a string slice,
+// skipping non-string elements
+func stringList(v interface{}) []string {
+	var result []string
+	switch list := v.(type) {
+	case []interface{}:
+		for _, item := range list {
+			if str, ok := item.(string); ok {
+				result = append(result, str)
+			}
+		}
+	case []string:
+		result = append(result, list...)
+	}
+	return result
+}
+
+// hasString reports whether list contains item
+func hasString(list []string, item string) bool {
+	for _, s := range list {
+		if s == item {
+			return true
+		}
+	}
+	return false
+}
+
+// exactTools returns the entries of a tool filter list that are plain
+// names, for tools that can't match globs
+func exactTools(patterns []string) []string {
+	var names []string
+	for _, p := range patterns {
+		if !mcp.IsToolGlob(p) {
+			names = append(names, p)
+		}
+	}
+	return names
+}
+
+// toolsStateKey returns the key the tool filter entries an adapter writes
+// for the servers under stateKey are tracked under
+func toolsStateKey(stateKey string) string {
+	return stateKey + "#tools"
+}
@@ -0,0 +1,298 @@
+package sync
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/iheanyi/agentctl/pkg/agent"
+	"github.com/iheanyi/agentctl/pkg/command"
+	"github.com/iheanyi/agentctl/pkg/mcp"
+	"github.com/iheanyi/agentctl/pkg/rule"
+	"github.com/iheanyi/agentctl/pkg/skill"
+)
+
+// KiroAdapter syncs configuration to Kiro. MCP servers live in
+// settings/mcp.json and rules become steering files, both under ~/.kiro
+// globally and .kiro in a project.
+type KiroAdapter struct {
+	fsHolder
+}
+
+// kiroSteering is the frontmatter of a Kiro steering file. Files without
+// it are always included.
+type kiroSteering struct {
+	Inclusion        string `yaml:"inclusion,omitempty"` // always, fileMatch or manual
+	FileMatchPattern any    `yaml:"fileMatchPattern,omitempty"`
+}
+
+func init() {
+	Register(&KiroAdapter{})
+}
+
+func (a *KiroAdapter) Name() string {
+	return "kiro"
+}
+
+// WithFS returns a copy of the adapter that uses fsys
+func (a *KiroAdapter) WithFS(fsys FS) Adapter {
+	c := *a
+	c.fsys = fsys
+	return &c
+}
+
+func (a *KiroAdapter) Detect() (bool, error) {
+	configDir := a.configDir()
+	if configDir == "" {
+		return false, nil
+	}
+
+	if _, err := a.fs().Stat(configDir); os.IsNotExist(err) {
+		return false, nil
+	}
+
+	return true, nil
+}
+
+func (a *KiroAdapter) configDir() string {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(homeDir, ".kiro")
+}
+
+func (a *KiroAdapter) ConfigPath() string {
+	return filepath.Join(a.configDir(), "settings", "mcp.json")
+}
+
+func (a *KiroAdapter) steeringDir() string {
+	return filepath.Join(a.configDir(), "steering")
+}
+
+// ManagedPaths returns the steering directory sync writes to
+func (a *KiroAdapter) ManagedPaths() []string {
+	return []string{a.steeringDir()}
+}
+
+func (a *KiroAdapter) SupportedResources() []ResourceType {
+	return []ResourceType{ResourceMCP, ResourceRules}
+}
+
+func (a *KiroAdapter) ReadServers() ([]*mcp.Server, error) {
+	return a.readServers(a.ConfigPath())
+}
+
+func (a *KiroAdapter) WriteServers(servers []*mcp.Server) error {
+	return a.writeServers(a.ConfigPath(), servers)
+}
+
+func (a *KiroAdapter) readServers(path string) ([]*mcp.Server, error) {
+	raw, err := NewJSONConfigHelper(a.fs(), path).LoadRaw()
+	if err != nil {
+		return nil, err
+	}
+
+	mcpServers, _ := GetMCPServersSection(raw, "mcpServers")
+	var servers []*mcp.Server
+	for name, v := range mcpServers {
+		serverData, ok := v.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		server := remoteServerFromRawMap(name, serverData, "")
+		server.Disabled, _ = serverData["disabled"].(bool)
+		servers = append(servers, server)
+	}
+	return servers, nil
+}
+
+func (a *KiroAdapter) writeServers(path string, servers []*mcp.Server) error {
+	helper := NewJSONConfigHelper(a.fs(), path)
+	raw, err := helper.LoadRaw()
+	if err != nil {
+		return err
+	}
+
+	mcpServers, _ := GetMCPServersSection(raw, "mcpServers")
+	RemoveManagedServers(mcpServers)
+
+	for _, server := range servers {
+		name := GetServerName(server)
+		if name == "" {
+			continue
+		}
+		mcpServers[name] = remoteServerToRawMap(server, "")
+	}
+
+	raw["mcpServers"] = mcpServers
+	return helper.SaveRaw(raw)
+}
+
+func (a *KiroAdapter) ReadRules() ([]*rule.Rule, error) {
+	return a.readSteering(a.steeringDir())
+}
+
+func (a *KiroAdapter) WriteRules(rules []*rule.Rule) error {
+	if len(rules) == 0 {
+		return nil
+	}
+	return a.writeSteering(a.steeringDir(), rules)
+}
+
+// readSteering loads the steering files in dir as rules. A fileMatch
+// pattern becomes the rule's paths.
+func (a *KiroAdapter) readSteering(dir string) ([]*rule.Rule, error) {
+	entries, err := a.fs().ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var rules []*rule.Rule
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".md") {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		data, err := a.fs().ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		r := &rule.Rule{
+			Name:    strings.TrimSuffix(entry.Name(), ".md"),
+			Path:    path,
+			Content: string(data),
+			Tool:    a.Name(),
+		}
+		if rest, ok := strings.CutPrefix(string(data), "---\n"); ok {
+			if front, body, ok := strings.Cut(rest, "\n---\n"); ok {
+				var steering kiroSteering
+				if err := yaml.Unmarshal([]byte(front), &steering); err == nil {
+					r.Content = strings.TrimSpace(body)
+					if steering.Inclusion == "fileMatch" {
+						r.Frontmatter = &rule.Frontmatter{Paths: kiroPatterns(steering.FileMatchPattern)}
+					}
+				}
+			}
+		}
+		rules = append(rules, r)
+	}
+	return rules, nil
+}
+
+// kiroPatterns returns a fileMatchPattern, which is one glob or a list
+func kiroPatterns(v any) []string {
+	if pattern, ok := v.(string); ok {
+		return []string{pattern}
+	}
+	return stringList(v)
+}
+
+// writeSteering writes rules as steering files in dir. Rules limited to
+// paths or globs are included on a file match, the rest always.
+func (a *KiroAdapter) writeSteering(dir string, rules []*rule.Rule) error {
+	if err := a.fs().MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	for _, r := range rules {
+		name, err := rule.FileName(r)
+		if err != nil {
+			return err
+		}
+
+		steering := kiroSteering{Inclusion: "always"}
+		var patterns []string
+		if r.Frontmatter != nil {
+			patterns = append(append(patterns, r.Frontmatter.Paths...), r.Frontmatter.Globs...)
+		}
+		if len(patterns) == 1 {
+			steering = kiroSteering{Inclusion: "fileMatch", FileMatchPattern: patterns[0]}
+		} else if len(patterns) > 1 {
+			steering = kiroSteering{Inclusion: "fileMatch", FileMatchPattern: patterns}
+		}
+		front, err := yaml.Marshal(steering)
+		if err != nil {
+			return err
+		}
+
+		var content strings.Builder
+		content.WriteString("---\n")
+		content.Write(front)
+		content.WriteString("---\n\n")
+		content.WriteString(r.Content)
+
+		if err := a.fs().WriteFile(filepath.Join(dir, name), []byte(content.String()), 0644); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// WorkspaceAdapter implementation for Kiro
+
+// SupportsWorkspace returns true - Kiro reads .kiro/settings/mcp.json in the workspace
+func (a *KiroAdapter) SupportsWorkspace() bool {
+	return true
+}
+
+// WorkspaceConfigPath returns the path to .kiro/settings/mcp.json in the project directory
+func (a *KiroAdapter) WorkspaceConfigPath(projectDir string) string {
+	return filepath.Join(projectDir, ".kiro", "settings", "mcp.json")
+}
+
+// ReadWorkspaceServers reads MCP servers from the project's .kiro/settings/mcp.json
+func (a *KiroAdapter) ReadWorkspaceServers(projectDir string) ([]*mcp.Server, error) {
+	servers, err := a.readServers(a.WorkspaceConfigPath(projectDir))
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range servers {
+		s.Scope = "local"
+	}
+	return servers, nil
+}
+
+// WriteWorkspaceServers writes MCP servers to the project's .kiro/settings/mcp.json
+func (a *KiroAdapter) WriteWorkspaceServers(projectDir string, servers []*mcp.Server) error {
+	return a.writeServers(a.WorkspaceConfigPath(projectDir), servers)
+}
+
+// WorkspaceResourcesAdapter implementation for Kiro
+
+// WorkspaceResources returns the resources Kiro reads from a project
+func (a *KiroAdapter) WorkspaceResources() []ResourceType {
+	return []ResourceType{ResourceRules}
+}
+
+// WorkspacePaths returns the project directories workspace syncs write to
+func (a *KiroAdapter) WorkspacePaths(projectDir string) []string {
+	return []string{filepath.Join(projectDir, ".kiro", "steering")}
+}
+
+// WriteWorkspaceCommands is a no-op - Kiro has no commands
+func (a *KiroAdapter) WriteWorkspaceCommands(projectDir string, commands []*command.Command) error {
+	return nil
+}
+
+// WriteWorkspaceRules writes rules as steering files in the project's .kiro/steering
+func (a *KiroAdapter) WriteWorkspaceRules(projectDir string, rules []*rule.Rule) error {
+	return a.writeSteering(filepath.Join(projectDir, ".kiro", "steering"), rules)
+}
+
+// WriteWorkspaceSkills is a no-op - Kiro has no skills
+func (a *KiroAdapter) WriteWorkspaceSkills(projectDir string, skills []*skill.Skill) error {
+	return nil
+}
+
+// WriteWorkspaceAgents is a no-op - Kiro has no agents
+func (a *KiroAdapter) WriteWorkspaceAgents(projectDir string, agents []*agent.Agent) error {
+	return nil
+}
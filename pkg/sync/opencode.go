@@ -2,20 +2,27 @@ package sync
 
 import (
 	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
 	"runtime"
+	"slices"
 	"strings"
 
 	"github.com/iheanyi/agentctl/pkg/agent"
 	"github.com/iheanyi/agentctl/pkg/command"
 	"github.com/iheanyi/agentctl/pkg/mcp"
+	"github.com/iheanyi/agentctl/pkg/permission"
+	"github.com/iheanyi/agentctl/pkg/plugin"
 	"github.com/iheanyi/agentctl/pkg/rule"
 	"github.com/iheanyi/agentctl/pkg/skill"
+	"github.com/iheanyi/agentctl/pkg/toolname"
 )
 
 // OpenCodeAdapter syncs configuration to OpenCode (opencode.ai)
-type OpenCodeAdapter struct{}
+type OpenCodeAdapter struct {
+	fsHolder
+}
 
 // OpenCodeServerConfig represents a server in OpenCode's config format
 // OpenCode uses a different format than Claude Desktop:
@@ -40,6 +47,13 @@ func (a *OpenCodeAdapter) Name() string {
 	return "opencode"
 }
 
+// WithFS returns a copy of the adapter that uses fsys
+func (a *OpenCodeAdapter) WithFS(fsys FS) Adapter {
+	c := *a
+	c.fsys = fsys
+	return &c
+}
+
 func (a *OpenCodeAdapter) Detect() (bool, error) {
 	path := a.ConfigPath()
 	if path == "" {
@@ -47,7 +61,7 @@ func (a *OpenCodeAdapter) Detect() (bool, error) {
 	}
 
 	dir := filepath.Dir(path)
-	if _, err := os.Stat(dir); os.IsNotExist(err) {
+	if _, err := a.fs().Stat(dir); os.IsNotExist(err) {
 		return false, nil
 	}
 
@@ -91,12 +105,26 @@ func (a *OpenCodeAdapter) agentsDir() string {
 	return filepath.Join(a.configDir(), "agent")
 }
 
+// ManagedPaths returns the command, skill and agent directories and AGENTS.md
+func (a *OpenCodeAdapter) ManagedPaths() []string {
+	return []string{a.commandsDir(), a.skillsDir(), a.agentsFilePath(), a.agentsDir()}
+}
+
 func (a *OpenCodeAdapter) SupportedResources() []ResourceType {
-	return []ResourceType{ResourceMCP, ResourceCommands, ResourceRules, ResourceSkills, ResourceAgents}
+	return []ResourceType{ResourceMCP, ResourceCommands, ResourceRules, ResourceSkills, ResourceAgents, ResourcePermissions, ResourcePlugins}
 }
 
 func (a *OpenCodeAdapter) ReadServers() ([]*mcp.Server, error) {
-	helper := NewJSONConfigHelper(a.ConfigPath())
+	return a.readServers(a.ConfigPath())
+}
+
+func (a *OpenCodeAdapter) WriteServers(servers []*mcp.Server) error {
+	return a.writeServers(a.ConfigPath(), a.Name(), servers)
+}
+
+// readServers reads the mcp section of the config file at path
+func (a *OpenCodeAdapter) readServers(path string) ([]*mcp.Server, error) {
+	helper := NewJSONConfigHelper(a.fs(), path)
 	raw, err := helper.LoadRaw()
 	if err != nil {
 		return nil, err
@@ -162,15 +190,18 @@ func (a *OpenCodeAdapter) ReadServers() ([]*mcp.Server, error) {
 	return servers, nil
 }
 
-func (a *OpenCodeAdapter) WriteServers(servers []*mcp.Server) error {
-	helper := NewJSONConfigHelper(a.ConfigPath())
+// writeServers replaces the managed entries in the mcp section of the
+// config file at path. OpenCode's schema rejects unknown fields, so the
+// entries we manage are tracked in the sync state under stateKey.
+func (a *OpenCodeAdapter) writeServers(path, stateKey string, servers []*mcp.Server) error {
+	helper := NewJSONConfigHelper(a.fs(), path)
 	raw, err := helper.LoadRaw()
 	if err != nil {
 		return err
 	}
 
 	// Load sync state to know which servers we previously managed
-	state, err := LoadState()
+	state, err := loadState(a.fs())
 	if err != nil {
 		return err
 	}
@@ -182,12 +213,21 @@ func (a *OpenCodeAdapter) WriteServers(servers []*mcp.Server) error {
 	}
 
 	// Remove previously managed servers (tracked in state file)
-	for _, name := range state.GetManagedServers(a.Name()) {
+	for _, name := range state.GetManagedServers(stateKey) {
 		delete(mcpSection, name)
 	}
 
-	// Track new managed server names
-	var managedNames []string
+	// Remove tool switches written by a previous sync
+	tools, ok := raw["tools"].(map[string]interface{})
+	if !ok {
+		tools = make(map[string]interface{})
+	}
+	for _, key := range state.GetManagedPermissions(toolsStateKey(stateKey)) {
+		delete(tools, key)
+	}
+
+	// Track new managed server names and tool switches
+	var managedNames, managedTools []string
 
 	// Add new servers (NO custom fields - OpenCode schema is strict)
 	for _, server := range servers {
@@ -224,10 +264,24 @@ func (a *OpenCodeAdapter) WriteServers(servers []*mcp.Server) error {
 
 		mcpSection[name] = serverCfg
 		managedNames = append(managedNames, name)
+
+		switches := openCodeToolSwitches(name, server)
+		for _, key := range slices.Sorted(maps.Keys(switches)) {
+			if _, exists := tools[key]; exists {
+				continue // Set by hand
+			}
+			tools[key] = switches[key]
+			managedTools = append(managedTools, key)
+		}
 	}
 
 	// Update the mcp section in raw config
 	raw["mcp"] = mcpSection
+	if len(tools) > 0 {
+		raw["tools"] = tools
+	} else {
+		delete(raw, "tools")
+	}
 
 	// Save the config
 	if err := helper.SaveRaw(raw); err != nil {
@@ -235,19 +289,40 @@ func (a *OpenCodeAdapter) WriteServers(servers []*mcp.Server) error {
 	}
 
 	// Update state with new managed servers
-	state.SetManagedServers(a.Name(), managedNames)
-	return state.Save()
+	state.SetManagedServers(stateKey, managedNames)
+	state.SetManagedPermissions(toolsStateKey(stateKey), managedTools)
+	return state.save(a.fs())
+}
+
+// openCodeToolSwitches returns the entries of OpenCode's tools map that
+// apply a server's tool filters. OpenCode names MCP tools
+// <server>_<tool> and matches the keys as globs.
+func openCodeToolSwitches(name string, server *mcp.Server) map[string]bool {
+	if !server.FiltersTools() {
+		return nil
+	}
+	switches := make(map[string]bool)
+	if len(server.EnabledTools) > 0 {
+		switches[name+"_*"] = false
+		for _, tool := range server.EnabledTools {
+			switches[name+"_"+tool] = true
+		}
+	}
+	for _, tool := range server.DisabledTools {
+		switches[name+"_"+tool] = false
+	}
+	return switches
 }
 
 func (a *OpenCodeAdapter) ReadCommands() ([]*command.Command, error) {
-	return ReadCommandsFromDir(a.commandsDir(), parseOpenCodeCommand)
+	return ReadCommandsFromDir(a.fs(), a.commandsDir(), parseOpenCodeCommand)
 }
 
 func (a *OpenCodeAdapter) WriteCommands(commands []*command.Command) error {
 	commandsDir := a.commandsDir()
 
 	// Ensure directory exists
-	if err := os.MkdirAll(commandsDir, 0755); err != nil {
+	if err := a.fs().MkdirAll(commandsDir, 0755); err != nil {
 		return err
 	}
 
@@ -261,7 +336,7 @@ func (a *OpenCodeAdapter) WriteCommands(commands []*command.Command) error {
 		filename := cmd.Name + ".md"
 		path := filepath.Join(commandsDir, filename)
 
-		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		if err := a.fs().WriteFile(path, []byte(content), 0644); err != nil {
 			return err
 		}
 	}
@@ -273,10 +348,10 @@ func (a *OpenCodeAdapter) ReadRules() ([]*rule.Rule, error) {
 	// OpenCode uses AGENTS.md (similar to CLAUDE.md)
 	agentsPath := a.agentsFilePath()
 
-	if _, err := os.Stat(agentsPath); os.IsNotExist(err) {
+	if _, err := a.fs().Stat(agentsPath); os.IsNotExist(err) {
 		// Also check for CLAUDE.md as fallback
 		claudePath := filepath.Join(a.configDir(), "CLAUDE.md")
-		if _, err := os.Stat(claudePath); os.IsNotExist(err) {
+		if _, err := a.fs().Stat(claudePath); os.IsNotExist(err) {
 			return nil, nil
 		}
 		agentsPath = claudePath
@@ -308,21 +383,21 @@ func (a *OpenCodeAdapter) WriteRules(rules []*rule.Rule) error {
 	}
 
 	// Ensure config directory exists
-	if err := os.MkdirAll(a.configDir(), 0755); err != nil {
+	if err := a.fs().MkdirAll(a.configDir(), 0755); err != nil {
 		return err
 	}
 
-	return os.WriteFile(agentsPath, []byte(content.String()), 0644)
+	return a.fs().WriteFile(agentsPath, []byte(content.String()), 0644)
 }
 
 // ReadSkills reads skills from OpenCode's skill directory
 func (a *OpenCodeAdapter) ReadSkills() ([]*skill.Skill, error) {
-	return ReadSkillsFromDir(a.skillsDir())
+	return ReadSkillsFromDir(a.fs(), a.skillsDir())
 }
 
 // WriteSkills writes skills to OpenCode's skill directory
 func (a *OpenCodeAdapter) WriteSkills(skills []*skill.Skill) error {
-	return WriteSkillsToDir(a.skillsDir(), skills)
+	return WriteSkillsToDir(a.fs(), a.skillsDir(), skills)
 }
 
 // parseOpenCodeCommand parses an OpenCode command markdown file
@@ -382,11 +457,340 @@ func formatOpenCodeCommand(cmd *command.Command) string {
 
 // ReadAgents reads agents from OpenCode's agent directory
 func (a *OpenCodeAdapter) ReadAgents() ([]*agent.Agent, error) {
-	agentsDir := a.agentsDir()
-	return agent.LoadAll(agentsDir)
+	agents, err := agent.LoadAll(a.agentsDir())
+	if err != nil {
+		return nil, err
+	}
+	agentsToCanonical(toolTranslator(a), agents)
+	return agents, nil
 }
 
 // WriteAgents writes agents to OpenCode's agent directory
 func (a *OpenCodeAdapter) WriteAgents(agents []*agent.Agent) error {
-	return WriteAgentsToDir(a.agentsDir(), agents)
+	return WriteAgentsToDir(a.fs(), a.agentsDir(), agentsToNative(toolTranslator(a), agents))
+}
+
+// WorkspaceResourcesAdapter implementation for OpenCode
+
+// workspaceDir returns the project's .opencode directory
+func (a *OpenCodeAdapter) workspaceDir(projectDir string) string {
+	return filepath.Join(projectDir, ".opencode")
+}
+
+// WorkspaceResources returns the resources OpenCode reads from .opencode
+// in a project. Rules aren't written: the project's AGENTS.md is the
+// user's own.
+func (a *OpenCodeAdapter) WorkspaceResources() []ResourceType {
+	return []ResourceType{ResourceCommands, ResourceSkills, ResourceAgents}
+}
+
+// WorkspacePaths returns the project's command, skill and agent directories
+func (a *OpenCodeAdapter) WorkspacePaths(projectDir string) []string {
+	dir := a.workspaceDir(projectDir)
+	return []string{filepath.Join(dir, "command"), filepath.Join(dir, "skill"), filepath.Join(dir, "agent")}
+}
+
+// WriteWorkspaceCommands writes commands to the project's .opencode/command
+func (a *OpenCodeAdapter) WriteWorkspaceCommands(projectDir string, commands []*command.Command) error {
+	return WriteCommandsToDir(a.fs(), filepath.Join(a.workspaceDir(projectDir), "command"), commands, formatOpenCodeCommand)
+}
+
+// WriteWorkspaceRules does nothing - see WorkspaceResources
+func (a *OpenCodeAdapter) WriteWorkspaceRules(projectDir string, rules []*rule.Rule) error {
+	return nil
+}
+
+// WriteWorkspaceSkills writes skills to the project's .opencode/skill
+func (a *OpenCodeAdapter) WriteWorkspaceSkills(projectDir string, skills []*skill.Skill) error {
+	return WriteSkillsToDir(a.fs(), filepath.Join(a.workspaceDir(projectDir), "skill"), skills)
+}
+
+// WriteWorkspaceAgents writes agents to the project's .opencode/agent
+func (a *OpenCodeAdapter) WriteWorkspaceAgents(projectDir string, agents []*agent.Agent) error {
+	return WriteAgentsToDir(a.fs(), filepath.Join(a.workspaceDir(projectDir), "agent"), agentsToNative(toolTranslator(a), agents))
+}
+
+// WorkspaceAdapter implementation for OpenCode
+
+// SupportsWorkspace returns true - OpenCode merges opencode.json in the project root
+func (a *OpenCodeAdapter) SupportsWorkspace() bool {
+	return true
+}
+
+// WorkspaceConfigPath returns the path to opencode.json in the project directory
+func (a *OpenCodeAdapter) WorkspaceConfigPath(projectDir string) string {
+	return filepath.Join(projectDir, "opencode.json")
+}
+
+// ReadWorkspaceServers reads MCP servers from the project's opencode.json
+func (a *OpenCodeAdapter) ReadWorkspaceServers(projectDir string) ([]*mcp.Server, error) {
+	servers, err := a.readServers(a.WorkspaceConfigPath(projectDir))
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range servers {
+		s.Scope = "local"
+	}
+	return servers, nil
+}
+
+// WriteWorkspaceServers writes MCP servers to the project's opencode.json
+func (a *OpenCodeAdapter) WriteWorkspaceServers(projectDir string, servers []*mcp.Server) error {
+	return a.writeServers(a.WorkspaceConfigPath(projectDir), WorkspaceStateKey(a.Name(), projectDir), servers)
+}
+
+// PermissionsAdapter implementation for OpenCode
+
+// ReadPermissions reads the permission block from opencode.json.
+// Tool-level values become plain patterns ("edit") and per-command bash
+// patterns become specifiers ("bash(git push:*)").
+func (a *OpenCodeAdapter) ReadPermissions() (*permission.Permissions, error) {
+	raw, err := NewJSONConfigHelper(a.fs(), a.ConfigPath()).LoadRaw()
+	if err != nil {
+		return nil, err
+	}
+
+	section, ok := raw["permission"].(map[string]interface{})
+	if !ok {
+		return nil, nil
+	}
+
+	tr := toolTranslator(a)
+	perms := &permission.Permissions{}
+	for tool, v := range section {
+		canonical := tr.CanonicalName(tool)
+		switch value := v.(type) {
+		case string:
+			perms.Add(openCodeDecision(value), canonical)
+		case map[string]interface{}:
+			for pattern, d := range value {
+				decision, ok := d.(string)
+				if !ok {
+					continue
+				}
+				if pattern == "*" {
+					perms.Add(openCodeDecision(decision), canonical)
+					continue
+				}
+				spec := strings.TrimSpace(strings.TrimSuffix(pattern, "*"))
+				if strings.HasSuffix(pattern, "*") {
+					spec += ":*"
+				}
+				perms.Add(openCodeDecision(decision), permission.FormatPattern(canonical, spec))
+			}
+		}
+	}
+
+	return perms, nil
+}
+
+// WritePermissions writes permission patterns to opencode.json.
+// OpenCode's schema is strict, so managed entries are tracked in the sync
+// state file rather than marked inline. MCP tool patterns have no OpenCode
+// equivalent and are skipped.
+func (a *OpenCodeAdapter) WritePermissions(perms *permission.Permissions) error {
+	helper := NewJSONConfigHelper(a.fs(), a.ConfigPath())
+	raw, err := helper.LoadRaw()
+	if err != nil {
+		return err
+	}
+
+	state, err := loadState(a.fs())
+	if err != nil {
+		return err
+	}
+
+	section, ok := raw["permission"].(map[string]interface{})
+	if !ok {
+		section = make(map[string]interface{})
+	}
+
+	// Remove entries written by a previous sync ("tool" or "tool:pattern")
+	for _, entry := range state.GetManagedPermissions(a.Name()) {
+		tool, pattern, hasPattern := strings.Cut(entry, ":")
+		if !hasPattern {
+			delete(section, tool)
+			continue
+		}
+		if patterns, ok := section[tool].(map[string]interface{}); ok {
+			delete(patterns, pattern)
+			if len(patterns) == 0 {
+				delete(section, tool)
+			}
+		}
+	}
+
+	tr := toolTranslator(a)
+	written := make(map[string]bool)
+	var managed []string
+	for _, r := range perms.Rules() {
+		if toolname.IsMCP(r.Pattern) {
+			continue
+		}
+
+		tool := tr.NativeName(r.Tool())
+		key := openCodePermissionKey(r)
+		entry := tool
+		if key != "" {
+			entry = tool + ":" + key
+		}
+		// Rules are ordered deny, ask, allow so the strictest decision wins
+		if written[entry] {
+			continue
+		}
+		written[entry] = true
+
+		if key == "" {
+			if patterns, ok := section[tool].(map[string]interface{}); ok {
+				patterns["*"] = string(r.Decision)
+				entry = tool + ":*"
+			} else {
+				section[tool] = string(r.Decision)
+			}
+		} else {
+			patterns, ok := section[tool].(map[string]interface{})
+			if !ok {
+				patterns = make(map[string]interface{})
+				if existing, ok := section[tool].(string); ok {
+					patterns["*"] = existing
+				}
+				section[tool] = patterns
+			}
+			patterns[key] = string(r.Decision)
+		}
+		managed = append(managed, entry)
+	}
+
+	// A tool-wide entry written before a pattern for the same tool now
+	// lives under the pattern map's "*" key
+	for i, entry := range managed {
+		if _, isMap := section[entry].(map[string]interface{}); isMap {
+			managed[i] = entry + ":*"
+		}
+	}
+
+	if len(section) > 0 {
+		raw["permission"] = section
+	} else {
+		delete(raw, "permission")
+	}
+
+	if err := helper.SaveRaw(raw); err != nil {
+		return err
+	}
+
+	state.SetManagedPermissions(a.Name(), managed)
+	return state.save(a.fs())
+}
+
+// openCodePermissionKey converts a rule's specifier to an OpenCode glob
+// ("git push:*" becomes "git push*"). Returns "" for tool-wide rules.
+func openCodePermissionKey(r permission.Rule) string {
+	spec := r.Specifier()
+	if spec == "" {
+		return ""
+	}
+	words, prefix := permission.CommandPrefix(spec)
+	key := strings.Join(words, " ")
+	if prefix {
+		key += "*"
+	}
+	return key
+}
+
+// openCodeDecision maps OpenCode's permission values to decisions
+func openCodeDecision(value string) permission.Decision {
+	switch value {
+	case "allow":
+		return permission.DecisionAllow
+	case "deny":
+		return permission.DecisionDeny
+	default:
+		return permission.DecisionAsk
+	}
+}
+
+// PluginsAdapter implementation for OpenCode
+
+// ReadPlugins reads the plugin array from opencode.json. Entries are npm
+// package specs such as "opencode-wakatime" or "@org/plugin@1.2.0".
+func (a *OpenCodeAdapter) ReadPlugins() ([]*plugin.Plugin, map[string]*plugin.Marketplace, error) {
+	raw, err := NewJSONConfigHelper(a.fs(), a.ConfigPath()).LoadRaw()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var plugins []*plugin.Plugin
+	for _, spec := range stringList(raw["plugin"]) {
+		if p, err := plugin.Parse(plugin.NPMPrefix + spec); err == nil {
+			plugins = append(plugins, p)
+		}
+	}
+	return plugins, nil, nil
+}
+
+// WritePlugins writes enabled npm plugins to opencode.json's plugin array,
+// pinning versions as "package@version". Marketplace plugins are Claude
+// Code specific and skipped. Managed entries are tracked in the sync state.
+func (a *OpenCodeAdapter) WritePlugins(plugins []*plugin.Plugin, marketplaces map[string]*plugin.Marketplace) error {
+	helper := NewJSONConfigHelper(a.fs(), a.ConfigPath())
+	raw, err := helper.LoadRaw()
+	if err != nil {
+		return err
+	}
+
+	state, err := loadState(a.fs())
+	if err != nil {
+		return err
+	}
+
+	// Drop entries written by a previous sync, and any other spec for a
+	// package agentctl now manages (so a pinned version replaces it)
+	previous := make(map[string]bool)
+	for _, entry := range state.GetManagedPlugins(a.Name()) {
+		previous[entry] = true
+	}
+	wanted := make(map[string]bool)
+	for _, p := range plugins {
+		if p.IsNPM() {
+			wanted[p.Name] = true
+		}
+	}
+
+	var list []string
+	for _, spec := range stringList(raw["plugin"]) {
+		if previous[spec] {
+			continue
+		}
+		if p, err := plugin.Parse(plugin.NPMPrefix + spec); err == nil && wanted[p.Name] {
+			continue
+		}
+		list = append(list, spec)
+	}
+
+	var managed []string
+	for _, p := range plugins {
+		if !p.IsNPM() || p.Disabled {
+			continue
+		}
+		spec := p.Name
+		if p.Version != "" {
+			spec += "@" + p.Version
+		}
+		list = append(list, spec)
+		managed = append(managed, spec)
+	}
+
+	if len(list) > 0 {
+		raw["plugin"] = list
+	} else {
+		delete(raw, "plugin")
+	}
+
+	if err := helper.SaveRaw(raw); err != nil {
+		return err
+	}
+
+	state.SetManagedPlugins(a.Name(), managed)
+	return state.save(a.fs())
 }
@@ -0,0 +1,356 @@
+package sync
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/iheanyi/agentctl/pkg/mcp"
+	"github.com/iheanyi/agentctl/pkg/permission"
+)
+
+// setupPermissionsHome points HOME and the agentctl state dir at a temp dir
+func setupPermissionsHome(t *testing.T) string {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", "")
+	t.Setenv("AGENTCTL_HOME", filepath.Join(home, ".config", "agentctl"))
+	return home
+}
+
+func TestClaudeWritePermissions(t *testing.T) {
+	home := setupPermissionsHome(t)
+	settingsPath := filepath.Join(home, ".claude", "settings.json")
+	if err := os.MkdirAll(filepath.Dir(settingsPath), 0755); err != nil {
+		t.Fatal(err)
+	}
+	initial := `{"model": "opus", "permissions": {"allow": ["Bash(make:*)"]}}`
+	if err := os.WriteFile(settingsPath, []byte(initial), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	adapter := &ClaudeAdapter{}
+	perms := &permission.Permissions{
+		Deny:  []string{"bash(git push --force:*)"},
+		Allow: []string{"read"},
+	}
+	if err := adapter.WritePermissions(perms); err != nil {
+		t.Fatalf("WritePermissions() error = %v", err)
+	}
+
+	var raw map[string]interface{}
+	data, _ := os.ReadFile(settingsPath)
+	if err := json.Unmarshal(data, &raw); err != nil {
+		t.Fatal(err)
+	}
+	if raw["model"] != "opus" {
+		t.Error("unrelated settings should be preserved")
+	}
+	section := raw["permissions"].(map[string]interface{})
+	if got := stringList(section["deny"]); !reflect.DeepEqual(got, []string{"Bash(git push --force:*)"}) {
+		t.Errorf("deny = %v", got)
+	}
+	if got := stringList(section["allow"]); !reflect.DeepEqual(got, []string{"Bash(make:*)", "Read"}) {
+		t.Errorf("allow = %v", got)
+	}
+
+	// Re-sync with a smaller policy removes only managed entries
+	if err := adapter.WritePermissions(&permission.Permissions{Allow: []string{"read"}}); err != nil {
+		t.Fatalf("WritePermissions() error = %v", err)
+	}
+	read, err := adapter.ReadPermissions()
+	if err != nil {
+		t.Fatalf("ReadPermissions() error = %v", err)
+	}
+	if len(read.Deny) != 0 {
+		t.Errorf("stale deny entries should be removed, got %v", read.Deny)
+	}
+	if !reflect.DeepEqual(read.Allow, []string{"bash(make:*)", "read"}) {
+		t.Errorf("allow after re-sync = %v", read.Allow)
+	}
+}
+
+func TestOpenCodeWritePermissions(t *testing.T) {
+	setupPermissionsHome(t)
+	adapter := &OpenCodeAdapter{}
+	configPath := adapter.ConfigPath()
+	if err := os.MkdirAll(filepath.Dir(configPath), 0755); err != nil {
+		t.Fatal(err)
+	}
+	initial := `{"permission": {"webfetch": "ask"}}`
+	if err := os.WriteFile(configPath, []byte(initial), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	perms := &permission.Permissions{
+		Deny:  []string{"bash(git push --force:*)", "mcp__github__delete_repo"},
+		Allow: []string{"bash", "edit"},
+	}
+	if err := adapter.WritePermissions(perms); err != nil {
+		t.Fatalf("WritePermissions() error = %v", err)
+	}
+
+	var raw map[string]interface{}
+	data, _ := os.ReadFile(configPath)
+	if err := json.Unmarshal(data, &raw); err != nil {
+		t.Fatal(err)
+	}
+	section := raw["permission"].(map[string]interface{})
+	if section["webfetch"] != "ask" {
+		t.Error("manual permission entries should be preserved")
+	}
+	if section["edit"] != "allow" {
+		t.Errorf("edit = %v, want allow", section["edit"])
+	}
+	bash, ok := section["bash"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("bash should be a pattern map, got %v", section["bash"])
+	}
+	if bash["git push --force*"] != "deny" || bash["*"] != "allow" {
+		t.Errorf("bash patterns = %v", bash)
+	}
+
+	// Clearing the policy removes everything we wrote
+	if err := adapter.WritePermissions(nil); err != nil {
+		t.Fatalf("WritePermissions(nil) error = %v", err)
+	}
+	read, err := adapter.ReadPermissions()
+	if err != nil {
+		t.Fatalf("ReadPermissions() error = %v", err)
+	}
+	if !reflect.DeepEqual(read.Ask, []string{"webfetch"}) || len(read.Allow) != 0 || len(read.Deny) != 0 {
+		t.Errorf("after clearing, permissions = %+v", read)
+	}
+}
+
+func TestClaudeToolDenials(t *testing.T) {
+	home := setupPermissionsHome(t)
+	adapter := &ClaudeAdapter{}
+	settingsPath := filepath.Join(home, ".claude", "settings.json")
+	if err := os.MkdirAll(filepath.Dir(settingsPath), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(settingsPath, []byte(`{"permissions": {"deny": ["WebFetch"]}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	servers := []*mcp.Server{
+		{Name: "github", Command: "github-mcp", DisabledTools: []string{"delete_repo", "admin_*"}},
+		{Name: "filesystem", Namespace: "fs", Command: "fs-mcp", DisabledTools: []string{"write_file"}},
+	}
+	if err := adapter.WriteServers(servers); err != nil {
+		t.Fatalf("WriteServers() error = %v", err)
+	}
+	perms, err := adapter.ReadPermissions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"webfetch", "mcp__github__delete_repo", "mcp__fs__write_file"}
+	if !reflect.DeepEqual(perms.Deny, want) {
+		t.Errorf("deny = %v, want %v", perms.Deny, want)
+	}
+	if got := SkippedToolFilters(adapter, servers); !reflect.DeepEqual(got, []string{"github:admin_*"}) {
+		t.Errorf("SkippedToolFilters() = %v", got)
+	}
+
+	// A rule synced as a permission too stays until neither needs it
+	if err := adapter.WritePermissions(&permission.Permissions{Deny: []string{"mcp__fs__write_file"}}); err != nil {
+		t.Fatalf("WritePermissions() error = %v", err)
+	}
+	if err := adapter.WritePermissions(&permission.Permissions{}); err != nil {
+		t.Fatalf("WritePermissions() error = %v", err)
+	}
+	if perms, _ = adapter.ReadPermissions(); !reflect.DeepEqual(perms.Deny, want) {
+		t.Errorf("deny after clearing permissions = %v, want %v", perms.Deny, want)
+	}
+	if err := adapter.WritePermissions(&permission.Permissions{Deny: []string{"mcp__github__delete_repo"}}); err != nil {
+		t.Fatalf("WritePermissions() error = %v", err)
+	}
+
+	// Clearing the filters removes only the rules agentctl wrote
+	servers[0].DisabledTools = nil
+	servers[1].DisabledTools = nil
+	if err := adapter.WriteServers(servers); err != nil {
+		t.Fatalf("WriteServers() error = %v", err)
+	}
+	if perms, _ = adapter.ReadPermissions(); !reflect.DeepEqual(perms.Deny, []string{"webfetch", "mcp__github__delete_repo"}) {
+		t.Errorf("deny after clearing = %v", perms.Deny)
+	}
+	if err := adapter.WritePermissions(&permission.Permissions{}); err != nil {
+		t.Fatalf("WritePermissions() error = %v", err)
+	}
+	if perms, _ = adapter.ReadPermissions(); !reflect.DeepEqual(perms.Deny, []string{"webfetch"}) {
+		t.Errorf("deny after clearing both = %v", perms.Deny)
+	}
+}
+
+func TestOpenCodeToolSwitches(t *testing.T) {
+	setupPermissionsHome(t)
+	adapter := &OpenCodeAdapter{}
+	configPath := adapter.ConfigPath()
+	if err := os.MkdirAll(filepath.Dir(configPath), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(configPath, []byte(`{"tools": {"webfetch": false}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	servers := []*mcp.Server{
+		{Name: "github", Command: "github-mcp", EnabledTools: []string{"get_*", "search"}, DisabledTools: []string{"get_secret"}},
+	}
+	if err := adapter.WriteServers(servers); err != nil {
+		t.Fatalf("WriteServers() error = %v", err)
+	}
+	readTools := func() map[string]interface{} {
+		var raw map[string]interface{}
+		data, _ := os.ReadFile(configPath)
+		if err := json.Unmarshal(data, &raw); err != nil {
+			t.Fatal(err)
+		}
+		tools, _ := raw["tools"].(map[string]interface{})
+		return tools
+	}
+	want := map[string]interface{}{
+		"webfetch":          false,
+		"github_*":          false,
+		"github_get_*":      true,
+		"github_search":     true,
+		"github_get_secret": false,
+	}
+	if got := readTools(); !reflect.DeepEqual(got, want) {
+		t.Errorf("tools = %v, want %v", got, want)
+	}
+
+	if err := adapter.WriteServers(nil); err != nil {
+		t.Fatalf("WriteServers() error = %v", err)
+	}
+	if got := readTools(); !reflect.DeepEqual(got, map[string]interface{}{"webfetch": false}) {
+		t.Errorf("tools after removing servers = %v", got)
+	}
+}
+
+func TestCodexWritePermissions(t *testing.T) {
+	home := setupPermissionsHome(t)
+	adapter := &CodexAdapter{}
+
+	perms := &permission.Permissions{
+		Deny: []string{"bash(git push --force:*)", "edit"},
+		Ask:  []string{"Bash(npm publish:*)"},
+		// Exact allows can't be written without allowing more
+		Allow: []string{"Bash(git status)"},
+	}
+	if err := adapter.WritePermissions(perms); err != nil {
+		t.Fatalf("WritePermissions() error = %v", err)
+	}
+
+	path := filepath.Join(home, ".codex", "rules", codexManagedRulesFile)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("rules file not written: %v", err)
+	}
+	content := string(data)
+	for _, want := range []string{
+		codexManagedRulesHeader,
+		`pattern = ["git", "push", "--force"]`,
+		`decision = "forbidden"`,
+		`pattern = ["npm", "publish"]`,
+		`decision = "prompt"`,
+	} {
+		if !strings.Contains(content, want) {
+			t.Errorf("rules file missing %q:\n%s", want, content)
+		}
+	}
+	if strings.Contains(content, `"status"`) {
+		t.Errorf("exact allow was widened to a prefix rule:\n%s", content)
+	}
+	if got := SkippedPermissions(adapter, perms); !reflect.DeepEqual(got, []string{"Bash(git status)"}) {
+		t.Errorf("SkippedPermissions() = %q", got)
+	}
+
+	// Without bash rules the managed file is removed
+	if err := adapter.WritePermissions(&permission.Permissions{Allow: []string{"read"}}); err != nil {
+		t.Fatalf("WritePermissions() error = %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("managed rules file should be removed when no rules apply")
+	}
+}
+
+func TestCodexReadPermissionsPreservesComments(t *testing.T) {
+	home := setupPermissionsHome(t)
+	adapter := &CodexAdapter{}
+
+	rulesDir := filepath.Join(home, ".codex", "rules")
+	if err := os.MkdirAll(rulesDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	userRules := "prefix_rule(pattern = [\"git\", [\"fetch\", \"pull\"]], decision = \"allow\")\n"
+	if err := os.WriteFile(filepath.Join(rulesDir, "default.rules"), []byte(userRules), 0644); err != nil {
+		t.Fatal(err)
+	}
+	managed := codexManagedRulesHeader + "\n\n# keep me\nhelper = 1\n"
+	path := filepath.Join(rulesDir, codexManagedRulesFile)
+	if err := os.WriteFile(path, []byte(managed), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	read, err := adapter.ReadPermissions()
+	if err != nil {
+		t.Fatalf("ReadPermissions() error = %v", err)
+	}
+	if want := []string{"bash(git fetch:*)", "bash(git pull:*)"}; !reflect.DeepEqual(read.Allow, want) {
+		t.Errorf("ReadPermissions().Allow = %v, want %v", read.Allow, want)
+	}
+
+	if err := adapter.WritePermissions(&permission.Permissions{Deny: []string{"bash(rm -rf:*)"}}); err != nil {
+		t.Fatalf("WritePermissions() error = %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(data), managed) || !strings.Contains(string(data), `pattern = ["rm", "-rf"]`) {
+		t.Errorf("rules file = %q, want comments preserved and rule appended", data)
+	}
+
+	// Unknown statements keep the file around even without rules
+	if err := adapter.WritePermissions(nil); err != nil {
+		t.Fatalf("WritePermissions() error = %v", err)
+	}
+	data, err = os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("rules file should be kept: %v", err)
+	}
+	if string(data) != managed {
+		t.Errorf("rules file = %q, want %q", data, managed)
+	}
+}
+
+func TestCodexPermissionsThroughFS(t *testing.T) {
+	home := setupPermissionsHome(t)
+	mem := NewMemFS(nil)
+	adapter, err := WithFS(&CodexAdapter{}, mem)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pa := adapter.(PermissionsAdapter)
+
+	rulesDir := filepath.Join(home, ".codex", "rules")
+	if err := pa.WritePermissions(&permission.Permissions{Deny: []string{"bash(git push:*)"}}); err != nil {
+		t.Fatalf("WritePermissions() error = %v", err)
+	}
+	read, err := pa.ReadPermissions()
+	if err != nil {
+		t.Fatalf("ReadPermissions() error = %v", err)
+	}
+	if want := []string{"bash(git push:*)"}; read == nil || !reflect.DeepEqual(read.Deny, want) || len(read.Allow) != 0 {
+		t.Errorf("ReadPermissions() = %+v, want deny %v only", read, want)
+	}
+	if _, err := os.Stat(rulesDir); !os.IsNotExist(err) {
+		t.Errorf("MemFS write reached the disk: %v", err)
+	}
+}
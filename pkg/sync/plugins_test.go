@@ -0,0 +1,163 @@
+package sync
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/iheanyi/agentctl/pkg/plugin"
+)
+
+func TestClaudeWritePlugins(t *testing.T) {
+	home := setupPermissionsHome(t)
+	adapter := &ClaudeAdapter{}
+
+	settingsPath := filepath.Join(home, ".claude", "settings.json")
+	if err := os.MkdirAll(filepath.Dir(settingsPath), 0755); err != nil {
+		t.Fatal(err)
+	}
+	initial := `{"model": "opus", "enabledPlugins": {"manual@official": true}}`
+	if err := os.WriteFile(settingsPath, []byte(initial), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	plugins := []*plugin.Plugin{
+		{Name: "review", Marketplace: "team"},
+		{Name: "lint", Marketplace: "team", Disabled: true},
+		{Name: "opencode-only"},
+	}
+	marketplaces := map[string]*plugin.Marketplace{
+		"team": {Source: plugin.SourceGitHub, Repo: "acme/plugins"},
+	}
+	if err := adapter.WritePlugins(plugins, marketplaces); err != nil {
+		t.Fatalf("WritePlugins() error = %v", err)
+	}
+
+	raw, err := NewJSONConfigHelper(DefaultFS, settingsPath).LoadRaw()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if raw["model"] != "opus" {
+		t.Error("unrelated settings should be preserved")
+	}
+	wantEnabled := map[string]interface{}{"manual@official": true, "review@team": true, "lint@team": false}
+	if !reflect.DeepEqual(raw["enabledPlugins"], wantEnabled) {
+		t.Errorf("enabledPlugins = %v, want %v", raw["enabledPlugins"], wantEnabled)
+	}
+
+	known, err := NewJSONConfigHelper(DefaultFS, adapter.knownMarketplacesPath()).LoadRaw()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := known["team"]; !ok {
+		t.Errorf("known_marketplaces.json = %v, want team registered", known)
+	}
+
+	// Fields Claude maintains survive another sync; the source follows
+	// agentctl.json
+	known["team"].(map[string]interface{})["lastUpdated"] = "2026-01-01T00:00:00Z"
+	if err := NewJSONConfigHelper(DefaultFS, adapter.knownMarketplacesPath()).SaveRaw(known); err != nil {
+		t.Fatal(err)
+	}
+	marketplaces["team"] = &plugin.Marketplace{Source: plugin.SourceGitHub, Repo: "acme/claude-plugins"}
+	if err := adapter.WritePlugins(plugins, marketplaces); err != nil {
+		t.Fatalf("WritePlugins() error = %v", err)
+	}
+	known, err = NewJSONConfigHelper(DefaultFS, adapter.knownMarketplacesPath()).LoadRaw()
+	if err != nil {
+		t.Fatal(err)
+	}
+	team, _ := known["team"].(map[string]interface{})
+	if team["lastUpdated"] != "2026-01-01T00:00:00Z" || team["installLocation"] == nil {
+		t.Errorf("known_marketplaces.json team = %v, want Claude's fields kept", team)
+	}
+	if source, _ := team["source"].(map[string]interface{}); source["repo"] != "acme/claude-plugins" {
+		t.Errorf("known_marketplaces.json team source = %v", team["source"])
+	}
+
+	readPlugins, readMarketplaces, err := adapter.ReadPlugins()
+	if err != nil {
+		t.Fatalf("ReadPlugins() error = %v", err)
+	}
+	if len(readPlugins) != 3 || plugin.Find(readPlugins, "lint@team") == nil || !plugin.Find(readPlugins, "lint@team").Disabled {
+		t.Errorf("ReadPlugins() = %+v", readPlugins)
+	}
+	if !reflect.DeepEqual(readMarketplaces["team"], marketplaces["team"]) {
+		t.Errorf("ReadPlugins() marketplace = %+v", readMarketplaces["team"])
+	}
+
+	// Removing everything leaves only the user's own entries
+	if err := adapter.WritePlugins(nil, nil); err != nil {
+		t.Fatalf("WritePlugins() error = %v", err)
+	}
+	raw, err = NewJSONConfigHelper(DefaultFS, settingsPath).LoadRaw()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]interface{}{"manual@official": true}; !reflect.DeepEqual(raw["enabledPlugins"], want) {
+		t.Errorf("enabledPlugins after removal = %v, want %v", raw["enabledPlugins"], want)
+	}
+	if _, ok := raw["extraKnownMarketplaces"]; ok {
+		t.Error("extraKnownMarketplaces should be removed")
+	}
+	known, err = NewJSONConfigHelper(DefaultFS, adapter.knownMarketplacesPath()).LoadRaw()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(known) != 0 {
+		t.Errorf("known_marketplaces.json = %v, want managed entry removed", known)
+	}
+}
+
+func TestOpenCodeWritePlugins(t *testing.T) {
+	setupPermissionsHome(t)
+	adapter := &OpenCodeAdapter{}
+
+	configPath := adapter.ConfigPath()
+	if err := os.MkdirAll(filepath.Dir(configPath), 0755); err != nil {
+		t.Fatal(err)
+	}
+	initial := `{"plugin": ["manual-plugin", "opencode-wakatime@1.0.0"]}`
+	if err := os.WriteFile(configPath, []byte(initial), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	plugins := []*plugin.Plugin{
+		{Name: "opencode-wakatime", Version: "1.2.0"},
+		{Name: "@org/helper"},
+		{Name: "off", Disabled: true},
+		{Name: "review", Marketplace: "team"},
+	}
+	if err := adapter.WritePlugins(plugins, nil); err != nil {
+		t.Fatalf("WritePlugins() error = %v", err)
+	}
+
+	raw, err := NewJSONConfigHelper(DefaultFS, configPath).LoadRaw()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"manual-plugin", "opencode-wakatime@1.2.0", "@org/helper"}
+	if got := stringList(raw["plugin"]); !reflect.DeepEqual(got, want) {
+		t.Errorf("plugin = %v, want %v", got, want)
+	}
+
+	read, _, err := adapter.ReadPlugins()
+	if err != nil {
+		t.Fatalf("ReadPlugins() error = %v", err)
+	}
+	if p := plugin.Find(read, "opencode-wakatime"); p == nil || p.Version != "1.2.0" {
+		t.Errorf("ReadPlugins() = %+v", read)
+	}
+
+	if err := adapter.WritePlugins(nil, nil); err != nil {
+		t.Fatalf("WritePlugins() error = %v", err)
+	}
+	raw, err = NewJSONConfigHelper(DefaultFS, configPath).LoadRaw()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := stringList(raw["plugin"]); !reflect.DeepEqual(got, []string{"manual-plugin"}) {
+		t.Errorf("plugin after removal = %v, want [manual-plugin]", got)
+	}
+}
@@ -0,0 +1,193 @@
+package sync
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/iheanyi/agentctl/pkg/agent"
+	"github.com/iheanyi/agentctl/pkg/command"
+	"github.com/iheanyi/agentctl/pkg/mcp"
+	"github.com/iheanyi/agentctl/pkg/rule"
+	"github.com/iheanyi/agentctl/pkg/skill"
+)
+
+// RooAdapter syncs configuration to Roo Code, a VS Code extension. Its
+// global MCP servers live in the extension's mcp_settings.json and its
+// rules in ~/.roo/rules; projects use .roo/mcp.json and .roo/rules.
+type RooAdapter struct {
+	fsHolder
+}
+
+func init() {
+	Register(&RooAdapter{})
+}
+
+func (a *RooAdapter) Name() string {
+	return "roo"
+}
+
+// WithFS returns a copy of the adapter that uses fsys
+func (a *RooAdapter) WithFS(fsys FS) Adapter {
+	c := *a
+	c.fsys = fsys
+	return &c
+}
+
+func (a *RooAdapter) Detect() (bool, error) {
+	path := a.ConfigPath()
+	if path == "" {
+		return false, nil
+	}
+
+	// The extension's storage directory exists once it has been installed
+	if _, err := a.fs().Stat(filepath.Dir(filepath.Dir(path))); os.IsNotExist(err) {
+		return false, nil
+	}
+
+	return true, nil
+}
+
+func (a *RooAdapter) ConfigPath() string {
+	userDir := vscodeUserDir()
+	if userDir == "" {
+		return ""
+	}
+	return filepath.Join(userDir, "globalStorage", "rooveterinaryinc.roo-cline", "settings", "mcp_settings.json")
+}
+
+func (a *RooAdapter) rulesDir() string {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(homeDir, ".roo", "rules")
+}
+
+// ManagedPaths returns the rules directory sync writes to
+func (a *RooAdapter) ManagedPaths() []string {
+	return []string{a.rulesDir()}
+}
+
+func (a *RooAdapter) SupportedResources() []ResourceType {
+	return []ResourceType{ResourceMCP, ResourceRules}
+}
+
+func (a *RooAdapter) ReadServers() ([]*mcp.Server, error) {
+	return a.readServers(a.ConfigPath())
+}
+
+func (a *RooAdapter) WriteServers(servers []*mcp.Server) error {
+	return a.writeServers(a.ConfigPath(), servers)
+}
+
+func (a *RooAdapter) readServers(path string) ([]*mcp.Server, error) {
+	raw, err := NewJSONConfigHelper(a.fs(), path).LoadRaw()
+	if err != nil {
+		return nil, err
+	}
+
+	mcpServers, _ := GetMCPServersSection(raw, "mcpServers")
+	var servers []*mcp.Server
+	for name, v := range mcpServers {
+		serverData, ok := v.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		servers = append(servers, remoteServerFromRawMap(name, serverData, "streamable-http"))
+	}
+	return servers, nil
+}
+
+func (a *RooAdapter) writeServers(path string, servers []*mcp.Server) error {
+	helper := NewJSONConfigHelper(a.fs(), path)
+	raw, err := helper.LoadRaw()
+	if err != nil {
+		return err
+	}
+
+	mcpServers, _ := GetMCPServersSection(raw, "mcpServers")
+	RemoveManagedServers(mcpServers)
+
+	for _, server := range servers {
+		name := GetServerName(server)
+		if name == "" {
+			continue
+		}
+		mcpServers[name] = remoteServerToRawMap(server, "streamable-http")
+	}
+
+	raw["mcpServers"] = mcpServers
+	return helper.SaveRaw(raw)
+}
+
+func (a *RooAdapter) ReadRules() ([]*rule.Rule, error) {
+	return rule.LoadAll(a.rulesDir())
+}
+
+func (a *RooAdapter) WriteRules(rules []*rule.Rule) error {
+	if len(rules) == 0 {
+		return nil
+	}
+	return WriteRulesToDir(a.fs(), a.rulesDir(), rules)
+}
+
+// WorkspaceAdapter implementation for Roo Code
+
+// SupportsWorkspace returns true - Roo Code reads .roo/mcp.json in the workspace
+func (a *RooAdapter) SupportsWorkspace() bool {
+	return true
+}
+
+// WorkspaceConfigPath returns the path to .roo/mcp.json in the project directory
+func (a *RooAdapter) WorkspaceConfigPath(projectDir string) string {
+	return filepath.Join(projectDir, ".roo", "mcp.json")
+}
+
+// ReadWorkspaceServers reads MCP servers from the project's .roo/mcp.json
+func (a *RooAdapter) ReadWorkspaceServers(projectDir string) ([]*mcp.Server, error) {
+	servers, err := a.readServers(a.WorkspaceConfigPath(projectDir))
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range servers {
+		s.Scope = "local"
+	}
+	return servers, nil
+}
+
+// WriteWorkspaceServers writes MCP servers to the project's .roo/mcp.json
+func (a *RooAdapter) WriteWorkspaceServers(projectDir string, servers []*mcp.Server) error {
+	return a.writeServers(a.WorkspaceConfigPath(projectDir), servers)
+}
+
+// WorkspaceResourcesAdapter implementation for Roo Code
+
+// WorkspaceResources returns the resources Roo Code reads from a project
+func (a *RooAdapter) WorkspaceResources() []ResourceType {
+	return []ResourceType{ResourceRules}
+}
+
+// WorkspacePaths returns the project directories workspace syncs write to
+func (a *RooAdapter) WorkspacePaths(projectDir string) []string {
+	return []string{filepath.Join(projectDir, ".roo", "rules")}
+}
+
+// WriteWorkspaceCommands is a no-op - Roo Code commands aren't synced
+func (a *RooAdapter) WriteWorkspaceCommands(projectDir string, commands []*command.Command) error {
+	return nil
+}
+
+// WriteWorkspaceRules writes rules to the project's .roo/rules
+func (a *RooAdapter) WriteWorkspaceRules(projectDir string, rules []*rule.Rule) error {
+	return WriteRulesToDir(a.fs(), filepath.Join(projectDir, ".roo", "rules"), rules)
+}
+
+// WriteWorkspaceSkills is a no-op - Roo Code has no skills
+func (a *RooAdapter) WriteWorkspaceSkills(projectDir string, skills []*skill.Skill) error {
+	return nil
+}
+
+// WriteWorkspaceAgents is a no-op - Roo Code's modes aren't synced
+func (a *RooAdapter) WriteWorkspaceAgents(projectDir string, agents []*agent.Agent) error {
+	return nil
+}
@@ -0,0 +1,60 @@
+package sync
+
+import (
+	"time"
+
+	"github.com/iheanyi/agentctl/pkg/config"
+	"github.com/iheanyi/agentctl/pkg/snapshot"
+)
+
+// Snapshots returns the store holding sync snapshots
+func Snapshots() *snapshot.Store {
+	return snapshot.NewStore(config.DefaultCacheDir())
+}
+
+// BeginSnapshot starts the snapshot for a new sync
+func BeginSnapshot() (*snapshot.Store, *snapshot.Snapshot) {
+	store := Snapshots()
+	return store, store.Begin(snapshot.NewID(time.Now()))
+}
+
+// CaptureAdapter records the files an adapter may write before a sync
+// touches them. extra adds paths outside TouchedPaths, such as workspace
+// configs.
+func CaptureAdapter(store *snapshot.Store, snap *snapshot.Snapshot, a Adapter, extra ...string) error {
+	return store.Capture(snap, a.Name(), append(TouchedPaths(a), extra...)...)
+}
+
+// FinishSnapshot saves a sync's snapshot and prunes old snapshots per
+// the global settings. It returns the snapshot ID, or "" when the sync
+// changed nothing.
+func FinishSnapshot(store *snapshot.Store, snap *snapshot.Snapshot) (string, error) {
+	saved, err := store.Finish(snap)
+	if err != nil || !saved {
+		return "", err
+	}
+
+	var settings config.SnapshotConfig
+	if cfg, err := config.Load(); err == nil && cfg.Settings.Snapshots != nil {
+		settings = *cfg.Settings.Snapshots
+	}
+	retention, err := SnapshotRetention(settings)
+	if err != nil {
+		return snap.ID, err
+	}
+	_, err = store.Prune(retention)
+	return snap.ID, err
+}
+
+// SnapshotRetention converts snapshot settings into a retention policy
+func SnapshotRetention(settings config.SnapshotConfig) (snapshot.Retention, error) {
+	maxAge, err := snapshot.ParseMaxAge(settings.MaxAge)
+	if err != nil {
+		return snapshot.Retention{}, err
+	}
+	keep := settings.Keep
+	if keep == 0 {
+		keep = snapshot.DefaultKeep
+	}
+	return snapshot.Retention{Keep: keep, MaxAge: maxAge}, nil
+}
@@ -14,23 +14,35 @@ type SyncState struct {
 	Version int `json:"version"`
 	// ManagedServers maps adapter name -> list of server names we manage
 	ManagedServers map[string][]string `json:"managedServers"`
+	// ManagedPermissions maps adapter name -> permission entries we manage
+	ManagedPermissions map[string][]string `json:"managedPermissions,omitempty"`
+	// ManagedPlugins maps adapter name -> plugin and marketplace entries we manage
+	ManagedPlugins map[string][]string `json:"managedPlugins,omitempty"`
 }
 
-// stateFilePath returns the path to the sync state file
-func stateFilePath() string {
+// StatePath returns the path to the sync state file
+func StatePath() string {
 	return filepath.Join(config.DefaultConfigDir(), "sync-state.json")
 }
 
 // LoadState loads the sync state from disk
 func LoadState() (*SyncState, error) {
-	path := stateFilePath()
+	return loadState(DefaultFS)
+}
+
+// loadState loads the sync state from fsys, so adapters given a MemFS
+// see the state they wrote
+func loadState(fsys FS) (*SyncState, error) {
+	path := StatePath()
 
-	data, err := os.ReadFile(path)
+	data, err := fsys.ReadFile(path)
 	if err != nil {
 		if os.IsNotExist(err) {
 			return &SyncState{
-				Version:        1,
-				ManagedServers: make(map[string][]string),
+				Version:            1,
+				ManagedServers:     make(map[string][]string),
+				ManagedPermissions: make(map[string][]string),
+				ManagedPlugins:     make(map[string][]string),
 			}, nil
 		}
 		return nil, err
@@ -44,16 +56,26 @@ func LoadState() (*SyncState, error) {
 	if state.ManagedServers == nil {
 		state.ManagedServers = make(map[string][]string)
 	}
+	if state.ManagedPermissions == nil {
+		state.ManagedPermissions = make(map[string][]string)
+	}
+	if state.ManagedPlugins == nil {
+		state.ManagedPlugins = make(map[string][]string)
+	}
 
 	return &state, nil
 }
 
 // Save saves the sync state to disk
 func (s *SyncState) Save() error {
-	path := stateFilePath()
+	return s.save(DefaultFS)
+}
+
+func (s *SyncState) save(fsys FS) error {
+	path := StatePath()
 
 	dir := filepath.Dir(path)
-	if err := os.MkdirAll(dir, 0755); err != nil {
+	if err := fsys.MkdirAll(dir, 0755); err != nil {
 		return err
 	}
 
@@ -62,7 +84,7 @@ func (s *SyncState) Save() error {
 		return err
 	}
 
-	return os.WriteFile(path, data, 0644)
+	return fsys.WriteFile(path, data, 0644)
 }
 
 // GetManagedServers returns the list of server names managed for an adapter
@@ -75,7 +97,41 @@ func (s *SyncState) SetManagedServers(adapterName string, servers []string) {
 	s.ManagedServers[adapterName] = servers
 }
 
+// WorkspaceStateKey returns the key servers an adapter manages in a
+// project's config are tracked under, kept apart from its global ones
+func WorkspaceStateKey(adapterName, projectDir string) string {
+	return adapterName + ":" + filepath.Clean(projectDir)
+}
+
 // ClearManagedServers removes all managed servers for an adapter
 func (s *SyncState) ClearManagedServers(adapterName string) {
 	delete(s.ManagedServers, adapterName)
 }
+
+// GetManagedPermissions returns the permission entries managed for an adapter
+func (s *SyncState) GetManagedPermissions(adapterName string) []string {
+	return s.ManagedPermissions[adapterName]
+}
+
+// SetManagedPermissions sets the permission entries managed for an adapter
+func (s *SyncState) SetManagedPermissions(adapterName string, entries []string) {
+	if len(entries) == 0 {
+		delete(s.ManagedPermissions, adapterName)
+		return
+	}
+	s.ManagedPermissions[adapterName] = entries
+}
+
+// GetManagedPlugins returns the plugin entries managed for an adapter
+func (s *SyncState) GetManagedPlugins(adapterName string) []string {
+	return s.ManagedPlugins[adapterName]
+}
+
+// SetManagedPlugins sets the plugin entries managed for an adapter
+func (s *SyncState) SetManagedPlugins(adapterName string, entries []string) {
+	if len(entries) == 0 {
+		delete(s.ManagedPlugins, adapterName)
+		return
+	}
+	s.ManagedPlugins[adapterName] = entries
+}
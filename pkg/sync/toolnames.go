@@ -0,0 +1,65 @@
+package sync
+
+import (
+	"github.com/iheanyi/agentctl/pkg/agent"
+	"github.com/iheanyi/agentctl/pkg/command"
+	"github.com/iheanyi/agentctl/pkg/toolname"
+)
+
+// toolTranslator returns a tool-name translator for an adapter. It is seeded
+// with the MCP servers currently configured for the tool so that native MCP
+// tool names (e.g., OpenCode's server_tool) can be split back into server
+// and tool on import.
+func toolTranslator(a Adapter) *toolname.Translator {
+	var servers []string
+	if sa, ok := AsServerAdapter(a); ok {
+		if existing, err := sa.ReadServers(); err == nil {
+			for _, s := range existing {
+				servers = append(servers, GetServerName(s))
+			}
+		}
+	}
+	return toolname.NewTranslator(a.Name(), servers)
+}
+
+// commandsToNative returns copies of commands with tool lists translated to
+// the adapter's dialect
+func commandsToNative(tr *toolname.Translator, commands []*command.Command) []*command.Command {
+	result := make([]*command.Command, len(commands))
+	for i, cmd := range commands {
+		c := *cmd
+		c.AllowedTools = tr.ToNative(cmd.AllowedTools)
+		c.DisallowedTools = tr.ToNative(cmd.DisallowedTools)
+		result[i] = &c
+	}
+	return result
+}
+
+// commandsToCanonical translates tool lists of imported commands in place
+func commandsToCanonical(tr *toolname.Translator, commands []*command.Command) {
+	for _, cmd := range commands {
+		cmd.AllowedTools = tr.ToCanonical(cmd.AllowedTools)
+		cmd.DisallowedTools = tr.ToCanonical(cmd.DisallowedTools)
+	}
+}
+
+// agentsToNative returns copies of agents with tool lists translated to the
+// adapter's dialect
+func agentsToNative(tr *toolname.Translator, agents []*agent.Agent) []*agent.Agent {
+	result := make([]*agent.Agent, len(agents))
+	for i, ag := range agents {
+		a := *ag
+		a.Tools = tr.ToNative(ag.Tools)
+		a.DisallowedTools = tr.ToNative(ag.DisallowedTools)
+		result[i] = &a
+	}
+	return result
+}
+
+// agentsToCanonical translates tool lists of imported agents in place
+func agentsToCanonical(tr *toolname.Translator, agents []*agent.Agent) {
+	for _, ag := range agents {
+		ag.Tools = tr.ToCanonical(ag.Tools)
+		ag.DisallowedTools = tr.ToCanonical(ag.DisallowedTools)
+	}
+}
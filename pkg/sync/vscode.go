@@ -0,0 +1,324 @@
+package sync
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/iheanyi/agentctl/pkg/mcp"
+)
+
+// VSCodeAdapter syncs MCP servers to VS Code's built-in MCP support. VS
+// Code reads a "servers" section from mcp.json in the user profile and in
+// a workspace's .vscode directory.
+type VSCodeAdapter struct {
+	fsHolder
+}
+
+// vscodeInputMarker ends the description of the inputs agentctl adds for
+// secrets. Inputs are an array VS Code validates strictly, so the
+// description is how a later sync knows which ones it may remove.
+const vscodeInputMarker = "(managed by agentctl)"
+
+// vscodeSecretPrefix marks an agentctl secret reference in env and header
+// values
+const vscodeSecretPrefix = "keychain:"
+
+func init() {
+	Register(&VSCodeAdapter{})
+}
+
+func (a *VSCodeAdapter) Name() string {
+	return "vscode"
+}
+
+// WithFS returns a copy of the adapter that uses fsys
+func (a *VSCodeAdapter) WithFS(fsys FS) Adapter {
+	c := *a
+	c.fsys = fsys
+	return &c
+}
+
+func (a *VSCodeAdapter) Detect() (bool, error) {
+	userDir := vscodeUserDir()
+	if userDir == "" {
+		return false, nil
+	}
+
+	if _, err := a.fs().Stat(userDir); os.IsNotExist(err) {
+		return false, nil
+	}
+
+	return true, nil
+}
+
+func (a *VSCodeAdapter) ConfigPath() string {
+	return filepath.Join(vscodeUserDir(), "mcp.json")
+}
+
+// vscodeUserDir returns VS Code's user profile directory, where it and
+// its extensions keep their settings
+func vscodeUserDir() string {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+
+	switch runtime.GOOS {
+	case "darwin":
+		return filepath.Join(homeDir, "Library", "Application Support", "Code", "User")
+	case "linux":
+		if xdgConfig := os.Getenv("XDG_CONFIG_HOME"); xdgConfig != "" {
+			return filepath.Join(xdgConfig, "Code", "User")
+		}
+		return filepath.Join(homeDir, ".config", "Code", "User")
+	case "windows":
+		if appData := os.Getenv("APPDATA"); appData != "" {
+			return filepath.Join(appData, "Code", "User")
+		}
+		return filepath.Join(homeDir, "AppData", "Roaming", "Code", "User")
+	default:
+		return ""
+	}
+}
+
+func (a *VSCodeAdapter) SupportedResources() []ResourceType {
+	return []ResourceType{ResourceMCP}
+}
+
+func (a *VSCodeAdapter) ReadServers() ([]*mcp.Server, error) {
+	return readVSCodeServers(a.fs(), a.ConfigPath())
+}
+
+func (a *VSCodeAdapter) WriteServers(servers []*mcp.Server) error {
+	return writeVSCodeServers(a.fs(), a.ConfigPath(), a.Name(), servers)
+}
+
+// WorkspaceAdapter implementation for VS Code
+
+// SupportsWorkspace returns true - VS Code reads .vscode/mcp.json in the workspace
+func (a *VSCodeAdapter) SupportsWorkspace() bool {
+	return true
+}
+
+// WorkspaceConfigPath returns the path to .vscode/mcp.json in the project directory
+func (a *VSCodeAdapter) WorkspaceConfigPath(projectDir string) string {
+	return filepath.Join(projectDir, ".vscode", "mcp.json")
+}
+
+// ReadWorkspaceServers reads MCP servers from the project's .vscode/mcp.json
+func (a *VSCodeAdapter) ReadWorkspaceServers(projectDir string) ([]*mcp.Server, error) {
+	servers, err := readVSCodeServers(a.fs(), a.WorkspaceConfigPath(projectDir))
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range servers {
+		s.Scope = "local"
+	}
+	return servers, nil
+}
+
+// WriteWorkspaceServers writes MCP servers to the project's .vscode/mcp.json
+func (a *VSCodeAdapter) WriteWorkspaceServers(projectDir string, servers []*mcp.Server) error {
+	return writeVSCodeServers(a.fs(), a.WorkspaceConfigPath(projectDir), WorkspaceStateKey(a.Name(), projectDir), servers)
+}
+
+// readVSCodeServers reads the "servers" section of the mcp.json at path.
+// References to password inputs become agentctl secret references.
+func readVSCodeServers(fsys FS, path string) ([]*mcp.Server, error) {
+	raw, err := NewJSONConfigHelper(fsys, path).LoadRaw()
+	if err != nil {
+		return nil, err
+	}
+
+	secrets := make(map[string]bool)
+	for _, v := range vscodeInputs(raw) {
+		if input, ok := v.(map[string]interface{}); ok && input["password"] == true {
+			if id, ok := input["id"].(string); ok {
+				secrets[id] = true
+			}
+		}
+	}
+
+	section, _ := GetMCPServersSection(raw, "servers")
+	var servers []*mcp.Server
+	for name, v := range section {
+		serverData, ok := v.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		server := vscodeServerFromConfig(name, serverData)
+		for k, val := range server.Env {
+			server.Env[k] = vscodeSecretRef(val, secrets)
+		}
+		for k, val := range server.Headers {
+			server.Headers[k] = vscodeSecretRef(val, secrets)
+		}
+		servers = append(servers, server)
+	}
+	return servers, nil
+}
+
+// writeVSCodeServers replaces the managed entries in the "servers" section
+// of the mcp.json at path. VS Code validates the file against its schema,
+// so the servers we manage are tracked in the sync state under stateKey
+// rather than with a _managedBy field. Secret references become
+// ${input:...} prompts backed by password inputs.
+func writeVSCodeServers(fsys FS, path, stateKey string, servers []*mcp.Server) error {
+	helper := NewJSONConfigHelper(fsys, path)
+	raw, err := helper.LoadRaw()
+	if err != nil {
+		return err
+	}
+
+	state, err := loadState(fsys)
+	if err != nil {
+		return err
+	}
+
+	section, _ := GetMCPServersSection(raw, "servers")
+	for _, name := range state.GetManagedServers(stateKey) {
+		delete(section, name)
+	}
+
+	var managedNames []string
+	needed := make(map[string]bool)
+	for _, server := range servers {
+		name := GetServerName(server)
+		if name == "" {
+			continue
+		}
+		section[name] = vscodeServerConfig(server, needed)
+		managedNames = append(managedNames, name)
+	}
+	raw["servers"] = section
+
+	// Keep the user's inputs and add ours for secrets they don't already prompt for
+	var inputs []interface{}
+	have := make(map[string]bool)
+	for _, v := range vscodeInputs(raw) {
+		input, _ := v.(map[string]interface{})
+		if desc, _ := input["description"].(string); strings.HasSuffix(desc, vscodeInputMarker) {
+			continue
+		}
+		if id, ok := input["id"].(string); ok {
+			have[id] = true
+		}
+		inputs = append(inputs, v)
+	}
+	var ids []string
+	for id := range needed {
+		if !have[id] {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		inputs = append(inputs, map[string]interface{}{
+			"type":        "promptString",
+			"id":          id,
+			"description": fmt.Sprintf("%s %s", id, vscodeInputMarker),
+			"password":    true,
+		})
+	}
+	if len(inputs) > 0 {
+		raw["inputs"] = inputs
+	} else {
+		delete(raw, "inputs")
+	}
+
+	if err := helper.SaveRaw(raw); err != nil {
+		return err
+	}
+
+	state.SetManagedServers(stateKey, managedNames)
+	return state.save(fsys)
+}
+
+// vscodeInputs returns the "inputs" array of an mcp.json
+func vscodeInputs(raw map[string]interface{}) []interface{} {
+	inputs, _ := raw["inputs"].([]interface{})
+	return inputs
+}
+
+// vscodeServerConfig converts a server to an entry in the "servers"
+// section of VS Code's mcp.json, adding the IDs of the secrets it
+// references to secrets
+func vscodeServerConfig(server *mcp.Server, secrets map[string]bool) map[string]interface{} {
+	serverCfg := make(map[string]interface{})
+
+	if server.Transport == mcp.TransportHTTP || server.Transport == mcp.TransportSSE {
+		serverCfg["type"] = string(server.Transport)
+		serverCfg["url"] = server.URL
+		if len(server.Headers) > 0 {
+			serverCfg["headers"] = vscodeInputRefs(server.Headers, secrets)
+		}
+	} else {
+		serverCfg["type"] = "stdio"
+		serverCfg["command"] = server.Command
+		if len(server.Args) > 0 {
+			serverCfg["args"] = server.Args
+		}
+	}
+
+	if len(server.Env) > 0 {
+		serverCfg["env"] = vscodeInputRefs(server.Env, secrets)
+	}
+	if server.EnvFile != "" {
+		serverCfg["envFile"] = server.EnvFile
+	}
+
+	return serverCfg
+}
+
+// vscodeInputRefs returns values with secret references replaced by
+// ${input:...} references
+func vscodeInputRefs(values map[string]string, secrets map[string]bool) map[string]string {
+	out := make(map[string]string, len(values))
+	for k, v := range values {
+		if id, ok := strings.CutPrefix(v, vscodeSecretPrefix); ok && id != "" {
+			secrets[id] = true
+			v = "${input:" + id + "}"
+		}
+		out[k] = v
+	}
+	return out
+}
+
+// vscodeSecretRef turns a reference to one of the password inputs in
+// secrets back into a secret reference
+func vscodeSecretRef(value string, secrets map[string]bool) string {
+	if id, ok := strings.CutPrefix(value, "${input:"); ok && strings.HasSuffix(id, "}") {
+		if id = strings.TrimSuffix(id, "}"); secrets[id] {
+			return vscodeSecretPrefix + id
+		}
+	}
+	return value
+}
+
+// vscodeServerFromConfig parses an entry in the "servers" section of VS
+// Code's mcp.json
+func vscodeServerFromConfig(name string, serverData map[string]interface{}) *mcp.Server {
+	server := ServerFromRawMap(name, serverData)
+
+	switch serverType, _ := serverData["type"].(string); serverType {
+	case "http", "sse":
+		server.Transport = mcp.Transport(serverType)
+		if headers, ok := serverData["headers"].(map[string]interface{}); ok {
+			server.Headers = make(map[string]string)
+			for k, v := range headers {
+				if str, ok := v.(string); ok {
+					server.Headers[k] = str
+				}
+			}
+		}
+	}
+	if envFile, ok := serverData["envFile"].(string); ok {
+		server.EnvFile = envFile
+	}
+
+	return server
+}
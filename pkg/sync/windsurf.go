@@ -10,7 +10,9 @@ import (
 )
 
 // WindsurfAdapter syncs configuration to Windsurf (Codeium)
-type WindsurfAdapter struct{}
+type WindsurfAdapter struct {
+	fsHolder
+}
 
 // WindsurfServerConfig represents a server in Windsurf's config format
 type WindsurfServerConfig struct {
@@ -28,6 +30,13 @@ func (a *WindsurfAdapter) Name() string {
 	return "windsurf"
 }
 
+// WithFS returns a copy of the adapter that uses fsys
+func (a *WindsurfAdapter) WithFS(fsys FS) Adapter {
+	c := *a
+	c.fsys = fsys
+	return &c
+}
+
 func (a *WindsurfAdapter) Detect() (bool, error) {
 	path := a.ConfigPath()
 	if path == "" {
@@ -35,7 +44,7 @@ func (a *WindsurfAdapter) Detect() (bool, error) {
 	}
 
 	dir := filepath.Dir(path)
-	if _, err := os.Stat(dir); os.IsNotExist(err) {
+	if _, err := a.fs().Stat(dir); os.IsNotExist(err) {
 		return false, nil
 	}
 
@@ -62,8 +71,22 @@ func (a *WindsurfAdapter) SupportedResources() []ResourceType {
 	return []ResourceType{ResourceMCP, ResourceRules}
 }
 
+// StdioOnly returns true - Windsurf only launches stdio servers
+func (a *WindsurfAdapter) StdioOnly() bool {
+	return true
+}
+
+// ManagedPaths returns the global rules file
+func (a *WindsurfAdapter) ManagedPaths() []string {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return nil
+	}
+	return []string{filepath.Join(homeDir, ".windsurfrules")}
+}
+
 func (a *WindsurfAdapter) ReadServers() ([]*mcp.Server, error) {
-	helper := NewJSONConfigHelper(a.ConfigPath())
+	helper := NewJSONConfigHelper(a.fs(), a.ConfigPath())
 	raw, err := helper.LoadRaw()
 	if err != nil {
 		return nil, err
@@ -74,7 +97,7 @@ func (a *WindsurfAdapter) ReadServers() ([]*mcp.Server, error) {
 }
 
 func (a *WindsurfAdapter) WriteServers(servers []*mcp.Server) error {
-	helper := NewJSONConfigHelper(a.ConfigPath())
+	helper := NewJSONConfigHelper(a.fs(), a.ConfigPath())
 	raw, err := helper.LoadRaw()
 	if err != nil {
 		return err
@@ -84,7 +107,7 @@ func (a *WindsurfAdapter) WriteServers(servers []*mcp.Server) error {
 	RemoveManagedServers(mcpServers)
 
 	// Add new servers
-	for _, server := range servers {
+	for _, server := range FilterStdioServers(servers) {
 		name := GetServerName(server)
 		if name == "" {
 			continue
@@ -103,7 +126,7 @@ func (a *WindsurfAdapter) ReadRules() ([]*rule.Rule, error) {
 	}
 
 	rulesPath := filepath.Join(homeDir, ".windsurfrules")
-	if _, err := os.Stat(rulesPath); os.IsNotExist(err) {
+	if _, err := a.fs().Stat(rulesPath); os.IsNotExist(err) {
 		return nil, nil
 	}
 
@@ -135,5 +158,5 @@ func (a *WindsurfAdapter) WriteRules(rules []*rule.Rule) error {
 		content += r.Content
 	}
 
-	return os.WriteFile(rulesPath, []byte(content), 0644)
+	return a.fs().WriteFile(rulesPath, []byte(content), 0644)
 }
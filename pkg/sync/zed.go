@@ -1,7 +1,6 @@
 package sync
 
 import (
-	"encoding/json"
 	"os"
 	"path/filepath"
 	"runtime"
@@ -12,7 +11,9 @@ import (
 )
 
 // ZedAdapter syncs configuration to Zed editor
-type ZedAdapter struct{}
+type ZedAdapter struct {
+	fsHolder
+}
 
 // ZedServerConfig represents a server in Zed's config format
 type ZedServerConfig struct {
@@ -30,6 +31,13 @@ func (a *ZedAdapter) Name() string {
 	return "zed"
 }
 
+// WithFS returns a copy of the adapter that uses fsys
+func (a *ZedAdapter) WithFS(fsys FS) Adapter {
+	c := *a
+	c.fsys = fsys
+	return &c
+}
+
 func (a *ZedAdapter) Detect() (bool, error) {
 	path := a.ConfigPath()
 	if path == "" {
@@ -37,7 +45,7 @@ func (a *ZedAdapter) Detect() (bool, error) {
 	}
 
 	dir := filepath.Dir(path)
-	if _, err := os.Stat(dir); os.IsNotExist(err) {
+	if _, err := a.fs().Stat(dir); os.IsNotExist(err) {
 		return false, nil
 	}
 
@@ -66,8 +74,22 @@ func (a *ZedAdapter) SupportedResources() []ResourceType {
 	return []ResourceType{ResourceMCP}
 }
 
+// StdioOnly returns true - Zed only launches stdio servers
+func (a *ZedAdapter) StdioOnly() bool {
+	return true
+}
+
 func (a *ZedAdapter) ReadServers() ([]*mcp.Server, error) {
-	raw, err := a.loadRawConfig()
+	return a.readServers(a.ConfigPath())
+}
+
+func (a *ZedAdapter) WriteServers(servers []*mcp.Server) error {
+	return a.writeServers(a.ConfigPath(), servers)
+}
+
+// readServers reads the context_servers of the settings file at path
+func (a *ZedAdapter) readServers(path string) ([]*mcp.Server, error) {
+	raw, err := a.loadRawConfig(path)
 	if err != nil {
 		return nil, err
 	}
@@ -116,9 +138,11 @@ func (a *ZedAdapter) ReadServers() ([]*mcp.Server, error) {
 	return servers, nil
 }
 
-func (a *ZedAdapter) WriteServers(servers []*mcp.Server) error {
+// writeServers replaces the managed context_servers of the settings file
+// at path
+func (a *ZedAdapter) writeServers(path string, servers []*mcp.Server) error {
 	// Load the full raw config to preserve all fields
-	raw, err := a.loadRawConfig()
+	raw, err := a.loadRawConfig(path)
 	if err != nil {
 		return err
 	}
@@ -139,7 +163,7 @@ func (a *ZedAdapter) WriteServers(servers []*mcp.Server) error {
 	}
 
 	// Add new servers
-	for _, server := range servers {
+	for _, server := range FilterStdioServers(servers) {
 		name := server.Name
 		if server.Namespace != "" {
 			name = server.Namespace
@@ -169,7 +193,7 @@ func (a *ZedAdapter) WriteServers(servers []*mcp.Server) error {
 	// Update the context_servers section in raw config
 	raw["context_servers"] = contextServers
 
-	return a.saveRawConfig(raw)
+	return a.saveRawConfig(path, raw)
 }
 
 func (a *ZedAdapter) ReadCommands() ([]*command.Command, error) {
@@ -188,39 +212,44 @@ func (a *ZedAdapter) WriteRules(rules []*rule.Rule) error {
 	return nil
 }
 
-// loadRawConfig loads the entire config as a raw map to preserve all fields
-func (a *ZedAdapter) loadRawConfig() (map[string]interface{}, error) {
-	path := a.ConfigPath()
+// loadRawConfig loads the entire config as a raw map to preserve all
+// fields. Zed's settings are JSONC, so comments and trailing commas are
+// allowed.
+func (a *ZedAdapter) loadRawConfig(path string) (map[string]interface{}, error) {
+	return NewJSONConfigHelper(a.fs(), path).LoadRaw()
+}
 
-	data, err := os.ReadFile(path)
-	if err != nil {
-		if os.IsNotExist(err) {
-			return make(map[string]interface{}), nil
-		}
-		return nil, err
-	}
+// saveRawConfig saves the entire config, patching only what changed so
+// the user's comments and formatting are kept
+func (a *ZedAdapter) saveRawConfig(path string, raw map[string]interface{}) error {
+	return NewJSONConfigHelper(a.fs(), path).SaveRaw(raw)
+}
 
-	var raw map[string]interface{}
-	if err := json.Unmarshal(data, &raw); err != nil {
-		return nil, err
-	}
+// WorkspaceAdapter implementation for Zed
 
-	return raw, nil
+// SupportsWorkspace returns true - Zed reads .zed/settings.json in the project
+func (a *ZedAdapter) SupportsWorkspace() bool {
+	return true
 }
 
-// saveRawConfig saves the entire config, preserving all fields
-func (a *ZedAdapter) saveRawConfig(raw map[string]interface{}) error {
-	path := a.ConfigPath()
-
-	dir := filepath.Dir(path)
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		return err
-	}
+// WorkspaceConfigPath returns the path to .zed/settings.json in the project directory
+func (a *ZedAdapter) WorkspaceConfigPath(projectDir string) string {
+	return filepath.Join(projectDir, ".zed", "settings.json")
+}
 
-	data, err := json.MarshalIndent(raw, "", "  ")
+// ReadWorkspaceServers reads MCP servers from the project's .zed/settings.json
+func (a *ZedAdapter) ReadWorkspaceServers(projectDir string) ([]*mcp.Server, error) {
+	servers, err := a.readServers(a.WorkspaceConfigPath(projectDir))
 	if err != nil {
-		return err
+		return nil, err
 	}
+	for _, s := range servers {
+		s.Scope = "local"
+	}
+	return servers, nil
+}
 
-	return os.WriteFile(path, data, 0644)
+// WriteWorkspaceServers writes MCP servers to the project's .zed/settings.json
+func (a *ZedAdapter) WriteWorkspaceServers(projectDir string, servers []*mcp.Server) error {
+	return a.writeServers(a.WorkspaceConfigPath(projectDir), servers)
 }
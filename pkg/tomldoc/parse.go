@@ -0,0 +1,194 @@
+package tomldoc
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Parse splits a TOML file into blocks, one per table. Comments directly
+// above a table header are attached to it so they travel with it;
+// comments separated from the header by a blank line stay with the block
+// before. Only the structure is parsed: headers are read, values are kept
+// verbatim.
+func Parse(src string) (*Document, error) {
+	doc := &Document{}
+	cur := Block{}
+	var lines []string
+	s := &scanner{}
+
+	flush := func(n int) {
+		cur.Text = strings.Join(lines[:n], "")
+		doc.Blocks = append(doc.Blocks, cur)
+		lines = append([]string(nil), lines[n:]...)
+	}
+
+	for i, line := range strings.SplitAfter(src, "\n") {
+		if line == "" {
+			continue
+		}
+		trimmed := strings.TrimSpace(line)
+		if s.atTopLevel() && strings.HasPrefix(trimmed, "[") {
+			header, array, err := parseHeader(trimmed)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", i+1, err)
+			}
+
+			// Comment lines directly above move with the header
+			n := len(lines)
+			for n > 0 && strings.HasPrefix(strings.TrimSpace(lines[n-1]), "#") {
+				n--
+			}
+			flush(n)
+			cur = Block{Header: header, Array: array}
+		}
+		if err := s.scan(line); err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+		lines = append(lines, line)
+	}
+	if s.multiline != "" {
+		return nil, fmt.Errorf("unterminated multi-line string")
+	}
+	flush(len(lines))
+
+	// A file starting with a table has no root block to keep
+	if len(doc.Blocks) > 1 && doc.Blocks[0].Text == "" {
+		doc.Blocks = doc.Blocks[1:]
+	}
+	return doc, nil
+}
+
+// scanner tracks the strings and brackets a value spans across lines, so
+// lines inside multi-line arrays and strings aren't read as headers
+type scanner struct {
+	depth     int
+	multiline string // Closing delimiter of an open multi-line string
+}
+
+func (s *scanner) atTopLevel() bool {
+	return s.depth == 0 && s.multiline == ""
+}
+
+func (s *scanner) scan(line string) error {
+	for i := 0; i < len(line); i++ {
+		if s.multiline != "" {
+			end := strings.Index(line[i:], s.multiline)
+			if s.multiline == `"""` {
+				end = indexUnescaped(line[i:], s.multiline)
+			}
+			if end < 0 {
+				return nil
+			}
+			i += end + len(s.multiline) - 1
+			s.multiline = ""
+			continue
+		}
+
+		switch c := line[i]; c {
+		case '#':
+			return nil
+		case '"', '\'':
+			delim := strings.Repeat(string(c), 3)
+			if strings.HasPrefix(line[i:], delim) {
+				s.multiline = delim
+				i += 2
+				continue
+			}
+			end := strings.IndexByte(line[i+1:], c)
+			if c == '"' {
+				end = indexUnescaped(line[i+1:], `"`)
+			}
+			if end < 0 {
+				return fmt.Errorf("unterminated string")
+			}
+			i += end + 1
+		case '[', '{':
+			s.depth++
+		case ']', '}':
+			if s.depth > 0 {
+				s.depth--
+			}
+		}
+	}
+	return nil
+}
+
+// indexUnescaped returns the index of the first delim in s not preceded
+// by a backslash escape, or -1
+func indexUnescaped(s, delim string) int {
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '\\':
+			i++
+		case strings.HasPrefix(s[i:], delim):
+			return i
+		}
+	}
+	return -1
+}
+
+// parseHeader reads a [table] or [[array]] header line
+func parseHeader(line string) ([]string, bool, error) {
+	array := strings.HasPrefix(line, "[[")
+	rest := line[1:]
+	closing := "]"
+	if array {
+		rest = line[2:]
+		closing = "]]"
+	}
+
+	var path []string
+	for {
+		rest = strings.TrimLeft(rest, " \t")
+		key, after, err := parseKey(rest)
+		if err != nil {
+			return nil, false, err
+		}
+		path = append(path, key)
+		rest = strings.TrimLeft(after, " \t")
+		if strings.HasPrefix(rest, ".") {
+			rest = rest[1:]
+			continue
+		}
+		if !strings.HasPrefix(rest, closing) {
+			return nil, false, fmt.Errorf("invalid table header %q", line)
+		}
+		rest = strings.TrimSpace(rest[len(closing):])
+		if rest != "" && !strings.HasPrefix(rest, "#") {
+			return nil, false, fmt.Errorf("unexpected text after table header %q", line)
+		}
+		return path, array, nil
+	}
+}
+
+// parseKey reads one bare or quoted key from the start of s
+func parseKey(s string) (string, string, error) {
+	switch {
+	case strings.HasPrefix(s, `"`):
+		end := indexUnescaped(s[1:], `"`)
+		if end < 0 {
+			return "", "", fmt.Errorf("unterminated key")
+		}
+		key, err := strconv.Unquote(s[:end+2])
+		if err != nil {
+			return "", "", fmt.Errorf("invalid key %s", s[:end+2])
+		}
+		return key, s[end+2:], nil
+	case strings.HasPrefix(s, "'"):
+		end := strings.IndexByte(s[1:], '\'')
+		if end < 0 {
+			return "", "", fmt.Errorf("unterminated key")
+		}
+		return s[1 : end+1], s[end+2:], nil
+	}
+
+	n := 0
+	for n < len(s) && isBareKeyChar(s[n]) {
+		n++
+	}
+	if n == 0 {
+		return "", "", fmt.Errorf("missing key")
+	}
+	return s[:n], s[n:], nil
+}
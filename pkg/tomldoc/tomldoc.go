@@ -0,0 +1,301 @@
+// Package tomldoc edits TOML documents without re-encoding them. A
+// document is split into its tables, each kept as the original source with
+// the comments directly above its header, so tables can be replaced, added
+// and removed while comments, ordering and formatting elsewhere round-trip
+// unchanged.
+package tomldoc
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Block is a table: its header line with the comments directly above it
+// and the lines up to the next table. The first block holds the root
+// table's keys and has no header.
+type Block struct {
+	Text   string   // Original source text, including trailing newline
+	Header []string // Table key path, or nil for the root table
+	Array  bool     // [[array]] table
+}
+
+// Document is a parsed TOML file
+type Document struct {
+	Blocks []Block
+}
+
+// KeyValue is a key and value to write in a table
+type KeyValue struct {
+	Key   string
+	Value any // string, bool, int, float64, []string or map[string]string
+}
+
+// String renders the document back to source
+func (d *Document) String() string {
+	var sb strings.Builder
+	for _, b := range d.Blocks {
+		sb.WriteString(b.Text)
+	}
+	return sb.String()
+}
+
+// Comments returns the comment lines directly above the block's header
+func (b *Block) Comments() []string {
+	if b.Header == nil {
+		return nil
+	}
+	var comments []string
+	for _, line := range strings.SplitAfter(b.Text, "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "#") {
+			break
+		}
+		comments = append(comments, line)
+	}
+	return comments
+}
+
+// HasComment reports whether comment is one of the lines above the header
+func (b *Block) HasComment(comment string) bool {
+	for _, c := range b.Comments() {
+		if c == comment {
+			return true
+		}
+	}
+	return false
+}
+
+// under reports whether the block is the table at path or one of its
+// sub-tables
+func (b *Block) under(path []string) bool {
+	if len(b.Header) < len(path) {
+		return false
+	}
+	for i := range path {
+		if b.Header[i] != path[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Table returns the table at path, or nil if there is none
+func (d *Document) Table(path ...string) *Block {
+	for i := range d.Blocks {
+		if b := &d.Blocks[i]; !b.Array && len(b.Header) == len(path) && b.under(path) {
+			return b
+		}
+	}
+	return nil
+}
+
+// SetTable writes the table at path with values, under the given comment
+// lines. An existing table is replaced where it stands, along with its
+// sub-tables; a new one goes after the last table sharing its parent, or
+// at the end of the document.
+func (d *Document) SetTable(path []string, values []KeyValue, comments ...string) error {
+	text, err := FormatTable(path, values, comments...)
+	if err != nil {
+		return err
+	}
+
+	if existing := d.Table(path...); existing != nil {
+		at := -1
+		var blocks []Block
+		tail := ""
+		for _, b := range d.Blocks {
+			if b.Header != nil && b.under(path) {
+				if at < 0 {
+					at = len(blocks)
+					blocks = append(blocks, Block{Header: path})
+				}
+				tail = trailingBlankLines(b.Text)
+				continue
+			}
+			blocks = append(blocks, b)
+		}
+		blocks[at].Text = text + tail
+		d.Blocks = blocks
+		return nil
+	}
+
+	after := len(d.Blocks) - 1
+	for i, b := range d.Blocks {
+		if b.Header != nil && b.under(path[:len(path)-1]) {
+			after = i
+		}
+	}
+	block := Block{Text: text, Header: path}
+	if after < 0 {
+		d.Blocks = []Block{block}
+		return nil
+	}
+
+	prev := &d.Blocks[after]
+	if after < len(d.Blocks)-1 {
+		// Keep the spacing the previous table had before the next one
+		block.Text += trailingBlankLines(prev.Text)
+	} else if prev.Text != "" {
+		if !strings.HasSuffix(prev.Text, "\n") {
+			prev.Text += "\n"
+		}
+		if !strings.HasSuffix(prev.Text, "\n\n") {
+			prev.Text += "\n"
+		}
+	}
+	d.Blocks = append(d.Blocks[:after+1], append([]Block{block}, d.Blocks[after+1:]...)...)
+	return nil
+}
+
+// RemoveTable removes the table at path, its sub-tables and the comments
+// above them. It reports whether anything was removed.
+func (d *Document) RemoveTable(path ...string) bool {
+	var blocks []Block
+	for _, b := range d.Blocks {
+		if b.Header != nil && b.under(path) {
+			continue
+		}
+		blocks = append(blocks, b)
+	}
+	if len(blocks) == len(d.Blocks) {
+		return false
+	}
+
+	// Don't leave the spacing before a removed final table behind
+	if last := d.Blocks[len(d.Blocks)-1]; last.Header != nil && last.under(path) && len(blocks) > 0 {
+		end := &blocks[len(blocks)-1]
+		if trimmed := strings.TrimRight(end.Text, " \t\r\n"); trimmed != "" {
+			end.Text = trimmed + "\n"
+		}
+	}
+	d.Blocks = blocks
+	return true
+}
+
+// trailingBlankLines returns the blank lines ending text
+func trailingBlankLines(text string) string {
+	trimmed := strings.TrimRight(text, " \t\r\n")
+	rest := text[len(trimmed):]
+	if i := strings.IndexByte(rest, '\n'); i >= 0 {
+		return rest[i+1:]
+	}
+	return ""
+}
+
+// FormatTable renders a table with the given comment lines above it
+func FormatTable(path []string, values []KeyValue, comments ...string) (string, error) {
+	var sb strings.Builder
+	for _, c := range comments {
+		sb.WriteString(c + "\n")
+	}
+	sb.WriteString("[" + FormatKey(path...) + "]\n")
+	for _, kv := range values {
+		value, err := FormatValue(kv.Value)
+		if err != nil {
+			return "", fmt.Errorf("%s: %w", kv.Key, err)
+		}
+		sb.WriteString(FormatKey(kv.Key) + " = " + value + "\n")
+	}
+	return sb.String(), nil
+}
+
+// FormatKey renders a dotted key, quoting parts that aren't bare keys
+func FormatKey(path ...string) string {
+	parts := make([]string, len(path))
+	for i, p := range path {
+		if isBareKey(p) {
+			parts[i] = p
+		} else {
+			parts[i] = quote(p)
+		}
+	}
+	return strings.Join(parts, ".")
+}
+
+func isBareKey(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, c := range []byte(s) {
+		if !isBareKeyChar(c) {
+			return false
+		}
+	}
+	return true
+}
+
+func isBareKeyChar(c byte) bool {
+	return c == '_' || c == '-' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+// FormatValue renders a value. Maps become inline tables with sorted keys.
+func FormatValue(v any) (string, error) {
+	switch v := v.(type) {
+	case string:
+		return quote(v), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case int:
+		return strconv.Itoa(v), nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case []string:
+		parts := make([]string, len(v))
+		for i, s := range v {
+			parts[i] = quote(s)
+		}
+		return "[" + strings.Join(parts, ", ") + "]", nil
+	case map[string]string:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		parts := make([]string, len(keys))
+		for i, k := range keys {
+			parts[i] = FormatKey(k) + " = " + quote(v[k])
+		}
+		if len(parts) == 0 {
+			return "{}", nil
+		}
+		return "{ " + strings.Join(parts, ", ") + " }", nil
+	default:
+		return "", fmt.Errorf("unsupported TOML value type %T", v)
+	}
+}
+
+// quote renders s as a TOML basic string
+func quote(s string) string {
+	var sb strings.Builder
+	sb.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			sb.WriteString(`\"`)
+		case '\\':
+			sb.WriteString(`\\`)
+		case '\b':
+			sb.WriteString(`\b`)
+		case '\t':
+			sb.WriteString(`\t`)
+		case '\n':
+			sb.WriteString(`\n`)
+		case '\f':
+			sb.WriteString(`\f`)
+		case '\r':
+			sb.WriteString(`\r`)
+		default:
+			if r < 0x20 || r == 0x7f {
+				fmt.Fprintf(&sb, `\u%04X`, r)
+			} else {
+				sb.WriteRune(r)
+			}
+		}
+	}
+	sb.WriteByte('"')
+	return sb.String()
+}
@@ -0,0 +1,130 @@
+package tomldoc
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const sample = `# Codex config
+model = "o3"
+notes = """
+[not a table]
+"""
+matrix = [
+  [1, 2],
+  ["a]", 'b'],
+]
+
+# Tools I added by hand
+[mcp_servers.github]
+command = "gh-mcp" # inline comment
+
+[mcp_servers.github.env]
+TOKEN = "x"
+
+# agentctl
+[mcp_servers."my server"]
+command = "old"
+
+[[profiles]]
+name = "fast"
+`
+
+func TestParseRoundTrip(t *testing.T) {
+	doc, err := Parse(sample)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if got := doc.String(); got != sample {
+		t.Errorf("round trip mismatch:\n%s", got)
+	}
+
+	var headers [][]string
+	for _, b := range doc.Blocks {
+		headers = append(headers, b.Header)
+	}
+	want := [][]string{nil, {"mcp_servers", "github"}, {"mcp_servers", "github", "env"}, {"mcp_servers", "my server"}, {"profiles"}}
+	if !reflect.DeepEqual(headers, want) {
+		t.Fatalf("headers = %q, want %q", headers, want)
+	}
+	if !doc.Blocks[4].Array {
+		t.Error("[[profiles]] should be an array table")
+	}
+	if got := doc.Blocks[1].Comments(); !reflect.DeepEqual(got, []string{"# Tools I added by hand"}) {
+		t.Errorf("Comments() = %q", got)
+	}
+	if !doc.Table("mcp_servers", "my server").HasComment("# agentctl") {
+		t.Error("HasComment() = false for the comment above the header")
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, bad := range []string{"[a.b\n", "[a] x\n", "s = \"\"\"\nopen\n", "[\"open]\n"} {
+		if _, err := Parse(bad); err == nil {
+			t.Errorf("Parse(%q) succeeded, want error", bad)
+		}
+	}
+}
+
+func TestSetTable(t *testing.T) {
+	doc, _ := Parse(sample)
+
+	// Replacing drops the sub-tables and keeps the position
+	if err := doc.SetTable([]string{"mcp_servers", "github"}, []KeyValue{{"command", "gh"}, {"env", map[string]string{"TOKEN": "y"}}}, "# agentctl"); err != nil {
+		t.Fatalf("SetTable() error = %v", err)
+	}
+	// New tables go after their siblings
+	if err := doc.SetTable([]string{"mcp_servers", "new"}, []KeyValue{{"args", []string{"-y", `a"b`}}, {"enabled", true}, {"timeout", 30}}); err != nil {
+		t.Fatalf("SetTable() error = %v", err)
+	}
+	if doc.RemoveTable("mcp_servers", "missing") {
+		t.Error("RemoveTable() of a missing table reported a removal")
+	}
+
+	got := doc.String()
+	want := strings.Replace(sample, `# Tools I added by hand
+[mcp_servers.github]
+command = "gh-mcp" # inline comment
+
+[mcp_servers.github.env]
+TOKEN = "x"
+`, `# agentctl
+[mcp_servers.github]
+command = "gh"
+env = { TOKEN = "y" }
+`, 1)
+	want = strings.Replace(want, `command = "old"
+`, `command = "old"
+
+[mcp_servers.new]
+args = ["-y", "a\"b"]
+enabled = true
+timeout = 30
+`, 1)
+	if got != want {
+		t.Errorf("SetTable() result:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestRemoveTable(t *testing.T) {
+	doc, _ := Parse("a = 1\n\n[x]\nb = 2\n\n# about y\n[y]\nc = 3\n")
+	if !doc.RemoveTable("y") {
+		t.Fatal("RemoveTable() = false")
+	}
+	if got, want := doc.String(), "a = 1\n\n[x]\nb = 2\n"; got != want {
+		t.Errorf("after RemoveTable() = %q, want %q", got, want)
+	}
+
+	// Appending to a document ending in a table leaves a blank line between
+	doc.SetTable([]string{"z"}, []KeyValue{{"d", 4.5}})
+	if got, want := doc.String(), "a = 1\n\n[x]\nb = 2\n\n[z]\nd = 4.5\n"; got != want {
+		t.Errorf("after SetTable() = %q, want %q", got, want)
+	}
+
+	empty, _ := Parse("")
+	empty.SetTable([]string{"t"}, []KeyValue{{"k", "v"}})
+	if got := empty.String(); got != "[t]\nk = \"v\"\n" {
+		t.Errorf("SetTable() on empty document = %q", got)
+	}
+}
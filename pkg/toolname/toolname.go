@@ -0,0 +1,394 @@
+// Package toolname maps tool names used in allowedTools, disallowedTools and
+// hook matchers between agentctl's canonical vocabulary and the names each
+// coding assistant uses natively.
+//
+// The canonical vocabulary uses lowercase names ("read", "bash", "webfetch")
+// and the mcp__<server>__<tool> form for MCP tools. Names that aren't part of
+// the vocabulary pass through unchanged, so translation never loses data.
+package toolname
+
+import (
+	"sort"
+	"strings"
+)
+
+// Canonical tool names
+const (
+	Read         = "read"
+	Write        = "write"
+	Edit         = "edit"
+	MultiEdit    = "multiedit"
+	Bash         = "bash"
+	Grep         = "grep"
+	Glob         = "glob"
+	LS           = "ls"
+	WebFetch     = "webfetch"
+	WebSearch    = "websearch"
+	Task         = "task"
+	TodoWrite    = "todowrite"
+	NotebookEdit = "notebookedit"
+)
+
+// Canonical lists all canonical tool names in display order
+var Canonical = []string{
+	Read, Write, Edit, MultiEdit, Bash, Grep, Glob, LS,
+	WebFetch, WebSearch, Task, TodoWrite, NotebookEdit,
+}
+
+// canonicalMCPPrefix is the prefix for MCP tools in the canonical vocabulary
+const canonicalMCPPrefix = "mcp__"
+
+// canonicalMCPSeparator separates server and tool in canonical MCP tool names
+const canonicalMCPSeparator = "__"
+
+// mapping pairs a canonical name with a tool's native name
+type mapping struct {
+	canonical string
+	native    string
+}
+
+// Dialect describes how a single tool names built-in and MCP tools
+type Dialect struct {
+	// Tool is the adapter name this dialect belongs to (e.g., "claude")
+	Tool string
+
+	// MCPPrefix is prepended to MCP tool names (e.g., "mcp__" for Claude)
+	MCPPrefix string
+
+	// MCPSeparator separates the server name from the tool name
+	MCPSeparator string
+
+	// mappings is ordered; when several canonical names share a native name
+	// the first one wins on reverse translation
+	mappings []mapping
+}
+
+var dialects = map[string]*Dialect{
+	"claude": {
+		Tool:         "claude",
+		MCPPrefix:    "mcp__",
+		MCPSeparator: "__",
+		mappings: []mapping{
+			{Read, "Read"},
+			{Write, "Write"},
+			{Edit, "Edit"},
+			{MultiEdit, "MultiEdit"},
+			{Bash, "Bash"},
+			{Grep, "Grep"},
+			{Glob, "Glob"},
+			{LS, "LS"},
+			{WebFetch, "WebFetch"},
+			{WebSearch, "WebSearch"},
+			{Task, "Task"},
+			{TodoWrite, "TodoWrite"},
+			{NotebookEdit, "NotebookEdit"},
+		},
+	},
+	"gemini": {
+		Tool:         "gemini",
+		MCPSeparator: "__",
+		mappings: []mapping{
+			{Read, "read_file"},
+			{Write, "write_file"},
+			{Edit, "replace"},
+			{MultiEdit, "replace"},
+			{Bash, "run_shell_command"},
+			{Grep, "search_file_content"},
+			{Glob, "glob"},
+			{LS, "list_directory"},
+			{WebFetch, "web_fetch"},
+			{WebSearch, "google_web_search"},
+			{TodoWrite, "write_todos"},
+		},
+	},
+	"opencode": {
+		Tool:         "opencode",
+		MCPSeparator: "_",
+		mappings: []mapping{
+			{Read, "read"},
+			{Write, "write"},
+			{Edit, "edit"},
+			{MultiEdit, "edit"},
+			{Bash, "bash"},
+			{Grep, "grep"},
+			{Glob, "glob"},
+			{LS, "list"},
+			{WebFetch, "webfetch"},
+			{Task, "task"},
+			{TodoWrite, "todowrite"},
+		},
+	},
+	"copilot": {
+		Tool:         "copilot",
+		MCPSeparator: "/",
+		mappings: []mapping{
+			{Read, "read"},
+			{Edit, "edit"},
+			{Write, "edit"},
+			{MultiEdit, "edit"},
+			{Bash, "shell"},
+			{Grep, "search"},
+			{Glob, "search"},
+			{WebFetch, "web"},
+			{WebSearch, "web"},
+			{Task, "custom-agent"},
+			{TodoWrite, "todo"},
+		},
+	},
+}
+
+// DialectFor returns the dialect for a tool, if agentctl knows its vocabulary
+func DialectFor(tool string) (*Dialect, bool) {
+	d, ok := dialects[tool]
+	return d, ok
+}
+
+// Tools returns the names of all tools with a known dialect
+func Tools() []string {
+	names := make([]string, 0, len(dialects))
+	for name := range dialects {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// native returns the native name for a canonical name
+func (d *Dialect) native(canonical string) (string, bool) {
+	for _, m := range d.mappings {
+		if m.canonical == canonical {
+			return m.native, true
+		}
+	}
+	return "", false
+}
+
+// canonical returns the canonical name for a native name
+func (d *Dialect) canonical(native string) (string, bool) {
+	for _, m := range d.mappings {
+		if m.native == native {
+			return m.canonical, true
+		}
+	}
+	return "", false
+}
+
+// MCPToolName formats an MCP tool name in this dialect.
+// An empty or "*" tool refers to every tool on the server.
+func (d *Dialect) MCPToolName(server, tool string) string {
+	if tool == "" {
+		// Claude-style dialects accept the bare server form
+		if d.MCPPrefix != "" {
+			return d.MCPPrefix + server
+		}
+		tool = "*"
+	}
+	return d.MCPPrefix + server + d.MCPSeparator + tool
+}
+
+// Translator converts tool names between the canonical vocabulary and a
+// single tool's dialect. Known MCP server names are used to split native
+// MCP tool names whose separator is ambiguous (e.g., OpenCode's server_tool).
+type Translator struct {
+	dialect *Dialect
+	servers []string
+}
+
+// NewTranslator creates a translator for a tool. servers lists the MCP
+// server names (after namespacing) configured for that tool. A tool without
+// a known dialect gets a translator that only normalizes MCP names.
+func NewTranslator(tool string, servers []string) *Translator {
+	d, _ := DialectFor(tool)
+
+	// Longest names first so "github-enterprise" wins over "github"
+	sorted := append([]string(nil), servers...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return len(sorted[i]) > len(sorted[j])
+	})
+
+	return &Translator{dialect: d, servers: sorted}
+}
+
+// ToNative translates canonical tool names to the tool's dialect
+func (t *Translator) ToNative(names []string) []string {
+	if len(names) == 0 {
+		return names
+	}
+	result := make([]string, len(names))
+	for i, name := range names {
+		result[i] = t.NativeName(name)
+	}
+	return result
+}
+
+// ToCanonical translates native tool names to the canonical vocabulary
+func (t *Translator) ToCanonical(names []string) []string {
+	if len(names) == 0 {
+		return names
+	}
+	result := make([]string, len(names))
+	for i, name := range names {
+		result[i] = t.CanonicalName(name)
+	}
+	return result
+}
+
+// NativeName translates a single canonical name to the tool's dialect.
+// Names may carry an argument specifier such as "bash(git push:*)" which is
+// preserved as-is. Names outside the vocabulary are returned unchanged.
+func (t *Translator) NativeName(name string) string {
+	if t.dialect == nil {
+		return name
+	}
+
+	base, spec := splitSpecifier(name)
+
+	if server, tool, ok := parseCanonicalMCP(base); ok {
+		return t.dialect.MCPToolName(server, tool) + spec
+	}
+
+	if native, ok := t.dialect.native(Canonicalize(base)); ok {
+		return native + spec
+	}
+
+	return name
+}
+
+// CanonicalName translates a single native name to the canonical vocabulary.
+// Names outside the tool's vocabulary are returned unchanged.
+func (t *Translator) CanonicalName(name string) string {
+	if t.dialect == nil {
+		return name
+	}
+
+	base, spec := splitSpecifier(name)
+
+	if canonical, ok := t.dialect.canonical(base); ok {
+		return canonical + spec
+	}
+
+	if server, tool, ok := t.parseNativeMCP(base); ok {
+		return formatCanonicalMCP(server, tool) + spec
+	}
+
+	return name
+}
+
+// TranslateMatcher translates a hook matcher such as "Edit|Write" into the
+// tool's dialect, translating each alternative independently
+func (t *Translator) TranslateMatcher(matcher string) string {
+	return translateMatcher(matcher, t.NativeName)
+}
+
+// CanonicalMatcher translates a native hook matcher such as
+// "write_file|replace" into the canonical vocabulary, translating each
+// alternative independently
+func (t *Translator) CanonicalMatcher(matcher string) string {
+	return translateMatcher(matcher, t.CanonicalName)
+}
+
+func translateMatcher(matcher string, translate func(string) string) string {
+	if matcher == "" || matcher == "*" {
+		return matcher
+	}
+	parts := strings.Split(matcher, "|")
+	for i, p := range parts {
+		parts[i] = translate(strings.TrimSpace(p))
+	}
+	return strings.Join(parts, "|")
+}
+
+// parseNativeMCP splits a native MCP tool name into server and tool using
+// the configured server names
+func (t *Translator) parseNativeMCP(name string) (server, tool string, ok bool) {
+	d := t.dialect
+	if d.MCPPrefix != "" {
+		if !strings.HasPrefix(name, d.MCPPrefix) {
+			return "", "", false
+		}
+		name = strings.TrimPrefix(name, d.MCPPrefix)
+	}
+
+	for _, s := range t.servers {
+		if name == s && d.MCPPrefix != "" {
+			return s, "", true
+		}
+		if rest, found := strings.CutPrefix(name, s+d.MCPSeparator); found && rest != "" {
+			if rest == "*" {
+				rest = ""
+			}
+			return s, rest, true
+		}
+	}
+
+	// Without a known server we can only split unambiguous prefixed names
+	if d.MCPPrefix != "" {
+		if s, rest, found := strings.Cut(name, d.MCPSeparator); found {
+			return s, rest, true
+		}
+		return name, "", true
+	}
+
+	return "", "", false
+}
+
+// Canonicalize returns the canonical form of a built-in tool name written in
+// any known dialect (e.g., "Read", "read_file" and "read" all yield "read").
+// MCP names and unknown names are returned unchanged.
+func Canonicalize(name string) string {
+	lower := strings.ToLower(name)
+	for _, c := range Canonical {
+		if c == lower {
+			return c
+		}
+	}
+	for _, tool := range Tools() {
+		if c, ok := dialects[tool].canonical(name); ok {
+			return c
+		}
+	}
+	return name
+}
+
+// IsMCP reports whether a canonical name refers to an MCP tool
+func IsMCP(name string) bool {
+	base, _ := splitSpecifier(name)
+	_, _, ok := parseCanonicalMCP(base)
+	return ok
+}
+
+// MCPServer returns the server part of a canonical MCP tool name
+func MCPServer(name string) (string, bool) {
+	base, _ := splitSpecifier(name)
+	server, _, ok := parseCanonicalMCP(base)
+	return server, ok
+}
+
+// parseCanonicalMCP parses mcp__server__tool, mcp__server__* and mcp__server
+func parseCanonicalMCP(name string) (server, tool string, ok bool) {
+	rest, found := strings.CutPrefix(name, canonicalMCPPrefix)
+	if !found || rest == "" {
+		return "", "", false
+	}
+	server, tool, _ = strings.Cut(rest, canonicalMCPSeparator)
+	if tool == "*" {
+		tool = ""
+	}
+	return server, tool, server != ""
+}
+
+// formatCanonicalMCP formats a canonical MCP tool name
+func formatCanonicalMCP(server, tool string) string {
+	if tool == "" {
+		return canonicalMCPPrefix + server
+	}
+	return canonicalMCPPrefix + server + canonicalMCPSeparator + tool
+}
+
+// splitSpecifier splits "Bash(git push:*)" into "Bash" and "(git push:*)"
+func splitSpecifier(name string) (base, spec string) {
+	if i := strings.Index(name, "("); i > 0 && strings.HasSuffix(name, ")") {
+		return name[:i], name[i:]
+	}
+	return name, ""
+}
@@ -0,0 +1,190 @@
+package toolname
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestToNative(t *testing.T) {
+	servers := []string{"github", "sentry"}
+
+	tests := []struct {
+		tool string
+		in   []string
+		want []string
+	}{
+		{
+			tool: "claude",
+			in:   []string{"read", "bash(git push:*)", "mcp__github__create_issue"},
+			want: []string{"Read", "Bash(git push:*)", "mcp__github__create_issue"},
+		},
+		{
+			tool: "claude",
+			in:   []string{"Read", "Write"},
+			want: []string{"Read", "Write"},
+		},
+		{
+			tool: "gemini",
+			in:   []string{"read", "bash", "mcp__github__create_issue", "mcp__sentry"},
+			want: []string{"read_file", "run_shell_command", "github__create_issue", "sentry__*"},
+		},
+		{
+			tool: "opencode",
+			in:   []string{"Read", "ls", "mcp__github__create_issue"},
+			want: []string{"read", "list", "github_create_issue"},
+		},
+		{
+			tool: "copilot",
+			in:   []string{"grep", "webfetch", "mcp__sentry__*"},
+			want: []string{"search", "web", "sentry/*"},
+		},
+		{
+			tool: "unknown",
+			in:   []string{"read", "mcp__github__x"},
+			want: []string{"read", "mcp__github__x"},
+		},
+		{
+			tool: "claude",
+			in:   []string{"custom_tool"},
+			want: []string{"custom_tool"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.tool, func(t *testing.T) {
+			tr := NewTranslator(tt.tool, servers)
+			got := tr.ToNative(tt.in)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ToNative(%v) = %v, want %v", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestToCanonical(t *testing.T) {
+	servers := []string{"github", "github_enterprise"}
+
+	tests := []struct {
+		tool string
+		in   []string
+		want []string
+	}{
+		{
+			tool: "claude",
+			in:   []string{"Read", "Bash(npm test:*)", "mcp__github__create_issue", "mcp__github"},
+			want: []string{"read", "bash(npm test:*)", "mcp__github__create_issue", "mcp__github"},
+		},
+		{
+			tool: "opencode",
+			in:   []string{"list", "github_enterprise_search", "github_create_issue", "github_*"},
+			want: []string{"ls", "mcp__github_enterprise__search", "mcp__github__create_issue", "mcp__github"},
+		},
+		{
+			tool: "gemini",
+			in:   []string{"run_shell_command", "github__list_prs", "mystery"},
+			want: []string{"bash", "mcp__github__list_prs", "mystery"},
+		},
+		{
+			tool: "copilot",
+			in:   []string{"edit", "search", "github/create_issue"},
+			want: []string{"edit", "grep", "mcp__github__create_issue"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.tool, func(t *testing.T) {
+			tr := NewTranslator(tt.tool, servers)
+			got := tr.ToCanonical(tt.in)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ToCanonical(%v) = %v, want %v", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	servers := []string{"github"}
+	canonical := []string{"read", "bash", "grep", "mcp__github__create_issue"}
+
+	for _, tool := range []string{"claude", "gemini", "opencode"} {
+		t.Run(tool, func(t *testing.T) {
+			tr := NewTranslator(tool, servers)
+			got := tr.ToCanonical(tr.ToNative(canonical))
+			if !reflect.DeepEqual(got, canonical) {
+				t.Errorf("round trip = %v, want %v", got, canonical)
+			}
+		})
+	}
+}
+
+func TestTranslateMatcher(t *testing.T) {
+	tr := NewTranslator("gemini", nil)
+
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"*", "*"},
+		{"", ""},
+		{"Edit|Write", "replace|write_file"},
+		{"bash", "run_shell_command"},
+	}
+
+	for _, tt := range tests {
+		if got := tr.TranslateMatcher(tt.in); got != tt.want {
+			t.Errorf("TranslateMatcher(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestCanonicalMatcher(t *testing.T) {
+	tests := []struct {
+		tool string
+		in   string
+		want string
+	}{
+		{"gemini", "*", "*"},
+		{"gemini", "write_file|replace", "write|edit"},
+		{"gemini", "run_shell_command", "bash"},
+		{"claude", "Edit|MultiEdit", "edit|multiedit"},
+		{"claude", "Notebook.*", "Notebook.*"},
+	}
+
+	for _, tt := range tests {
+		if got := NewTranslator(tt.tool, nil).CanonicalMatcher(tt.in); got != tt.want {
+			t.Errorf("CanonicalMatcher(%s, %q) = %q, want %q", tt.tool, tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestCanonicalize(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"Read", "read"},
+		{"read_file", "read"},
+		{"run_shell_command", "bash"},
+		{"WebFetch", "webfetch"},
+		{"mcp__github__x", "mcp__github__x"},
+		{"unknown", "unknown"},
+	}
+
+	for _, tt := range tests {
+		if got := Canonicalize(tt.in); got != tt.want {
+			t.Errorf("Canonicalize(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestMCPServer(t *testing.T) {
+	if server, ok := MCPServer("mcp__github__create_issue"); !ok || server != "github" {
+		t.Errorf("MCPServer() = %q, %v; want github, true", server, ok)
+	}
+	if _, ok := MCPServer("Read"); ok {
+		t.Error("MCPServer(Read) should not be an MCP tool")
+	}
+	if !IsMCP("mcp__sentry") {
+		t.Error("IsMCP(mcp__sentry) should be true")
+	}
+}